@@ -9,6 +9,7 @@ package app
 
 import (
 	"context"
+	"path/filepath"
 
 	"github.com/DataDog/datadog-agent/pkg/collector/runner"
 	"github.com/DataDog/datadog-agent/pkg/collector/scheduler"
@@ -91,10 +92,8 @@ func startCompliance(stopper restart.Stopper, apiCl *apiserver.APIClient, isLead
 	if err != nil {
 		return err
 	}
-	agent, err := agent.New(
-		reporter,
-		scheduler,
-		configDir,
+
+	options := []checks.BuilderOption{
 		checks.WithInterval(checkInterval),
 		checks.WithHostname(hostname),
 		checks.WithMatchRule(func(rule *compliance.Rule) bool {
@@ -102,6 +101,19 @@ func startCompliance(stopper restart.Stopper, apiCl *apiserver.APIClient, isLead
 		}),
 		checks.WithKubernetesClient(apiCl.DynamicCl),
 		checks.WithIsLeader(isLeader),
+	}
+
+	if coreconfig.Datadog.GetBool("compliance_config.drift_detection.enabled") {
+		statePath := filepath.Join(coreconfig.Datadog.GetString("compliance_config.run_path"), "compliance-cluster-state.json")
+		snapshotInterval := coreconfig.Datadog.GetDuration("compliance_config.drift_detection.snapshot_interval")
+		options = append(options, checks.MayFail(checks.WithDriftDetection(statePath, snapshotInterval)))
+	}
+
+	agent, err := agent.New(
+		reporter,
+		scheduler,
+		configDir,
+		options...,
 	)
 	if err != nil {
 		return err
@@ -8,6 +8,7 @@ package app
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -153,6 +154,12 @@ func startCompliance(hostname string, stopper restart.Stopper, statsdClient *ddg
 		checks.MayFail(checks.WithAudit()),
 	}
 
+	if coreconfig.Datadog.GetBool("compliance_config.drift_detection.enabled") {
+		statePath := filepath.Join(coreconfig.Datadog.GetString("compliance_config.run_path"), "compliance-state.json")
+		snapshotInterval := coreconfig.Datadog.GetDuration("compliance_config.drift_detection.snapshot_interval")
+		options = append(options, checks.MayFail(checks.WithDriftDetection(statePath, snapshotInterval)))
+	}
+
 	if coreconfig.IsKubernetes() {
 		nodeLabels, err := agent.WaitGetNodeLabels()
 		if err != nil {
@@ -10,6 +10,7 @@ package app
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -50,6 +51,32 @@ var (
 		dir string
 	}{}
 
+	policyCmd = &cobra.Command{
+		Use:   "policy",
+		Short: "Policy utility commands",
+	}
+
+	policyCheckCmd = &cobra.Command{
+		Use:   "check",
+		Short: "Check policies for errors, conflicts, unused macros and undiscardable fields",
+		RunE:  policyCheck,
+	}
+
+	policyCheckArgs = struct {
+		dir string
+	}{}
+
+	policyTestCmd = &cobra.Command{
+		Use:   "test",
+		Short: "Evaluate the rules of the policies against JSON event fixtures",
+		RunE:  policyTest,
+	}
+
+	policyTestArgs = struct {
+		dir      string
+		fixtures string
+	}{}
+
 	dumpCmd = &cobra.Command{
 		Use:   "dump",
 		Short: "Dump security module information",
@@ -68,6 +95,16 @@ func init() {
 
 	runtimeCmd.AddCommand(checkPoliciesCmd)
 	checkPoliciesCmd.Flags().StringVar(&checkPoliciesArgs.dir, "policies-dir", coreconfig.DefaultRuntimePoliciesDir, "Path to policies directory")
+
+	policyCmd.AddCommand(policyCheckCmd)
+	policyCheckCmd.Flags().StringVar(&policyCheckArgs.dir, "policies-dir", coreconfig.DefaultRuntimePoliciesDir, "Path to policies directory")
+
+	policyCmd.AddCommand(policyTestCmd)
+	policyTestCmd.Flags().StringVar(&policyTestArgs.dir, "policies-dir", coreconfig.DefaultRuntimePoliciesDir, "Path to policies directory")
+	policyTestCmd.Flags().StringVar(&policyTestArgs.fixtures, "fixtures", "", "Path to the JSON file of event fixtures")
+	_ = policyTestCmd.MarkFlagRequired("fixtures")
+
+	runtimeCmd.AddCommand(policyCmd)
 }
 
 func dumpProcessCache(cmd *cobra.Command, args []string) error {
@@ -120,6 +157,75 @@ func checkPolicies(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func policyCheck(cmd *cobra.Command, args []string) error {
+	policies, err := rules.LoadPolicyFiles(policyCheckArgs.dir, securityLogger.DatadogAgentLogger{})
+	if err.ErrorOrNil() != nil {
+		return err
+	}
+
+	// enabled all the rules
+	enabled := map[eval.EventType]bool{"*": true}
+
+	opts := rules.NewOptsWithParams(model.SECLConstants, sprobe.SupportedDiscarders, enabled, sprobe.AllCustomRuleIDs(), securityLogger.DatadogAgentLogger{})
+	model := &sprobe.Model{}
+	ruleSet := rules.NewRuleSet(model, model.NewEvent, opts)
+
+	report := rules.CheckPolicies(policies, ruleSet)
+
+	content, _ := json.MarshalIndent(report, "", "\t")
+	fmt.Printf("%s\n", string(content))
+
+	if !report.IsValid() {
+		return fmt.Errorf("%d error(s) and %d conflict(s) found", len(report.Errors), len(report.Conflicts))
+	}
+
+	return nil
+}
+
+func policyTest(cmd *cobra.Command, args []string) error {
+	f, err := os.Open(policyTestArgs.fixtures)
+	if err != nil {
+		return errors.Wrap(err, "unable to open the fixtures")
+	}
+	defer f.Close()
+
+	fixtures, err := rules.LoadEventFixtures(f)
+	if err != nil {
+		return err
+	}
+
+	// enabled all the rules
+	enabled := map[eval.EventType]bool{"*": true}
+
+	// the fixtures are evaluated using the model only, without any probe
+	opts := rules.NewOptsWithParams(model.SECLConstants, nil, enabled, sprobe.AllCustomRuleIDs(), securityLogger.DatadogAgentLogger{})
+	ruleSet := rules.NewRuleSet(&model.Model{}, (&model.Model{}).NewEvent, opts)
+
+	if err := rules.LoadPolicies(policyTestArgs.dir, ruleSet); err.ErrorOrNil() != nil {
+		return err
+	}
+
+	results := ruleSet.TestEventFixtures(fixtures, func(eventType eval.EventType) eval.Event {
+		return &model.Event{Type: uint64(model.ParseEvalEventType(eventType))}
+	})
+
+	var failed int
+	for _, result := range results {
+		if !result.Passed() {
+			failed++
+		}
+	}
+
+	content, _ := json.MarshalIndent(results, "", "\t")
+	fmt.Printf("%s\n", string(content))
+
+	if failed > 0 {
+		return fmt.Errorf("%d out of %d fixture(s) failed", failed, len(results))
+	}
+
+	return nil
+}
+
 func newRuntimeReporter(stopper restart.Stopper, sourceName, sourceType string, endpoints *config.Endpoints, context *client.DestinationsContext) (event.Reporter, error) {
 	health := health.RegisterLiveness("runtime-security")
 
@@ -29,6 +29,13 @@ var (
 		RunE:  runStatus,
 	}
 
+	complianceStatusCmd = &cobra.Command{
+		Use:   "status",
+		Short: "Print the status of the compliance checks",
+		Long:  ``,
+		RunE:  runComplianceStatus,
+	}
+
 	statusArgs = struct {
 		json            bool
 		prettyPrintJSON bool
@@ -41,9 +48,23 @@ func init() {
 	statusCmd.Flags().BoolVarP(&statusArgs.json, "json", "j", false, "print out raw json")
 	statusCmd.Flags().BoolVarP(&statusArgs.prettyPrintJSON, "pretty-json", "p", false, "pretty print JSON")
 	statusCmd.Flags().StringVarP(&statusArgs.file, "file", "o", "", "Output the status command to a file")
+
+	complianceCmd.AddCommand(complianceStatusCmd)
+	complianceStatusCmd.Flags().BoolVarP(&statusArgs.json, "json", "j", false, "print out raw json")
+	complianceStatusCmd.Flags().BoolVarP(&statusArgs.prettyPrintJSON, "pretty-json", "p", false, "pretty print JSON")
+	complianceStatusCmd.Flags().StringVarP(&statusArgs.file, "file", "o", "", "Output the status command to a file")
 }
 
 func runStatus(cmd *cobra.Command, args []string) error {
+	return runStatusWithFormat(cmd, status.FormatSecurityAgentStatus)
+}
+
+// runComplianceStatus prints the status of the compliance checks only, including when their result last changed
+func runComplianceStatus(cmd *cobra.Command, args []string) error {
+	return runStatusWithFormat(cmd, status.FormatComplianceStatus)
+}
+
+func runStatusWithFormat(cmd *cobra.Command, format func([]byte) (string, error)) error {
 	if flagNoColor {
 		color.NoColor = true
 	}
@@ -59,10 +80,10 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		return log.Errorf("Cannot setup logger, exiting: %v", err)
 	}
 
-	return requestStatus()
+	return requestStatus(format)
 }
 
-func requestStatus() error {
+func requestStatus(format func([]byte) (string, error)) error {
 	fmt.Printf("Getting the status from the agent.\n")
 	var e error
 	var s string
@@ -99,7 +120,7 @@ func requestStatus() error {
 	} else if statusArgs.json {
 		s = string(r)
 	} else {
-		formattedStatus, err := status.FormatSecurityAgentStatus(r)
+		formattedStatus, err := format(r)
 		if err != nil {
 			return err
 		}
@@ -15,9 +15,11 @@ import (
 	"os"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/compliance"
 	"github.com/DataDog/datadog-agent/pkg/compliance/agent"
 	"github.com/DataDog/datadog-agent/pkg/compliance/checks"
 	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/report"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/flavor"
@@ -33,6 +35,8 @@ var (
 		framework string
 		file      string
 		verbose   bool
+		report    string
+		output    string
 	}{}
 )
 
@@ -40,6 +44,8 @@ func setupCheckCmd(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&checkArgs.framework, "framework", "", "", "Framework to run the checks from")
 	cmd.Flags().StringVarP(&checkArgs.file, "file", "f", "", "Compliance suite file to read rules from")
 	cmd.Flags().BoolVarP(&checkArgs.verbose, "verbose", "v", false, "Include verbose details")
+	cmd.Flags().StringVarP(&checkArgs.report, "report", "", "", fmt.Sprintf("Write the results aggregated per rule instead of the events, in one of the formats %v", report.Formats))
+	cmd.Flags().StringVarP(&checkArgs.output, "output", "o", "", "File to write the report to, defaults to the standard output")
 }
 
 // CheckCmd returns a cobra command to run security agent checks
@@ -57,6 +63,15 @@ func CheckCmd(confPathArray []string) *cobra.Command {
 }
 
 func runCheck(cmd *cobra.Command, confPathArray []string, args []string) error {
+	var reportFormat report.Format
+	if checkArgs.report != "" {
+		format, err := report.ParseFormat(checkArgs.report)
+		if err != nil {
+			return err
+		}
+		reportFormat = format
+	}
+
 	err := configureLogger()
 	if err != nil {
 		return err
@@ -113,7 +128,10 @@ func runCheck(cmd *cobra.Command, confPathArray []string, args []string) error {
 
 	options = append(options, checks.WithHostname(hostname))
 
-	reporter := &runCheckReporter{}
+	// when writing a report, the events are only collected through the status of the checks
+	reporter := &runCheckReporter{
+		silent: reportFormat != "",
+	}
 
 	if ruleID != "" {
 		log.Infof("Looking for rule with ID=%s", ruleID)
@@ -125,17 +143,46 @@ func runCheck(cmd *cobra.Command, confPathArray []string, args []string) error {
 		options = append(options, checks.WithMatchSuite(checks.IsFramework(checkArgs.framework)))
 	}
 
+	var checksStatus compliance.CheckStatusList
 	if checkArgs.file != "" {
-		err = agent.RunChecksFromFile(reporter, checkArgs.file, options...)
+		checksStatus, err = agent.RunChecksFromFile(reporter, checkArgs.file, options...)
 	} else {
 		configDir := config.Datadog.GetString("compliance_config.dir")
-		err = agent.RunChecks(reporter, configDir, options...)
+		checksStatus, err = agent.RunChecks(reporter, configDir, options...)
 	}
 
 	if err != nil {
 		log.Errorf("Failed to run checks: %v", err)
 		return err
 	}
+
+	if reportFormat != "" {
+		return writeReport(reportFormat, report.NewResults(hostname, checksStatus, time.Now()))
+	}
+	return nil
+}
+
+func writeReport(format report.Format, results *report.Results) error {
+	if checkArgs.output == "" {
+		return report.Write(os.Stdout, format, results)
+	}
+
+	f, err := os.Create(checkArgs.output)
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+
+	if err := report.Write(f, format, results); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	log.Infof("Report of %d rules written to %s: %d passed, %d failed, %d errors, %d skipped", len(results.Rules), checkArgs.output,
+		results.Summary.Passed, results.Summary.Failed, results.Summary.Error, results.Summary.Skipped)
 	return nil
 }
 
@@ -149,7 +196,14 @@ func configureLogger() error {
 		logFormat = fmt.Sprintf("%%Date(%s) | %%LEVEL | (%%ShortFilePath:%%Line in %%FuncShort) | %%Msg%%n", logDateFormat)
 		logLevel = "trace"
 	}
-	logger, err := seelog.LoggerFromWriterWithMinLevelAndFormat(os.Stdout, seelog.DebugLvl, logFormat)
+
+	// keep the standard output for the report when it's written there
+	output := os.Stdout
+	if checkArgs.report != "" && checkArgs.output == "" {
+		output = os.Stderr
+	}
+
+	logger, err := seelog.LoggerFromWriterWithMinLevelAndFormat(output, seelog.DebugLvl, logFormat)
 	if err != nil {
 		return err
 	}
@@ -159,9 +213,14 @@ func configureLogger() error {
 }
 
 type runCheckReporter struct {
+	silent bool
 }
 
 func (r *runCheckReporter) Report(event *event.Event) {
+	if r.silent {
+		return
+	}
+
 	data, err := json.Marshal(event)
 	if err != nil {
 		log.Errorf("Failed to marshal rule event: %v", err)
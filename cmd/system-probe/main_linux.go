@@ -4,6 +4,7 @@ package main
 
 import (
 	"flag"
+	"os"
 
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 )
@@ -15,6 +16,10 @@ func main() {
 	flag.BoolVar(&opts.version, "version", false, "Print the version and exit")
 	flag.Parse()
 
+	if flag.Arg(0) == nettopCommand {
+		os.Exit(runNettop(flag.Args()[1:]))
+	}
+
 	// Handles signals, which tells us whether we should exit.
 	exit := make(chan struct{})
 	go util.HandleSignals(exit)
@@ -105,6 +105,17 @@ func (nt *networkTracer) Register(httpMux *http.ServeMux) error {
 		utils.WriteAsJSON(w, stats)
 	})
 
+	httpMux.HandleFunc("/check/dns_query_log", func(w http.ResponseWriter, req *http.Request) {
+		entries, err := nt.tracer.GetDNSQueryLog()
+		if err != nil {
+			log.Errorf("unable to retrieve dns query log: %s", err)
+			w.WriteHeader(500)
+			return
+		}
+
+		utils.WriteAsJSON(w, entries)
+	})
+
 	// Convenience logging if nothing has made any requests to the system-probe in some time, let's log something.
 	// This should be helpful for customers + support to debug the underlying issue.
 	time.AfterFunc(inactivityLogDuration, func() {
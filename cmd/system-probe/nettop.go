@@ -0,0 +1,67 @@
+// +build linux
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/network/nettop"
+	"github.com/DataDog/datadog-agent/pkg/process/config"
+	"github.com/DataDog/datadog-agent/pkg/process/net"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/containers/providers"
+
+	// register the cgroup provider used to retrieve the container of the processes
+	_ "github.com/DataDog/datadog-agent/pkg/util/containers/providers/cgroup"
+)
+
+const nettopCommand = "nettop"
+
+// runNettop runs the nettop command against the running system-probe and returns the exit code
+func runNettop(args []string) int {
+	nettopOpts := nettop.Options{}
+	flags := flag.NewFlagSet(nettopCommand, flag.ContinueOnError)
+	flags.DurationVar(&nettopOpts.Interval, "interval", 2*time.Second, "Time between two refreshes")
+	flags.StringVar(&nettopOpts.GroupBy, "group-by", nettop.GroupByProcess, "Grouping of the connections: process, container or destination")
+	flags.StringVar(&nettopOpts.SortBy, "sort", nettop.SortByTotal, "Sort of the talkers: total, sent, recv, conns or retransmits")
+	flags.IntVar(&nettopOpts.Limit, "n", 20, "Number of talkers displayed, 0 for all of them")
+	flags.IntVar(&nettopOpts.Count, "count", 0, "Number of refreshes before exiting, 0 to run until interrupted")
+	flags.BoolVar(&nettopOpts.JSON, "json", false, "Output a JSON object per line and refresh instead of a table")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if err := nettopOpts.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	cfg, err := config.NewSystemProbeConfig(loggerName, opts.configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load the system-probe config: %s\n", err)
+		return 1
+	}
+
+	net.SetSystemProbePath(cfg.SystemProbeAddress)
+	probeUtil, err := net.GetRemoteSystemProbeUtil()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to connect to system-probe on %s: %s\n", cfg.SystemProbeAddress, err)
+		return 1
+	}
+
+	nettopOpts.ProcRoot = util.GetProcRoot()
+	nettopOpts.ContainerIDForPID = providers.ContainerImpl().ContainerIDForPID
+	if stat, err := os.Stdout.Stat(); err == nil && stat.Mode()&os.ModeCharDevice != 0 {
+		nettopOpts.Clear = !nettopOpts.JSON
+	}
+
+	exit := make(chan struct{})
+	go util.HandleSignals(exit)
+	if err := nettop.Run(probeUtil, nettopOpts, os.Stdout, exit); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,229 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// FIXME: we require the `cgo` build tag because of this dep relationship:
+// github.com/DataDog/datadog-agent/pkg/process/net depends on `github.com/DataDog/agent-payload/process`,
+// which has a hard dependency on `github.com/DataDog/zstd`, which requires CGO.
+// Should be removed once `github.com/DataDog/agent-payload/process` can be imported with CGO disabled.
+// +build cgo
+// +build linux
+
+package ebpf
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	dd_config "github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/logs"
+	logsconfig "github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/network"
+	process_net "github.com/DataDog/datadog-agent/pkg/process/net"
+	"github.com/DataDog/datadog-agent/pkg/serverless/aws"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/containers/providers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	dnsQueryLogCheckName = "dns_query_log"
+
+	// dnsQueryLogChannelSize is the number of queries buffered for the logs-agent, the queries being dropped beyond
+	dnsQueryLogChannelSize = 10000
+)
+
+// DNSQueryLogConfig is the config of the DNS query log check
+type DNSQueryLogConfig struct {
+	SendLogs              bool    `yaml:"send_logs"`
+	MinQueries            int     `yaml:"min_queries"`
+	NXDomainRateThreshold float64 `yaml:"nxdomain_rate_threshold"`
+	TimeoutRateThreshold  float64 `yaml:"timeout_rate_threshold"`
+}
+
+// DNSQueryLogCheck forwards the DNS queries logged by system-probe as logs, and sends an event when the rate of
+// NXDOMAIN responses or of timed out queries of a domain or a container crosses its threshold
+type DNSQueryLogCheck struct {
+	core.CheckBase
+	instance *DNSQueryLogConfig
+	detector *network.DNSFailureDetector
+	source   *logs.ChannelSource
+	dropped  int
+}
+
+// DNSQueryLogFactory is exported for integration testing
+func DNSQueryLogFactory() check.Check {
+	return &DNSQueryLogCheck{
+		CheckBase: core.NewCheckBase(dnsQueryLogCheckName),
+		instance:  &DNSQueryLogConfig{},
+	}
+}
+
+func init() {
+	core.RegisterCheck(dnsQueryLogCheckName, DNSQueryLogFactory)
+}
+
+// Parse parses the check configuration
+func (c *DNSQueryLogConfig) Parse(data []byte) error {
+	// default values
+	c.SendLogs = true
+	c.MinQueries = 20
+	c.NXDomainRateThreshold = 0.5
+	c.TimeoutRateThreshold = 0.2
+
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Configure parses the check configuration and init the check
+func (d *DNSQueryLogCheck) Configure(config, initConfig integration.Data, source string) error {
+	// TODO: Remove that hard-code and put it somewhere else
+	process_net.SetSystemProbePath(dd_config.Datadog.GetString("system_probe_config.sysprobe_socket"))
+
+	err := d.CommonConfigure(config, source)
+	if err != nil {
+		return err
+	}
+
+	if err := d.instance.Parse(config); err != nil {
+		return err
+	}
+	d.detector = network.NewDNSFailureDetector(d.instance.MinQueries, d.instance.NXDomainRateThreshold, d.instance.TimeoutRateThreshold)
+	return nil
+}
+
+// Run executes the check
+func (d *DNSQueryLogCheck) Run() error {
+	sysProbeUtil, err := process_net.GetRemoteSystemProbeUtil()
+	if err != nil {
+		return err
+	}
+
+	data, err := sysProbeUtil.GetCheck(dnsQueryLogCheckName)
+	if err != nil {
+		return err
+	}
+
+	entries, ok := data.([]network.DNSQueryLogEntry)
+	if !ok {
+		return log.Errorf("Raw data has incorrect type")
+	}
+
+	// the containers are resolved on the agent side, system-probe only knowing the pid of the processes
+	containerIDs := make(map[uint32]string)
+	for i := range entries {
+		pid := entries[i].Pid
+		if pid == 0 {
+			continue
+		}
+		id, ok := containerIDs[pid]
+		if !ok {
+			id, _ = providers.ContainerImpl().ContainerIDForPID(int(pid))
+			containerIDs[pid] = id
+		}
+		entries[i].ContainerID = id
+	}
+
+	if d.instance.SendLogs {
+		d.sendLogs(entries)
+	}
+
+	// sender is just what is used to submit the data
+	sender, err := aggregator.GetSender(d.ID())
+	if err != nil {
+		return err
+	}
+
+	for _, spike := range d.detector.Detect(entries) {
+		var tags []string
+		var subject string
+		if spike.ContainerID != "" {
+			subject = fmt.Sprintf("container %s", spike.ContainerID)
+			if entityID := containers.BuildTaggerEntityName(spike.ContainerID); entityID != "" {
+				tags, err = tagger.Tag(entityID, tagger.ChecksCardinality)
+				if err != nil {
+					log.Errorf("Error collecting tags for container %s: %s", spike.ContainerID, err)
+				}
+			}
+		} else {
+			subject = fmt.Sprintf("domain %s", spike.Domain)
+			tags = append(tags, "domain:"+spike.Domain)
+		}
+		tags = append(tags, "failure_type:"+spike.Kind)
+
+		var failures string
+		if spike.Kind == network.DNSSpikeTimeout {
+			failures = "timed out"
+		} else {
+			failures = "got a NXDOMAIN response"
+		}
+
+		var b strings.Builder
+		b.WriteString("%%% \n")
+		fmt.Fprintf(&b, "%d of the %d DNS queries (%.0f%%) for the %s %s since the previous run of the check.", spike.Failures, spike.Queries, spike.Rate*100, subject, failures)
+		b.WriteString("\n %%%")
+
+		sender.Event(metrics.Event{
+			Priority:       metrics.EventPriorityNormal,
+			AlertType:      metrics.EventAlertTypeWarning,
+			SourceTypeName: dnsQueryLogCheckName,
+			EventType:      dnsQueryLogCheckName,
+			AggregationKey: spike.Kind + ":" + subject,
+			Title:          fmt.Sprintf("DNS %s spike for the %s", spike.Kind, subject),
+			Text:           b.String(),
+			Tags:           tags,
+		})
+	}
+
+	sender.Commit()
+	return nil
+}
+
+// sendLogs forwards the queries to the logs-agent, dropping them if it can't keep up
+func (d *DNSQueryLogCheck) sendLogs(entries []network.DNSQueryLogEntry) {
+	if len(entries) == 0 {
+		return
+	}
+
+	// the source is closed when the logs-agent stops, and added again once it is restarted
+	if d.source == nil || d.source.Closed() {
+		source, err := logs.AddChannelSource("DNS query log", &logsconfig.LogsConfig{
+			Type:    logsconfig.StringChannelType,
+			Source:  dnsQueryLogCheckName,
+			Service: "system-probe",
+		}, dnsQueryLogChannelSize)
+		if err != nil {
+			log.Debugf("Not sending the DNS query log: %s", err)
+			return
+		}
+		d.source = source
+	}
+
+	dropped := 0
+	for _, entry := range entries {
+		record, err := json.Marshal(entry)
+		if err != nil {
+			log.Debugf("Error encoding DNS query %s: %s", entry.Question, err)
+			continue
+		}
+		if !d.source.Send(aws.LogMessage{Time: entry.Timestamp, StringRecord: string(record)}) {
+			dropped++
+		}
+	}
+	if dropped > 0 {
+		d.dropped += dropped
+		log.Warnf("Dropped %d DNS queries (%d in total) because the logs-agent can't keep up", dropped, d.dropped)
+	}
+}
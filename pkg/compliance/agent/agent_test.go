@@ -211,7 +211,7 @@ func TestRunChecks(t *testing.T) {
 	dockerClient.On("Close").Return(nil).Once()
 	defer dockerClient.AssertExpectations(t)
 
-	err := RunChecks(
+	status, err := RunChecks(
 		reporter,
 		e.dir,
 		checks.WithMatchSuite(checks.IsFramework("cis-docker")),
@@ -221,6 +221,10 @@ func TestRunChecks(t *testing.T) {
 		checks.WithDockerClient(dockerClient),
 	)
 	assert.NoError(err)
+	assert.Len(status, 1)
+	assert.Equal("cis-docker-1", status[0].RuleID)
+	assert.Equal("cis-docker", status[0].Framework)
+	assert.Equal("passed", status[0].LastEvent.Result)
 }
 
 func TestRunChecksFromFile(t *testing.T) {
@@ -256,7 +260,7 @@ func TestRunChecksFromFile(t *testing.T) {
 		"node-role.kubernetes.io/worker": "",
 	}
 
-	err := RunChecksFromFile(
+	status, err := RunChecksFromFile(
 		reporter,
 		filepath.Join(e.dir, "cis-kubernetes.yaml"),
 		checks.WithHostname("the-host"),
@@ -265,4 +269,8 @@ func TestRunChecksFromFile(t *testing.T) {
 		checks.WithNodeLabels(nodeLabels),
 	)
 	assert.NoError(err)
+	assert.Len(status, 1)
+	assert.Equal("cis-kubernetes-1", status[0].RuleID)
+	assert.Equal("Run chmod 644 /files/kube-apiserver.yaml", status[0].Remediation)
+	assert.Equal("failed", status[0].LastEvent.Result)
 }
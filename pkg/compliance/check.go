@@ -18,11 +18,14 @@ type CheckStatus struct {
 	RuleID      string
 	Name        string
 	Description string
+	Remediation string
 	Version     string
 	Framework   string
 	Source      string
 	InitError   error
 	LastEvent   *event.Event
+	LastEvents  []*event.Event
+	LastChange  int64
 }
 
 // CheckStatusList describes status for all configured checks
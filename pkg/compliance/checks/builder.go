@@ -174,6 +174,22 @@ func WithNodeLabels(nodeLabels map[string]string) BuilderOption {
 	}
 }
 
+// WithDriftDetection configures a builder to only report the result changes of the checks, along with a full snapshot
+// of the results every snapshot interval. The last result per rule and resource is stored in the given file.
+func WithDriftDetection(statePath string, snapshotInterval time.Duration) BuilderOption {
+	return func(b *builder) error {
+		if snapshotInterval <= 0 {
+			return fmt.Errorf("invalid drift detection snapshot interval: %s", snapshotInterval)
+		}
+		drift, err := newDriftStore(statePath, snapshotInterval)
+		if err != nil {
+			return fmt.Errorf("failed to load drift state from %s: %w", statePath, err)
+		}
+		b.drift = drift
+		return nil
+	}
+}
+
 // IsFramework matches a compliance suite by the name of the framework
 func IsFramework(framework string) SuiteMatcher {
 	return func(s *compliance.SuiteMeta) bool {
@@ -196,6 +212,8 @@ func NewBuilder(reporter event.Reporter, options ...BuilderOption) (Builder, err
 		etcGroupPath:  "/etc/group",
 		status:        newStatus(),
 	}
+	// results are tracked in memory only, to know when they last changed, unless drift detection is configured
+	b.drift, _ = newDriftStore("", 0)
 
 	for _, o := range options {
 		if err := o(b); err != nil {
@@ -230,6 +248,7 @@ type builder struct {
 	kubeClient   env.KubeClient
 	isLeaderFunc func() bool
 
+	drift  *driftStore
 	status *status
 }
 
@@ -277,7 +296,7 @@ func (b *builder) ChecksFromFile(file string, onCheck compliance.CheckVisitor) e
 		}
 		matchedCount++
 
-		if len(r.Resources) == 0 {
+		if len(r.Resources) == 0 && !r.IsRego() {
 			log.Infof("%s/%s: skipped rule %s - no configured resources", suite.Meta.Name, suite.Meta.Version, r.ID)
 			continue
 		}
@@ -296,6 +315,7 @@ func (b *builder) ChecksFromFile(file string, onCheck compliance.CheckVisitor) e
 			b.status.addCheck(&compliance.CheckStatus{
 				RuleID:      r.ID,
 				Description: r.Description,
+				Remediation: r.Remediation,
 				Name:        compliance.CheckName(r.ID, r.Description),
 				Framework:   suite.Meta.Framework,
 				Source:      suite.Meta.Source,
@@ -447,7 +467,15 @@ func (b *builder) nodeLabelKeys() []string {
 }
 
 func (b *builder) newCheck(meta *compliance.SuiteMeta, ruleScope compliance.RuleScope, rule *compliance.Rule) (compliance.Check, error) {
-	checkable, err := newResourceCheckList(b, rule.ID, rule.Resources)
+	var (
+		checkable checkable
+		err       error
+	)
+	if rule.IsRego() {
+		checkable, err = newRegoCheck(b, rule.ID, rule)
+	} else {
+		checkable, err = newResourceCheckList(b, rule.ID, rule.Resources)
+	}
 
 	if err != nil {
 		return nil, err
@@ -473,6 +501,7 @@ func (b *builder) newCheck(meta *compliance.SuiteMeta, ruleScope compliance.Rule
 		resourceID:   b.hostname,
 		checkable:    checkable,
 
+		drift:       b.drift,
 		eventNotify: notify,
 	}, nil
 }
@@ -16,8 +16,9 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
-// eventNotify is a callback invoked when a compliance check reported an event
-type eventNotify func(ruleID string, event *event.Event)
+// eventNotify is a callback invoked once per run of a compliance check with the events of all its resources, along with
+// the time the result of the rule last changed
+type eventNotify func(ruleID string, events []*event.Event, lastChange time.Time)
 
 // complianceCheck implements a compliance check
 type complianceCheck struct {
@@ -34,6 +35,7 @@ type complianceCheck struct {
 
 	checkable checkable
 
+	drift       *driftStore
 	eventNotify eventNotify
 }
 
@@ -84,30 +86,102 @@ func (c *complianceCheck) Run() error {
 		return nil
 	}
 
-	report, err := c.checkable.check(c)
+	var (
+		reports []*compliance.Report
+		err     error
+	)
+	if m, ok := c.checkable.(multiReportCheckable); ok {
+		reports, err = m.checkReports(c)
+	} else {
+		var report *compliance.Report
+		report, err = c.checkable.check(c)
+		reports = []*compliance.Report{report}
+	}
+
 	if err != nil {
 		log.Warnf("%s: check run failed: %v", c.ruleID, err)
+		reports = []*compliance.Report{nil}
 	}
-	data, result := reportToEventData(report, err)
-
-	e := &event.Event{
-		AgentRuleID:  c.ruleID,
-		ResourceID:   c.resourceID,
-		ResourceType: c.resourceType,
-		Result:       result,
-		Data:         data,
+
+	events := make([]*event.Event, 0, len(reports))
+	for _, report := range reports {
+		reportErr := err
+		if report != nil && report.Error != nil {
+			reportErr = report.Error
+		}
+		data, result := reportToEventData(report, reportErr)
+
+		resourceType, resourceID := c.resourceType, c.resourceID
+		if report != nil && report.ResourceID != "" {
+			resourceID = report.ResourceID
+			if report.ResourceType != "" {
+				resourceType = report.ResourceType
+			}
+		}
+
+		events = append(events, &event.Event{
+			AgentRuleID:  c.ruleID,
+			ResourceID:   resourceID,
+			ResourceType: resourceType,
+			Result:       result,
+			Data:         data,
+		})
 	}
 
-	log.Debugf("%s: reporting [%s]", c.ruleID, e.Result)
+	updates := c.driftUpdates(events)
+	if c.drift != nil {
+		if saveErr := c.drift.save(c.ruleID, updates); saveErr != nil {
+			log.Warnf("%s: failed to save drift state: %v", c.ruleID, saveErr)
+		}
+	}
+
+	var lastChange time.Time
+	for _, e := range events {
+		update := updates[driftKey(c.ruleID, e.ResourceType, e.ResourceID)]
+		if update.report() {
+			if c.drift != nil && c.drift.enabled() {
+				e.Tags = update.tags()
+			}
+
+			log.Debugf("%s: reporting [%s]", c.ruleID, e.Result)
+			c.Reporter().Report(e)
+		} else {
+			log.Debugf("%s: skipping [%s], result unchanged", c.ruleID, e.Result)
+		}
+
+		if update.lastChange.After(lastChange) {
+			lastChange = update.lastChange
+		}
+	}
 
-	c.Reporter().Report(e)
 	if c.eventNotify != nil {
-		c.eventNotify(c.ruleID, e)
+		c.eventNotify(c.ruleID, events, lastChange)
 	}
 
 	return err
 }
 
+// driftUpdates records the results of the events per resource and returns, for each resource, how its result compares
+// to the previous one. The events reported for the same resource are recorded with the worst of their results.
+func (c *complianceCheck) driftUpdates(events []*event.Event) map[string]driftUpdate {
+	resourceEvents := make(map[string][]*event.Event)
+	for _, e := range events {
+		key := driftKey(c.ruleID, e.ResourceType, e.ResourceID)
+		resourceEvents[key] = append(resourceEvents[key], e)
+	}
+
+	now := time.Now()
+	updates := make(map[string]driftUpdate, len(resourceEvents))
+	for key, events := range resourceEvents {
+		if c.drift == nil {
+			updates[key] = driftUpdate{snapshot: true}
+			continue
+		}
+		updates[key] = c.drift.update(key, worstResult(events), now)
+	}
+	return updates
+}
+
 func reportToEventData(report *compliance.Report, err error) (event.Data, string) {
 	var (
 		data   event.Data
@@ -7,9 +7,14 @@ package checks
 
 import (
 	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
 	"github.com/DataDog/datadog-agent/pkg/compliance/event"
 	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
 	assert "github.com/stretchr/testify/require"
@@ -151,3 +156,166 @@ func TestCheckRunNoLeader(t *testing.T) {
 	err := check.Run()
 	assert.Nil(err)
 }
+
+func TestCheckRunDrift(t *testing.T) {
+	const (
+		ruleID       = "rule-id"
+		resourceType = "resource-type"
+		resourceID   = "resource-id"
+	)
+
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "compliance-drift-")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	statePath := filepath.Join(dir, "compliance-state.json")
+	drift, err := newDriftStore(statePath, time.Hour)
+	assert.NoError(err)
+
+	env := &mocks.Env{}
+	defer env.AssertExpectations(t)
+
+	reporter := &mocks.Reporter{}
+	defer reporter.AssertExpectations(t)
+
+	checkable := &mockCheckable{}
+	defer checkable.AssertExpectations(t)
+
+	check := &complianceCheck{
+		Env: env,
+
+		ruleID:       ruleID,
+		resourceType: resourceType,
+		resourceID:   resourceID,
+		checkable:    checkable,
+		drift:        drift,
+	}
+
+	env.On("IsLeader").Return(true)
+	env.On("Reporter").Return(reporter)
+
+	data := event.Data{
+		"file.permissions": 0644,
+	}
+
+	newEvent := func(result string, tags ...string) *event.Event {
+		return &event.Event{
+			AgentRuleID:  ruleID,
+			ResourceType: resourceType,
+			ResourceID:   resourceID,
+			Result:       result,
+			Tags:         tags,
+			Data:         data,
+		}
+	}
+
+	// first run, reported as part of a snapshot
+	checkable.On("check", check).Return(&compliance.Report{Passed: true, Data: data}, nil).Once()
+	reporter.On("Report", newEvent("passed", "drift:snapshot")).Once()
+	assert.NoError(check.Run())
+
+	// same result, not reported
+	checkable.On("check", check).Return(&compliance.Report{Passed: true, Data: data}, nil).Once()
+	assert.NoError(check.Run())
+
+	// result changed, reported as a change
+	checkable.On("check", check).Return(&compliance.Report{Passed: false, Data: data}, nil).Once()
+	reporter.On("Report", newEvent("failed", "drift:change", "previous_result:passed")).Once()
+	assert.NoError(check.Run())
+
+	// the state survives restarts
+	restored, err := newDriftStore(statePath, time.Hour)
+	assert.NoError(err)
+	assert.Equal("failed", restored.states[driftKey(ruleID, resourceType, resourceID)].Result)
+}
+
+// multiReportCheckableFunc is a checkable reporting several results per run
+type multiReportCheckableFunc func(env env.Env) ([]*compliance.Report, error)
+
+func (f multiReportCheckableFunc) check(env env.Env) (*compliance.Report, error) {
+	return nil, errors.New("unexpected single report check")
+}
+
+func (f multiReportCheckableFunc) checkReports(env env.Env) ([]*compliance.Report, error) {
+	return f(env)
+}
+
+func TestCheckRunDriftPerResource(t *testing.T) {
+	const (
+		ruleID       = "rule-id"
+		resourceType = "kube_node"
+	)
+
+	assert := assert.New(t)
+
+	drift, err := newDriftStore("", time.Hour)
+	assert.NoError(err)
+
+	// the check reports the result of each node
+	var results map[string]bool
+	checkable := multiReportCheckableFunc(func(_ env.Env) ([]*compliance.Report, error) {
+		var reports []*compliance.Report
+		for _, node := range []string{"node-1", "node-2"} {
+			reports = append(reports, &compliance.Report{
+				Data:         event.Data{"kube.node.name": node},
+				Passed:       results[node],
+				ResourceType: resourceType,
+				ResourceID:   node,
+			})
+		}
+		return reports, nil
+	})
+
+	env := &mocks.Env{}
+	defer env.AssertExpectations(t)
+
+	reporter := &mocks.Reporter{}
+	defer reporter.AssertExpectations(t)
+
+	check := &complianceCheck{
+		Env: env,
+
+		ruleID:       ruleID,
+		resourceType: "kubernetesCluster",
+		resourceID:   "the-cluster",
+		checkable:    checkable,
+		drift:        drift,
+	}
+
+	// the status is notified once per run, with the events of all the resources
+	var notified [][]*event.Event
+	check.eventNotify = func(_ string, events []*event.Event, _ time.Time) {
+		notified = append(notified, events)
+	}
+
+	env.On("IsLeader").Return(true)
+	env.On("Reporter").Return(reporter)
+
+	newEvent := func(node, result string, tags ...string) *event.Event {
+		return &event.Event{
+			AgentRuleID:  ruleID,
+			ResourceType: resourceType,
+			ResourceID:   node,
+			Result:       result,
+			Tags:         tags,
+			Data:         event.Data{"kube.node.name": node},
+		}
+	}
+
+	// first run, every resource is reported as part of a snapshot
+	results = map[string]bool{"node-1": true, "node-2": true}
+	reporter.On("Report", newEvent("node-1", "passed", "drift:snapshot")).Once()
+	reporter.On("Report", newEvent("node-2", "passed", "drift:snapshot")).Once()
+	assert.NoError(check.Run())
+
+	// only the resource whose result changed is reported
+	results = map[string]bool{"node-1": true, "node-2": false}
+	reporter.On("Report", newEvent("node-2", "failed", "drift:change", "previous_result:passed")).Once()
+	assert.NoError(check.Run())
+
+	assert.Len(notified, 2)
+	assert.Len(notified[1], 2)
+	assert.Equal("failed", worstResult(notified[1]))
+}
@@ -15,6 +15,13 @@ type checkable interface {
 	check(env env.Env) (*compliance.Report, error)
 }
 
+// multiReportCheckable is implemented by the checkables reporting several results per run, such as Rego rules
+// reporting each of their findings
+type multiReportCheckable interface {
+	checkable
+	checkReports(env env.Env) ([]*compliance.Report, error)
+}
+
 // checkableList abstracts a list of resource checks
 type checkableList []checkable
 
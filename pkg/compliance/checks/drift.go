@@ -0,0 +1,201 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+)
+
+const (
+	// driftTagChange is the tag of the events reported because the result of the check changed
+	driftTagChange = "drift:change"
+	// driftTagSnapshot is the tag of the events reported as part of a periodic full snapshot
+	driftTagSnapshot = "drift:snapshot"
+)
+
+// driftState holds the last result of a rule for a resource
+type driftState struct {
+	Result     string    `json:"result"`
+	LastChange time.Time `json:"last_change"`
+	LastReport time.Time `json:"last_report"`
+}
+
+// driftUpdate describes the outcome of a check run compared to the previous result
+type driftUpdate struct {
+	previous   string
+	lastChange time.Time
+	changed    bool
+	snapshot   bool
+}
+
+// report returns whether the events of the check run have to be reported
+func (u driftUpdate) report() bool {
+	return u.changed || u.snapshot
+}
+
+// tags returns the tags of the events reported for the check run
+func (u driftUpdate) tags() []string {
+	if u.changed {
+		return []string{driftTagChange, "previous_result:" + u.previous}
+	}
+	return []string{driftTagSnapshot}
+}
+
+// driftStore remembers the last result per rule and resource. When drift detection is enabled, only the result
+// changes are reported between the periodic full snapshots. The states are written to a local file, if any, so that
+// they survive restarts.
+type driftStore struct {
+	sync.Mutex
+	path             string
+	snapshotInterval time.Duration
+	states           map[string]*driftState
+	dirty            bool // whether the states changed since they were last saved
+}
+
+// newDriftStore returns a drift store persisted in the given file, loading the states it holds. An empty path keeps
+// the states in memory only. A zero snapshot interval disables drift detection: every check run is reported.
+func newDriftStore(path string, snapshotInterval time.Duration) (*driftStore, error) {
+	s := &driftStore{
+		path:             path,
+		snapshotInterval: snapshotInterval,
+		states:           make(map[string]*driftState),
+	}
+
+	if path == "" {
+		return s, nil
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(content, &s.states); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// enabled returns whether only the result changes and the snapshots are reported
+func (s *driftStore) enabled() bool {
+	return s.snapshotInterval > 0
+}
+
+func driftKey(ruleID, resourceType, resourceID string) string {
+	return ruleID + "|" + resourceType + "|" + resourceID
+}
+
+// update records the result of a check run and returns how it compares to the previous one. A snapshot is due on the
+// first run and once the snapshot interval elapsed since the last report.
+func (s *driftStore) update(key, result string, now time.Time) driftUpdate {
+	s.Lock()
+	defer s.Unlock()
+
+	state, exists := s.states[key]
+	if !exists {
+		state = &driftState{
+			Result:     result,
+			LastChange: now,
+		}
+		s.states[key] = state
+	}
+
+	u := driftUpdate{
+		previous: state.Result,
+		changed:  state.Result != result,
+		snapshot: !exists || !s.enabled() || now.Sub(state.LastReport) >= s.snapshotInterval,
+	}
+
+	if u.changed {
+		state.Result = result
+		state.LastChange = now
+	}
+	if u.report() {
+		state.LastReport = now
+		s.dirty = true
+	}
+	u.lastChange = state.LastChange
+
+	return u
+}
+
+// save drops the states of the resources of a rule that weren't seen by its latest run, whose keys are given, so that
+// the resources that are gone don't accumulate. It then writes the states to the file of the store, unless they
+// didn't change since they were last saved.
+func (s *driftStore) save(ruleID string, seen map[string]driftUpdate) error {
+	s.Lock()
+	defer s.Unlock()
+
+	prefix := ruleID + "|"
+	for key := range s.states {
+		if _, exists := seen[key]; !exists && strings.HasPrefix(key, prefix) {
+			delete(s.states, key)
+			s.dirty = true
+		}
+	}
+
+	if s.path == "" || !s.dirty {
+		return nil
+	}
+
+	content, err := json.Marshal(s.states)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+
+	// write to a temporary file first so that a crash doesn't leave a truncated state behind
+	tmpPath := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, content, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return err
+	}
+
+	s.dirty = false
+	return nil
+}
+
+// resultSeverity orders the results of the events from the least to the most severe
+var resultSeverity = map[string]int{
+	event.Passed: 0,
+	event.Failed: 1,
+	event.Error:  2,
+}
+
+// worstEvent returns the first of the events with the most severe result: error, then failed, then passed
+func worstEvent(events []*event.Event) *event.Event {
+	var worst *event.Event
+	for _, e := range events {
+		if worst == nil || resultSeverity[e.Result] > resultSeverity[worst.Result] {
+			worst = e
+		}
+	}
+	return worst
+}
+
+// worstResult returns the most severe of the results of the events: error, then failed, then passed
+func worstResult(events []*event.Event) string {
+	if worst := worstEvent(events); worst != nil {
+		return worst.Result
+	}
+	return event.Passed
+}
@@ -0,0 +1,152 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestDriftStore(t *testing.T) {
+	assert := assert.New(t)
+
+	store, err := newDriftStore("", time.Hour)
+	assert.NoError(err)
+
+	const key = "rule-id|resource-type|resource-id"
+	start := time.Unix(1600000000, 0)
+
+	u := store.update(key, event.Passed, start)
+	assert.True(u.report())
+	assert.False(u.changed)
+	assert.Equal([]string{driftTagSnapshot}, u.tags())
+	assert.Equal(start, u.lastChange)
+
+	u = store.update(key, event.Passed, start.Add(20*time.Minute))
+	assert.False(u.report())
+	assert.Equal(start, u.lastChange)
+
+	changeTime := start.Add(40 * time.Minute)
+	u = store.update(key, event.Failed, changeTime)
+	assert.True(u.report())
+	assert.True(u.changed)
+	assert.Equal([]string{driftTagChange, "previous_result:passed"}, u.tags())
+	assert.Equal(changeTime, u.lastChange)
+
+	// the snapshot interval is counted from the last report
+	u = store.update(key, event.Failed, start.Add(80*time.Minute))
+	assert.False(u.report())
+
+	u = store.update(key, event.Failed, changeTime.Add(time.Hour))
+	assert.True(u.report())
+	assert.False(u.changed)
+	assert.Equal([]string{driftTagSnapshot}, u.tags())
+	assert.Equal(changeTime, u.lastChange)
+}
+
+func TestDriftStoreDisabled(t *testing.T) {
+	assert := assert.New(t)
+
+	store, err := newDriftStore("", 0)
+	assert.NoError(err)
+
+	const key = "rule-id|resource-type|resource-id"
+	start := time.Unix(1600000000, 0)
+
+	assert.True(store.update(key, event.Passed, start).report())
+	assert.True(store.update(key, event.Passed, start.Add(time.Minute)).report())
+
+	u := store.update(key, event.Error, start.Add(2*time.Minute))
+	assert.True(u.report())
+	assert.Equal(start.Add(2*time.Minute), u.lastChange)
+}
+
+func TestDriftStoreSave(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "compliance-drift-")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	statePath := filepath.Join(dir, "compliance-state.json")
+	store, err := newDriftStore(statePath, time.Hour)
+	assert.NoError(err)
+
+	const key = "rule-id|resource-type|resource-id"
+	start := time.Unix(1600000000, 0)
+
+	seen := map[string]driftUpdate{key: store.update(key, event.Passed, start)}
+	assert.NoError(store.save("rule-id", seen))
+	assert.FileExists(statePath)
+
+	// the states are only written when they changed
+	assert.NoError(os.Remove(statePath))
+	seen[key] = store.update(key, event.Passed, start.Add(time.Minute))
+	assert.NoError(store.save("rule-id", seen))
+	assert.NoFileExists(statePath)
+
+	seen[key] = store.update(key, event.Failed, start.Add(2*time.Minute))
+	assert.NoError(store.save("rule-id", seen))
+	assert.FileExists(statePath)
+}
+
+func TestDriftStorePrune(t *testing.T) {
+	assert := assert.New(t)
+
+	store, err := newDriftStore("", time.Hour)
+	assert.NoError(err)
+
+	keep := driftKey("rule-id", "resource-type", "kept")
+	gone := driftKey("rule-id", "resource-type", "gone")
+	other := driftKey("other-rule-id", "resource-type", "gone")
+	start := time.Unix(1600000000, 0)
+
+	store.update(other, event.Passed, start)
+	assert.NoError(store.save("other-rule-id", map[string]driftUpdate{other: {}}))
+
+	store.update(keep, event.Passed, start)
+	store.update(gone, event.Failed, start)
+	assert.NoError(store.save("rule-id", map[string]driftUpdate{keep: {}, gone: {}}))
+	assert.Len(store.states, 3)
+
+	// the resources that aren't seen anymore by the rule are dropped, the ones of the other rules are kept
+	store.update(keep, event.Passed, start.Add(time.Minute))
+	assert.NoError(store.save("rule-id", map[string]driftUpdate{keep: {}}))
+	assert.Contains(store.states, keep)
+	assert.Contains(store.states, other)
+	assert.NotContains(store.states, gone)
+
+	// a resource that is seen again is reported as new
+	u := store.update(gone, event.Failed, start.Add(2*time.Minute))
+	assert.True(u.report())
+	assert.False(u.changed)
+}
+
+func TestWorstResult(t *testing.T) {
+	tests := []struct {
+		results  []string
+		expected string
+	}{
+		{results: nil, expected: event.Passed},
+		{results: []string{event.Passed, event.Passed}, expected: event.Passed},
+		{results: []string{event.Passed, event.Failed}, expected: event.Failed},
+		{results: []string{event.Failed, event.Error, event.Passed}, expected: event.Error},
+	}
+
+	for _, test := range tests {
+		var events []*event.Event
+		for _, result := range test.results {
+			events = append(events, &event.Event{Result: result})
+		}
+		assert.Equal(t, test.expected, worstResult(events))
+	}
+}
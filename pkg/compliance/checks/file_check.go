@@ -7,7 +7,9 @@ package checks
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 
@@ -15,6 +17,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
 	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"gopkg.in/yaml.v2"
 )
 
 var fileReportedFields = []string{
@@ -77,6 +80,15 @@ func resolveFile(_ context.Context, e env.Env, ruleID string, res compliance.Res
 			instance.Vars[compliance.FileFieldGroup] = group
 		}
 
+		if file.Parser != "" {
+			content, err := readFileContent(path, file.Parser)
+			if err != nil {
+				log.Debugf("%s: file check failed to parse %s [%s]: %v", ruleID, path, relPath, err)
+				continue
+			}
+			instance.Vars[compliance.FileFieldContent] = content
+		}
+
 		instances = append(instances, instance)
 	}
 
@@ -113,3 +125,50 @@ func fileYAML(path string) eval.Function {
 func fileRegexp(path string) eval.Function {
 	return fileQuery(path, regexpGetter)
 }
+
+// readFileContent reads the content of a file and parses it with the given parser
+func readFileContent(path string, parser string) (interface{}, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch parser {
+	case compliance.FileParserJSON:
+		var content interface{}
+		if err := json.Unmarshal(data, &content); err != nil {
+			return nil, err
+		}
+		return content, nil
+	case compliance.FileParserYAML:
+		var content interface{}
+		if err := yaml.Unmarshal(data, &content); err != nil {
+			return nil, err
+		}
+		return normalizeYAMLValue(content), nil
+	case compliance.FileParserRaw:
+		return string(data), nil
+	default:
+		return nil, fmt.Errorf("unsupported file parser %q", parser)
+	}
+}
+
+// normalizeYAMLValue converts the maps decoded from YAML, keyed by any value, to maps keyed by strings as decoded from
+// JSON
+func normalizeYAMLValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[fmt.Sprintf("%v", key)] = normalizeYAMLValue(value)
+		}
+		return m
+	case []interface{}:
+		for i, value := range v {
+			v[i] = normalizeYAMLValue(value)
+		}
+		return v
+	default:
+		return v
+	}
+}
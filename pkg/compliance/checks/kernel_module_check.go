@@ -0,0 +1,124 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	procModulesPath = "/proc/modules"
+	modprobeDirPath = "/etc/modprobe.d"
+)
+
+var kernelModuleReportedFields = []string{
+	compliance.KernelModuleFieldName,
+	compliance.KernelModuleFieldLoaded,
+	compliance.KernelModuleFieldBlacklisted,
+	compliance.KernelModuleFieldDisabled,
+}
+
+func resolveKernelModule(_ context.Context, e env.Env, ruleID string, res compliance.Resource) (interface{}, error) {
+	if res.KernelModule == nil {
+		return nil, fmt.Errorf("%s: expecting kernel module resource in kernel module check", ruleID)
+	}
+
+	module := res.KernelModule
+	name := normalizeModuleName(module.Name)
+
+	log.Debugf("%s: running kernel module check for %q", ruleID, module.Name)
+
+	loaded, err := isModuleLoaded(e.NormalizeToHostRoot(procModulesPath), name)
+	if err != nil {
+		return nil, wrapErrorWithID(ruleID, err)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(e.NormalizeToHostRoot(modprobeDirPath), "*.conf"))
+	if err != nil {
+		return nil, wrapErrorWithID(ruleID, err)
+	}
+
+	var blacklisted, disabled bool
+	for _, path := range paths {
+		b, d, err := readModprobeConf(path, name)
+		if err != nil {
+			log.Debugf("%s: failed to read %s: %v", ruleID, path, err)
+			continue
+		}
+		blacklisted = blacklisted || b
+		disabled = disabled || d
+	}
+
+	return &eval.Instance{
+		Vars: eval.VarMap{
+			compliance.KernelModuleFieldName:        module.Name,
+			compliance.KernelModuleFieldLoaded:      loaded,
+			compliance.KernelModuleFieldBlacklisted: blacklisted,
+			compliance.KernelModuleFieldDisabled:    disabled,
+		},
+	}, nil
+}
+
+// normalizeModuleName returns the name of a module as listed in /proc/modules, where dashes are replaced by
+// underscores
+func normalizeModuleName(name string) string {
+	return strings.Replace(name, "-", "_", -1)
+}
+
+func isModuleLoaded(path string, name string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 0 && fields[0] == name {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+// readModprobeConf returns whether a modprobe configuration file blacklists the module, and whether it disables it by
+// replacing its installation with a command that does nothing
+func readModprobeConf(path string, name string) (blacklisted bool, disabled bool, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || normalizeModuleName(fields[1]) != name {
+			continue
+		}
+
+		switch fields[0] {
+		case "blacklist":
+			blacklisted = true
+		case "install":
+			if len(fields) > 2 && (filepath.Base(fields[2]) == "true" || filepath.Base(fields[2]) == "false") {
+				disabled = true
+			}
+		}
+	}
+	return blacklisted, disabled, scanner.Err()
+}
@@ -0,0 +1,90 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestKernelModuleCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		module    string
+		condition string
+
+		expectReport *compliance.Report
+	}{
+		{
+			name:      "disabled module",
+			module:    "cramfs",
+			condition: `kernelModule.disabled && !kernelModule.loaded`,
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"kernelModule.name":        "cramfs",
+					"kernelModule.loaded":      false,
+					"kernelModule.blacklisted": false,
+					"kernelModule.disabled":    true,
+				},
+			},
+		},
+		{
+			name:      "blacklisted module still loaded",
+			module:    "usb-storage",
+			condition: `kernelModule.blacklisted && !kernelModule.loaded`,
+			expectReport: &compliance.Report{
+				Passed: false,
+				Data: event.Data{
+					"kernelModule.name":        "usb-storage",
+					"kernelModule.loaded":      true,
+					"kernelModule.blacklisted": true,
+					"kernelModule.disabled":    false,
+				},
+			},
+		},
+		{
+			name:      "disabled and blacklisted module",
+			module:    "freevxfs",
+			condition: `kernelModule.blacklisted && kernelModule.disabled`,
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"kernelModule.name":        "freevxfs",
+					"kernelModule.loaded":      false,
+					"kernelModule.blacklisted": true,
+					"kernelModule.disabled":    true,
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			env := newHostRootEnv("./testdata/host")
+
+			resource := compliance.Resource{
+				KernelModule: &compliance.KernelModule{
+					Name: test.module,
+				},
+				Condition: test.condition,
+			}
+
+			moduleCheck, err := newResourceCheck(env, "rule-id", resource)
+			assert.NoError(err)
+
+			result, err := moduleCheck.check(env)
+			assert.NoError(err)
+			assert.Equal(test.expectReport, result)
+		})
+	}
+}
@@ -0,0 +1,400 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	dpkgStatusPath   = "/var/lib/dpkg/status"
+	apkInstalledPath = "/lib/apk/db/installed"
+)
+
+// ErrPackageDatabaseNotFound is returned when none of the supported package databases can be found
+var ErrPackageDatabaseNotFound = errors.New("no supported package database found")
+
+var packageReportedFields = []string{
+	compliance.PackageFieldName,
+	compliance.PackageFieldInstalled,
+	compliance.PackageFieldVersion,
+}
+
+// packageDatabase describes a package database, how to look up a package in it and how to order the versions of
+// its packages
+type packageDatabase struct {
+	path    string
+	lookup  func(f *os.File, name string) (version string, found bool, err error)
+	compare func(a, b string) int
+}
+
+var packageDatabases = []packageDatabase{
+	{path: dpkgStatusPath, lookup: lookupDpkgPackage, compare: compareDpkgVersions},
+	{path: apkInstalledPath, lookup: lookupApkPackage, compare: compareApkVersions},
+}
+
+func resolvePackage(_ context.Context, e env.Env, ruleID string, res compliance.Resource) (interface{}, error) {
+	if res.Package == nil {
+		return nil, fmt.Errorf("%s: expecting package resource in package check", ruleID)
+	}
+
+	pkg := res.Package
+
+	log.Debugf("%s: running package check for %q", ruleID, pkg.Name)
+
+	for _, db := range packageDatabases {
+		f, err := os.Open(e.NormalizeToHostRoot(db.path))
+		if err != nil {
+			continue
+		}
+
+		version, found, err := db.lookup(f, pkg.Name)
+		f.Close()
+		if err != nil {
+			return nil, wrapErrorWithID(ruleID, fmt.Errorf("failed to read %s: %w", db.path, err))
+		}
+
+		return &eval.Instance{
+			Vars: eval.VarMap{
+				compliance.PackageFieldName:      pkg.Name,
+				compliance.PackageFieldInstalled: found,
+				compliance.PackageFieldVersion:   version,
+			},
+			Functions: eval.FunctionMap{
+				compliance.PackageFuncCompareVersion: packageCompareVersion(version, found, db.compare),
+			},
+		}, nil
+	}
+
+	return nil, wrapErrorWithID(ruleID, ErrPackageDatabaseNotFound)
+}
+
+// packageCompareVersion returns a function comparing the installed version of a package to the version given as
+// argument, returning -1, 0 or 1 when the installed version is respectively lower, equal or greater
+func packageCompareVersion(version string, installed bool, compare func(a, b string) int) eval.Function {
+	return func(_ *eval.Instance, args ...interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf(`invalid number of arguments, expecting 1 got %d`, len(args))
+		}
+		other, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf(`expecting string value for version argument`)
+		}
+		if !installed {
+			return nil, errors.New("package not installed")
+		}
+		return compare(version, other), nil
+	}
+}
+
+// lookupDpkgPackage looks for an installed package in the dpkg status file, made of paragraphs of fields separated
+// by empty lines
+func lookupDpkgPackage(f *os.File, name string) (string, bool, error) {
+	var pkg, status, version string
+
+	scanner := bufio.NewScanner(f)
+	for {
+		more := scanner.Scan()
+		line := scanner.Text()
+
+		if !more || len(line) == 0 {
+			if pkg == name && strings.HasSuffix(status, " installed") {
+				return version, true, scanner.Err()
+			}
+			if !more {
+				return "", false, scanner.Err()
+			}
+			pkg, status, version = "", "", ""
+			continue
+		}
+
+		if key, value, ok := splitField(line, ": "); ok {
+			switch key {
+			case "Package":
+				pkg = value
+			case "Status":
+				status = value
+			case "Version":
+				version = value
+			}
+		}
+	}
+}
+
+// lookupApkPackage looks for a package in the apk installed database, made of paragraphs of single letter fields
+// separated by empty lines
+func lookupApkPackage(f *os.File, name string) (string, bool, error) {
+	var pkg, version string
+
+	scanner := bufio.NewScanner(f)
+	for {
+		more := scanner.Scan()
+		line := scanner.Text()
+
+		if !more || len(line) == 0 {
+			if pkg == name {
+				return version, true, scanner.Err()
+			}
+			if !more {
+				return "", false, scanner.Err()
+			}
+			pkg, version = "", ""
+			continue
+		}
+
+		if key, value, ok := splitField(line, ":"); ok {
+			switch key {
+			case "P":
+				pkg = value
+			case "V":
+				version = value
+			}
+		}
+	}
+}
+
+func splitField(line string, sep string) (string, string, bool) {
+	i := strings.Index(line, sep)
+	if i < 0 {
+		return "", "", false
+	}
+	return line[:i], strings.TrimSpace(line[i+len(sep):]), true
+}
+
+// compareDpkgVersions compares two package versions of the form [epoch:]upstream[-revision] following the dpkg
+// algorithm, returning -1, 0 or 1
+func compareDpkgVersions(a, b string) int {
+	aEpoch, aUpstream, aRevision := splitVersion(a)
+	bEpoch, bUpstream, bRevision := splitVersion(b)
+
+	if aEpoch != bEpoch {
+		if aEpoch < bEpoch {
+			return -1
+		}
+		return 1
+	}
+
+	if c := compareVersionPart(aUpstream, bUpstream); c != 0 {
+		return c
+	}
+	return compareVersionPart(aRevision, bRevision)
+}
+
+func splitVersion(version string) (epoch int, upstream string, revision string) {
+	if i := strings.Index(version, ":"); i >= 0 {
+		for _, c := range version[:i] {
+			if c >= '0' && c <= '9' {
+				epoch = epoch*10 + int(c-'0')
+			}
+		}
+		version = version[i+1:]
+	}
+
+	if i := strings.LastIndex(version, "-"); i >= 0 {
+		return epoch, version[:i], version[i+1:]
+	}
+	return epoch, version, ""
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// versionCharOrder returns the weight of a character in the non-digit parts of a version: `~` sorts before
+// everything, even the end of the part, and letters sort before the other characters
+func versionCharOrder(s string, i int) int {
+	if i >= len(s) {
+		return 0
+	}
+
+	c := s[i]
+	switch {
+	case isDigit(c):
+		return 0
+	case isLetter(c):
+		return int(c)
+	case c == '~':
+		return -1
+	default:
+		return int(c) + 256
+	}
+}
+
+// compareVersionPart implements the verrevcmp function of dpkg, alternating the comparison of non-digit prefixes
+// and numeric values
+func compareVersionPart(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		firstDiff := 0
+
+		for (i < len(a) && !isDigit(a[i])) || (j < len(b) && !isDigit(b[j])) {
+			ac, bc := versionCharOrder(a, i), versionCharOrder(b, j)
+			if ac != bc {
+				if ac < bc {
+					return -1
+				}
+				return 1
+			}
+			i++
+			j++
+		}
+
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+
+		for i < len(a) && isDigit(a[i]) && j < len(b) && isDigit(b[j]) {
+			if firstDiff == 0 {
+				firstDiff = int(a[i]) - int(b[j])
+			}
+			i++
+			j++
+		}
+
+		if i < len(a) && isDigit(a[i]) {
+			return 1
+		}
+		if j < len(b) && isDigit(b[j]) {
+			return -1
+		}
+		if firstDiff < 0 {
+			return -1
+		}
+		if firstDiff > 0 {
+			return 1
+		}
+	}
+	return 0
+}
+
+// apkSuffixes gives the order of the apk version suffixes relatively to a release, which has no suffix
+var apkSuffixes = map[string]int{
+	"alpha": -4,
+	"beta":  -3,
+	"pre":   -2,
+	"rc":    -1,
+	"cvs":   1,
+	"svn":   2,
+	"git":   3,
+	"hg":    4,
+	"p":     5,
+}
+
+// apkVersion is a parsed apk version of the form number{.number}[letter]{_suffix[number]}[-rrevision]
+type apkVersion struct {
+	numbers  []string
+	letter   byte
+	suffixes []apkSuffix
+	revision string
+}
+
+type apkSuffix struct {
+	order  int
+	number string
+}
+
+func parseApkVersion(version string) apkVersion {
+	var v apkVersion
+
+	if i := strings.LastIndex(version, "-r"); i >= 0 {
+		v.revision = version[i+2:]
+		version = version[:i]
+	}
+
+	parts := strings.Split(version, "_")
+	for _, suffix := range parts[1:] {
+		i := len(suffix)
+		for i > 0 && isDigit(suffix[i-1]) {
+			i--
+		}
+		v.suffixes = append(v.suffixes, apkSuffix{order: apkSuffixes[suffix[:i]], number: suffix[i:]})
+	}
+
+	version = parts[0]
+	if n := len(version); n > 0 && isLetter(version[n-1]) {
+		v.letter = version[n-1]
+		version = version[:n-1]
+	}
+	v.numbers = strings.Split(version, ".")
+
+	return v
+}
+
+// compareNumbers compares two numbers of arbitrary size written in decimal
+func compareNumbers(a, b string) int {
+	a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareApkVersions compares two apk package versions, returning -1, 0 or 1. Unlike dpkg, the `_alpha`, `_beta`,
+// `_pre` and `_rc` suffixes sort before the release, and the `_cvs`, `_svn`, `_git`, `_hg` and `_p` ones after.
+func compareApkVersions(a, b string) int {
+	av, bv := parseApkVersion(a), parseApkVersion(b)
+
+	for i := 0; i < len(av.numbers) && i < len(bv.numbers); i++ {
+		if c := compareNumbers(av.numbers[i], bv.numbers[i]); c != 0 {
+			return c
+		}
+	}
+	if c := compareInts(len(av.numbers), len(bv.numbers)); c != 0 {
+		return c
+	}
+
+	if c := compareInts(int(av.letter), int(bv.letter)); c != 0 {
+		return c
+	}
+
+	for i := 0; i < len(av.suffixes) || i < len(bv.suffixes); i++ {
+		var as, bs apkSuffix
+		if i < len(av.suffixes) {
+			as = av.suffixes[i]
+		}
+		if i < len(bv.suffixes) {
+			bs = bv.suffixes[i]
+		}
+		if c := compareInts(as.order, bs.order); c != 0 {
+			return c
+		}
+		if c := compareNumbers(as.number, bs.number); c != 0 {
+			return c
+		}
+	}
+
+	return compareNumbers(av.revision, bv.revision)
+}
@@ -0,0 +1,179 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestPackageCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		root      string
+		pkg       string
+		condition string
+
+		expectReport *compliance.Report
+		expectError  bool
+	}{
+		{
+			name:      "dpkg version greater",
+			root:      "./testdata/host",
+			pkg:       "openssh-server",
+			condition: `package.installed && package.compareVersion("1:7.4p1") >= 0`,
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"package.name":      "openssh-server",
+					"package.installed": true,
+					"package.version":   "1:8.2p1-4ubuntu0.2",
+				},
+			},
+		},
+		{
+			name:      "dpkg version lower",
+			root:      "./testdata/host",
+			pkg:       "sudo",
+			condition: `package.compareVersion("1.9.5p2-1") >= 0`,
+			expectReport: &compliance.Report{
+				Passed: false,
+				Data: event.Data{
+					"package.name":      "sudo",
+					"package.installed": true,
+					"package.version":   "1.8.31-1ubuntu1.2",
+				},
+			},
+		},
+		{
+			name:      "dpkg removed package",
+			root:      "./testdata/host",
+			pkg:       "telnet",
+			condition: `!package.installed`,
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"package.name":      "telnet",
+					"package.installed": false,
+					"package.version":   "",
+				},
+			},
+		},
+		{
+			name:      "apk installed package",
+			root:      "./testdata/alpine",
+			pkg:       "musl",
+			condition: `package.compareVersion("1.2.2-r0") == 0`,
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"package.name":      "musl",
+					"package.installed": true,
+					"package.version":   "1.2.2-r0",
+				},
+			},
+		},
+		{
+			name:      "apk version greater than a release candidate",
+			root:      "./testdata/alpine",
+			pkg:       "musl",
+			condition: `package.compareVersion("1.2.2_rc1-r0") > 0`,
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"package.name":      "musl",
+					"package.installed": true,
+					"package.version":   "1.2.2-r0",
+				},
+			},
+		},
+		{
+			name:        "no package database",
+			root:        "./testdata/group",
+			pkg:         "musl",
+			condition:   `package.installed`,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			env := newHostRootEnv(test.root)
+
+			resource := compliance.Resource{
+				Package: &compliance.Package{
+					Name: test.pkg,
+				},
+				Condition: test.condition,
+			}
+
+			packageCheck, err := newResourceCheck(env, "rule-id", resource)
+			assert.NoError(err)
+
+			result, err := packageCheck.check(env)
+			assert.Equal(test.expectReport, result)
+			assert.Equal(test.expectError, err != nil)
+		})
+	}
+}
+
+func TestCompareDpkgVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.0", "1.0", 0},
+		{"1.0", "1.1", -1},
+		{"1.10", "1.9", 1},
+		{"1:1.0", "2.0", 1},
+		{"1.0~rc1", "1.0", -1},
+		{"1.0", "1.0a", -1},
+		{"1.0-1", "1.0-2", -1},
+		{"8.2p1-4ubuntu0.2", "8.2p1-4", 1},
+		{"1.01", "1.1", 0},
+		{"1.2.2-r0", "1.2.10-r0", -1},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, compareDpkgVersions(test.a, test.b), "%s <=> %s", test.a, test.b)
+	}
+}
+
+func TestCompareApkVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.2.2-r0", "1.2.2-r0", 0},
+		{"1.2.2-r0", "1.2.10-r0", -1},
+		{"1.2.2-r1", "1.2.2-r10", -1},
+		{"1.2.2", "1.2.2-r1", -1},
+		{"1.2", "1.2.1", -1},
+		{"1.01", "1.1", 0},
+		{"1.2.2a", "1.2.2", 1},
+		{"1.2.2a", "1.2.2b", -1},
+		{"1.2.2_rc1", "1.2.2", -1},
+		{"1.2.2_rc2", "1.2.2_rc10", -1},
+		{"1.2.2_alpha1", "1.2.2_beta", -1},
+		{"1.2.2_pre1", "1.2.2_rc1", -1},
+		{"1.2.2_p1", "1.2.2", 1},
+		{"1.2.2_p1", "1.2.2_p1-r1", -1},
+		{"1.2.2_git20210101", "1.2.2_p1", -1},
+		{"1.2.2_rc1_p1", "1.2.2_rc1", 1},
+		{"1.2.2_rc1-r5", "1.2.2-r0", -1},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, compareApkVersions(test.a, test.b), "%s <=> %s", test.a, test.b)
+		assert.Equal(t, -test.expected, compareApkVersions(test.b, test.a), "%s <=> %s", test.b, test.a)
+	}
+}
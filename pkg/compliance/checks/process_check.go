@@ -53,6 +53,7 @@ func resolveProcess(_ context.Context, e env.Env, id string, res compliance.Reso
 				compliance.ProcessFieldName:    mp.Name,
 				compliance.ProcessFieldExe:     mp.Exe,
 				compliance.ProcessFieldCmdLine: mp.Cmdline,
+				compliance.ProcessFieldFlags:   flagValues,
 			},
 			Functions: eval.FunctionMap{
 				compliance.ProcessFuncFlag:    processFlag(flagValues),
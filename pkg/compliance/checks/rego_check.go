@@ -0,0 +1,258 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/open-policy-agent/opa/rego"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+)
+
+const (
+	regoFindingStatus       = "status"
+	regoFindingData         = "data"
+	regoFindingResourceType = "resource_type"
+	regoFindingResourceID   = "resource_id"
+)
+
+// regoInput is a resource fed to a Rego rule along with its resolver
+type regoInput struct {
+	compliance.RegoInput
+	resolve resolveFunc
+}
+
+// regoCheck evaluates a Rego module against the instances of the resources declared as its input. Each finding of
+// the module is reported as a distinct result.
+type regoCheck struct {
+	ruleID string
+	inputs []regoInput
+	query  rego.PreparedEvalQuery
+}
+
+func newRegoCheck(env env.Env, ruleID string, rule *compliance.Rule) (*regoCheck, error) {
+	if len(rule.Input) == 0 {
+		return nil, fmt.Errorf("%s: missing input for rego rule", ruleID)
+	}
+
+	var inputs []regoInput
+	for _, input := range rule.Input {
+		if len(input.Tag) == 0 {
+			return nil, fmt.Errorf("%s: missing tag for rego input of kind %s", ruleID, input.Kind())
+		}
+
+		if err := checkResourceClient(env, ruleID, input.Kind()); err != nil {
+			return nil, err
+		}
+
+		resolve, _, err := resourceKindToResolverAndFields(input.Kind())
+		if err != nil {
+			return nil, fmt.Errorf("%s: unsupported rego input of kind %s: %w", ruleID, input.Kind(), err)
+		}
+
+		inputs = append(inputs, regoInput{
+			RegoInput: input,
+			resolve:   resolve,
+		})
+	}
+
+	findings := rule.Findings
+	if len(findings) == 0 {
+		findings = compliance.DefaultRegoFindings
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	query, err := rego.New(
+		rego.Query(findings),
+		rego.Module(ruleID+".rego", rule.Module),
+	).PrepareForEval(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to compile rego module: %w", ruleID, err)
+	}
+
+	return &regoCheck{
+		ruleID: ruleID,
+		inputs: inputs,
+		query:  query,
+	}, nil
+}
+
+// check aggregates the findings of the module into a single report, the first one with the worst result
+func (c *regoCheck) check(env env.Env) (*compliance.Report, error) {
+	reports, err := c.checkReports(env)
+	if err != nil {
+		return nil, err
+	}
+	return worstReport(reports), nil
+}
+
+// worstReport returns the first of the reports with the most severe result: error, then failed, then passed
+func worstReport(reports []*compliance.Report) *compliance.Report {
+	var worst *compliance.Report
+	for _, report := range reports {
+		if worst == nil || resultSeverity[eventResult(report.Passed, report.Error)] > resultSeverity[eventResult(worst.Passed, worst.Error)] {
+			worst = report
+		}
+	}
+	return worst
+}
+
+func (c *regoCheck) checkReports(env env.Env) ([]*compliance.Report, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	input, err := c.buildInput(ctx, env)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := c.query.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		return nil, wrapErrorWithID(c.ruleID, err)
+	}
+
+	return regoResultsToReports(results)
+}
+
+// buildInput resolves the resources of the rule into the input document, each resource being set as the list of its
+// instances under its tag
+func (c *regoCheck) buildInput(ctx context.Context, env env.Env) (map[string]interface{}, error) {
+	input := map[string]interface{}{
+		"context": map[string]interface{}{
+			"hostname": env.Hostname(),
+			"ruleID":   c.ruleID,
+		},
+	}
+
+	for _, in := range c.inputs {
+		resolved, err := in.resolve(ctx, env, c.ruleID, in.Resource)
+		if err != nil {
+			return nil, err
+		}
+
+		var instances []interface{}
+		switch resolved := resolved.(type) {
+		case *eval.Instance:
+			instances = append(instances, instanceToRegoValue(resolved))
+		case eval.Iterator:
+			for !resolved.Done() {
+				instance, err := resolved.Next()
+				if err != nil {
+					return nil, err
+				}
+				instances = append(instances, instanceToRegoValue(instance))
+			}
+		default:
+			return nil, ErrResourceFailedToResolve
+		}
+
+		if existing, ok := input[in.Tag].([]interface{}); ok {
+			instances = append(existing, instances...)
+		}
+		input[in.Tag] = instances
+	}
+
+	return input, nil
+}
+
+// instanceToRegoValue converts the fields of an instance to an object, nesting the dot separated parts of their names.
+// The `file.path` field is available as `file.path` in Rego too.
+func instanceToRegoValue(instance *eval.Instance) map[string]interface{} {
+	value := make(map[string]interface{})
+	for name, v := range instance.Vars {
+		parts := strings.Split(name, ".")
+
+		current := value
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := current[part].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				current[part] = next
+			}
+			current = next
+		}
+		current[parts[len(parts)-1]] = v
+	}
+	return value
+}
+
+// regoResultsToReports converts the findings of a Rego module to reports. The findings query can either evaluate to
+// a boolean, or to a set of findings objects holding a `status` (passed, failed or error) and `data` reported as is,
+// along with the optional `resource_type` and `resource_id` of the finding. An empty set of findings is reported as
+// passed.
+func regoResultsToReports(results rego.ResultSet) ([]*compliance.Report, error) {
+	if len(results) == 0 || len(results[0].Expressions) == 0 {
+		return nil, errors.New("rego findings query is undefined")
+	}
+
+	switch value := results[0].Expressions[0].Value.(type) {
+	case bool:
+		return []*compliance.Report{{Passed: value}}, nil
+	case []interface{}:
+		if len(value) == 0 {
+			return []*compliance.Report{{Passed: true}}, nil
+		}
+
+		var reports []*compliance.Report
+		for _, v := range value {
+			report, err := regoFindingToReport(v)
+			if err != nil {
+				return nil, err
+			}
+			reports = append(reports, report)
+		}
+		return reports, nil
+	default:
+		return nil, fmt.Errorf("unexpected rego findings of type %T", value)
+	}
+}
+
+func regoFindingToReport(value interface{}) (*compliance.Report, error) {
+	finding, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected rego finding of type %T", value)
+	}
+
+	report := &compliance.Report{
+		Data: event.Data{},
+	}
+
+	if data, ok := finding[regoFindingData].(map[string]interface{}); ok {
+		for k, v := range data {
+			report.Data[k] = v
+		}
+	}
+
+	if resourceID, ok := finding[regoFindingResourceID].(string); ok {
+		report.ResourceID = resourceID
+		report.ResourceType, _ = finding[regoFindingResourceType].(string)
+	}
+
+	switch status := finding[regoFindingStatus]; status {
+	case event.Passed:
+		report.Passed = true
+	case event.Failed:
+	case event.Error:
+		if err, ok := report.Data["error"].(string); ok {
+			report.Error = errors.New(err)
+		} else {
+			report.Error = errors.New("rego finding reported an error")
+		}
+	default:
+		return nil, fmt.Errorf("unexpected rego finding status %v", status)
+	}
+
+	return report, nil
+}
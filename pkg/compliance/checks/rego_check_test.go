@@ -0,0 +1,192 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+)
+
+const testRegoModule = `
+package datadog
+
+kubelet = input.kubelet[0].file.content
+apiserver = input.apiserver[0].file.content
+
+findings[f] {
+	kubelet.authorization.mode == "AlwaysAllow"
+	contains(apiserver["authorization-mode"], "Node")
+	f := {
+		"status": "failed",
+		"data": {
+			"kubelet.authorization.mode": kubelet.authorization.mode,
+			"apiserver.authorization.mode": apiserver["authorization-mode"],
+		},
+	}
+}
+
+findings[f] {
+	kubelet.authentication.anonymous.enabled == false
+	apiserver["anonymous-auth"] == "false"
+	f := {
+		"status": "passed",
+		"data": {
+			"host": input.context.hostname,
+		},
+	}
+}
+`
+
+// newRegoTestEnv returns an env whose host root is mounted on ./testdata/rego
+func newRegoTestEnv() *mocks.Env {
+	mapper := pathMapper{hostMountPath: "./testdata/rego"}
+	env := &mocks.Env{}
+	env.On("NormalizeToHostRoot", mock.AnythingOfType("string")).Return(mapper.normalizeToHostRoot)
+	env.On("RelativeToHostRoot", mock.AnythingOfType("string")).Return(mapper.relativeToHostRoot)
+	env.On("Hostname").Return("the-host")
+	return env
+}
+
+func newRegoTestRule(module string, findings string) *compliance.Rule {
+	return &compliance.Rule{
+		ID: "rule-id",
+		Input: []compliance.RegoInput{
+			{
+				Resource: compliance.Resource{
+					File: &compliance.File{
+						Path:   "/kubelet-config.yaml",
+						Parser: compliance.FileParserYAML,
+					},
+				},
+				Tag: "kubelet",
+			},
+			{
+				Resource: compliance.Resource{
+					File: &compliance.File{
+						Path:   "/apiserver-flags.json",
+						Parser: compliance.FileParserJSON,
+					},
+				},
+				Tag: "apiserver",
+			},
+		},
+		Module:   module,
+		Findings: findings,
+	}
+}
+
+func TestRegoCheck(t *testing.T) {
+	assert := assert.New(t)
+
+	env := newRegoTestEnv()
+
+	regoCheck, err := newRegoCheck(env, "rule-id", newRegoTestRule(testRegoModule, ""))
+	assert.NoError(err)
+
+	reports, err := regoCheck.checkReports(env)
+	assert.NoError(err)
+	assert.Len(reports, 2)
+
+	expected := map[bool]event.Data{
+		false: {
+			"kubelet.authorization.mode":   "AlwaysAllow",
+			"apiserver.authorization.mode": "Node,RBAC",
+		},
+		true: {
+			"host": "the-host",
+		},
+	}
+	for _, report := range reports {
+		assert.NoError(report.Error)
+		assert.Equal(expected[report.Passed], report.Data)
+	}
+
+	// a single report aggregates the findings with the worst of their results
+	report, err := regoCheck.check(env)
+	assert.NoError(err)
+	assert.False(report.Passed)
+	assert.Equal(expected[false], report.Data)
+}
+
+func TestRegoCheckBooleanFindings(t *testing.T) {
+	assert := assert.New(t)
+
+	env := newRegoTestEnv()
+
+	module := `
+package datadog
+
+allow {
+	input.kubelet[0].file.content.readOnlyPort == 0
+}
+`
+	regoCheck, err := newRegoCheck(env, "rule-id", newRegoTestRule(module, "data.datadog.allow"))
+	assert.NoError(err)
+
+	report, err := regoCheck.check(env)
+	assert.NoError(err)
+	assert.True(report.Passed)
+}
+
+func TestRegoCheckErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	env := newRegoTestEnv()
+
+	_, err := newRegoCheck(env, "rule-id", newRegoTestRule("package datadog\n\nfindings[f] {", ""))
+	assert.Error(err)
+
+	rule := newRegoTestRule(testRegoModule, "")
+	rule.Input[0].Tag = ""
+	_, err = newRegoCheck(env, "rule-id", rule)
+	assert.Error(err)
+
+	module := `
+package datadog
+
+findings[f] {
+	f := {"status": "error", "data": {"error": "missing kubelet configuration"}}
+}
+`
+	regoCheck, err := newRegoCheck(env, "rule-id", newRegoTestRule(module, ""))
+	assert.NoError(err)
+
+	reports, err := regoCheck.checkReports(env)
+	assert.NoError(err)
+	assert.Len(reports, 1)
+	assert.EqualError(reports[0].Error, "missing kubelet configuration")
+}
+
+func TestInstanceToRegoValue(t *testing.T) {
+	assert := assert.New(t)
+
+	value := instanceToRegoValue(&eval.Instance{
+		Vars: eval.VarMap{
+			"process.name":       "kube-apiserver",
+			"process.flags":      map[string]string{"--anonymous-auth": "false"},
+			"kube.resource.name": "default",
+		},
+	})
+
+	assert.Equal(map[string]interface{}{
+		"process": map[string]interface{}{
+			"name":  "kube-apiserver",
+			"flags": map[string]string{"--anonymous-auth": "false"},
+		},
+		"kube": map[string]interface{}{
+			"resource": map[string]interface{}{
+				"name": "default",
+			},
+		},
+	}, value)
+}
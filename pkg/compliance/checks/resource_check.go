@@ -105,21 +105,12 @@ func newResourceCheck(env env.Env, ruleID string, resource compliance.Resource)
 	// TODO: validate resource here
 	kind := resource.Kind()
 
-	switch kind {
-	case compliance.KindCustom:
+	if kind == compliance.KindCustom {
 		return newCustomCheck(ruleID, resource)
-	case compliance.KindAudit:
-		if env.AuditClient() == nil {
-			return nil, log.Errorf("%s: audit client not initialized", ruleID)
-		}
-	case compliance.KindDocker:
-		if env.DockerClient() == nil {
-			return nil, log.Errorf("%s: docker client not initialized", ruleID)
-		}
-	case compliance.KindKubernetes:
-		if env.KubeClient() == nil {
-			return nil, log.Errorf("%s: kube client not initialized", ruleID)
-		}
+	}
+
+	if err := checkResourceClient(env, ruleID, kind); err != nil {
+		return nil, err
 	}
 
 	resolve, reportedFields, err := resourceKindToResolverAndFields(kind)
@@ -144,6 +135,25 @@ func newResourceCheck(env env.Env, ruleID string, resource compliance.Resource)
 	}, nil
 }
 
+// checkResourceClient returns an error when the client needed to resolve a resource kind isn't initialized
+func checkResourceClient(env env.Env, ruleID string, kind compliance.ResourceKind) error {
+	switch kind {
+	case compliance.KindAudit:
+		if env.AuditClient() == nil {
+			return log.Errorf("%s: audit client not initialized", ruleID)
+		}
+	case compliance.KindDocker:
+		if env.DockerClient() == nil {
+			return log.Errorf("%s: docker client not initialized", ruleID)
+		}
+	case compliance.KindKubernetes:
+		if env.KubeClient() == nil {
+			return log.Errorf("%s: kube client not initialized", ruleID)
+		}
+	}
+	return nil
+}
+
 func resourceKindToResolverAndFields(kind compliance.ResourceKind) (resolveFunc, []string, error) {
 	switch kind {
 	case compliance.KindFile:
@@ -160,6 +170,14 @@ func resourceKindToResolverAndFields(kind compliance.ResourceKind) (resolveFunc,
 		return resolveDocker, dockerReportedFields, nil
 	case compliance.KindKubernetes:
 		return resolveKubeapiserver, kubeResourceReportedFields, nil
+	case compliance.KindSysctl:
+		return resolveSysctl, sysctlReportedFields, nil
+	case compliance.KindKernelModule:
+		return resolveKernelModule, kernelModuleReportedFields, nil
+	case compliance.KindSystemdUnit:
+		return resolveSystemdUnit, systemdUnitReportedFields, nil
+	case compliance.KindPackage:
+		return resolvePackage, packageReportedFields, nil
 	default:
 		return nil, nil, ErrResourceKindNotSupported
 	}
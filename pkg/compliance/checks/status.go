@@ -7,6 +7,7 @@ package checks
 
 import (
 	"sync"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/compliance"
 	"github.com/DataDog/datadog-agent/pkg/compliance/event"
@@ -34,7 +35,8 @@ func (s *status) addCheck(checkStatus *compliance.CheckStatus) {
 	s.checks[checkStatus.RuleID] = checkStatus
 }
 
-func (s *status) updateCheck(ruleID string, event *event.Event) {
+// updateCheck records the events of the last run of a check. The worst of them is kept as the last event of the check.
+func (s *status) updateCheck(ruleID string, events []*event.Event, lastChange time.Time) {
 	s.Lock()
 	defer s.Unlock()
 
@@ -47,7 +49,11 @@ func (s *status) updateCheck(ruleID string, event *event.Event) {
 		log.Errorf("Check with ruleID=%s has nil stats", ruleID)
 		return
 	}
-	stats.LastEvent = event
+	stats.LastEvent = worstEvent(events)
+	stats.LastEvents = events
+	if !lastChange.IsZero() {
+		stats.LastChange = lastChange.Unix()
+	}
 }
 
 func (s *status) getChecksStatus() compliance.CheckStatusList {
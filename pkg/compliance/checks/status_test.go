@@ -8,6 +8,7 @@ package checks
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/compliance"
 	"github.com/DataDog/datadog-agent/pkg/compliance/event"
@@ -35,13 +36,17 @@ func TestStatus(t *testing.T) {
 		Version:   "version",
 	})
 
-	status.updateCheck("rule-2", &event.Event{
-		Result: "passed",
-	})
+	lastChange := time.Unix(1600000000, 0)
 
-	status.updateCheck("rule-3", &event.Event{
-		Result: "passed",
-	})
+	// the worst result of the resources checked by the rule is kept
+	events := []*event.Event{
+		{ResourceID: "node-1", Result: "passed"},
+		{ResourceID: "node-2", Result: "failed"},
+		{ResourceID: "node-3", Result: "passed"},
+	}
+	status.updateCheck("rule-2", events, lastChange)
+
+	status.updateCheck("rule-3", []*event.Event{{Result: "passed"}}, lastChange)
 
 	assert.Equal(
 		t,
@@ -61,8 +66,11 @@ func TestStatus(t *testing.T) {
 				Source:    "source",
 				Version:   "version",
 				LastEvent: &event.Event{
-					Result: "passed",
+					ResourceID: "node-2",
+					Result:     "failed",
 				},
+				LastEvents: events,
+				LastChange: 1600000000,
 			},
 		},
 		status.getChecksStatus(),
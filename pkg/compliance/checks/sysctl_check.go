@@ -0,0 +1,63 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const procSysPath = "/proc/sys"
+
+var sysctlReportedFields = []string{
+	compliance.SysctlFieldName,
+	compliance.SysctlFieldValue,
+}
+
+func resolveSysctl(_ context.Context, e env.Env, ruleID string, res compliance.Resource) (interface{}, error) {
+	if res.Sysctl == nil {
+		return nil, fmt.Errorf("%s: expecting sysctl resource in sysctl check", ruleID)
+	}
+
+	sysctl := res.Sysctl
+
+	log.Debugf("%s: running sysctl check for %q", ruleID, sysctl.Name)
+
+	path := e.NormalizeToHostRoot(sysctlPath(sysctl.Name))
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, wrapErrorWithID(ruleID, fmt.Errorf("failed to read kernel parameter %q: %w", sysctl.Name, err))
+	}
+
+	return &eval.Instance{
+		Vars: eval.VarMap{
+			compliance.SysctlFieldName:  sysctl.Name,
+			compliance.SysctlFieldValue: normalizeSysctlValue(string(content)),
+		},
+	}, nil
+}
+
+// sysctlPath returns the path of a kernel parameter in /proc/sys, its name being either dot or slash separated
+func sysctlPath(name string) string {
+	if !strings.Contains(name, "/") {
+		name = strings.Replace(name, ".", "/", -1)
+	}
+	return filepath.Join(procSysPath, name)
+}
+
+// normalizeSysctlValue joins the fields of multi-valued parameters with a single space, as sysctl does, since /proc
+// separates them with tabs
+func normalizeSysctlValue(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
@@ -0,0 +1,112 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+)
+
+// newHostRootEnv returns an environment whose host root is a testdata directory faking /proc, /etc and the package
+// databases
+func newHostRootEnv(root string) *mocks.Env {
+	env := &mocks.Env{}
+	env.On("NormalizeToHostRoot", mock.AnythingOfType("string")).Return(func(path string) string {
+		return filepath.Join(root, path)
+	})
+	return env
+}
+
+func TestSysctlCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource compliance.Resource
+
+		expectReport *compliance.Report
+		expectError  bool
+	}{
+		{
+			name: "dot separated name",
+			resource: compliance.Resource{
+				Sysctl: &compliance.Sysctl{
+					Name: "net.ipv4.ip_forward",
+				},
+				Condition: `sysctl.value == "0"`,
+			},
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"sysctl.name":  "net.ipv4.ip_forward",
+					"sysctl.value": "0",
+				},
+			},
+		},
+		{
+			name: "slash separated name",
+			resource: compliance.Resource{
+				Sysctl: &compliance.Sysctl{
+					Name: "kernel/randomize_va_space",
+				},
+				Condition: `sysctl.value == "1"`,
+			},
+			expectReport: &compliance.Report{
+				Passed: false,
+				Data: event.Data{
+					"sysctl.name":  "kernel/randomize_va_space",
+					"sysctl.value": "2",
+				},
+			},
+		},
+		{
+			name: "multiple values",
+			resource: compliance.Resource{
+				Sysctl: &compliance.Sysctl{
+					Name: "net.ipv4.ip_local_port_range",
+				},
+				Condition: `sysctl.value == "32768 60999"`,
+			},
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"sysctl.name":  "net.ipv4.ip_local_port_range",
+					"sysctl.value": "32768 60999",
+				},
+			},
+		},
+		{
+			name: "unknown parameter",
+			resource: compliance.Resource{
+				Sysctl: &compliance.Sysctl{
+					Name: "net.ipv4.unknown",
+				},
+				Condition: `sysctl.value == "0"`,
+			},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			env := newHostRootEnv("./testdata/host")
+
+			sysctlCheck, err := newResourceCheck(env, "rule-id", test.resource)
+			assert.NoError(err)
+
+			result, err := sysctlCheck.check(env)
+			assert.Equal(test.expectReport, result)
+			assert.Equal(test.expectError, err != nil)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const systemdConfigDir = "/etc/systemd/system"
+
+// systemdUnitDirs lists the directories units are loaded from, by order of precedence
+var systemdUnitDirs = []string{
+	systemdConfigDir,
+	"/run/systemd/system",
+	"/lib/systemd/system",
+	"/usr/lib/systemd/system",
+}
+
+var systemdUnitReportedFields = []string{
+	compliance.SystemdUnitFieldName,
+	compliance.SystemdUnitFieldExists,
+	compliance.SystemdUnitFieldEnabled,
+	compliance.SystemdUnitFieldMasked,
+}
+
+func resolveSystemdUnit(_ context.Context, e env.Env, ruleID string, res compliance.Resource) (interface{}, error) {
+	if res.SystemdUnit == nil {
+		return nil, fmt.Errorf("%s: expecting systemd unit resource in systemd unit check", ruleID)
+	}
+
+	unit := res.SystemdUnit
+	name := unit.Name
+	if !strings.Contains(name, ".") {
+		name += ".service"
+	}
+
+	log.Debugf("%s: running systemd unit check for %q", ruleID, name)
+
+	var exists, masked bool
+	for _, dir := range systemdUnitDirs {
+		path := filepath.Join(e.NormalizeToHostRoot(dir), name)
+		if _, err := os.Lstat(path); err != nil {
+			continue
+		}
+
+		// the first unit file found overrides the others, a unit linked to /dev/null is masked
+		if target, err := os.Readlink(path); err == nil && target == os.DevNull {
+			masked = true
+		} else {
+			exists = true
+		}
+		break
+	}
+
+	// a unit is enabled when it's wanted or required by a target through a symlink in the configuration directory
+	var enabled bool
+	for _, pattern := range []string{"*.wants", "*.requires"} {
+		paths, err := filepath.Glob(filepath.Join(e.NormalizeToHostRoot(systemdConfigDir), pattern, name))
+		if err != nil {
+			return nil, wrapErrorWithID(ruleID, err)
+		}
+		if len(paths) > 0 {
+			enabled = true
+			break
+		}
+	}
+
+	return &eval.Instance{
+		Vars: eval.VarMap{
+			compliance.SystemdUnitFieldName:    name,
+			compliance.SystemdUnitFieldExists:  exists,
+			compliance.SystemdUnitFieldEnabled: enabled && exists,
+			compliance.SystemdUnitFieldMasked:  masked,
+		},
+	}, nil
+}
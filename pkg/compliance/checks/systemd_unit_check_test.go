@@ -0,0 +1,104 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestSystemdUnitCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		unit      string
+		condition string
+
+		expectReport *compliance.Report
+	}{
+		{
+			name:      "enabled unit",
+			unit:      "rsyslog.service",
+			condition: `systemdUnit.enabled`,
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"systemdUnit.name":    "rsyslog.service",
+					"systemdUnit.exists":  true,
+					"systemdUnit.enabled": true,
+					"systemdUnit.masked":  false,
+				},
+			},
+		},
+		{
+			name:      "disabled unit without suffix",
+			unit:      "chrony",
+			condition: `systemdUnit.enabled`,
+			expectReport: &compliance.Report{
+				Passed: false,
+				Data: event.Data{
+					"systemdUnit.name":    "chrony.service",
+					"systemdUnit.exists":  true,
+					"systemdUnit.enabled": false,
+					"systemdUnit.masked":  false,
+				},
+			},
+		},
+		{
+			name:      "masked unit",
+			unit:      "avahi-daemon.service",
+			condition: `systemdUnit.masked || !systemdUnit.exists`,
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"systemdUnit.name":    "avahi-daemon.service",
+					"systemdUnit.exists":  false,
+					"systemdUnit.enabled": false,
+					"systemdUnit.masked":  true,
+				},
+			},
+		},
+		{
+			name:      "missing unit",
+			unit:      "telnet.socket",
+			condition: `!systemdUnit.exists`,
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"systemdUnit.name":    "telnet.socket",
+					"systemdUnit.exists":  false,
+					"systemdUnit.enabled": false,
+					"systemdUnit.masked":  false,
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			env := newHostRootEnv("./testdata/host")
+
+			resource := compliance.Resource{
+				SystemdUnit: &compliance.SystemdUnit{
+					Name: test.unit,
+				},
+				Condition: test.condition,
+			}
+
+			unitCheck, err := newResourceCheck(env, "rule-id", resource)
+			assert.NoError(err)
+
+			result, err := unitCheck.check(env)
+			assert.NoError(err)
+			assert.Equal(test.expectReport, result)
+		})
+	}
+}
@@ -15,4 +15,10 @@ type Report struct {
 	Data event.Data
 	// Passed defines whether check was successful or not
 	Passed bool
+	// Error is set when the evaluation failed, for checks reporting several results at once
+	Error error
+	// ResourceType and ResourceID identify the resource of the report, for checks reporting several results at once.
+	// The resource of the check is used when they are not set.
+	ResourceType string
+	ResourceID   string
 }
@@ -0,0 +1,116 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package report
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+)
+
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Errors     int              `xml:"errors,attr"`
+	Skipped    int              `xml:"skipped,attr"`
+	Timestamp  string           `xml:"timestamp,attr"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Hostname  string          `xml:"hostname,attr,omitempty"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+func writeJUnit(w io.Writer, results *Results) error {
+	suites := junitTestSuites{
+		Name:      toolName,
+		Timestamp: results.Generated.UTC().Format("2006-01-02T15:04:05"),
+	}
+
+	// rules are grouped by framework, keeping the order in which the frameworks appear
+	indexes := make(map[string]int)
+	for _, r := range results.Rules {
+		i, exists := indexes[r.Framework]
+		if !exists {
+			i = len(suites.TestSuites)
+			indexes[r.Framework] = i
+			suites.TestSuites = append(suites.TestSuites, junitTestSuite{
+				Name:     r.Framework,
+				Hostname: results.Hostname,
+			})
+		}
+		suite := &suites.TestSuites[i]
+
+		testCase := junitTestCase{
+			Name:      r.RuleID,
+			ClassName: r.Framework,
+			SystemOut: r.evidenceText(),
+		}
+
+		details := r.Description
+		if len(r.Remediation) > 0 {
+			details = strings.TrimSpace(details + "\n\nRemediation: " + r.Remediation)
+		}
+
+		switch r.Result {
+		case event.Failed:
+			testCase.Failure = &junitMessage{Message: r.message(), Text: details}
+			suite.Failures++
+		case event.Error:
+			testCase.Error = &junitMessage{Message: r.message(), Text: details}
+			suite.Errors++
+		case Skipped:
+			testCase.Skipped = &junitMessage{Message: r.message()}
+			suite.Skipped++
+		}
+
+		suite.Tests++
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	for _, suite := range suites.TestSuites {
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Skipped += suite.Skipped
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
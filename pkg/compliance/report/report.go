@@ -0,0 +1,211 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package report implements the local reporting of compliance check results, aggregated per rule
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+)
+
+// Format defines the format of a report
+type Format string
+
+const (
+	// FormatJSON is a JSON document holding the results per rule
+	FormatJSON Format = "json"
+	// FormatSARIF is a SARIF 2.1.0 log
+	FormatSARIF Format = "sarif"
+	// FormatJUnit is a JUnit XML report, with a test suite per framework and a test case per rule
+	FormatJUnit Format = "junit"
+)
+
+// Formats lists the supported report formats
+var Formats = []Format{FormatJSON, FormatSARIF, FormatJUnit}
+
+// ParseFormat returns the report format matching a name
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats {
+		if string(format) == strings.ToLower(name) {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported report format %q, expecting one of %v", name, Formats)
+}
+
+const toolName = "datadog-security-agent"
+
+// Skipped is the result of a rule that wasn't evaluated, usually because it doesn't apply to the host
+const Skipped = "skipped"
+
+// resultSeverity orders the results so that the result of a rule is the worst result of its checks
+var resultSeverity = map[string]int{
+	Skipped:      0,
+	event.Passed: 1,
+	event.Failed: 2,
+	event.Error:  3,
+}
+
+// Evidence holds the resource fields a check was evaluated against
+type Evidence struct {
+	ResourceType string      `json:"resource_type,omitempty"`
+	ResourceID   string      `json:"resource_id,omitempty"`
+	Result       string      `json:"result"`
+	Data         interface{} `json:"data,omitempty"`
+}
+
+// RuleResult holds the aggregated result of a rule
+type RuleResult struct {
+	RuleID      string     `json:"rule_id"`
+	Description string     `json:"description,omitempty"`
+	Framework   string     `json:"framework,omitempty"`
+	Version     string     `json:"version,omitempty"`
+	Source      string     `json:"source,omitempty"`
+	Result      string     `json:"result"`
+	Error       string     `json:"error,omitempty"`
+	Remediation string     `json:"remediation,omitempty"`
+	Evidence    []Evidence `json:"evidence,omitempty"`
+}
+
+// Summary counts the rules per result
+type Summary struct {
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Error   int `json:"error"`
+	Skipped int `json:"skipped"`
+}
+
+// Results holds the results of a compliance check run
+type Results struct {
+	Generated time.Time     `json:"generated"`
+	Hostname  string        `json:"hostname,omitempty"`
+	Summary   Summary       `json:"summary"`
+	Rules     []*RuleResult `json:"rules"`
+}
+
+// NewResults aggregates the status of the checks per rule. A rule listed in several suites has a single result, the
+// worst of its checks.
+func NewResults(hostname string, statuses compliance.CheckStatusList, generated time.Time) *Results {
+	results := &Results{
+		Generated: generated,
+		Hostname:  hostname,
+		Rules:     []*RuleResult{},
+	}
+
+	rules := make(map[string]*RuleResult)
+	for _, check := range statuses {
+		result, exists := rules[check.RuleID]
+		if !exists {
+			result = &RuleResult{
+				RuleID:      check.RuleID,
+				Description: check.Description,
+				Framework:   check.Framework,
+				Version:     check.Version,
+				Source:      check.Source,
+				Result:      Skipped,
+				Remediation: check.Remediation,
+			}
+			rules[check.RuleID] = result
+			results.Rules = append(results.Rules, result)
+		}
+
+		if err := check.InitError; err != nil && check.LastEvent == nil {
+			if err != checks.ErrRuleDoesNotApply {
+				result.merge(event.Error)
+				result.Error = err.Error()
+			}
+			continue
+		}
+
+		// each resource checked by the last run is an evidence
+		events := check.LastEvents
+		if len(events) == 0 && check.LastEvent != nil {
+			events = []*event.Event{check.LastEvent}
+		}
+
+		for _, e := range events {
+			result.merge(e.Result)
+			result.Evidence = append(result.Evidence, Evidence{
+				ResourceType: e.ResourceType,
+				ResourceID:   e.ResourceID,
+				Result:       e.Result,
+				Data:         e.Data,
+			})
+
+			if data, ok := e.Data.(event.Data); ok && e.Result == event.Error {
+				if err, ok := data["error"].(string); ok {
+					result.Error = err
+				}
+			}
+		}
+	}
+
+	for _, result := range results.Rules {
+		switch result.Result {
+		case event.Passed:
+			results.Summary.Passed++
+		case event.Failed:
+			results.Summary.Failed++
+		case event.Error:
+			results.Summary.Error++
+		default:
+			results.Summary.Skipped++
+		}
+	}
+
+	return results
+}
+
+func (r *RuleResult) merge(result string) {
+	if resultSeverity[result] > resultSeverity[r.Result] {
+		r.Result = result
+	}
+}
+
+// Write writes the results in the given format
+func Write(w io.Writer, format Format, results *Results) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	case FormatSARIF:
+		return writeSARIF(w, results)
+	case FormatJUnit:
+		return writeJUnit(w, results)
+	default:
+		return fmt.Errorf("unsupported report format %q", format)
+	}
+}
+
+// message returns a human readable description of the result of a rule
+func (r *RuleResult) message() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s", compliance.CheckName(r.RuleID, r.Description), r.Result)
+	if len(r.Error) > 0 {
+		fmt.Fprintf(&b, " (%s)", r.Error)
+	}
+	return b.String()
+}
+
+// evidenceText returns the evidence of a rule as indented JSON
+func (r *RuleResult) evidenceText() string {
+	if len(r.Evidence) == 0 {
+		return ""
+	}
+	data, err := json.MarshalIndent(r.Evidence, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
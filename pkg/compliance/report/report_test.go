@@ -0,0 +1,199 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func testCheckStatus() compliance.CheckStatusList {
+	return compliance.CheckStatusList{
+		{
+			RuleID:      "cis-docker-1",
+			Description: "Ensure the docker daemon socket is owned by root",
+			Remediation: "Run chown root:docker /var/run/docker.sock",
+			Framework:   "cis-docker",
+			Version:     "1.2.0",
+			LastEvent: &event.Event{
+				AgentRuleID:  "cis-docker-1",
+				Result:       event.Failed,
+				ResourceType: "docker",
+				ResourceID:   "the-host",
+				Data: event.Data{
+					"file.path":  "/var/run/docker.sock",
+					"file.user":  "nobody",
+					"file.group": "docker",
+				},
+			},
+		},
+		{
+			RuleID:    "cis-docker-2",
+			Framework: "cis-docker",
+			LastEvent: &event.Event{
+				AgentRuleID: "cis-docker-2",
+				Result:      event.Passed,
+			},
+		},
+		{
+			RuleID:    "cis-docker-3",
+			Framework: "cis-docker",
+			InitError: checks.ErrRuleDoesNotApply,
+		},
+		{
+			RuleID:    "cis-kubernetes-1",
+			Framework: "cis-kubernetes",
+			LastEvent: &event.Event{
+				AgentRuleID: "cis-kubernetes-1",
+				Result:      event.Error,
+				Data: event.Data{
+					"error": "no files found",
+				},
+			},
+		},
+		{
+			RuleID:    "cis-kubernetes-2",
+			Framework: "cis-kubernetes",
+			InitError: errors.New("invalid resource"),
+		},
+		{
+			RuleID:    "cis-docker-2",
+			Framework: "cis-docker",
+			LastEvent: &event.Event{
+				AgentRuleID: "cis-docker-2",
+				Result:      event.Failed,
+			},
+		},
+	}
+}
+
+func TestNewResults(t *testing.T) {
+	assert := assert.New(t)
+
+	results := NewResults("the-host", testCheckStatus(), time.Unix(0, 0))
+
+	assert.Equal(Summary{Passed: 0, Failed: 2, Error: 2, Skipped: 1}, results.Summary)
+	assert.Len(results.Rules, 5)
+
+	expected := map[string]string{
+		"cis-docker-1":     event.Failed,
+		"cis-docker-2":     event.Failed,
+		"cis-docker-3":     Skipped,
+		"cis-kubernetes-1": event.Error,
+		"cis-kubernetes-2": event.Error,
+	}
+	for _, rule := range results.Rules {
+		assert.Equal(expected[rule.RuleID], rule.Result, rule.RuleID)
+	}
+
+	rule := results.Rules[0]
+	assert.Equal("Run chown root:docker /var/run/docker.sock", rule.Remediation)
+	assert.Len(rule.Evidence, 1)
+	assert.Equal("/var/run/docker.sock", rule.Evidence[0].Data.(event.Data)["file.path"])
+
+	assert.Len(results.Rules[1].Evidence, 2)
+	assert.Equal("no files found", results.Rules[3].Error)
+	assert.Equal("invalid resource", results.Rules[4].Error)
+}
+
+func TestNewResultsPerResource(t *testing.T) {
+	assert := assert.New(t)
+
+	failed := &event.Event{AgentRuleID: "cis-kubernetes-3", ResourceType: "kube_node", ResourceID: "node-1", Result: event.Failed}
+	passed := &event.Event{AgentRuleID: "cis-kubernetes-3", ResourceType: "kube_node", ResourceID: "node-2", Result: event.Passed}
+
+	results := NewResults("the-host", compliance.CheckStatusList{
+		{
+			RuleID:     "cis-kubernetes-3",
+			Framework:  "cis-kubernetes",
+			LastEvent:  failed,
+			LastEvents: []*event.Event{failed, passed},
+		},
+	}, time.Unix(0, 0))
+
+	assert.Len(results.Rules, 1)
+	assert.Equal(event.Failed, results.Rules[0].Result)
+	assert.Len(results.Rules[0].Evidence, 2)
+	assert.Equal("node-1", results.Rules[0].Evidence[0].ResourceID)
+	assert.Equal("node-2", results.Rules[0].Evidence[1].ResourceID)
+}
+
+func TestParseFormat(t *testing.T) {
+	assert := assert.New(t)
+
+	format, err := ParseFormat("SARIF")
+	assert.NoError(err)
+	assert.Equal(FormatSARIF, format)
+
+	_, err = ParseFormat("csv")
+	assert.Error(err)
+}
+
+func TestWriteJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	assert.NoError(Write(&buf, FormatJSON, NewResults("the-host", testCheckStatus(), time.Unix(0, 0))))
+
+	var results Results
+	assert.NoError(json.Unmarshal(buf.Bytes(), &results))
+	assert.Equal("the-host", results.Hostname)
+	assert.Len(results.Rules, 5)
+	assert.Equal("Run chown root:docker /var/run/docker.sock", results.Rules[0].Remediation)
+}
+
+func TestWriteSARIF(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	assert.NoError(Write(&buf, FormatSARIF, NewResults("the-host", testCheckStatus(), time.Unix(0, 0))))
+
+	var log sarifLog
+	assert.NoError(json.Unmarshal(buf.Bytes(), &log))
+	assert.Equal("2.1.0", log.Version)
+	assert.Len(log.Runs, 1)
+
+	run := log.Runs[0]
+	assert.Len(run.Tool.Driver.Rules, 5)
+	assert.Equal("Run chown root:docker /var/run/docker.sock", run.Tool.Driver.Rules[0].Help.Text)
+
+	var kinds []string
+	for _, result := range run.Results {
+		kinds = append(kinds, result.Kind)
+	}
+	assert.Equal([]string{"fail", "fail", "notApplicable", "review", "review"}, kinds)
+}
+
+func TestWriteJUnit(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	assert.NoError(Write(&buf, FormatJUnit, NewResults("the-host", testCheckStatus(), time.Unix(0, 0))))
+
+	var suites junitTestSuites
+	assert.NoError(xml.Unmarshal(buf.Bytes(), &suites))
+	assert.Equal(5, suites.Tests)
+	assert.Equal(2, suites.Failures)
+	assert.Equal(2, suites.Errors)
+	assert.Equal(1, suites.Skipped)
+	assert.Len(suites.TestSuites, 2)
+
+	docker := suites.TestSuites[0]
+	assert.Equal("cis-docker", docker.Name)
+	assert.Len(docker.TestCases, 3)
+	assert.Contains(docker.TestCases[0].Failure.Text, "Remediation: Run chown root:docker /var/run/docker.sock")
+	assert.Contains(docker.TestCases[0].SystemOut, "/var/run/docker.sock")
+}
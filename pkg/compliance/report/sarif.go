@@ -0,0 +1,125 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string                 `json:"id"`
+	ShortDescription *sarifMessage          `json:"shortDescription,omitempty"`
+	Help             *sarifMessage          `json:"help,omitempty"`
+	Properties       map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string                 `json:"ruleId"`
+	RuleIndex  int                    `json:"ruleIndex"`
+	Kind       string                 `json:"kind"`
+	Level      string                 `json:"level"`
+	Message    sarifMessage           `json:"message"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// sarifKindAndLevel maps the result of a rule to the kind and level of a SARIF result
+func sarifKindAndLevel(result string) (string, string) {
+	switch result {
+	case event.Passed:
+		return "pass", "none"
+	case event.Failed:
+		return "fail", "error"
+	case event.Error:
+		return "review", "warning"
+	default:
+		return "notApplicable", "none"
+	}
+}
+
+func writeSARIF(w io.Writer, results *Results) error {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:  toolName,
+				Rules: []sarifRule{},
+			},
+		},
+		Results: []sarifResult{},
+	}
+
+	for i, r := range results.Rules {
+		rule := sarifRule{
+			ID: r.RuleID,
+			Properties: map[string]interface{}{
+				"framework": r.Framework,
+				"version":   r.Version,
+			},
+		}
+		if len(r.Description) > 0 {
+			rule.ShortDescription = &sarifMessage{Text: r.Description}
+		}
+		if len(r.Remediation) > 0 {
+			rule.Help = &sarifMessage{Text: r.Remediation}
+		}
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+
+		kind, level := sarifKindAndLevel(r.Result)
+		result := sarifResult{
+			RuleID:    r.RuleID,
+			RuleIndex: i,
+			Kind:      kind,
+			Level:     level,
+			Message:   sarifMessage{Text: r.message()},
+		}
+		if len(r.Evidence) > 0 {
+			result.Properties = map[string]interface{}{
+				"hostname": results.Hostname,
+				"evidence": r.Evidence,
+			}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	})
+}
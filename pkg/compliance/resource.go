@@ -32,6 +32,14 @@ const (
 	KindKubernetes = ResourceKind("kubernetes")
 	// KindCustom is used for a Custom check
 	KindCustom = ResourceKind("custom")
+	// KindSysctl is used for a Sysctl resource
+	KindSysctl = ResourceKind("sysctl")
+	// KindKernelModule is used for a KernelModule resource
+	KindKernelModule = ResourceKind("kernelModule")
+	// KindSystemdUnit is used for a SystemdUnit resource
+	KindSystemdUnit = ResourceKind("systemdUnit")
+	// KindPackage is used for a Package resource
+	KindPackage = ResourceKind("package")
 )
 
 // Resource describes supported resource types observed by a Rule
@@ -44,6 +52,10 @@ type Resource struct {
 	Docker        *DockerResource     `yaml:"docker,omitempty"`
 	KubeApiserver *KubernetesResource `yaml:"kubeApiserver,omitempty"`
 	Custom        *Custom             `yaml:"custom,omitempty"`
+	Sysctl        *Sysctl             `yaml:"sysctl,omitempty"`
+	KernelModule  *KernelModule       `yaml:"kernelModule,omitempty"`
+	SystemdUnit   *SystemdUnit        `yaml:"systemdUnit,omitempty"`
+	Package       *Package            `yaml:"package,omitempty"`
 	Condition     string              `yaml:"condition"`
 	Fallback      *Fallback           `yaml:"fallback,omitempty"`
 }
@@ -67,6 +79,14 @@ func (r *Resource) Kind() ResourceKind {
 		return KindKubernetes
 	case r.Custom != nil:
 		return KindCustom
+	case r.Sysctl != nil:
+		return KindSysctl
+	case r.KernelModule != nil:
+		return KindKernelModule
+	case r.SystemdUnit != nil:
+		return KindSystemdUnit
+	case r.Package != nil:
+		return KindPackage
 	default:
 		return KindInvalid
 	}
@@ -84,6 +104,7 @@ const (
 	FileFieldPermissions = "file.permissions"
 	FileFieldUser        = "file.user"
 	FileFieldGroup       = "file.group"
+	FileFieldContent     = "file.content"
 
 	FileFuncJQ     = "file.jq"
 	FileFuncYAML   = "file.yaml"
@@ -92,14 +113,23 @@ const (
 
 // File describes a file resource
 type File struct {
-	Path string `yaml:"path"`
+	Path   string `yaml:"path"`
+	Parser string `yaml:"parser,omitempty"`
 }
 
+// Parsers available for the content of a File, set in the `file.content` field
+const (
+	FileParserJSON = "json"
+	FileParserYAML = "yaml"
+	FileParserRaw  = "raw"
+)
+
 // Fields & functions available for Process
 const (
 	ProcessFieldName    = "process.name"
 	ProcessFieldExe     = "process.exe"
 	ProcessFieldCmdLine = "process.cmdLine"
+	ProcessFieldFlags   = "process.flags"
 
 	ProcessFuncFlag    = "process.flag"
 	ProcessFuncHasFlag = "process.hasFlag"
@@ -257,3 +287,55 @@ type Custom struct {
 	Name      string            `yaml:"name"`
 	Variables map[string]string `yaml:"variables,omitempty"`
 }
+
+// Fields available for Sysctl
+const (
+	SysctlFieldName  = "sysctl.name"
+	SysctlFieldValue = "sysctl.value"
+)
+
+// Sysctl describes a kernel parameter resource, read from /proc/sys
+type Sysctl struct {
+	Name string `yaml:"name"`
+}
+
+// Fields available for KernelModule
+const (
+	KernelModuleFieldName        = "kernelModule.name"
+	KernelModuleFieldLoaded      = "kernelModule.loaded"
+	KernelModuleFieldBlacklisted = "kernelModule.blacklisted"
+	KernelModuleFieldDisabled    = "kernelModule.disabled"
+)
+
+// KernelModule describes a kernel module resource, its state being read from /proc/modules and its configuration
+// from /etc/modprobe.d
+type KernelModule struct {
+	Name string `yaml:"name"`
+}
+
+// Fields available for SystemdUnit
+const (
+	SystemdUnitFieldName    = "systemdUnit.name"
+	SystemdUnitFieldExists  = "systemdUnit.exists"
+	SystemdUnitFieldEnabled = "systemdUnit.enabled"
+	SystemdUnitFieldMasked  = "systemdUnit.masked"
+)
+
+// SystemdUnit describes a systemd unit resource, its state being read from the unit directories
+type SystemdUnit struct {
+	Name string `yaml:"name"`
+}
+
+// Fields & functions available for Package
+const (
+	PackageFieldName      = "package.name"
+	PackageFieldInstalled = "package.installed"
+	PackageFieldVersion   = "package.version"
+
+	PackageFuncCompareVersion = "package.compareVersion"
+)
+
+// Package describes an installed package resource, read from the dpkg or apk database
+type Package struct {
+	Name string `yaml:"name"`
+}
@@ -62,6 +62,30 @@ docker:
 condition: docker.template("{{ $.Config.Healthcheck }}") != ""
 `
 
+const testResourceSysctl = `
+sysctl:
+  name: net.ipv4.ip_forward
+condition: sysctl.value == "0"
+`
+
+const testResourceKernelModule = `
+kernelModule:
+  name: cramfs
+condition: kernelModule.disabled && !kernelModule.loaded
+`
+
+const testResourceSystemdUnit = `
+systemdUnit:
+  name: rsyslog.service
+condition: systemdUnit.enabled
+`
+
+const testResourcePackage = `
+package:
+  name: openssh-server
+condition: package.compareVersion("1:7.4") >= 0
+`
+
 func TestResources(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -150,6 +174,46 @@ func TestResources(t *testing.T) {
 				Condition: `docker.template("{{ $.Config.Healthcheck }}") != ""`,
 			},
 		},
+		{
+			name:  "sysctl",
+			input: testResourceSysctl,
+			expected: Resource{
+				Sysctl: &Sysctl{
+					Name: "net.ipv4.ip_forward",
+				},
+				Condition: `sysctl.value == "0"`,
+			},
+		},
+		{
+			name:  "kernel module",
+			input: testResourceKernelModule,
+			expected: Resource{
+				KernelModule: &KernelModule{
+					Name: "cramfs",
+				},
+				Condition: `kernelModule.disabled && !kernelModule.loaded`,
+			},
+		},
+		{
+			name:  "systemd unit",
+			input: testResourceSystemdUnit,
+			expected: Resource{
+				SystemdUnit: &SystemdUnit{
+					Name: "rsyslog.service",
+				},
+				Condition: `systemdUnit.enabled`,
+			},
+		},
+		{
+			name:  "package",
+			input: testResourcePackage,
+			expected: Resource{
+				Package: &Package{
+					Name: "openssh-server",
+				},
+				Condition: `package.compareVersion("1:7.4") >= 0`,
+			},
+		},
 	}
 
 	for _, test := range tests {
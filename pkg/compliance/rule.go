@@ -12,9 +12,29 @@ import "fmt"
 type Rule struct {
 	ID           string        `yaml:"id"`
 	Description  string        `yaml:"description,omitempty"`
+	Remediation  string        `yaml:"remediation,omitempty"`
 	Scope        RuleScopeList `yaml:"scope,omitempty"`
 	HostSelector string        `yaml:"hostSelector,omitempty"`
 	Resources    []Resource    `yaml:"resources,omitempty"`
+	Input        []RegoInput   `yaml:"input,omitempty"`
+	Module       string        `yaml:"module,omitempty"`
+	Findings     string        `yaml:"findings,omitempty"`
+}
+
+// DefaultRegoFindings is the query evaluated to get the findings of a Rego rule
+const DefaultRegoFindings = "data.datadog.findings"
+
+// IsRego returns whether the logic of the rule is written in Rego, in which case the rule evaluates a Rego module
+// against its input instead of the conditions of its resources
+func (r *Rule) IsRego() bool {
+	return len(r.Module) > 0
+}
+
+// RegoInput describes a resource fed to a Rego rule. The instances of the resource are set in the input document
+// under the given tag.
+type RegoInput struct {
+	Resource `yaml:",inline"`
+	Tag      string `yaml:"tag"`
 }
 
 // RuleScope defines scope for applicability of a rule
@@ -48,6 +48,48 @@ func TestParseSuite(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "rego rule",
+			file: "./testdata/cis-kubernetes-rego.yaml",
+			expectSuite: &Suite{
+				Meta: SuiteMeta{
+					Schema: SuiteSchema{
+						Version: "1.0",
+					},
+					Name:      "CIS Kubernetes Generic",
+					Framework: "cis-kubernetes",
+					Version:   "1.6.0",
+					Source:    "./testdata/cis-kubernetes-rego.yaml",
+				},
+				Rules: []Rule{
+					{
+						ID:    "cis-kubernetes-4.2.6",
+						Scope: RuleScopeList{KubernetesNodeScope},
+						Input: []RegoInput{
+							{
+								Resource: Resource{
+									Process: &Process{
+										Name: "kube-apiserver",
+									},
+								},
+								Tag: "apiserver",
+							},
+							{
+								Resource: Resource{
+									File: &File{
+										Path:   "/var/lib/kubelet/config.yaml",
+										Parser: FileParserYAML,
+									},
+								},
+								Tag: "kubelet",
+							},
+						},
+						Findings: "data.datadog.findings",
+						Module:   "package datadog\n\nfindings[f] {\n  input.kubelet[0].file.content.readOnlyPort != 0\n  f := {\"status\": \"failed\"}\n}\n",
+					},
+				},
+			},
+		},
 		{
 			name:        "unsupported version",
 			file:        "./testdata/cis-docker-unsupported.yaml",
@@ -44,6 +44,14 @@ func setupAPM(config Config) {
 	config.SetKnown("apm_config.bucket_size_seconds")
 	config.SetKnown("apm_config.watchdog_check_delay")
 	config.SetKnown("apm_config.sync_flushing")
+	config.SetKnown("apm_config.record_payloads_file")
+	config.SetKnown("apm_config.stats_aggregation.peer_service")
+	config.SetKnown("apm_config.stats_aggregation.http_method")
+	config.SetKnown("apm_config.stats_aggregation.tags")
+	config.SetKnown("apm_config.stats_aggregation.max_cardinality")
+	config.SetKnown("apm_config.trace_inspector.enabled")
+	config.SetKnown("apm_config.trace_inspector.max_traces")
+	config.SetKnown("apm_config.trace_inspector.show_raw_values")
 
 	if runtime.GOARCH == "386" && runtime.GOOS == "windows" {
 		// on Windows-32 bit, the trace agent isn't installed.  Set the default to disabled
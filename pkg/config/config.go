@@ -793,7 +793,23 @@ func InitConfig(config Config) {
 	config.SetKnown("system_probe_config.windows.driver_buffer_size")
 	config.SetKnown("network_config.enabled")
 	config.SetKnown("network_config.enable_http_monitoring")
+	config.SetKnown("network_config.enable_http2_monitoring")
+	config.SetKnown("network_config.http2_monitoring_ports")
+	config.SetKnown("network_config.enable_kafka_monitoring")
+	config.SetKnown("network_config.kafka_monitoring_ports")
+	config.SetKnown("network_config.enable_postgres_monitoring")
+	config.SetKnown("network_config.postgres_monitoring_ports")
+	config.SetKnown("network_config.collect_tls_metadata")
+	config.SetKnown("network_config.enable_tcp_failure_telemetry")
+	config.SetKnown("network_config.aggregate_ephemeral_connections")
+	config.SetKnown("network_config.ephemeral_port_range")
+	config.SetKnown("network_config.connection_sampling_rules")
+	config.SetKnown("network_config.max_returned_connections")
 	config.SetKnown("network_config.ignore_conntrack_init_failure")
+	config.SetKnown("network_config.enable_conntrack_fallback")
+	config.SetKnown("network_config.conntrack_fallback_interval_in_s")
+	config.SetKnown("network_config.enable_dns_query_log")
+	config.SetKnown("network_config.max_dns_query_log_entries")
 
 	// Network
 	config.BindEnv("network.id") //nolint:errcheck
@@ -813,6 +829,8 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("compliance_config.check_interval", 20*time.Minute)
 	config.BindEnvAndSetDefault("compliance_config.dir", "/etc/datadog-agent/compliance.d")
 	config.BindEnvAndSetDefault("compliance_config.run_path", defaultRunPath)
+	config.BindEnvAndSetDefault("compliance_config.drift_detection.enabled", false)
+	config.BindEnvAndSetDefault("compliance_config.drift_detection.snapshot_interval", 24*time.Hour)
 
 	// Datadog security agent (runtime)
 	config.BindEnvAndSetDefault("runtime_security_config.enabled", false)
@@ -832,6 +850,14 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("runtime_security_config.pid_cache_size", 10000)
 	config.BindEnvAndSetDefault("runtime_security_config.cookie_cache_size", 100)
 	config.BindEnvAndSetDefault("runtime_security_config.agent_monitoring_events", true)
+	config.BindEnvAndSetDefault("runtime_security_config.activity_dump.enabled", false)
+	config.BindEnvAndSetDefault("runtime_security_config.activity_dump.dir", filepath.Join(defaultRunPath, "runtime-security", "activity_dumps"))
+	config.BindEnvAndSetDefault("runtime_security_config.activity_dump.learning_window", 3600)
+	config.BindEnvAndSetDefault("runtime_security_config.activity_dump.max_files", 1000)
+	config.BindEnvAndSetDefault("runtime_security_config.enforcement.enabled", false)
+	config.BindEnvAndSetDefault("runtime_security_config.enforcement.dry_run", false)
+	config.BindEnvAndSetDefault("runtime_security_config.enforcement.rule_ids", []string{})
+	config.BindEnvAndSetDefault("runtime_security_config.enforcement.max_per_minute", 10)
 
 	// command line options
 	config.SetKnown("cmd.check.fullsketches")
@@ -3,4 +3,4 @@
 
 package runtime
 
-var RuntimeSecurity = NewRuntimeAsset("runtime-security.c", "109672f0b445868ea4d81a4876b5b5bd27436c2b18ed99c8bb6188fd74b77a3b")
+var RuntimeSecurity = NewRuntimeAsset("runtime-security.c", "20db2ee6ee892187fc6611081b4e9753b2ccf20eecc4fc17ee16845821c20eab")
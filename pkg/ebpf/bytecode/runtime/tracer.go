@@ -3,4 +3,4 @@
 
 package runtime
 
-var Tracer = NewRuntimeAsset("tracer.c", "254fae6874a60c458b6369ff904edd1938d7d2b10719a14b188e7453bc51e7a7")
+var Tracer = NewRuntimeAsset("tracer.c", "9c52ac38a099788485b5cca702df76a4127eea19880e8e01e0b57f555e826c6c")
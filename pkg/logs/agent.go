@@ -74,6 +74,7 @@ func NewAgent(sources *config.LogSources, services *service.Services, processing
 		journald.NewLauncher(sources, pipelineProvider, auditor),
 		windowsevent.NewLauncher(sources, pipelineProvider),
 		traps.NewLauncher(sources, pipelineProvider),
+		channel.NewLauncher(sources, pipelineProvider, false),
 	}
 
 	return &Agent{
@@ -103,7 +104,7 @@ func NewServerless(sources *config.LogSources, services *service.Services, proce
 
 	// setup the inputs
 	inputs := []restart.Restartable{
-		channel.NewLauncher(sources, pipelineProvider),
+		channel.NewLauncher(sources, pipelineProvider, true),
 	}
 
 	return &Agent{
@@ -16,14 +16,16 @@ type Launcher struct {
 	sources          chan *config.LogSource
 	tailers          []*Tailer
 	stop             chan struct{}
+	serverless       bool
 }
 
 // NewLauncher returns an initialized Launcher
-func NewLauncher(sources *config.LogSources, pipelineProvider pipeline.Provider) *Launcher {
+func NewLauncher(sources *config.LogSources, pipelineProvider pipeline.Provider, serverless bool) *Launcher {
 	return &Launcher{
 		pipelineProvider: pipelineProvider,
 		sources:          sources.GetAddedForType(config.StringChannelType),
 		stop:             make(chan struct{}),
+		serverless:       serverless,
 	}
 }
 
@@ -34,7 +36,7 @@ func (l *Launcher) Start() {
 
 func (l *Launcher) startNewTailer(source *config.LogSource) {
 	outputChan := l.pipelineProvider.NextPipelineChan()
-	tailer := NewTailer(source, source.Config.Channel, outputChan)
+	tailer := NewTailer(source, source.Config.Channel, outputChan, l.serverless)
 	l.tailers = append(l.tailers, tailer)
 	tailer.Start()
 }
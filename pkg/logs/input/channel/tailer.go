@@ -20,15 +20,19 @@ type Tailer struct {
 	inputChan  chan aws.LogMessage
 	outputChan chan *message.Message
 	done       chan interface{}
+	serverless bool
 }
 
-// NewTailer returns a new Tailer
-func NewTailer(source *config.LogSource, inputChan chan aws.LogMessage, outputChan chan *message.Message) *Tailer {
+// NewTailer returns a new Tailer.
+// The messages of a serverless tailer carry the metadata of the Lambda function,
+// the messages of the other tailers are only tagged with the config of their source.
+func NewTailer(source *config.LogSource, inputChan chan aws.LogMessage, outputChan chan *message.Message, serverless bool) *Tailer {
 	return &Tailer{
 		source:     source,
 		inputChan:  inputChan,
 		outputChan: outputChan,
 		done:       make(chan interface{}, 1),
+		serverless: serverless,
 	}
 }
 
@@ -52,6 +56,11 @@ func (t *Tailer) run() {
 	// TODO(remy): calling GetARN(), GetRequestID() and FunctionNameFromARN() in this loop
 	// may not be the most performant.
 	for logline := range t.inputChan {
+		if !t.serverless {
+			t.outputChan <- message.NewMessageWithSource([]byte(logline.StringRecord), message.StatusInfo, t.source, time.Now().UnixNano())
+			continue
+		}
+
 		origin := message.NewOrigin(t.source)
 		tags := origin.Tags()
 
@@ -0,0 +1,46 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package channel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/serverless/aws"
+)
+
+func TestTailerKeepsTheConfigOfHostSources(t *testing.T) {
+	inputChan := make(chan aws.LogMessage, 1)
+	outputChan := make(chan *message.Message, 1)
+	source := config.NewLogSource("test", &config.LogsConfig{
+		Type:    config.StringChannelType,
+		Source:  "dns_query_log",
+		Service: "system-probe",
+		Tags:    []string{"foo:bar"},
+	})
+	tailer := NewTailer(source, inputChan, outputChan, false)
+	tailer.Start()
+
+	inputChan <- aws.LogMessage{Time: time.Now(), StringRecord: "hello"}
+
+	var msg *message.Message
+	select {
+	case msg = <-outputChan:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the message")
+	}
+	assert.Equal(t, "hello", string(msg.Content))
+	assert.Equal(t, "system-probe", msg.Origin.Service())
+	assert.Equal(t, "dns_query_log", msg.Origin.Source())
+	assert.NotContains(t, string(msg.Origin.TagsPayload()), "functionname")
+	assert.Nil(t, msg.Lambda)
+
+	tailer.WaitFlush()
+}
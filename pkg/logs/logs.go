@@ -8,6 +8,7 @@ package logs
 import (
 	"errors"
 	"fmt"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -37,6 +38,11 @@ var (
 	isRunning int32
 	// logs-agent
 	agent *Agent
+	// sources of the running logs-agent and the channel sources added to it,
+	// both guarded by channelSourcesMu
+	logSources       *config.LogSources
+	channelSources   []*ChannelSource
+	channelSourcesMu sync.Mutex
 )
 
 // Start starts logs-agent
@@ -62,6 +68,9 @@ func start(getAC func() *autodiscovery.AutoConfig, serverless bool, logsChan cha
 	// setup the sources and the services
 	sources := config.NewLogSources()
 	services := service.NewServices()
+	channelSourcesMu.Lock()
+	logSources = sources
+	channelSourcesMu.Unlock()
 
 	// setup the config scheduler
 	scheduler.CreateScheduler(sources, services)
@@ -139,6 +148,67 @@ func start(getAC func() *autodiscovery.AutoConfig, serverless bool, logsChan cha
 	return nil
 }
 
+// ChannelSource is a source of the logs-agent collecting the log lines sent by a component of the agent.
+// Its channel is owned by the logs-agent: it is closed when the logs-agent stops, after which the
+// source drops the log lines and has to be added again once the logs-agent is restarted.
+type ChannelSource struct {
+	sync.RWMutex
+	channel chan aws.LogMessage
+	closed  bool
+}
+
+// Send sends a log line to the logs-agent without blocking, it returns false if the
+// line has been dropped because the channel is full or the source is closed.
+func (s *ChannelSource) Send(msg aws.LogMessage) bool {
+	s.RLock()
+	defer s.RUnlock()
+	if s.closed {
+		return false
+	}
+	select {
+	case s.channel <- msg:
+		return true
+	default:
+		return false
+	}
+}
+
+// Closed returns true once the logs-agent the source was added to has been stopped.
+func (s *ChannelSource) Closed() bool {
+	s.RLock()
+	defer s.RUnlock()
+	return s.closed
+}
+
+func (s *ChannelSource) close() {
+	s.Lock()
+	s.closed = true
+	s.Unlock()
+}
+
+// AddChannelSource adds a source collecting the log lines sent through the returned handle,
+// for the components of the agent forwarding their own data as logs.
+// Up to size log lines are buffered, the lines sent beyond are dropped.
+func AddChannelSource(name string, cfg *config.LogsConfig, size int) (*ChannelSource, error) {
+	if cfg.Type != config.StringChannelType {
+		return nil, fmt.Errorf("invalid source %s: a channel source must have the %s type", name, config.StringChannelType)
+	}
+
+	channelSourcesMu.Lock()
+	defer channelSourcesMu.Unlock()
+	if !IsAgentRunning() || logSources == nil {
+		return nil, errors.New("the logs-agent is not running")
+	}
+
+	source := &ChannelSource{channel: make(chan aws.LogMessage, size)}
+	cfg.Channel = source.channel
+	channelSources = append(channelSources, source)
+
+	log.Debugf("Adding %s channel source to the Logs Agent", name)
+	logSources.AddSource(config.NewLogSource(name, cfg))
+	return source, nil
+}
+
 // BlockUntilAutoConfigRanOnce blocks until the AutoConfig has been run once.
 // It also returns after the given timeout.
 func BlockUntilAutoConfigRanOnce(getAC func() *autodiscovery.AutoConfig, timeout time.Duration) {
@@ -160,6 +230,15 @@ func BlockUntilAutoConfigRanOnce(getAC func() *autodiscovery.AutoConfig, timeout
 func Stop() {
 	log.Info("Stopping logs-agent")
 	if IsAgentRunning() {
+		// the channels are closed by the agent, the channel sources must not send anything from now on
+		channelSourcesMu.Lock()
+		for _, source := range channelSources {
+			source.close()
+		}
+		channelSources = nil
+		logSources = nil
+		channelSourcesMu.Unlock()
+
 		if agent != nil {
 			agent.Stop()
 			agent = nil
@@ -0,0 +1,36 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package logs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/serverless/aws"
+)
+
+func TestAddChannelSourceWhenNotRunning(t *testing.T) {
+	source, err := AddChannelSource("test", &config.LogsConfig{Type: config.StringChannelType}, 1)
+	assert.Error(t, err)
+	assert.Nil(t, source)
+}
+
+func TestChannelSourceSend(t *testing.T) {
+	source := &ChannelSource{channel: make(chan aws.LogMessage, 1)}
+
+	assert.True(t, source.Send(aws.LogMessage{StringRecord: "foo"}))
+	// the channel is full
+	assert.False(t, source.Send(aws.LogMessage{StringRecord: "bar"}))
+	assert.Equal(t, "foo", (<-source.channel).StringRecord)
+
+	// the logs-agent closes the channel once the source is closed
+	source.close()
+	close(source.channel)
+	assert.True(t, source.Closed())
+	assert.False(t, source.Send(aws.LogMessage{StringRecord: "baz"}))
+}
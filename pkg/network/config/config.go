@@ -42,9 +42,42 @@ type Config struct {
 	// DNSTimeout determines the length of time to wait before considering a DNS Query to have timed out
 	DNSTimeout time.Duration
 
+	// EnableDNSQueryLog specifies whether the answered and timed out DNS queries are individually logged
+	// It is relevant *only* when DNSInspection and CollectDNSStats is enabled.
+	EnableDNSQueryLog bool
+
+	// MaxDNSQueryLogEntries is the maximum number of DNS queries logged between two retrievals of the query log
+	MaxDNSQueryLogEntries int
+
 	// EnableHTTPMonitoring specifies whether the tracer should monitor HTTP traffic
 	EnableHTTPMonitoring bool
 
+	// EnableHTTP2Monitoring specifies whether the HTTP monitoring should decode the HTTP/2 and gRPC traffic as well
+	EnableHTTP2Monitoring bool
+
+	// HTTP2MonitoringPorts is the list of the ports whose TCP traffic is decoded as HTTP/2
+	HTTP2MonitoringPorts []uint16
+
+	// EnableKafkaMonitoring specifies whether the tracer should decode the Kafka traffic into request stats
+	EnableKafkaMonitoring bool
+
+	// KafkaMonitoringPorts is the list of the Kafka broker ports whose TCP traffic is decoded
+	KafkaMonitoringPorts []uint16
+
+	// EnablePostgresMonitoring specifies whether the tracer should decode the PostgreSQL traffic into query stats
+	EnablePostgresMonitoring bool
+
+	// PostgresMonitoringPorts is the list of the PostgreSQL server ports whose TCP traffic is decoded
+	PostgresMonitoringPorts []uint16
+
+	// CollectTLSMetadata specifies whether the tracer should enhance TCP connections with the server name, version and
+	// cipher suite of their TLS handshake
+	CollectTLSMetadata bool
+
+	// EnableTCPFailureTelemetry specifies whether the tracer should count the resets, zero window probes, out of order
+	// segments and SYN retransmissions of the TCP connections, and report the failed connection attempts
+	EnableTCPFailureTelemetry bool
+
 	// UDPConnTimeout determines the length of traffic inactivity between two
 	// (IP, port)-pairs before declaring a UDP connection as inactive. This is
 	// set to /proc/sys/net/netfilter/nf_conntrack_udp_timeout on Linux by
@@ -105,6 +138,13 @@ type Config struct {
 	// default is true
 	EnableConntrackAllNamespaces bool
 
+	// EnableConntrackFallback enables a conntracker periodically loading the whole conntrack table, used when netlink
+	// can't be subscribed to, and to look up the translations missed by the netlink conntracker otherwise
+	EnableConntrackFallback bool
+
+	// ConntrackFallbackInterval is the time between two loads of the conntrack table by the conntrack fallback
+	ConntrackFallbackInterval time.Duration
+
 	// ClosedChannelSize specifies the size for closed channel for the tracer
 	ClosedChannelSize int
 
@@ -114,6 +154,21 @@ type Config struct {
 	// ExcludedDestinationConnections is a map of destination connections to blacklist
 	ExcludedDestinationConnections map[string][]string
 
+	// AggregateEphemeralConnections specifies whether the outgoing connections from an ephemeral port are aggregated
+	// into one connection per process, source address, destination address and destination port
+	AggregateEphemeralConnections bool
+
+	// EphemeralPortRange is the range of the client ports considered ephemeral, formatted as "lower-upper"
+	EphemeralPortRange string
+
+	// ConnectionSamplingRules lists the ratio of the connections kept among the ones matching each rule, the first
+	// matching rule applying
+	ConnectionSamplingRules []ConnectionSamplingRule
+
+	// MaxReturnedConnections caps the number of connections returned to a client, the connections with the least
+	// traffic being dropped first. 0 disables the limit.
+	MaxReturnedConnections int
+
 	// OffsetGuessThreshold is the size of the byte threshold we will iterate over when guessing offsets
 	OffsetGuessThreshold uint64
 
@@ -124,6 +179,14 @@ type Config struct {
 	DriverBufferSize int
 }
 
+// ConnectionSamplingRule keeps the given ratio of the connections matching its source and destination filters, which
+// have the same format as ExcludedSourceConnections and ExcludedDestinationConnections
+type ConnectionSamplingRule struct {
+	SampleRate  float64
+	Source      map[string][]string
+	Destination map[string][]string
+}
+
 // NewDefaultConfig enables traffic collection for all connection types
 func NewDefaultConfig() *Config {
 	return &Config{
@@ -134,6 +197,14 @@ func NewDefaultConfig() *Config {
 		CollectLocalDNS:              false,
 		DNSInspection:                true,
 		EnableHTTPMonitoring:         false,
+		EnableHTTP2Monitoring:        false,
+		HTTP2MonitoringPorts:         []uint16{80, 8080, 50051},
+		EnableKafkaMonitoring:        false,
+		KafkaMonitoringPorts:         []uint16{9092},
+		EnablePostgresMonitoring:     false,
+		PostgresMonitoringPorts:      []uint16{5432},
+		CollectTLSMetadata:           false,
+		EnableTCPFailureTelemetry:    false,
 		UDPConnTimeout:               defaultUDPTimeoutSeconds * time.Second,
 		UDPStreamTimeout:             defaultUDPStreamTimeoutSeconds * time.Second,
 		TCPConnTimeout:               2 * time.Minute,
@@ -144,6 +215,7 @@ func NewDefaultConfig() *Config {
 		EnableConntrackAllNamespaces: true,
 		EnableConntrack:              true,
 		IgnoreConntrackInitFailure:   false,
+		ConntrackFallbackInterval:    30 * time.Second,
 		// With clients checking connection stats roughly every 30s, this gives us roughly ~1.6k + ~2.5k objects a second respectively.
 		MaxClosedConnectionsBuffered: 50000,
 		MaxConnectionsStateBuffered:  75000,
@@ -152,10 +224,12 @@ func NewDefaultConfig() *Config {
 		ClientStateExpiry:            2 * time.Minute,
 		ClosedChannelSize:            500,
 		// DNS Stats related configurations
-		CollectDNSStats:      true,
-		CollectDNSDomains:    false,
-		DNSTimeout:           15 * time.Second,
-		OffsetGuessThreshold: 400,
-		EnableMonotonicCount: false,
+		CollectDNSStats:       true,
+		CollectDNSDomains:     false,
+		DNSTimeout:            15 * time.Second,
+		MaxDNSQueryLogEntries: 10000,
+		OffsetGuessThreshold:  400,
+		EphemeralPortRange:    "32768-60999",
+		EnableMonotonicCount:  false,
 	}
 }
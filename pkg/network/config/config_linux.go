@@ -40,6 +40,14 @@ func (c *Config) EnabledProbes(runtimeTracer bool) (map[probes.ProbeName]struct{
 		if c.BPFDebug || c.EnableHTTPMonitoring {
 			enabled[probes.TCPSendMsgReturn] = struct{}{}
 		}
+
+		if c.EnableTCPFailureTelemetry {
+			enabled[probes.TCPConnect] = struct{}{}
+			enabled[probes.TCPSendActiveReset] = struct{}{}
+			enabled[probes.TCPReset] = struct{}{}
+			enabled[probes.TCPSendProbe0] = struct{}{}
+			enabled[probes.TCPDataQueueOFO] = struct{}{}
+		}
 	}
 
 	if c.CollectUDPConns {
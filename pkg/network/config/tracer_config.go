@@ -49,6 +49,21 @@ func TracerConfigFromConfig(cfg *config.AgentConfig) *Config {
 		tracerConfig.ExcludedDestinationConnections = cfg.ExcludedDestinationConnections
 	}
 
+	tracerConfig.AggregateEphemeralConnections = cfg.AggregateEphemeralConnections
+	if cfg.EphemeralPortRange != "" {
+		tracerConfig.EphemeralPortRange = cfg.EphemeralPortRange
+	}
+	for _, rule := range cfg.ConnectionSamplingRules {
+		tracerConfig.ConnectionSamplingRules = append(tracerConfig.ConnectionSamplingRules, ConnectionSamplingRule{
+			SampleRate:  rule.SampleRate,
+			Source:      rule.Source,
+			Destination: rule.Destination,
+		})
+	}
+	if cfg.MaxReturnedConnections > 0 {
+		tracerConfig.MaxReturnedConnections = cfg.MaxReturnedConnections
+	}
+
 	tracerConfig.CollectLocalDNS = cfg.CollectLocalDNS
 	tracerConfig.CollectDNSStats = cfg.CollectDNSStats
 	tracerConfig.CollectDNSDomains = cfg.CollectDNSDomains
@@ -57,13 +72,36 @@ func TracerConfigFromConfig(cfg *config.AgentConfig) *Config {
 		tracerConfig.DNSTimeout = cfg.DNSTimeout
 	}
 
+	tracerConfig.EnableDNSQueryLog = cfg.EnableDNSQueryLog
+	if cfg.MaxDNSQueryLogEntries > 0 {
+		tracerConfig.MaxDNSQueryLogEntries = cfg.MaxDNSQueryLogEntries
+	}
+
 	tracerConfig.MaxTrackedConnections = cfg.MaxTrackedConnections
 	tracerConfig.EnableConntrack = cfg.EnableConntrack
 	tracerConfig.ConntrackMaxStateSize = cfg.ConntrackMaxStateSize
 	tracerConfig.IgnoreConntrackInitFailure = cfg.IgnoreConntrackInitFailure
 	tracerConfig.EnableConntrackAllNamespaces = cfg.EnableConntrackAllNamespaces
+	tracerConfig.EnableConntrackFallback = cfg.EnableConntrackFallback
+	if cfg.ConntrackFallbackInterval > 0 {
+		tracerConfig.ConntrackFallbackInterval = cfg.ConntrackFallbackInterval
+	}
 	tracerConfig.DebugPort = cfg.SystemProbeDebugPort
 	tracerConfig.EnableHTTPMonitoring = cfg.EnableHTTPMonitoring
+	tracerConfig.EnableHTTP2Monitoring = cfg.EnableHTTP2Monitoring
+	if len(cfg.HTTP2MonitoringPorts) > 0 {
+		tracerConfig.HTTP2MonitoringPorts = cfg.HTTP2MonitoringPorts
+	}
+	tracerConfig.EnableKafkaMonitoring = cfg.EnableKafkaMonitoring
+	if len(cfg.KafkaMonitoringPorts) > 0 {
+		tracerConfig.KafkaMonitoringPorts = cfg.KafkaMonitoringPorts
+	}
+	tracerConfig.EnablePostgresMonitoring = cfg.EnablePostgresMonitoring
+	if len(cfg.PostgresMonitoringPorts) > 0 {
+		tracerConfig.PostgresMonitoringPorts = cfg.PostgresMonitoringPorts
+	}
+	tracerConfig.CollectTLSMetadata = cfg.CollectTLSMetadata
+	tracerConfig.EnableTCPFailureTelemetry = cfg.EnableTCPFailureTelemetry
 
 	if mccb := cfg.MaxClosedConnectionsBuffered; mccb > 0 {
 		tracerConfig.MaxClosedConnectionsBuffered = mccb
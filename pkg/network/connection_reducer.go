@@ -0,0 +1,263 @@
+package network
+
+import (
+	"sort"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// ConnectionReducer reduces the connections returned to a client: the outgoing connections from an ephemeral port are
+// aggregated, the sampling rules are applied and the number of connections is capped.
+//
+// A ConnectionReducer isn't safe for concurrent use.
+type ConnectionReducer struct {
+	aggregate     bool
+	ephemeralLow  uint16
+	ephemeralHigh uint16
+	samplingRules []*ConnectionSamplingRule
+	maxConns      int
+
+	buf []byte
+}
+
+// ReductionStats holds the number of connections removed by a ConnectionReducer
+type ReductionStats struct {
+	// Aggregated is the number of connections merged into another one
+	Aggregated int64
+	// SampledOut is the number of connections dropped by the sampling rules
+	SampledOut int64
+	// Overflow is the number of connections dropped because of the cap, whose last sent and received bytes are
+	// summed in OverflowSentBytes and OverflowRecvBytes
+	Overflow          int64
+	OverflowSentBytes int64
+	OverflowRecvBytes int64
+}
+
+// aggregationKey identifies the connections aggregated together, which only differ by their source port
+type aggregationKey struct {
+	pid       uint32
+	netNS     uint32
+	source    util.Address
+	dest      util.Address
+	dport     uint16
+	connType  ConnectionType
+	direction ConnectionDirection
+	// the failed connection attempts aren't merged with the established connections
+	failure TCPFailureReason
+}
+
+// NewConnectionReducer returns a ConnectionReducer configured by the network config
+func NewConnectionReducer(cfg *config.Config) *ConnectionReducer {
+	r := &ConnectionReducer{
+		aggregate:     cfg.AggregateEphemeralConnections,
+		samplingRules: ParseConnectionSamplingRules(cfg.ConnectionSamplingRules),
+		maxConns:      cfg.MaxReturnedConnections,
+		buf:           make([]byte, ConnectionByteKeyMaxLen),
+	}
+
+	if r.aggregate {
+		low, high, _, err := parsePortFilter(cfg.EphemeralPortRange)
+		if err != nil || low == 0 {
+			log.Errorf("Connection aggregation disabled. Invalid ephemeral port range %q: %s", cfg.EphemeralPortRange, err)
+			r.aggregate = false
+		}
+		r.ephemeralLow, r.ephemeralHigh = uint16(low), uint16(high)
+	}
+	return r
+}
+
+// Enabled returns whether the reducer modifies the connections at all
+func (r *ConnectionReducer) Enabled() bool {
+	return r.aggregate || len(r.samplingRules) > 0 || r.maxConns > 0
+}
+
+// Reduce returns the connections to send to a client. The given slice is modified in place.
+func (r *ConnectionReducer) Reduce(conns []ConnectionStats) ([]ConnectionStats, ReductionStats) {
+	var stats ReductionStats
+
+	if r.aggregate {
+		conns, stats.Aggregated = r.aggregateConnections(conns)
+	}
+
+	if len(r.samplingRules) > 0 {
+		kept := conns[:0]
+		for i := range conns {
+			if IsSampledOutConnection(r.samplingRules, &conns[i], r.buf) {
+				stats.SampledOut++
+				continue
+			}
+			kept = append(kept, conns[i])
+		}
+		conns = kept
+	}
+
+	if r.maxConns > 0 && len(conns) > r.maxConns {
+		// the connections with the most traffic are kept
+		sort.SliceStable(conns, func(i, j int) bool {
+			return conns[i].LastSentBytes+conns[i].LastRecvBytes > conns[j].LastSentBytes+conns[j].LastRecvBytes
+		})
+		for _, c := range conns[r.maxConns:] {
+			stats.Overflow++
+			stats.OverflowSentBytes += int64(c.LastSentBytes)
+			stats.OverflowRecvBytes += int64(c.LastRecvBytes)
+		}
+		conns = conns[:r.maxConns]
+	}
+
+	return conns, stats
+}
+
+// isEphemeral returns whether the connection is an outgoing connection from an ephemeral port
+func (r *ConnectionReducer) isEphemeral(c *ConnectionStats) bool {
+	return c.Direction != INCOMING && c.SPort >= r.ephemeralLow && c.SPort <= r.ephemeralHigh
+}
+
+// aggregateConnections merges the connections from an ephemeral port that only differ by their source port. The
+// aggregated connections have a source port of 0.
+func (r *ConnectionReducer) aggregateConnections(conns []ConnectionStats) ([]ConnectionStats, int64) {
+	var aggregated int64
+	indexes := make(map[aggregationKey]int)
+	// the aggregated connections whose maps were copied, so that merging into them doesn't modify the given ones
+	owned := make(map[int]bool)
+	result := conns[:0]
+	for _, c := range conns {
+		if !r.isEphemeral(&c) {
+			result = append(result, c)
+			continue
+		}
+
+		key := aggregationKey{
+			pid:       c.Pid,
+			netNS:     c.NetNS,
+			source:    c.Source,
+			dest:      c.Dest,
+			dport:     c.DPort,
+			connType:  c.Type,
+			direction: c.Direction,
+			failure:   c.TCPFailure,
+		}
+		if i, ok := indexes[key]; ok {
+			if !owned[i] {
+				copyConnectionMaps(&result[i])
+				owned[i] = true
+			}
+			mergeConnection(&result[i], &c)
+			aggregated++
+			continue
+		}
+
+		c.SPort = 0
+		if c.IPTranslation != nil {
+			translation := *c.IPTranslation
+			translation.ReplDstPort = 0
+			c.IPTranslation = &translation
+		}
+		indexes[key] = len(result)
+		result = append(result, c)
+	}
+	return result, aggregated
+}
+
+// mergeConnection adds the counters of src to dst. The maps of dst are modified, the ones of src are left untouched.
+func mergeConnection(dst, src *ConnectionStats) {
+	dst.MonotonicSentBytes += src.MonotonicSentBytes
+	dst.LastSentBytes += src.LastSentBytes
+	dst.MonotonicRecvBytes += src.MonotonicRecvBytes
+	dst.LastRecvBytes += src.LastRecvBytes
+	dst.MonotonicRetransmits += src.MonotonicRetransmits
+	dst.LastRetransmits += src.LastRetransmits
+	dst.MonotonicTCPEstablished += src.MonotonicTCPEstablished
+	dst.LastTCPEstablished += src.LastTCPEstablished
+	dst.MonotonicTCPClosed += src.MonotonicTCPClosed
+	dst.LastTCPClosed += src.LastTCPClosed
+	dst.MonotonicTCPCounters = dst.MonotonicTCPCounters.Add(src.MonotonicTCPCounters)
+	dst.LastTCPCounters = dst.LastTCPCounters.Add(src.LastTCPCounters)
+
+	// the RTT is the one of the most recently updated connection
+	if src.LastUpdateEpoch > dst.LastUpdateEpoch {
+		dst.LastUpdateEpoch = src.LastUpdateEpoch
+		dst.RTT = src.RTT
+		dst.RTTVar = src.RTTVar
+	}
+	dst.IntraHost = dst.IntraHost || src.IntraHost
+	if dst.TLS == nil {
+		dst.TLS = src.TLS
+	}
+
+	dst.DNSSuccessfulResponses += src.DNSSuccessfulResponses
+	dst.DNSFailedResponses += src.DNSFailedResponses
+	dst.DNSTimeouts += src.DNSTimeouts
+	dst.DNSSuccessLatencySum += src.DNSSuccessLatencySum
+	dst.DNSFailureLatencySum += src.DNSFailureLatencySum
+	mergeConnectionMaps(dst, src)
+}
+
+// copyConnectionMaps replaces the maps of the connection, which are shared with the connections it was copied from,
+// by copies of them
+func copyConnectionMaps(c *ConnectionStats) {
+	var maps ConnectionStats
+	mergeConnectionMaps(&maps, c)
+	c.DNSCountByRcode = maps.DNSCountByRcode
+	c.DNSStatsByDomain = maps.DNSStatsByDomain
+	c.HTTPStatsByPath = maps.HTTPStatsByPath
+	c.KafkaStats = maps.KafkaStats
+	c.PostgresStats = maps.PostgresStats
+}
+
+// mergeConnectionMaps adds the DNS, HTTP, Kafka and PostgreSQL stats of src to the ones of dst, without sharing any
+// map or sketch of src
+func mergeConnectionMaps(dst, src *ConnectionStats) {
+	dst.DNSCountByRcode = mergeRcodeCounts(dst.DNSCountByRcode, src.DNSCountByRcode)
+	if len(src.DNSStatsByDomain) > 0 && dst.DNSStatsByDomain == nil {
+		dst.DNSStatsByDomain = make(map[string]DNSStats, len(src.DNSStatsByDomain))
+	}
+	for domain, stats := range src.DNSStatsByDomain {
+		prev := dst.DNSStatsByDomain[domain]
+		prev.DNSTimeouts += stats.DNSTimeouts
+		prev.DNSSuccessLatencySum += stats.DNSSuccessLatencySum
+		prev.DNSFailureLatencySum += stats.DNSFailureLatencySum
+		prev.DNSCountByRcode = mergeRcodeCounts(prev.DNSCountByRcode, stats.DNSCountByRcode)
+		dst.DNSStatsByDomain[domain] = prev
+	}
+
+	if len(src.HTTPStatsByPath) > 0 && dst.HTTPStatsByPath == nil {
+		dst.HTTPStatsByPath = make(map[string]http.RequestStats, len(src.HTTPStatsByPath))
+	}
+	for path, stats := range src.HTTPStatsByPath {
+		prev := dst.HTTPStatsByPath[path]
+		prev.CombineWith(stats)
+		dst.HTTPStatsByPath[path] = prev
+	}
+
+	if len(src.KafkaStats) > 0 && dst.KafkaStats == nil {
+		dst.KafkaStats = make(map[protocols.KafkaRequestKey]protocols.RequestStats, len(src.KafkaStats))
+	}
+	for request, stats := range src.KafkaStats {
+		prev := dst.KafkaStats[request]
+		prev.CombineWith(stats)
+		dst.KafkaStats[request] = prev
+	}
+
+	if len(src.PostgresStats) > 0 && dst.PostgresStats == nil {
+		dst.PostgresStats = make(map[protocols.PostgresRequestKey]protocols.RequestStats, len(src.PostgresStats))
+	}
+	for request, stats := range src.PostgresStats {
+		prev := dst.PostgresStats[request]
+		prev.CombineWith(stats)
+		dst.PostgresStats[request] = prev
+	}
+}
+
+func mergeRcodeCounts(dst, src map[uint32]uint32) map[uint32]uint32 {
+	if len(src) > 0 && dst == nil {
+		dst = make(map[uint32]uint32, len(src))
+	}
+	for rcode, count := range src {
+		dst[rcode] += count
+	}
+	return dst
+}
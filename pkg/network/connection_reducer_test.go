@@ -0,0 +1,168 @@
+package network
+
+import (
+	"testing"
+
+	model "github.com/DataDog/agent-payload/process"
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newReducerTestConn(sport, dport uint16, sent uint64) ConnectionStats {
+	return ConnectionStats{
+		Pid:                123,
+		Source:             util.AddressFromString("10.0.0.1"),
+		Dest:               util.AddressFromString("10.0.0.2"),
+		SPort:              sport,
+		DPort:              dport,
+		Type:               TCP,
+		Direction:          OUTGOING,
+		MonotonicSentBytes: sent,
+		LastSentBytes:      sent,
+		MonotonicRecvBytes: 2 * sent,
+		LastRecvBytes:      2 * sent,
+	}
+}
+
+func TestConnectionReducerDisabled(t *testing.T) {
+	r := NewConnectionReducer(config.NewDefaultConfig())
+	assert.False(t, r.Enabled())
+
+	cfg := config.NewDefaultConfig()
+	cfg.AggregateEphemeralConnections = true
+	cfg.EphemeralPortRange = "invalid"
+	r = NewConnectionReducer(cfg)
+	assert.False(t, r.Enabled())
+}
+
+func TestConnectionReducerAggregation(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.AggregateEphemeralConnections = true
+	r := NewConnectionReducer(cfg)
+	require.True(t, r.Enabled())
+
+	var httpStats http.RequestStats
+	httpStats.AddRequest(200, 10)
+
+	first := newReducerTestConn(40000, 80, 10)
+	first.LastUpdateEpoch = 1
+	first.RTT = 100
+	first.HTTPStatsByPath = map[string]http.RequestStats{"/": httpStats}
+	first.DNSStatsByDomain = map[string]DNSStats{"foo.com": {DNSCountByRcode: map[uint32]uint32{0: 1}}}
+
+	second := newReducerTestConn(40001, 80, 20)
+	second.LastUpdateEpoch = 2
+	second.RTT = 200
+	second.HTTPStatsByPath = map[string]http.RequestStats{"/": httpStats, "/api": httpStats}
+	second.DNSStatsByDomain = map[string]DNSStats{"foo.com": {DNSCountByRcode: map[uint32]uint32{0: 2}}}
+
+	// an incoming connection and a connection from a port out of the ephemeral range aren't aggregated
+	incoming := newReducerTestConn(40002, 80, 30)
+	incoming.Direction = INCOMING
+	nonEphemeral := newReducerTestConn(1000, 80, 40)
+	otherDest := newReducerTestConn(40003, 443, 50)
+
+	conns, stats := r.Reduce([]ConnectionStats{first, incoming, second, nonEphemeral, otherDest})
+	assert.Equal(t, ReductionStats{Aggregated: 1}, stats)
+	require.Len(t, conns, 4)
+
+	aggregated := conns[0]
+	assert.Equal(t, uint16(0), aggregated.SPort)
+	assert.Equal(t, uint64(30), aggregated.MonotonicSentBytes)
+	assert.Equal(t, uint64(30), aggregated.LastSentBytes)
+	assert.Equal(t, uint64(60), aggregated.LastRecvBytes)
+	assert.Equal(t, uint32(200), aggregated.RTT)
+	assert.Equal(t, uint32(3), aggregated.DNSStatsByDomain["foo.com"].DNSCountByRcode[0])
+	require.Len(t, aggregated.HTTPStatsByPath, 2)
+	rootStats := aggregated.HTTPStatsByPath["/"]
+	assert.Equal(t, 2, rootStats.Count(model.HTTPResponseStatus_Success))
+
+	// the stats of the given connections aren't modified
+	assert.Equal(t, uint32(1), first.DNSStatsByDomain["foo.com"].DNSCountByRcode[0])
+	assert.Len(t, first.HTTPStatsByPath, 1)
+	assert.Equal(t, 1, httpStats.Count(model.HTTPResponseStatus_Success))
+	assert.Equal(t, 1.0, httpStats.Latencies(model.HTTPResponseStatus_Success).GetCount())
+
+	assert.Equal(t, uint16(40002), conns[1].SPort)
+	assert.Equal(t, uint16(1000), conns[2].SPort)
+	assert.Equal(t, uint16(0), conns[3].SPort)
+	assert.Equal(t, uint16(443), conns[3].DPort)
+}
+
+func TestConnectionReducerAggregationTCPFailures(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.AggregateEphemeralConnections = true
+	r := NewConnectionReducer(cfg)
+
+	established := newReducerTestConn(40000, 80, 10)
+	established.MonotonicTCPCounters = TCPCounters{RstsReceived: 1}
+	established.LastTCPCounters = established.MonotonicTCPCounters
+
+	refused := newReducerTestConn(40001, 80, 0)
+	refused.TCPFailure = TCPFailureRefused
+	refused.MonotonicTCPCounters = TCPCounters{RstsReceived: 1}
+	refused.LastTCPCounters = refused.MonotonicTCPCounters
+
+	timedOut := newReducerTestConn(40002, 80, 0)
+	timedOut.TCPFailure = TCPFailureTimeout
+	timedOut.MonotonicTCPCounters = TCPCounters{SynRetries: 5}
+	timedOut.LastTCPCounters = timedOut.MonotonicTCPCounters
+
+	otherRefused := refused
+	otherRefused.SPort = 40003
+
+	// the failed connection attempts are only aggregated with the ones which failed for the same reason
+	conns, stats := r.Reduce([]ConnectionStats{established, refused, timedOut, otherRefused})
+	assert.Equal(t, ReductionStats{Aggregated: 1}, stats)
+	require.Len(t, conns, 3)
+
+	assert.Equal(t, TCPFailureNone, conns[0].TCPFailure)
+	assert.Equal(t, TCPFailureRefused, conns[1].TCPFailure)
+	assert.Equal(t, TCPCounters{RstsReceived: 2}, conns[1].MonotonicTCPCounters)
+	assert.Equal(t, TCPCounters{RstsReceived: 2}, conns[1].LastTCPCounters)
+	assert.Equal(t, TCPFailureTimeout, conns[2].TCPFailure)
+}
+
+func TestConnectionReducerSampling(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.ConnectionSamplingRules = []config.ConnectionSamplingRule{
+		{SampleRate: 0, Destination: map[string][]string{"10.0.0.2": {"8125"}}},
+	}
+	r := NewConnectionReducer(cfg)
+	require.True(t, r.Enabled())
+
+	conns, stats := r.Reduce([]ConnectionStats{
+		newReducerTestConn(40000, 8125, 10),
+		newReducerTestConn(40001, 80, 10),
+		newReducerTestConn(40002, 8125, 10),
+	})
+	assert.Equal(t, ReductionStats{SampledOut: 2}, stats)
+	require.Len(t, conns, 1)
+	assert.Equal(t, uint16(80), conns[0].DPort)
+}
+
+func TestConnectionReducerCap(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.MaxReturnedConnections = 2
+	r := NewConnectionReducer(cfg)
+	require.True(t, r.Enabled())
+
+	conns, stats := r.Reduce([]ConnectionStats{
+		newReducerTestConn(40000, 80, 10),
+		newReducerTestConn(40001, 80, 40),
+		newReducerTestConn(40002, 80, 20),
+		newReducerTestConn(40003, 80, 30),
+	})
+	assert.Equal(t, ReductionStats{Overflow: 2, OverflowSentBytes: 30, OverflowRecvBytes: 60}, stats)
+	require.Len(t, conns, 2)
+	// the connections with the most traffic are kept
+	assert.Equal(t, uint16(40001), conns[0].SPort)
+	assert.Equal(t, uint16(40003), conns[1].SPort)
+
+	conns, stats = r.Reduce(conns)
+	assert.Equal(t, ReductionStats{}, stats)
+	assert.Len(t, conns, 2)
+}
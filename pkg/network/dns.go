@@ -6,6 +6,7 @@ import "github.com/DataDog/datadog-agent/pkg/process/util"
 type ReverseDNS interface {
 	Resolve([]ConnectionStats) map[util.Address][]string
 	GetDNSStats() map[DNSKey]map[string]DNSStats
+	GetDNSQueryLog() []DNSQueryLogEntry
 	GetStats() map[string]int64
 	Close()
 }
@@ -25,6 +26,10 @@ func (nullReverseDNS) GetDNSStats() map[DNSKey]map[string]DNSStats {
 	return nil
 }
 
+func (nullReverseDNS) GetDNSQueryLog() []DNSQueryLogEntry {
+	return nil
+}
+
 func (nullReverseDNS) GetStats() map[string]int64 {
 	return map[string]int64{
 		"lookups":           0,
@@ -140,6 +140,7 @@ func (p *dnsParser) parseAnswerInto(
 	// Only consider responses
 	if !dns.QR {
 		pktInfo.pktType = Query
+		pktInfo.qType = uint16(question.Type)
 		if p.collectDNSDomains {
 			pktInfo.question = string(question.Name)
 		}
@@ -0,0 +1,266 @@
+package network
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DNSResponseCodeNXDomain is the value that indicates that the queried domain does not exist
+	DNSResponseCodeNXDomain = 3
+
+	// DNSSpikeNXDomain is the kind of the spikes of NXDOMAIN responses
+	DNSSpikeNXDomain = "nxdomain"
+	// DNSSpikeTimeout is the kind of the spikes of timed out queries
+	DNSSpikeTimeout = "timeout"
+)
+
+var dnsResponseCodeNames = map[uint8]string{
+	0: "NOERROR",
+	1: "FORMERR",
+	2: "SERVFAIL",
+	3: "NXDOMAIN",
+	4: "NOTIMP",
+	5: "REFUSED",
+}
+
+var dnsQueryTypeNames = map[uint16]string{
+	1:  "A",
+	2:  "NS",
+	5:  "CNAME",
+	6:  "SOA",
+	12: "PTR",
+	15: "MX",
+	16: "TXT",
+	28: "AAAA",
+	33: "SRV",
+}
+
+// DNSQueryLogEntry holds a single DNS query, as logged by the DNS query log
+type DNSQueryLogEntry struct {
+	Timestamp  time.Time `json:"timestamp"`
+	ClientIP   string    `json:"client_ip"`
+	ClientPort uint16    `json:"client_port"`
+	ServerIP   string    `json:"server_ip"`
+	Protocol   string    `json:"protocol"`
+	// Pid is the process which issued the query, if it could be matched with one of the tracked connections
+	Pid uint32 `json:"pid,omitempty"`
+	// ContainerID is the container of the process, resolved by the agent from the pid
+	ContainerID  string `json:"container_id,omitempty"`
+	Question     string `json:"question"`
+	QueryType    string `json:"query_type"`
+	ResponseCode uint8  `json:"rcode"`
+	// ResponseCodeName is empty when the query timed out
+	ResponseCodeName string `json:"rcode_name,omitempty"`
+	Timeout          bool   `json:"timeout"`
+	LatencyMicros    uint64 `json:"latency_us"`
+
+	key DNSKey
+}
+
+func newDNSQueryLogEntry(key DNSKey, question string, qType uint16, start uint64, latency uint64) DNSQueryLogEntry {
+	return DNSQueryLogEntry{
+		Timestamp:     time.Unix(0, int64(start)*1000),
+		ClientIP:      key.clientIP.String(),
+		ClientPort:    key.clientPort,
+		ServerIP:      key.serverIP.String(),
+		Protocol:      key.protocol.String(),
+		Question:      question,
+		QueryType:     dnsQueryTypeName(qType),
+		LatencyMicros: latency,
+		key:           key,
+	}
+}
+
+func dnsQueryTypeName(qType uint16) string {
+	if name, ok := dnsQueryTypeNames[qType]; ok {
+		return name
+	}
+	return fmt.Sprintf("TYPE%d", qType)
+}
+
+func dnsResponseCodeName(rCode uint8) string {
+	if name, ok := dnsResponseCodeNames[rCode]; ok {
+		return name
+	}
+	return fmt.Sprintf("RCODE%d", rCode)
+}
+
+// DNSClientPIDs keeps the pid of the processes which recently issued DNS queries, keyed by the client side of their
+// connection to the DNS server, to attribute the logged queries to processes
+type DNSClientPIDs struct {
+	mux     sync.Mutex
+	pids    map[DNSKey]uint32
+	maxSize int
+}
+
+// NewDNSClientPIDs returns a new DNSClientPIDs, holding at most maxSize clients
+func NewDNSClientPIDs(maxSize int) *DNSClientPIDs {
+	return &DNSClientPIDs{
+		pids:    make(map[DNSKey]uint32),
+		maxSize: maxSize,
+	}
+}
+
+// Add stores the pid of the connections made to a DNS server
+func (c *DNSClientPIDs) Add(conns ...ConnectionStats) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	for i := range conns {
+		conn := &conns[i]
+		if conn.DPort != 53 {
+			continue
+		}
+		key := DNSKey{
+			serverIP:   conn.Dest,
+			clientIP:   conn.Source,
+			clientPort: conn.SPort,
+			protocol:   conn.Type,
+		}
+		if _, ok := c.pids[key]; !ok && len(c.pids) >= c.maxSize {
+			// the oldest clients can't be told apart, so start over with the most recent ones
+			c.pids = make(map[DNSKey]uint32)
+		}
+		c.pids[key] = conn.Pid
+	}
+}
+
+// Annotate sets the pid of the DNS query log entries whose client is known
+func (c *DNSClientPIDs) Annotate(entries []DNSQueryLogEntry) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	for i := range entries {
+		if pid, ok := c.pids[entries[i].key]; ok {
+			entries[i].Pid = pid
+		}
+	}
+}
+
+// DNSFailureSpike is reported when the rate of NXDOMAIN responses or of timed out queries crosses its threshold
+type DNSFailureSpike struct {
+	// Kind is either DNSSpikeNXDomain or DNSSpikeTimeout
+	Kind string
+	// Domain is set for the spikes detected for a domain
+	Domain string
+	// ContainerID is set for the spikes detected for a container
+	ContainerID string
+	Queries     int
+	Failures    int
+	Rate        float64
+}
+
+type dnsSpikeKey struct {
+	kind        string
+	domain      string
+	containerID string
+}
+
+type dnsFailureCount struct {
+	queries   int
+	nxdomains int
+	timeouts  int
+}
+
+// DNSFailureDetector detects the domains and the containers whose rate of NXDOMAIN responses or of timed out queries
+// crosses a threshold
+type DNSFailureDetector struct {
+	minQueries        int
+	nxdomainThreshold float64
+	timeoutThreshold  float64
+
+	// alerting holds the spikes which were already reported and are still ongoing
+	alerting map[dnsSpikeKey]struct{}
+}
+
+// NewDNSFailureDetector returns a new DNSFailureDetector. A domain or a container must have issued at least minQueries
+// queries for its failure rates to be considered. A threshold of 0 disables the detection of the related spikes.
+func NewDNSFailureDetector(minQueries int, nxdomainThreshold, timeoutThreshold float64) *DNSFailureDetector {
+	if minQueries < 1 {
+		minQueries = 1
+	}
+	return &DNSFailureDetector{
+		minQueries:        minQueries,
+		nxdomainThreshold: nxdomainThreshold,
+		timeoutThreshold:  timeoutThreshold,
+		alerting:          make(map[dnsSpikeKey]struct{}),
+	}
+}
+
+// Detect returns the spikes starting with the given entries, which should hold the queries logged since the previous
+// call. A spike is only reported once, until the failure rate gets back under its threshold.
+func (d *DNSFailureDetector) Detect(entries []DNSQueryLogEntry) []DNSFailureSpike {
+	byDomain := make(map[string]*dnsFailureCount)
+	byContainer := make(map[string]*dnsFailureCount)
+	add := func(counts map[string]*dnsFailureCount, key string, e *DNSQueryLogEntry) {
+		c, ok := counts[key]
+		if !ok {
+			c = &dnsFailureCount{}
+			counts[key] = c
+		}
+		c.queries++
+		if e.Timeout {
+			c.timeouts++
+		} else if e.ResponseCode == DNSResponseCodeNXDomain {
+			c.nxdomains++
+		}
+	}
+
+	for i := range entries {
+		e := &entries[i]
+		if e.Question != "" {
+			add(byDomain, e.Question, e)
+		}
+		if e.ContainerID != "" {
+			add(byContainer, e.ContainerID, e)
+		}
+	}
+
+	var spikes []DNSFailureSpike
+	ongoing := make(map[dnsSpikeKey]struct{})
+	check := func(key dnsSpikeKey, queries, failures int, threshold float64) {
+		if threshold <= 0 || queries < d.minQueries {
+			return
+		}
+		rate := float64(failures) / float64(queries)
+		if rate < threshold {
+			return
+		}
+		ongoing[key] = struct{}{}
+		if _, ok := d.alerting[key]; ok {
+			return
+		}
+		spikes = append(spikes, DNSFailureSpike{
+			Kind:        key.kind,
+			Domain:      key.domain,
+			ContainerID: key.containerID,
+			Queries:     queries,
+			Failures:    failures,
+			Rate:        rate,
+		})
+	}
+
+	for domain, c := range byDomain {
+		check(dnsSpikeKey{kind: DNSSpikeNXDomain, domain: domain}, c.queries, c.nxdomains, d.nxdomainThreshold)
+		check(dnsSpikeKey{kind: DNSSpikeTimeout, domain: domain}, c.queries, c.timeouts, d.timeoutThreshold)
+	}
+	for containerID, c := range byContainer {
+		check(dnsSpikeKey{kind: DNSSpikeNXDomain, containerID: containerID}, c.queries, c.nxdomains, d.nxdomainThreshold)
+		check(dnsSpikeKey{kind: DNSSpikeTimeout, containerID: containerID}, c.queries, c.timeouts, d.timeoutThreshold)
+	}
+	d.alerting = ongoing
+
+	sort.Slice(spikes, func(i, j int) bool {
+		if spikes[i].Kind != spikes[j].Kind {
+			return spikes[i].Kind < spikes[j].Kind
+		}
+		if spikes[i].Domain != spikes[j].Domain {
+			return spikes[i].Domain < spikes[j].Domain
+		}
+		return spikes[i].ContainerID < spikes[j].ContainerID
+	})
+	return spikes
+}
@@ -0,0 +1,79 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestQueryLogEntry(question, containerID string, rCode uint8, timeout bool) DNSQueryLogEntry {
+	return DNSQueryLogEntry{
+		Question:     question,
+		ContainerID:  containerID,
+		ResponseCode: rCode,
+		Timeout:      timeout,
+	}
+}
+
+func TestDNSClientPIDs(t *testing.T) {
+	pids := NewDNSClientPIDs(2)
+	conn := ConnectionStats{
+		Pid:    42,
+		Source: util.AddressFromString("1.1.1.1"),
+		Dest:   util.AddressFromString("8.8.8.8"),
+		SPort:  1000,
+		DPort:  53,
+		Type:   UDP,
+	}
+	other := conn
+	other.Pid = 43
+	other.DPort = 80
+	pids.Add(conn, other)
+
+	key := getSampleDNSKey()
+	entries := []DNSQueryLogEntry{
+		newDNSQueryLogEntry(key, "abc.com", 1, 0, 10),
+		newDNSQueryLogEntry(DNSKey{clientIP: key.clientIP, serverIP: key.serverIP, clientPort: 1001, protocol: UDP}, "abc.com", 1, 0, 10),
+	}
+	pids.Annotate(entries)
+	assert.Equal(t, uint32(42), entries[0].Pid)
+	assert.Equal(t, uint32(0), entries[1].Pid)
+
+	// the known clients are forgotten once the cache is full
+	for port := uint16(2000); port < 2002; port++ {
+		conn.SPort = port
+		pids.Add(conn)
+	}
+	entries[0].Pid = 0
+	pids.Annotate(entries)
+	assert.Equal(t, uint32(0), entries[0].Pid)
+}
+
+func TestDNSFailureDetector(t *testing.T) {
+	d := NewDNSFailureDetector(4, 0.5, 0.25)
+
+	entries := []DNSQueryLogEntry{
+		newTestQueryLogEntry("foo.com", "c1", DNSResponseCodeNXDomain, false),
+		newTestQueryLogEntry("foo.com", "c1", DNSResponseCodeNXDomain, false),
+		newTestQueryLogEntry("foo.com", "c2", DNSResponseCodeNXDomain, false),
+		newTestQueryLogEntry("foo.com", "c2", DNSResponseCodeNoError, false),
+		newTestQueryLogEntry("bar.com", "c2", 0, true),
+		newTestQueryLogEntry("bar.com", "c2", DNSResponseCodeNoError, false),
+		// too few queries for this domain
+		newTestQueryLogEntry("baz.com", "", 0, true),
+	}
+
+	spikes := d.Detect(entries)
+	require.Len(t, spikes, 2)
+	assert.Equal(t, DNSFailureSpike{Kind: DNSSpikeNXDomain, Domain: "foo.com", Queries: 4, Failures: 3, Rate: 0.75}, spikes[0])
+	assert.Equal(t, DNSFailureSpike{Kind: DNSSpikeTimeout, ContainerID: "c2", Queries: 4, Failures: 1, Rate: 0.25}, spikes[1])
+
+	// ongoing spikes aren't reported again
+	assert.Empty(t, d.Detect(entries))
+
+	// a spike is reported again once the failure rate went back under the threshold
+	assert.Empty(t, d.Detect(entries[5:6]))
+	assert.Len(t, d.Detect(entries), 2)
+}
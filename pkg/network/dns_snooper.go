@@ -62,18 +62,27 @@ type PacketSource interface {
 func NewSocketFilterSnooper(cfg *config.Config, source PacketSource) (*SocketFilterSnooper, error) {
 	cache := newReverseDNSCache(dnsCacheSize, dnsCacheTTL, dnsCacheExpirationPeriod)
 	var statKeeper *dnsStatKeeper
+	var maxQueryLogSize int
 	if cfg.CollectDNSStats {
-		statKeeper = newDNSStatkeeper(cfg.DNSTimeout)
+		if cfg.EnableDNSQueryLog {
+			maxQueryLogSize = cfg.MaxDNSQueryLogEntries
+			log.Infof("DNS query log has been enabled")
+		}
+		statKeeper = newDNSStatkeeper(cfg.DNSTimeout, cfg.CollectDNSDomains, maxQueryLogSize)
 		log.Infof("DNS Stats Collection has been enabled.")
 		if cfg.CollectDNSDomains {
 			log.Infof("DNS domain collection has been enabled")
 		}
 	} else {
+		if cfg.EnableDNSQueryLog {
+			log.Warnf("DNS query log requires the DNS stats collection, which is disabled")
+		}
 		log.Infof("DNS Stats Collection has been disabled.")
 	}
 	snooper := &SocketFilterSnooper{
-		source:          source,
-		parser:          newDNSParser(cfg.CollectDNSStats, cfg.CollectDNSDomains),
+		source: source,
+		// the questions are also collected for the query log, which always holds the queried domain
+		parser:          newDNSParser(cfg.CollectDNSStats, cfg.CollectDNSDomains || maxQueryLogSize > 0),
 		cache:           cache,
 		statKeeper:      statKeeper,
 		translation:     new(translation),
@@ -110,6 +119,14 @@ func (s *SocketFilterSnooper) GetDNSStats() map[DNSKey]map[string]DNSStats {
 	return s.statKeeper.GetAndResetAllStats()
 }
 
+// GetDNSQueryLog returns the DNS queries logged since the previous call, if the query log is enabled
+func (s *SocketFilterSnooper) GetDNSQueryLog() []DNSQueryLogEntry {
+	if s.statKeeper == nil {
+		return nil
+	}
+	return s.statKeeper.GetAndFlushQueryLog()
+}
+
 // GetStats returns stats for use with telemetry
 func (s *SocketFilterSnooper) GetStats() map[string]int64 {
 	stats := s.cache.Stats()
@@ -124,6 +141,9 @@ func (s *SocketFilterSnooper) GetStats() map[string]int64 {
 	stats["queries"] = atomic.LoadInt64(&s.queries)
 	stats["successes"] = atomic.LoadInt64(&s.successes)
 	stats["errors"] = atomic.LoadInt64(&s.errors)
+	if s.statKeeper != nil {
+		stats["query_log_dropped"] = s.statKeeper.QueryLogDropped()
+	}
 	return stats
 }
 
@@ -29,6 +29,7 @@ type dnsPacketInfo struct {
 	pktType       DNSPacketType
 	rCode         uint8  // responseCode
 	question      string // only relevant for query packets
+	qType         uint16 // only relevant for query packets
 }
 
 type stateKey struct {
@@ -39,6 +40,7 @@ type stateKey struct {
 type stateValue struct {
 	ts       uint64
 	question string
+	qType    uint16
 }
 
 type dnsStatKeeper struct {
@@ -49,15 +51,27 @@ type dnsStatKeeper struct {
 	exit             chan struct{}
 	maxSize          int // maximum size of the state map
 	deleteCount      int
+
+	// collectDomains scopes the stats by domain, the questions being otherwise only kept for the query log
+	collectDomains bool
+
+	// queryLog holds the queries answered or timed out since the last flush, if the query log is enabled
+	queryLog        []DNSQueryLogEntry
+	maxQueryLogSize int
+	queryLogDropped int64
 }
 
-func newDNSStatkeeper(timeout time.Duration) *dnsStatKeeper {
+// newDNSStatkeeper returns a new dnsStatKeeper, logging up to maxQueryLogSize queries between two flushes of the
+// query log if maxQueryLogSize is positive
+func newDNSStatkeeper(timeout time.Duration, collectDomains bool, maxQueryLogSize int) *dnsStatKeeper {
 	statsKeeper := &dnsStatKeeper{
 		stats:            make(map[DNSKey]map[string]DNSStats),
 		state:            make(map[stateKey]stateValue),
 		expirationPeriod: timeout,
 		exit:             make(chan struct{}),
 		maxSize:          MaxStateMapSize,
+		collectDomains:   collectDomains,
+		maxQueryLogSize:  maxQueryLogSize,
 	}
 
 	ticker := time.NewTicker(statsKeeper.expirationPeriod)
@@ -90,7 +104,7 @@ func (d *dnsStatKeeper) ProcessPacketInfo(info dnsPacketInfo, ts time.Time) {
 		}
 
 		if _, ok := d.state[sk]; !ok {
-			d.state[sk] = stateValue{question: info.question, ts: microSecs(ts)}
+			d.state[sk] = stateValue{question: info.question, qType: info.qType, ts: microSecs(ts)}
 		}
 		return
 	}
@@ -107,11 +121,12 @@ func (d *dnsStatKeeper) ProcessPacketInfo(info dnsPacketInfo, ts time.Time) {
 
 	latency := microSecs(ts) - start.ts
 
+	domain := d.domain(start.question)
 	allStats, ok := d.stats[info.key]
 	if !ok {
 		allStats = make(map[string]DNSStats)
 	}
-	stats, ok := allStats[start.question]
+	stats, ok := allStats[domain]
 	if !ok {
 		stats.DNSCountByRcode = make(map[uint32]uint32)
 	}
@@ -119,7 +134,9 @@ func (d *dnsStatKeeper) ProcessPacketInfo(info dnsPacketInfo, ts time.Time) {
 	// Note: time.Duration in the agent version of go (1.12.9) does not have the Microseconds method.
 	if latency > uint64(d.expirationPeriod.Microseconds()) {
 		stats.DNSTimeouts++
+		d.logQuery(info.key, start, latency, 0, true)
 	} else {
+		d.logQuery(info.key, start, latency, info.rCode, false)
 		stats.DNSCountByRcode[uint32(info.rCode)]++
 		if info.pktType == SuccessfulResponse {
 			stats.DNSSuccessLatencySum += latency
@@ -128,10 +145,53 @@ func (d *dnsStatKeeper) ProcessPacketInfo(info dnsPacketInfo, ts time.Time) {
 		}
 	}
 
-	allStats[start.question] = stats
+	allStats[domain] = stats
 	d.stats[info.key] = allStats
 }
 
+// domain returns the domain by which the stats of a question are scoped
+func (d *dnsStatKeeper) domain(question string) string {
+	if !d.collectDomains {
+		return ""
+	}
+	return question
+}
+
+// logQuery adds a query to the query log, if it is enabled and not full. The caller must hold the lock.
+func (d *dnsStatKeeper) logQuery(key DNSKey, start stateValue, latency uint64, rCode uint8, timeout bool) {
+	if d.maxQueryLogSize <= 0 {
+		return
+	}
+	if len(d.queryLog) >= d.maxQueryLogSize {
+		d.queryLogDropped++
+		return
+	}
+
+	entry := newDNSQueryLogEntry(key, start.question, start.qType, start.ts, latency)
+	entry.Timeout = timeout
+	if !timeout {
+		entry.ResponseCode = rCode
+		entry.ResponseCodeName = dnsResponseCodeName(rCode)
+	}
+	d.queryLog = append(d.queryLog, entry)
+}
+
+// GetAndFlushQueryLog returns the queries logged since the previous call
+func (d *dnsStatKeeper) GetAndFlushQueryLog() []DNSQueryLogEntry {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	ret := d.queryLog
+	d.queryLog = nil
+	return ret
+}
+
+// QueryLogDropped returns the number of queries which were not logged because the query log was full
+func (d *dnsStatKeeper) QueryLogDropped() int64 {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	return d.queryLogDropped
+}
+
 func (d *dnsStatKeeper) GetAndResetAllStats() map[DNSKey]map[string]DNSStats {
 	d.mux.Lock()
 	defer d.mux.Unlock()
@@ -175,16 +235,18 @@ func (d *dnsStatKeeper) removeExpiredStates(earliestTs time.Time) {
 			delete(d.state, k)
 			d.deleteCount++
 			// When we expire a state, we need to increment timeout count for that key:domain
+			domain := d.domain(v.question)
 			allStats, ok := d.stats[k.key]
 			if !ok {
 				allStats = make(map[string]DNSStats)
 			}
-			stats, ok := allStats[v.question]
+			stats, ok := allStats[domain]
 			if !ok {
 				stats.DNSCountByRcode = make(map[uint32]uint32)
 			}
 			stats.DNSTimeouts++
-			allStats[v.question] = stats
+			allStats[domain] = stats
+			d.logQuery(k.key, v, threshold-v.ts+uint64(d.expirationPeriod.Microseconds()), 0, true)
 			d.stats[k.key] = allStats
 		}
 	}
@@ -34,7 +34,7 @@ func testLatency(
 	expectedTimeouts uint32,
 ) {
 	var d = "abc.com"
-	sk := newDNSStatkeeper(DNSTimeoutSecs * time.Second)
+	sk := newDNSStatkeeper(DNSTimeoutSecs*time.Second, true, 0)
 	key := getSampleDNSKey()
 	qPkt := dnsPacketInfo{transactionID: 1, pktType: Query, key: key, question: d}
 	then := time.Now()
@@ -71,7 +71,7 @@ func TestTimeout(t *testing.T) {
 }
 
 func TestExpiredStateRemoval(t *testing.T) {
-	sk := newDNSStatkeeper(DNSTimeoutSecs * time.Second)
+	sk := newDNSStatkeeper(DNSTimeoutSecs*time.Second, true, 0)
 	key := getSampleDNSKey()
 	var d = "abc.com"
 	qPkt1 := dnsPacketInfo{transactionID: 1, pktType: Query, key: key, question: d}
@@ -115,7 +115,7 @@ func BenchmarkStats(b *testing.B) {
 			b.ResetTimer()
 			b.ReportAllocs()
 			for i := 0; i < b.N; i++ {
-				sk := newDNSStatkeeper(1000 * time.Second)
+				sk := newDNSStatkeeper(1000*time.Second, true, 0)
 				for j := 0; j < numPackets; j++ {
 					sk.ProcessPacketInfo(packets[j], ts)
 				}
@@ -123,3 +123,42 @@ func BenchmarkStats(b *testing.B) {
 		})
 	}
 }
+
+func TestQueryLog(t *testing.T) {
+	sk := newDNSStatkeeper(DNSTimeoutSecs*time.Second, false, 2)
+	key := getSampleDNSKey()
+	then := time.Now()
+
+	sk.ProcessPacketInfo(dnsPacketInfo{transactionID: 1, pktType: Query, key: key, question: "abc.com", qType: 1}, then)
+	sk.ProcessPacketInfo(dnsPacketInfo{transactionID: 1, key: key, pktType: FailedResponse, rCode: DNSResponseCodeNXDomain}, then.Add(10*time.Microsecond))
+	sk.ProcessPacketInfo(dnsPacketInfo{transactionID: 2, pktType: Query, key: key, question: "def.com", qType: 28}, then)
+	sk.removeExpiredStates(then.Add(time.Microsecond))
+	sk.ProcessPacketInfo(dnsPacketInfo{transactionID: 3, pktType: Query, key: key, question: "ghi.com", qType: 1}, then)
+	sk.ProcessPacketInfo(dnsPacketInfo{transactionID: 3, key: key, pktType: SuccessfulResponse}, then)
+
+	// the stats aren't scoped by domain when the domains aren't collected
+	stats := sk.GetAndResetAllStats()
+	require.Contains(t, stats, key)
+	require.Len(t, stats[key], 1)
+	assert.Equal(t, uint32(1), stats[key][""].DNSTimeouts)
+
+	queries := sk.GetAndFlushQueryLog()
+	require.Len(t, queries, 2)
+	assert.Equal(t, "abc.com", queries[0].Question)
+	assert.Equal(t, "A", queries[0].QueryType)
+	assert.Equal(t, "NXDOMAIN", queries[0].ResponseCodeName)
+	assert.Equal(t, uint64(10), queries[0].LatencyMicros)
+	assert.Equal(t, "1.1.1.1", queries[0].ClientIP)
+	assert.Equal(t, "8.8.8.8", queries[0].ServerIP)
+	assert.Equal(t, "UDP", queries[0].Protocol)
+	assert.False(t, queries[0].Timeout)
+
+	assert.Equal(t, "def.com", queries[1].Question)
+	assert.Equal(t, "AAAA", queries[1].QueryType)
+	assert.True(t, queries[1].Timeout)
+	assert.Empty(t, queries[1].ResponseCodeName)
+
+	// the last query was dropped since the query log was full
+	assert.Equal(t, int64(1), sk.QueryLogDropped())
+	assert.Empty(t, sk.GetAndFlushQueryLog())
+}
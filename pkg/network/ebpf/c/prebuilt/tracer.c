@@ -526,6 +526,52 @@ int kprobe__tcp_set_state(struct pt_regs* ctx) {
     return 0;
 }
 
+SEC("kprobe/tcp_connect")
+int kprobe__tcp_connect(struct pt_regs* ctx) {
+    struct sock* sk = (struct sock*)PT_REGS_PARM1(ctx);
+    u64 pid_tgid = bpf_get_current_pid_tgid();
+    log_debug("kprobe/tcp_connect: tgid: %u, pid: %u\n", pid_tgid >> 32, pid_tgid & 0xFFFFFFFF);
+
+    return handle_tcp_connect(sk, pid_tgid);
+}
+
+SEC("kprobe/tcp_send_active_reset")
+int kprobe__tcp_send_active_reset(struct pt_regs* ctx) {
+    struct sock* sk = (struct sock*)PT_REGS_PARM1(ctx);
+    log_debug("kprobe/tcp_send_active_reset\n");
+
+    tcp_stats_t stats = { .rsts_sent = 1 };
+    return handle_tcp_event(sk, stats);
+}
+
+SEC("kprobe/tcp_reset")
+int kprobe__tcp_reset(struct pt_regs* ctx) {
+    struct sock* sk = (struct sock*)PT_REGS_PARM1(ctx);
+    log_debug("kprobe/tcp_reset\n");
+
+    tcp_stats_t stats = { .rsts_received = 1 };
+    return handle_tcp_event(sk, stats);
+}
+
+// tcp_send_probe0 is called each time a zero window probe is sent, as long as the remote advertises a zero window
+SEC("kprobe/tcp_send_probe0")
+int kprobe__tcp_send_probe0(struct pt_regs* ctx) {
+    struct sock* sk = (struct sock*)PT_REGS_PARM1(ctx);
+    log_debug("kprobe/tcp_send_probe0\n");
+
+    tcp_stats_t stats = { .zero_windows = 1 };
+    return handle_tcp_event(sk, stats);
+}
+
+SEC("kprobe/tcp_data_queue_ofo")
+int kprobe__tcp_data_queue_ofo(struct pt_regs* ctx) {
+    struct sock* sk = (struct sock*)PT_REGS_PARM1(ctx);
+    log_debug("kprobe/tcp_data_queue_ofo\n");
+
+    tcp_stats_t stats = { .out_of_order = 1 };
+    return handle_tcp_event(sk, stats);
+}
+
 SEC("kretprobe/inet_csk_accept")
 int kretprobe__inet_csk_accept(struct pt_regs* ctx) {
     struct sock* sk = (struct sock*)PT_REGS_RC(ctx);
@@ -773,6 +819,94 @@ int socket__http_filter(struct __sk_buff* skb) {
     return 0;
 }
 
+// This function is meant to be used as a BPF_PROG_TYPE_SOCKET_FILTER.
+// When attached to a RAW_SOCKET, this code filters out everything but the TCP segments starting with a TLS ClientHello
+// or ServerHello, from which the TLS version, cipher suite and server name of the connections are extracted.
+SEC("socket/tls_filter")
+int socket__tls_filter(struct __sk_buff* skb) {
+    skb_info_t skb_info;
+
+    if (!read_conn_tuple_skb(skb, &skb_info)) {
+        return 0;
+    }
+
+    if ((skb_info.tup.metadata & CONN_TYPE_TCP) == 0) {
+        return 0;
+    }
+
+    if (skb->len < skb_info.data_off + TLS_RECORD_HEADER_SIZE + 1) {
+        return 0;
+    }
+
+    if (load_byte(skb, skb_info.data_off) != TLS_CONTENT_TYPE_HANDSHAKE) {
+        return 0;
+    }
+
+    __u8 handshake_type = load_byte(skb, skb_info.data_off + TLS_RECORD_HEADER_SIZE);
+    if (handshake_type != TLS_HANDSHAKE_CLIENT_HELLO && handshake_type != TLS_HANDSHAKE_SERVER_HELLO) {
+        return 0;
+    }
+
+    return -1;
+}
+
+// This function is meant to be used as a BPF_PROG_TYPE_SOCKET_FILTER.
+// When attached to a RAW_SOCKET, this code filters out everything but the TCP segments of the ports listed in the
+// http2_ports map, which are either carrying a payload or closing the connection. The HTTP/2 frames are reassembled
+// and decoded in userspace since the HPACK decoding of the headers requires the whole history of the connection.
+SEC("socket/http2_filter")
+int socket__http2_filter(struct __sk_buff* skb) {
+    skb_info_t skb_info;
+
+    if (!read_conn_tuple_skb(skb, &skb_info)) {
+        return 0;
+    }
+
+    if ((skb_info.tup.metadata & CONN_TYPE_TCP) == 0) {
+        return 0;
+    }
+
+    if (skb->len <= skb_info.data_off && (skb_info.tcp_flags & (TCPHDR_FIN | TCPHDR_RST)) == 0) {
+        return 0;
+    }
+
+    __u16 sport = skb_info.tup.sport;
+    __u16 dport = skb_info.tup.dport;
+    if (bpf_map_lookup_elem(&http2_ports, &sport) == NULL && bpf_map_lookup_elem(&http2_ports, &dport) == NULL) {
+        return 0;
+    }
+
+    return -1;
+}
+
+// This socket filter captures the TCP segments sent from or to the ports of the protocol_ports map, which are either
+// carrying a payload or closing the connection. The Kafka and PostgreSQL messages are reassembled and decoded in
+// userspace.
+SEC("socket/protocol_filter")
+int socket__protocol_filter(struct __sk_buff* skb) {
+    skb_info_t skb_info;
+
+    if (!read_conn_tuple_skb(skb, &skb_info)) {
+        return 0;
+    }
+
+    if ((skb_info.tup.metadata & CONN_TYPE_TCP) == 0) {
+        return 0;
+    }
+
+    if (skb->len <= skb_info.data_off && (skb_info.tcp_flags & (TCPHDR_FIN | TCPHDR_RST)) == 0) {
+        return 0;
+    }
+
+    __u16 sport = skb_info.tup.sport;
+    __u16 dport = skb_info.tup.dport;
+    if (bpf_map_lookup_elem(&protocol_ports, &sport) == NULL && bpf_map_lookup_elem(&protocol_ports, &dport) == NULL) {
+        return 0;
+    }
+
+    return -1;
+}
+
 // This number will be interpreted by elf-loader to set the current running kernel version
 __u32 _version SEC("version") = 0xFFFFFFFE; // NOLINT(bugprone-reserved-identifier)
 
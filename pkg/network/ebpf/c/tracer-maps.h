@@ -154,6 +154,30 @@ struct bpf_map_def SEC("maps/http_batch_state") http_batch_state = {
     .namespace = "",
 };
 
+/* This map holds the ports whose TCP traffic is captured for the decoding of HTTP/2
+ * The keys are port numbers and the values are unused
+ */
+struct bpf_map_def SEC("maps/http2_ports") http2_ports = {
+    .type = BPF_MAP_TYPE_HASH,
+    .key_size = sizeof(__u16),
+    .value_size = sizeof(__u8),
+    .max_entries = 64,
+    .pinning = 0,
+    .namespace = "",
+};
+
+/* This map holds the ports whose TCP traffic is captured for the decoding of the Kafka and PostgreSQL protocols
+ * The keys are port numbers and the values identify the protocol, which is only used in userspace
+ */
+struct bpf_map_def SEC("maps/protocol_ports") protocol_ports = {
+    .type = BPF_MAP_TYPE_HASH,
+    .key_size = sizeof(__u16),
+    .value_size = sizeof(__u8),
+    .max_entries = 64,
+    .pinning = 0,
+    .namespace = "",
+};
+
 /* This map is used for telemetry in kernelspace
  * only key 0 is used
  * value is a telemetry object
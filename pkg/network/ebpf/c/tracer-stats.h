@@ -2,6 +2,7 @@
 #define __TRACER_STATS_H
 
 #include "tracer.h"
+#include "tcp_states.h"
 
 static int read_conn_tuple(conn_tuple_t* t, struct sock* skp, u64 pid_gid, metadata_mask_t type);
 
@@ -81,6 +82,27 @@ static __always_inline void update_tcp_stats(conn_tuple_t* t, tcp_stats_t stats)
 
     if (stats.retransmits > 0) {
         __sync_fetch_and_add(&val->retransmits, stats.retransmits);
+
+        // Connections whose SYN was sent but which aren't established yet are only retransmitting their SYN
+        if ((val->state_transitions & (1 << TCP_SYN_SENT)) && !(val->state_transitions & (1 << TCP_ESTABLISHED))) {
+            __sync_fetch_and_add(&val->syn_retries, stats.retransmits);
+        }
+    }
+
+    if (stats.rsts_sent > 0) {
+        __sync_fetch_and_add(&val->rsts_sent, stats.rsts_sent);
+    }
+
+    if (stats.rsts_received > 0) {
+        __sync_fetch_and_add(&val->rsts_received, stats.rsts_received);
+    }
+
+    if (stats.zero_windows > 0) {
+        __sync_fetch_and_add(&val->zero_windows, stats.zero_windows);
+    }
+
+    if (stats.out_of_order > 0) {
+        __sync_fetch_and_add(&val->out_of_order, stats.out_of_order);
     }
 
     if (stats.rtt > 0) {
@@ -117,4 +139,32 @@ static __always_inline int handle_retransmit(struct sock* sk, int segs) {
     return 0;
 }
 
+static __always_inline int handle_tcp_event(struct sock* sk, tcp_stats_t stats) {
+    conn_tuple_t t = {};
+    u64 zero = 0;
+
+    if (!read_conn_tuple(&t, sk, zero, CONN_TYPE_TCP)) {
+        return 0;
+    }
+
+    update_tcp_stats(&t, stats);
+
+    return 0;
+}
+
+// handle_tcp_connect tracks the connection as soon as its SYN is sent, so that the attempts which fail are
+// reported as well
+static __always_inline int handle_tcp_connect(struct sock* sk, u64 pid_tgid) {
+    conn_tuple_t t = {};
+
+    if (!read_conn_tuple(&t, sk, pid_tgid, CONN_TYPE_TCP)) {
+        return 0;
+    }
+
+    tcp_stats_t stats = { .state_transitions = (1 << TCP_SYN_SENT) };
+    update_tcp_stats(&t, stats);
+
+    return handle_message(&t, 0, 0, CONN_DIRECTION_OUTGOING);
+}
+
 #endif // __TRACER_STATS_H
@@ -63,6 +63,15 @@ typedef struct {
     __u32 rtt;
     __u32 rtt_var;
 
+    // Counters only updated when network_config.enable_tcp_failure_telemetry is set
+    __u32 rsts_sent;
+    __u32 rsts_received;
+    // Zero window probes sent while the remote advertises a zero window
+    __u32 zero_windows;
+    __u32 out_of_order;
+    // Retransmissions of the SYN, before the connection got established
+    __u32 syn_retries;
+
     // Bit mask containing all TCP state transitions tracked by our tracer
     __u16 state_transitions;
 } tcp_stats_t;
@@ -78,6 +87,7 @@ typedef struct {
 // tcp_flag_byte(th) (((u_int8_t *)th)[13])
 #define TCP_FLAGS_OFFSET 13
 #define TCPHDR_FIN 0x01
+#define TCPHDR_RST 0x04
 
 // skb_info_t embeds a conn_tuple_t extracted from the skb object as well as
 // some ancillary data such as the data offset (the byte offset pointing to
@@ -90,6 +100,12 @@ typedef struct {
     __u8 tcp_flags;
 } skb_info_t;
 
+// TLS record header: content type (1 byte), protocol version (2 bytes) and length (2 bytes)
+#define TLS_RECORD_HEADER_SIZE 5
+#define TLS_CONTENT_TYPE_HANDSHAKE 22
+#define TLS_HANDSHAKE_CLIENT_HELLO 1
+#define TLS_HANDSHAKE_SERVER_HELLO 2
+
 // This determines the size of the payload fragment that is captured for each HTTP request
 #define HTTP_BUFFER_SIZE 25
 // This controls the number of HTTP transactions read from userspace at a time
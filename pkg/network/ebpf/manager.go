@@ -46,6 +46,8 @@ func NewManager(closedHandler, httpHandler *ebpf.PerfHandler, runtimeTracer bool
 			{Name: string(probes.HttpInFlightMap)},
 			{Name: string(probes.HttpBatchesMap)},
 			{Name: string(probes.HttpBatchStateMap)},
+			{Name: string(probes.HTTP2PortsMap)},
+			{Name: string(probes.ProtocolPortsMap)},
 		},
 		PerfMaps: []*manager.PerfMap{
 			{
@@ -81,6 +83,11 @@ func NewManager(closedHandler, httpHandler *ebpf.PerfHandler, runtimeTracer bool
 			{Section: string(probes.UDPRecvMsgPre410), MatchFuncName: "^udp_recvmsg$"},
 			{Section: string(probes.UDPRecvMsgReturn), KProbeMaxActive: maxActive},
 			{Section: string(probes.TCPRetransmit)},
+			{Section: string(probes.TCPConnect)},
+			{Section: string(probes.TCPSendActiveReset)},
+			{Section: string(probes.TCPReset)},
+			{Section: string(probes.TCPSendProbe0)},
+			{Section: string(probes.TCPDataQueueOFO)},
 			{Section: string(probes.InetCskAcceptReturn), KProbeMaxActive: maxActive},
 			{Section: string(probes.TCPv4DestroySock)},
 			{Section: string(probes.UDPDestroySock)},
@@ -91,6 +98,9 @@ func NewManager(closedHandler, httpHandler *ebpf.PerfHandler, runtimeTracer bool
 			{Section: string(probes.Inet6BindRet), KProbeMaxActive: maxActive},
 			{Section: string(probes.SocketDnsFilter)},
 			{Section: string(probes.SocketHTTPFilter)},
+			{Section: string(probes.SocketTLSFilter)},
+			{Section: string(probes.SocketHTTP2Filter)},
+			{Section: string(probes.SocketProtocolFilter)},
 		},
 	}
 
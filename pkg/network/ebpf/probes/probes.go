@@ -62,6 +62,17 @@ const (
 	TCPRetransmit       ProbeName = "kprobe/tcp_retransmit_skb"
 	TCPRetransmitPre470 ProbeName = "kprobe/tcp_retransmit_skb/pre_4_7_0"
 
+	// TCPConnect traces the tcp_connect() kernel function, which sends the SYN of the outgoing connections
+	TCPConnect ProbeName = "kprobe/tcp_connect"
+	// TCPSendActiveReset traces the tcp_send_active_reset() kernel function, which sends a reset
+	TCPSendActiveReset ProbeName = "kprobe/tcp_send_active_reset"
+	// TCPReset traces the tcp_reset() kernel function, which handles the resets received
+	TCPReset ProbeName = "kprobe/tcp_reset"
+	// TCPSendProbe0 traces the tcp_send_probe0() kernel function, which sends the zero window probes
+	TCPSendProbe0 ProbeName = "kprobe/tcp_send_probe0"
+	// TCPDataQueueOFO traces the tcp_data_queue_ofo() kernel function, which queues the segments received out of order
+	TCPDataQueueOFO ProbeName = "kprobe/tcp_data_queue_ofo"
+
 	// InetCskAcceptReturn traces the return value for the inet_csk_accept syscall
 	InetCskAcceptReturn ProbeName = "kretprobe/inet_csk_accept"
 
@@ -80,6 +91,15 @@ const (
 
 	// SocketHTTPFilter is the socket probe for HTTP
 	SocketHTTPFilter ProbeName = "socket/http_filter"
+
+	// SocketTLSFilter is the socket probe for TLS handshakes
+	SocketTLSFilter ProbeName = "socket/tls_filter"
+
+	// SocketHTTP2Filter is the socket probe for HTTP/2
+	SocketHTTP2Filter ProbeName = "socket/http2_filter"
+
+	// SocketProtocolFilter is the socket probe for the Kafka and PostgreSQL protocols
+	SocketProtocolFilter ProbeName = "socket/protocol_filter"
 )
 
 // BPFMapName stores the name of the BPF maps storing statistics and other info
@@ -98,6 +118,8 @@ const (
 	HttpBatchesMap       BPFMapName = "http_batches"
 	HttpBatchStateMap    BPFMapName = "http_batch_state"
 	HttpNotificationsMap BPFMapName = "http_notifications"
+	HTTP2PortsMap        BPFMapName = "http2_ports"
+	ProtocolPortsMap     BPFMapName = "protocol_ports"
 )
 
 // SectionName returns the SectionName for the given BPF map
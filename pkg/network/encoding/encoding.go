@@ -14,6 +14,10 @@ var (
 		marshaller: jsonpb.Marshaler{
 			EmitDefaults: true,
 		},
+		// the extension of the payload is decoded separately
+		unmarshaller: jsonpb.Unmarshaler{
+			AllowUnknownFields: true,
+		},
 	}
 )
 
@@ -26,6 +30,8 @@ type Marshaler interface {
 // Unmarshaler is an interface implemented by all Connections deserializers
 type Unmarshaler interface {
 	Unmarshal([]byte) (*model.Connections, error)
+	// UnmarshalExtension returns the extension of the connections of the payload, or nil if it has none
+	UnmarshalExtension([]byte) (*ConnectionsExtension, error)
 }
 
 // GetMarshaler returns the appropriate Marshaler based on the given accept header
@@ -7,6 +7,7 @@ import (
 	model "github.com/DataDog/agent-payload/process"
 	"github.com/DataDog/datadog-agent/pkg/network"
 	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 	"github.com/DataDog/sketches-go/ddsketch"
 	"github.com/DataDog/sketches-go/ddsketch/pb/sketchpb"
@@ -217,6 +218,127 @@ func TestSerialization(t *testing.T) {
 	})
 }
 
+func TestSerializationExtension(t *testing.T) {
+	var requestStats protocols.RequestStats
+	requestStats.AddRequest(12.5)
+	latencies, err := proto.Marshal(requestStats.Latencies().ToProto())
+	require.NoError(t, err)
+
+	in := &network.Connections{
+		Conns: []network.ConnectionStats{
+			{
+				Source: util.AddressFromString("10.1.1.1"),
+				Dest:   util.AddressFromString("10.2.2.2"),
+				SPort:  1000,
+				DPort:  80,
+				Type:   network.TCP,
+			},
+			{
+				Source: util.AddressFromString("10.1.1.1"),
+				Dest:   util.AddressFromString("10.2.2.2"),
+				SPort:  1001,
+				DPort:  443,
+				Type:   network.TCP,
+				TLS: &network.TLSMetadata{
+					ServerName:  "example.com",
+					Version:     0x0303,
+					CipherSuite: 0xc02f,
+				},
+			},
+			{
+				Source: util.AddressFromString("10.1.1.1"),
+				Dest:   util.AddressFromString("10.3.3.3"),
+				SPort:  1002,
+				DPort:  9092,
+				Type:   network.TCP,
+				KafkaStats: map[protocols.KafkaRequestKey]protocols.RequestStats{
+					{APIKey: 1, Topic: "orders"}: requestStats,
+					{APIKey: 0, Topic: "orders"}: requestStats,
+				},
+			},
+			{
+				Source: util.AddressFromString("10.1.1.1"),
+				Dest:   util.AddressFromString("10.4.4.4"),
+				SPort:  1003,
+				DPort:  5432,
+				Type:   network.TCP,
+				PostgresStats: map[protocols.PostgresRequestKey]protocols.RequestStats{
+					{QueryType: protocols.PostgresSimpleQuery, Command: "SELECT"}: requestStats,
+				},
+			},
+			{
+				Source:               util.AddressFromString("10.1.1.1"),
+				Dest:                 util.AddressFromString("10.5.5.5"),
+				SPort:                1004,
+				DPort:                8080,
+				Type:                 network.TCP,
+				TCPFailure:           network.TCPFailureRefused,
+				MonotonicTCPCounters: network.TCPCounters{RstsReceived: 2, SynRetries: 1},
+				LastTCPCounters: network.TCPCounters{
+					RstsSent:     1,
+					RstsReceived: 2,
+					ZeroWindows:  3,
+					OutOfOrder:   4,
+					SynRetries:   5,
+				},
+			},
+		},
+	}
+
+	ext := &ConnectionsExtension{
+		Conns: map[int32]*ConnectionExtension{
+			1: {
+				Tls: &TLSMetadata{ServerName: "example.com", Version: 0x0303, CipherSuite: 0xc02f},
+			},
+			2: {
+				KafkaStats: []*KafkaStats{
+					{ApiKey: 0, Topic: "orders", Count: 1, Latencies: latencies},
+					{ApiKey: 1, Topic: "orders", Count: 1, Latencies: latencies},
+				},
+			},
+			3: {
+				PostgresStats: []*PostgresStats{
+					{QueryType: protocols.PostgresSimpleQuery, Command: "SELECT", Count: 1, Latencies: latencies},
+				},
+			},
+			4: {
+				LastTcpCounters: &TCPCounters{
+					RstsSent:     1,
+					RstsReceived: 2,
+					ZeroWindows:  3,
+					OutOfOrder:   4,
+					SynRetries:   5,
+				},
+				TcpFailure: "refused",
+			},
+		},
+	}
+
+	for _, contentType := range []string{ContentTypeJSON, ContentTypeProtobuf} {
+		t.Run(contentType, func(t *testing.T) {
+			blob, err := GetMarshaler(contentType).Marshal(in)
+			require.NoError(t, err)
+
+			unmarshaler := GetUnmarshaler(contentType)
+			result, err := unmarshaler.Unmarshal(blob)
+			require.NoError(t, err)
+			require.Len(t, result.Conns, 5)
+			assert.Equal(t, int32(443), result.Conns[1].Raddr.Port)
+
+			resultExt, err := unmarshaler.UnmarshalExtension(blob)
+			require.NoError(t, err)
+			assert.Equal(t, ext, resultExt)
+
+			// the payloads of connections without any extended field have no extension
+			blob, err = GetMarshaler(contentType).Marshal(&network.Connections{Conns: in.Conns[:1]})
+			require.NoError(t, err)
+			resultExt, err = unmarshaler.UnmarshalExtension(blob)
+			require.NoError(t, err)
+			assert.Nil(t, resultExt)
+		})
+	}
+}
+
 func TestFormatHTTPStatsByPath(t *testing.T) {
 	var httpReqStats http.RequestStats
 	httpReqStats.AddRequest(100, 12.5)
@@ -0,0 +1,249 @@
+package encoding
+
+import (
+	"sort"
+
+	"github.com/DataDog/datadog-agent/pkg/network"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
+	"github.com/gogo/protobuf/proto"
+)
+
+// ConnectionsExtension holds the fields of the connections which the agent-payload model has no field for yet. It is
+// encoded along the model.Connections of a payload, and the decoders which don't know it ignore it.
+type ConnectionsExtension struct {
+	// Conns holds the extension of the connections which have any, by index in the connections of the payload
+	Conns map[int32]*ConnectionExtension `protobuf:"bytes,1,rep,name=conns" json:"conns,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+// Reset implements proto.Message
+func (m *ConnectionsExtension) Reset() { *m = ConnectionsExtension{} }
+
+// String implements proto.Message
+func (m *ConnectionsExtension) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ConnectionsExtension) ProtoMessage() {}
+
+// ConnectionExtension holds the fields of a connection which the agent-payload model has no field for yet
+type ConnectionExtension struct {
+	Tls           *TLSMetadata     `protobuf:"bytes,1,opt,name=tls" json:"tls,omitempty"`
+	KafkaStats    []*KafkaStats    `protobuf:"bytes,2,rep,name=kafkaStats" json:"kafkaStats,omitempty"`
+	PostgresStats []*PostgresStats `protobuf:"bytes,3,rep,name=postgresStats" json:"postgresStats,omitempty"`
+	// LastTcpCounters holds the TCP counters since the previous payload, like the other last fields of a connection
+	LastTcpCounters *TCPCounters `protobuf:"bytes,4,opt,name=lastTcpCounters" json:"lastTcpCounters,omitempty"`
+	// TcpFailure is why the connection attempt failed: refused, timeout or aborted. It is empty for the connections
+	// which didn't fail to be established.
+	TcpFailure string `protobuf:"bytes,5,opt,name=tcpFailure,proto3" json:"tcpFailure,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *ConnectionExtension) Reset() { *m = ConnectionExtension{} }
+
+// String implements proto.Message
+func (m *ConnectionExtension) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ConnectionExtension) ProtoMessage() {}
+
+// TLSMetadata holds the metadata of the TLS handshake of a connection
+type TLSMetadata struct {
+	ServerName  string `protobuf:"bytes,1,opt,name=serverName,proto3" json:"serverName,omitempty"`
+	Version     uint32 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	CipherSuite uint32 `protobuf:"varint,3,opt,name=cipherSuite,proto3" json:"cipherSuite,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *TLSMetadata) Reset() { *m = TLSMetadata{} }
+
+// String implements proto.Message
+func (m *TLSMetadata) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*TLSMetadata) ProtoMessage() {}
+
+// TCPCounters holds the counters of the events signaling a TCP connection with losses or a struggling peer
+type TCPCounters struct {
+	RstsSent     uint32 `protobuf:"varint,1,opt,name=rstsSent,proto3" json:"rstsSent,omitempty"`
+	RstsReceived uint32 `protobuf:"varint,2,opt,name=rstsReceived,proto3" json:"rstsReceived,omitempty"`
+	ZeroWindows  uint32 `protobuf:"varint,3,opt,name=zeroWindows,proto3" json:"zeroWindows,omitempty"`
+	OutOfOrder   uint32 `protobuf:"varint,4,opt,name=outOfOrder,proto3" json:"outOfOrder,omitempty"`
+	SynRetries   uint32 `protobuf:"varint,5,opt,name=synRetries,proto3" json:"synRetries,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *TCPCounters) Reset() { *m = TCPCounters{} }
+
+// String implements proto.Message
+func (m *TCPCounters) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*TCPCounters) ProtoMessage() {}
+
+// KafkaStats holds the number of Kafka requests of an API and topic sent over a connection, and the serialized
+// sketch of their latencies in milliseconds
+type KafkaStats struct {
+	ApiKey    int32  `protobuf:"varint,1,opt,name=apiKey,proto3" json:"apiKey,omitempty"`
+	Topic     string `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"`
+	Count     uint32 `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	Latencies []byte `protobuf:"bytes,4,opt,name=latencies,proto3" json:"latencies,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *KafkaStats) Reset() { *m = KafkaStats{} }
+
+// String implements proto.Message
+func (m *KafkaStats) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*KafkaStats) ProtoMessage() {}
+
+// PostgresStats holds the number of PostgreSQL queries of a type and command sent over a connection, and the
+// serialized sketch of their latencies in milliseconds
+type PostgresStats struct {
+	QueryType string `protobuf:"bytes,1,opt,name=queryType,proto3" json:"queryType,omitempty"`
+	Command   string `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
+	Count     uint32 `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	Latencies []byte `protobuf:"bytes,4,opt,name=latencies,proto3" json:"latencies,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *PostgresStats) Reset() { *m = PostgresStats{} }
+
+// String implements proto.Message
+func (m *PostgresStats) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*PostgresStats) ProtoMessage() {}
+
+// connectionsWithExtension is the message holding the extension of a payload. Its encoding is appended to the one of
+// the model.Connections, which protobuf decodes as the merge of both messages. The field of the extension is far above
+// the fields of model.Connections so that they never collide, and the decoders which don't know it skip it.
+type connectionsWithExtension struct {
+	Extension *ConnectionsExtension `protobuf:"bytes,1000,opt,name=extension" json:"extension,omitempty"`
+}
+
+func (m *connectionsWithExtension) Reset()         { *m = connectionsWithExtension{} }
+func (m *connectionsWithExtension) String() string { return proto.CompactTextString(m) }
+func (*connectionsWithExtension) ProtoMessage()    {}
+
+// FormatExtension returns the extension of the connections, or nil if none of them has any field to extend
+func FormatExtension(conns []network.ConnectionStats) *ConnectionsExtension {
+	var ext *ConnectionsExtension
+	for i := range conns {
+		c := formatConnectionExtension(&conns[i])
+		if c == nil {
+			continue
+		}
+		if ext == nil {
+			ext = &ConnectionsExtension{Conns: make(map[int32]*ConnectionExtension)}
+		}
+		ext.Conns[int32(i)] = c
+	}
+	return ext
+}
+
+func formatConnectionExtension(conn *network.ConnectionStats) *ConnectionExtension {
+	if conn.TLS == nil && len(conn.KafkaStats) == 0 && len(conn.PostgresStats) == 0 &&
+		conn.LastTCPCounters == (network.TCPCounters{}) && conn.TCPFailure == network.TCPFailureNone {
+		return nil
+	}
+
+	return &ConnectionExtension{
+		Tls:             formatTLSMetadata(conn.TLS),
+		KafkaStats:      formatKafkaStats(conn.KafkaStats),
+		PostgresStats:   formatPostgresStats(conn.PostgresStats),
+		LastTcpCounters: formatTCPCounters(conn.LastTCPCounters),
+		TcpFailure:      formatTCPFailure(conn.TCPFailure),
+	}
+}
+
+func formatTLSMetadata(tls *network.TLSMetadata) *TLSMetadata {
+	if tls == nil {
+		return nil
+	}
+
+	return &TLSMetadata{
+		ServerName:  tls.ServerName,
+		Version:     uint32(tls.Version),
+		CipherSuite: uint32(tls.CipherSuite),
+	}
+}
+
+func formatTCPCounters(counters network.TCPCounters) *TCPCounters {
+	if counters == (network.TCPCounters{}) {
+		return nil
+	}
+
+	return &TCPCounters{
+		RstsSent:     counters.RstsSent,
+		RstsReceived: counters.RstsReceived,
+		ZeroWindows:  counters.ZeroWindows,
+		OutOfOrder:   counters.OutOfOrder,
+		SynRetries:   counters.SynRetries,
+	}
+}
+
+func formatTCPFailure(reason network.TCPFailureReason) string {
+	if reason == network.TCPFailureNone {
+		return ""
+	}
+	return reason.String()
+}
+
+// formatKafkaStats returns the Kafka stats sorted by API and topic
+func formatKafkaStats(stats map[protocols.KafkaRequestKey]protocols.RequestStats) []*KafkaStats {
+	if len(stats) == 0 {
+		return nil
+	}
+
+	formatted := make([]*KafkaStats, 0, len(stats))
+	for key, s := range stats {
+		count, latencies := formatRequestStats(s)
+		formatted = append(formatted, &KafkaStats{
+			ApiKey:    int32(key.APIKey),
+			Topic:     key.Topic,
+			Count:     count,
+			Latencies: latencies,
+		})
+	}
+	sort.Slice(formatted, func(i, j int) bool {
+		if formatted[i].ApiKey != formatted[j].ApiKey {
+			return formatted[i].ApiKey < formatted[j].ApiKey
+		}
+		return formatted[i].Topic < formatted[j].Topic
+	})
+	return formatted
+}
+
+// formatPostgresStats returns the PostgreSQL stats sorted by query type and command
+func formatPostgresStats(stats map[protocols.PostgresRequestKey]protocols.RequestStats) []*PostgresStats {
+	if len(stats) == 0 {
+		return nil
+	}
+
+	formatted := make([]*PostgresStats, 0, len(stats))
+	for key, s := range stats {
+		count, latencies := formatRequestStats(s)
+		formatted = append(formatted, &PostgresStats{
+			QueryType: key.QueryType,
+			Command:   key.Command,
+			Count:     count,
+			Latencies: latencies,
+		})
+	}
+	sort.Slice(formatted, func(i, j int) bool {
+		if formatted[i].QueryType != formatted[j].QueryType {
+			return formatted[i].QueryType < formatted[j].QueryType
+		}
+		return formatted[i].Command < formatted[j].Command
+	})
+	return formatted
+}
+
+func formatRequestStats(stats protocols.RequestStats) (uint32, []byte) {
+	var latencyBytes []byte
+	if latencies := stats.Latencies(); latencies != nil {
+		latencyBytes, _ = proto.Marshal(latencies.ToProto())
+	}
+	return uint32(stats.Count()), latencyBytes
+}
@@ -2,6 +2,7 @@ package encoding
 
 import (
 	"bytes"
+	"encoding/json"
 
 	model "github.com/DataDog/agent-payload/process"
 	"github.com/DataDog/datadog-agent/pkg/network"
@@ -11,8 +12,12 @@ import (
 // ContentTypeJSON holds the HTML content-type of a JSON payload
 const ContentTypeJSON = "application/json"
 
+// jsonExtensionKey is the key of the payload object holding the extension of the connections
+const jsonExtensionKey = "extension"
+
 type jsonSerializer struct {
-	marshaller jsonpb.Marshaler
+	marshaller   jsonpb.Marshaler
+	unmarshaller jsonpb.Unmarshaler
 }
 
 func (j jsonSerializer) Marshal(conns *network.Connections) ([]byte, error) {
@@ -37,18 +42,57 @@ func (j jsonSerializer) Marshal(conns *network.Connections) ([]byte, error) {
 	writer := new(bytes.Buffer)
 	err := j.marshaller.Marshal(writer, payload)
 	returnToPool(payload)
-	return writer.Bytes(), err
+	if err != nil {
+		return nil, err
+	}
+
+	ext := FormatExtension(conns.Conns)
+	if ext == nil {
+		return writer.Bytes(), nil
+	}
+
+	// the extension is added as the last key of the payload object
+	// unlike the payload, the extension only holds the fields which are set
+	extWriter := new(bytes.Buffer)
+	if err := (&jsonpb.Marshaler{}).Marshal(extWriter, ext); err != nil {
+		return nil, err
+	}
+	blob := bytes.TrimRight(writer.Bytes(), " \n")
+	blob = blob[:len(blob)-1]
+	if !bytes.HasSuffix(bytes.TrimSpace(blob), []byte("{")) {
+		blob = append(blob, ',')
+	}
+	blob = append(blob, `"`+jsonExtensionKey+`":`...)
+	blob = append(blob, extWriter.Bytes()...)
+	return append(blob, '}'), nil
 }
 
-func (jsonSerializer) Unmarshal(blob []byte) (*model.Connections, error) {
+func (j jsonSerializer) Unmarshal(blob []byte) (*model.Connections, error) {
 	conns := new(model.Connections)
 	reader := bytes.NewReader(blob)
-	if err := jsonpb.Unmarshal(reader, conns); err != nil {
+	if err := j.unmarshaller.Unmarshal(reader, conns); err != nil {
 		return nil, err
 	}
 	return conns, nil
 }
 
+func (j jsonSerializer) UnmarshalExtension(blob []byte) (*ConnectionsExtension, error) {
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(blob, &payload); err != nil {
+		return nil, err
+	}
+
+	raw, ok := payload[jsonExtensionKey]
+	if !ok {
+		return nil, nil
+	}
+	ext := new(ConnectionsExtension)
+	if err := j.unmarshaller.Unmarshal(bytes.NewReader(raw), ext); err != nil {
+		return nil, err
+	}
+	return ext, nil
+}
+
 func (j jsonSerializer) ContentType() string {
 	return ContentTypeJSON
 }
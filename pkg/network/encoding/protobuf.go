@@ -32,7 +32,18 @@ func (protoSerializer) Marshal(conns *network.Connections) ([]byte, error) {
 
 	buf, err := proto.Marshal(payload)
 	returnToPool(payload)
-	return buf, err
+	if err != nil {
+		return nil, err
+	}
+
+	if ext := FormatExtension(conns.Conns); ext != nil {
+		extBuf, err := proto.Marshal(&connectionsWithExtension{Extension: ext})
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, extBuf...)
+	}
+	return buf, nil
 }
 
 func (protoSerializer) Unmarshal(blob []byte) (*model.Connections, error) {
@@ -43,6 +54,14 @@ func (protoSerializer) Unmarshal(blob []byte) (*model.Connections, error) {
 	return conns, nil
 }
 
+func (protoSerializer) UnmarshalExtension(blob []byte) (*ConnectionsExtension, error) {
+	var m connectionsWithExtension
+	if err := proto.Unmarshal(blob, &m); err != nil {
+		return nil, err
+	}
+	return m.Extension, nil
+}
+
 func (p protoSerializer) ContentType() string {
 	return ContentTypeProtobuf
 }
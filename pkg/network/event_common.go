@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 	"github.com/dustin/go-humanize"
 )
@@ -77,6 +78,95 @@ func (d ConnectionDirection) String() string {
 	}
 }
 
+// TCPFailureReason indicates why a TCP connection attempt failed. It is set on the TCP connections closed before
+// their handshake completed.
+type TCPFailureReason uint8
+
+const (
+	// TCPFailureNone is the reason of the connections which didn't fail to be established
+	TCPFailureNone TCPFailureReason = 0
+
+	// TCPFailureRefused represents connection attempts which received a reset
+	TCPFailureRefused TCPFailureReason = 1
+
+	// TCPFailureTimeout represents connection attempts whose SYN was retransmitted without getting any response
+	TCPFailureTimeout TCPFailureReason = 2
+
+	// TCPFailureAborted represents connection attempts closed before their SYN was answered or retransmitted
+	TCPFailureAborted TCPFailureReason = 3
+)
+
+func (r TCPFailureReason) String() string {
+	switch r {
+	case TCPFailureRefused:
+		return "refused"
+	case TCPFailureTimeout:
+		return "timeout"
+	case TCPFailureAborted:
+		return "aborted"
+	default:
+		return "none"
+	}
+}
+
+// ConnectFailureReason returns the reason why a connection attempt, closed before its handshake completed, failed
+func ConnectFailureReason(counters TCPCounters) TCPFailureReason {
+	if counters.RstsReceived > 0 {
+		return TCPFailureRefused
+	}
+	if counters.SynRetries > 0 {
+		return TCPFailureTimeout
+	}
+	return TCPFailureAborted
+}
+
+// TCPCounters holds the counters of the events signaling a TCP connection with losses or a struggling peer
+type TCPCounters struct {
+	RstsSent     uint32
+	RstsReceived uint32
+	// ZeroWindows is the number of zero window probes sent while the remote advertises a zero window
+	ZeroWindows uint32
+	OutOfOrder  uint32
+	// SynRetries is the number of retransmissions of the SYN, before the connection got established
+	SynRetries uint32
+}
+
+// Add returns the sum of both counters
+func (c TCPCounters) Add(o TCPCounters) TCPCounters {
+	return TCPCounters{
+		RstsSent:     c.RstsSent + o.RstsSent,
+		RstsReceived: c.RstsReceived + o.RstsReceived,
+		ZeroWindows:  c.ZeroWindows + o.ZeroWindows,
+		OutOfOrder:   c.OutOfOrder + o.OutOfOrder,
+		SynRetries:   c.SynRetries + o.SynRetries,
+	}
+}
+
+// Sub returns the difference between both counters
+func (c TCPCounters) Sub(o TCPCounters) TCPCounters {
+	return TCPCounters{
+		RstsSent:     c.RstsSent - o.RstsSent,
+		RstsReceived: c.RstsReceived - o.RstsReceived,
+		ZeroWindows:  c.ZeroWindows - o.ZeroWindows,
+		OutOfOrder:   c.OutOfOrder - o.OutOfOrder,
+		SynRetries:   c.SynRetries - o.SynRetries,
+	}
+}
+
+// Underflows returns whether any of the counters is lower than the one of o, the subtraction overflowing then
+func (c TCPCounters) Underflows(o TCPCounters) bool {
+	return c.RstsSent < o.RstsSent ||
+		c.RstsReceived < o.RstsReceived ||
+		c.ZeroWindows < o.ZeroWindows ||
+		c.OutOfOrder < o.OutOfOrder ||
+		c.SynRetries < o.SynRetries
+}
+
+// IsZero returns whether none of the events happened
+func (c TCPCounters) IsZero() bool {
+	return c == TCPCounters{}
+}
+
 // Connections wraps a collection of ConnectionStats
 type Connections struct {
 	DNS       map[util.Address][]string
@@ -96,6 +186,11 @@ type ConnectionsTelemetry struct {
 	MonotonicUDPSendsProcessed         int64
 	MonotonicUDPSendsMissed            int64
 	ConntrackSamplingPercent           int64
+	ConnsAggregated                    int64
+	ConnsSampledOut                    int64
+	ConnsOverflow                      int64
+	ConnsOverflowSentBytes             int64
+	ConnsOverflowRecvBytes             int64
 }
 
 // ConnectionStats stores statistics for a single connection.  Field order in the struct should be 8-byte aligned
@@ -130,6 +225,10 @@ type ConnectionStats struct {
 	MonotonicTCPClosed uint32
 	LastTCPClosed      uint32
 
+	// MonotonicTCPCounters is only collected when network_config.enable_tcp_failure_telemetry is set
+	MonotonicTCPCounters TCPCounters
+	LastTCPCounters      TCPCounters
+
 	Pid   uint32
 	NetNS uint32
 
@@ -138,6 +237,7 @@ type ConnectionStats struct {
 	Type                   ConnectionType
 	Family                 ConnectionFamily
 	Direction              ConnectionDirection
+	TCPFailure             TCPFailureReason
 	IPTranslation          *IPTranslation
 	IntraHost              bool
 	DNSSuccessfulResponses uint32
@@ -148,6 +248,9 @@ type ConnectionStats struct {
 	DNSCountByRcode        map[uint32]uint32
 	DNSStatsByDomain       map[string]DNSStats
 	HTTPStatsByPath        map[string]http.RequestStats
+	KafkaStats             map[protocols.KafkaRequestKey]protocols.RequestStats
+	PostgresStats          map[protocols.PostgresRequestKey]protocols.RequestStats
+	TLS                    *TLSMetadata
 }
 
 // IPTranslation can be associated with a connection to show the connection is NAT'd
@@ -244,6 +347,29 @@ func ConnectionSummary(c *ConnectionStats, names map[util.Address][]string) stri
 			time.Duration(c.RTT)*time.Microsecond,
 			time.Duration(c.RTTVar)*time.Microsecond,
 		)
+
+		if !c.MonotonicTCPCounters.IsZero() {
+			str += fmt.Sprintf(
+				", %d/%d resets sent/received, %d zero windows, %d out of order, %d SYN retries",
+				c.MonotonicTCPCounters.RstsSent, c.MonotonicTCPCounters.RstsReceived,
+				c.MonotonicTCPCounters.ZeroWindows,
+				c.MonotonicTCPCounters.OutOfOrder,
+				c.MonotonicTCPCounters.SynRetries,
+			)
+		}
+		if c.TCPFailure != TCPFailureNone {
+			str += fmt.Sprintf(", connection failed (%s)", c.TCPFailure)
+		}
+	}
+
+	if c.TLS != nil {
+		str += fmt.Sprintf(", %s", c.TLS.VersionString())
+		if cipherSuite := c.TLS.CipherSuiteString(); cipherSuite != "" {
+			str += fmt.Sprintf(" %s", cipherSuite)
+		}
+		if c.TLS.ServerName != "" {
+			str += fmt.Sprintf(" (SNI: %s)", c.TLS.ServerName)
+		}
 	}
 
 	return str
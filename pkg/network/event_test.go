@@ -131,3 +131,22 @@ func BenchmarkByteKey(b *testing.B) {
 	}
 	runtime.KeepAlive(buf)
 }
+
+func TestConnectFailureReason(t *testing.T) {
+	assert.Equal(t, TCPFailureRefused, ConnectFailureReason(TCPCounters{RstsReceived: 1, SynRetries: 2}))
+	assert.Equal(t, TCPFailureTimeout, ConnectFailureReason(TCPCounters{SynRetries: 6}))
+	assert.Equal(t, TCPFailureAborted, ConnectFailureReason(TCPCounters{}))
+	assert.Equal(t, "refused", TCPFailureRefused.String())
+	assert.Equal(t, "none", TCPFailureNone.String())
+}
+
+func TestTCPCounters(t *testing.T) {
+	prev := TCPCounters{RstsSent: 1, ZeroWindows: 2}
+	cur := prev.Add(TCPCounters{RstsSent: 1, OutOfOrder: 4})
+	assert.Equal(t, TCPCounters{RstsSent: 2, ZeroWindows: 2, OutOfOrder: 4}, cur)
+	assert.Equal(t, TCPCounters{RstsSent: 1, OutOfOrder: 4}, cur.Sub(prev))
+	assert.False(t, cur.Underflows(prev))
+	assert.True(t, prev.Underflows(cur))
+	assert.True(t, TCPCounters{}.IsZero())
+	assert.False(t, cur.IsZero())
+}
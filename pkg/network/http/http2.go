@@ -0,0 +1,633 @@
+package http
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"golang.org/x/net/http2/hpack"
+)
+
+const (
+	http2FrameHeaderLen = 9
+
+	http2FrameData         = 0x0
+	http2FrameHeaders      = 0x1
+	http2FrameRSTStream    = 0x3
+	http2FrameSettings     = 0x4
+	http2FramePushPromise  = 0x5
+	http2FrameContinuation = 0x9
+
+	http2FlagEndStream  = 0x1
+	http2FlagAck        = 0x1
+	http2FlagEndHeaders = 0x4
+	http2FlagPadded     = 0x8
+	http2FlagPriority   = 0x20
+
+	http2SettingHeaderTableSize = 0x1
+	http2DefaultHeaderTableSize = 4096
+
+	// http2MaxFrameBuffered is the largest HEADERS, CONTINUATION or SETTINGS frame buffered. Larger frames make the
+	// connection untracked since the HPACK state can't be kept in sync without decoding them.
+	http2MaxFrameBuffered = 64 * 1024
+	// http2MaxHeaderBlockSize is the largest header block, spread over a HEADERS and its CONTINUATION frames
+	http2MaxHeaderBlockSize = 64 * 1024
+
+	http2MaxConnections          = 10000
+	http2MaxStreamsPerConnection = 1000
+	http2ConnectionTimeout       = 2 * time.Minute
+	http2StreamTimeout           = 2 * time.Minute
+	http2ExpirationPeriod        = 30 * time.Second
+
+	// http2NoGRPCStatus is the gRPC status of the streams that didn't receive any grpc-status header
+	http2NoGRPCStatus = -1
+)
+
+// http2Preface is the connection preface sent by the clients, see RFC 7540 section 3.5
+var http2Preface = []byte("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n")
+
+var (
+	errHTTP2Desync         = errors.New("lost bytes that are required to decode the HTTP/2 frames")
+	errHTTP2FrameTooLarge  = errors.New("HTTP/2 frame too large")
+	errHTTP2InvalidFrame   = errors.New("invalid HTTP/2 frame")
+	errHTTP2UnexpectedCont = errors.New("unexpected HTTP/2 CONTINUATION frame")
+)
+
+// http2Transaction is a request and its response, exchanged over an HTTP/2 stream
+type http2Transaction struct {
+	// Key holds the client as source and the server as destination
+	Key    Key
+	Method string
+	Path   string
+	// Status is the :status pseudo-header of the response
+	Status int
+	// GRPCStatus is the grpc-status of the response, or http2NoGRPCStatus for the non-gRPC streams
+	GRPCStatus int
+	// Latency is the time in milliseconds between the request headers and the end of the response
+	Latency float64
+}
+
+// StatusCode returns the HTTP status code of the transaction. The gRPC status, when it isn't OK, takes precedence
+// over the :status pseudo-header which is always 200 for the gRPC calls that reached the server.
+func (tx *http2Transaction) StatusCode() int {
+	if tx.GRPCStatus > 0 {
+		return grpcStatusToHTTP(tx.GRPCStatus)
+	}
+	return tx.Status
+}
+
+// StatusClass returns an integer representing the status code class
+// Example: a 404 would return 400
+func (tx *http2Transaction) StatusClass() int {
+	return (tx.StatusCode() / 100) * 100
+}
+
+// grpcStatusToHTTP maps the gRPC status codes to the HTTP status codes, following the mapping of grpc-gateway
+func grpcStatusToHTTP(code int) int {
+	switch code {
+	case 0: // OK
+		return 200
+	case 1: // Canceled
+		return 499
+	case 3, 9, 11: // InvalidArgument, FailedPrecondition, OutOfRange
+		return 400
+	case 4: // DeadlineExceeded
+		return 504
+	case 5: // NotFound
+		return 404
+	case 6, 10: // AlreadyExists, Aborted
+		return 409
+	case 7: // PermissionDenied
+		return 403
+	case 8: // ResourceExhausted
+		return 429
+	case 12: // Unimplemented
+		return 501
+	case 14: // Unavailable
+		return 503
+	case 16: // Unauthenticated
+		return 401
+	default: // Unknown, Internal, DataLoss and the codes unknown to this mapping
+		return 500
+	}
+}
+
+// tcpSegment is the part of a TCP segment needed to reassemble the HTTP/2 frames of a connection
+type tcpSegment struct {
+	Source     util.Address
+	Dest       util.Address
+	SourcePort uint16
+	DestPort   uint16
+	Seq        uint32
+	// Payload is the captured payload, which may be truncated
+	Payload []byte
+	// Len is the length of the payload on the wire
+	Len int
+	// Closing is set for the segments with the FIN or RST flag
+	Closing bool
+}
+
+// http2Parser decodes the HTTP/2 frames exchanged over TCP connections into transactions. Only the connections whose
+// preface was observed are tracked since the HPACK decoding depends on all the headers previously exchanged.
+//
+// The parser isn't safe for concurrent use, except for GetStats.
+type http2Parser struct {
+	// Telemetry is at the beginning of the struct to keep all fields 64-bit aligned.
+	// see https://staticcheck.io/docs/checks#SA1027
+	transactions       int64
+	decodingErrors     int64
+	droppedConnections int64
+	droppedStreams     int64
+	resetStreams       int64
+	expiredStreams     int64
+	connections        int64
+
+	conns          map[Key]*http2Conn
+	maxConns       int
+	maxStreams     int
+	lastExpiration time.Time
+}
+
+func newHTTP2Parser() *http2Parser {
+	return &http2Parser{
+		conns:      make(map[Key]*http2Conn),
+		maxConns:   http2MaxConnections,
+		maxStreams: http2MaxStreamsPerConnection,
+	}
+}
+
+// Process decodes the HTTP/2 frames of a TCP segment and returns the transactions that completed
+func (p *http2Parser) Process(seg *tcpSegment, now time.Time) []http2Transaction {
+	if now.Sub(p.lastExpiration) >= http2ExpirationPeriod {
+		p.expire(now)
+		p.lastExpiration = now
+	}
+
+	key := Key{SourceIP: seg.Source, DestIP: seg.Dest, SourcePort: seg.SourcePort, DestPort: seg.DestPort}
+	fromClient := true
+	conn, ok := p.conns[key]
+	if !ok {
+		key = Key{SourceIP: seg.Dest, DestIP: seg.Source, SourcePort: seg.DestPort, DestPort: seg.SourcePort}
+		if conn, ok = p.conns[key]; ok {
+			fromClient = false
+		}
+	}
+
+	if !ok {
+		if seg.Closing || !bytes.HasPrefix(seg.Payload, http2Preface) {
+			return nil
+		}
+		if len(p.conns) >= p.maxConns {
+			atomic.AddInt64(&p.droppedConnections, 1)
+			return nil
+		}
+		key = Key{SourceIP: seg.Source, DestIP: seg.Dest, SourcePort: seg.SourcePort, DestPort: seg.DestPort}
+		conn = newHTTP2Conn(key, p.maxStreams)
+		// the preface isn't followed by a frame header
+		conn.client.skip = len(http2Preface)
+		p.conns[key] = conn
+		atomic.StoreInt64(&p.connections, int64(len(p.conns)))
+	}
+
+	conn.lastSeen = now
+	err := conn.process(fromClient, seg, now)
+	if err != nil {
+		atomic.AddInt64(&p.decodingErrors, 1)
+	}
+	if err != nil || seg.Closing {
+		delete(p.conns, key)
+		atomic.StoreInt64(&p.connections, int64(len(p.conns)))
+	}
+
+	txs := conn.flush()
+	atomic.AddInt64(&p.transactions, int64(len(txs)))
+	atomic.AddInt64(&p.droppedStreams, conn.droppedStreams)
+	atomic.AddInt64(&p.resetStreams, conn.resetStreams)
+	conn.droppedStreams, conn.resetStreams = 0, 0
+	return txs
+}
+
+// expire forgets the idle connections and the streams that didn't complete in time
+func (p *http2Parser) expire(now time.Time) {
+	for key, conn := range p.conns {
+		if now.Sub(conn.lastSeen) > http2ConnectionTimeout {
+			delete(p.conns, key)
+			continue
+		}
+		for id, stream := range conn.streams {
+			if now.Sub(stream.start) > http2StreamTimeout {
+				delete(conn.streams, id)
+				atomic.AddInt64(&p.expiredStreams, 1)
+			}
+		}
+	}
+	atomic.StoreInt64(&p.connections, int64(len(p.conns)))
+}
+
+// GetStats returns stats for use with telemetry
+func (p *http2Parser) GetStats() map[string]int64 {
+	return map[string]int64{
+		"transactions":        atomic.LoadInt64(&p.transactions),
+		"decoding_errors":     atomic.LoadInt64(&p.decodingErrors),
+		"dropped_connections": atomic.LoadInt64(&p.droppedConnections),
+		"dropped_streams":     atomic.LoadInt64(&p.droppedStreams),
+		"reset_streams":       atomic.LoadInt64(&p.resetStreams),
+		"expired_streams":     atomic.LoadInt64(&p.expiredStreams),
+		"connections":         atomic.LoadInt64(&p.connections),
+	}
+}
+
+// http2Stream is a request waiting for the end of its response
+type http2Stream struct {
+	method     string
+	path       string
+	status     int
+	grpcStatus int
+	start      time.Time
+}
+
+// http2Conn holds the decoding state of both directions of a connection and its pending streams
+type http2Conn struct {
+	key        Key
+	client     http2Direction
+	server     http2Direction
+	streams    map[uint32]*http2Stream
+	maxStreams int
+	lastSeen   time.Time
+
+	completed      []http2Transaction
+	droppedStreams int64
+	resetStreams   int64
+}
+
+func newHTTP2Conn(key Key, maxStreams int) *http2Conn {
+	return &http2Conn{
+		key:        key,
+		client:     newHTTP2Direction(),
+		server:     newHTTP2Direction(),
+		streams:    make(map[uint32]*http2Stream),
+		maxStreams: maxStreams,
+	}
+}
+
+func (c *http2Conn) process(fromClient bool, seg *tcpSegment, now time.Time) error {
+	d, peer := &c.client, &c.server
+	if !fromClient {
+		d, peer = &c.server, &c.client
+	}
+
+	return d.feed(seg.Seq, seg.Payload, seg.Len, func(h http2FrameHeader, payload []byte) error {
+		return c.onFrame(fromClient, d, peer, h, payload, now)
+	})
+}
+
+func (c *http2Conn) flush() []http2Transaction {
+	txs := c.completed
+	c.completed = nil
+	return txs
+}
+
+func (c *http2Conn) onFrame(fromClient bool, d, peer *http2Direction, h http2FrameHeader, payload []byte, now time.Time) error {
+	if d.blockStream != 0 && h.typ != http2FrameContinuation {
+		// a header block must be continued by CONTINUATION frames only
+		return errHTTP2InvalidFrame
+	}
+
+	switch h.typ {
+	case http2FrameData:
+		if h.flags&http2FlagEndStream != 0 && !fromClient {
+			c.endStream(h.streamID, now)
+		}
+	case http2FrameHeaders:
+		fragment, ok := headersFragment(h, payload)
+		if !ok {
+			return errHTTP2InvalidFrame
+		}
+		return c.onHeaderFragment(fromClient, d, h.streamID, h.flags, fragment, now)
+	case http2FramePushPromise:
+		fragment, ok := pushPromiseFragment(h, payload)
+		if !ok {
+			return errHTTP2InvalidFrame
+		}
+		// the pushed requests are decoded to keep the HPACK state in sync but not tracked
+		d.blockPromise = true
+		return c.onHeaderFragment(fromClient, d, h.streamID, h.flags, fragment, now)
+	case http2FrameContinuation:
+		if d.blockStream == 0 || d.blockStream != h.streamID {
+			return errHTTP2UnexpectedCont
+		}
+		return c.onHeaderFragment(fromClient, d, h.streamID, d.blockFlags&http2FlagEndStream|h.flags, payload, now)
+	case http2FrameRSTStream:
+		if _, ok := c.streams[h.streamID]; ok {
+			delete(c.streams, h.streamID)
+			c.resetStreams++
+		}
+	case http2FrameSettings:
+		if h.flags&http2FlagAck != 0 {
+			return nil
+		}
+		// the header table size announced by an endpoint bounds the dynamic table of the headers it receives
+		for ; len(payload) >= 6; payload = payload[6:] {
+			if binary.BigEndian.Uint16(payload) == http2SettingHeaderTableSize {
+				peer.decoder.SetAllowedMaxDynamicTableSize(binary.BigEndian.Uint32(payload[2:]))
+			}
+		}
+	}
+	return nil
+}
+
+// onHeaderFragment accumulates the fragments of a header block until its END_HEADERS flag, then decodes it
+func (c *http2Conn) onHeaderFragment(fromClient bool, d *http2Direction, streamID uint32, flags uint8, fragment []byte, now time.Time) error {
+	if len(d.block)+len(fragment) > http2MaxHeaderBlockSize {
+		return errHTTP2FrameTooLarge
+	}
+	d.block = append(d.block, fragment...)
+
+	if flags&http2FlagEndHeaders == 0 {
+		d.blockStream, d.blockFlags = streamID, flags
+		return nil
+	}
+
+	fields, err := d.decoder.DecodeFull(d.block)
+	promise := d.blockPromise
+	d.block, d.blockStream, d.blockFlags, d.blockPromise = nil, 0, 0, false
+	if err != nil {
+		return err
+	}
+	if promise {
+		return nil
+	}
+
+	if fromClient {
+		c.onRequestHeaders(streamID, fields, now)
+		return nil
+	}
+
+	stream, ok := c.streams[streamID]
+	if !ok {
+		return nil
+	}
+	for _, f := range fields {
+		switch f.Name {
+		case ":status":
+			// the informational responses precede the final one
+			if status, err := strconv.Atoi(f.Value); err == nil && status >= 200 && status < 600 {
+				stream.status = status
+			}
+		case "grpc-status":
+			if status, err := strconv.Atoi(f.Value); err == nil && status >= 0 {
+				stream.grpcStatus = status
+			}
+		}
+	}
+	if flags&http2FlagEndStream != 0 {
+		c.endStream(streamID, now)
+	}
+	return nil
+}
+
+func (c *http2Conn) onRequestHeaders(streamID uint32, fields []hpack.HeaderField, now time.Time) {
+	if _, ok := c.streams[streamID]; ok {
+		// the trailers of the request
+		return
+	}
+	if len(c.streams) >= c.maxStreams {
+		c.droppedStreams++
+		return
+	}
+
+	stream := &http2Stream{start: now, grpcStatus: http2NoGRPCStatus}
+	for _, f := range fields {
+		switch f.Name {
+		case ":method":
+			stream.method = f.Value
+		case ":path":
+			stream.path = f.Value
+		}
+	}
+	c.streams[streamID] = stream
+}
+
+func (c *http2Conn) endStream(streamID uint32, now time.Time) {
+	stream, ok := c.streams[streamID]
+	if !ok {
+		return
+	}
+	delete(c.streams, streamID)
+
+	if stream.status == 0 {
+		return
+	}
+	c.completed = append(c.completed, http2Transaction{
+		Key:        c.key,
+		Method:     stream.method,
+		Path:       stream.path,
+		Status:     stream.status,
+		GRPCStatus: stream.grpcStatus,
+		Latency:    float64(now.Sub(stream.start)) / float64(time.Millisecond),
+	})
+}
+
+// headersFragment returns the header block fragment of a HEADERS frame, stripped of its padding and priority
+func headersFragment(h http2FrameHeader, payload []byte) ([]byte, bool) {
+	payload, ok := stripPadding(h, payload)
+	if !ok {
+		return nil, false
+	}
+	if h.flags&http2FlagPriority != 0 {
+		if len(payload) < 5 {
+			return nil, false
+		}
+		payload = payload[5:]
+	}
+	return payload, true
+}
+
+// pushPromiseFragment returns the header block fragment of a PUSH_PROMISE frame, stripped of its padding and of the
+// promised stream ID
+func pushPromiseFragment(h http2FrameHeader, payload []byte) ([]byte, bool) {
+	payload, ok := stripPadding(h, payload)
+	if !ok || len(payload) < 4 {
+		return nil, false
+	}
+	return payload[4:], true
+}
+
+func stripPadding(h http2FrameHeader, payload []byte) ([]byte, bool) {
+	if h.flags&http2FlagPadded == 0 {
+		return payload, true
+	}
+	if len(payload) < 1 || int(payload[0]) >= len(payload) {
+		return nil, false
+	}
+	return payload[1 : len(payload)-int(payload[0])], true
+}
+
+// http2FrameHeader is the fixed-size header of the HTTP/2 frames, see RFC 7540 section 4.1
+type http2FrameHeader struct {
+	length   int
+	typ      uint8
+	flags    uint8
+	streamID uint32
+}
+
+func parseHTTP2FrameHeader(b []byte) http2FrameHeader {
+	return http2FrameHeader{
+		length:   int(b[0])<<16 | int(b[1])<<8 | int(b[2]),
+		typ:      b[3],
+		flags:    b[4],
+		streamID: binary.BigEndian.Uint32(b[5:]) & (1<<31 - 1),
+	}
+}
+
+// needsPayload returns whether the payload of the frame has to be decoded. The payload of the other frames, most
+// notably DATA, is skipped without being buffered.
+func (h http2FrameHeader) needsPayload() bool {
+	switch h.typ {
+	case http2FrameHeaders, http2FramePushPromise, http2FrameContinuation, http2FrameRSTStream, http2FrameSettings:
+		return true
+	default:
+		return false
+	}
+}
+
+// http2Direction reassembles the frames sent by one of the endpoints of a connection
+type http2Direction struct {
+	initialized bool
+	nextSeq     uint32
+
+	// buf holds the frame being reassembled: its header then, if needed, its payload
+	buf []byte
+	// skip is the number of bytes to discard before the next frame header
+	skip int
+
+	decoder *hpack.Decoder
+	// block holds the fragments of the header block being received on blockStream
+	block        []byte
+	blockStream  uint32
+	blockFlags   uint8
+	blockPromise bool
+}
+
+func newHTTP2Direction() http2Direction {
+	return http2Direction{
+		decoder: hpack.NewDecoder(http2DefaultHeaderTableSize, nil),
+	}
+}
+
+// feed processes the payload of a segment of sequence number seq and of length n on the wire, calling visit for each
+// complete frame. The retransmitted bytes are ignored, while the lost bytes are tolerated as long as they're part of
+// skipped frame payloads.
+func (d *http2Direction) feed(seq uint32, payload []byte, n int, visit func(http2FrameHeader, []byte) error) error {
+	if !d.initialized {
+		d.initialized = true
+		d.nextSeq = seq
+	}
+
+	end := seq + uint32(n)
+	if offset := int32(seq - d.nextSeq); offset < 0 {
+		// retransmission of bytes already processed
+		overlap := int(-offset)
+		if overlap >= n {
+			return nil
+		}
+		if overlap < len(payload) {
+			payload = payload[overlap:]
+		} else {
+			payload = nil
+		}
+		n -= overlap
+	} else if offset > 0 {
+		if err := d.discard(int(offset)); err != nil {
+			return err
+		}
+	}
+	d.nextSeq = end
+
+	if len(payload) > n {
+		payload = payload[:n]
+	}
+	if err := d.consume(payload, visit); err != nil {
+		return err
+	}
+	return d.discard(n - len(payload))
+}
+
+// discard accounts for bytes of the stream that weren't captured
+func (d *http2Direction) discard(n int) error {
+	if n == 0 {
+		return nil
+	}
+	if len(d.buf) > 0 || d.skip < n {
+		return errHTTP2Desync
+	}
+	d.skip -= n
+	return nil
+}
+
+func (d *http2Direction) consume(data []byte, visit func(http2FrameHeader, []byte) error) error {
+	for len(data) > 0 {
+		if d.skip > 0 {
+			n := min(d.skip, len(data))
+			d.skip -= n
+			data = data[n:]
+			continue
+		}
+
+		if len(d.buf) < http2FrameHeaderLen {
+			n := min(http2FrameHeaderLen-len(d.buf), len(data))
+			d.buf = append(d.buf, data[:n]...)
+			data = data[n:]
+			if len(d.buf) < http2FrameHeaderLen {
+				return nil
+			}
+		}
+
+		h := parseHTTP2FrameHeader(d.buf)
+		if !h.needsPayload() {
+			d.resetBuf()
+			d.skip = h.length
+			if err := visit(h, nil); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if h.length > http2MaxFrameBuffered {
+			return errHTTP2FrameTooLarge
+		}
+		n := min(http2FrameHeaderLen+h.length-len(d.buf), len(data))
+		d.buf = append(d.buf, data[:n]...)
+		data = data[n:]
+		if len(d.buf) < http2FrameHeaderLen+h.length {
+			return nil
+		}
+
+		err := visit(h, d.buf[http2FrameHeaderLen:])
+		d.resetBuf()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resetBuf empties the frame buffer, releasing it when it grew for a large frame
+func (d *http2Direction) resetBuf() {
+	if cap(d.buf) > 4*1024 {
+		d.buf = nil
+		return
+	}
+	d.buf = d.buf[:0]
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
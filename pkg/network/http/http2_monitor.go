@@ -0,0 +1,180 @@
+// +build linux_bpf
+
+package http
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/DataDog/datadog-agent/pkg/network/ebpf/probes"
+	filterpkg "github.com/DataDog/datadog-agent/pkg/network/filter"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/ebpf/manager"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// http2Monitor decodes the HTTP/2 traffic of the monitored ports, captured by a socket filter attached to a
+// RAW_SOCKET, and hands over the completed transactions to a handler
+type http2Monitor struct {
+	source  *filterpkg.AFPacketSource
+	parser  *http2Parser
+	handler func([]http2Transaction)
+
+	decoder     *gopacket.DecodingLayerParser
+	layers      []gopacket.LayerType
+	ipv4Payload *layers.IPv4
+	ipv6Payload *layers.IPv6
+	tcpPayload  *layers.TCP
+
+	exit chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newHTTP2Monitor(procRoot string, mgr *manager.Manager, ports []uint16, handler func([]http2Transaction)) (*http2Monitor, error) {
+	portsMap, _, err := mgr.GetMap(string(probes.HTTP2PortsMap))
+	if err != nil {
+		return nil, err
+	}
+	for _, port := range ports {
+		port := port
+		enabled := uint8(1)
+		if err := portsMap.Put(unsafe.Pointer(&port), unsafe.Pointer(&enabled)); err != nil {
+			return nil, fmt.Errorf("error adding port %d to the HTTP/2 ports: %s", port, err)
+		}
+	}
+
+	filter, _ := mgr.GetProbe(manager.ProbeIdentificationPair{Section: string(probes.SocketHTTP2Filter)})
+	if filter == nil {
+		return nil, fmt.Errorf("error retrieving socket filter")
+	}
+
+	// Create the RAW_SOCKET inside the root network namespace
+	var (
+		source *filterpkg.AFPacketSource
+		srcErr error
+	)
+	err = util.WithRootNS(procRoot, func() error {
+		source, srcErr = filterpkg.NewPacketSource(filter)
+		return srcErr
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error enabling HTTP/2 traffic inspection: %s", err)
+	}
+
+	ipv4Payload := &layers.IPv4{}
+	ipv6Payload := &layers.IPv6{}
+	tcpPayload := &layers.TCP{}
+	decoder := gopacket.NewDecodingLayerParser(
+		layers.LayerTypeEthernet,
+		&layers.Ethernet{},
+		ipv4Payload,
+		ipv6Payload,
+		tcpPayload,
+	)
+	// the TCP payload holding the HTTP/2 frames is parsed separately
+	decoder.IgnoreUnsupported = true
+
+	m := &http2Monitor{
+		source:      source,
+		parser:      newHTTP2Parser(),
+		handler:     handler,
+		decoder:     decoder,
+		ipv4Payload: ipv4Payload,
+		ipv6Payload: ipv6Payload,
+		tcpPayload:  tcpPayload,
+		exit:        make(chan struct{}),
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.pollPackets()
+	}()
+
+	log.Infof("http2 monitoring enabled on ports %v", ports)
+	return m, nil
+}
+
+// GetStats returns stats for use with telemetry
+func (m *http2Monitor) GetStats() map[string]int64 {
+	stats := m.parser.GetStats()
+	for key, value := range m.source.Stats() {
+		stats[key] = value
+	}
+	return stats
+}
+
+// Close terminates the decoding of the HTTP/2 traffic as well as the underlying socket and the attached filter
+func (m *http2Monitor) Close() {
+	close(m.exit)
+	m.wg.Wait()
+	m.source.Close()
+}
+
+func (m *http2Monitor) pollPackets() {
+	for {
+		err := m.source.VisitPackets(m.exit, m.processPacket)
+
+		if err != nil {
+			log.Warnf("error reading packet: %s", err)
+		}
+
+		// Properly synchronizes termination process
+		select {
+		case <-m.exit:
+			return
+		default:
+		}
+
+		// Sleep briefly and try again
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// processPacket feeds the TCP segment held by the packet to the HTTP/2 parser. The underlying packet data can't be
+// referenced after this method call since the underlying memory content gets invalidated by `afpacket`.
+func (m *http2Monitor) processPacket(data []byte, ts time.Time) error {
+	if err := m.decoder.DecodeLayers(data, &m.layers); err != nil {
+		log.Tracef("error decoding packet: %v", err)
+		return nil
+	}
+
+	if len(m.layers) == 0 || m.layers[len(m.layers)-1] != layers.LayerTypeTCP {
+		return nil
+	}
+
+	tcp := m.tcpPayload
+	seg := tcpSegment{
+		SourcePort: uint16(tcp.SrcPort),
+		DestPort:   uint16(tcp.DstPort),
+		Seq:        tcp.Seq,
+		Payload:    tcp.Payload,
+		Closing:    tcp.FIN || tcp.RST,
+	}
+
+	// the length of the payload on the wire is needed since the captured packets may be truncated
+	switch m.layers[len(m.layers)-2] {
+	case layers.LayerTypeIPv4:
+		seg.Source = util.AddressFromNetIP(m.ipv4Payload.SrcIP)
+		seg.Dest = util.AddressFromNetIP(m.ipv4Payload.DstIP)
+		seg.Len = int(m.ipv4Payload.Length) - int(m.ipv4Payload.IHL)*4 - int(tcp.DataOffset)*4
+	case layers.LayerTypeIPv6:
+		seg.Source = util.AddressFromNetIP(m.ipv6Payload.SrcIP)
+		seg.Dest = util.AddressFromNetIP(m.ipv6Payload.DstIP)
+		seg.Len = int(m.ipv6Payload.Length) - int(tcp.DataOffset)*4
+	default:
+		return nil
+	}
+	if seg.Len < len(seg.Payload) {
+		seg.Len = len(seg.Payload)
+	}
+
+	if txs := m.parser.Process(&seg, ts); len(txs) > 0 {
+		m.handler(txs)
+	}
+	return nil
+}
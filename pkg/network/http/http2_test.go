@@ -0,0 +1,403 @@
+package http
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+)
+
+var (
+	http2ClientIP   = util.AddressFromString("1.1.1.1")
+	http2ServerIP   = util.AddressFromString("2.2.2.2")
+	http2ClientPort = uint16(45678)
+	http2ServerPort = uint16(50051)
+)
+
+// http2Endpoint encodes the frames sent by one side of an HTTP/2 connection, keeping track of its HPACK state and of
+// the TCP sequence number
+type http2Endpoint struct {
+	t        *testing.T
+	client   bool
+	seq      uint32
+	buf      bytes.Buffer
+	framer   *http2.Framer
+	hpackBuf bytes.Buffer
+	encoder  *hpack.Encoder
+}
+
+func newHTTP2Endpoint(t *testing.T, client bool, seq uint32) *http2Endpoint {
+	e := &http2Endpoint{t: t, client: client, seq: seq}
+	e.framer = http2.NewFramer(&e.buf, nil)
+	e.encoder = hpack.NewEncoder(&e.hpackBuf)
+	return e
+}
+
+func (e *http2Endpoint) headerBlock(fields ...string) []byte {
+	e.hpackBuf.Reset()
+	for i := 0; i+1 < len(fields); i += 2 {
+		require.NoError(e.t, e.encoder.WriteField(hpack.HeaderField{Name: fields[i], Value: fields[i+1]}))
+	}
+	return append([]byte(nil), e.hpackBuf.Bytes()...)
+}
+
+func (e *http2Endpoint) preface() *http2Endpoint {
+	e.buf.WriteString(http2.ClientPreface)
+	return e.settings()
+}
+
+func (e *http2Endpoint) settings() *http2Endpoint {
+	require.NoError(e.t, e.framer.WriteSettings())
+	return e
+}
+
+func (e *http2Endpoint) headers(streamID uint32, endStream bool, fields ...string) *http2Endpoint {
+	require.NoError(e.t, e.framer.WriteHeaders(http2.HeadersFrameParam{
+		StreamID:      streamID,
+		BlockFragment: e.headerBlock(fields...),
+		EndStream:     endStream,
+		EndHeaders:    true,
+	}))
+	return e
+}
+
+func (e *http2Endpoint) data(streamID uint32, endStream bool, size int) *http2Endpoint {
+	require.NoError(e.t, e.framer.WriteData(streamID, endStream, make([]byte, size)))
+	return e
+}
+
+func (e *http2Endpoint) rstStream(streamID uint32) *http2Endpoint {
+	require.NoError(e.t, e.framer.WriteRSTStream(streamID, http2.ErrCodeCancel))
+	return e
+}
+
+// segment returns the bytes written so far as a single TCP segment
+func (e *http2Endpoint) segment() *tcpSegment {
+	segs := e.segments(e.buf.Len())
+	if len(segs) == 0 {
+		return nil
+	}
+	return segs[0]
+}
+
+// segments returns the bytes written so far split into segments of at most size bytes
+func (e *http2Endpoint) segments(size int) []*tcpSegment {
+	var segs []*tcpSegment
+	for e.buf.Len() > 0 {
+		payload := append([]byte(nil), e.buf.Next(size)...)
+		seg := &tcpSegment{
+			Source:     http2ClientIP,
+			Dest:       http2ServerIP,
+			SourcePort: http2ClientPort,
+			DestPort:   http2ServerPort,
+			Seq:        e.seq,
+			Payload:    payload,
+			Len:        len(payload),
+		}
+		if !e.client {
+			seg.Source, seg.Dest = seg.Dest, seg.Source
+			seg.SourcePort, seg.DestPort = seg.DestPort, seg.SourcePort
+		}
+		e.seq += uint32(len(payload))
+		segs = append(segs, seg)
+	}
+	return segs
+}
+
+func newHTTP2Endpoints(t *testing.T) (*http2Endpoint, *http2Endpoint) {
+	return newHTTP2Endpoint(t, true, 1000), newHTTP2Endpoint(t, false, 5000)
+}
+
+func TestHTTP2Transaction(t *testing.T) {
+	p := newHTTP2Parser()
+	client, server := newHTTP2Endpoints(t)
+	start := time.Now()
+
+	txs := p.Process(client.preface().headers(1, true, ":method", "GET", ":path", "/users?id=1", ":scheme", "http").segment(), start)
+	assert.Empty(t, txs)
+	txs = p.Process(server.settings().headers(1, false, ":status", "404").segment(), start.Add(10*time.Millisecond))
+	assert.Empty(t, txs)
+	txs = p.Process(server.data(1, true, 100).segment(), start.Add(25*time.Millisecond))
+
+	require.Len(t, txs, 1)
+	tx := txs[0]
+	assert.Equal(t, Key{SourceIP: http2ClientIP, DestIP: http2ServerIP, SourcePort: http2ClientPort, DestPort: http2ServerPort}, tx.Key)
+	assert.Equal(t, "GET", tx.Method)
+	assert.Equal(t, "/users?id=1", tx.Path)
+	assert.Equal(t, 404, tx.Status)
+	assert.Equal(t, http2NoGRPCStatus, tx.GRPCStatus)
+	assert.Equal(t, 400, tx.StatusClass())
+	assert.Equal(t, float64(25), tx.Latency)
+}
+
+func TestHTTP2GRPCTrailers(t *testing.T) {
+	p := newHTTP2Parser()
+	client, server := newHTTP2Endpoints(t)
+	start := time.Now()
+
+	p.Process(client.preface().
+		headers(1, false, ":method", "POST", ":path", "/helloworld.Greeter/SayHello", "content-type", "application/grpc").
+		data(1, true, 12).
+		segment(), start)
+
+	txs := p.Process(server.settings().
+		headers(1, false, ":status", "200", "content-type", "application/grpc").
+		data(1, false, 20).
+		headers(1, true, "grpc-status", "5", "grpc-message", "not found").
+		segment(), start.Add(time.Millisecond))
+
+	require.Len(t, txs, 1)
+	assert.Equal(t, "/helloworld.Greeter/SayHello", txs[0].Path)
+	assert.Equal(t, 200, txs[0].Status)
+	assert.Equal(t, 5, txs[0].GRPCStatus)
+	assert.Equal(t, 404, txs[0].StatusCode())
+	assert.Equal(t, 400, txs[0].StatusClass())
+
+	// trailers-only response
+	p.Process(client.headers(3, true, ":method", "POST", ":path", "/helloworld.Greeter/SayHello").segment(), start)
+	txs = p.Process(server.headers(3, true, ":status", "200", "grpc-status", "0").segment(), start)
+	require.Len(t, txs, 1)
+	assert.Equal(t, 0, txs[0].GRPCStatus)
+	assert.Equal(t, 200, txs[0].StatusClass())
+}
+
+func TestHTTP2DynamicTable(t *testing.T) {
+	p := newHTTP2Parser()
+	client, server := newHTTP2Endpoints(t)
+	now := time.Now()
+
+	p.Process(client.preface().segment(), now)
+	p.Process(server.settings().segment(), now)
+
+	// the repeated header fields are encoded as references to the dynamic tables of the connection
+	for i := uint32(0); i < 10; i++ {
+		streamID := 2*i + 1
+		path := "/api/v1/resource/" + strconv.Itoa(int(i%2))
+		p.Process(client.headers(streamID, true, ":method", "GET", ":path", path, "user-agent", "test").segment(), now)
+		txs := p.Process(server.headers(streamID, true, ":status", "201", "server", "test").segment(), now)
+
+		require.Len(t, txs, 1)
+		assert.Equal(t, path, txs[0].Path)
+		assert.Equal(t, 201, txs[0].Status)
+	}
+	assert.Zero(t, p.GetStats()["decoding_errors"])
+}
+
+func TestHTTP2Reassembly(t *testing.T) {
+	for _, size := range []int{1, 7, 9, 10, 100} {
+		t.Run(strconv.Itoa(size), func(t *testing.T) {
+			p := newHTTP2Parser()
+			client, server := newHTTP2Endpoints(t)
+			now := time.Now()
+
+			// the preface is sent on its own
+			p.Process(client.preface().segment(), now)
+
+			client.
+				headers(1, true, ":method", "GET", ":path", "/a").
+				headers(3, true, ":method", "GET", ":path", "/b")
+			server.settings().
+				headers(3, false, ":status", "200").
+				data(3, true, 1000).
+				headers(1, true, ":status", "500")
+
+			var txs []http2Transaction
+			for _, seg := range client.segments(size) {
+				txs = append(txs, p.Process(seg, now)...)
+			}
+			for _, seg := range server.segments(size) {
+				txs = append(txs, p.Process(seg, now)...)
+			}
+
+			require.Len(t, txs, 2)
+			assert.Equal(t, "/b", txs[0].Path)
+			assert.Equal(t, 200, txs[0].Status)
+			assert.Equal(t, "/a", txs[1].Path)
+			assert.Equal(t, 500, txs[1].Status)
+		})
+	}
+}
+
+func TestHTTP2Continuation(t *testing.T) {
+	p := newHTTP2Parser()
+	client, server := newHTTP2Endpoints(t)
+	now := time.Now()
+
+	p.Process(client.preface().segment(), now)
+	block := client.headerBlock(":method", "GET", ":path", "/continued", "x-padding", string(make([]byte, 100)))
+	require.NoError(t, client.framer.WriteHeaders(http2.HeadersFrameParam{
+		StreamID:      1,
+		BlockFragment: block[:10],
+		EndStream:     true,
+		PadLength:     3,
+		Priority:      http2.PriorityParam{Weight: 10},
+	}))
+	require.NoError(t, client.framer.WriteContinuation(1, false, block[10:50]))
+	require.NoError(t, client.framer.WriteContinuation(1, true, block[50:]))
+	p.Process(client.segment(), now)
+
+	txs := p.Process(server.settings().headers(1, true, ":status", "200").segment(), now)
+	require.Len(t, txs, 1)
+	assert.Equal(t, "/continued", txs[0].Path)
+}
+
+func TestHTTP2TCPSequence(t *testing.T) {
+	t.Run("retransmission", func(t *testing.T) {
+		p := newHTTP2Parser()
+		client, server := newHTTP2Endpoints(t)
+		now := time.Now()
+
+		p.Process(client.preface().segment(), now)
+		segs := client.headers(1, true, ":method", "GET", ":path", "/").segments(5)
+		for _, seg := range segs {
+			p.Process(seg, now)
+		}
+		// retransmitted segments are ignored
+		for _, seg := range segs[1:] {
+			p.Process(seg, now)
+		}
+
+		txs := p.Process(server.settings().headers(1, true, ":status", "200").segment(), now)
+		assert.Len(t, txs, 1)
+		assert.Zero(t, p.GetStats()["decoding_errors"])
+	})
+
+	t.Run("lost data", func(t *testing.T) {
+		p := newHTTP2Parser()
+		client, server := newHTTP2Endpoints(t)
+		now := time.Now()
+
+		p.Process(client.preface().headers(1, true, ":method", "GET", ":path", "/").segment(), now)
+		p.Process(server.settings().headers(1, false, ":status", "200").segment(), now)
+
+		// the lost payload of a DATA frame can be skipped
+		segs := server.data(1, false, 3000).segments(1000)
+		p.Process(segs[0], now)
+		p.Process(segs[2], now)
+
+		// a truncated payload as well
+		seg := server.data(1, true, 3000).segment()
+		seg.Payload = seg.Payload[:100]
+		txs := p.Process(seg, now)
+		assert.Len(t, txs, 1)
+		assert.Zero(t, p.GetStats()["decoding_errors"])
+		assert.Equal(t, int64(1), p.GetStats()["connections"])
+	})
+
+	t.Run("lost headers", func(t *testing.T) {
+		p := newHTTP2Parser()
+		client, _ := newHTTP2Endpoints(t)
+		now := time.Now()
+
+		p.Process(client.preface().segment(), now)
+		segs := client.headers(1, true, ":method", "GET", ":path", "/some/long/path/to/split").segments(10)
+		p.Process(segs[0], now)
+		p.Process(segs[2], now)
+
+		// the HPACK state can't be recovered
+		assert.Equal(t, int64(1), p.GetStats()["decoding_errors"])
+		assert.Equal(t, int64(0), p.GetStats()["connections"])
+	})
+}
+
+func TestHTTP2UntrackedConnections(t *testing.T) {
+	p := newHTTP2Parser()
+	client, server := newHTTP2Endpoints(t)
+	now := time.Now()
+
+	// the connection preface wasn't observed
+	assert.Empty(t, p.Process(client.headers(1, true, ":method", "GET", ":path", "/").segment(), now))
+	assert.Empty(t, p.Process(server.headers(1, true, ":status", "200").segment(), now))
+	assert.Zero(t, p.GetStats()["connections"])
+
+	// HTTP/1.x
+	seg := &tcpSegment{Source: http2ClientIP, Dest: http2ServerIP, Payload: []byte("GET / HTTP/1.1\r\n\r\n"), Len: 18}
+	assert.Empty(t, p.Process(seg, now))
+	assert.Zero(t, p.GetStats()["connections"])
+}
+
+func TestHTTP2Limits(t *testing.T) {
+	t.Run("streams", func(t *testing.T) {
+		p := newHTTP2Parser()
+		p.maxStreams = 2
+		client, server := newHTTP2Endpoints(t)
+		now := time.Now()
+
+		p.Process(client.preface().
+			headers(1, true, ":method", "GET", ":path", "/").
+			headers(3, true, ":method", "GET", ":path", "/").
+			headers(5, true, ":method", "GET", ":path", "/").
+			segment(), now)
+		txs := p.Process(server.settings().
+			headers(1, true, ":status", "200").
+			headers(3, true, ":status", "200").
+			headers(5, true, ":status", "200").
+			segment(), now)
+
+		assert.Len(t, txs, 2)
+		assert.Equal(t, int64(1), p.GetStats()["dropped_streams"])
+	})
+
+	t.Run("connections", func(t *testing.T) {
+		p := newHTTP2Parser()
+		p.maxConns = 1
+		now := time.Now()
+
+		client, _ := newHTTP2Endpoints(t)
+		p.Process(client.preface().segment(), now)
+
+		other := newHTTP2Endpoint(t, true, 0)
+		seg := other.preface().segment()
+		seg.SourcePort++
+		p.Process(seg, now)
+
+		assert.Equal(t, int64(1), p.GetStats()["connections"])
+		assert.Equal(t, int64(1), p.GetStats()["dropped_connections"])
+	})
+}
+
+func TestHTTP2StreamLifecycle(t *testing.T) {
+	p := newHTTP2Parser()
+	client, server := newHTTP2Endpoints(t)
+	now := time.Now()
+
+	p.Process(client.preface().headers(1, true, ":method", "GET", ":path", "/").segment(), now)
+	p.Process(client.rstStream(1).segment(), now)
+	assert.Empty(t, p.Process(server.settings().headers(1, true, ":status", "200").segment(), now))
+	assert.Equal(t, int64(1), p.GetStats()["reset_streams"])
+
+	// informational responses are followed by the final one
+	p.Process(client.headers(3, true, ":method", "GET", ":path", "/").segment(), now)
+	txs := p.Process(server.headers(3, false, ":status", "100").headers(3, true, ":status", "204").segment(), now)
+	require.Len(t, txs, 1)
+	assert.Equal(t, 204, txs[0].Status)
+
+	// streams waiting for too long are forgotten
+	p.Process(client.headers(5, true, ":method", "GET", ":path", "/").segment(), now)
+	p.Process(client.headers(7, true, ":method", "GET", ":path", "/").segment(), now.Add(http2ExpirationPeriod))
+	later := now.Add(http2StreamTimeout + time.Second)
+	p.Process(client.headers(9, true, ":method", "GET", ":path", "/").segment(), later)
+	assert.Equal(t, int64(1), p.GetStats()["expired_streams"])
+
+	// the connection is forgotten once closed
+	seg := client.data(9, true, 0).segment()
+	seg.Closing = true
+	p.Process(seg, later)
+	assert.Zero(t, p.GetStats()["connections"])
+}
+
+func TestGRPCStatusToHTTP(t *testing.T) {
+	tx := http2Transaction{Status: 200, GRPCStatus: http2NoGRPCStatus}
+	assert.Equal(t, 200, tx.StatusCode())
+
+	for code, class := range map[int]int{0: 200, 1: 400, 2: 500, 4: 500, 5: 400, 14: 500, 16: 400, 42: 500} {
+		tx.GRPCStatus = code
+		assert.Equal(t, class, tx.StatusClass(), "grpc-status %d", code)
+	}
+}
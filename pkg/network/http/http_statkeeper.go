@@ -30,17 +30,29 @@ func (h *httpStatKeeper) Process(transactions []httpTX) {
 			SourcePort: tx.SourcePort(),
 			DestPort:   tx.DestPort(),
 		}
-		path := cleanPath(tx.Path())
-		statusClass := tx.StatusClass()
-		latency := tx.RequestLatency()
+		h.add(key, tx.Path(), tx.StatusClass(), tx.RequestLatency())
+	}
+}
 
-		if _, ok := h.stats[key]; !ok {
-			h.stats[key] = make(map[string]RequestStats)
-		}
-		stats := h.stats[key][path]
-		stats.AddRequest(statusClass, latency)
-		h.stats[key][path] = stats
+// ProcessHTTP2 adds the transactions decoded from the HTTP/2 streams to the same stats as the HTTP/1.x ones
+func (h *httpStatKeeper) ProcessHTTP2(transactions []http2Transaction) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	for _, tx := range transactions {
+		h.add(tx.Key, tx.Path, tx.StatusClass(), tx.Latency)
+	}
+}
+
+func (h *httpStatKeeper) add(key Key, path string, statusClass int, latency float64) {
+	path = cleanPath(path)
+
+	if _, ok := h.stats[key]; !ok {
+		h.stats[key] = make(map[string]RequestStats)
 	}
+	stats := h.stats[key][path]
+	stats.AddRequest(statusClass, latency)
+	h.stats[key][path] = stats
 }
 
 func (h *httpStatKeeper) GetAndResetAllStats() map[Key]map[string]RequestStats {
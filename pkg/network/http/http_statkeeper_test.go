@@ -9,6 +9,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestProcessHTTPTransactions(t *testing.T) {
@@ -64,6 +65,33 @@ func TestProcessHTTPTransactions(t *testing.T) {
 	}
 }
 
+func TestProcessHTTP2Transactions(t *testing.T) {
+	sk := newHTTPStatkeeper()
+	key := Key{
+		SourceIP:   util.AddressFromString("1.1.1.1"),
+		DestIP:     util.AddressFromString("2.2.2.2"),
+		SourcePort: 1234,
+		DestPort:   50051,
+	}
+
+	sk.ProcessHTTP2([]http2Transaction{
+		{Key: key, Method: "POST", Path: "/helloworld.Greeter/SayHello", Status: 200, GRPCStatus: 0, Latency: 2},
+		{Key: key, Method: "POST", Path: "/helloworld.Greeter/SayHello", Status: 200, GRPCStatus: 5, Latency: 3},
+		{Key: key, Method: "GET", Path: "/status?verbose=true", Status: 503, GRPCStatus: http2NoGRPCStatus, Latency: 1},
+	})
+
+	stats := sk.GetAndResetAllStats()
+	require.Len(t, stats, 1)
+	require.Len(t, stats[key], 2)
+
+	grpcStats := stats[key]["/helloworld.Greeter/SayHello"]
+	assert.Equal(t, 1, grpcStats[1].count)
+	assert.Equal(t, 1, grpcStats[3].count)
+
+	statusStats := stats[key]["/status"]
+	assert.Equal(t, 1, statusStats[4].count)
+}
+
 func TestCleanPath(t *testing.T) {
 	path := "/some/path?key1=val1&key2=val2"
 	expected := "/some/path"
@@ -36,7 +36,9 @@ func (r *RequestStats) CombineWith(newStats RequestStats) {
 		r[i].count += newStats[i].count
 
 		if r[i].latencies == nil {
-			r[i].latencies = newStats[i].latencies
+			if newStats[i].latencies != nil {
+				r[i].latencies = newStats[i].latencies.Copy()
+			}
 		} else if newStats[i].latencies != nil {
 			err := r[i].latencies.MergeWith(newStats[i].latencies)
 			if err != nil {
@@ -21,8 +21,10 @@ import (
 // * Polling a perf buffer that contains notifications about HTTP transaction batches ready to be read;
 // * Querying these batches by doing a map lookup;
 // * Aggregating and emitting metrics based on the received HTTP transactions;
+// * Optionally, decoding the HTTP/2 traffic of a set of ports into the same metrics.
 type Monitor struct {
 	handler func([]httpTX)
+	http2   *http2Monitor
 
 	batchManager *batchManager
 	perfMap      *manager.PerfMap
@@ -38,8 +40,8 @@ type Monitor struct {
 	stopped       bool
 }
 
-// NewMonitor returns a new Monitor instance
-func NewMonitor(procRoot string, mgr *manager.Manager, h *ddebpf.PerfHandler) (*Monitor, error) {
+// NewMonitor returns a new Monitor instance. The HTTP/2 traffic of the given ports, if any, is decoded as well.
+func NewMonitor(procRoot string, http2Ports []uint16, mgr *manager.Manager, h *ddebpf.PerfHandler) (*Monitor, error) {
 	filter, _ := mgr.GetProbe(manager.ProbeIdentificationPair{Section: string(probes.SocketHTTPFilter)})
 	if filter == nil {
 		return nil, fmt.Errorf("error retrieving socket filter")
@@ -76,8 +78,18 @@ func NewMonitor(procRoot string, mgr *manager.Manager, h *ddebpf.PerfHandler) (*
 		}
 	}
 
+	var http2 *http2Monitor
+	if len(http2Ports) > 0 {
+		http2, err = newHTTP2Monitor(procRoot, mgr, http2Ports, statkeeper.ProcessHTTP2)
+		if err != nil {
+			closeFilterFn()
+			return nil, err
+		}
+	}
+
 	return &Monitor{
 		handler:       handler,
+		http2:         http2,
 		batchManager:  newBatchManager(batchMap, batchStateMap, numCPUs),
 		perfMap:       pm,
 		perfHandler:   h,
@@ -169,6 +181,11 @@ func (m *Monitor) GetHTTPStats() map[Key]map[string]RequestStats {
 
 func (m *Monitor) GetStats() map[string]interface{} {
 	currentTime, telemetryData := m.telemetry.getStats()
+	if m.http2 != nil {
+		for key, value := range m.http2.GetStats() {
+			telemetryData["http2_"+key] = value
+		}
+	}
 	return map[string]interface{}{
 		"current_time": currentTime,
 		"telemetry":    telemetryData,
@@ -188,6 +205,9 @@ func (m *Monitor) Stop() {
 	}
 
 	m.closeFilterFn()
+	if m.http2 != nil {
+		m.http2.Close()
+	}
 	_ = m.perfMap.Stop(manager.CleanAll)
 	m.perfHandler.Stop()
 	close(m.pollRequests)
@@ -0,0 +1,129 @@
+// +build linux
+// +build !android
+
+package netlink
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/network"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+// procTuple is a direction of a connection, as listed in /proc/net/nf_conntrack
+type procTuple struct {
+	src, dst         net.IP
+	srcPort, dstPort uint16
+}
+
+func (t *procTuple) key(transport network.ConnectionType) connKey {
+	return connKey{
+		srcIP:     util.AddressFromNetIP(t.src),
+		srcPort:   t.srcPort,
+		dstIP:     util.AddressFromNetIP(t.dst),
+		dstPort:   t.dstPort,
+		transport: transport,
+	}
+}
+
+func (t *procTuple) translation() *network.IPTranslation {
+	return &network.IPTranslation{
+		ReplSrcIP:   util.AddressFromNetIP(t.src),
+		ReplDstIP:   util.AddressFromNetIP(t.dst),
+		ReplSrcPort: t.srcPort,
+		ReplDstPort: t.dstPort,
+	}
+}
+
+// parseProcConntrack reads the NAT translations from a conntrack table formatted as /proc/net/nf_conntrack,
+// or as the legacy /proc/net/ip_conntrack. Both directions of a connection are registered, the same way as
+// the netlink conntracker does, and the table is truncated once maxStateSize entries are registered.
+func parseProcConntrack(r io.Reader, maxStateSize int) (state map[connKey]*network.IPTranslation, truncated bool, err error) {
+	state = make(map[connKey]*network.IPTranslation)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		transport, origin, reply, ok := parseProcConntrackLine(scanner.Text())
+		if !ok || !isProcNAT(origin, reply) {
+			continue
+		}
+		if len(state)+2 > maxStateSize {
+			truncated = true
+			break
+		}
+		state[origin.key(transport)] = reply.translation()
+		state[reply.key(transport)] = origin.translation()
+	}
+	return state, truncated, scanner.Err()
+}
+
+// parseProcConntrackLine parses an entry such as:
+// ipv4     2 tcp      6 431999 ESTABLISHED src=10.0.0.1 dst=10.0.0.2 sport=40000 dport=80 src=10.0.0.3 dst=10.0.0.1 sport=8080 dport=40000 [ASSURED] mark=0 use=2
+func parseProcConntrackLine(line string) (transport network.ConnectionType, origin, reply procTuple, ok bool) {
+	fields := strings.Fields(line)
+
+	// the protocol is the first field of the legacy format, and the third one of nf_conntrack
+	var protoFound bool
+	for i := 0; i < len(fields) && i < 3 && !protoFound; i++ {
+		switch fields[i] {
+		case "tcp":
+			transport, protoFound = network.TCP, true
+		case "udp":
+			transport, protoFound = network.UDP, true
+		}
+	}
+	if !protoFound {
+		return
+	}
+
+	// the first src, dst, sport and dport values are the original direction, the next ones the reply
+	var srcs, dsts []net.IP
+	var sports, dports []uint16
+	for _, field := range fields {
+		i := strings.IndexByte(field, '=')
+		if i < 0 {
+			continue
+		}
+		name, value := field[:i], field[i+1:]
+		switch name {
+		case "src", "dst":
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return
+			}
+			if name == "src" {
+				srcs = append(srcs, ip)
+			} else {
+				dsts = append(dsts, ip)
+			}
+		case "sport", "dport":
+			port, err := strconv.ParseUint(value, 10, 16)
+			if err != nil {
+				return
+			}
+			if name == "sport" {
+				sports = append(sports, uint16(port))
+			} else {
+				dports = append(dports, uint16(port))
+			}
+		}
+	}
+	if len(srcs) < 2 || len(dsts) < 2 || len(sports) < 2 || len(dports) < 2 {
+		return
+	}
+
+	origin = procTuple{src: srcs[0], dst: dsts[0], srcPort: sports[0], dstPort: dports[0]}
+	reply = procTuple{src: srcs[1], dst: dsts[1], srcPort: sports[1], dstPort: dports[1]}
+	return transport, origin, reply, true
+}
+
+// isProcNAT is the equivalent of isNAT for the entries of /proc/net/nf_conntrack
+func isProcNAT(origin, reply procTuple) bool {
+	return !origin.src.Equal(reply.dst) ||
+		!origin.dst.Equal(reply.src) ||
+		origin.srcPort != reply.dstPort ||
+		origin.dstPort != reply.srcPort
+}
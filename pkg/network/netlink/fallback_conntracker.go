@@ -0,0 +1,317 @@
+// +build linux
+// +build !android
+
+package netlink
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/network"
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"golang.org/x/sys/unix"
+)
+
+// the conntrack tables exposed by procfs, relative to the proc root
+var procConntrackPaths = []string{"net/nf_conntrack", "net/ip_conntrack"}
+
+// conntrackTable loads the NAT translations of the whole conntrack table
+type conntrackTable interface {
+	Load(maxStateSize int) (state map[connKey]*network.IPTranslation, truncated bool, err error)
+	String() string
+	Close()
+}
+
+// procConntrackTable reads the conntrack table from procfs
+type procConntrackTable struct {
+	path string
+}
+
+func (t *procConntrackTable) Load(maxStateSize int) (map[connKey]*network.IPTranslation, bool, error) {
+	f, err := os.Open(t.path)
+	if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+
+	return parseProcConntrack(f, maxStateSize)
+}
+
+func (t *procConntrackTable) String() string {
+	return t.path
+}
+
+func (t *procConntrackTable) Close() {}
+
+// netlinkConntrackTable dumps the conntrack table through netlink, without subscribing to its updates
+type netlinkConntrackTable struct {
+	consumer *Consumer
+}
+
+func (t *netlinkConntrackTable) Load(maxStateSize int) (map[connKey]*network.IPTranslation, bool, error) {
+	state := make(map[connKey]*network.IPTranslation)
+	truncated := false
+	for _, family := range []uint8{unix.AF_INET, unix.AF_INET6} {
+		for e := range t.consumer.DumpTable(family) {
+			// the events are drained even once the state is full, for their buffers to be released
+			for _, c := range DecodeAndReleaseEvent(e) {
+				if !isNAT(c) {
+					continue
+				}
+				if len(state)+2 > maxStateSize {
+					truncated = true
+					continue
+				}
+				if k, ok := formatKey(c.Origin); ok {
+					state[k] = formatIPTranslation(c.Reply)
+				}
+				if k, ok := formatKey(c.Reply); ok {
+					state[k] = formatIPTranslation(c.Origin)
+				}
+			}
+		}
+	}
+	return state, truncated, nil
+}
+
+func (t *netlinkConntrackTable) String() string {
+	return "netlink dump"
+}
+
+func (t *netlinkConntrackTable) Close() {
+	t.consumer.Stop()
+}
+
+// fallbackConntracker periodically loads the whole conntrack table, for NAT to be resolved when the netlink
+// conntrack subscription is unavailable or samples its events
+type fallbackConntracker struct {
+	sync.RWMutex
+	table conntrackTable
+	state map[connKey]*network.IPTranslation
+
+	maxStateSize int
+	interval     time.Duration
+	exit         chan struct{}
+	wg           sync.WaitGroup
+
+	stats struct {
+		gets          int64
+		misses        int64
+		refreshes     int64
+		refreshErrors int64
+		truncations   int64
+		// lastRefresh is the time, in nanoseconds, of the last successful refresh
+		lastRefresh         int64
+		lastRefreshDuration int64
+	}
+	errorLogLimit *util.LogLimit
+}
+
+// NewFallbackConntracker creates a conntracker loading the conntrack table from procfs every
+// network_config.conntrack_fallback_interval, or dumping it through netlink if procfs doesn't expose it
+func NewFallbackConntracker(cfg *config.Config) (Conntracker, error) {
+	table, err := newConntrackTable(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctr := newFallbackConntracker(table, cfg.ConntrackMaxStateSize, cfg.ConntrackFallbackInterval)
+	if err := ctr.refresh(); err != nil {
+		ctr.Close()
+		return nil, fmt.Errorf("could not load the conntrack table from %s: %s", table, err)
+	}
+	ctr.run()
+
+	log.Infof("initialized the conntrack fallback, loading the conntrack table from %s every %s", table, cfg.ConntrackFallbackInterval)
+	return ctr, nil
+}
+
+func newConntrackTable(cfg *config.Config) (conntrackTable, error) {
+	for _, p := range procConntrackPaths {
+		path := filepath.Join(cfg.ProcRoot, p)
+		if f, err := os.Open(path); err == nil {
+			f.Close()
+			return &procConntrackTable{path: path}, nil
+		}
+	}
+
+	consumer, err := NewConsumer(cfg.ProcRoot, cfg.ConntrackRateLimit, cfg.EnableConntrackAllNamespaces)
+	if err != nil {
+		return nil, fmt.Errorf("the conntrack table is neither readable from %s nor dumpable through netlink: %s", filepath.Join(cfg.ProcRoot, "net"), err)
+	}
+	return &netlinkConntrackTable{consumer: consumer}, nil
+}
+
+func newFallbackConntracker(table conntrackTable, maxStateSize int, interval time.Duration) *fallbackConntracker {
+	return &fallbackConntracker{
+		table:         table,
+		state:         make(map[connKey]*network.IPTranslation),
+		maxStateSize:  maxStateSize,
+		interval:      interval,
+		exit:          make(chan struct{}),
+		errorLogLimit: util.NewLogLimit(10, time.Minute*10),
+	}
+}
+
+func (ctr *fallbackConntracker) GetTranslationForConn(c network.ConnectionStats) *network.IPTranslation {
+	ctr.RLock()
+	defer ctr.RUnlock()
+
+	k := connKey{
+		srcIP:     c.Source,
+		srcPort:   c.SPort,
+		dstIP:     c.Dest,
+		dstPort:   c.DPort,
+		transport: c.Type,
+	}
+
+	result := ctr.state[k]
+	atomic.AddInt64(&ctr.stats.gets, 1)
+	if result == nil {
+		atomic.AddInt64(&ctr.stats.misses, 1)
+	}
+	return result
+}
+
+func (ctr *fallbackConntracker) DeleteTranslation(c network.ConnectionStats) {
+	ctr.Lock()
+	defer ctr.Unlock()
+
+	k := connKey{
+		srcIP:     c.Source,
+		srcPort:   c.SPort,
+		dstIP:     c.Dest,
+		dstPort:   c.DPort,
+		transport: c.Type,
+	}
+
+	// the translation is loaded again by the next refresh if the connection is still in the conntrack table
+	if t, ok := ctr.state[k]; ok {
+		delete(ctr.state, k)
+		delete(ctr.state, ipTranslationToConnKey(k.transport, t))
+	}
+}
+
+// GetStats returns the telemetry of the conntracker. healthy is 0 when the conntrack table
+// couldn't be loaded during the last two intervals, the translations being then outdated.
+func (ctr *fallbackConntracker) GetStats() map[string]int64 {
+	ctr.RLock()
+	size := len(ctr.state)
+	ctr.RUnlock()
+
+	m := map[string]int64{
+		"state_size":      int64(size),
+		"gets_total":      atomic.LoadInt64(&ctr.stats.gets),
+		"misses_total":    atomic.LoadInt64(&ctr.stats.misses),
+		"refreshes_total": atomic.LoadInt64(&ctr.stats.refreshes),
+		"refresh_errors":  atomic.LoadInt64(&ctr.stats.refreshErrors),
+		"truncations":     atomic.LoadInt64(&ctr.stats.truncations),
+		"healthy":         0,
+	}
+
+	if last := atomic.LoadInt64(&ctr.stats.lastRefresh); last != 0 {
+		age := time.Since(time.Unix(0, last))
+		m["last_refresh_age_seconds"] = int64(age.Seconds())
+		m["nanoseconds_per_refresh"] = atomic.LoadInt64(&ctr.stats.lastRefreshDuration)
+		if age <= 2*ctr.interval {
+			m["healthy"] = 1
+		}
+	}
+	return m
+}
+
+func (ctr *fallbackConntracker) Close() {
+	close(ctr.exit)
+	ctr.wg.Wait()
+	ctr.table.Close()
+	ctr.errorLogLimit.Close()
+}
+
+// refresh replaces the translations with the ones of the conntrack table
+func (ctr *fallbackConntracker) refresh() error {
+	then := time.Now()
+	state, truncated, err := ctr.table.Load(ctr.maxStateSize)
+	if err != nil {
+		atomic.AddInt64(&ctr.stats.refreshErrors, 1)
+		return err
+	}
+
+	ctr.Lock()
+	ctr.state = state
+	ctr.Unlock()
+
+	now := time.Now()
+	atomic.AddInt64(&ctr.stats.refreshes, 1)
+	atomic.StoreInt64(&ctr.stats.lastRefresh, now.UnixNano())
+	atomic.StoreInt64(&ctr.stats.lastRefreshDuration, now.Sub(then).Nanoseconds())
+	if truncated {
+		atomic.AddInt64(&ctr.stats.truncations, 1)
+		if ctr.errorLogLimit.ShouldLog() {
+			log.Warnf("the conntrack table exceeds the maximum conntrack state size: %d entries. You may need to increase system_probe_config.conntrack_max_state_size (will log first ten times, and then once every 10 minutes)", ctr.maxStateSize)
+		}
+	}
+	return nil
+}
+
+func (ctr *fallbackConntracker) run() {
+	ctr.wg.Add(1)
+	go func() {
+		defer ctr.wg.Done()
+		ticker := time.NewTicker(ctr.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := ctr.refresh(); err != nil && ctr.errorLogLimit.ShouldLog() {
+					log.Warnf("could not load the conntrack table from %s, the NAT translations may be outdated: %s (will log first ten times, and then once every 10 minutes)", ctr.table, err)
+				}
+			case <-ctr.exit:
+				return
+			}
+		}
+	}()
+}
+
+// conntrackerWithFallback looks up the translations missed by the netlink conntracker, for instance
+// once its circuit breaker tripped and it samples the conntrack events, in a fallback conntracker
+type conntrackerWithFallback struct {
+	primary  Conntracker
+	fallback Conntracker
+}
+
+// NewConntrackerWithFallback creates a conntracker looking up the translations missed by the primary conntracker in the fallback one
+func NewConntrackerWithFallback(primary, fallback Conntracker) Conntracker {
+	return &conntrackerWithFallback{primary: primary, fallback: fallback}
+}
+
+func (ctr *conntrackerWithFallback) GetTranslationForConn(c network.ConnectionStats) *network.IPTranslation {
+	if t := ctr.primary.GetTranslationForConn(c); t != nil {
+		return t
+	}
+	return ctr.fallback.GetTranslationForConn(c)
+}
+
+func (ctr *conntrackerWithFallback) DeleteTranslation(c network.ConnectionStats) {
+	ctr.primary.DeleteTranslation(c)
+	ctr.fallback.DeleteTranslation(c)
+}
+
+// GetStats returns the telemetry of the primary conntracker, along with the one of the fallback prefixed with fallback_
+func (ctr *conntrackerWithFallback) GetStats() map[string]int64 {
+	m := ctr.primary.GetStats()
+	for k, v := range ctr.fallback.GetStats() {
+		m["fallback_"+k] = v
+	}
+	return m
+}
+
+func (ctr *conntrackerWithFallback) Close() {
+	ctr.primary.Close()
+	ctr.fallback.Close()
+}
@@ -0,0 +1,183 @@
+// +build linux
+// +build !android
+
+package netlink
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/network"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConn(src string, sport uint16, dst string, dport uint16, transport network.ConnectionType) network.ConnectionStats {
+	return network.ConnectionStats{
+		Source: util.AddressFromString(src),
+		SPort:  sport,
+		Dest:   util.AddressFromString(dst),
+		DPort:  dport,
+		Type:   transport,
+	}
+}
+
+func TestParseProcConntrack(t *testing.T) {
+	f, err := os.Open("testdata/nf_conntrack")
+	require.NoError(t, err)
+	defer f.Close()
+
+	state, truncated, err := parseProcConntrack(f, 100)
+	require.NoError(t, err)
+	assert.False(t, truncated)
+	// the non NAT, non TCP/UDP, unreplied and malformed entries are ignored
+	assert.Len(t, state, 6)
+
+	// DNAT
+	assert.Equal(t, &network.IPTranslation{
+		ReplSrcIP:   util.AddressFromString("10.244.1.5"),
+		ReplDstIP:   util.AddressFromString("10.0.0.1"),
+		ReplSrcPort: 8080,
+		ReplDstPort: 40000,
+	}, state[connKey{
+		srcIP:     util.AddressFromString("10.0.0.1"),
+		srcPort:   40000,
+		dstIP:     util.AddressFromString("10.96.0.10"),
+		dstPort:   80,
+		transport: network.TCP,
+	}])
+	assert.Equal(t, &network.IPTranslation{
+		ReplSrcIP:   util.AddressFromString("10.0.0.1"),
+		ReplDstIP:   util.AddressFromString("10.96.0.10"),
+		ReplSrcPort: 40000,
+		ReplDstPort: 80,
+	}, state[connKey{
+		srcIP:     util.AddressFromString("10.244.1.5"),
+		srcPort:   8080,
+		dstIP:     util.AddressFromString("10.0.0.1"),
+		dstPort:   40000,
+		transport: network.TCP,
+	}])
+
+	// SNAT
+	assert.Equal(t, util.AddressFromString("192.168.1.10"), state[connKey{
+		srcIP:     util.AddressFromString("172.17.0.2"),
+		srcPort:   5353,
+		dstIP:     util.AddressFromString("8.8.8.8"),
+		dstPort:   53,
+		transport: network.UDP,
+	}].ReplDstIP)
+
+	// IPv6
+	assert.Equal(t, uint16(8443), state[connKey{
+		srcIP:     util.AddressFromString("fd00::1"),
+		srcPort:   41000,
+		dstIP:     util.AddressFromString("fd00::2"),
+		dstPort:   443,
+		transport: network.TCP,
+	}].ReplSrcPort)
+
+	t.Run("truncated", func(t *testing.T) {
+		_, err := f.Seek(0, 0)
+		require.NoError(t, err)
+		state, truncated, err := parseProcConntrack(f, 5)
+		require.NoError(t, err)
+		assert.True(t, truncated)
+		assert.Len(t, state, 4)
+	})
+
+	t.Run("legacy format", func(t *testing.T) {
+		legacy, err := os.Open("testdata/ip_conntrack")
+		require.NoError(t, err)
+		defer legacy.Close()
+
+		state, _, err := parseProcConntrack(legacy, 100)
+		require.NoError(t, err)
+		assert.Len(t, state, 2)
+		assert.Contains(t, state, connKey{
+			srcIP:     util.AddressFromString("10.0.0.1"),
+			srcPort:   40000,
+			dstIP:     util.AddressFromString("10.96.0.10"),
+			dstPort:   80,
+			transport: network.TCP,
+		})
+	})
+}
+
+func TestFallbackConntracker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "conntrack")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	table, err := ioutil.ReadFile("testdata/nf_conntrack")
+	require.NoError(t, err)
+	path := filepath.Join(dir, "nf_conntrack")
+	require.NoError(t, ioutil.WriteFile(path, table, 0644))
+
+	ctr := newFallbackConntracker(&procConntrackTable{path: path}, 100, time.Minute)
+	defer ctr.Close()
+	require.NoError(t, ctr.refresh())
+
+	conn := newTestConn("10.0.0.1", 40000, "10.96.0.10", 80, network.TCP)
+	trans := ctr.GetTranslationForConn(conn)
+	require.NotNil(t, trans)
+	assert.Equal(t, util.AddressFromString("10.244.1.5"), trans.ReplSrcIP)
+	assert.Nil(t, ctr.GetTranslationForConn(newTestConn("10.0.0.1", 40001, "10.96.0.10", 80, network.TCP)))
+
+	stats := ctr.GetStats()
+	assert.Equal(t, int64(6), stats["state_size"])
+	assert.Equal(t, int64(2), stats["gets_total"])
+	assert.Equal(t, int64(1), stats["misses_total"])
+	assert.Equal(t, int64(1), stats["refreshes_total"])
+	assert.Equal(t, int64(1), stats["healthy"])
+
+	// both directions of the connection are deleted
+	ctr.DeleteTranslation(conn)
+	assert.Nil(t, ctr.GetTranslationForConn(conn))
+	assert.Equal(t, int64(4), ctr.GetStats()["state_size"])
+
+	// the translations are kept when the table can't be loaded
+	require.NoError(t, os.Remove(path))
+	assert.Error(t, ctr.refresh())
+	stats = ctr.GetStats()
+	assert.Equal(t, int64(4), stats["state_size"])
+	assert.Equal(t, int64(1), stats["refresh_errors"])
+
+	t.Run("unhealthy", func(t *testing.T) {
+		ctr := newFallbackConntracker(&procConntrackTable{path: path}, 100, time.Minute)
+		defer ctr.Close()
+		assert.Error(t, ctr.refresh())
+		assert.Equal(t, int64(0), ctr.GetStats()["healthy"])
+	})
+}
+
+func TestConntrackerWithFallback(t *testing.T) {
+	primary := newFallbackConntracker(&procConntrackTable{path: "testdata/ip_conntrack"}, 100, time.Minute)
+	fallback := newFallbackConntracker(&procConntrackTable{path: "testdata/nf_conntrack"}, 100, time.Minute)
+	require.NoError(t, primary.refresh())
+	require.NoError(t, fallback.refresh())
+
+	ctr := NewConntrackerWithFallback(primary, fallback)
+	defer ctr.Close()
+
+	// known by both
+	conn := newTestConn("10.0.0.1", 40000, "10.96.0.10", 80, network.TCP)
+	assert.NotNil(t, ctr.GetTranslationForConn(conn))
+	// only known by the fallback
+	assert.NotNil(t, ctr.GetTranslationForConn(newTestConn("172.17.0.2", 5353, "8.8.8.8", 53, network.UDP)))
+	// unknown
+	assert.Nil(t, ctr.GetTranslationForConn(newTestConn("172.17.0.2", 5354, "8.8.8.8", 53, network.UDP)))
+
+	ctr.DeleteTranslation(conn)
+	assert.Nil(t, ctr.GetTranslationForConn(conn))
+
+	stats := ctr.GetStats()
+	assert.Equal(t, int64(4), stats["gets_total"])
+	assert.Equal(t, int64(3), stats["misses_total"])
+	assert.Equal(t, int64(3), stats["fallback_gets_total"])
+	assert.Equal(t, int64(2), stats["fallback_misses_total"])
+}
@@ -0,0 +1,214 @@
+// Package nettop displays the top talkers of the connections tracked by a running system-probe
+package nettop
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	model "github.com/DataDog/agent-payload/process"
+	"github.com/dustin/go-humanize"
+)
+
+const (
+	// maxListedValues is the number of domains and HTTP paths displayed per talker in the table
+	maxListedValues = 3
+
+	clearScreen = "\033[H\033[2J"
+)
+
+// ConnectionsGetter retrieves the connections tracked by system-probe since the previous call made by a client
+type ConnectionsGetter interface {
+	GetConnections(clientID string) (*model.Connections, error)
+}
+
+// Options holds the settings of nettop
+type Options struct {
+	// Interval is the time between two refreshes
+	Interval time.Duration
+	// GroupBy is the grouping of the connections: by process, container or destination
+	GroupBy string
+	// SortBy is the criteria used to sort the talkers
+	SortBy string
+	// Limit is the maximum number of talkers displayed, 0 displaying all of them
+	Limit int
+	// Count is the number of refreshes before exiting, 0 running until the exit channel is closed
+	Count int
+	// JSON outputs a JSON object per line and refresh instead of a table
+	JSON bool
+	// Clear clears the screen before each table
+	Clear bool
+
+	// ProcRoot is used to retrieve the name of the processes
+	ProcRoot string
+	// ContainerIDForPID returns the ID of the container of a process, or an empty string
+	ContainerIDForPID func(pid int) (string, error)
+}
+
+// Validate returns an error if one of the options is invalid
+func (o *Options) Validate() error {
+	if o.Interval <= 0 {
+		return fmt.Errorf("invalid interval %s, it must be positive", o.Interval)
+	}
+	switch o.GroupBy {
+	case GroupByProcess, GroupByContainer, GroupByDestination:
+	default:
+		return fmt.Errorf("invalid grouping %q, it must be one of %s, %s or %s", o.GroupBy, GroupByProcess, GroupByContainer, GroupByDestination)
+	}
+	switch o.SortBy {
+	case SortByTotal, SortBySent, SortByRecv, SortByConns, SortByRetransmits:
+	default:
+		return fmt.Errorf("invalid sort %q, it must be one of %s, %s, %s, %s or %s", o.SortBy, SortByTotal, SortBySent, SortByRecv, SortByConns, SortByRetransmits)
+	}
+	if o.Limit < 0 || o.Count < 0 {
+		return fmt.Errorf("the limit and the count can't be negative")
+	}
+	return nil
+}
+
+// report is the JSON output of a refresh
+type report struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Interval    float64   `json:"interval_secs"`
+	GroupBy     string    `json:"group_by"`
+	Connections int       `json:"connections"`
+	Talkers     []*Talker `json:"talkers"`
+}
+
+// Run displays the top talkers every interval, until the exit channel is closed or the count of refreshes is reached.
+// The rates are derived from the traffic of the connections since the previous refresh.
+func Run(getter ConnectionsGetter, opts Options, out io.Writer, exit <-chan struct{}) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	// the first call registers the client, whose connections then only hold the traffic since the previous call
+	clientID := fmt.Sprintf("nettop-%d", os.Getpid())
+	if _, err := getter.GetConnections(clientID); err != nil {
+		return fmt.Errorf("error retrieving the connections from system-probe: %s", err)
+	}
+	last := time.Now()
+
+	resolver := newProcessResolver(opts.ProcRoot, opts.ContainerIDForPID)
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	for refreshes := 0; opts.Count == 0 || refreshes < opts.Count; refreshes++ {
+		select {
+		case <-exit:
+			return nil
+		case <-ticker.C:
+		}
+
+		conns, err := getter.GetConnections(clientID)
+		if err != nil {
+			return fmt.Errorf("error retrieving the connections from system-probe: %s", err)
+		}
+		now := time.Now()
+
+		resolver.reset()
+		talkers := topTalkers(conns, opts.GroupBy, now.Sub(last), resolver)
+		sortTalkers(talkers, opts.SortBy)
+		if opts.Limit > 0 && len(talkers) > opts.Limit {
+			talkers = talkers[:opts.Limit]
+		}
+
+		r := &report{
+			Timestamp:   now,
+			Interval:    now.Sub(last).Seconds(),
+			GroupBy:     opts.GroupBy,
+			Connections: len(conns.Conns),
+			Talkers:     talkers,
+		}
+		if opts.JSON {
+			err = json.NewEncoder(out).Encode(r)
+		} else {
+			err = writeTable(out, r, opts)
+		}
+		if err != nil {
+			return err
+		}
+		last = now
+	}
+	return nil
+}
+
+func writeTable(out io.Writer, r *report, opts Options) error {
+	if opts.Clear {
+		fmt.Fprint(out, clearScreen)
+	}
+	fmt.Fprintf(out, "nettop - %s - %d connections over %.1fs, by %s, sorted by %s\n\n",
+		r.Timestamp.Format("15:04:05"), r.Connections, r.Interval, r.GroupBy, opts.SortBy)
+
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	switch r.GroupBy {
+	case GroupByProcess:
+		fmt.Fprint(w, "PID\tPROCESS\tCONTAINER\t")
+	case GroupByContainer:
+		fmt.Fprint(w, "CONTAINER\t")
+	case GroupByDestination:
+		fmt.Fprint(w, "DESTINATION\t")
+	}
+	fmt.Fprintln(w, "CONNS\tSENT/s\tRECV/s\tRETRANS\tDNS\tHTTP")
+
+	for _, t := range r.Talkers {
+		switch r.GroupBy {
+		case GroupByProcess:
+			fmt.Fprintf(w, "%d\t%s\t%s\t", t.Pid, orDash(t.Process), orDash(shortContainerID(t.ContainerID)))
+		case GroupByContainer:
+			fmt.Fprintf(w, "%s\t", orDash(shortContainerID(t.ContainerID)))
+		case GroupByDestination:
+			fmt.Fprintf(w, "%s\t", orDash(t.Destination))
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\t%s\n",
+			t.Connections,
+			humanize.Bytes(uint64(t.SentRate)),
+			humanize.Bytes(uint64(t.RecvRate)),
+			t.Retransmits,
+			orDash(listValues(t.Domains)),
+			orDash(listValues(topPaths(t.HTTPPaths))),
+		)
+	}
+	return w.Flush()
+}
+
+// topPaths returns the HTTP paths sorted by descending number of requests
+func topPaths(paths map[string]uint32) []string {
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if paths[sorted[i]] != paths[sorted[j]] {
+			return paths[sorted[i]] > paths[sorted[j]]
+		}
+		return sorted[i] < sorted[j]
+	})
+	return sorted
+}
+
+func listValues(values []string) string {
+	if len(values) <= maxListedValues {
+		return strings.Join(values, ",")
+	}
+	return fmt.Sprintf("%s,+%d", strings.Join(values[:maxListedValues], ","), len(values)-maxListedValues)
+}
+
+func shortContainerID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,202 @@
+package nettop
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	model "github.com/DataDog/agent-payload/process"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConnections() *model.Connections {
+	return &model.Connections{
+		Conns: []*model.Connection{
+			{
+				Pid:               1,
+				Laddr:             &model.Addr{Ip: "10.0.0.1", Port: 40000},
+				Raddr:             &model.Addr{Ip: "10.0.0.2", Port: 80},
+				LastBytesSent:     100,
+				LastBytesReceived: 1000,
+				LastRetransmits:   1,
+				HttpStatsByPath: map[string]*model.HTTPStats{
+					"/api": {StatsByResponseStatus: []*model.HTTPStats_Data{{Count: 0}, {Count: 3}, nil, {Count: 1}, {Count: 0}}},
+				},
+			},
+			{
+				Pid:               1,
+				Laddr:             &model.Addr{Ip: "10.0.0.1", Port: 40001},
+				Raddr:             &model.Addr{Ip: "10.0.0.3", Port: 53},
+				LastBytesSent:     50,
+				LastBytesReceived: 50,
+				DnsStatsByDomain:  map[int32]*model.DNSStats{1: {DnsTimeouts: 1}},
+			},
+			{
+				Pid:               2,
+				Laddr:             &model.Addr{Ip: "10.0.0.1", Port: 40002},
+				Raddr:             &model.Addr{Ip: "10.0.0.2", Port: 80},
+				LastBytesSent:     4000,
+				LastBytesReceived: 0,
+			},
+		},
+		Dns: map[string]*model.DNSEntry{
+			"10.0.0.2": {Names: []string{"web.local"}},
+		},
+		Domains: []string{"unused.local", "queried.local"},
+	}
+}
+
+func TestTopTalkers(t *testing.T) {
+	procRoot, err := ioutil.TempDir("", "nettop")
+	require.NoError(t, err)
+	defer os.RemoveAll(procRoot)
+	require.NoError(t, os.MkdirAll(filepath.Join(procRoot, "1"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(procRoot, "1", "comm"), []byte("curl\n"), 0644))
+
+	containerIDForPID := func(pid int) (string, error) {
+		if pid == 2 {
+			return "", errors.New("no such process")
+		}
+		return "abcdef", nil
+	}
+	resolver := newProcessResolver(procRoot, containerIDForPID)
+
+	t.Run("by process", func(t *testing.T) {
+		talkers := topTalkers(testConnections(), GroupByProcess, 2*time.Second, resolver)
+		sortTalkers(talkers, SortByRecv)
+		require.Len(t, talkers, 2)
+
+		assert.Equal(t, &Talker{
+			Key:         "1",
+			Pid:         1,
+			Process:     "curl",
+			ContainerID: "abcdef",
+			Connections: 2,
+			SentBytes:   150,
+			RecvBytes:   1050,
+			SentRate:    75,
+			RecvRate:    525,
+			Retransmits: 1,
+			Domains:     []string{"queried.local", "web.local"},
+			HTTPPaths:   map[string]uint32{"/api": 4},
+			domains:     talkers[0].domains,
+		}, talkers[0])
+		assert.Equal(t, int32(2), talkers[1].Pid)
+		assert.Equal(t, "", talkers[1].Process)
+
+		sortTalkers(talkers, SortByTotal)
+		assert.Equal(t, int32(2), talkers[0].Pid)
+		sortTalkers(talkers, SortByConns)
+		assert.Equal(t, int32(1), talkers[0].Pid)
+		sortTalkers(talkers, SortByRetransmits)
+		assert.Equal(t, int32(1), talkers[0].Pid)
+	})
+
+	t.Run("by container", func(t *testing.T) {
+		talkers := topTalkers(testConnections(), GroupByContainer, time.Second, resolver)
+		sortTalkers(talkers, SortByRecv)
+		require.Len(t, talkers, 2)
+		assert.Equal(t, "abcdef", talkers[0].ContainerID)
+		assert.Equal(t, 2, talkers[0].Connections)
+		assert.Equal(t, "", talkers[1].ContainerID)
+	})
+
+	t.Run("by destination", func(t *testing.T) {
+		talkers := topTalkers(testConnections(), GroupByDestination, time.Second, resolver)
+		sortTalkers(talkers, SortByTotal)
+		require.Len(t, talkers, 2)
+		assert.Equal(t, "10.0.0.2:80", talkers[0].Destination)
+		assert.Equal(t, 2, talkers[0].Connections)
+		assert.Equal(t, uint64(5100), talkers[0].SentBytes+talkers[0].RecvBytes)
+		assert.Equal(t, []string{"web.local"}, talkers[0].Domains)
+		assert.Equal(t, "10.0.0.3:53", talkers[1].Destination)
+	})
+}
+
+type fakeGetter struct {
+	calls []string
+	err   error
+}
+
+func (g *fakeGetter) GetConnections(clientID string) (*model.Connections, error) {
+	g.calls = append(g.calls, clientID)
+	return testConnections(), g.err
+}
+
+func TestRun(t *testing.T) {
+	opts := Options{
+		Interval: time.Millisecond,
+		GroupBy:  GroupByDestination,
+		SortBy:   SortByTotal,
+		Limit:    1,
+		Count:    2,
+	}
+
+	t.Run("table", func(t *testing.T) {
+		getter := &fakeGetter{}
+		var out bytes.Buffer
+		require.NoError(t, Run(getter, opts, &out, make(chan struct{})))
+
+		// the first call only registers the client
+		assert.Len(t, getter.calls, 3)
+		assert.Equal(t, getter.calls[0], getter.calls[2])
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		require.Len(t, lines, 8)
+		assert.Contains(t, lines[0], "3 connections")
+		assert.Equal(t, []string{"DESTINATION", "CONNS", "SENT/s", "RECV/s", "RETRANS", "DNS", "HTTP"}, strings.Fields(lines[2]))
+		fields := strings.Fields(lines[3])
+		assert.Equal(t, "10.0.0.2:80", fields[0])
+		assert.Equal(t, "web.local", fields[len(fields)-2])
+		assert.Equal(t, "/api", fields[len(fields)-1])
+	})
+
+	t.Run("json", func(t *testing.T) {
+		jsonOpts := opts
+		jsonOpts.JSON = true
+		jsonOpts.Count = 1
+		var out bytes.Buffer
+		require.NoError(t, Run(&fakeGetter{}, jsonOpts, &out, make(chan struct{})))
+
+		var r report
+		require.NoError(t, json.Unmarshal(out.Bytes(), &r))
+		assert.Equal(t, GroupByDestination, r.GroupBy)
+		assert.Equal(t, 3, r.Connections)
+		require.Len(t, r.Talkers, 1)
+		assert.Equal(t, "10.0.0.2:80", r.Talkers[0].Destination)
+		assert.Equal(t, map[string]uint32{"/api": 4}, r.Talkers[0].HTTPPaths)
+	})
+
+	t.Run("exit", func(t *testing.T) {
+		exit := make(chan struct{})
+		close(exit)
+		runOpts := opts
+		runOpts.Count = 0
+		runOpts.Interval = time.Hour
+		var out bytes.Buffer
+		require.NoError(t, Run(&fakeGetter{}, runOpts, &out, exit))
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		err := Run(&fakeGetter{err: errors.New("connection refused")}, opts, ioutil.Discard, make(chan struct{}))
+		assert.Error(t, err)
+
+		invalid := opts
+		invalid.GroupBy = "pod"
+		assert.Error(t, Run(&fakeGetter{}, invalid, ioutil.Discard, make(chan struct{})))
+	})
+}
+
+func TestListValues(t *testing.T) {
+	assert.Equal(t, "a,b", listValues([]string{"a", "b"}))
+	assert.Equal(t, "a,b,c,+2", listValues([]string{"a", "b", "c", "d", "e"}))
+	assert.Equal(t, []string{"/b", "/a", "/c"}, topPaths(map[string]uint32{"/a": 1, "/b": 2, "/c": 1}))
+}
@@ -0,0 +1,221 @@
+package nettop
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	model "github.com/DataDog/agent-payload/process"
+)
+
+// Grouping of the connections
+const (
+	GroupByProcess     = "process"
+	GroupByContainer   = "container"
+	GroupByDestination = "destination"
+)
+
+// Sorting of the talkers, in descending order
+const (
+	SortByTotal       = "total"
+	SortBySent        = "sent"
+	SortByRecv        = "recv"
+	SortByConns       = "conns"
+	SortByRetransmits = "retransmits"
+)
+
+// Talker holds the traffic of a group of connections during the last interval
+type Talker struct {
+	Key         string  `json:"key"`
+	Pid         int32   `json:"pid,omitempty"`
+	Process     string  `json:"process,omitempty"`
+	ContainerID string  `json:"container_id,omitempty"`
+	Destination string  `json:"destination,omitempty"`
+	Connections int     `json:"connections"`
+	SentBytes   uint64  `json:"sent_bytes"`
+	RecvBytes   uint64  `json:"recv_bytes"`
+	SentRate    float64 `json:"sent_bytes_per_sec"`
+	RecvRate    float64 `json:"recv_bytes_per_sec"`
+	Retransmits uint32  `json:"retransmits"`
+	// Domains lists the domains resolved to the destinations or queried by the connections
+	Domains []string `json:"dns_domains,omitempty"`
+	// HTTPPaths holds the number of requests per HTTP path
+	HTTPPaths map[string]uint32 `json:"http_paths,omitempty"`
+
+	domains map[string]struct{}
+}
+
+// processResolver resolves the name and the container of the processes, caching them for a refresh
+type processResolver struct {
+	procRoot          string
+	containerIDForPID func(pid int) (string, error)
+
+	names      map[int32]string
+	containers map[int32]string
+}
+
+func newProcessResolver(procRoot string, containerIDForPID func(pid int) (string, error)) *processResolver {
+	return &processResolver{
+		procRoot:          procRoot,
+		containerIDForPID: containerIDForPID,
+		names:             make(map[int32]string),
+		containers:        make(map[int32]string),
+	}
+}
+
+// reset forgets the resolved processes, whose pid may be reused
+func (r *processResolver) reset() {
+	r.names = make(map[int32]string)
+	r.containers = make(map[int32]string)
+}
+
+func (r *processResolver) name(pid int32) string {
+	if name, ok := r.names[pid]; ok {
+		return name
+	}
+
+	var name string
+	if r.procRoot != "" {
+		if comm, err := ioutil.ReadFile(filepath.Join(r.procRoot, strconv.Itoa(int(pid)), "comm")); err == nil {
+			name = strings.TrimSpace(string(comm))
+		}
+	}
+	r.names[pid] = name
+	return name
+}
+
+func (r *processResolver) containerID(pid int32) string {
+	if id, ok := r.containers[pid]; ok {
+		return id
+	}
+
+	var id string
+	if r.containerIDForPID != nil {
+		id, _ = r.containerIDForPID(int(pid))
+	}
+	r.containers[pid] = id
+	return id
+}
+
+// topTalkers groups the connections, computing the rates from the traffic since the previous call made by the client
+func topTalkers(conns *model.Connections, groupBy string, elapsed time.Duration, r *processResolver) []*Talker {
+	talkers := make(map[string]*Talker)
+	for _, c := range conns.Conns {
+		var key string
+		var t *Talker
+		switch groupBy {
+		case GroupByContainer:
+			id := r.containerID(c.Pid)
+			key = id
+			if t = talkers[key]; t == nil {
+				t = &Talker{ContainerID: id}
+			}
+		case GroupByDestination:
+			dest := formatAddr(c.Raddr)
+			key = dest
+			if t = talkers[key]; t == nil {
+				t = &Talker{Destination: dest}
+			}
+		default:
+			key = strconv.Itoa(int(c.Pid))
+			if t = talkers[key]; t == nil {
+				t = &Talker{Pid: c.Pid, Process: r.name(c.Pid), ContainerID: r.containerID(c.Pid)}
+			}
+		}
+		t.Key = key
+		talkers[key] = t
+
+		t.Connections++
+		t.SentBytes += c.LastBytesSent
+		t.RecvBytes += c.LastBytesReceived
+		t.Retransmits += c.LastRetransmits
+		t.addDomains(conns, c)
+		for path, stats := range c.HttpStatsByPath {
+			if t.HTTPPaths == nil {
+				t.HTTPPaths = make(map[string]uint32)
+			}
+			for _, data := range stats.StatsByResponseStatus {
+				if data != nil {
+					t.HTTPPaths[path] += data.Count
+				}
+			}
+		}
+	}
+
+	result := make([]*Talker, 0, len(talkers))
+	for _, t := range talkers {
+		if secs := elapsed.Seconds(); secs > 0 {
+			t.SentRate = float64(t.SentBytes) / secs
+			t.RecvRate = float64(t.RecvBytes) / secs
+		}
+		for domain := range t.domains {
+			t.Domains = append(t.Domains, domain)
+		}
+		sort.Strings(t.Domains)
+		result = append(result, t)
+	}
+	return result
+}
+
+func (t *Talker) addDomains(conns *model.Connections, c *model.Connection) {
+	add := func(domain string) {
+		if domain == "" {
+			return
+		}
+		if t.domains == nil {
+			t.domains = make(map[string]struct{})
+		}
+		t.domains[domain] = struct{}{}
+	}
+
+	if c.Raddr != nil {
+		if entry, ok := conns.Dns[c.Raddr.Ip]; ok && entry != nil {
+			for _, name := range entry.Names {
+				add(name)
+			}
+		}
+	}
+	for i := range c.DnsStatsByDomain {
+		if i >= 0 && int(i) < len(conns.Domains) {
+			add(conns.Domains[i])
+		}
+	}
+}
+
+// sortTalkers sorts the talkers in descending order of the given criteria
+func sortTalkers(talkers []*Talker, sortBy string) {
+	value := func(t *Talker) uint64 {
+		switch sortBy {
+		case SortBySent:
+			return t.SentBytes
+		case SortByRecv:
+			return t.RecvBytes
+		case SortByConns:
+			return uint64(t.Connections)
+		case SortByRetransmits:
+			return uint64(t.Retransmits)
+		default:
+			return t.SentBytes + t.RecvBytes
+		}
+	}
+
+	sort.Slice(talkers, func(i, j int) bool {
+		vi, vj := value(talkers[i]), value(talkers[j])
+		if vi != vj {
+			return vi > vj
+		}
+		return talkers[i].Key < talkers[j].Key
+	})
+}
+
+func formatAddr(addr *model.Addr) string {
+	if addr == nil {
+		return ""
+	}
+	return net.JoinHostPort(addr.Ip, fmt.Sprintf("%d", addr.Port))
+}
@@ -2,10 +2,12 @@ package network
 
 import (
 	"fmt"
+	"hash/fnv"
 	"net"
 	"strconv"
 	"strings"
 
+	"github.com/DataDog/datadog-agent/pkg/network/config"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -192,3 +194,67 @@ func findMatchingFilter(cf []*ConnectionFilter, ip net.IP, addrPort uint16, addr
 	}
 	return false
 }
+
+// ConnectionSamplingRule keeps a ratio of the connections matching its source and destination filters
+type ConnectionSamplingRule struct {
+	SampleRate float64
+	Source     []*ConnectionFilter
+	Dest       []*ConnectionFilter
+}
+
+// ParseConnectionSamplingRules takes the user defined sampling rules and returns the valid ones, in the same order
+func ParseConnectionSamplingRules(rules []config.ConnectionSamplingRule) []*ConnectionSamplingRule {
+	parsed := make([]*ConnectionSamplingRule, 0, len(rules))
+	for i, rule := range rules {
+		if rule.SampleRate < 0 || rule.SampleRate > 1 {
+			log.Errorf("Given sampling rule %d will not be respected. Invalid sample rate %f, it must be between 0 and 1", i, rule.SampleRate)
+			continue
+		}
+
+		r := &ConnectionSamplingRule{
+			SampleRate: rule.SampleRate,
+			Source:     ParseConnectionFilters(rule.Source),
+			Dest:       ParseConnectionFilters(rule.Destination),
+		}
+		// a rule whose filters are all invalid would otherwise match every connection
+		if len(r.Source) < len(rule.Source) || len(r.Dest) < len(rule.Destination) {
+			log.Errorf("Given sampling rule %d will not be respected. It has invalid filters", i)
+			continue
+		}
+		parsed = append(parsed, r)
+	}
+	return parsed
+}
+
+// Matches returns true if the connection matches both the source and destination filters of the rule
+func (r *ConnectionSamplingRule) Matches(conn *ConnectionStats) bool {
+	if len(r.Source) > 0 && (conn.Source == nil || !findMatchingFilter(r.Source, util.NetIPFromAddress(conn.Source), conn.SPort, conn.Type)) {
+		return false
+	}
+	if len(r.Dest) > 0 && (conn.Dest == nil || !findMatchingFilter(r.Dest, util.NetIPFromAddress(conn.Dest), conn.DPort, conn.Type)) {
+		return false
+	}
+	return true
+}
+
+// IsSampledOutConnection returns true if the first rule matching the connection doesn't keep it. The decision only
+// depends on the connection tuple, so that a connection is either always kept or always dropped.
+func IsSampledOutConnection(rules []*ConnectionSamplingRule, conn *ConnectionStats, buf []byte) bool {
+	for _, rule := range rules {
+		if !rule.Matches(conn) {
+			continue
+		}
+		if rule.SampleRate >= 1 || conn.Source == nil || conn.Dest == nil {
+			return false
+		}
+
+		key, err := conn.ByteKey(buf)
+		if err != nil {
+			return false
+		}
+		h := fnv.New32a()
+		_, _ = h.Write(key)
+		return float64(h.Sum32()) >= rule.SampleRate*(1<<32)
+	}
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"math/rand"
 	"testing"
 
+	"github.com/DataDog/datadog-agent/pkg/network/config"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 	"github.com/stretchr/testify/assert"
 )
@@ -79,6 +80,44 @@ func TestParseConnectionFilters(t *testing.T) {
 	assert.False(t, IsExcludedConnection(sourceList, destList, &ConnectionStats{Dest: util.AddressFromString("10.0.0.5"), DPort: uint16(0), Type: TCP}))         // invalid port
 }
 
+func TestConnectionSamplingRules(t *testing.T) {
+	rules := ParseConnectionSamplingRules([]config.ConnectionSamplingRule{
+		{SampleRate: 0, Destination: map[string][]string{"10.0.0.0/24": {"8125"}}},
+		{SampleRate: 1, Destination: map[string][]string{"10.0.0.1": {"*"}}},
+		{SampleRate: 0.5, Source: map[string][]string{"*": {"udp *"}}}, // invalid config
+		{SampleRate: 1.5},                                              // invalid sample rate
+		{SampleRate: 0.5},
+	})
+	assert.Len(t, rules, 3)
+
+	newConn := func(dest string, sport, dport uint16) *ConnectionStats {
+		return &ConnectionStats{
+			Source: util.AddressFromString("172.0.0.1"),
+			Dest:   util.AddressFromString(dest),
+			SPort:  sport,
+			DPort:  dport,
+			Type:   UDP,
+		}
+	}
+
+	// the first matching rule decides
+	buf := make([]byte, ConnectionByteKeyMaxLen)
+	assert.True(t, IsSampledOutConnection(rules, newConn("10.0.0.1", 50000, 8125), buf))
+	assert.False(t, IsSampledOutConnection(rules, newConn("10.0.0.1", 50000, 53), buf))
+
+	// the remaining connections are sampled at 50%, always with the same decision for a given connection
+	sampledOut := 0
+	for sport := uint16(50000); sport < 52000; sport++ {
+		conn := newConn("10.0.1.1", sport, 53)
+		decision := IsSampledOutConnection(rules, conn, buf)
+		assert.Equal(t, decision, IsSampledOutConnection(rules, conn, buf))
+		if decision {
+			sampledOut++
+		}
+	}
+	assert.InDelta(t, 1000, sampledOut, 100)
+}
+
 var sink bool
 
 func BenchmarkIsBlacklistedConnectionIPv4(b *testing.B) {
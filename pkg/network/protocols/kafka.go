@@ -0,0 +1,381 @@
+package protocols
+
+import (
+	"encoding/binary"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	kafkaLengthLen     = 4
+	kafkaMaxMessageLen = 100 * 1024 * 1024
+	// kafkaMaxAPIKey and kafkaMaxAPIVersion bound the requests considered valid when classifying connections
+	kafkaMaxAPIKey     = 74
+	kafkaMaxAPIVersion = 20
+
+	kafkaProduce = 0
+	kafkaFetch   = 1
+)
+
+var kafkaAPINames = map[int16]string{
+	0:  "Produce",
+	1:  "Fetch",
+	2:  "ListOffsets",
+	3:  "Metadata",
+	8:  "OffsetCommit",
+	9:  "OffsetFetch",
+	10: "FindCoordinator",
+	11: "JoinGroup",
+	12: "Heartbeat",
+	13: "LeaveGroup",
+	14: "SyncGroup",
+	15: "DescribeGroups",
+	16: "ListGroups",
+	17: "SaslHandshake",
+	18: "ApiVersions",
+	19: "CreateTopics",
+	20: "DeleteTopics",
+	22: "InitProducerId",
+	36: "SaslAuthenticate",
+}
+
+// KafkaRequestKey identifies a kind of Kafka requests
+type KafkaRequestKey struct {
+	APIKey int16
+	// Topic is the first topic of the Produce and Fetch requests, empty for the other requests
+	Topic string
+}
+
+// APIName returns the name of the API of the requests
+func (k KafkaRequestKey) APIName() string {
+	if name, ok := kafkaAPINames[k.APIKey]; ok {
+		return name
+	}
+	return "Api" + strconv.Itoa(int(k.APIKey))
+}
+
+// kafkaTransaction is a Kafka request matched with its response
+type kafkaTransaction struct {
+	Key     Key
+	Request KafkaRequestKey
+	// Latency is in milliseconds
+	Latency float64
+}
+
+// kafkaFramer splits a Kafka stream into messages, each starting with its length
+type kafkaFramer struct{}
+
+func (kafkaFramer) headerLen() int {
+	return kafkaLengthLen
+}
+
+func (kafkaFramer) messageLen(header []byte) (int, error) {
+	size := int32(binary.BigEndian.Uint32(header))
+	if size < 4 || size > kafkaMaxMessageLen {
+		return 0, errInvalidLength
+	}
+	return kafkaLengthLen + int(size), nil
+}
+
+// kafkaReader decodes the primitive types of the Kafka protocol
+type kafkaReader struct {
+	buf []byte
+	err error
+}
+
+func (r *kafkaReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.buf) {
+		r.err = errInvalidMessage
+		return nil
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *kafkaReader) skip(n int) {
+	r.next(n)
+}
+
+func (r *kafkaReader) int16() int16 {
+	if b := r.next(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (r *kafkaReader) int32() int32 {
+	if b := r.next(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (r *kafkaReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.buf)
+	if n <= 0 {
+		r.err = errInvalidMessage
+		return 0
+	}
+	r.buf = r.buf[n:]
+	return v
+}
+
+// nullableString decodes a STRING or a NULLABLE_STRING
+func (r *kafkaReader) nullableString() string {
+	n := r.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(r.next(int(n)))
+}
+
+// compactNullableString decodes a COMPACT_STRING or a COMPACT_NULLABLE_STRING
+func (r *kafkaReader) compactNullableString() string {
+	n := r.uvarint()
+	if n == 0 {
+		return ""
+	}
+	return string(r.next(int(n - 1)))
+}
+
+// arrayLen decodes the length of an ARRAY or of a COMPACT_ARRAY
+func (r *kafkaReader) arrayLen(compact bool) int {
+	if compact {
+		return int(r.uvarint()) - 1
+	}
+	return int(r.int32())
+}
+
+func (r *kafkaReader) skipTaggedFields() {
+	for n := r.uvarint(); n > 0 && r.err == nil; n-- {
+		r.uvarint()
+		r.skip(int(r.uvarint()))
+	}
+}
+
+// kafkaFlexibleVersion returns whether the request uses the compact encodings and the tagged fields. Only the requests
+// whose body is decoded are listed.
+func kafkaFlexibleVersion(apiKey, apiVersion int16) bool {
+	switch apiKey {
+	case kafkaProduce:
+		return apiVersion >= 9
+	case kafkaFetch:
+		return apiVersion >= 12
+	}
+	return false
+}
+
+// kafkaRequest is the decoded start of a Kafka request
+type kafkaRequest struct {
+	apiKey        int16
+	apiVersion    int16
+	correlationID int32
+	topic         string
+	// expectResponse is unset for the Produce requests with acks=0
+	expectResponse bool
+}
+
+// parseKafkaRequest decodes the prefix of a request, including its length. Only the header is required: the topic is
+// left empty when the body can't be decoded.
+func parseKafkaRequest(msg []byte) (*kafkaRequest, error) {
+	r := &kafkaReader{buf: msg}
+	r.skip(kafkaLengthLen)
+	req := &kafkaRequest{
+		apiKey:         r.int16(),
+		apiVersion:     r.int16(),
+		correlationID:  r.int32(),
+		expectResponse: true,
+	}
+	// the client ID is never compact, even in the flexible versions
+	r.nullableString()
+	if r.err != nil || req.apiKey < 0 || req.apiVersion < 0 {
+		return nil, errInvalidMessage
+	}
+
+	flexible := kafkaFlexibleVersion(req.apiKey, req.apiVersion)
+	if flexible {
+		r.skipTaggedFields()
+	}
+	str := r.nullableString
+	if flexible {
+		str = r.compactNullableString
+	}
+
+	switch req.apiKey {
+	case kafkaProduce:
+		if req.apiVersion >= 3 {
+			// transactional_id
+			str()
+		}
+		acks := r.int16()
+		// timeout_ms
+		r.skip(4)
+		if r.err == nil && acks == 0 {
+			req.expectResponse = false
+		}
+	case kafkaFetch:
+		if req.apiVersion < 15 {
+			// replica_id
+			r.skip(4)
+		}
+		// max_wait_ms, min_bytes
+		r.skip(8)
+		if req.apiVersion >= 3 {
+			// max_bytes
+			r.skip(4)
+		}
+		if req.apiVersion >= 4 {
+			// isolation_level
+			r.skip(1)
+		}
+		if req.apiVersion >= 7 {
+			// session_id, session_epoch
+			r.skip(8)
+		}
+		if req.apiVersion >= 13 {
+			// the topics are identified by their ID
+			return req, nil
+		}
+	default:
+		return req, nil
+	}
+
+	if r.arrayLen(flexible) > 0 {
+		if topic := str(); r.err == nil {
+			req.topic = topic
+		}
+	}
+	return req, nil
+}
+
+// parseKafkaResponse returns the correlation ID of a response, given its prefix including its length
+func parseKafkaResponse(msg []byte) (int32, error) {
+	if len(msg) < kafkaLengthLen+4 {
+		return 0, errInvalidMessage
+	}
+	return int32(binary.BigEndian.Uint32(msg[kafkaLengthLen:])), nil
+}
+
+// isKafkaRequest returns whether the payload starts with a Kafka request
+func isKafkaRequest(payload []byte) bool {
+	const minLen = kafkaLengthLen + 10
+	if len(payload) < minLen {
+		return false
+	}
+	size := int32(binary.BigEndian.Uint32(payload))
+	if size < minLen-kafkaLengthLen || size > kafkaMaxMessageLen {
+		return false
+	}
+	apiKey := int16(binary.BigEndian.Uint16(payload[4:]))
+	apiVersion := int16(binary.BigEndian.Uint16(payload[6:]))
+	correlationID := int32(binary.BigEndian.Uint32(payload[8:]))
+	clientIDLen := int16(binary.BigEndian.Uint16(payload[12:]))
+	return apiKey >= 0 && apiKey <= kafkaMaxAPIKey &&
+		apiVersion >= 0 && apiVersion <= kafkaMaxAPIVersion &&
+		correlationID >= 0 &&
+		clientIDLen >= -1 && int32(clientIDLen) <= size-10
+}
+
+type kafkaPendingRequest struct {
+	key   KafkaRequestKey
+	start time.Time
+}
+
+// kafkaConn matches the requests of a Kafka connection with their responses, using their correlation ID
+type kafkaConn struct {
+	key       Key
+	telemetry *parserTelemetry
+	handler   func(kafkaTransaction)
+
+	client  *messageStream
+	server  *messageStream
+	pending map[int32]kafkaPendingRequest
+}
+
+func newKafkaConn(key Key, telemetry *parserTelemetry, handler func(kafkaTransaction)) *kafkaConn {
+	return &kafkaConn{
+		key:       key,
+		telemetry: telemetry,
+		handler:   handler,
+		client:    newMessageStream(kafkaFramer{}, messagePrefixLen),
+		server:    newMessageStream(kafkaFramer{}, messagePrefixLen),
+		pending:   make(map[int32]kafkaPendingRequest),
+	}
+}
+
+func (c *kafkaConn) process(fromClient bool, seg *Segment, now time.Time) error {
+	if fromClient {
+		return c.client.feed(seg.Seq, seg.Payload, seg.Len, func(prefix []byte, _ int) error {
+			return c.onRequest(prefix, now)
+		})
+	}
+	return c.server.feed(seg.Seq, seg.Payload, seg.Len, func(prefix []byte, _ int) error {
+		return c.onResponse(prefix, now)
+	})
+}
+
+func (c *kafkaConn) onRequest(msg []byte, now time.Time) error {
+	req, err := parseKafkaRequest(msg)
+	if err != nil {
+		return err
+	}
+	if !req.expectResponse {
+		return nil
+	}
+	if len(c.pending) >= maxPendingRequests {
+		atomic.AddInt64(&c.telemetry.droppedRequests, 1)
+		return nil
+	}
+	c.pending[req.correlationID] = kafkaPendingRequest{
+		key:   KafkaRequestKey{APIKey: req.apiKey, Topic: req.topic},
+		start: now,
+	}
+	return nil
+}
+
+func (c *kafkaConn) onResponse(msg []byte, now time.Time) error {
+	correlationID, err := parseKafkaResponse(msg)
+	if err != nil {
+		return err
+	}
+	req, ok := c.pending[correlationID]
+	if !ok {
+		return nil
+	}
+	delete(c.pending, correlationID)
+
+	atomic.AddInt64(&c.telemetry.requests, 1)
+	c.handler(kafkaTransaction{
+		Key:     c.key,
+		Request: req.key,
+		Latency: float64(now.Sub(req.start)) / float64(time.Millisecond),
+	})
+	return nil
+}
+
+func (c *kafkaConn) expire(now time.Time) int {
+	expired := 0
+	for id, req := range c.pending {
+		if now.Sub(req.start) > requestTimeout {
+			delete(c.pending, id)
+			expired++
+		}
+	}
+	return expired
+}
+
+func newKafkaParser(handler func(kafkaTransaction)) *parser {
+	p := newParser(isKafkaRequest)
+	p.newConn = func(key Key, _ []byte) protocolConn {
+		return newKafkaConn(key, &p.telemetry, handler)
+	}
+	return p
+}
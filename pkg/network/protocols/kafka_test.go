@@ -0,0 +1,295 @@
+package protocols
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// kafkaBuilder encodes Kafka messages
+type kafkaBuilder struct {
+	buf []byte
+}
+
+func (b *kafkaBuilder) int8(v int8) *kafkaBuilder {
+	b.buf = append(b.buf, byte(v))
+	return b
+}
+
+func (b *kafkaBuilder) int16(v int16) *kafkaBuilder {
+	b.buf = append(b.buf, byte(v>>8), byte(v))
+	return b
+}
+
+func (b *kafkaBuilder) int32(v int32) *kafkaBuilder {
+	b.buf = append(b.buf, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+	return b
+}
+
+func (b *kafkaBuilder) uvarint(v uint64) *kafkaBuilder {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	b.buf = append(b.buf, buf[:n]...)
+	return b
+}
+
+func (b *kafkaBuilder) string(s string) *kafkaBuilder {
+	b.int16(int16(len(s)))
+	b.buf = append(b.buf, s...)
+	return b
+}
+
+func (b *kafkaBuilder) compactString(s string) *kafkaBuilder {
+	b.uvarint(uint64(len(s) + 1))
+	b.buf = append(b.buf, s...)
+	return b
+}
+
+func (b *kafkaBuilder) bytes(n int) *kafkaBuilder {
+	b.buf = append(b.buf, make([]byte, n)...)
+	return b
+}
+
+// message prepends the length of the message
+func (b *kafkaBuilder) message() []byte {
+	return append((&kafkaBuilder{}).int32(int32(len(b.buf))).buf, b.buf...)
+}
+
+func kafkaRequestHeader(apiKey, apiVersion int16, correlationID int32) *kafkaBuilder {
+	b := &kafkaBuilder{}
+	return b.int16(apiKey).int16(apiVersion).int32(correlationID).string("client")
+}
+
+func kafkaProduceRequest(version int16, correlationID int32, acks int16, topic string) []byte {
+	b := kafkaRequestHeader(kafkaProduce, version, correlationID)
+	if version >= 9 {
+		b.uvarint(0)
+		b.uvarint(0)
+		b.int16(acks).int32(1000)
+		return b.uvarint(2).compactString(topic).bytes(32).message()
+	}
+	if version >= 3 {
+		b.int16(-1)
+	}
+	b.int16(acks).int32(1000)
+	return b.int32(1).string(topic).bytes(32).message()
+}
+
+func kafkaFetchRequest(version int16, correlationID int32, topic string) []byte {
+	b := kafkaRequestHeader(kafkaFetch, version, correlationID)
+	if version >= 12 {
+		b.uvarint(0)
+	}
+	b.int32(-1).int32(500).int32(1)
+	if version >= 3 {
+		b.int32(1 << 20)
+	}
+	if version >= 4 {
+		b.int8(0)
+	}
+	if version >= 7 {
+		b.int32(0).int32(-1)
+	}
+	if version >= 12 {
+		return b.uvarint(2).compactString(topic).bytes(16).message()
+	}
+	return b.int32(1).string(topic).bytes(16).message()
+}
+
+func kafkaResponse(correlationID int32, bodyLen int) []byte {
+	return (&kafkaBuilder{}).int32(correlationID).bytes(bodyLen).message()
+}
+
+func TestParseKafkaRequest(t *testing.T) {
+	for _, version := range []int16{0, 3, 8, 9, 11} {
+		req, err := parseKafkaRequest(kafkaProduceRequest(version, 42, 1, "orders"))
+		require.NoError(t, err, "produce v%d", version)
+		assert.Equal(t, &kafkaRequest{
+			apiKey:         kafkaProduce,
+			apiVersion:     version,
+			correlationID:  42,
+			topic:          "orders",
+			expectResponse: true,
+		}, req, "produce v%d", version)
+	}
+
+	for _, version := range []int16{0, 3, 4, 7, 11, 12} {
+		req, err := parseKafkaRequest(kafkaFetchRequest(version, 7, "events"))
+		require.NoError(t, err, "fetch v%d", version)
+		assert.Equal(t, "events", req.topic, "fetch v%d", version)
+		assert.Equal(t, int32(7), req.correlationID)
+	}
+
+	req, err := parseKafkaRequest(kafkaProduceRequest(7, 1, 0, "orders"))
+	require.NoError(t, err)
+	assert.False(t, req.expectResponse)
+
+	// the request body is truncated
+	msg := kafkaProduceRequest(7, 1, 1, "orders")
+	req, err = parseKafkaRequest(msg[:len(msg)-40])
+	require.NoError(t, err)
+	assert.Equal(t, "", req.topic)
+
+	req, err = parseKafkaRequest(kafkaRequestHeader(3, 9, 5).uvarint(0).message())
+	require.NoError(t, err)
+	assert.Equal(t, KafkaRequestKey{APIKey: 3}, KafkaRequestKey{APIKey: req.apiKey, Topic: req.topic})
+
+	_, err = parseKafkaRequest([]byte{0, 0, 0, 8, 0, 3})
+	assert.Equal(t, errInvalidMessage, err)
+}
+
+func TestIsKafkaRequest(t *testing.T) {
+	assert.True(t, isKafkaRequest(kafkaProduceRequest(7, 1, 1, "orders")))
+	assert.True(t, isKafkaRequest(kafkaFetchRequest(12, 1, "events")))
+	assert.False(t, isKafkaRequest([]byte("GET / HTTP/1.1\r\nHost: localhost\r\n\r\n")))
+	assert.False(t, isKafkaRequest(kafkaRequestHeader(1000, 1, 1).message()))
+	assert.False(t, isKafkaRequest(kafkaRequestHeader(3, 1, -1).message()))
+	assert.False(t, isKafkaRequest([]byte{0, 0, 0, 1}))
+}
+
+func TestKafkaRequestKeyAPIName(t *testing.T) {
+	assert.Equal(t, "Produce", KafkaRequestKey{APIKey: 0}.APIName())
+	assert.Equal(t, "Metadata", KafkaRequestKey{APIKey: 3}.APIName())
+	assert.Equal(t, "Api61", KafkaRequestKey{APIKey: 61}.APIName())
+}
+
+// testConn exchanges segments between a client and a server
+type testConn struct {
+	key       Key
+	clientSeq uint32
+	serverSeq uint32
+}
+
+func newTestConn(serverPort uint16) *testConn {
+	return &testConn{
+		key: Key{
+			SourceIP:   util.AddressFromString("1.1.1.1"),
+			DestIP:     util.AddressFromString("2.2.2.2"),
+			SourcePort: 45678,
+			DestPort:   serverPort,
+		},
+		clientSeq: 1000,
+		serverSeq: 5000,
+	}
+}
+
+func (c *testConn) send(p *parser, fromClient bool, payload []byte, now time.Time) {
+	seg := &Segment{Payload: payload, Len: len(payload)}
+	if fromClient {
+		seg.Seq = c.clientSeq
+		c.clientSeq += uint32(len(payload))
+	} else {
+		seg.Seq = c.serverSeq
+		c.serverSeq += uint32(len(payload))
+	}
+	p.Process(c.key, fromClient, seg, now)
+}
+
+func TestKafkaParser(t *testing.T) {
+	var txs []kafkaTransaction
+	p := newKafkaParser(func(tx kafkaTransaction) {
+		txs = append(txs, tx)
+	})
+	conn := newTestConn(9092)
+	now := time.Now()
+
+	// pipelined requests, answered in order
+	var requests []byte
+	requests = append(requests, kafkaProduceRequest(8, 1, 1, "orders")...)
+	requests = append(requests, kafkaFetchRequest(11, 2, "events")...)
+	requests = append(requests, kafkaProduceRequest(8, 3, 0, "orders")...)
+	conn.send(p, true, requests, now)
+
+	var responses []byte
+	responses = append(responses, kafkaResponse(1, 20)...)
+	responses = append(responses, kafkaResponse(2, 2000)...)
+	conn.send(p, false, responses, now.Add(5*time.Millisecond))
+
+	require.Len(t, txs, 2)
+	assert.Equal(t, kafkaTransaction{
+		Key:     conn.key,
+		Request: KafkaRequestKey{APIKey: kafkaProduce, Topic: "orders"},
+		Latency: 5,
+	}, txs[0])
+	assert.Equal(t, KafkaRequestKey{APIKey: kafkaFetch, Topic: "events"}, txs[1].Request)
+
+	// responses without a pending request are ignored
+	conn.send(p, false, kafkaResponse(3, 20), now)
+	assert.Len(t, txs, 2)
+
+	stats := p.GetStats()
+	assert.Equal(t, int64(2), stats["requests"])
+	assert.Equal(t, int64(1), stats["connections"])
+
+	// the connection is forgotten once closed
+	p.Process(conn.key, true, &Segment{Seq: conn.clientSeq, Closing: true}, now)
+	assert.Equal(t, int64(0), p.GetStats()["connections"])
+}
+
+func TestKafkaParserClassification(t *testing.T) {
+	p := newKafkaParser(func(kafkaTransaction) {})
+	conn := newTestConn(9092)
+	now := time.Now()
+
+	conn.send(p, false, kafkaResponse(1, 20), now)
+	conn.send(p, true, []byte("GET / HTTP/1.1\r\nHost: localhost\r\n\r\n"), now)
+	assert.Empty(t, p.conns)
+
+	conn.send(p, true, kafkaProduceRequest(8, 1, 1, "orders"), now)
+	assert.Len(t, p.conns, 1)
+
+	// a desync of the stream drops the connection
+	conn.clientSeq += 2
+	conn.send(p, true, kafkaProduceRequest(8, 2, 1, "orders"), now)
+	assert.Empty(t, p.conns)
+	assert.Equal(t, int64(1), p.GetStats()["decoding_errors"])
+}
+
+func TestKafkaParserExpiration(t *testing.T) {
+	var txs []kafkaTransaction
+	p := newKafkaParser(func(tx kafkaTransaction) {
+		txs = append(txs, tx)
+	})
+	now := time.Now()
+
+	idle := newTestConn(9093)
+	idle.send(p, true, kafkaProduceRequest(8, 1, 1, "orders"), now)
+
+	conn := newTestConn(9092)
+	conn.send(p, true, kafkaProduceRequest(8, 1, 1, "orders"), now)
+	conn.send(p, true, kafkaProduceRequest(8, 2, 1, "orders"), now.Add(requestTimeout))
+
+	// the expiration runs before the segment is processed
+	later := now.Add(requestTimeout + expirationPeriod)
+	conn.send(p, false, kafkaResponse(1, 20), later)
+	conn.send(p, false, kafkaResponse(2, 20), later)
+
+	assert.Equal(t, int64(1), p.GetStats()["expired_requests"])
+	require.Len(t, txs, 1)
+	assert.Equal(t, float64(expirationPeriod/time.Millisecond), txs[0].Latency)
+
+	// the idle connection was forgotten
+	assert.Len(t, p.conns, 1)
+	assert.Contains(t, p.conns, conn.key)
+}
+
+func TestKafkaParserLimits(t *testing.T) {
+	p := newKafkaParser(func(kafkaTransaction) {})
+	p.maxConns = 1
+	now := time.Now()
+
+	conn := newTestConn(9092)
+	var requests []byte
+	for i := 0; i <= maxPendingRequests; i++ {
+		requests = append(requests, kafkaProduceRequest(8, int32(i), 1, "orders")...)
+	}
+	conn.send(p, true, requests, now)
+	assert.Equal(t, int64(1), p.GetStats()["dropped_requests"])
+
+	newTestConn(9093).send(p, true, kafkaProduceRequest(8, 1, 1, "orders"), now)
+	assert.Equal(t, int64(1), p.GetStats()["dropped_connections"])
+}
@@ -0,0 +1,219 @@
+// +build linux_bpf
+
+package protocols
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/DataDog/datadog-agent/pkg/network/ebpf/probes"
+	filterpkg "github.com/DataDog/datadog-agent/pkg/network/filter"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/ebpf/manager"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// protocol identifiers stored in the protocol_ports map
+const (
+	protocolKafka    uint8 = 1
+	protocolPostgres uint8 = 2
+)
+
+// Monitor decodes the Kafka and PostgreSQL traffic of the monitored ports, captured by a socket filter attached to a
+// RAW_SOCKET, into per-connection request stats
+type Monitor struct {
+	source     *filterpkg.AFPacketSource
+	statkeeper *statKeeper
+	parsers    map[string]*parser
+	// ports maps the server ports to the parser of their protocol
+	ports map[uint16]*parser
+
+	decoder     *gopacket.DecodingLayerParser
+	layers      []gopacket.LayerType
+	ipv4Payload *layers.IPv4
+	ipv6Payload *layers.IPv6
+	tcpPayload  *layers.TCP
+
+	exit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewMonitor returns a new Monitor decoding the Kafka and PostgreSQL traffic of the given server ports
+func NewMonitor(procRoot string, mgr *manager.Manager, kafkaPorts, postgresPorts []uint16) (*Monitor, error) {
+	statkeeper := newStatKeeper()
+	m := &Monitor{
+		statkeeper: statkeeper,
+		parsers: map[string]*parser{
+			"kafka":    newKafkaParser(statkeeper.ProcessKafka),
+			"postgres": newPostgresParser(statkeeper.ProcessPostgres),
+		},
+		ports: make(map[uint16]*parser),
+		exit:  make(chan struct{}),
+	}
+
+	portsMap, _, err := mgr.GetMap(string(probes.ProtocolPortsMap))
+	if err != nil {
+		return nil, err
+	}
+	addPorts := func(name string, protocol uint8, ports []uint16) error {
+		for _, port := range ports {
+			port := port
+			if err := portsMap.Put(unsafe.Pointer(&port), unsafe.Pointer(&protocol)); err != nil {
+				return fmt.Errorf("error adding port %d to the %s ports: %s", port, name, err)
+			}
+			m.ports[port] = m.parsers[name]
+		}
+		return nil
+	}
+	if err := addPorts("kafka", protocolKafka, kafkaPorts); err != nil {
+		return nil, err
+	}
+	if err := addPorts("postgres", protocolPostgres, postgresPorts); err != nil {
+		return nil, err
+	}
+
+	filter, _ := mgr.GetProbe(manager.ProbeIdentificationPair{Section: string(probes.SocketProtocolFilter)})
+	if filter == nil {
+		return nil, fmt.Errorf("error retrieving socket filter")
+	}
+
+	// Create the RAW_SOCKET inside the root network namespace
+	var srcErr error
+	err = util.WithRootNS(procRoot, func() error {
+		m.source, srcErr = filterpkg.NewPacketSource(filter)
+		return srcErr
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error enabling protocol traffic inspection: %s", err)
+	}
+
+	m.ipv4Payload = &layers.IPv4{}
+	m.ipv6Payload = &layers.IPv6{}
+	m.tcpPayload = &layers.TCP{}
+	m.decoder = gopacket.NewDecodingLayerParser(
+		layers.LayerTypeEthernet,
+		&layers.Ethernet{},
+		m.ipv4Payload,
+		m.ipv6Payload,
+		m.tcpPayload,
+	)
+	// the TCP payload holding the protocol messages is parsed separately
+	m.decoder.IgnoreUnsupported = true
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.pollPackets()
+	}()
+
+	log.Infof("kafka monitoring enabled on ports %v, postgres monitoring enabled on ports %v", kafkaPorts, postgresPorts)
+	return m, nil
+}
+
+// GetProtocolStats returns the request stats gathered since the last call, per connection
+func (m *Monitor) GetProtocolStats() *Stats {
+	if m == nil {
+		return nil
+	}
+	return m.statkeeper.GetAndResetAllStats()
+}
+
+// GetStats returns stats for use with telemetry
+func (m *Monitor) GetStats() map[string]int64 {
+	if m == nil {
+		return nil
+	}
+
+	stats := m.source.Stats()
+	for name, p := range m.parsers {
+		for key, value := range p.GetStats() {
+			stats[name+"_"+key] = value
+		}
+	}
+	return stats
+}
+
+// Close terminates the decoding of the traffic as well as the underlying socket and the attached filter
+func (m *Monitor) Close() {
+	if m == nil {
+		return
+	}
+
+	close(m.exit)
+	m.wg.Wait()
+	m.source.Close()
+}
+
+func (m *Monitor) pollPackets() {
+	for {
+		err := m.source.VisitPackets(m.exit, m.processPacket)
+
+		if err != nil {
+			log.Warnf("error reading packet: %s", err)
+		}
+
+		// Properly synchronizes termination process
+		select {
+		case <-m.exit:
+			return
+		default:
+		}
+
+		// Sleep briefly and try again
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// processPacket feeds the TCP segment held by the packet to the parser of the protocol of its server port. The
+// underlying packet data can't be referenced after this method call since the underlying memory content gets
+// invalidated by `afpacket`.
+func (m *Monitor) processPacket(data []byte, ts time.Time) error {
+	if err := m.decoder.DecodeLayers(data, &m.layers); err != nil {
+		log.Tracef("error decoding packet: %v", err)
+		return nil
+	}
+
+	if len(m.layers) == 0 || m.layers[len(m.layers)-1] != layers.LayerTypeTCP {
+		return nil
+	}
+
+	tcp := m.tcpPayload
+	seg := Segment{
+		SourcePort: uint16(tcp.SrcPort),
+		DestPort:   uint16(tcp.DstPort),
+		Seq:        tcp.Seq,
+		Payload:    tcp.Payload,
+		Closing:    tcp.FIN || tcp.RST,
+	}
+
+	// the length of the payload on the wire is needed since the captured packets may be truncated
+	switch m.layers[len(m.layers)-2] {
+	case layers.LayerTypeIPv4:
+		seg.Source = util.AddressFromNetIP(m.ipv4Payload.SrcIP)
+		seg.Dest = util.AddressFromNetIP(m.ipv4Payload.DstIP)
+		seg.Len = int(m.ipv4Payload.Length) - int(m.ipv4Payload.IHL)*4 - int(tcp.DataOffset)*4
+	case layers.LayerTypeIPv6:
+		seg.Source = util.AddressFromNetIP(m.ipv6Payload.SrcIP)
+		seg.Dest = util.AddressFromNetIP(m.ipv6Payload.DstIP)
+		seg.Len = int(m.ipv6Payload.Length) - int(tcp.DataOffset)*4
+	default:
+		return nil
+	}
+	if seg.Len < len(seg.Payload) {
+		seg.Len = len(seg.Payload)
+	}
+
+	// the direction of the segment is given by the server port
+	if p, ok := m.ports[seg.DestPort]; ok {
+		key := Key{SourceIP: seg.Source, DestIP: seg.Dest, SourcePort: seg.SourcePort, DestPort: seg.DestPort}
+		p.Process(key, true, &seg, ts)
+	} else if p, ok := m.ports[seg.SourcePort]; ok {
+		key := Key{SourceIP: seg.Dest, DestIP: seg.Source, SourcePort: seg.DestPort, DestPort: seg.SourcePort}
+		p.Process(key, false, &seg, ts)
+	}
+	return nil
+}
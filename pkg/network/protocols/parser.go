@@ -0,0 +1,131 @@
+package protocols
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+const (
+	maxConnections     = 10000
+	maxPendingRequests = 1000
+	connectionTimeout  = 2 * time.Minute
+	requestTimeout     = 2 * time.Minute
+	expirationPeriod   = 30 * time.Second
+
+	// messagePrefixLen is the number of bytes buffered at the start of each message, which is enough to hold the
+	// headers decoded by the parsers
+	messagePrefixLen = 1024
+)
+
+// protocolConn decodes the messages exchanged over a connection of a given protocol
+type protocolConn interface {
+	// process decodes the messages of a TCP segment
+	process(fromClient bool, seg *Segment, now time.Time) error
+	// expire forgets the requests waiting for a response for too long and returns their number
+	expire(now time.Time) int
+}
+
+// parserTelemetry holds the counters of a parser, updated atomically
+type parserTelemetry struct {
+	requests             int64
+	decodingErrors       int64
+	encryptedConnections int64
+	droppedConnections   int64
+	droppedRequests      int64
+	expiredRequests      int64
+	connections          int64
+}
+
+type parserConn struct {
+	conn     protocolConn
+	lastSeen time.Time
+}
+
+// parser tracks the connections of a protocol. A connection gets decoded once a segment sent by its client is
+// classified as holding a message of the protocol.
+//
+// The parser isn't safe for concurrent use, except for GetStats.
+type parser struct {
+	// Telemetry is at the beginning of the struct to keep all fields 64-bit aligned.
+	// see https://staticcheck.io/docs/checks#SA1027
+	telemetry parserTelemetry
+
+	// classify returns whether the payload sent by a client starts with a message of the protocol
+	classify func(payload []byte) bool
+	// newConn returns the decoder of a connection, given the first payload classified
+	newConn func(key Key, payload []byte) protocolConn
+
+	conns          map[Key]*parserConn
+	maxConns       int
+	lastExpiration time.Time
+}
+
+func newParser(classify func([]byte) bool) *parser {
+	return &parser{
+		classify: classify,
+		conns:    make(map[Key]*parserConn),
+		maxConns: maxConnections,
+	}
+}
+
+// Process decodes the messages of a TCP segment of the connection identified by key, from its client to its server
+func (p *parser) Process(key Key, fromClient bool, seg *Segment, now time.Time) {
+	if now.Sub(p.lastExpiration) >= expirationPeriod {
+		p.expire(now)
+		p.lastExpiration = now
+	}
+
+	c, ok := p.conns[key]
+	if !ok {
+		if !fromClient || seg.Closing || !p.classify(seg.Payload) {
+			return
+		}
+		if len(p.conns) >= p.maxConns {
+			atomic.AddInt64(&p.telemetry.droppedConnections, 1)
+			return
+		}
+		c = &parserConn{conn: p.newConn(key, seg.Payload)}
+		p.conns[key] = c
+		atomic.StoreInt64(&p.telemetry.connections, int64(len(p.conns)))
+	}
+
+	c.lastSeen = now
+	err := c.conn.process(fromClient, seg, now)
+	switch err {
+	case nil:
+	case errEncryptedStream:
+		atomic.AddInt64(&p.telemetry.encryptedConnections, 1)
+	default:
+		atomic.AddInt64(&p.telemetry.decodingErrors, 1)
+	}
+
+	if err != nil || seg.Closing {
+		delete(p.conns, key)
+		atomic.StoreInt64(&p.telemetry.connections, int64(len(p.conns)))
+	}
+}
+
+// expire forgets the idle connections and the requests that didn't get a response in time
+func (p *parser) expire(now time.Time) {
+	for key, c := range p.conns {
+		if now.Sub(c.lastSeen) > connectionTimeout {
+			delete(p.conns, key)
+			continue
+		}
+		atomic.AddInt64(&p.telemetry.expiredRequests, int64(c.conn.expire(now)))
+	}
+	atomic.StoreInt64(&p.telemetry.connections, int64(len(p.conns)))
+}
+
+// GetStats returns stats for use with telemetry
+func (p *parser) GetStats() map[string]int64 {
+	return map[string]int64{
+		"requests":              atomic.LoadInt64(&p.telemetry.requests),
+		"decoding_errors":       atomic.LoadInt64(&p.telemetry.decodingErrors),
+		"encrypted_connections": atomic.LoadInt64(&p.telemetry.encryptedConnections),
+		"dropped_connections":   atomic.LoadInt64(&p.telemetry.droppedConnections),
+		"dropped_requests":      atomic.LoadInt64(&p.telemetry.droppedRequests),
+		"expired_requests":      atomic.LoadInt64(&p.telemetry.expiredRequests),
+		"connections":           atomic.LoadInt64(&p.telemetry.connections),
+	}
+}
@@ -0,0 +1,307 @@
+package protocols
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// PostgresSimpleQuery is the query type of the queries sent with the simple query protocol
+	PostgresSimpleQuery = "simple"
+	// PostgresExtendedQuery is the query type of the queries sent with the extended query protocol
+	PostgresExtendedQuery = "extended"
+	// PostgresErrorCommand is the command of the queries that failed
+	PostgresErrorCommand = "ERROR"
+
+	postgresProtocolVersion3  = 196608
+	postgresCancelRequestCode = 80877102
+	postgresSSLRequestCode    = 80877103
+	postgresGSSENCRequestCode = 80877104
+
+	postgresTypedHeaderLen   = 5
+	postgresStartupHeaderLen = 8
+	postgresMaxStartupLen    = 10000
+	postgresMaxMessageLen    = 1 << 30
+)
+
+// PostgresRequestKey identifies a kind of PostgreSQL queries
+type PostgresRequestKey struct {
+	// QueryType is either PostgresSimpleQuery or PostgresExtendedQuery
+	QueryType string
+	// Command is the command tag of the queries, such as SELECT or INSERT, or PostgresErrorCommand when they failed
+	Command string
+}
+
+// postgresTransaction is a PostgreSQL query matched with its completion
+type postgresTransaction struct {
+	Key     Key
+	Request PostgresRequestKey
+	// Latency is in milliseconds
+	Latency float64
+}
+
+// postgresFrontendFramer splits the messages sent by a client. The connection starts with untyped messages (the
+// StartupMessage, possibly preceded by a SSLRequest or a GSSENCRequest), followed by typed messages.
+type postgresFrontendFramer struct {
+	startup bool
+}
+
+func (f *postgresFrontendFramer) headerLen() int {
+	if f.startup {
+		return postgresStartupHeaderLen
+	}
+	return postgresTypedHeaderLen
+}
+
+func (f *postgresFrontendFramer) messageLen(header []byte) (int, error) {
+	if f.startup {
+		length := int32(binary.BigEndian.Uint32(header))
+		if length < postgresStartupHeaderLen || length > postgresMaxStartupLen {
+			return 0, errInvalidLength
+		}
+		return int(length), nil
+	}
+	return postgresTypedMessageLen(header)
+}
+
+// postgresBackendFramer splits the messages sent by a server, which are all typed except for the single byte answering
+// a SSLRequest or a GSSENCRequest
+type postgresBackendFramer struct {
+	encryptionResponse bool
+}
+
+func (f *postgresBackendFramer) headerLen() int {
+	if f.encryptionResponse {
+		return 1
+	}
+	return postgresTypedHeaderLen
+}
+
+func (f *postgresBackendFramer) messageLen(header []byte) (int, error) {
+	if f.encryptionResponse {
+		return 1, nil
+	}
+	return postgresTypedMessageLen(header)
+}
+
+func postgresTypedMessageLen(header []byte) (int, error) {
+	length := int32(binary.BigEndian.Uint32(header[1:]))
+	if length < 4 || length > postgresMaxMessageLen {
+		return 0, errInvalidLength
+	}
+	return 1 + int(length), nil
+}
+
+// isPostgresMessage returns whether the payload starts with a message sent by a PostgreSQL client: either one of the
+// messages starting a connection, or a query for the connections already established
+func isPostgresMessage(payload []byte) bool {
+	if isPostgresStartupMessage(payload) {
+		return true
+	}
+	if len(payload) < postgresTypedHeaderLen || (payload[0] != 'Q' && payload[0] != 'P') {
+		return false
+	}
+	length, err := postgresTypedMessageLen(payload)
+	if err != nil || length <= postgresTypedHeaderLen {
+		return false
+	}
+	// the Query and Parse messages end with a null-terminated string
+	return length > len(payload) || payload[length-1] == 0
+}
+
+func isPostgresStartupMessage(payload []byte) bool {
+	if len(payload) < postgresStartupHeaderLen {
+		return false
+	}
+	length := binary.BigEndian.Uint32(payload)
+	if length < postgresStartupHeaderLen || length > postgresMaxStartupLen {
+		return false
+	}
+	switch binary.BigEndian.Uint32(payload[4:]) {
+	case postgresProtocolVersion3, postgresCancelRequestCode, postgresSSLRequestCode, postgresGSSENCRequestCode:
+		return true
+	}
+	return false
+}
+
+// postgresCommand returns the command of a CommandComplete tag, stripping the row counts: "INSERT 0 1" is an INSERT
+func postgresCommand(tag string) string {
+	fields := strings.Fields(tag)
+	for len(fields) > 1 && isDigits(fields[len(fields)-1]) {
+		fields = fields[:len(fields)-1]
+	}
+	return strings.Join(fields, " ")
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// postgresPendingRequest is either a query waiting for its completion, or the marker of the end of a batch of queries
+// (a simple query or a Sync message) waiting for the next ReadyForQuery message
+type postgresPendingRequest struct {
+	// queryType is empty for the Sync messages
+	queryType string
+	start     time.Time
+	// marker is set when the request is completed by a ReadyForQuery message
+	marker bool
+}
+
+// postgresConn matches the queries of a PostgreSQL connection with their completion, relying on the server answering
+// the queries in order
+type postgresConn struct {
+	key       Key
+	telemetry *parserTelemetry
+	handler   func(postgresTransaction)
+
+	frontend *postgresFrontendFramer
+	backend  *postgresBackendFramer
+	client   *messageStream
+	server   *messageStream
+	pending  []postgresPendingRequest
+}
+
+func newPostgresConn(key Key, payload []byte, telemetry *parserTelemetry, handler func(postgresTransaction)) *postgresConn {
+	c := &postgresConn{
+		key:       key,
+		telemetry: telemetry,
+		handler:   handler,
+		frontend:  &postgresFrontendFramer{startup: isPostgresStartupMessage(payload)},
+		backend:   &postgresBackendFramer{},
+	}
+	c.client = newMessageStream(c.frontend, messagePrefixLen)
+	c.server = newMessageStream(c.backend, messagePrefixLen)
+	return c
+}
+
+func (c *postgresConn) process(fromClient bool, seg *Segment, now time.Time) error {
+	if fromClient {
+		return c.client.feed(seg.Seq, seg.Payload, seg.Len, func(prefix []byte, _ int) error {
+			return c.onFrontendMessage(prefix, now)
+		})
+	}
+	return c.server.feed(seg.Seq, seg.Payload, seg.Len, func(prefix []byte, _ int) error {
+		return c.onBackendMessage(prefix, now)
+	})
+}
+
+func (c *postgresConn) onFrontendMessage(msg []byte, now time.Time) error {
+	if c.frontend.startup {
+		switch binary.BigEndian.Uint32(msg[4:]) {
+		case postgresSSLRequestCode, postgresGSSENCRequestCode:
+			c.backend.encryptionResponse = true
+		case postgresCancelRequestCode:
+		default:
+			c.frontend.startup = false
+		}
+		return nil
+	}
+
+	switch msg[0] {
+	case 'Q':
+		return c.push(postgresPendingRequest{queryType: PostgresSimpleQuery, start: now, marker: true})
+	case 'E':
+		return c.push(postgresPendingRequest{queryType: PostgresExtendedQuery, start: now})
+	case 'S':
+		return c.push(postgresPendingRequest{start: now, marker: true})
+	}
+	return nil
+}
+
+func (c *postgresConn) onBackendMessage(msg []byte, now time.Time) error {
+	if c.backend.encryptionResponse {
+		// the server either accepts to encrypt the connection or expects the StartupMessage in clear
+		if msg[0] != 'N' {
+			return errEncryptedStream
+		}
+		c.backend.encryptionResponse = false
+		return nil
+	}
+
+	switch msg[0] {
+	case 'C':
+		// CommandComplete
+		tag := msg[postgresTypedHeaderLen:]
+		if i := bytes.IndexByte(tag, 0); i >= 0 {
+			tag = tag[:i]
+		}
+		c.complete(postgresCommand(string(tag)), now)
+	case 'E':
+		// ErrorResponse
+		c.complete(PostgresErrorCommand, now)
+	case 'I', 's':
+		// EmptyQueryResponse and PortalSuspended end the execution of a query without completing a command
+		c.complete("", now)
+	case 'Z':
+		// ReadyForQuery
+		for len(c.pending) > 0 {
+			req := c.pending[0]
+			c.pending = c.pending[1:]
+			if req.marker {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (c *postgresConn) push(req postgresPendingRequest) error {
+	if len(c.pending) >= maxPendingRequests {
+		// dropping requests would break the matching of the next ones
+		atomic.AddInt64(&c.telemetry.droppedRequests, int64(len(c.pending)))
+		return errInvalidMessage
+	}
+	c.pending = append(c.pending, req)
+	return nil
+}
+
+// complete ends the execution of the oldest query. The extended queries are done once their execution ends, while the
+// simple queries may be made of several commands and are done with the next ReadyForQuery message.
+func (c *postgresConn) complete(command string, now time.Time) {
+	if len(c.pending) == 0 {
+		return
+	}
+	req := c.pending[0]
+	if req.queryType == "" {
+		return
+	}
+	if req.queryType == PostgresExtendedQuery {
+		c.pending = c.pending[1:]
+	}
+	if command == "" {
+		return
+	}
+
+	atomic.AddInt64(&c.telemetry.requests, 1)
+	c.handler(postgresTransaction{
+		Key:     c.key,
+		Request: PostgresRequestKey{QueryType: req.queryType, Command: command},
+		Latency: float64(now.Sub(req.start)) / float64(time.Millisecond),
+	})
+}
+
+// expire drops all the pending queries once the oldest one timed out, since they're answered in order
+func (c *postgresConn) expire(now time.Time) int {
+	if len(c.pending) == 0 || now.Sub(c.pending[0].start) <= requestTimeout {
+		return 0
+	}
+	expired := len(c.pending)
+	c.pending = nil
+	return expired
+}
+
+func newPostgresParser(handler func(postgresTransaction)) *parser {
+	p := newParser(isPostgresMessage)
+	p.newConn = func(key Key, payload []byte) protocolConn {
+		return newPostgresConn(key, payload, &p.telemetry, handler)
+	}
+	return p
+}
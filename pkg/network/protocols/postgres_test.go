@@ -0,0 +1,265 @@
+package protocols
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postgresMessage(typ byte, body ...[]byte) []byte {
+	var payload []byte
+	for _, b := range body {
+		payload = append(payload, b...)
+	}
+	msg := []byte{typ, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(msg[1:], uint32(4+len(payload)))
+	return append(msg, payload...)
+}
+
+func postgresStartupMessage(code uint32, body []byte) []byte {
+	msg := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(msg, uint32(8+len(body)))
+	binary.BigEndian.PutUint32(msg[4:], code)
+	return append(msg, body...)
+}
+
+func cstring(s string) []byte {
+	return append([]byte(s), 0)
+}
+
+func postgresQuery(query string) []byte {
+	return postgresMessage('Q', cstring(query))
+}
+
+func postgresExtendedQuery(query string) []byte {
+	var msgs []byte
+	msgs = append(msgs, postgresMessage('P', cstring(""), cstring(query), []byte{0, 0})...)
+	msgs = append(msgs, postgresMessage('B', cstring(""), cstring(""), []byte{0, 0, 0, 0, 0, 0})...)
+	msgs = append(msgs, postgresMessage('D', []byte{'P'}, cstring(""))...)
+	msgs = append(msgs, postgresMessage('E', cstring(""), []byte{0, 0, 0, 0})...)
+	return msgs
+}
+
+func postgresSync() []byte {
+	return postgresMessage('S')
+}
+
+func postgresCommandComplete(tag string) []byte {
+	return postgresMessage('C', cstring(tag))
+}
+
+func postgresErrorResponse() []byte {
+	return postgresMessage('E', []byte{'S'}, cstring("ERROR"), []byte{'C'}, cstring("42P01"), []byte{0})
+}
+
+func postgresReadyForQuery() []byte {
+	return postgresMessage('Z', []byte{'I'})
+}
+
+func postgresRows(n int) []byte {
+	msgs := postgresMessage('T', []byte{0, 1}, cstring("id"), make([]byte, 18))
+	for i := 0; i < n; i++ {
+		msgs = append(msgs, postgresMessage('D', []byte{0, 1, 0, 0, 0, 1, '1'})...)
+	}
+	return msgs
+}
+
+func concat(msgs ...[]byte) []byte {
+	var buf []byte
+	for _, msg := range msgs {
+		buf = append(buf, msg...)
+	}
+	return buf
+}
+
+func TestPostgresCommand(t *testing.T) {
+	assert.Equal(t, "SELECT", postgresCommand("SELECT 5"))
+	assert.Equal(t, "INSERT", postgresCommand("INSERT 0 1"))
+	assert.Equal(t, "CREATE TABLE", postgresCommand("CREATE TABLE"))
+	assert.Equal(t, "BEGIN", postgresCommand("BEGIN"))
+	assert.Equal(t, "", postgresCommand(""))
+}
+
+func TestIsPostgresMessage(t *testing.T) {
+	assert.True(t, isPostgresMessage(postgresStartupMessage(postgresProtocolVersion3, concat(cstring("user"), cstring("postgres"), []byte{0}))))
+	assert.True(t, isPostgresMessage(postgresStartupMessage(postgresSSLRequestCode, nil)))
+	assert.True(t, isPostgresMessage(postgresQuery("SELECT 1")))
+	assert.True(t, isPostgresMessage(postgresExtendedQuery("SELECT $1")))
+	// the query is truncated
+	assert.True(t, isPostgresMessage(postgresQuery("SELECT 1")[:8]))
+
+	assert.False(t, isPostgresMessage([]byte("GET / HTTP/1.1\r\nHost: localhost\r\n\r\n")))
+	assert.False(t, isPostgresMessage(postgresStartupMessage(1234, nil)))
+	assert.False(t, isPostgresMessage(postgresMessage('Q', []byte("SELECT 1"))))
+	assert.False(t, isPostgresMessage(postgresSync()))
+}
+
+func newTestPostgresParser() (*parser, *[]postgresTransaction) {
+	var txs []postgresTransaction
+	p := newPostgresParser(func(tx postgresTransaction) {
+		txs = append(txs, tx)
+	})
+	return p, &txs
+}
+
+func requestKeys(txs []postgresTransaction) []PostgresRequestKey {
+	keys := make([]PostgresRequestKey, 0, len(txs))
+	for _, tx := range txs {
+		keys = append(keys, tx.Request)
+	}
+	return keys
+}
+
+func TestPostgresParserSimpleQuery(t *testing.T) {
+	p, txs := newTestPostgresParser()
+	conn := newTestConn(5432)
+	now := time.Now()
+
+	conn.send(p, true, postgresStartupMessage(postgresProtocolVersion3, concat(cstring("user"), cstring("postgres"), []byte{0})), now)
+	conn.send(p, false, concat(postgresMessage('R', []byte{0, 0, 0, 0}), postgresReadyForQuery()), now)
+
+	conn.send(p, true, postgresQuery("SELECT * FROM users"), now)
+	conn.send(p, false, concat(postgresRows(3), postgresCommandComplete("SELECT 3"), postgresReadyForQuery()), now.Add(3*time.Millisecond))
+
+	// a query made of several commands, the last one failing
+	conn.send(p, true, postgresQuery("BEGIN; INSERT INTO users VALUES (1); SELECT * FROM missing"), now)
+	conn.send(p, false, concat(
+		postgresCommandComplete("BEGIN"),
+		postgresCommandComplete("INSERT 0 1"),
+		postgresErrorResponse(),
+		postgresReadyForQuery(),
+	), now.Add(time.Millisecond))
+
+	// an empty query
+	conn.send(p, true, postgresQuery(""), now)
+	conn.send(p, false, concat(postgresMessage('I'), postgresReadyForQuery()), now)
+
+	require.Len(t, *txs, 4)
+	assert.Equal(t, postgresTransaction{
+		Key:     conn.key,
+		Request: PostgresRequestKey{QueryType: PostgresSimpleQuery, Command: "SELECT"},
+		Latency: 3,
+	}, (*txs)[0])
+	assert.Equal(t, []PostgresRequestKey{
+		{QueryType: PostgresSimpleQuery, Command: "SELECT"},
+		{QueryType: PostgresSimpleQuery, Command: "BEGIN"},
+		{QueryType: PostgresSimpleQuery, Command: "INSERT"},
+		{QueryType: PostgresSimpleQuery, Command: PostgresErrorCommand},
+	}, requestKeys(*txs))
+	assert.Empty(t, p.conns[conn.key].conn.(*postgresConn).pending)
+}
+
+func TestPostgresParserExtendedQuery(t *testing.T) {
+	p, txs := newTestPostgresParser()
+	conn := newTestConn(5432)
+	now := time.Now()
+
+	// the connection is already established
+	conn.send(p, true, concat(
+		postgresExtendedQuery("SELECT * FROM users WHERE id = $1"),
+		postgresExtendedQuery("UPDATE users SET name = $1"),
+		postgresSync(),
+	), now)
+	conn.send(p, false, concat(
+		postgresMessage('1'),
+		postgresMessage('2'),
+		postgresRows(1),
+		postgresCommandComplete("SELECT 1"),
+		postgresMessage('1'),
+		postgresMessage('2'),
+		postgresCommandComplete("UPDATE 10"),
+		postgresReadyForQuery(),
+	), now.Add(2*time.Millisecond))
+
+	// the failure of a query skips the next ones of the batch
+	conn.send(p, true, concat(
+		postgresExtendedQuery("SELECT * FROM missing"),
+		postgresExtendedQuery("SELECT 1"),
+		postgresSync(),
+	), now)
+	conn.send(p, false, concat(postgresErrorResponse(), postgresReadyForQuery()), now)
+
+	conn.send(p, true, concat(postgresExtendedQuery("DELETE FROM users"), postgresSync()), now)
+	conn.send(p, false, concat(
+		postgresMessage('1'),
+		postgresMessage('2'),
+		postgresCommandComplete("DELETE 2"),
+		postgresReadyForQuery(),
+	), now)
+
+	assert.Equal(t, []PostgresRequestKey{
+		{QueryType: PostgresExtendedQuery, Command: "SELECT"},
+		{QueryType: PostgresExtendedQuery, Command: "UPDATE"},
+		{QueryType: PostgresExtendedQuery, Command: PostgresErrorCommand},
+		{QueryType: PostgresExtendedQuery, Command: "DELETE"},
+	}, requestKeys(*txs))
+	assert.Equal(t, 2.0, (*txs)[0].Latency)
+	assert.Empty(t, p.conns[conn.key].conn.(*postgresConn).pending)
+}
+
+func TestPostgresParserEncryption(t *testing.T) {
+	startup := postgresStartupMessage(postgresProtocolVersion3, concat(cstring("user"), cstring("postgres"), []byte{0}))
+
+	t.Run("refused", func(t *testing.T) {
+		p, txs := newTestPostgresParser()
+		conn := newTestConn(5432)
+		now := time.Now()
+
+		conn.send(p, true, postgresStartupMessage(postgresSSLRequestCode, nil), now)
+		conn.send(p, false, []byte{'N'}, now)
+		conn.send(p, true, startup, now)
+		conn.send(p, false, postgresReadyForQuery(), now)
+		conn.send(p, true, postgresQuery("SELECT 1"), now)
+		conn.send(p, false, concat(postgresRows(1), postgresCommandComplete("SELECT 1"), postgresReadyForQuery()), now)
+
+		assert.Len(t, *txs, 1)
+	})
+
+	t.Run("accepted", func(t *testing.T) {
+		p, _ := newTestPostgresParser()
+		conn := newTestConn(5432)
+		now := time.Now()
+
+		conn.send(p, true, postgresStartupMessage(postgresSSLRequestCode, nil), now)
+		conn.send(p, false, []byte{'S'}, now)
+		assert.Empty(t, p.conns)
+		assert.Equal(t, int64(1), p.GetStats()["encrypted_connections"])
+
+		// the TLS handshake isn't classified as PostgreSQL
+		conn.send(p, true, []byte{0x16, 0x03, 0x01, 0x02, 0x00, 0x01, 0x00, 0x01, 0xfc, 0x03, 0x03}, now)
+		assert.Empty(t, p.conns)
+	})
+}
+
+func TestPostgresParserExpiration(t *testing.T) {
+	p, txs := newTestPostgresParser()
+	conn := newTestConn(5432)
+	now := time.Now()
+
+	conn.send(p, true, concat(postgresQuery("SELECT pg_sleep(600)"), postgresQuery("SELECT 1")), now)
+	// keeps the connection active
+	conn.send(p, true, postgresMessage('P', cstring(""), cstring("SELECT 2"), []byte{0, 0}), now.Add(requestTimeout))
+
+	later := now.Add(requestTimeout + expirationPeriod)
+	conn.send(p, false, concat(postgresCommandComplete("SELECT 1"), postgresReadyForQuery()), later)
+
+	assert.Empty(t, *txs)
+	assert.Equal(t, int64(2), p.GetStats()["expired_requests"])
+}
+
+func TestPostgresParserLimits(t *testing.T) {
+	p, _ := newTestPostgresParser()
+	conn := newTestConn(5432)
+
+	var queries []byte
+	for i := 0; i <= maxPendingRequests; i++ {
+		queries = append(queries, postgresQuery("SELECT 1")...)
+	}
+	conn.send(p, true, queries, time.Now())
+
+	assert.Empty(t, p.conns)
+	assert.Equal(t, int64(maxPendingRequests), p.GetStats()["dropped_requests"])
+}
@@ -0,0 +1,60 @@
+package protocols
+
+import (
+	"sync"
+)
+
+// statKeeper aggregates the decoded transactions into per-connection request stats
+type statKeeper struct {
+	mux      sync.Mutex
+	kafka    map[Key]map[KafkaRequestKey]RequestStats
+	postgres map[Key]map[PostgresRequestKey]RequestStats
+}
+
+func newStatKeeper() *statKeeper {
+	return &statKeeper{
+		kafka:    make(map[Key]map[KafkaRequestKey]RequestStats),
+		postgres: make(map[Key]map[PostgresRequestKey]RequestStats),
+	}
+}
+
+func (s *statKeeper) ProcessKafka(tx kafkaTransaction) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	stats, ok := s.kafka[tx.Key]
+	if !ok {
+		stats = make(map[KafkaRequestKey]RequestStats)
+		s.kafka[tx.Key] = stats
+	}
+	requestStats := stats[tx.Request]
+	requestStats.AddRequest(tx.Latency)
+	stats[tx.Request] = requestStats
+}
+
+func (s *statKeeper) ProcessPostgres(tx postgresTransaction) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	stats, ok := s.postgres[tx.Key]
+	if !ok {
+		stats = make(map[PostgresRequestKey]RequestStats)
+		s.postgres[tx.Key] = stats
+	}
+	requestStats := stats[tx.Request]
+	requestStats.AddRequest(tx.Latency)
+	stats[tx.Request] = requestStats
+}
+
+func (s *statKeeper) GetAndResetAllStats() *Stats {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	stats := &Stats{
+		Kafka:    s.kafka,
+		Postgres: s.postgres,
+	}
+	s.kafka = make(map[Key]map[KafkaRequestKey]RequestStats)
+	s.postgres = make(map[Key]map[PostgresRequestKey]RequestStats)
+	return stats
+}
@@ -0,0 +1,54 @@
+package protocols
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatKeeper(t *testing.T) {
+	sk := newStatKeeper()
+	key := Key{
+		SourceIP:   util.AddressFromString("1.1.1.1"),
+		DestIP:     util.AddressFromString("2.2.2.2"),
+		SourcePort: 45678,
+		DestPort:   9092,
+	}
+	produce := KafkaRequestKey{APIKey: kafkaProduce, Topic: "orders"}
+	selectKey := PostgresRequestKey{QueryType: PostgresSimpleQuery, Command: "SELECT"}
+
+	for i := 0; i < 10; i++ {
+		sk.ProcessKafka(kafkaTransaction{Key: key, Request: produce, Latency: float64(i % 2)})
+	}
+	sk.ProcessKafka(kafkaTransaction{Key: key, Request: KafkaRequestKey{APIKey: 3}, Latency: 1})
+	sk.ProcessPostgres(postgresTransaction{Key: key, Request: selectKey, Latency: 10})
+
+	stats := sk.GetAndResetAllStats()
+	require.Len(t, stats.Kafka[key], 2)
+	produceStats := stats.Kafka[key][produce]
+	assert.Equal(t, 10, produceStats.Count())
+	assert.Equal(t, 10.0, produceStats.Latencies().GetCount())
+
+	selectStats := stats.Postgres[key][selectKey]
+	assert.Equal(t, 1, selectStats.Count())
+	p50, err := selectStats.Latencies().GetValueAtQuantile(0.5)
+	require.NoError(t, err)
+	assert.InDelta(t, 10, p50, 10*RelativeAccuracy)
+
+	assert.Empty(t, sk.GetAndResetAllStats().Kafka)
+}
+
+func TestRequestStatsCombineWith(t *testing.T) {
+	var a, b RequestStats
+	b.AddRequest(1)
+	b.AddRequest(2)
+
+	a.CombineWith(b)
+	a.CombineWith(b)
+	assert.Equal(t, 4, a.Count())
+	assert.Equal(t, 4.0, a.Latencies().GetCount())
+	// the sketch of b isn't shared
+	assert.Equal(t, 2.0, b.Latencies().GetCount())
+}
@@ -0,0 +1,78 @@
+package protocols
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/sketches-go/ddsketch"
+)
+
+// Key is an identifier for the requests exchanged over a connection, from the client to the server
+type Key struct {
+	SourceIP   util.Address
+	DestIP     util.Address
+	SourcePort uint16
+	DestPort   uint16
+}
+
+// RelativeAccuracy defines the acceptable error in quantile values calculated by DDSketch.
+// For example, if the actual value at p50 is 100, with a relative accuracy of 0.01 the value calculated
+// will be between 99 and 101
+const RelativeAccuracy = 0.01
+
+// RequestStats stores the number of requests of a kind and a sketch of their latencies
+type RequestStats struct {
+	// Note: every time we add a latency value to the DDSketch below, it's possible for the sketch to discard that value
+	// (ie if it is outside the range that is tracked by the sketch). For that reason, in order to keep an accurate count
+	// the number of requests processed, we have our own count field (rather than relying on DDSketch.GetCount())
+	count     int
+	latencies *ddsketch.DDSketch
+}
+
+// Count returns the number of requests
+func (r *RequestStats) Count() int {
+	return r.count
+}
+
+// Latencies returns a sketch of the latencies of the requests, in milliseconds
+func (r *RequestStats) Latencies() *ddsketch.DDSketch {
+	return r.latencies
+}
+
+// CombineWith merges the data in 2 RequestStats objects
+func (r *RequestStats) CombineWith(newStats RequestStats) {
+	r.count += newStats.count
+
+	if r.latencies == nil {
+		if newStats.latencies != nil {
+			r.latencies = newStats.latencies.Copy()
+		}
+	} else if newStats.latencies != nil {
+		if err := r.latencies.MergeWith(newStats.latencies); err != nil {
+			log.Debugf("Error merging request stats: %v", err)
+		}
+	}
+}
+
+// AddRequest adds a request of the given latency, in milliseconds, to the stats
+func (r *RequestStats) AddRequest(latency float64) {
+	r.count++
+
+	if r.latencies == nil {
+		var err error
+		r.latencies, err = ddsketch.NewDefaultDDSketch(RelativeAccuracy)
+		if err != nil {
+			log.Debugf("Error recording request latency: could not create new ddsketch: %v", err)
+			return
+		}
+	}
+
+	if err := r.latencies.Add(latency); err != nil {
+		log.Debugf("Error recording request latency: could not add latency to ddsketch: %v", err)
+	}
+}
+
+// Stats holds the request stats of the decoded protocols, per connection
+type Stats struct {
+	Kafka    map[Key]map[KafkaRequestKey]RequestStats
+	Postgres map[Key]map[PostgresRequestKey]RequestStats
+}
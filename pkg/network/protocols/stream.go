@@ -0,0 +1,168 @@
+package protocols
+
+import (
+	"errors"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+var (
+	errDesync          = errors.New("lost bytes that are required to decode the messages")
+	errInvalidLength   = errors.New("invalid message length")
+	errInvalidMessage  = errors.New("invalid message")
+	errEncryptedStream = errors.New("the connection is encrypted")
+)
+
+// Segment is the part of a TCP segment needed to reassemble the messages of a connection
+type Segment struct {
+	Source     util.Address
+	Dest       util.Address
+	SourcePort uint16
+	DestPort   uint16
+	Seq        uint32
+	// Payload is the captured payload, which may be truncated
+	Payload []byte
+	// Len is the length of the payload on the wire
+	Len int
+	// Closing is set for the segments with the FIN or RST flag
+	Closing bool
+}
+
+// messageFramer splits the bytes sent in one direction of a connection into messages
+type messageFramer interface {
+	// headerLen returns the number of bytes needed to compute the length of the next message
+	headerLen() int
+	// messageLen returns the total length of the message starting with the given header
+	messageLen(header []byte) (int, error)
+}
+
+// messageStream reassembles the messages sent in one direction of a TCP connection. Only the first bytes of each
+// message are buffered and the rest is skipped, so that the lost and truncated segments are tolerated as long as they
+// don't overlap with these prefixes.
+type messageStream struct {
+	framer    messageFramer
+	prefixLen int
+
+	initialized bool
+	nextSeq     uint32
+
+	// buf holds the prefix of the current message
+	buf []byte
+	// msgLen is the length of the current message, or 0 until its header is complete
+	msgLen int
+	// remaining is the number of bytes of the current message not received yet
+	remaining int
+}
+
+func newMessageStream(framer messageFramer, prefixLen int) *messageStream {
+	return &messageStream{
+		framer:    framer,
+		prefixLen: prefixLen,
+	}
+}
+
+// feed processes the payload of a segment of sequence number seq and of length n on the wire, calling visit with the
+// prefix and the length of each complete message
+func (s *messageStream) feed(seq uint32, payload []byte, n int, visit func(prefix []byte, length int) error) error {
+	if !s.initialized {
+		s.initialized = true
+		s.nextSeq = seq
+	}
+
+	end := seq + uint32(n)
+	if offset := int32(seq - s.nextSeq); offset < 0 {
+		// retransmission of bytes already processed
+		overlap := int(-offset)
+		if overlap >= n {
+			return nil
+		}
+		if overlap < len(payload) {
+			payload = payload[overlap:]
+		} else {
+			payload = nil
+		}
+		n -= overlap
+	} else if offset > 0 {
+		if err := s.discard(int(offset), visit); err != nil {
+			return err
+		}
+	}
+	s.nextSeq = end
+
+	if len(payload) > n {
+		payload = payload[:n]
+	}
+	if err := s.consume(payload, visit); err != nil {
+		return err
+	}
+	return s.discard(n-len(payload), visit)
+}
+
+// discard accounts for bytes of the stream that weren't captured
+func (s *messageStream) discard(n int, visit func([]byte, int) error) error {
+	if n == 0 {
+		return nil
+	}
+	if s.msgLen == 0 || len(s.buf) < min(s.prefixLen, s.msgLen) || s.remaining < n {
+		return errDesync
+	}
+	s.remaining -= n
+	if s.remaining == 0 {
+		return s.complete(visit)
+	}
+	return nil
+}
+
+func (s *messageStream) consume(data []byte, visit func([]byte, int) error) error {
+	for len(data) > 0 {
+		if s.msgLen == 0 {
+			n := min(s.framer.headerLen()-len(s.buf), len(data))
+			s.buf = append(s.buf, data[:n]...)
+			data = data[n:]
+			if len(s.buf) < s.framer.headerLen() {
+				return nil
+			}
+
+			length, err := s.framer.messageLen(s.buf)
+			if err != nil {
+				return err
+			}
+			if length < len(s.buf) {
+				return errInvalidLength
+			}
+			s.msgLen = length
+			s.remaining = length - len(s.buf)
+		} else {
+			var n int
+			if prefixLen := min(s.prefixLen, s.msgLen); len(s.buf) < prefixLen {
+				n = min(prefixLen-len(s.buf), len(data))
+				s.buf = append(s.buf, data[:n]...)
+			} else {
+				n = min(s.remaining, len(data))
+			}
+			s.remaining -= n
+			data = data[n:]
+		}
+
+		if s.remaining == 0 {
+			if err := s.complete(visit); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *messageStream) complete(visit func([]byte, int) error) error {
+	err := visit(s.buf, s.msgLen)
+	s.buf = s.buf[:0]
+	s.msgLen, s.remaining = 0, 0
+	return err
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,128 @@
+package protocols
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testFramer frames messages starting with their total length on 2 bytes
+type testFramer struct{}
+
+func (testFramer) headerLen() int { return 2 }
+
+func (testFramer) messageLen(header []byte) (int, error) {
+	return int(binary.BigEndian.Uint16(header)), nil
+}
+
+func testMessage(length int, fill byte) []byte {
+	msg := make([]byte, length)
+	binary.BigEndian.PutUint16(msg, uint16(length))
+	for i := 2; i < length; i++ {
+		msg[i] = fill
+	}
+	return msg
+}
+
+type visitedMessage struct {
+	prefix []byte
+	length int
+}
+
+func feedStream(t *testing.T, s *messageStream, seq uint32, payload []byte, n int) ([]visitedMessage, error) {
+	t.Helper()
+	var msgs []visitedMessage
+	err := s.feed(seq, payload, n, func(prefix []byte, length int) error {
+		msgs = append(msgs, visitedMessage{prefix: append([]byte(nil), prefix...), length: length})
+		return nil
+	})
+	return msgs, err
+}
+
+func TestMessageStreamReassembly(t *testing.T) {
+	var data []byte
+	data = append(data, testMessage(10, 'a')...)
+	data = append(data, testMessage(3, 'b')...)
+	data = append(data, testMessage(20, 'c')...)
+
+	for _, size := range []int{1, 2, 3, 7, 100} {
+		s := newMessageStream(testFramer{}, 6)
+		var msgs []visitedMessage
+		for i := 0; i < len(data); i += size {
+			chunk := data[i:min(i+size, len(data))]
+			visited, err := feedStream(t, s, uint32(1000+i), chunk, len(chunk))
+			require.NoError(t, err)
+			msgs = append(msgs, visited...)
+		}
+
+		require.Len(t, msgs, 3, "segment size %d", size)
+		assert.Equal(t, visitedMessage{prefix: data[:6], length: 10}, msgs[0])
+		assert.Equal(t, visitedMessage{prefix: data[10:13], length: 3}, msgs[1])
+		assert.Equal(t, visitedMessage{prefix: data[13:19], length: 20}, msgs[2])
+	}
+}
+
+func TestMessageStreamTruncatedPayload(t *testing.T) {
+	s := newMessageStream(testFramer{}, 6)
+	msg := testMessage(100, 'a')
+
+	// only the prefix of the message was captured
+	msgs, err := feedStream(t, s, 0, msg[:8], len(msg))
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, 100, msgs[0].length)
+
+	msgs, err = feedStream(t, s, 100, testMessage(4, 'b'), 4)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, 4, msgs[0].length)
+}
+
+func TestMessageStreamSequence(t *testing.T) {
+	msg := testMessage(30, 'a')
+
+	t.Run("retransmission", func(t *testing.T) {
+		s := newMessageStream(testFramer{}, 6)
+		_, err := feedStream(t, s, 0, msg[:10], 10)
+		require.NoError(t, err)
+		_, err = feedStream(t, s, 0, msg[:10], 10)
+		require.NoError(t, err)
+
+		// partial overlap with the bytes already processed
+		msgs, err := feedStream(t, s, 5, msg[5:], 25)
+		require.NoError(t, err)
+		require.Len(t, msgs, 1)
+		assert.Equal(t, msg[:6], msgs[0].prefix)
+	})
+
+	t.Run("lost data", func(t *testing.T) {
+		s := newMessageStream(testFramer{}, 6)
+		_, err := feedStream(t, s, 0, msg[:10], 10)
+		require.NoError(t, err)
+
+		msgs, err := feedStream(t, s, 20, msg[20:], 10)
+		require.NoError(t, err)
+		require.Len(t, msgs, 1)
+		assert.Equal(t, 30, msgs[0].length)
+	})
+
+	t.Run("lost prefix", func(t *testing.T) {
+		s := newMessageStream(testFramer{}, 6)
+		_, err := feedStream(t, s, 0, msg[:4], 4)
+		require.NoError(t, err)
+
+		_, err = feedStream(t, s, 8, msg[8:], 22)
+		assert.Equal(t, errDesync, err)
+	})
+
+	t.Run("lost header", func(t *testing.T) {
+		s := newMessageStream(testFramer{}, 6)
+		_, err := feedStream(t, s, 0, msg, 30)
+		require.NoError(t, err)
+
+		_, err = feedStream(t, s, 40, msg, 30)
+		assert.Equal(t, errDesync, err)
+	})
+}
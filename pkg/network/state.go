@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -39,6 +40,10 @@ type State interface {
 		http map[http.Key]map[string]http.RequestStats,
 	) []ConnectionStats
 
+	// StoreProtocolStats stores the latest Kafka and PostgreSQL request stats, which are attached to the connections
+	// returned by the next call to Connections of each client
+	StoreProtocolStats(stats *protocols.Stats)
+
 	// StoreClosedConnection stores a new closed connection
 	StoreClosedConnection(conn *ConnectionStats)
 
@@ -66,6 +71,8 @@ type telemetry struct {
 	timeSyncCollisions int64
 	dnsStatsDropped    int64
 	httpStatsDropped   int64
+	kafkaStatsDropped  int64
+	pgStatsDropped     int64
 	dnsPidCollisions   int64
 }
 
@@ -75,6 +82,7 @@ type stats struct {
 	totalRetransmits    uint32
 	totalTCPEstablished uint32
 	totalTCPClosed      uint32
+	totalTCPCounters    TCPCounters
 }
 
 type client struct {
@@ -84,6 +92,8 @@ type client struct {
 	stats             map[string]*stats
 	dnsStats          map[DNSKey]map[string]DNSStats
 	httpStatsDelta    map[http.Key]map[string]http.RequestStats
+	kafkaStatsDelta   map[protocols.Key]map[protocols.KafkaRequestKey]protocols.RequestStats
+	pgStatsDelta      map[protocols.Key]map[protocols.PostgresRequestKey]protocols.RequestStats
 }
 
 type networkState struct {
@@ -162,6 +172,7 @@ func (ns *networkState) Connections(
 			c.LastRetransmits = 0
 			c.LastTCPEstablished = 0
 			c.LastTCPClosed = 0
+			c.LastTCPCounters = TCPCounters{}
 		}
 
 		ns.determineConnectionIntraHost(latestConns)
@@ -173,6 +184,7 @@ func (ns *networkState) Connections(
 			ns.storeHTTPStats(httpStats)
 		}
 		ns.addHTTPStats(id, latestConns)
+		ns.addProtocolStats(id, latestConns)
 
 		// copy to ensure return value doesn't get clobbered
 		conns := make([]ConnectionStats, len(latestConns))
@@ -205,6 +217,7 @@ func (ns *networkState) Connections(
 		ns.storeHTTPStats(httpStats)
 	}
 	ns.addHTTPStats(id, conns)
+	ns.addProtocolStats(id, conns)
 
 	return conns
 }
@@ -288,6 +301,33 @@ func (ns *networkState) addHTTPStats(id string, conns []ConnectionStats) {
 	ns.clients[id].httpStatsDelta = make(map[http.Key]map[string]http.RequestStats)
 }
 
+// addProtocolStats fills in the Kafka and PostgreSQL stats for each connection
+func (ns *networkState) addProtocolStats(id string, conns []ConnectionStats) {
+	client := ns.clients[id]
+	if len(client.kafkaStatsDelta) > 0 || len(client.pgStatsDelta) > 0 {
+		for i := range conns {
+			conn := &conns[i]
+			key := protocols.Key{
+				SourceIP:   conn.Source,
+				DestIP:     conn.Dest,
+				SourcePort: conn.SPort,
+				DestPort:   conn.DPort,
+			}
+
+			if stats, ok := client.kafkaStatsDelta[key]; ok {
+				conn.KafkaStats = stats
+			}
+			if stats, ok := client.pgStatsDelta[key]; ok {
+				conn.PostgresStats = stats
+			}
+		}
+	}
+
+	// flush the protocol stats from client state
+	client.kafkaStatsDelta = make(map[protocols.Key]map[protocols.KafkaRequestKey]protocols.RequestStats)
+	client.pgStatsDelta = make(map[protocols.Key]map[protocols.PostgresRequestKey]protocols.RequestStats)
+}
+
 // getConnsByKey returns a mapping of byte-key -> connection for easier access + manipulation
 func getConnsByKey(conns []ConnectionStats, buf []byte) map[string]*ConnectionStats {
 	connsByKey := make(map[string]*ConnectionStats, len(conns))
@@ -328,6 +368,8 @@ func (ns *networkState) StoreClosedConnection(conn *ConnectionStats) {
 			prev.MonotonicRetransmits += conn.MonotonicRetransmits
 			prev.MonotonicTCPEstablished += conn.MonotonicTCPEstablished
 			prev.MonotonicTCPClosed += conn.MonotonicTCPClosed
+			prev.MonotonicTCPCounters = prev.MonotonicTCPCounters.Add(conn.MonotonicTCPCounters)
+			prev.TCPFailure = conn.TCPFailure
 			// Also update the timestamp
 			prev.LastUpdateEpoch = conn.LastUpdateEpoch
 			client.closedConnections[string(key)] = prev
@@ -388,6 +430,72 @@ func (ns *networkState) storeHTTPStats(stats map[http.Key]map[string]http.Reques
 	}
 }
 
+// StoreProtocolStats stores latest Kafka and PostgreSQL stats for all clients
+func (ns *networkState) StoreProtocolStats(stats *protocols.Stats) {
+	if stats == nil {
+		return
+	}
+
+	ns.Lock()
+	defer ns.Unlock()
+
+	for key, statsByRequest := range stats.Kafka {
+		for _, client := range ns.clients {
+			if prevStats, ok := client.kafkaStatsDelta[key]; ok {
+				for request, newStats := range statsByRequest {
+					requestStats := prevStats[request]
+					requestStats.CombineWith(newStats)
+					prevStats[request] = requestStats
+				}
+			} else if len(client.kafkaStatsDelta) >= ns.maxHTTPStats {
+				ns.telemetry.kafkaStatsDropped++
+			} else {
+				client.kafkaStatsDelta[key] = copyKafkaStats(statsByRequest)
+			}
+		}
+	}
+
+	for key, statsByRequest := range stats.Postgres {
+		for _, client := range ns.clients {
+			if prevStats, ok := client.pgStatsDelta[key]; ok {
+				for request, newStats := range statsByRequest {
+					requestStats := prevStats[request]
+					requestStats.CombineWith(newStats)
+					prevStats[request] = requestStats
+				}
+			} else if len(client.pgStatsDelta) >= ns.maxHTTPStats {
+				ns.telemetry.pgStatsDropped++
+			} else {
+				client.pgStatsDelta[key] = copyPostgresStats(statsByRequest)
+			}
+		}
+	}
+}
+
+// copyKafkaStats returns a copy of the stats which doesn't share its latency sketches, since the stats of a
+// client get combined with the next ones
+func copyKafkaStats(stats map[protocols.KafkaRequestKey]protocols.RequestStats) map[protocols.KafkaRequestKey]protocols.RequestStats {
+	copied := make(map[protocols.KafkaRequestKey]protocols.RequestStats, len(stats))
+	for request, requestStats := range stats {
+		var c protocols.RequestStats
+		c.CombineWith(requestStats)
+		copied[request] = c
+	}
+	return copied
+}
+
+// copyPostgresStats returns a copy of the stats which doesn't share its latency sketches, since the stats of a
+// client get combined with the next ones
+func copyPostgresStats(stats map[protocols.PostgresRequestKey]protocols.RequestStats) map[protocols.PostgresRequestKey]protocols.RequestStats {
+	copied := make(map[protocols.PostgresRequestKey]protocols.RequestStats, len(stats))
+	for request, requestStats := range stats {
+		var c protocols.RequestStats
+		c.CombineWith(requestStats)
+		copied[request] = c
+	}
+	return copied
+}
+
 // combineHTTPStats combines 2 maps of http stats by adding new stats to the old stats map
 func combineHTTPStats(prevStatsByPath map[string]http.RequestStats, newStatsByPath map[string]http.RequestStats) map[string]http.RequestStats {
 	for path, newStats := range newStatsByPath {
@@ -413,6 +521,8 @@ func (ns *networkState) newClient(clientID string) (*client, bool) {
 		closedConnections: map[string]ConnectionStats{},
 		dnsStats:          map[DNSKey]map[string]DNSStats{},
 		httpStatsDelta:    map[http.Key]map[string]http.RequestStats{},
+		kafkaStatsDelta:   map[protocols.Key]map[protocols.KafkaRequestKey]protocols.RequestStats{},
+		pgStatsDelta:      map[protocols.Key]map[protocols.PostgresRequestKey]protocols.RequestStats{},
 	}
 	ns.clients[clientID] = c
 	return c, false
@@ -442,6 +552,7 @@ func (ns *networkState) mergeConnections(id string, active map[string]*Connectio
 				closedConn.MonotonicRetransmits += activeConn.MonotonicRetransmits
 				closedConn.MonotonicTCPEstablished += activeConn.MonotonicTCPEstablished
 				closedConn.MonotonicTCPClosed += activeConn.MonotonicTCPClosed
+				closedConn.MonotonicTCPCounters = closedConn.MonotonicTCPCounters.Add(activeConn.MonotonicTCPCounters)
 
 				ns.createStatsForKey(client, key)
 				ns.updateConnWithStatWithActiveConn(client, key, *activeConn, &closedConn)
@@ -452,6 +563,7 @@ func (ns *networkState) mergeConnections(id string, active map[string]*Connectio
 					stats.totalRetransmits = activeConn.MonotonicRetransmits
 					stats.totalSent = activeConn.MonotonicSentBytes
 					stats.totalRecv = activeConn.MonotonicRecvBytes
+					stats.totalTCPCounters = activeConn.MonotonicTCPCounters
 				}
 			} else {
 				// Else the closed connection and the active connection have the same epoch
@@ -497,6 +609,7 @@ func (ns *networkState) updateConnWithStatWithActiveConn(client *client, key str
 		closed.LastRetransmits = closed.MonotonicRetransmits - st.totalRetransmits
 		closed.LastTCPEstablished = closed.LastTCPEstablished - st.totalTCPEstablished
 		closed.LastTCPClosed = closed.LastTCPClosed - st.totalTCPClosed
+		closed.LastTCPCounters = closed.MonotonicTCPCounters.Sub(st.totalTCPCounters)
 
 		// Update stats object with latest values
 		st.totalSent = active.MonotonicSentBytes
@@ -504,12 +617,14 @@ func (ns *networkState) updateConnWithStatWithActiveConn(client *client, key str
 		st.totalRetransmits = active.MonotonicRetransmits
 		st.totalTCPEstablished = active.MonotonicTCPEstablished
 		st.totalTCPClosed = active.MonotonicTCPClosed
+		st.totalTCPCounters = active.MonotonicTCPCounters
 	} else {
 		closed.LastSentBytes = closed.MonotonicSentBytes
 		closed.LastRecvBytes = closed.MonotonicRecvBytes
 		closed.LastRetransmits = closed.MonotonicRetransmits
 		closed.LastTCPEstablished = closed.MonotonicTCPEstablished
 		closed.LastTCPClosed = closed.MonotonicTCPClosed
+		closed.LastTCPCounters = closed.MonotonicTCPCounters
 	}
 }
 
@@ -523,6 +638,7 @@ func (ns *networkState) updateConnWithStats(client *client, key string, c *Conne
 		c.LastRetransmits = c.MonotonicRetransmits - st.totalRetransmits
 		c.LastTCPEstablished = c.MonotonicTCPEstablished - st.totalTCPEstablished
 		c.LastTCPClosed = c.MonotonicTCPClosed - st.totalTCPClosed
+		c.LastTCPCounters = c.MonotonicTCPCounters.Sub(st.totalTCPCounters)
 
 		// Update stats object with latest values
 		st.totalSent = c.MonotonicSentBytes
@@ -530,23 +646,27 @@ func (ns *networkState) updateConnWithStats(client *client, key string, c *Conne
 		st.totalRetransmits = c.MonotonicRetransmits
 		st.totalTCPEstablished = c.MonotonicTCPEstablished
 		st.totalTCPClosed = c.MonotonicTCPClosed
+		st.totalTCPCounters = c.MonotonicTCPCounters
 	} else {
 		c.LastSentBytes = c.MonotonicSentBytes
 		c.LastRecvBytes = c.MonotonicRecvBytes
 		c.LastRetransmits = c.MonotonicRetransmits
 		c.LastTCPEstablished = c.MonotonicTCPEstablished
 		c.LastTCPClosed = c.MonotonicTCPClosed
+		c.LastTCPCounters = c.MonotonicTCPCounters
 	}
 }
 
 // handleStatsUnderflow checks if we are going to have an underflow when computing last stats and if it's the case it resets the stats to avoid it
 func (ns *networkState) handleStatsUnderflow(key string, st *stats, c *ConnectionStats) {
-	if c.MonotonicSentBytes < st.totalSent || c.MonotonicRecvBytes < st.totalRecv || c.MonotonicRetransmits < st.totalRetransmits {
+	if c.MonotonicSentBytes < st.totalSent || c.MonotonicRecvBytes < st.totalRecv || c.MonotonicRetransmits < st.totalRetransmits ||
+		c.MonotonicTCPCounters.Underflows(st.totalTCPCounters) {
 		ns.telemetry.statsResets++
 		log.Debugf("Stats reset triggered for key:%s, stats:%+v, connection:%+v", BeautifyKey(key), *st, *c)
 		st.totalSent = 0
 		st.totalRecv = 0
 		st.totalRetransmits = 0
+		st.totalTCPCounters = TCPCounters{}
 	}
 }
 
@@ -638,6 +758,8 @@ func (ns *networkState) GetStats() map[string]interface{} {
 			"time_sync_collisions": ns.telemetry.timeSyncCollisions,
 			"dns_stats_dropped":    ns.telemetry.dnsStatsDropped,
 			"http_stats_dropped":   ns.telemetry.httpStatsDropped,
+			"kafka_stats_dropped":  ns.telemetry.kafkaStatsDropped,
+			"pg_stats_dropped":     ns.telemetry.pgStatsDropped,
 			"dns_pid_collisions":   ns.telemetry.dnsPidCollisions,
 		},
 		"current_time":       time.Now().Unix(),
@@ -659,6 +781,11 @@ func (ns *networkState) DumpState(clientID string) map[string]interface{} {
 				"total_retransmits":     uint64(s.totalRetransmits),
 				"total_tcp_established": uint64(s.totalTCPEstablished),
 				"total_tcp_closed":      uint64(s.totalTCPClosed),
+				"total_rsts_sent":       uint64(s.totalTCPCounters.RstsSent),
+				"total_rsts_received":   uint64(s.totalTCPCounters.RstsReceived),
+				"total_zero_windows":    uint64(s.totalTCPCounters.ZeroWindows),
+				"total_out_of_order":    uint64(s.totalTCPCounters.OutOfOrder),
+				"total_syn_retries":     uint64(s.totalTCPCounters.SynRetries),
 			}
 		}
 	}
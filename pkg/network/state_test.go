@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 
 	"github.com/stretchr/testify/assert"
@@ -374,6 +375,72 @@ func TestLastStatsForClosedConnection(t *testing.T) {
 	assert.Equal(t, conn2.MonotonicRetransmits, conns[0].MonotonicRetransmits)
 }
 
+func TestLastTCPCounters(t *testing.T) {
+	clientID := "1"
+	state := newDefaultState()
+
+	conn := ConnectionStats{
+		Pid:                123,
+		Type:               TCP,
+		Family:             AFINET,
+		Source:             util.AddressFromString("127.0.0.1"),
+		Dest:               util.AddressFromString("127.0.0.1"),
+		SPort:              31890,
+		DPort:              80,
+		MonotonicSentBytes: 36,
+		MonotonicTCPCounters: TCPCounters{
+			RstsSent:   1,
+			OutOfOrder: 3,
+		},
+	}
+
+	conn2 := conn
+	conn2.LastUpdateEpoch++
+	conn2.MonotonicTCPCounters = conn.MonotonicTCPCounters.Add(TCPCounters{ZeroWindows: 2, OutOfOrder: 1})
+
+	// First get, we should not have any connections stored
+	conns := state.Connections(clientID, latestEpochTime(), nil, nil, nil)
+	assert.Equal(t, 0, len(conns))
+
+	conns = state.Connections(clientID, latestEpochTime(), []ConnectionStats{conn}, nil, nil)
+	require.Len(t, conns, 1)
+	assert.Equal(t, conn.MonotonicTCPCounters, conns[0].LastTCPCounters)
+
+	conns = state.Connections(clientID, latestEpochTime(), []ConnectionStats{conn2}, nil, nil)
+	require.Len(t, conns, 1)
+	assert.Equal(t, TCPCounters{ZeroWindows: 2, OutOfOrder: 1}, conns[0].LastTCPCounters)
+	assert.Equal(t, conn2.MonotonicTCPCounters, conns[0].MonotonicTCPCounters)
+
+	// A failed connection attempt is returned as a closed connection with its failure reason
+	failed := ConnectionStats{
+		Pid:                  123,
+		Type:                 TCP,
+		Family:               AFINET,
+		Source:               util.AddressFromString("127.0.0.1"),
+		Dest:                 util.AddressFromString("127.0.0.1"),
+		SPort:                31891,
+		DPort:                81,
+		Direction:            OUTGOING,
+		MonotonicRetransmits: 2,
+		MonotonicTCPClosed:   1,
+		MonotonicTCPCounters: TCPCounters{SynRetries: 2},
+		TCPFailure:           TCPFailureTimeout,
+	}
+	state.StoreClosedConnection(&failed)
+
+	conns = state.Connections(clientID, latestEpochTime(), []ConnectionStats{conn2}, nil, nil)
+	require.Len(t, conns, 2)
+	for _, c := range conns {
+		if c.DPort != failed.DPort {
+			assert.Equal(t, TCPFailureNone, c.TCPFailure)
+			assert.Equal(t, TCPCounters{}, c.LastTCPCounters)
+			continue
+		}
+		assert.Equal(t, TCPFailureTimeout, c.TCPFailure)
+		assert.Equal(t, TCPCounters{SynRetries: 2}, c.LastTCPCounters)
+	}
+}
+
 func TestRaceConditions(t *testing.T) {
 	nClients := 10
 
@@ -1389,6 +1456,57 @@ func TestHTTPStatsWithMultipleClients(t *testing.T) {
 	assert.Len(t, conns[0].HTTPStatsByPath, 2)
 }
 
+func TestProtocolStats(t *testing.T) {
+	c := ConnectionStats{
+		Source: util.AddressFromString("1.1.1.1"),
+		Dest:   util.AddressFromString("2.2.2.2"),
+		SPort:  1000,
+		DPort:  5432,
+	}
+	key := protocols.Key{
+		SourceIP:   c.Source,
+		DestIP:     c.Dest,
+		SourcePort: c.SPort,
+		DestPort:   c.DPort,
+	}
+	selectKey := protocols.PostgresRequestKey{QueryType: protocols.PostgresSimpleQuery, Command: "SELECT"}
+
+	getStats := func() *protocols.Stats {
+		var rs protocols.RequestStats
+		rs.AddRequest(2)
+		return &protocols.Stats{
+			Postgres: map[protocols.Key]map[protocols.PostgresRequestKey]protocols.RequestStats{
+				key: {selectKey: rs},
+			},
+		}
+	}
+
+	client1 := "client1"
+	client2 := "client2"
+	state := newDefaultState()
+	assert.Len(t, state.Connections(client1, latestEpochTime(), nil, nil, nil), 0)
+	assert.Len(t, state.Connections(client2, latestEpochTime(), nil, nil, nil), 0)
+
+	state.StoreProtocolStats(getStats())
+	conns := state.Connections(client1, latestEpochTime(), []ConnectionStats{c}, nil, nil)
+	require.Len(t, conns, 1)
+	require.Contains(t, conns[0].PostgresStats, selectKey)
+	selectStats := conns[0].PostgresStats[selectKey]
+	assert.Equal(t, 1, selectStats.Count())
+	assert.Empty(t, conns[0].KafkaStats)
+
+	// the stats are flushed for the first client only
+	conns = state.Connections(client1, latestEpochTime(), []ConnectionStats{c}, nil, nil)
+	assert.Empty(t, conns[0].PostgresStats)
+
+	// the second client accumulates the stats it didn't fetch yet
+	state.StoreProtocolStats(getStats())
+	conns = state.Connections(client2, latestEpochTime(), []ConnectionStats{c}, nil, nil)
+	selectStats = conns[0].PostgresStats[selectKey]
+	assert.Equal(t, 2, selectStats.Count())
+	assert.Equal(t, 2.0, selectStats.Latencies().GetCount())
+}
+
 func generateRandConnections(n int) []ConnectionStats {
 	cs := make([]ConnectionStats, 0, n)
 	for i := 0; i < n; i++ {
@@ -0,0 +1,273 @@
+package network
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	tlsRecordHeaderLen    = 5
+	tlsHandshakeHeaderLen = 4
+
+	tlsContentTypeHandshake = 22
+
+	tlsHandshakeClientHello = 1
+	tlsHandshakeServerHello = 2
+
+	tlsExtensionServerName        = 0
+	tlsExtensionSupportedVersions = 43
+
+	tlsServerNameTypeHostName = 0
+)
+
+var errNotTLSHandshake = errors.New("the packet does not contain a TLS ClientHello or ServerHello")
+
+// TLSMetadata holds the parameters of the TLS handshake of a connection
+type TLSMetadata struct {
+	// ServerName is the server name indication sent by the client
+	ServerName string
+	// Version is the protocol version negotiated by the server, or offered by the client until the server replied
+	Version uint16
+	// CipherSuite is the cipher suite selected by the server
+	CipherSuite uint16
+}
+
+// VersionString returns the name of the TLS version, e.g. TLS 1.2
+func (m *TLSMetadata) VersionString() string {
+	return TLSVersionName(m.Version)
+}
+
+// CipherSuiteString returns the name of the cipher suite, e.g. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256
+func (m *TLSMetadata) CipherSuiteString() string {
+	if m.CipherSuite == 0 {
+		return ""
+	}
+	return tls.CipherSuiteName(m.CipherSuite)
+}
+
+// merge completes the metadata with the parameters of another handshake message of the same connection. The version
+// selected by the server overrides the one offered by the client.
+func (m *TLSMetadata) merge(other *TLSMetadata) {
+	if other.ServerName != "" {
+		m.ServerName = other.ServerName
+	}
+	if other.CipherSuite != 0 {
+		m.Version = other.Version
+		m.CipherSuite = other.CipherSuite
+	} else if m.CipherSuite == 0 {
+		m.Version = other.Version
+	}
+}
+
+// TLSVersionName returns the name of a TLS protocol version
+func TLSVersionName(version uint16) string {
+	switch version {
+	case 0:
+		return ""
+	case tls.VersionSSL30: //nolint:staticcheck
+		return "SSL 3.0"
+	case tls.VersionTLS10:
+		return "TLS 1.0"
+	case tls.VersionTLS11:
+		return "TLS 1.1"
+	case tls.VersionTLS12:
+		return "TLS 1.2"
+	case tls.VersionTLS13:
+		return "TLS 1.3"
+	default:
+		return fmt.Sprintf("0x%04X", version)
+	}
+}
+
+// tlsHandshake is the outcome of the parsing of a ClientHello or a ServerHello
+type tlsHandshake struct {
+	clientHello bool
+	metadata    TLSMetadata
+}
+
+// parseTLSHandshake extracts the TLS metadata of the ClientHello or ServerHello at the start of a TCP payload. The
+// handshake message may be truncated when it doesn't fit in a single segment, in which case the parameters found
+// before the end of the payload are returned.
+func parseTLSHandshake(payload []byte) (*tlsHandshake, error) {
+	if len(payload) < tlsRecordHeaderLen+tlsHandshakeHeaderLen || payload[0] != tlsContentTypeHandshake {
+		return nil, errNotTLSHandshake
+	}
+
+	handshakeType := payload[tlsRecordHeaderLen]
+	// skip the handshake type and length
+	r := tlsReader(payload[tlsRecordHeaderLen+tlsHandshakeHeaderLen:])
+
+	switch handshakeType {
+	case tlsHandshakeClientHello:
+		h := &tlsHandshake{clientHello: true}
+		parseClientHello(r, &h.metadata)
+		return h, nil
+	case tlsHandshakeServerHello:
+		h := &tlsHandshake{}
+		parseServerHello(r, &h.metadata)
+		return h, nil
+	default:
+		return nil, errNotTLSHandshake
+	}
+}
+
+func parseClientHello(r tlsReader, m *TLSMetadata) {
+	var ok bool
+	if m.Version, ok = r.readUint16(); !ok {
+		return
+	}
+	// version, random, session ID, cipher suites and compression methods
+	if r, ok = r.skip(2 + 32); !ok {
+		return
+	}
+	if r, ok = r.skipVector(1); !ok {
+		return
+	}
+	if r, ok = r.skipVector(2); !ok {
+		return
+	}
+	if r, ok = r.skipVector(1); !ok {
+		return
+	}
+
+	parseExtensions(r, func(extType uint16, ext tlsReader) {
+		switch extType {
+		case tlsExtensionServerName:
+			if name, ok := parseServerName(ext); ok {
+				m.ServerName = name
+			}
+		case tlsExtensionSupportedVersions:
+			// the client lists the versions it supports, keep the highest one
+			versions, _ := ext.readVector(1)
+			for ; len(versions) >= 2; versions = versions[2:] {
+				if v := binary.BigEndian.Uint16(versions); !isGREASE(v) && v > m.Version {
+					m.Version = v
+				}
+			}
+		}
+	})
+}
+
+func parseServerHello(r tlsReader, m *TLSMetadata) {
+	var ok bool
+	if m.Version, ok = r.readUint16(); !ok {
+		return
+	}
+	// version, random and session ID
+	if r, ok = r.skip(2 + 32); !ok {
+		return
+	}
+	if r, ok = r.skipVector(1); !ok {
+		return
+	}
+	if m.CipherSuite, ok = r.readUint16(); !ok {
+		return
+	}
+	// cipher suite and compression method
+	if r, ok = r.skip(3); !ok {
+		return
+	}
+
+	parseExtensions(r, func(extType uint16, ext tlsReader) {
+		// TLS 1.3 servers keep the legacy version to TLS 1.2 and select the version in an extension
+		if extType == tlsExtensionSupportedVersions {
+			if v, ok := ext.readUint16(); ok {
+				m.Version = v
+			}
+		}
+	})
+}
+
+func parseExtensions(r tlsReader, visit func(extType uint16, ext tlsReader)) {
+	extensionsLen, ok := r.readUint16()
+	if !ok {
+		return
+	}
+	r = r[2:]
+	if int(extensionsLen) < len(r) {
+		r = r[:extensionsLen]
+	}
+
+	for len(r) >= 4 {
+		extType := binary.BigEndian.Uint16(r)
+		ext, ok := r[2:].readVector(2)
+		if !ok {
+			return
+		}
+		visit(extType, ext)
+		r = r[4+len(ext):]
+	}
+}
+
+func parseServerName(r tlsReader) (string, bool) {
+	names, ok := r.readVector(2)
+	for ok && len(names) > 0 {
+		nameType, _ := names.readUint8()
+		var name tlsReader
+		if name, ok = names[1:].readVector(2); !ok {
+			return "", false
+		}
+		if nameType == tlsServerNameTypeHostName {
+			return string(name), true
+		}
+		names = names[3+len(name):]
+	}
+	return "", false
+}
+
+// isGREASE returns whether the value is one of the reserved values used to exercise the extensibility of TLS
+// implementations, see RFC 8701
+func isGREASE(v uint16) bool {
+	return v&0x0f0f == 0x0a0a && v>>8 == v&0xff
+}
+
+// tlsReader reads the big-endian fields of TLS messages
+type tlsReader []byte
+
+func (r tlsReader) readUint8() (uint8, bool) {
+	if len(r) < 1 {
+		return 0, false
+	}
+	return r[0], true
+}
+
+func (r tlsReader) readUint16() (uint16, bool) {
+	if len(r) < 2 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint16(r), true
+}
+
+func (r tlsReader) skip(n int) (tlsReader, bool) {
+	if len(r) < n {
+		return nil, false
+	}
+	return r[n:], true
+}
+
+// readVector returns the content of a vector prefixed by its length on lenSize bytes
+func (r tlsReader) readVector(lenSize int) (tlsReader, bool) {
+	if len(r) < lenSize {
+		return nil, false
+	}
+	var n int
+	for _, b := range r[:lenSize] {
+		n = n<<8 | int(b)
+	}
+	if len(r) < lenSize+n {
+		return nil, false
+	}
+	return r[lenSize : lenSize+n], true
+}
+
+// skipVector skips a vector prefixed by its length on lenSize bytes
+func (r tlsReader) skipVector(lenSize int) (tlsReader, bool) {
+	v, ok := r.readVector(lenSize)
+	if !ok {
+		return nil, false
+	}
+	return r[lenSize+len(v):], true
+}
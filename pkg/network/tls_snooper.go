@@ -0,0 +1,323 @@
+package network
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+const (
+	tlsCacheTTL              = 10 * time.Minute
+	tlsCacheExpirationPeriod = 1 * time.Minute
+	tlsCacheSize             = 100000
+)
+
+// TLSInspector attaches the metadata of the TLS handshakes to the connections
+type TLSInspector interface {
+	Annotate(conns []ConnectionStats)
+	GetStats() map[string]int64
+	Close()
+}
+
+// NewNullTLSInspector returns a dummy implementation of TLSInspector
+func NewNullTLSInspector() TLSInspector {
+	return nullTLSInspector{}
+}
+
+type nullTLSInspector struct{}
+
+func (nullTLSInspector) Annotate(_ []ConnectionStats) {}
+
+func (nullTLSInspector) GetStats() map[string]int64 {
+	return map[string]int64{
+		"client_hellos":   0,
+		"server_hellos":   0,
+		"decoding_errors": 0,
+		"connections":     0,
+		"annotated":       0,
+		"expired":         0,
+		"dropped":         0,
+	}
+}
+
+func (nullTLSInspector) Close() {}
+
+var _ TLSInspector = nullTLSInspector{}
+
+// tlsKey identifies a TLS session by its client and server endpoints
+type tlsKey struct {
+	clientIP   util.Address
+	serverIP   util.Address
+	clientPort uint16
+	serverPort uint16
+}
+
+type tlsCacheEntry struct {
+	metadata TLSMetadata
+	lastSeen time.Time
+}
+
+var _ TLSInspector = &SocketFilterTLSSnooper{}
+
+// SocketFilterTLSSnooper is a TLS handshake snooper built on top of an eBPF SOCKET_FILTER
+type SocketFilterTLSSnooper struct {
+	// Telemetry is at the beginning of the struct to keep all fields 64-bit aligned.
+	// see https://staticcheck.io/docs/checks#SA1027
+	clientHellos   int64
+	serverHellos   int64
+	decodingErrors int64
+	annotated      int64
+	expired        int64
+	dropped        int64
+
+	source PacketSource
+	parser *tlsParser
+
+	mux     sync.Mutex
+	entries map[tlsKey]*tlsCacheEntry
+	maxSize int
+	ttl     time.Duration
+
+	exit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewSocketFilterTLSSnooper returns a new SocketFilterTLSSnooper
+func NewSocketFilterTLSSnooper(source PacketSource) *SocketFilterTLSSnooper {
+	snooper := &SocketFilterTLSSnooper{
+		source:  source,
+		parser:  newTLSParser(),
+		entries: make(map[tlsKey]*tlsCacheEntry),
+		maxSize: tlsCacheSize,
+		ttl:     tlsCacheTTL,
+		exit:    make(chan struct{}),
+	}
+
+	// Start consuming packets
+	snooper.wg.Add(1)
+	go func() {
+		snooper.pollPackets()
+		snooper.wg.Done()
+	}()
+
+	// Start expiring the sessions whose connections are gone
+	snooper.wg.Add(1)
+	go func() {
+		ticker := time.NewTicker(tlsCacheExpirationPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				snooper.expire(now)
+			case <-snooper.exit:
+				return
+			}
+		}
+	}()
+
+	log.Infof("TLS metadata collection has been enabled.")
+	return snooper
+}
+
+// Annotate attaches the TLS metadata of their handshake, if it was observed, to the TCP connections
+func (s *SocketFilterTLSSnooper) Annotate(conns []ConnectionStats) {
+	now := time.Now()
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	for i := range conns {
+		c := &conns[i]
+		if c.Type != TCP {
+			continue
+		}
+
+		// the connection can be either the client or the server side of the session
+		entry, ok := s.entries[tlsKey{clientIP: c.Source, serverIP: c.Dest, clientPort: c.SPort, serverPort: c.DPort}]
+		if !ok {
+			entry, ok = s.entries[tlsKey{clientIP: c.Dest, serverIP: c.Source, clientPort: c.DPort, serverPort: c.SPort}]
+		}
+		if !ok {
+			continue
+		}
+
+		entry.lastSeen = now
+		metadata := entry.metadata
+		c.TLS = &metadata
+		s.annotated++
+	}
+}
+
+// GetStats returns stats for use with telemetry
+func (s *SocketFilterTLSSnooper) GetStats() map[string]int64 {
+	stats := make(map[string]int64)
+	for key, value := range s.source.Stats() {
+		stats[key] = value
+	}
+
+	s.mux.Lock()
+	stats["connections"] = int64(len(s.entries))
+	stats["annotated"] = s.annotated
+	stats["expired"] = s.expired
+	s.mux.Unlock()
+
+	stats["client_hellos"] = atomic.LoadInt64(&s.clientHellos)
+	stats["server_hellos"] = atomic.LoadInt64(&s.serverHellos)
+	stats["decoding_errors"] = atomic.LoadInt64(&s.decodingErrors)
+	stats["dropped"] = atomic.LoadInt64(&s.dropped)
+	return stats
+}
+
+// Close terminates the TLS snooper as well as the underlying socket and the attached filter
+func (s *SocketFilterTLSSnooper) Close() {
+	close(s.exit)
+	s.wg.Wait()
+	s.source.Close()
+}
+
+// processPacket records the TLS metadata of the ClientHello or ServerHello held by the packet. The underlying packet
+// data can't be referenced after this method call since the underlying memory content gets invalidated by `afpacket`.
+func (s *SocketFilterTLSSnooper) processPacket(data []byte, ts time.Time) error {
+	key, handshake, err := s.parser.Parse(data)
+	if err != nil {
+		if err != errNotTLSHandshake {
+			atomic.AddInt64(&s.decodingErrors, 1)
+			log.Tracef("error decoding TLS handshake: %v", err)
+		}
+		return nil
+	}
+
+	if handshake.clientHello {
+		atomic.AddInt64(&s.clientHellos, 1)
+	} else {
+		atomic.AddInt64(&s.serverHellos, 1)
+	}
+
+	s.add(key, &handshake.metadata, ts)
+	return nil
+}
+
+func (s *SocketFilterTLSSnooper) add(key tlsKey, metadata *TLSMetadata, now time.Time) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		if len(s.entries) >= s.maxSize {
+			atomic.AddInt64(&s.dropped, 1)
+			return
+		}
+		entry = &tlsCacheEntry{}
+		s.entries[key] = entry
+	}
+
+	entry.metadata.merge(metadata)
+	entry.lastSeen = now
+}
+
+func (s *SocketFilterTLSSnooper) expire(now time.Time) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	for key, entry := range s.entries {
+		if now.Sub(entry.lastSeen) > s.ttl {
+			delete(s.entries, key)
+			s.expired++
+		}
+	}
+}
+
+func (s *SocketFilterTLSSnooper) pollPackets() {
+	for {
+		err := s.source.VisitPackets(s.exit, s.processPacket)
+
+		if err != nil {
+			log.Warnf("error reading packet: %s", err)
+		}
+
+		// Properly synchronizes termination process
+		select {
+		case <-s.exit:
+			return
+		default:
+		}
+
+		// Sleep briefly and try again
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+type tlsParser struct {
+	decoder     *gopacket.DecodingLayerParser
+	layers      []gopacket.LayerType
+	ipv4Payload *layers.IPv4
+	ipv6Payload *layers.IPv6
+	tcpPayload  *layers.TCP
+}
+
+func newTLSParser() *tlsParser {
+	ipv4Payload := &layers.IPv4{}
+	ipv6Payload := &layers.IPv6{}
+	tcpPayload := &layers.TCP{}
+
+	decoder := gopacket.NewDecodingLayerParser(
+		layers.LayerTypeEthernet,
+		&layers.Ethernet{},
+		ipv4Payload,
+		ipv6Payload,
+		tcpPayload,
+	)
+	// the TCP payload holding the TLS records is parsed separately
+	decoder.IgnoreUnsupported = true
+
+	return &tlsParser{
+		decoder:     decoder,
+		ipv4Payload: ipv4Payload,
+		ipv6Payload: ipv6Payload,
+		tcpPayload:  tcpPayload,
+	}
+}
+
+// Parse returns the client and server endpoints of a packet holding a TLS ClientHello or ServerHello, along with the
+// TLS metadata it carries
+func (p *tlsParser) Parse(data []byte) (tlsKey, *tlsHandshake, error) {
+	var key tlsKey
+
+	if err := p.decoder.DecodeLayers(data, &p.layers); err != nil {
+		return key, nil, err
+	}
+
+	if len(p.layers) == 0 || p.layers[len(p.layers)-1] != layers.LayerTypeTCP {
+		return key, nil, errNotTLSHandshake
+	}
+
+	handshake, err := parseTLSHandshake(p.tcpPayload.Payload)
+	if err != nil {
+		return key, nil, err
+	}
+
+	var srcIP, dstIP util.Address
+	switch p.layers[len(p.layers)-2] {
+	case layers.LayerTypeIPv4:
+		srcIP = util.AddressFromNetIP(p.ipv4Payload.SrcIP)
+		dstIP = util.AddressFromNetIP(p.ipv4Payload.DstIP)
+	case layers.LayerTypeIPv6:
+		srcIP = util.AddressFromNetIP(p.ipv6Payload.SrcIP)
+		dstIP = util.AddressFromNetIP(p.ipv6Payload.DstIP)
+	}
+	srcPort, dstPort := uint16(p.tcpPayload.SrcPort), uint16(p.tcpPayload.DstPort)
+
+	// the ClientHello is sent by the client, the ServerHello by the server
+	if handshake.clientHello {
+		key = tlsKey{clientIP: srcIP, serverIP: dstIP, clientPort: srcPort, serverPort: dstPort}
+	} else {
+		key = tlsKey{clientIP: dstIP, serverIP: srcIP, clientPort: dstPort, serverPort: srcPort}
+	}
+
+	return key, handshake, nil
+}
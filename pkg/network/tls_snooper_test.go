@@ -0,0 +1,81 @@
+package network
+
+import (
+	"crypto/tls"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTLSSnooper() *SocketFilterTLSSnooper {
+	return &SocketFilterTLSSnooper{
+		entries: make(map[tlsKey]*tlsCacheEntry),
+		maxSize: 2,
+		ttl:     time.Minute,
+	}
+}
+
+func TestTLSSnooperAnnotate(t *testing.T) {
+	s := newTestTLSSnooper()
+	now := time.Now()
+
+	client := util.AddressFromString("10.0.0.1")
+	server := util.AddressFromString("10.0.0.2")
+	key := tlsKey{clientIP: client, serverIP: server, clientPort: 43210, serverPort: 443}
+
+	s.add(key, &TLSMetadata{ServerName: "api.example.com", Version: tls.VersionTLS12}, now)
+	s.add(key, &TLSMetadata{Version: tls.VersionTLS11, CipherSuite: tls.TLS_RSA_WITH_AES_128_CBC_SHA}, now)
+
+	conns := []ConnectionStats{
+		// client side
+		{Source: client, Dest: server, SPort: 43210, DPort: 443, Type: TCP, Direction: OUTGOING},
+		// server side
+		{Source: server, Dest: client, SPort: 443, DPort: 43210, Type: TCP, Direction: INCOMING},
+		// other connection
+		{Source: client, Dest: server, SPort: 43211, DPort: 443, Type: TCP, Direction: OUTGOING},
+		{Source: client, Dest: server, SPort: 43210, DPort: 443, Type: UDP, Direction: OUTGOING},
+	}
+	s.Annotate(conns)
+
+	expected := &TLSMetadata{
+		ServerName:  "api.example.com",
+		Version:     tls.VersionTLS11,
+		CipherSuite: tls.TLS_RSA_WITH_AES_128_CBC_SHA,
+	}
+	assert.Equal(t, expected, conns[0].TLS)
+	assert.Equal(t, expected, conns[1].TLS)
+	assert.Nil(t, conns[2].TLS)
+	assert.Nil(t, conns[3].TLS)
+	assert.Equal(t, "TLS 1.1", conns[0].TLS.VersionString())
+	assert.Equal(t, "TLS_RSA_WITH_AES_128_CBC_SHA", conns[0].TLS.CipherSuiteString())
+	assert.Equal(t, int64(2), s.annotated)
+}
+
+func TestTLSSnooperExpiration(t *testing.T) {
+	s := newTestTLSSnooper()
+	now := time.Now()
+
+	key := func(port uint16) tlsKey {
+		return tlsKey{
+			clientIP:   util.AddressFromString("10.0.0.1"),
+			serverIP:   util.AddressFromString("10.0.0.2"),
+			clientPort: port,
+			serverPort: 443,
+		}
+	}
+
+	s.add(key(1), &TLSMetadata{ServerName: "a.example.com"}, now)
+	s.add(key(2), &TLSMetadata{ServerName: "b.example.com"}, now.Add(30*time.Second))
+
+	// the cache is full
+	s.add(key(3), &TLSMetadata{ServerName: "c.example.com"}, now)
+	assert.Len(t, s.entries, 2)
+	assert.Equal(t, int64(1), s.dropped)
+
+	s.expire(now.Add(80 * time.Second))
+	assert.Len(t, s.entries, 1)
+	assert.Contains(t, s.entries, key(2))
+	assert.Equal(t, int64(1), s.expired)
+}
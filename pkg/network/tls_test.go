@@ -0,0 +1,189 @@
+package network
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureTLSHandshake runs a TLS handshake between a client and a server and returns the first segment sent by each
+// of them, respectively holding the ClientHello and the ServerHello
+func captureTLSHandshake(t *testing.T, clientConfig *tls.Config, serverConfig *tls.Config) ([]byte, []byte) {
+	clientConn, clientProxy := net.Pipe()
+	serverProxy, serverConn := net.Pipe()
+
+	firstSegment := func(dst, src net.Conn, segment chan<- []byte) {
+		buf := make([]byte, 64*1024)
+		n, err := src.Read(buf)
+		if err != nil {
+			close(segment)
+			return
+		}
+		segment <- append([]byte(nil), buf[:n]...)
+		if _, err := dst.Write(buf[:n]); err == nil {
+			_, _ = io.Copy(dst, src)
+		}
+	}
+
+	clientSegment := make(chan []byte, 1)
+	serverSegment := make(chan []byte, 1)
+	go firstSegment(serverProxy, clientProxy, clientSegment)
+	go firstSegment(clientProxy, serverProxy, serverSegment)
+
+	go func() {
+		_ = tls.Server(serverConn, serverConfig).Handshake()
+	}()
+	go func() {
+		_ = tls.Client(clientConn, clientConfig).Handshake()
+	}()
+
+	defer func() {
+		for _, c := range []net.Conn{clientConn, clientProxy, serverProxy, serverConn} {
+			c.Close()
+		}
+	}()
+
+	var clientHello, serverHello []byte
+	select {
+	case clientHello = <-clientSegment:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no ClientHello sent")
+	}
+	select {
+	case serverHello = <-serverSegment:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no ServerHello sent")
+	}
+
+	return clientHello, serverHello
+}
+
+func testCertificate(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestParseTLSHandshake(t *testing.T) {
+	cert := testCertificate(t)
+
+	tests := []struct {
+		name          string
+		maxVersion    uint16
+		cipherSuites  []uint16
+		expectVersion uint16
+		expectCipher  uint16
+	}{
+		{
+			name:          "TLS 1.2",
+			maxVersion:    tls.VersionTLS12,
+			cipherSuites:  []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+			expectVersion: tls.VersionTLS12,
+			expectCipher:  tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+		},
+		{
+			name:          "TLS 1.3",
+			maxVersion:    tls.VersionTLS13,
+			expectVersion: tls.VersionTLS13,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clientHello, serverHello := captureTLSHandshake(t,
+				&tls.Config{
+					ServerName:         "api.example.com",
+					InsecureSkipVerify: true,
+					MaxVersion:         test.maxVersion,
+					CipherSuites:       test.cipherSuites,
+				},
+				&tls.Config{
+					Certificates: []tls.Certificate{cert},
+					MaxVersion:   test.maxVersion,
+					CipherSuites: test.cipherSuites,
+				},
+			)
+
+			h, err := parseTLSHandshake(clientHello)
+			require.NoError(t, err)
+			assert.True(t, h.clientHello)
+			assert.Equal(t, "api.example.com", h.metadata.ServerName)
+			assert.Equal(t, test.expectVersion, h.metadata.Version)
+			assert.Zero(t, h.metadata.CipherSuite)
+
+			metadata := h.metadata
+
+			h, err = parseTLSHandshake(serverHello)
+			require.NoError(t, err)
+			assert.False(t, h.clientHello)
+			assert.Empty(t, h.metadata.ServerName)
+			assert.Equal(t, test.expectVersion, h.metadata.Version)
+			assert.NotZero(t, h.metadata.CipherSuite)
+			if test.expectCipher != 0 {
+				assert.Equal(t, test.expectCipher, h.metadata.CipherSuite)
+			}
+
+			metadata.merge(&h.metadata)
+			assert.Equal(t, "api.example.com", metadata.ServerName)
+			assert.Equal(t, test.expectVersion, metadata.Version)
+			assert.Equal(t, h.metadata.CipherSuite, metadata.CipherSuite)
+		})
+	}
+}
+
+func TestParseTLSHandshakeTruncated(t *testing.T) {
+	clientHello, _ := captureTLSHandshake(t,
+		&tls.Config{ServerName: "api.example.com", InsecureSkipVerify: true, MaxVersion: tls.VersionTLS12},
+		&tls.Config{Certificates: []tls.Certificate{testCertificate(t)}},
+	)
+
+	// every prefix of the message is either rejected or parsed without panicking
+	for i := 0; i < len(clientHello); i++ {
+		h, err := parseTLSHandshake(clientHello[:i])
+		if i < tlsRecordHeaderLen+tlsHandshakeHeaderLen {
+			assert.Equal(t, errNotTLSHandshake, err)
+			continue
+		}
+		require.NoError(t, err)
+		if i >= tlsRecordHeaderLen+tlsHandshakeHeaderLen+2 {
+			assert.Equal(t, uint16(tls.VersionTLS12), h.metadata.Version)
+		}
+	}
+}
+
+func TestParseTLSHandshakeNotTLS(t *testing.T) {
+	_, err := parseTLSHandshake([]byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+	assert.Equal(t, errNotTLSHandshake, err)
+
+	// application data record
+	_, err = parseTLSHandshake([]byte{23, 3, 3, 0, 4, 1, 2, 3, 4})
+	assert.Equal(t, errNotTLSHandshake, err)
+}
+
+func TestTLSVersionName(t *testing.T) {
+	assert.Equal(t, "TLS 1.0", TLSVersionName(tls.VersionTLS10))
+	assert.Equal(t, "TLS 1.3", TLSVersionName(tls.VersionTLS13))
+	assert.Equal(t, "0x7F1C", TLSVersionName(0x7f1c))
+	assert.Equal(t, "", TLSVersionName(0))
+}
@@ -233,6 +233,12 @@ type ConnStatsWithTimestamp C.conn_stats_ts_t
 __u32 retransmits;
 __u32 rtt;
 __u32 rtt_var;
+__u32 rsts_sent;
+__u32 rsts_received;
+__u32 zero_windows;
+__u32 out_of_order;
+__u32 syn_retries;
+__u16 state_transitions;
 */
 type TCPStats C.tcp_stats_t
 
@@ -287,6 +293,19 @@ func connStats(t *ConnTuple, s *ConnStatsWithTimestamp, tcpStats *TCPStats) netw
 		stats.MonotonicTCPClosed = uint32(tcpStats.state_transitions >> C.TCP_CLOSE & 1)
 		stats.RTT = uint32(tcpStats.rtt)
 		stats.RTTVar = uint32(tcpStats.rtt_var)
+		stats.MonotonicTCPCounters = network.TCPCounters{
+			RstsSent:     uint32(tcpStats.rsts_sent),
+			RstsReceived: uint32(tcpStats.rsts_received),
+			ZeroWindows:  uint32(tcpStats.zero_windows),
+			OutOfOrder:   uint32(tcpStats.out_of_order),
+			SynRetries:   uint32(tcpStats.syn_retries),
+		}
+
+		// the SYN_SENT transition is only tracked when network_config.enable_tcp_failure_telemetry is set
+		synSent := tcpStats.state_transitions>>C.TCP_SYN_SENT&1 == 1
+		if synSent && stats.MonotonicTCPEstablished == 0 && stats.MonotonicTCPClosed == 1 {
+			stats.TCPFailure = network.ConnectFailureReason(stats.MonotonicTCPCounters)
+		}
 	}
 
 	return stats
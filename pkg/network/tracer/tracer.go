@@ -24,6 +24,7 @@ import (
 	filterpkg "github.com/DataDog/datadog-agent/pkg/network/filter"
 	"github.com/DataDog/datadog-agent/pkg/network/http"
 	"github.com/DataDog/datadog-agent/pkg/network/netlink"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
 	"github.com/DataDog/datadog-agent/pkg/process/procutil"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 	"github.com/DataDog/datadog-agent/pkg/util/kernel"
@@ -35,7 +36,7 @@ import (
 
 var (
 	expvarEndpoints map[string]*expvar.Map
-	expvarTypes     = []string{"conntrack", "state", "tracer", "ebpf", "kprobes", "dns", "http"}
+	expvarTypes     = []string{"conntrack", "state", "tracer", "ebpf", "kprobes", "dns", "http", "tls", "protocols"}
 )
 
 func init() {
@@ -55,8 +56,14 @@ type Tracer struct {
 	conntracker netlink.Conntracker
 
 	reverseDNS network.ReverseDNS
+	tlsSnooper network.TLSInspector
+	reducer    *network.ConnectionReducer
 
-	httpMonitor *http.Monitor
+	// dnsClients attributes the logged DNS queries to processes, if the DNS query log is enabled
+	dnsClients *network.DNSClientPIDs
+
+	httpMonitor     *http.Monitor
+	protocolMonitor *protocols.Monitor
 
 	perfMap       *manager.PerfMap
 	perfHandler   *ddebpf.PerfHandler
@@ -147,6 +154,17 @@ func NewTracer(config *config.Config) (*Tracer, error) {
 
 	if config.EnableHTTPMonitoring && !pre410Kernel {
 		enabledProbes[probes.SocketHTTPFilter] = struct{}{}
+		if config.EnableHTTP2Monitoring {
+			enabledProbes[probes.SocketHTTP2Filter] = struct{}{}
+		}
+	}
+
+	if config.CollectTLSMetadata && !pre410Kernel {
+		enabledProbes[probes.SocketTLSFilter] = struct{}{}
+	}
+
+	if (config.EnableKafkaMonitoring || config.EnablePostgresMonitoring) && !pre410Kernel {
+		enabledProbes[probes.SocketProtocolFilter] = struct{}{}
 	}
 
 	mgrOptions := manager.Options{
@@ -228,12 +246,17 @@ func NewTracer(config *config.Config) (*Tracer, error) {
 		return nil, fmt.Errorf("error enabling DNS traffic inspection: %s", err)
 	}
 
+	tlsSnooper, err := newTLSSnooper(config, m, pre410Kernel)
+	if err != nil {
+		return nil, fmt.Errorf("error enabling TLS metadata collection: %s", err)
+	}
+
 	err = initializePortBindingMaps(config, m)
 	if err != nil {
 		return nil, fmt.Errorf("error initializing port binding maps: %s", err)
 	}
 
-	conntracker, err := newConntracker(config, netlink.NewConntracker)
+	conntracker, err := newConntracker(config, netlink.NewConntracker, netlink.NewFallbackConntracker)
 	if err != nil {
 		return nil, err
 	}
@@ -248,20 +271,24 @@ func NewTracer(config *config.Config) (*Tracer, error) {
 	)
 
 	tr := &Tracer{
-		m:              m,
-		config:         config,
-		state:          state,
-		reverseDNS:     reverseDNS,
-		httpMonitor:    newHTTPMonitor(!pre410Kernel, config, m, perfHandlerHTTP),
-		buffer:         make([]network.ConnectionStats, 0, 512),
-		conntracker:    conntracker,
-		sourceExcludes: network.ParseConnectionFilters(config.ExcludedSourceConnections),
-		destExcludes:   network.ParseConnectionFilters(config.ExcludedDestinationConnections),
-		perfHandler:    perfHandlerTCP,
-		flushIdle:      make(chan chan struct{}),
-		stop:           make(chan struct{}),
-		buf:            make([]byte, network.ConnectionByteKeyMaxLen),
-		runtimeTracer:  runtimeTracer,
+		m:               m,
+		config:          config,
+		state:           state,
+		reverseDNS:      reverseDNS,
+		tlsSnooper:      tlsSnooper,
+		reducer:         network.NewConnectionReducer(config),
+		httpMonitor:     newHTTPMonitor(!pre410Kernel, config, m, perfHandlerHTTP),
+		protocolMonitor: newProtocolMonitor(!pre410Kernel, config, m),
+		buffer:          make([]network.ConnectionStats, 0, 512),
+		conntracker:     conntracker,
+		dnsClients:      newDNSClientPIDs(config),
+		sourceExcludes:  network.ParseConnectionFilters(config.ExcludedSourceConnections),
+		destExcludes:    network.ParseConnectionFilters(config.ExcludedDestinationConnections),
+		perfHandler:     perfHandlerTCP,
+		flushIdle:       make(chan chan struct{}),
+		stop:            make(chan struct{}),
+		buf:             make([]byte, network.ConnectionByteKeyMaxLen),
+		runtimeTracer:   runtimeTracer,
 	}
 
 	tr.perfMap, tr.batchManager, err = tr.initPerfPolling(perfHandlerTCP)
@@ -282,18 +309,32 @@ func NewTracer(config *config.Config) (*Tracer, error) {
 	return tr, nil
 }
 
-func newConntracker(cfg *config.Config, conntrackerCreator func(*config.Config) (netlink.Conntracker, error)) (netlink.Conntracker, error) {
+func newConntracker(cfg *config.Config, conntrackerCreator, fallbackCreator func(*config.Config) (netlink.Conntracker, error)) (netlink.Conntracker, error) {
 	conntracker := netlink.NewNoOpConntracker()
 	if !cfg.EnableConntrack {
 		return conntracker, nil
 	}
 
+	var fallback netlink.Conntracker
+	if cfg.EnableConntrackFallback {
+		var err error
+		if fallback, err = fallbackCreator(cfg); err != nil {
+			log.Warnf("could not initialize the conntrack fallback: %s", err)
+		}
+	}
+
 	if c, err := conntrackerCreator(cfg); err != nil {
+		if fallback != nil {
+			log.Warnf("could not initialize conntrack, tracer will resolve NAT from periodic loads of the conntrack table: %s", err)
+			return fallback, nil
+		}
 		if cfg.IgnoreConntrackInitFailure {
 			log.Warnf("could not initialize conntrack, tracer will continue without NAT tracking: %s", err)
 		} else {
 			return nil, fmt.Errorf("could not initialize conntrack: %s. set network_config.ignore_conntrack_init_failure to true to ignore conntrack failures on startup", err)
 		}
+	} else if fallback != nil {
+		conntracker = netlink.NewConntrackerWithFallback(c, fallback)
 	} else {
 		conntracker = c
 	}
@@ -373,6 +414,38 @@ func newReverseDNS(cfg *config.Config, m *manager.Manager, pre410Kernel bool) (n
 	return network.NewSocketFilterSnooper(cfg, packetSrc)
 }
 
+func newTLSSnooper(cfg *config.Config, m *manager.Manager, pre410Kernel bool) (network.TLSInspector, error) {
+	if !cfg.CollectTLSMetadata {
+		return network.NewNullTLSInspector(), nil
+	}
+
+	if pre410Kernel {
+		log.Warn("TLS metadata collection not supported by kernel versions < 4.1.0.")
+		return network.NewNullTLSInspector(), nil
+	}
+
+	filter, _ := m.GetProbe(manager.ProbeIdentificationPair{Section: string(probes.SocketTLSFilter)})
+	if filter == nil {
+		return nil, fmt.Errorf("error retrieving socket filter")
+	}
+
+	// Create the RAW_SOCKET inside the root network namespace
+	var (
+		packetSrc *filterpkg.AFPacketSource
+		srcErr    error
+	)
+	err := util.WithRootNS(cfg.ProcRoot, func() error {
+		packetSrc, srcErr = filterpkg.NewPacketSource(filter)
+		return srcErr
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return network.NewSocketFilterTLSSnooper(packetSrc), nil
+}
+
 func runOffsetGuessing(config *config.Config, buf bytecode.AssetReader) ([]manager.ConstantEditor, error) {
 	// Enable kernel probes used for offset guessing.
 	offsetMgr := netebpf.NewOffsetManager()
@@ -516,7 +589,7 @@ func (t *Tracer) initPerfPolling(perf *ddebpf.PerfHandler) (*manager.PerfMap, *P
 }
 
 // shouldSkipConnection returns whether or not the tracer should ignore a given connection:
-//  • Local DNS (*:53) requests if configured (default: true)
+//   - Local DNS (*:53) requests if configured (default: true)
 func (t *Tracer) shouldSkipConnection(conn *network.ConnectionStats) bool {
 	isDNSConnection := conn.DPort == 53 || conn.SPort == 53
 	if !t.config.CollectLocalDNS && isDNSConnection && conn.Dest.IsLoopback() {
@@ -534,6 +607,9 @@ func (t *Tracer) storeClosedConn(cs *network.ConnectionStats) {
 	}
 
 	atomic.AddInt64(&t.closedConns, 1)
+	if t.dnsClients != nil && cs.DPort == 53 {
+		t.dnsClients.Add(*cs)
+	}
 	cs.IPTranslation = t.conntracker.GetTranslationForConn(*cs)
 	t.state.StoreClosedConnection(cs)
 	if cs.IPTranslation != nil {
@@ -544,10 +620,12 @@ func (t *Tracer) storeClosedConn(cs *network.ConnectionStats) {
 func (t *Tracer) Stop() {
 	close(t.stop)
 	t.reverseDNS.Close()
+	t.tlsSnooper.Close()
 	_ = t.m.Stop(manager.CleanAll)
 	_ = t.perfMap.Stop(manager.CleanAll)
 	t.perfHandler.Stop()
 	t.httpMonitor.Stop()
+	t.protocolMonitor.Close()
 	close(t.flushIdle)
 	t.conntracker.Close()
 }
@@ -569,18 +647,52 @@ func (t *Tracer) GetActiveConnections(clientID string) (*network.Connections, er
 		t.buffer = make([]network.ConnectionStats, 0, cap(t.buffer)/2)
 	}
 
+	if t.dnsClients != nil {
+		t.dnsClients.Add(latestConns...)
+	}
+
 	// Ensure that TCP closed connections are flushed
 	done := make(chan struct{})
 	t.flushIdle <- done
 	<-done
 
+	t.state.StoreProtocolStats(t.protocolMonitor.GetProtocolStats())
 	conns := t.state.Connections(clientID, latestTime, latestConns, t.reverseDNS.GetDNSStats(), t.httpMonitor.GetHTTPStats())
+	t.tlsSnooper.Annotate(conns)
+
+	var rs network.ReductionStats
+	if t.reducer.Enabled() {
+		conns, rs = t.reducer.Reduce(conns)
+	}
+
 	names := t.reverseDNS.Resolve(conns)
 	tm := t.getConnTelemetry(len(latestConns))
+	tm.ConnsAggregated = rs.Aggregated
+	tm.ConnsSampledOut = rs.SampledOut
+	tm.ConnsOverflow = rs.Overflow
+	tm.ConnsOverflowSentBytes = rs.OverflowSentBytes
+	tm.ConnsOverflowRecvBytes = rs.OverflowRecvBytes
 
 	return &network.Connections{Conns: conns, DNS: names, Telemetry: tm}, nil
 }
 
+// GetDNSQueryLog returns the DNS queries logged since the previous call, along with the pid of the processes which
+// issued them when their connection to the DNS server was seen
+func (t *Tracer) GetDNSQueryLog() ([]network.DNSQueryLogEntry, error) {
+	entries := t.reverseDNS.GetDNSQueryLog()
+	if t.dnsClients != nil {
+		t.dnsClients.Annotate(entries)
+	}
+	return entries, nil
+}
+
+func newDNSClientPIDs(config *config.Config) *network.DNSClientPIDs {
+	if !config.DNSInspection || !config.CollectDNSStats || !config.EnableDNSQueryLog {
+		return nil
+	}
+	return network.NewDNSClientPIDs(network.MaxStateMapSize)
+}
+
 func (t *Tracer) getConnTelemetry(mapSize int) *network.ConnectionsTelemetry {
 	kprobeStats := ddebpf.GetProbeTotals()
 	tm := &network.ConnectionsTelemetry{
@@ -853,12 +965,17 @@ func (t *Tracer) GetStats() (map[string]interface{}, error) {
 		"ebpf":    t.getEbpfTelemetry(),
 		"kprobes": ddebpf.GetProbeStats(),
 		"dns":     t.reverseDNS.GetStats(),
+		"tls":     t.tlsSnooper.GetStats(),
 	}
 
 	if t.httpMonitor != nil {
 		ret["http"] = t.httpMonitor.GetStats()
 	}
 
+	if t.protocolMonitor != nil {
+		ret["protocols"] = t.protocolMonitor.GetStats()
+	}
+
 	return ret, nil
 }
 
@@ -934,7 +1051,12 @@ func newHTTPMonitor(supported bool, c *config.Config, m *manager.Manager, h *dde
 		return nil
 	}
 
-	monitor, err := http.NewMonitor(c.ProcRoot, m, h)
+	var http2Ports []uint16
+	if c.EnableHTTP2Monitoring {
+		http2Ports = c.HTTP2MonitoringPorts
+	}
+
+	monitor, err := http.NewMonitor(c.ProcRoot, http2Ports, m, h)
 	if err != nil {
 		log.Errorf("could not enable http monitoring: %s", err)
 		return nil
@@ -943,3 +1065,29 @@ func newHTTPMonitor(supported bool, c *config.Config, m *manager.Manager, h *dde
 	log.Info("http monitoring enabled")
 	return monitor
 }
+
+func newProtocolMonitor(supported bool, c *config.Config, m *manager.Manager) *protocols.Monitor {
+	if !c.EnableKafkaMonitoring && !c.EnablePostgresMonitoring {
+		return nil
+	}
+
+	if !supported {
+		log.Warnf("kafka and postgres monitoring are not supported by this kernel version. please refer to system-probe's documentation")
+		return nil
+	}
+
+	var kafkaPorts, postgresPorts []uint16
+	if c.EnableKafkaMonitoring {
+		kafkaPorts = c.KafkaMonitoringPorts
+	}
+	if c.EnablePostgresMonitoring {
+		postgresPorts = c.PostgresMonitoringPorts
+	}
+
+	monitor, err := protocols.NewMonitor(c.ProcRoot, m, kafkaPorts, postgresPorts)
+	if err != nil {
+		log.Errorf("could not enable kafka and postgres monitoring: %s", err)
+		return nil
+	}
+	return monitor
+}
@@ -1741,6 +1741,43 @@ func TestTCPEstablishedPreExistingConn(t *testing.T) {
 	assert.Equal(t, uint32(1), conn.MonotonicTCPClosed)
 }
 
+func TestTCPFailedConnect(t *testing.T) {
+	// Ensure closed connections are flushed as soon as possible
+	cfg := testConfig()
+	cfg.TCPClosedTimeout = 500 * time.Millisecond
+	cfg.EnableTCPFailureTelemetry = true
+
+	tr, err := NewTracer(cfg)
+	require.NoError(t, err)
+	defer tr.Stop()
+
+	// Warm-up state
+	getConnections(t, tr)
+
+	// Find a port nothing listens on
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := uint16(addrPort(l.Addr().String()))
+	l.Close()
+
+	_, err = net.DialTimeout("tcp", l.Addr().String(), 50*time.Millisecond)
+	require.Error(t, err)
+
+	// Wait for the connection to be sent from the perf buffer
+	time.Sleep(cfg.TCPClosedTimeout)
+
+	conns := searchConnections(getConnections(t, tr), func(c network.ConnectionStats) bool {
+		return c.DPort == port && c.Dest.IsLoopback()
+	})
+	require.Len(t, conns, 1)
+	conn := conns[0]
+	assert.Equal(t, network.TCPFailureRefused, conn.TCPFailure)
+	assert.Equal(t, network.OUTGOING, conn.Direction)
+	assert.Equal(t, uint32(1), conn.MonotonicTCPCounters.RstsReceived)
+	assert.Equal(t, uint32(0), conn.MonotonicTCPEstablished)
+	assert.Equal(t, uint32(1), conn.MonotonicTCPClosed)
+}
+
 func TestUnconnectedUDPSendIPv4(t *testing.T) {
 	cfg := testConfig()
 	tr, err := NewTracer(cfg)
@@ -2240,26 +2277,35 @@ func TestNewConntracker(t *testing.T) {
 
 	mockConntracker := netlink.NewMockConntracker(ctrl)
 	noopConntracker := netlink.NewNoOpConntracker()
+	withFallbackConntracker := netlink.NewConntrackerWithFallback(mockConntracker, mockConntracker)
 
 	tests := []struct {
 		conntrackEnabled  bool
 		ignoreInitFailure bool
+		fallbackEnabled   bool
 		creator           func(*config.Config) (netlink.Conntracker, error)
+		fallbackCreator   func(*config.Config) (netlink.Conntracker, error)
 
 		conntracker netlink.Conntracker
 		err         error
 	}{
-		{false, false, mockCreator, noopConntracker, nil},
-		{true, true, mockCreator, mockConntracker, nil},
-		{true, true, errCreator, noopConntracker, nil},
-		{true, false, mockCreator, mockConntracker, nil},
-		{true, false, errCreator, nil, assert.AnError},
+		{false, false, false, mockCreator, mockCreator, noopConntracker, nil},
+		{true, true, false, mockCreator, mockCreator, mockConntracker, nil},
+		{true, true, false, errCreator, mockCreator, noopConntracker, nil},
+		{true, false, false, mockCreator, mockCreator, mockConntracker, nil},
+		{true, false, false, errCreator, mockCreator, nil, assert.AnError},
+		{true, false, true, mockCreator, mockCreator, withFallbackConntracker, nil},
+		{true, false, true, errCreator, mockCreator, mockConntracker, nil},
+		{true, false, true, mockCreator, errCreator, mockConntracker, nil},
+		{true, true, true, errCreator, errCreator, noopConntracker, nil},
+		{true, false, true, errCreator, errCreator, nil, assert.AnError},
 	}
 
 	for _, te := range tests {
 		cfg.EnableConntrack = te.conntrackEnabled
 		cfg.IgnoreConntrackInitFailure = te.ignoreInitFailure
-		c, err := newConntracker(cfg, te.creator)
+		cfg.EnableConntrackFallback = te.fallbackEnabled
+		c, err := newConntracker(cfg, te.creator, te.fallbackCreator)
 		if te.conntracker != nil {
 			require.IsType(t, te.conntracker, c)
 		} else {
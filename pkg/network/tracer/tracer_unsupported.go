@@ -34,6 +34,11 @@ func (t *Tracer) DebugNetworkState(clientID string) (map[string]interface{}, err
 	return nil, ebpf.ErrNotImplemented
 }
 
+// GetDNSQueryLog is not implemented on this OS for Tracer
+func (t *Tracer) GetDNSQueryLog() ([]network.DNSQueryLogEntry, error) {
+	return nil, ebpf.ErrNotImplemented
+}
+
 // DebugNetworkMaps is not implemented on this OS for Tracer
 func (t *Tracer) DebugNetworkMaps() (*network.Connections, error) {
 	return nil, ebpf.ErrNotImplemented
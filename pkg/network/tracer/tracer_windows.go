@@ -43,6 +43,7 @@ type Tracer struct {
 	stopChan        chan struct{}
 	state           network.State
 	reverseDNS      network.ReverseDNS
+	reducer         *network.ConnectionReducer
 
 	connStatsActive *network.DriverBuffer
 	connStatsClosed *network.DriverBuffer
@@ -81,6 +82,7 @@ func NewTracer(config *config.Config) (*Tracer, error) {
 		timerInterval:   defaultPollInterval,
 		state:           state,
 		reverseDNS:      network.NewNullReverseDNS(),
+		reducer:         network.NewConnectionReducer(config),
 		connStatsActive: network.NewDriverBuffer(512),
 		connStatsClosed: network.NewDriverBuffer(512),
 	}
@@ -156,7 +158,24 @@ func (t *Tracer) GetActiveConnections(clientID string) (*network.Connections, er
 	// check for expired clients in the state
 	t.state.RemoveExpiredClients(time.Now())
 	conns := t.state.Connections(clientID, uint64(time.Now().Nanosecond()), activeConnStats, t.reverseDNS.GetDNSStats(), nil)
-	return &network.Connections{Conns: conns}, nil
+	if !t.reducer.Enabled() {
+		return &network.Connections{Conns: conns}, nil
+	}
+
+	conns, rs := t.reducer.Reduce(conns)
+	tm := &network.ConnectionsTelemetry{
+		ConnsAggregated:        rs.Aggregated,
+		ConnsSampledOut:        rs.SampledOut,
+		ConnsOverflow:          rs.Overflow,
+		ConnsOverflowSentBytes: rs.OverflowSentBytes,
+		ConnsOverflowRecvBytes: rs.OverflowRecvBytes,
+	}
+	return &network.Connections{Conns: conns, Telemetry: tm}, nil
+}
+
+// GetDNSQueryLog returns the DNS queries logged since the previous call
+func (t *Tracer) GetDNSQueryLog() ([]network.DNSQueryLogEntry, error) {
+	return t.reverseDNS.GetDNSQueryLog(), nil
 }
 
 // GetStats returns a map of statistics about the current tracer's internal state
@@ -72,6 +72,14 @@ type WindowsConfig struct {
 	DriverBufferSize int
 }
 
+// ConnectionSamplingRule keeps the given ratio of the connections matching its source and destination filters, which
+// have the same format as the connection excludes. A rule without filters matches all the connections.
+type ConnectionSamplingRule struct {
+	SampleRate  float64             `mapstructure:"sample_rate"`
+	Source      map[string][]string `mapstructure:"source"`
+	Destination map[string][]string `mapstructure:"destination"`
+}
+
 // AgentConfig is the global config for the process-agent. This information
 // is sourced from config files and the environment variables.
 type AgentConfig struct {
@@ -109,6 +117,14 @@ type AgentConfig struct {
 	DisableDNSInspection           bool
 	CollectLocalDNS                bool
 	EnableHTTPMonitoring           bool
+	EnableHTTP2Monitoring          bool
+	HTTP2MonitoringPorts           []uint16
+	EnableKafkaMonitoring          bool
+	KafkaMonitoringPorts           []uint16
+	EnablePostgresMonitoring       bool
+	PostgresMonitoringPorts        []uint16
+	CollectTLSMetadata             bool
+	EnableTCPFailureTelemetry      bool
 	SystemProbeAddress             string
 	SystemProbeLogFile             string
 	SystemProbeBPFDir              string
@@ -117,11 +133,17 @@ type AgentConfig struct {
 	ExcludedBPFLinuxVersions       []string
 	ExcludedSourceConnections      map[string][]string
 	ExcludedDestinationConnections map[string][]string
+	AggregateEphemeralConnections  bool
+	EphemeralPortRange             string
+	ConnectionSamplingRules        []ConnectionSamplingRule
+	MaxReturnedConnections         int
 	EnableConntrack                bool
 	ConntrackMaxStateSize          int
 	ConntrackRateLimit             int
 	IgnoreConntrackInitFailure     bool
 	EnableConntrackAllNamespaces   bool
+	EnableConntrackFallback        bool
+	ConntrackFallbackInterval      time.Duration
 	SystemProbeDebugPort           int
 	ClosedChannelSize              int
 	MaxClosedConnectionsBuffered   int
@@ -140,6 +162,10 @@ type AgentConfig struct {
 	DNSTimeout        time.Duration
 	CollectDNSDomains bool
 
+	// DNS query log configuration
+	EnableDNSQueryLog     bool
+	MaxDNSQueryLogEntries int
+
 	// Check config
 	EnabledChecks  []string
 	CheckIntervals map[string]time.Duration
@@ -234,6 +260,11 @@ func NewDefaultAgentConfig(canAccessContainers bool) *AgentConfig {
 		DisableIPv6Tracing:           false,
 		DisableDNSInspection:         false,
 		EnableHTTPMonitoring:         false,
+		EnableHTTP2Monitoring:        false,
+		EnableKafkaMonitoring:        false,
+		EnablePostgresMonitoring:     false,
+		CollectTLSMetadata:           false,
+		EnableTCPFailureTelemetry:    false,
 		SystemProbeAddress:           defaultSystemProbeAddress,
 		SystemProbeLogFile:           defaultSystemProbeLogFilePath,
 		SystemProbeBPFDir:            defaultSystemProbeBPFDir,
@@ -244,10 +275,12 @@ func NewDefaultAgentConfig(canAccessContainers bool) *AgentConfig {
 		ConntrackRateLimit:           500,
 		IgnoreConntrackInitFailure:   false,
 		EnableConntrackAllNamespaces: true,
+		ConntrackFallbackInterval:    30 * time.Second,
 		OffsetGuessThreshold:         400,
 		EnableTracepoints:            false,
 		CollectDNSStats:              true,
 		CollectDNSDomains:            false,
+		MaxDNSQueryLogEntries:        10000,
 		EnableRuntimeCompiler:        false,
 		RuntimeCompilerOutputDir:     defaultRuntimeCompilerOutputDir,
 
@@ -521,10 +554,18 @@ func loadSysProbeEnvVariables() {
 		{"DD_SYSTEM_PROBE_ENABLED", "system_probe_config.enabled"},
 		{"DD_SYSTEM_PROBE_NETWORK_ENABLED", "network_config.enabled"},
 		{"DD_SYSTEM_PROBE_NETWORK_ENABLE_HTTP_MONITORING", "network_config.enable_http_monitoring"},
+		{"DD_SYSTEM_PROBE_NETWORK_ENABLE_HTTP2_MONITORING", "network_config.enable_http2_monitoring"},
+		{"DD_SYSTEM_PROBE_NETWORK_ENABLE_KAFKA_MONITORING", "network_config.enable_kafka_monitoring"},
+		{"DD_SYSTEM_PROBE_NETWORK_ENABLE_POSTGRES_MONITORING", "network_config.enable_postgres_monitoring"},
+		{"DD_SYSTEM_PROBE_NETWORK_COLLECT_TLS_METADATA", "network_config.collect_tls_metadata"},
+		{"DD_SYSTEM_PROBE_NETWORK_ENABLE_TCP_FAILURE_TELEMETRY", "network_config.enable_tcp_failure_telemetry"},
+		{"DD_SYSTEM_PROBE_NETWORK_AGGREGATE_EPHEMERAL_CONNECTIONS", "network_config.aggregate_ephemeral_connections"},
+		{"DD_SYSTEM_PROBE_NETWORK_MAX_RETURNED_CONNECTIONS", "network_config.max_returned_connections"},
 		{"DD_SYSPROBE_SOCKET", "system_probe_config.sysprobe_socket"},
 		{"DD_SYSTEM_PROBE_CONNTRACK_IGNORE_ENOBUFS", "system_probe_config.conntrack_ignore_enobufs"},
 		{"DD_SYSTEM_PROBE_ENABLE_CONNTRACK_ALL_NAMESPACES", "system_probe_config.enable_conntrack_all_namespaces"},
 		{"DD_SYSTEM_PROBE_NETWORK_IGNORE_CONNTRACK_INIT_FAILURE", "network_config.ignore_conntrack_init_failure"},
+		{"DD_SYSTEM_PROBE_NETWORK_ENABLE_CONNTRACK_FALLBACK", "network_config.enable_conntrack_fallback"},
 		{"DD_DISABLE_TCP_TRACING", "system_probe_config.disable_tcp"},
 		{"DD_DISABLE_UDP_TRACING", "system_probe_config.disable_udp"},
 		{"DD_DISABLE_IPV6_TRACING", "system_probe_config.disable_ipv6"},
@@ -537,6 +578,7 @@ func loadSysProbeEnvVariables() {
 		{"DD_API_KEY", "system_probe_config.profiling.api_key"},
 		{"DD_ENV", "system_probe_config.profiling.env"},
 		{"DD_COLLECT_DNS_DOMAINS", "system_probe_config.collect_dns_domains"},
+		{"DD_SYSTEM_PROBE_NETWORK_ENABLE_DNS_QUERY_LOG", "network_config.enable_dns_query_log"},
 		{"DD_ENABLE_RUNTIME_COMPILER", "system_probe_config.enable_runtime_compiler"},
 		{"DD_KERNEL_HEADER_DIRS", "system_probe_config.kernel_header_dirs"},
 		{"DD_RUNTIME_COMPILER_OUTPUT_DIR", "system_probe_config.runtime_compiler_output_dir"},
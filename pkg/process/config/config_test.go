@@ -216,6 +216,210 @@ func TestEnableHTTPMonitoring(t *testing.T) {
 	})
 }
 
+func TestEnableHTTP2Monitoring(t *testing.T) {
+	t.Run("via YAML", func(t *testing.T) {
+		config.Datadog = config.NewConfig("datadog", "DD", strings.NewReplacer(".", "_"))
+		defer restoreGlobalConfig()
+
+		cfg, err := NewAgentConfig(
+			"test",
+			"./testdata/TestDDAgentConfigYamlAndSystemProbeConfig-EnableHTTP2.yaml",
+			"",
+		)
+
+		assert.Nil(t, err)
+		assert.True(t, cfg.EnableHTTP2Monitoring)
+		assert.Equal(t, []uint16{8080, 9090}, cfg.HTTP2MonitoringPorts)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		config.Datadog = config.NewConfig("datadog", "DD", strings.NewReplacer(".", "_"))
+		defer restoreGlobalConfig()
+
+		os.Setenv("DD_SYSTEM_PROBE_NETWORK_ENABLE_HTTP2_MONITORING", "true")
+		defer os.Unsetenv("DD_SYSTEM_PROBE_NETWORK_ENABLE_HTTP2_MONITORING")
+		cfg, err := NewAgentConfig("test", "", "")
+
+		assert.Nil(t, err)
+		assert.True(t, cfg.EnableHTTP2Monitoring)
+		assert.Empty(t, cfg.HTTP2MonitoringPorts)
+	})
+}
+
+func TestEnableProtocolMonitoring(t *testing.T) {
+	t.Run("via YAML", func(t *testing.T) {
+		config.Datadog = config.NewConfig("datadog", "DD", strings.NewReplacer(".", "_"))
+		defer restoreGlobalConfig()
+
+		cfg, err := NewAgentConfig(
+			"test",
+			"./testdata/TestDDAgentConfigYamlAndSystemProbeConfig-EnableProtocols.yaml",
+			"",
+		)
+
+		assert.Nil(t, err)
+		assert.True(t, cfg.EnableKafkaMonitoring)
+		assert.Equal(t, []uint16{9092, 9093}, cfg.KafkaMonitoringPorts)
+		assert.True(t, cfg.EnablePostgresMonitoring)
+		assert.Empty(t, cfg.PostgresMonitoringPorts)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		config.Datadog = config.NewConfig("datadog", "DD", strings.NewReplacer(".", "_"))
+		defer restoreGlobalConfig()
+
+		os.Setenv("DD_SYSTEM_PROBE_NETWORK_ENABLE_KAFKA_MONITORING", "true")
+		defer os.Unsetenv("DD_SYSTEM_PROBE_NETWORK_ENABLE_KAFKA_MONITORING")
+		os.Setenv("DD_SYSTEM_PROBE_NETWORK_ENABLE_POSTGRES_MONITORING", "true")
+		defer os.Unsetenv("DD_SYSTEM_PROBE_NETWORK_ENABLE_POSTGRES_MONITORING")
+		cfg, err := NewAgentConfig("test", "", "")
+
+		assert.Nil(t, err)
+		assert.True(t, cfg.EnableKafkaMonitoring)
+		assert.True(t, cfg.EnablePostgresMonitoring)
+	})
+}
+
+func TestConnectionReduction(t *testing.T) {
+	t.Run("via YAML", func(t *testing.T) {
+		config.Datadog = config.NewConfig("datadog", "DD", strings.NewReplacer(".", "_"))
+		defer restoreGlobalConfig()
+
+		cfg, err := NewAgentConfig(
+			"test",
+			"./testdata/TestDDAgentConfigYamlAndSystemProbeConfig-ConnectionReduction.yaml",
+			"",
+		)
+
+		assert.Nil(t, err)
+		assert.True(t, cfg.AggregateEphemeralConnections)
+		assert.Equal(t, "49152-65535", cfg.EphemeralPortRange)
+		assert.Equal(t, 5000, cfg.MaxReturnedConnections)
+		assert.Equal(t, []ConnectionSamplingRule{
+			{SampleRate: 0.1, Destination: map[string][]string{"10.0.0.0/24": {"8125", "udp 53"}}},
+			{SampleRate: 0.5},
+		}, cfg.ConnectionSamplingRules)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		config.Datadog = config.NewConfig("datadog", "DD", strings.NewReplacer(".", "_"))
+		defer restoreGlobalConfig()
+
+		os.Setenv("DD_SYSTEM_PROBE_NETWORK_AGGREGATE_EPHEMERAL_CONNECTIONS", "true")
+		defer os.Unsetenv("DD_SYSTEM_PROBE_NETWORK_AGGREGATE_EPHEMERAL_CONNECTIONS")
+		os.Setenv("DD_SYSTEM_PROBE_NETWORK_MAX_RETURNED_CONNECTIONS", "100")
+		defer os.Unsetenv("DD_SYSTEM_PROBE_NETWORK_MAX_RETURNED_CONNECTIONS")
+		cfg, err := NewAgentConfig("test", "", "")
+
+		assert.Nil(t, err)
+		assert.True(t, cfg.AggregateEphemeralConnections)
+		assert.Equal(t, 100, cfg.MaxReturnedConnections)
+		assert.Empty(t, cfg.ConnectionSamplingRules)
+	})
+}
+
+func TestDNSQueryLog(t *testing.T) {
+	t.Run("via YAML", func(t *testing.T) {
+		config.Datadog = config.NewConfig("datadog", "DD", strings.NewReplacer(".", "_"))
+		defer restoreGlobalConfig()
+
+		cfg, err := NewAgentConfig(
+			"test",
+			"./testdata/TestDDAgentConfigYamlAndSystemProbeConfig-DNSQueryLog.yaml",
+			"",
+		)
+
+		assert.Nil(t, err)
+		assert.True(t, cfg.EnableDNSQueryLog)
+		assert.Equal(t, 500, cfg.MaxDNSQueryLogEntries)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		config.Datadog = config.NewConfig("datadog", "DD", strings.NewReplacer(".", "_"))
+		defer restoreGlobalConfig()
+
+		os.Setenv("DD_SYSTEM_PROBE_NETWORK_ENABLE_DNS_QUERY_LOG", "true")
+		defer os.Unsetenv("DD_SYSTEM_PROBE_NETWORK_ENABLE_DNS_QUERY_LOG")
+		cfg, err := NewAgentConfig("test", "", "")
+
+		assert.Nil(t, err)
+		assert.True(t, cfg.EnableDNSQueryLog)
+		assert.Equal(t, 10000, cfg.MaxDNSQueryLogEntries)
+	})
+}
+
+func TestConntrackFallback(t *testing.T) {
+	t.Run("via YAML", func(t *testing.T) {
+		config.Datadog = config.NewConfig("datadog", "DD", strings.NewReplacer(".", "_"))
+		defer restoreGlobalConfig()
+
+		cfg, err := NewAgentConfig(
+			"test",
+			"./testdata/TestDDAgentConfigYamlAndSystemProbeConfig-ConntrackFallback.yaml",
+			"",
+		)
+
+		assert.Nil(t, err)
+		assert.True(t, cfg.EnableConntrackFallback)
+		assert.Equal(t, 10*time.Second, cfg.ConntrackFallbackInterval)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		config.Datadog = config.NewConfig("datadog", "DD", strings.NewReplacer(".", "_"))
+		defer restoreGlobalConfig()
+
+		os.Setenv("DD_SYSTEM_PROBE_NETWORK_ENABLE_CONNTRACK_FALLBACK", "true")
+		defer os.Unsetenv("DD_SYSTEM_PROBE_NETWORK_ENABLE_CONNTRACK_FALLBACK")
+		cfg, err := NewAgentConfig("test", "", "")
+
+		assert.Nil(t, err)
+		assert.True(t, cfg.EnableConntrackFallback)
+		assert.Equal(t, 30*time.Second, cfg.ConntrackFallbackInterval)
+	})
+}
+
+func TestCollectTLSMetadata(t *testing.T) {
+	t.Run("via ENV variable", func(t *testing.T) {
+		config.Datadog = config.NewConfig("datadog", "DD", strings.NewReplacer(".", "_"))
+		defer restoreGlobalConfig()
+
+		os.Setenv("DD_SYSTEM_PROBE_NETWORK_COLLECT_TLS_METADATA", "true")
+		defer os.Unsetenv("DD_SYSTEM_PROBE_NETWORK_COLLECT_TLS_METADATA")
+		cfg, err := NewAgentConfig("test", "", "")
+
+		assert.Nil(t, err)
+		assert.True(t, cfg.CollectTLSMetadata)
+	})
+}
+
+func TestEnableTCPFailureTelemetry(t *testing.T) {
+	t.Run("via YAML", func(t *testing.T) {
+		config.Datadog = config.NewConfig("datadog", "DD", strings.NewReplacer(".", "_"))
+		defer restoreGlobalConfig()
+
+		cfg, err := NewAgentConfig(
+			"test",
+			"./testdata/TestDDAgentConfigYamlAndSystemProbeConfig-TCPFailureTelemetry.yaml",
+			"",
+		)
+
+		assert.Nil(t, err)
+		assert.True(t, cfg.EnableTCPFailureTelemetry)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		config.Datadog = config.NewConfig("datadog", "DD", strings.NewReplacer(".", "_"))
+		defer restoreGlobalConfig()
+
+		os.Setenv("DD_SYSTEM_PROBE_NETWORK_ENABLE_TCP_FAILURE_TELEMETRY", "true")
+		defer os.Unsetenv("DD_SYSTEM_PROBE_NETWORK_ENABLE_TCP_FAILURE_TELEMETRY")
+		cfg, err := NewAgentConfig("test", "", "")
+
+		assert.Nil(t, err)
+		assert.True(t, cfg.EnableTCPFailureTelemetry)
+	})
+}
+
 func TestIgnoreConntrackInitFailure(t *testing.T) {
 	t.Run("via YAML", func(t *testing.T) {
 		config.Datadog = config.NewConfig("datadog", "DD", strings.NewReplacer(".", "_"))
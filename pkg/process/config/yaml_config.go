@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -58,14 +59,62 @@ func (a *AgentConfig) loadSysProbeYamlConfig(path string) error {
 		a.DNSTimeout = config.Datadog.GetDuration(key(spNS, "dns_timeout_in_s")) * time.Second
 	}
 
+	if config.Datadog.IsSet("network_config.enable_dns_query_log") {
+		a.EnableDNSQueryLog = config.Datadog.GetBool("network_config.enable_dns_query_log")
+	}
+
+	if config.Datadog.IsSet("network_config.max_dns_query_log_entries") {
+		a.MaxDNSQueryLogEntries = config.Datadog.GetInt("network_config.max_dns_query_log_entries")
+	}
+
 	if config.Datadog.IsSet("network_config.enable_http_monitoring") {
 		a.EnableHTTPMonitoring = config.Datadog.GetBool("network_config.enable_http_monitoring")
 	}
 
+	if config.Datadog.IsSet("network_config.enable_http2_monitoring") {
+		a.EnableHTTP2Monitoring = config.Datadog.GetBool("network_config.enable_http2_monitoring")
+	}
+
+	if config.Datadog.IsSet("network_config.http2_monitoring_ports") {
+		a.HTTP2MonitoringPorts = getPorts("network_config.http2_monitoring_ports")
+	}
+
+	if config.Datadog.IsSet("network_config.enable_kafka_monitoring") {
+		a.EnableKafkaMonitoring = config.Datadog.GetBool("network_config.enable_kafka_monitoring")
+	}
+
+	if config.Datadog.IsSet("network_config.kafka_monitoring_ports") {
+		a.KafkaMonitoringPorts = getPorts("network_config.kafka_monitoring_ports")
+	}
+
+	if config.Datadog.IsSet("network_config.enable_postgres_monitoring") {
+		a.EnablePostgresMonitoring = config.Datadog.GetBool("network_config.enable_postgres_monitoring")
+	}
+
+	if config.Datadog.IsSet("network_config.postgres_monitoring_ports") {
+		a.PostgresMonitoringPorts = getPorts("network_config.postgres_monitoring_ports")
+	}
+
+	if config.Datadog.IsSet("network_config.collect_tls_metadata") {
+		a.CollectTLSMetadata = config.Datadog.GetBool("network_config.collect_tls_metadata")
+	}
+
+	if config.Datadog.IsSet("network_config.enable_tcp_failure_telemetry") {
+		a.EnableTCPFailureTelemetry = config.Datadog.GetBool("network_config.enable_tcp_failure_telemetry")
+	}
+
 	if config.Datadog.IsSet("network_config.ignore_conntrack_init_failure") {
 		a.IgnoreConntrackInitFailure = config.Datadog.GetBool("network_config.ignore_conntrack_init_failure")
 	}
 
+	if config.Datadog.IsSet("network_config.enable_conntrack_fallback") {
+		a.EnableConntrackFallback = config.Datadog.GetBool("network_config.enable_conntrack_fallback")
+	}
+
+	if config.Datadog.IsSet("network_config.conntrack_fallback_interval_in_s") {
+		a.ConntrackFallbackInterval = config.Datadog.GetDuration("network_config.conntrack_fallback_interval_in_s") * time.Second
+	}
+
 	if config.Datadog.GetBool(key(spNS, "enabled")) {
 		a.EnableSystemProbe = true
 	}
@@ -162,6 +211,25 @@ func (a *AgentConfig) loadSysProbeYamlConfig(path string) error {
 		a.ExcludedDestinationConnections = config.Datadog.GetStringMapStringSlice(destinationExclude)
 	}
 
+	if config.Datadog.IsSet("network_config.aggregate_ephemeral_connections") {
+		a.AggregateEphemeralConnections = config.Datadog.GetBool("network_config.aggregate_ephemeral_connections")
+	}
+
+	if config.Datadog.IsSet("network_config.ephemeral_port_range") {
+		a.EphemeralPortRange = config.Datadog.GetString("network_config.ephemeral_port_range")
+	}
+
+	if config.Datadog.IsSet("network_config.connection_sampling_rules") {
+		if err := config.Datadog.UnmarshalKey("network_config.connection_sampling_rules", &a.ConnectionSamplingRules); err != nil {
+			log.Errorf("ignoring invalid network_config.connection_sampling_rules: %s", err)
+			a.ConnectionSamplingRules = nil
+		}
+	}
+
+	if config.Datadog.IsSet("network_config.max_returned_connections") {
+		a.MaxReturnedConnections = config.Datadog.GetInt("network_config.max_returned_connections")
+	}
+
 	if config.Datadog.GetBool(key(spNS, "enable_tcp_queue_length")) {
 		log.Info("system_probe_config.enable_tcp_queue_length detected, will enable system-probe with TCP queue length check")
 		a.EnableSystemProbe = true
@@ -440,3 +508,17 @@ func (a *AgentConfig) setCheckInterval(ns, check, checkKey string) {
 		a.CheckIntervals[checkKey] = time.Duration(interval) * time.Second
 	}
 }
+
+// getPorts returns the list of ports of the given key, ignoring the invalid ones
+func getPorts(k string) []uint16 {
+	var ports []uint16
+	for _, p := range config.Datadog.GetStringSlice(k) {
+		port, err := strconv.ParseUint(p, 10, 16)
+		if err != nil || port == 0 {
+			log.Warnf("ignoring invalid port %q in %s", p, k)
+			continue
+		}
+		ports = append(ports, uint16(port))
+	}
+	return ports
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/ebpf/probe"
+	"github.com/DataDog/datadog-agent/pkg/network"
 )
 
 const (
@@ -48,6 +49,13 @@ func (r *RemoteSysProbeUtil) GetCheck(check string) (interface{}, error) {
 			return nil, err
 		}
 		return stats, nil
+	} else if check == "dns_query_log" {
+		var entries []network.DNSQueryLogEntry
+		err = json.Unmarshal(body, &entries)
+		if err != nil {
+			return nil, err
+		}
+		return entries, nil
 	}
 
 	return nil, fmt.Errorf("Invalid check name: %s", check)
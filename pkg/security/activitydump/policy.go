@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package activitydump
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/DataDog/datadog-agent/pkg/security/rules"
+)
+
+// GeneratePolicy returns a policy whose rules match the activity of the image that deviates from the profile. The
+// parent/child relations can't be expressed in SECL and are only checked by the anomaly detection.
+func GeneratePolicy(p *Profile) *rules.Policy {
+	policy := &rules.Policy{
+		Name:    p.ID() + policyExt,
+		Version: p.LearningEnd.UTC().Format("20060102150405"),
+	}
+
+	image := fmt.Sprintf("container.image == %s", strconv.Quote(p.Image))
+	tags := map[string]string{"image": p.Image}
+
+	if executables := p.Executables(); len(executables) > 0 {
+		policy.Rules = append(policy.Rules, &rules.RuleDefinition{
+			ID:          p.ID() + "_exec",
+			Expression:  fmt.Sprintf("%s && exec.filename not in [%s]", image, seclValues(executables)),
+			Description: fmt.Sprintf("Unknown binary executed in a container of %s", p.Image),
+			Tags:        tags,
+		})
+	}
+
+	p.RLock()
+	overflow := p.FilesOverflow
+	p.RUnlock()
+
+	if files := p.Files(); len(files) > 0 && !overflow {
+		policy.Rules = append(policy.Rules, &rules.RuleDefinition{
+			ID:          p.ID() + "_open",
+			Expression:  fmt.Sprintf("%s && open.filename not in [%s]", image, seclValues(files)),
+			Description: fmt.Sprintf("Unknown file opened in a container of %s", p.Image),
+			Tags:        tags,
+		})
+	}
+
+	return policy
+}
+
+// seclValues returns the SECL array items of the given values, the normalized paths being turned into patterns
+func seclValues(values []string) string {
+	items := make([]string, len(values))
+	for i, value := range values {
+		items[i] = strconv.Quote(value)
+		if strings.Contains(value, "*") {
+			items[i] = "~" + items[i]
+		}
+	}
+	return strings.Join(items, ", ")
+}
+
+type policyRuleYAML struct {
+	ID          string            `yaml:"id"`
+	Expression  string            `yaml:"expression"`
+	Description string            `yaml:"description,omitempty"`
+	Tags        map[string]string `yaml:"tags,omitempty"`
+}
+
+type policyYAML struct {
+	Version string           `yaml:"version"`
+	Rules   []policyRuleYAML `yaml:"rules"`
+}
+
+// EncodePolicy writes the policy in the YAML format of the policy files
+func EncodePolicy(w io.Writer, policy *rules.Policy) error {
+	py := policyYAML{Version: policy.Version}
+	for _, rule := range policy.Rules {
+		py.Rules = append(py.Rules, policyRuleYAML{
+			ID:          rule.ID,
+			Expression:  rule.Expression,
+			Description: rule.Description,
+			Tags:        rule.Tags,
+		})
+	}
+
+	encoder := yaml.NewEncoder(w)
+	if err := encoder.Encode(py); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
@@ -0,0 +1,90 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package activitydump
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/security/model"
+	"github.com/DataDog/datadog-agent/pkg/security/rules"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
+)
+
+func TestGeneratePolicy(t *testing.T) {
+	p := NewProfile("nginx:1.19", time.Now(), time.Minute)
+	p.AddExec("/usr/sbin/nginx", "/bin/sh")
+	p.AddExec("", "/usr/sbin/nginx")
+	p.AddFile("/etc/nginx/nginx.conf", 0)
+	p.AddFile("/proc/42/status", 0)
+
+	var buf bytes.Buffer
+	if err := EncodePolicy(&buf, GeneratePolicy(p)); err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := rules.LoadPolicy(&buf, p.ID()+policyExt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(policy.Rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(policy.Rules))
+	}
+
+	enabled := map[eval.EventType]bool{"*": true}
+	opts := rules.NewOptsWithParams(model.SECLConstants, nil, enabled, nil)
+	rs := rules.NewRuleSet(&model.Model{}, (&model.Model{}).NewEvent, opts)
+
+	if err := rules.ApplyPolicies([]*rules.Policy{policy}, rs); err.ErrorOrNil() != nil {
+		t.Fatal(err)
+	}
+
+	fixtures, err := rules.LoadEventFixtures(strings.NewReader(`[
+  {
+    "name": "known binary",
+    "type": "exec",
+    "fields": {"container.image": "nginx:1.19", "exec.filename": "/bin/sh"}
+  },
+  {
+    "name": "unknown binary",
+    "type": "exec",
+    "fields": {"container.image": "nginx:1.19", "exec.filename": "/usr/bin/curl"},
+    "match": ["` + p.ID() + `_exec"]
+  },
+  {
+    "name": "other image",
+    "type": "exec",
+    "fields": {"container.image": "redis", "exec.filename": "/usr/bin/curl"}
+  },
+  {
+    "name": "known file",
+    "type": "open",
+    "fields": {"container.image": "nginx:1.19", "open.filename": "/proc/4242/status"}
+  },
+  {
+    "name": "unknown file",
+    "type": "open",
+    "fields": {"container.image": "nginx:1.19", "open.filename": "/etc/shadow"},
+    "match": ["` + p.ID() + `_open"]
+  }
+]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := rs.TestEventFixtures(fixtures, func(eventType eval.EventType) eval.Event {
+		return &model.Event{Type: uint64(model.ParseEvalEventType(eventType))}
+	})
+
+	for _, result := range results {
+		if !result.Passed() {
+			t.Errorf("fixture `%s` failed: %+v", result.Name, result)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package activitydump
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AnomalyType describes how an event deviates from a profile
+type AnomalyType string
+
+const (
+	// UnknownExecutableAnomaly is reported when a binary that wasn't seen during the learning window is executed
+	UnknownExecutableAnomaly AnomalyType = "unknown_executable"
+	// UnknownFileAnomaly is reported when a path that wasn't seen during the learning window is opened
+	UnknownFileAnomaly AnomalyType = "unknown_file"
+	// UnknownLineageAnomaly is reported when a known binary is executed by an unexpected parent
+	UnknownLineageAnomaly AnomalyType = "unknown_lineage"
+)
+
+// Anomaly describes a deviation from a profile
+type Anomaly struct {
+	Image  string
+	Type   AnomalyType
+	Value  string
+	Parent string
+}
+
+// Profile holds the activity learned for a container image: the executed binaries, the opened paths and the
+// parent/child relations between binaries.
+type Profile struct {
+	sync.RWMutex
+
+	Image         string
+	LearningStart time.Time
+	LearningEnd   time.Time
+	FilesOverflow bool
+
+	executables map[string]bool
+	files       map[string]bool
+	lineage     map[string]map[string]bool
+}
+
+// NewProfile returns a new profile for the given image, learning for the given window
+func NewProfile(image string, start time.Time, window time.Duration) *Profile {
+	return &Profile{
+		Image:         image,
+		LearningStart: start,
+		LearningEnd:   start.Add(window),
+		executables:   make(map[string]bool),
+		files:         make(map[string]bool),
+		lineage:       make(map[string]map[string]bool),
+	}
+}
+
+// ID returns an identifier of the profile, usable both as a file name and as a rule ID
+func (p *Profile) ID() string {
+	var b strings.Builder
+	for _, c := range p.Image {
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			b.WriteRune(c)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+
+	// distinct images can share the same sanitized name, add a hash of the original one
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(p.Image))
+
+	return fmt.Sprintf("%s_%08x", b.String(), h.Sum32())
+}
+
+// IsLearning returns whether the profile is still in its learning window
+func (p *Profile) IsLearning(now time.Time) bool {
+	return now.Before(p.LearningEnd)
+}
+
+// AddExec records the execution of a binary by a parent binary. The parent can be empty when unknown.
+func (p *Profile) AddExec(parent, executable string) {
+	if len(executable) == 0 {
+		return
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	p.executables[executable] = true
+
+	if len(parent) == 0 {
+		return
+	}
+
+	children, exists := p.lineage[parent]
+	if !exists {
+		children = make(map[string]bool)
+		p.lineage[parent] = children
+	}
+	children[executable] = true
+}
+
+// AddFile records the opening of a path. Once the profile holds maxFiles paths, the file activity is considered too
+// broad to be profiled and the paths aren't checked anymore.
+func (p *Profile) AddFile(path string, maxFiles int) {
+	if len(path) == 0 {
+		return
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	if p.FilesOverflow {
+		return
+	}
+
+	path = NormalizePath(path)
+	if p.files[path] {
+		return
+	}
+
+	if maxFiles > 0 && len(p.files) >= maxFiles {
+		p.FilesOverflow = true
+		p.files = make(map[string]bool)
+		return
+	}
+
+	p.files[path] = true
+}
+
+// CheckExec returns an anomaly if the execution of the binary by the parent wasn't learned
+func (p *Profile) CheckExec(parent, executable string) *Anomaly {
+	if len(executable) == 0 {
+		return nil
+	}
+
+	p.RLock()
+	defer p.RUnlock()
+
+	if !p.executables[executable] {
+		return &Anomaly{Image: p.Image, Type: UnknownExecutableAnomaly, Value: executable, Parent: parent}
+	}
+
+	// the lineage can only be checked when both the parent and its children were learned
+	if len(parent) == 0 || !p.executables[parent] {
+		return nil
+	}
+
+	if !p.lineage[parent][executable] {
+		return &Anomaly{Image: p.Image, Type: UnknownLineageAnomaly, Value: executable, Parent: parent}
+	}
+
+	return nil
+}
+
+// CheckFile returns an anomaly if the opening of the path wasn't learned
+func (p *Profile) CheckFile(path string) *Anomaly {
+	if len(path) == 0 {
+		return nil
+	}
+
+	p.RLock()
+	defer p.RUnlock()
+
+	if p.FilesOverflow || p.files[NormalizePath(path)] {
+		return nil
+	}
+
+	return &Anomaly{Image: p.Image, Type: UnknownFileAnomaly, Value: path}
+}
+
+// Executables returns the sorted list of the learned binaries
+func (p *Profile) Executables() []string {
+	p.RLock()
+	defer p.RUnlock()
+
+	return sortedKeys(p.executables)
+}
+
+// Files returns the sorted list of the learned paths
+func (p *Profile) Files() []string {
+	p.RLock()
+	defer p.RUnlock()
+
+	return sortedKeys(p.files)
+}
+
+// Children returns the sorted list of the binaries executed by the given parent
+func (p *Profile) Children(parent string) []string {
+	p.RLock()
+	defer p.RUnlock()
+
+	return sortedKeys(p.lineage[parent])
+}
+
+type profileJSON struct {
+	Image         string              `json:"image"`
+	LearningStart time.Time           `json:"learning_start"`
+	LearningEnd   time.Time           `json:"learning_end"`
+	Executables   []string            `json:"executables"`
+	Files         []string            `json:"files"`
+	FilesOverflow bool                `json:"files_overflow,omitempty"`
+	Lineage       map[string][]string `json:"lineage"`
+}
+
+// MarshalJSON returns the JSON encoding of the profile
+func (p *Profile) MarshalJSON() ([]byte, error) {
+	p.RLock()
+	defer p.RUnlock()
+
+	pj := profileJSON{
+		Image:         p.Image,
+		LearningStart: p.LearningStart,
+		LearningEnd:   p.LearningEnd,
+		Executables:   sortedKeys(p.executables),
+		Files:         sortedKeys(p.files),
+		FilesOverflow: p.FilesOverflow,
+		Lineage:       make(map[string][]string, len(p.lineage)),
+	}
+
+	for parent, children := range p.lineage {
+		pj.Lineage[parent] = sortedKeys(children)
+	}
+
+	return json.Marshal(pj)
+}
+
+// UnmarshalJSON decodes a profile from its JSON encoding
+func (p *Profile) UnmarshalJSON(data []byte) error {
+	var pj profileJSON
+	if err := json.Unmarshal(data, &pj); err != nil {
+		return err
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	p.Image = pj.Image
+	p.LearningStart = pj.LearningStart
+	p.LearningEnd = pj.LearningEnd
+	p.FilesOverflow = pj.FilesOverflow
+	p.executables = toSet(pj.Executables)
+	p.files = toSet(pj.Files)
+	p.lineage = make(map[string]map[string]bool, len(pj.Lineage))
+
+	for parent, children := range pj.Lineage {
+		p.lineage[parent] = toSet(children)
+	}
+
+	return nil
+}
+
+// NormalizePath replaces the numeric segments of a path, such as PIDs in /proc, by a wildcard so that they don't
+// grow the profiles endlessly
+func NormalizePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if isNumeric(segment) {
+			segments[i] = "*"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func isNumeric(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
@@ -0,0 +1,137 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package activitydump
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestProfileCheck(t *testing.T) {
+	now := time.Now()
+
+	p := NewProfile("nginx:1.19", now, time.Minute)
+	if !p.IsLearning(now.Add(time.Second)) || p.IsLearning(now.Add(time.Minute)) {
+		t.Fatal("unexpected learning window")
+	}
+
+	p.AddExec("", "/usr/sbin/nginx")
+	p.AddExec("/usr/sbin/nginx", "/bin/sh")
+	p.AddExec("/bin/sh", "/usr/bin/id")
+	p.AddFile("/etc/nginx/nginx.conf", 0)
+	p.AddFile("/proc/42/status", 0)
+
+	tests := []struct {
+		name     string
+		anomaly  *Anomaly
+		expected AnomalyType
+	}{
+		{"known-exec", p.CheckExec("/usr/sbin/nginx", "/bin/sh"), ""},
+		{"unknown-parent", p.CheckExec("/usr/bin/containerd-shim", "/usr/sbin/nginx"), ""},
+		{"unknown-exec", p.CheckExec("/bin/sh", "/usr/bin/curl"), UnknownExecutableAnomaly},
+		{"unknown-lineage", p.CheckExec("/usr/sbin/nginx", "/usr/bin/id"), UnknownLineageAnomaly},
+		{"known-file", p.CheckFile("/etc/nginx/nginx.conf"), ""},
+		{"normalized-file", p.CheckFile("/proc/4242/status"), ""},
+		{"unknown-file", p.CheckFile("/etc/shadow"), UnknownFileAnomaly},
+	}
+
+	for _, test := range tests {
+		var anomalyType AnomalyType
+		if test.anomaly != nil {
+			anomalyType = test.anomaly.Type
+		}
+		if anomalyType != test.expected {
+			t.Errorf("%s: expected anomaly `%s`, got `%s`", test.name, test.expected, anomalyType)
+		}
+	}
+}
+
+func TestProfileFilesOverflow(t *testing.T) {
+	p := NewProfile("redis", time.Now(), time.Minute)
+
+	p.AddFile("/etc/redis.conf", 2)
+	p.AddFile("/var/lib/redis/dump.rdb", 2)
+	if p.FilesOverflow {
+		t.Fatal("the files shouldn't overflow yet")
+	}
+
+	p.AddFile("/tmp/temp-1.rdb", 2)
+	if !p.FilesOverflow {
+		t.Fatal("the files should overflow")
+	}
+
+	if anomaly := p.CheckFile("/etc/shadow"); anomaly != nil {
+		t.Errorf("the files of an overflowed profile shouldn't be checked, got %+v", anomaly)
+	}
+}
+
+func TestProfileID(t *testing.T) {
+	a := NewProfile("registry/app:1.0", time.Now(), time.Minute)
+	b := NewProfile("registry/app_1.0", time.Now(), time.Minute)
+
+	if a.ID() == b.ID() {
+		t.Errorf("expected distinct IDs, got `%s` twice", a.ID())
+	}
+
+	for _, c := range a.ID() {
+		if !((c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '_') {
+			t.Errorf("unexpected character `%c` in `%s`", c, a.ID())
+		}
+	}
+}
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "activity-dump")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	p := NewProfile("nginx:1.19", now, time.Minute)
+	p.AddExec("/usr/sbin/nginx", "/bin/sh")
+	p.AddFile("/etc/nginx/nginx.conf", 0)
+
+	if err := store.Save(p); err != nil {
+		t.Fatal(err)
+	}
+
+	profiles, errs := store.Load()
+	if errs.ErrorOrNil() != nil {
+		t.Fatal(errs)
+	}
+
+	if len(profiles) != 1 {
+		t.Fatalf("expected 1 profile, got %d", len(profiles))
+	}
+
+	loaded := profiles[0]
+	if loaded.Image != p.Image || !loaded.LearningEnd.Equal(p.LearningEnd) {
+		t.Errorf("unexpected profile %s (%s)", loaded.Image, loaded.LearningEnd)
+	}
+	if !reflect.DeepEqual(loaded.Executables(), p.Executables()) {
+		t.Errorf("expected executables %v, got %v", p.Executables(), loaded.Executables())
+	}
+	if !reflect.DeepEqual(loaded.Files(), p.Files()) {
+		t.Errorf("expected files %v, got %v", p.Files(), loaded.Files())
+	}
+	if !reflect.DeepEqual(loaded.Children("/usr/sbin/nginx"), []string{"/bin/sh"}) {
+		t.Errorf("unexpected lineage %v", loaded.Children("/usr/sbin/nginx"))
+	}
+
+	if _, err := os.Stat(dir + "/" + p.ID() + policyExt); err != nil {
+		t.Errorf("the policy wasn't written: %s", err)
+	}
+}
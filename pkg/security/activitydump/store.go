@@ -0,0 +1,101 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package activitydump
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+)
+
+const (
+	profileExt = ".json"
+	policyExt  = ".policy"
+)
+
+// Store persists the profiles and their generated policies in a directory
+type Store struct {
+	dir string
+}
+
+// NewStore returns a new store, creating the directory if needed
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Wrap(err, "failed to create the activity dump directory")
+	}
+
+	return &Store{dir: dir}, nil
+}
+
+// Save writes the profile and its generated policy to the store
+func (s *Store) Save(p *Profile) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode the profile of `%s`", p.Image)
+	}
+
+	if err := writeFile(filepath.Join(s.dir, p.ID()+profileExt), data); err != nil {
+		return errors.Wrapf(err, "failed to write the profile of `%s`", p.Image)
+	}
+
+	var policy bytes.Buffer
+	if err := EncodePolicy(&policy, GeneratePolicy(p)); err != nil {
+		return errors.Wrapf(err, "failed to generate the policy of `%s`", p.Image)
+	}
+
+	if err := writeFile(filepath.Join(s.dir, p.ID()+policyExt), policy.Bytes()); err != nil {
+		return errors.Wrapf(err, "failed to write the policy of `%s`", p.Image)
+	}
+
+	return nil
+}
+
+// Load reads all the profiles of the store
+func (s *Store) Load() ([]*Profile, *multierror.Error) {
+	var result *multierror.Error
+
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, multierror.Append(result, err)
+	}
+
+	var profiles []*Profile
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != profileExt {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(s.dir, file.Name()))
+		if err != nil {
+			result = multierror.Append(result, err)
+			continue
+		}
+
+		p := &Profile{}
+		if err := json.Unmarshal(data, p); err != nil {
+			result = multierror.Append(result, errors.Wrapf(err, "failed to decode profile `%s`", file.Name()))
+			continue
+		}
+
+		profiles = append(profiles, p)
+	}
+
+	return profiles, result
+}
+
+// writeFile atomically replaces the content of a file
+func writeFile(filename string, data []byte) error {
+	tmp := filename + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
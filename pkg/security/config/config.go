@@ -65,6 +65,22 @@ type Config struct {
 	AgentMonitoringEvents bool
 	// FIMEnabled determines whether fim rules will be loaded
 	FIMEnabled bool
+	// ActivityDumpEnabled defines if the activity of the container images should be profiled
+	ActivityDumpEnabled bool
+	// ActivityDumpDir defines the directory in which the activity profiles are stored
+	ActivityDumpDir string
+	// ActivityDumpLearningWindow defines how long the activity of a new container image is learned
+	ActivityDumpLearningWindow time.Duration
+	// ActivityDumpMaxFiles defines the maximum number of paths learned per container image
+	ActivityDumpMaxFiles int
+	// EnforcementEnabled defines if the `kill` actions of the rules should be enforced
+	EnforcementEnabled bool
+	// EnforcementDryRun defines if the `kill` actions should only be reported, without sending any signal
+	EnforcementDryRun bool
+	// EnforcementRuleIDs lists the rules allowed to enforce their `kill` actions
+	EnforcementRuleIDs []string
+	// EnforcementMaxPerMinute defines the maximum number of `kill` actions enforced per rule and per minute
+	EnforcementMaxPerMinute int
 }
 
 // IsEnabled returns true if any feature is enabled
@@ -95,6 +111,14 @@ func NewConfig(cfg *config.AgentConfig) (*Config, error) {
 		StatsPollingInterval:               time.Duration(aconfig.Datadog.GetInt("runtime_security_config.events_stats.polling_interval")) * time.Second,
 		StatsdAddr:                         fmt.Sprintf("%s:%d", cfg.StatsdHost, cfg.StatsdPort),
 		AgentMonitoringEvents:              aconfig.Datadog.GetBool("runtime_security_config.agent_monitoring_events"),
+		ActivityDumpEnabled:                aconfig.Datadog.GetBool("runtime_security_config.activity_dump.enabled"),
+		ActivityDumpDir:                    aconfig.Datadog.GetString("runtime_security_config.activity_dump.dir"),
+		ActivityDumpLearningWindow:         time.Duration(aconfig.Datadog.GetInt("runtime_security_config.activity_dump.learning_window")) * time.Second,
+		ActivityDumpMaxFiles:               aconfig.Datadog.GetInt("runtime_security_config.activity_dump.max_files"),
+		EnforcementEnabled:                 aconfig.Datadog.GetBool("runtime_security_config.enforcement.enabled"),
+		EnforcementDryRun:                  aconfig.Datadog.GetBool("runtime_security_config.enforcement.dry_run"),
+		EnforcementRuleIDs:                 aconfig.Datadog.GetStringSlice("runtime_security_config.enforcement.rule_ids"),
+		EnforcementMaxPerMinute:            aconfig.Datadog.GetInt("runtime_security_config.enforcement.max_per_minute"),
 	}
 
 	// if runtime is enabled then we force fim
@@ -0,0 +1,23 @@
+#ifndef _BIND_H_
+#define _BIND_H_
+
+#include "network.h"
+
+int __attribute__((always_inline)) bind_approvers(struct syscall_cache_t *syscall) {
+    return addr_approvers(syscall, EVENT_BIND);
+}
+
+SYSCALL_KPROBE0(bind) {
+    return trace_network_syscall(SYSCALL_BIND, EVENT_BIND);
+}
+
+SEC("kprobe/security_socket_bind")
+int kprobe__security_socket_bind(struct pt_regs *ctx) {
+    return trace_security_socket(ctx, SYSCALL_BIND, bind_approvers);
+}
+
+SYSCALL_KRETPROBE(bind) {
+    return trace_network_syscall_ret(ctx, SYSCALL_BIND, EVENT_BIND);
+}
+
+#endif
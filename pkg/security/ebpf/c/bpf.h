@@ -0,0 +1,60 @@
+#ifndef _BPF_H_
+#define _BPF_H_
+
+#include "syscalls.h"
+
+#include <uapi/linux/bpf.h>
+
+struct bpf_event_t {
+    struct kevent_t event;
+    struct process_context_t process;
+    struct container_context_t container;
+    struct syscall_t syscall;
+    u32 cmd;
+    u32 prog_type;
+    char prog_name[BPF_PROG_NAME_LEN];
+};
+
+SYSCALL_KPROBE2(bpf, int, cmd, union bpf_attr *, uattr) {
+    struct syscall_cache_t syscall = {
+        .type = SYSCALL_BPF,
+        .bpf = {
+            .cmd = cmd,
+        },
+    };
+
+    // the type and the name of the program are only available when a program is loaded
+    if (cmd == BPF_PROG_LOAD) {
+        bpf_probe_read(&syscall.bpf.prog_type, sizeof(syscall.bpf.prog_type), &uattr->prog_type);
+        bpf_probe_read(&syscall.bpf.prog_name, sizeof(syscall.bpf.prog_name), &uattr->prog_name);
+    }
+
+    cache_syscall(&syscall);
+    return 0;
+}
+
+SYSCALL_KRETPROBE(bpf) {
+    struct syscall_cache_t *syscall = pop_syscall(SYSCALL_BPF);
+    if (!syscall)
+        return 0;
+
+    int retval = PT_REGS_RC(ctx);
+    if (IS_UNHANDLED_ERROR(retval))
+        return 0;
+
+    struct bpf_event_t event = {
+        .syscall.retval = retval,
+        .cmd = syscall->bpf.cmd,
+        .prog_type = syscall->bpf.prog_type,
+    };
+    bpf_probe_read(&event.prog_name, sizeof(event.prog_name), &syscall->bpf.prog_name);
+
+    struct proc_cache_t *entry = fill_process_context(&event.process);
+    fill_container_context(entry, &event.container);
+
+    send_event(ctx, EVENT_BPF, event);
+
+    return 0;
+}
+
+#endif
@@ -0,0 +1,23 @@
+#ifndef _CONNECT_H_
+#define _CONNECT_H_
+
+#include "network.h"
+
+int __attribute__((always_inline)) connect_approvers(struct syscall_cache_t *syscall) {
+    return addr_approvers(syscall, EVENT_CONNECT);
+}
+
+SYSCALL_KPROBE0(connect) {
+    return trace_network_syscall(SYSCALL_CONNECT, EVENT_CONNECT);
+}
+
+SEC("kprobe/security_socket_connect")
+int kprobe__security_socket_connect(struct pt_regs *ctx) {
+    return trace_security_socket(ctx, SYSCALL_CONNECT, connect_approvers);
+}
+
+SYSCALL_KRETPROBE(connect) {
+    return trace_network_syscall_ret(ctx, SYSCALL_CONNECT, EVENT_CONNECT);
+}
+
+#endif
@@ -169,6 +169,9 @@
 #define TTY_NAME_LEN 64
 #define CONTAINER_ID_LEN 64
 #define MAX_XATTR_NAME_LEN 200
+#define BPF_PROG_NAME_LEN 16
+#define LOAD_MODULE_NAME_LEN 56
+#define DNS_MAX_LENGTH 256
 
 #define bpf_printk(fmt, ...)                       \
 	({                                             \
@@ -201,6 +204,13 @@ enum event_type
     EVENT_EXEC,
     EVENT_EXIT,
     EVENT_INVALIDATE_DENTRY,
+    EVENT_SIGNAL,
+    EVENT_PTRACE,
+    EVENT_BPF,
+    EVENT_LOAD_MODULE,
+    EVENT_CONNECT,
+    EVENT_BIND,
+    EVENT_DNS,
     EVENT_MAX, // has to be the last one
 };
 
@@ -221,6 +231,12 @@ enum syscall_type
     SYSCALL_REMOVEXATTR = 1 << EVENT_REMOVEXATTR,
     SYSCALL_EXEC        = 1 << EVENT_EXEC,
     SYSCALL_FORK        = 1 << EVENT_FORK,
+    SYSCALL_SIGNAL      = 1 << EVENT_SIGNAL,
+    SYSCALL_PTRACE      = 1 << EVENT_PTRACE,
+    SYSCALL_BPF         = 1 << EVENT_BPF,
+    SYSCALL_LOAD_MODULE = 1 << EVENT_LOAD_MODULE,
+    SYSCALL_CONNECT     = 1 << EVENT_CONNECT,
+    SYSCALL_BIND        = 1 << EVENT_BIND,
 };
 
 struct kevent_t {
@@ -237,6 +253,13 @@ struct file_t {
     u32 padding;
 };
 
+struct addr_t {
+    u64 addr[2];
+    u16 family;
+    u16 port;
+    u32 padding;
+};
+
 struct syscall_t {
     s64 retval;
 };
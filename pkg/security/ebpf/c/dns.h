@@ -0,0 +1,111 @@
+#ifndef _DNS_H_
+#define _DNS_H_
+
+#include "network.h"
+
+#include <linux/uio.h>
+#include <net/sock.h>
+
+#define DNS_PORT 53
+
+struct dns_event_t {
+    struct kevent_t event;
+    struct process_context_t process;
+    struct container_context_t container;
+    u32 size;
+    u32 padding;
+    char payload[DNS_MAX_LENGTH];
+};
+
+// the queries are captured when sent, the payload is parsed by the agent
+int __attribute__((always_inline)) trace_dns_query(struct pt_regs *ctx, struct sock *sk, struct msghdr *msg, size_t len) {
+    struct policy_t policy = fetch_policy(EVENT_DNS);
+    if (is_discarded_by_process(policy.mode, EVENT_DNS)) {
+        return 0;
+    }
+
+    u16 port = 0;
+    struct sockaddr *address = NULL;
+    bpf_probe_read(&address, sizeof(address), &msg->msg_name);
+    if (address) {
+        struct addr_t addr = {};
+        read_sockaddr(address, &addr);
+        port = addr.port;
+    } else {
+        bpf_probe_read(&port, sizeof(port), &sk->__sk_common.skc_dport);
+    }
+
+    if (port != __constant_htons(DNS_PORT))
+        return 0;
+
+    // the layout of struct iov_iter depends on the kernel version: `iov` was renamed `__iov` and moved before
+    // `count` in 6.4, and since 6.0 the iterator can also be an ITER_UBUF holding the user buffer itself, in the
+    // same union. The iovec arrays are copied in the kernel, so a user space pointer identifies an ITER_UBUF
+    // regardless of the values of enum iter_type, which changed across versions.
+    u64 iov_offset;
+    LOAD_CONSTANT("iov_iter_iov_offset", iov_offset);
+    u64 count_offset;
+    LOAD_CONSTANT("iov_iter_count_offset", count_offset);
+
+    void *ptr = NULL;
+    bpf_probe_read(&ptr, sizeof(ptr), (char *)&msg->msg_iter + iov_offset);
+    if (!ptr)
+        return 0;
+
+    void *base = NULL;
+    size_t iov_len = 0;
+    if ((long)ptr < 0) {
+        // ITER_IOVEC
+        const struct iovec *iov = ptr;
+        bpf_probe_read(&base, sizeof(base), &iov->iov_base);
+        bpf_probe_read(&iov_len, sizeof(iov_len), &iov->iov_len);
+    } else {
+        // ITER_UBUF
+        base = ptr;
+        bpf_probe_read(&iov_len, sizeof(iov_len), (char *)&msg->msg_iter + count_offset);
+    }
+    if (!base)
+        return 0;
+
+    // only the first segment is copied
+    if (len > iov_len)
+        len = iov_len;
+
+    u32 size = len;
+    if (size >= DNS_MAX_LENGTH)
+        size = DNS_MAX_LENGTH - 1;
+    if (size == 0)
+        return 0;
+
+    struct dns_event_t event = {
+        .size = size,
+    };
+    bpf_probe_read(&event.payload, size & (DNS_MAX_LENGTH - 1), base);
+
+    struct proc_cache_t *entry = fill_process_context(&event.process);
+    fill_container_context(entry, &event.container);
+
+    send_event(ctx, EVENT_DNS, event);
+
+    return 0;
+}
+
+SEC("kprobe/udp_sendmsg")
+int kprobe__udp_sendmsg(struct pt_regs *ctx) {
+    struct sock *sk = (struct sock *)PT_REGS_PARM1(ctx);
+    struct msghdr *msg = (struct msghdr *)PT_REGS_PARM2(ctx);
+    size_t len = (size_t)PT_REGS_PARM3(ctx);
+
+    return trace_dns_query(ctx, sk, msg, len);
+}
+
+SEC("kprobe/udpv6_sendmsg")
+int kprobe__udpv6_sendmsg(struct pt_regs *ctx) {
+    struct sock *sk = (struct sock *)PT_REGS_PARM1(ctx);
+    struct msghdr *msg = (struct msghdr *)PT_REGS_PARM2(ctx);
+    size_t len = (size_t)PT_REGS_PARM3(ctx);
+
+    return trace_dns_query(ctx, sk, msg, len);
+}
+
+#endif
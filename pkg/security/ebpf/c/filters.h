@@ -16,6 +16,8 @@ enum policy_flags
     FLAGS = 2,
     MODE = 4,
     PARENT_NAME = 8,
+    PORT = 16,
+    FAMILY = 32,
 };
 
 struct policy_t {
@@ -0,0 +1,139 @@
+#ifndef _NETWORK_H_
+#define _NETWORK_H_
+
+#include "syscalls.h"
+
+#include <linux/in.h>
+#include <linux/in6.h>
+#include <linux/socket.h>
+
+struct bpf_map_def SEC("maps/port_approvers") port_approvers = {
+    .type = BPF_MAP_TYPE_HASH,
+    .key_size = sizeof(u16),
+    .value_size = sizeof(u64),
+    .max_entries = 255,
+    .pinning = 0,
+    .namespace = "",
+};
+
+struct bpf_map_def SEC("maps/family_approvers") family_approvers = {
+    .type = BPF_MAP_TYPE_HASH,
+    .key_size = sizeof(u16),
+    .value_size = sizeof(u64),
+    .max_entries = 16,
+    .pinning = 0,
+    .namespace = "",
+};
+
+struct network_event_t {
+    struct kevent_t event;
+    struct process_context_t process;
+    struct container_context_t container;
+    struct syscall_t syscall;
+    struct addr_t addr;
+};
+
+// the port is kept in network byte order
+void __attribute__((always_inline)) read_sockaddr(struct sockaddr *address, struct addr_t *addr) {
+    bpf_probe_read(&addr->family, sizeof(addr->family), &address->sa_family);
+
+    switch (addr->family) {
+        case AF_INET: {
+            struct sockaddr_in *in = (struct sockaddr_in *)address;
+            bpf_probe_read(&addr->port, sizeof(addr->port), &in->sin_port);
+            bpf_probe_read(&addr->addr, sizeof(in->sin_addr), &in->sin_addr);
+            break;
+        }
+        case AF_INET6: {
+            struct sockaddr_in6 *in6 = (struct sockaddr_in6 *)address;
+            bpf_probe_read(&addr->port, sizeof(addr->port), &in6->sin6_port);
+            bpf_probe_read(&addr->addr, sizeof(in6->sin6_addr), &in6->sin6_addr);
+            break;
+        }
+    }
+}
+
+int __attribute__((always_inline)) approve_by_port(struct addr_t *addr, u64 event_type) {
+    u64 *event_mask = bpf_map_lookup_elem(&port_approvers, &addr->port);
+    if (event_mask && *event_mask & (1 << (event_type-1))) {
+        return 1;
+    }
+    return 0;
+}
+
+int __attribute__((always_inline)) approve_by_family(struct addr_t *addr, u64 event_type) {
+    u64 *event_mask = bpf_map_lookup_elem(&family_approvers, &addr->family);
+    if (event_mask && *event_mask & (1 << (event_type-1))) {
+        return 1;
+    }
+    return 0;
+}
+
+int __attribute__((always_inline)) addr_approvers(struct syscall_cache_t *syscall, u64 event_type) {
+    int pass_to_userspace = 0;
+
+    if ((syscall->policy.flags & PORT) > 0) {
+        pass_to_userspace = approve_by_port(&syscall->network.addr, event_type);
+    }
+
+    if (!pass_to_userspace && (syscall->policy.flags & FAMILY) > 0) {
+        pass_to_userspace = approve_by_family(&syscall->network.addr, event_type);
+    }
+
+    return pass_to_userspace;
+}
+
+int __attribute__((always_inline)) trace_network_syscall(u64 type, u64 event_type) {
+    struct policy_t policy = fetch_policy(event_type);
+    if (is_discarded_by_process(policy.mode, event_type)) {
+        return 0;
+    }
+
+    struct syscall_cache_t syscall = {
+        .type = type,
+        .policy = policy,
+    };
+
+    cache_syscall(&syscall);
+    return 0;
+}
+
+int __attribute__((always_inline)) trace_security_socket(struct pt_regs *ctx, u64 type, int (*check_approvers)(struct syscall_cache_t *syscall)) {
+    struct syscall_cache_t *syscall = peek_syscall(type);
+    if (!syscall)
+        return 0;
+
+    struct sockaddr *address = (struct sockaddr *)PT_REGS_PARM2(ctx);
+    read_sockaddr(address, &syscall->network.addr);
+
+    if (filter_syscall(syscall, check_approvers)) {
+        return discard_syscall(syscall);
+    }
+
+    return 0;
+}
+
+int __attribute__((always_inline)) trace_network_syscall_ret(struct pt_regs *ctx, u64 type, u64 event_type) {
+    struct syscall_cache_t *syscall = pop_syscall(type);
+    if (!syscall)
+        return 0;
+
+    int retval = PT_REGS_RC(ctx);
+    // non blocking connections are reported as well
+    if (IS_UNHANDLED_ERROR(retval) && retval != -EINPROGRESS)
+        return 0;
+
+    struct network_event_t event = {
+        .syscall.retval = retval,
+        .addr = syscall->network.addr,
+    };
+
+    struct proc_cache_t *entry = fill_process_context(&event.process);
+    fill_container_context(entry, &event.container);
+
+    send_event(ctx, event_type, event);
+
+    return 0;
+}
+
+#endif
@@ -36,6 +36,13 @@
 #include "setxattr.h"
 #include "erpc.h"
 #include "ioctl.h"
+#include "signal.h"
+#include "ptrace.h"
+#include "bpf.h"
+#include "load_module.h"
+#include "connect.h"
+#include "bind.h"
+#include "dns.h"
 
 struct invalidate_dentry_event_t {
     struct kevent_t event;
@@ -0,0 +1,54 @@
+#ifndef _PTRACE_H_
+#define _PTRACE_H_
+
+#include "syscalls.h"
+
+struct ptrace_event_t {
+    struct kevent_t event;
+    struct process_context_t process;
+    struct container_context_t container;
+    struct syscall_t syscall;
+    u32 request;
+    u32 pid;
+    u64 addr;
+};
+
+SYSCALL_KPROBE3(ptrace, u32, request, pid_t, pid, void *, addr) {
+    struct syscall_cache_t syscall = {
+        .type = SYSCALL_PTRACE,
+        .ptrace = {
+            .request = request,
+            .pid = pid,
+            .addr = addr,
+        },
+    };
+
+    cache_syscall(&syscall);
+    return 0;
+}
+
+SYSCALL_KRETPROBE(ptrace) {
+    struct syscall_cache_t *syscall = pop_syscall(SYSCALL_PTRACE);
+    if (!syscall)
+        return 0;
+
+    int retval = PT_REGS_RC(ctx);
+    if (IS_UNHANDLED_ERROR(retval))
+        return 0;
+
+    struct ptrace_event_t event = {
+        .syscall.retval = retval,
+        .request = syscall->ptrace.request,
+        .pid = syscall->ptrace.pid,
+        .addr = (u64)syscall->ptrace.addr,
+    };
+
+    struct proc_cache_t *entry = fill_process_context(&event.process);
+    fill_container_context(entry, &event.container);
+
+    send_event(ctx, EVENT_PTRACE, event);
+
+    return 0;
+}
+
+#endif
@@ -0,0 +1,51 @@
+#ifndef _SIGNAL_H_
+#define _SIGNAL_H_
+
+#include "syscalls.h"
+
+struct signal_event_t {
+    struct kevent_t event;
+    struct process_context_t process;
+    struct container_context_t container;
+    struct syscall_t syscall;
+    u32 pid;
+    u32 type;
+};
+
+SYSCALL_KPROBE2(kill, int, pid, int, type) {
+    struct syscall_cache_t syscall = {
+        .type = SYSCALL_SIGNAL,
+        .signal = {
+            .pid = pid,
+            .type = type,
+        },
+    };
+
+    cache_syscall(&syscall);
+    return 0;
+}
+
+SYSCALL_KRETPROBE(kill) {
+    struct syscall_cache_t *syscall = pop_syscall(SYSCALL_SIGNAL);
+    if (!syscall)
+        return 0;
+
+    int retval = PT_REGS_RC(ctx);
+    if (IS_UNHANDLED_ERROR(retval))
+        return 0;
+
+    struct signal_event_t event = {
+        .syscall.retval = retval,
+        .pid = syscall->signal.pid,
+        .type = syscall->signal.type,
+    };
+
+    struct proc_cache_t *entry = fill_process_context(&event.process);
+    fill_container_context(entry, &event.container);
+
+    send_event(ctx, EVENT_SIGNAL, event);
+
+    return 0;
+}
+
+#endif
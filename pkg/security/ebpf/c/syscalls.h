@@ -100,6 +100,32 @@ struct syscall_cache_t {
         struct {
             u8 is_thread;
         } clone;
+
+        struct {
+            u32 pid;
+            u32 type;
+        } signal;
+
+        struct {
+            u32 request;
+            u32 pid;
+            void *addr;
+        } ptrace;
+
+        struct {
+            u32 cmd;
+            u32 prog_type;
+            char prog_name[BPF_PROG_NAME_LEN];
+        } bpf;
+
+        struct {
+            char name[LOAD_MODULE_NAME_LEN];
+            u32 loaded_from_memory;
+        } load_module;
+
+        struct {
+            struct addr_t addr;
+        } network;
     };
 };
 
@@ -29,6 +29,26 @@ func (i Uint8MapItem) MarshalBinary() ([]byte, error) {
 	return []byte{uint8(i)}, nil
 }
 
+// Uint16MapItem describes an uint16 table key or value
+type Uint16MapItem uint16
+
+// MarshalBinary returns the binary representation of a Uint16MapItem
+func (i Uint16MapItem) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 2)
+	model.ByteOrder.PutUint16(b, uint16(i))
+	return b, nil
+}
+
+// NetworkUint16MapItem describes an uint16 table key or value stored in network byte order, like ports
+type NetworkUint16MapItem uint16
+
+// MarshalBinary returns the binary representation of a NetworkUint16MapItem
+func (i NetworkUint16MapItem) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 2)
+	binary.BigEndian.PutUint16(b, uint16(i))
+	return b, nil
+}
+
 // Uint32MapItem describes an uint32 table key or value
 type Uint32MapItem uint32
 
@@ -30,6 +30,11 @@ func AllProbes() []*manager.Probe {
 	allProbes = append(allProbes, getUnlinkProbes()...)
 	allProbes = append(allProbes, getXattrProbes()...)
 	allProbes = append(allProbes, getIoctlProbes()...)
+	allProbes = append(allProbes, getSignalProbes()...)
+	allProbes = append(allProbes, getPTraceProbes()...)
+	allProbes = append(allProbes, getBPFProbes()...)
+	allProbes = append(allProbes, getLoadModuleProbes()...)
+	allProbes = append(allProbes, getNetworkProbes()...)
 
 	allProbes = append(allProbes,
 		// Syscall monitor
@@ -70,6 +75,9 @@ func AllMaps() []*manager.Map {
 		{Name: "inode_info_cache"},
 		// Open tables
 		{Name: "open_flags_approvers"},
+		// Network tables
+		{Name: "port_approvers"},
+		{Name: "family_approvers"},
 		// Exec tables
 		{Name: "proc_cache"},
 		{Name: "pid_cache"},
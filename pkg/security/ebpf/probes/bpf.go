@@ -0,0 +1,21 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux
+
+package probes
+
+import "github.com/DataDog/ebpf/manager"
+
+// bpfProbes holds the list of probes used to track bpf events
+var bpfProbes []*manager.Probe
+
+func getBPFProbes() []*manager.Probe {
+	bpfProbes = append(bpfProbes, ExpandSyscallProbes(&manager.Probe{
+		UID:             SecurityAgentUID,
+		SyscallFuncName: "bpf",
+	}, EntryAndExit)...)
+	return bpfProbes
+}
@@ -0,0 +1,42 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux
+
+package probes
+
+import "github.com/DataDog/ebpf/manager"
+
+// networkProbes holds the list of probes used to track connect, bind and dns events
+var networkProbes = []*manager.Probe{
+	{
+		UID:     SecurityAgentUID,
+		Section: "kprobe/security_socket_connect",
+	},
+	{
+		UID:     SecurityAgentUID,
+		Section: "kprobe/security_socket_bind",
+	},
+	{
+		UID:     SecurityAgentUID,
+		Section: "kprobe/udp_sendmsg",
+	},
+	{
+		UID:     SecurityAgentUID,
+		Section: "kprobe/udpv6_sendmsg",
+	},
+}
+
+func getNetworkProbes() []*manager.Probe {
+	networkProbes = append(networkProbes, ExpandSyscallProbes(&manager.Probe{
+		UID:             SecurityAgentUID,
+		SyscallFuncName: "connect",
+	}, EntryAndExit)...)
+	networkProbes = append(networkProbes, ExpandSyscallProbes(&manager.Probe{
+		UID:             SecurityAgentUID,
+		SyscallFuncName: "bind",
+	}, EntryAndExit)...)
+	return networkProbes
+}
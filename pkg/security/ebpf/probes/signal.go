@@ -0,0 +1,21 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux
+
+package probes
+
+import "github.com/DataDog/ebpf/manager"
+
+// signalProbes holds the list of probes used to track signal events
+var signalProbes []*manager.Probe
+
+func getSignalProbes() []*manager.Probe {
+	signalProbes = append(signalProbes, ExpandSyscallProbes(&manager.Probe{
+		UID:             SecurityAgentUID,
+		SyscallFuncName: "kill",
+	}, EntryAndExit)...)
+	return signalProbes
+}
@@ -34,6 +34,25 @@ var (
 	// Tags: rule_id
 	MetricRateLimiterAllow = newRuntimeMetric(".rules.rate_limiter.allow")
 
+	// Rule actions metrics
+
+	// MetricActionSuppressed is the name of the metric used to count the amount of events suppressed by a `suppress`
+	// rule action
+	// Tags: rule_id
+	MetricActionSuppressed = newRuntimeMetric(".rules.actions.suppressed")
+	// MetricActionKill is the name of the metric used to count the `kill` rule actions, per enforcement status
+	// Tags: rule_id, status
+	MetricActionKill = newRuntimeMetric(".rules.actions.kill")
+
+	// Activity dump metrics
+
+	// MetricActivityDumpProfiles is the name of the metric used to report the number of activity profiles
+	// Tags: learning
+	MetricActivityDumpProfiles = newRuntimeMetric(".activity_dump.profiles")
+	// MetricActivityDumpAnomalies is the name of the metric used to count the deviations from the activity profiles
+	// Tags: anomaly
+	MetricActivityDumpAnomalies = newRuntimeMetric(".activity_dump.anomalies")
+
 	// Syscall monitoring metrics
 
 	// MetricSyscalls is the name of the metric used to count each syscall executed on the host
@@ -22,7 +22,8 @@ const (
 
 // GetEventTypeCategory returns the category for the given event type
 func GetEventTypeCategory(eventType eval.EventType) EventCategory {
-	if eventType == "exec" {
+	switch eventType {
+	case "exec", "signal", "ptrace", "bpf", "load_module", "connect", "bind", "dns":
 		return RuntimeCategory
 	}
 
@@ -3,6 +3,7 @@
 // This product includes software developed at Datadog (https://www.datadoghq.com/).
 // Copyright 2016-present Datadog, Inc.
 
+//go:build linux
 // +build linux
 
 package model
@@ -24,6 +25,9 @@ const MaxSegmentLength = 127
 // MaxPathDepth defines the maximum depth of a path
 const MaxPathDepth = 15
 
+// DNSMaxLength defines the maximum length of the DNS queries captured by the kernel
+const DNSMaxLength = 256
+
 var (
 	errorConstants = map[string]int{
 		"E2BIG":           -int(syscall.E2BIG),
@@ -219,6 +223,159 @@ var (
 		"AT_REMOVEDIR": unix.AT_REMOVEDIR,
 	}
 
+	signalConstants = map[string]int{
+		"SIGHUP":    int(unix.SIGHUP),
+		"SIGINT":    int(unix.SIGINT),
+		"SIGQUIT":   int(unix.SIGQUIT),
+		"SIGILL":    int(unix.SIGILL),
+		"SIGTRAP":   int(unix.SIGTRAP),
+		"SIGABRT":   int(unix.SIGABRT),
+		"SIGBUS":    int(unix.SIGBUS),
+		"SIGFPE":    int(unix.SIGFPE),
+		"SIGKILL":   int(unix.SIGKILL),
+		"SIGUSR1":   int(unix.SIGUSR1),
+		"SIGSEGV":   int(unix.SIGSEGV),
+		"SIGUSR2":   int(unix.SIGUSR2),
+		"SIGPIPE":   int(unix.SIGPIPE),
+		"SIGALRM":   int(unix.SIGALRM),
+		"SIGTERM":   int(unix.SIGTERM),
+		"SIGSTKFLT": int(unix.SIGSTKFLT),
+		"SIGCHLD":   int(unix.SIGCHLD),
+		"SIGCONT":   int(unix.SIGCONT),
+		"SIGSTOP":   int(unix.SIGSTOP),
+		"SIGTSTP":   int(unix.SIGTSTP),
+		"SIGTTIN":   int(unix.SIGTTIN),
+		"SIGTTOU":   int(unix.SIGTTOU),
+		"SIGURG":    int(unix.SIGURG),
+		"SIGXCPU":   int(unix.SIGXCPU),
+		"SIGXFSZ":   int(unix.SIGXFSZ),
+		"SIGVTALRM": int(unix.SIGVTALRM),
+		"SIGPROF":   int(unix.SIGPROF),
+		"SIGWINCH":  int(unix.SIGWINCH),
+		"SIGIO":     int(unix.SIGIO),
+		"SIGPWR":    int(unix.SIGPWR),
+		"SIGSYS":    int(unix.SIGSYS),
+	}
+
+	ptraceConstants = map[string]int{
+		"PTRACE_TRACEME":    unix.PTRACE_TRACEME,
+		"PTRACE_PEEKTEXT":   unix.PTRACE_PEEKTEXT,
+		"PTRACE_PEEKDATA":   unix.PTRACE_PEEKDATA,
+		"PTRACE_PEEKUSR":    unix.PTRACE_PEEKUSR,
+		"PTRACE_POKETEXT":   unix.PTRACE_POKETEXT,
+		"PTRACE_POKEDATA":   unix.PTRACE_POKEDATA,
+		"PTRACE_POKEUSR":    unix.PTRACE_POKEUSR,
+		"PTRACE_CONT":       unix.PTRACE_CONT,
+		"PTRACE_KILL":       unix.PTRACE_KILL,
+		"PTRACE_SINGLESTEP": unix.PTRACE_SINGLESTEP,
+		"PTRACE_ATTACH":     unix.PTRACE_ATTACH,
+		"PTRACE_DETACH":     unix.PTRACE_DETACH,
+		"PTRACE_SYSCALL":    unix.PTRACE_SYSCALL,
+		"PTRACE_SETOPTIONS": unix.PTRACE_SETOPTIONS,
+		"PTRACE_GETSIGINFO": unix.PTRACE_GETSIGINFO,
+		"PTRACE_SETSIGINFO": unix.PTRACE_SETSIGINFO,
+		"PTRACE_GETREGSET":  unix.PTRACE_GETREGSET,
+		"PTRACE_SETREGSET":  unix.PTRACE_SETREGSET,
+		"PTRACE_SEIZE":      unix.PTRACE_SEIZE,
+		"PTRACE_INTERRUPT":  unix.PTRACE_INTERRUPT,
+		"PTRACE_LISTEN":     unix.PTRACE_LISTEN,
+	}
+
+	bpfCmdConstants = map[string]int{
+		"BPF_MAP_CREATE":          unix.BPF_MAP_CREATE,
+		"BPF_MAP_LOOKUP_ELEM":     unix.BPF_MAP_LOOKUP_ELEM,
+		"BPF_MAP_UPDATE_ELEM":     unix.BPF_MAP_UPDATE_ELEM,
+		"BPF_MAP_DELETE_ELEM":     unix.BPF_MAP_DELETE_ELEM,
+		"BPF_MAP_GET_NEXT_KEY":    unix.BPF_MAP_GET_NEXT_KEY,
+		"BPF_PROG_LOAD":           unix.BPF_PROG_LOAD,
+		"BPF_OBJ_PIN":             unix.BPF_OBJ_PIN,
+		"BPF_OBJ_GET":             unix.BPF_OBJ_GET,
+		"BPF_PROG_ATTACH":         unix.BPF_PROG_ATTACH,
+		"BPF_PROG_DETACH":         unix.BPF_PROG_DETACH,
+		"BPF_PROG_TEST_RUN":       unix.BPF_PROG_TEST_RUN,
+		"BPF_PROG_GET_NEXT_ID":    unix.BPF_PROG_GET_NEXT_ID,
+		"BPF_MAP_GET_NEXT_ID":     unix.BPF_MAP_GET_NEXT_ID,
+		"BPF_PROG_GET_FD_BY_ID":   unix.BPF_PROG_GET_FD_BY_ID,
+		"BPF_MAP_GET_FD_BY_ID":    unix.BPF_MAP_GET_FD_BY_ID,
+		"BPF_OBJ_GET_INFO_BY_FD":  unix.BPF_OBJ_GET_INFO_BY_FD,
+		"BPF_PROG_QUERY":          unix.BPF_PROG_QUERY,
+		"BPF_RAW_TRACEPOINT_OPEN": unix.BPF_RAW_TRACEPOINT_OPEN,
+		"BPF_BTF_LOAD":            unix.BPF_BTF_LOAD,
+		"BPF_BTF_GET_FD_BY_ID":    unix.BPF_BTF_GET_FD_BY_ID,
+		"BPF_TASK_FD_QUERY":       unix.BPF_TASK_FD_QUERY,
+		"BPF_MAP_FREEZE":          unix.BPF_MAP_FREEZE,
+		"BPF_LINK_CREATE":         unix.BPF_LINK_CREATE,
+		"BPF_LINK_UPDATE":         unix.BPF_LINK_UPDATE,
+		"BPF_ENABLE_STATS":        unix.BPF_ENABLE_STATS,
+		"BPF_ITER_CREATE":         unix.BPF_ITER_CREATE,
+	}
+
+	bpfProgramTypeConstants = map[string]int{
+		"BPF_PROG_TYPE_UNSPEC":                  unix.BPF_PROG_TYPE_UNSPEC,
+		"BPF_PROG_TYPE_SOCKET_FILTER":           unix.BPF_PROG_TYPE_SOCKET_FILTER,
+		"BPF_PROG_TYPE_KPROBE":                  unix.BPF_PROG_TYPE_KPROBE,
+		"BPF_PROG_TYPE_SCHED_CLS":               unix.BPF_PROG_TYPE_SCHED_CLS,
+		"BPF_PROG_TYPE_SCHED_ACT":               unix.BPF_PROG_TYPE_SCHED_ACT,
+		"BPF_PROG_TYPE_TRACEPOINT":              unix.BPF_PROG_TYPE_TRACEPOINT,
+		"BPF_PROG_TYPE_XDP":                     unix.BPF_PROG_TYPE_XDP,
+		"BPF_PROG_TYPE_PERF_EVENT":              unix.BPF_PROG_TYPE_PERF_EVENT,
+		"BPF_PROG_TYPE_CGROUP_SKB":              unix.BPF_PROG_TYPE_CGROUP_SKB,
+		"BPF_PROG_TYPE_CGROUP_SOCK":             unix.BPF_PROG_TYPE_CGROUP_SOCK,
+		"BPF_PROG_TYPE_LWT_IN":                  unix.BPF_PROG_TYPE_LWT_IN,
+		"BPF_PROG_TYPE_LWT_OUT":                 unix.BPF_PROG_TYPE_LWT_OUT,
+		"BPF_PROG_TYPE_LWT_XMIT":                unix.BPF_PROG_TYPE_LWT_XMIT,
+		"BPF_PROG_TYPE_SOCK_OPS":                unix.BPF_PROG_TYPE_SOCK_OPS,
+		"BPF_PROG_TYPE_SK_SKB":                  unix.BPF_PROG_TYPE_SK_SKB,
+		"BPF_PROG_TYPE_CGROUP_DEVICE":           unix.BPF_PROG_TYPE_CGROUP_DEVICE,
+		"BPF_PROG_TYPE_SK_MSG":                  unix.BPF_PROG_TYPE_SK_MSG,
+		"BPF_PROG_TYPE_RAW_TRACEPOINT":          unix.BPF_PROG_TYPE_RAW_TRACEPOINT,
+		"BPF_PROG_TYPE_CGROUP_SOCK_ADDR":        unix.BPF_PROG_TYPE_CGROUP_SOCK_ADDR,
+		"BPF_PROG_TYPE_LWT_SEG6LOCAL":           unix.BPF_PROG_TYPE_LWT_SEG6LOCAL,
+		"BPF_PROG_TYPE_LIRC_MODE2":              unix.BPF_PROG_TYPE_LIRC_MODE2,
+		"BPF_PROG_TYPE_SK_REUSEPORT":            unix.BPF_PROG_TYPE_SK_REUSEPORT,
+		"BPF_PROG_TYPE_FLOW_DISSECTOR":          unix.BPF_PROG_TYPE_FLOW_DISSECTOR,
+		"BPF_PROG_TYPE_CGROUP_SYSCTL":           unix.BPF_PROG_TYPE_CGROUP_SYSCTL,
+		"BPF_PROG_TYPE_RAW_TRACEPOINT_WRITABLE": unix.BPF_PROG_TYPE_RAW_TRACEPOINT_WRITABLE,
+		"BPF_PROG_TYPE_CGROUP_SOCKOPT":          unix.BPF_PROG_TYPE_CGROUP_SOCKOPT,
+		"BPF_PROG_TYPE_TRACING":                 unix.BPF_PROG_TYPE_TRACING,
+		"BPF_PROG_TYPE_STRUCT_OPS":              unix.BPF_PROG_TYPE_STRUCT_OPS,
+		"BPF_PROG_TYPE_EXT":                     unix.BPF_PROG_TYPE_EXT,
+		"BPF_PROG_TYPE_LSM":                     unix.BPF_PROG_TYPE_LSM,
+	}
+
+	addressFamilyConstants = map[string]int{
+		"AF_UNSPEC":  unix.AF_UNSPEC,
+		"AF_UNIX":    unix.AF_UNIX,
+		"AF_INET":    unix.AF_INET,
+		"AF_INET6":   unix.AF_INET6,
+		"AF_NETLINK": unix.AF_NETLINK,
+		"AF_PACKET":  unix.AF_PACKET,
+	}
+
+	dnsQTypeConstants = map[string]int{
+		"A":     1,
+		"NS":    2,
+		"CNAME": 5,
+		"SOA":   6,
+		"PTR":   12,
+		"MX":    15,
+		"TXT":   16,
+		"AAAA":  28,
+		"SRV":   33,
+		"NAPTR": 35,
+		"OPT":   41,
+		"ANY":   255,
+	}
+
+	dnsQClassConstants = map[string]int{
+		"CLASS_INET":   1,
+		"CLASS_CSNET":  2,
+		"CLASS_CHAOS":  3,
+		"CLASS_HESIOD": 4,
+		"CLASS_NONE":   254,
+		"CLASS_ANY":    255,
+	}
+
 	// SECLConstants are constants available in runtime security agent rules
 	SECLConstants = map[string]interface{}{
 		// boolean
@@ -228,9 +385,16 @@ var (
 )
 
 var (
-	openFlagsStrings   = map[int]string{}
-	chmodModeStrings   = map[int]string{}
-	unlinkFlagsStrings = map[int]string{}
+	openFlagsStrings     = map[int]string{}
+	chmodModeStrings     = map[int]string{}
+	unlinkFlagsStrings   = map[int]string{}
+	signalStrings        = map[int]string{}
+	ptraceStrings        = map[int]string{}
+	bpfCmdStrings        = map[int]string{}
+	bpfProgTypeStrings   = map[int]string{}
+	addressFamilyStrings = map[int]string{}
+	dnsQTypeStrings      = map[int]string{}
+	dnsQClassStrings     = map[int]string{}
 )
 
 func initOpenConstants() {
@@ -263,6 +427,49 @@ func initUnlinkConstanst() {
 	}
 }
 
+func initSignalConstants() {
+	for k, v := range signalConstants {
+		SECLConstants[k] = &eval.IntEvaluator{Value: v}
+		signalStrings[v] = k
+	}
+}
+
+func initPTraceConstants() {
+	for k, v := range ptraceConstants {
+		SECLConstants[k] = &eval.IntEvaluator{Value: v}
+		ptraceStrings[v] = k
+	}
+}
+
+func initBPFConstants() {
+	for k, v := range bpfCmdConstants {
+		SECLConstants[k] = &eval.IntEvaluator{Value: v}
+		bpfCmdStrings[v] = k
+	}
+
+	for k, v := range bpfProgramTypeConstants {
+		SECLConstants[k] = &eval.IntEvaluator{Value: v}
+		bpfProgTypeStrings[v] = k
+	}
+}
+
+func initNetworkConstants() {
+	for k, v := range addressFamilyConstants {
+		SECLConstants[k] = &eval.IntEvaluator{Value: v}
+		addressFamilyStrings[v] = k
+	}
+
+	for k, v := range dnsQTypeConstants {
+		SECLConstants[k] = &eval.IntEvaluator{Value: v}
+		dnsQTypeStrings[v] = k
+	}
+
+	for k, v := range dnsQClassConstants {
+		SECLConstants[k] = &eval.IntEvaluator{Value: v}
+		dnsQClassStrings[v] = k
+	}
+}
+
 func initErrorConstants() {
 	for k, v := range errorConstants {
 		SECLConstants[k] = &eval.IntEvaluator{Value: v}
@@ -274,6 +481,10 @@ func initConstants() {
 	initOpenConstants()
 	initChmodConstants()
 	initUnlinkConstanst()
+	initSignalConstants()
+	initPTraceConstants()
+	initBPFConstants()
+	initNetworkConstants()
 }
 
 func bitmaskToStringArray(bitmask int, intToStrMap map[int]string) []string {
@@ -340,6 +551,63 @@ func (f UnlinkFlags) StringArray() []string {
 	return bitmaskToStringArray(int(f), unlinkFlagsStrings)
 }
 
+// valueToString returns the name of a value, or the value itself when it has no name
+func valueToString(value int, intToStrMap map[int]string) string {
+	if str, exists := intToStrMap[value]; exists {
+		return str
+	}
+	return fmt.Sprintf("%d", value)
+}
+
+// Signal represents a signal number
+type Signal int
+
+func (s Signal) String() string {
+	return valueToString(int(s), signalStrings)
+}
+
+// PTraceRequest represents a ptrace request
+type PTraceRequest int
+
+func (r PTraceRequest) String() string {
+	return valueToString(int(r), ptraceStrings)
+}
+
+// BPFCmd represents a bpf command
+type BPFCmd int
+
+func (c BPFCmd) String() string {
+	return valueToString(int(c), bpfCmdStrings)
+}
+
+// BPFProgramType represents a bpf program type
+type BPFProgramType int
+
+func (t BPFProgramType) String() string {
+	return valueToString(int(t), bpfProgTypeStrings)
+}
+
+// AddressFamily represents a socket address family
+type AddressFamily int
+
+func (f AddressFamily) String() string {
+	return valueToString(int(f), addressFamilyStrings)
+}
+
+// QType represents the type of a DNS question
+type QType int
+
+func (t QType) String() string {
+	return valueToString(int(t), dnsQTypeStrings)
+}
+
+// QClass represents the class of a DNS question
+type QClass int
+
+func (c QClass) String() string {
+	return valueToString(int(c), dnsQClassStrings)
+}
+
 // RetValError represents a syscall return error value
 type RetValError int
 
@@ -34,3 +34,21 @@ func TestFlagsToString(t *testing.T) {
 		t.Errorf("expexted flags not found, got: %s", str)
 	}
 }
+
+func TestValueToString(t *testing.T) {
+	if str := Signal(syscall.SIGKILL).String(); str != "SIGKILL" {
+		t.Errorf("expected signal not found, got: %s", str)
+	}
+
+	if str := PTraceRequest(syscall.PTRACE_ATTACH).String(); str != "PTRACE_ATTACH" {
+		t.Errorf("expected ptrace request not found, got: %s", str)
+	}
+
+	if str := BPFCmd(5).String(); str != "BPF_PROG_LOAD" {
+		t.Errorf("expected bpf command not found, got: %s", str)
+	}
+
+	if str := BPFProgramType(12345).String(); str != "12345" {
+		t.Errorf("expected unknown bpf program type, got: %s", str)
+	}
+}
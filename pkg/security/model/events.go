@@ -47,6 +47,20 @@ const (
 	ExitEventType
 	// InvalidateDentryEventType Dentry invalidated event
 	InvalidateDentryEventType
+	// SignalEventType Signal event
+	SignalEventType
+	// PTraceEventType PTrace event
+	PTraceEventType
+	// BPFEventType BPF event
+	BPFEventType
+	// LoadModuleEventType Kernel module load event
+	LoadModuleEventType
+	// ConnectEventType Connect event
+	ConnectEventType
+	// BindEventType Bind event
+	BindEventType
+	// DNSEventType DNS event
+	DNSEventType
 	// MaxEventType is used internally to get the maximum number of kernel events.
 	MaxEventType
 
@@ -70,6 +84,8 @@ const (
 	CustomTruncatedParentsEventType
 	// CustomTruncatedSegmentEventType is the custom event used to report that a segment of a path was truncated
 	CustomTruncatedSegmentEventType
+	// CustomAnomalyDetectedEventType is the custom event used to report a deviation from an activity profile
+	CustomAnomalyDetectedEventType
 )
 
 func (t EventType) String() string {
@@ -108,6 +124,20 @@ func (t EventType) String() string {
 		return "exit"
 	case InvalidateDentryEventType:
 		return "invalidate_dentry"
+	case SignalEventType:
+		return "signal"
+	case PTraceEventType:
+		return "ptrace"
+	case BPFEventType:
+		return "bpf"
+	case LoadModuleEventType:
+		return "load_module"
+	case ConnectEventType:
+		return "connect"
+	case BindEventType:
+		return "bind"
+	case DNSEventType:
+		return "dns"
 
 	case CustomLostReadEventType:
 		return "lost_events_read"
@@ -123,6 +153,8 @@ func (t EventType) String() string {
 		return "truncated_parents"
 	case CustomTruncatedSegmentEventType:
 		return "truncated_segment"
+	case CustomAnomalyDetectedEventType:
+		return "anomaly_detected"
 	default:
 		return "unknown"
 	}
@@ -13,6 +13,7 @@ package model
 import (
 	"bytes"
 	"fmt"
+	"net"
 	"path"
 	"regexp"
 	"strings"
@@ -67,6 +68,17 @@ func (m *Model) ValidateField(key string, field eval.FieldValue) error {
 		}
 	}
 
+	// check that the IP addresses and networks are valid
+	if strings.HasSuffix(key, ".addr.ip") && field.Type != eval.PatternValueType {
+		if value, ok := field.Value.(string); ok {
+			if net.ParseIP(value) == nil {
+				if _, _, err := net.ParseCIDR(value); err != nil {
+					return fmt.Errorf("invalid IP address or network `%s`", value)
+				}
+			}
+		}
+	}
+
 	switch key {
 
 	case "event.retval":
@@ -78,6 +90,27 @@ func (m *Model) ValidateField(key string, field eval.FieldValue) error {
 	return nil
 }
 
+// AddrContext holds a socket address. The IP is empty for the families other than AF_INET and AF_INET6.
+type AddrContext struct {
+	Family uint16 `field:"family"`
+	IP     string `field:"ip"`
+	Port   uint16 `field:"port"`
+}
+
+// BindEvent represents a bind event
+type BindEvent struct {
+	SyscallEvent
+	Addr AddrContext `field:"addr"`
+}
+
+// BPFEvent represents a bpf event
+type BPFEvent struct {
+	SyscallEvent
+	Cmd      uint32 `field:"cmd"`
+	ProgType uint32 `field:"prog_type"`
+	ProgName string `field:"prog_name"`
+}
+
 // ChmodEvent represents a chmod event
 type ChmodEvent struct {
 	SyscallEvent
@@ -93,9 +126,30 @@ type ChownEvent struct {
 	GID int32 `field:"gid"`
 }
 
+// ConnectEvent represents a connect event
+type ConnectEvent struct {
+	SyscallEvent
+	Addr AddrContext `field:"addr"`
+}
+
 // ContainerContext holds the container context of an event
 type ContainerContext struct {
-	ID string `field:"id" handler:"ResolveContainerID,string"`
+	ID    string `field:"id" handler:"ResolveContainerID,string"`
+	Image string `field:"image" handler:"ResolveContainerImage,string"`
+}
+
+// DNSEvent represents a DNS query sent by a process
+type DNSEvent struct {
+	ID       uint16      `field:"id"`
+	Question DNSQuestion `field:"question"`
+}
+
+// DNSQuestion holds the first question of a DNS query
+type DNSQuestion struct {
+	Name  string `field:"name"`
+	Type  uint16 `field:"type"`
+	Class uint16 `field:"class"`
+	Count uint16 `field:"count"`
 }
 
 // Event represents an event sent from the kernel
@@ -122,6 +176,15 @@ type Event struct {
 	RemoveXAttr SetXAttrEvent `field:"removexattr" event:"removexattr"`
 	Exec        ExecEvent     `field:"exec" event:"exec"`
 
+	Signal     SignalEvent     `field:"signal" event:"signal"`
+	PTrace     PTraceEvent     `field:"ptrace" event:"ptrace"`
+	BPF        BPFEvent        `field:"bpf" event:"bpf"`
+	LoadModule LoadModuleEvent `field:"load_module" event:"load_module"`
+
+	Connect ConnectEvent `field:"connect" event:"connect"`
+	Bind    BindEvent    `field:"bind" event:"bind"`
+	DNS     DNSEvent     `field:"dns" event:"dns"`
+
 	Mount            MountEvent            `field:"-"`
 	Umount           UmountEvent           `field:"-"`
 	InvalidateDentry InvalidateDentryEvent `field:"-"`
@@ -172,6 +235,8 @@ type ExecEvent struct {
 	ExitTimestamp uint64    `field:"-"`
 	ExitTime      time.Time `field:"-"`
 
+	CreatedAt uint64 `field:"created_at" handler:"ResolveExecCreatedAt,int"`
+
 	Cookie uint32 `field:"cookie" handler:"ResolveExecCookie,int"`
 	PPid   uint32 `field:"ppid" handler:"ResolveExecPPID,int"`
 
@@ -230,6 +295,13 @@ type LinkEvent struct {
 	Target FileEvent `field:"target"`
 }
 
+// LoadModuleEvent represents a kernel module load event
+type LoadModuleEvent struct {
+	SyscallEvent
+	Name             string `field:"name"`
+	LoadedFromMemory bool   `field:"loaded_from_memory"`
+}
+
 // MkdirEvent represents a mkdir event
 type MkdirEvent struct {
 	SyscallEvent
@@ -336,6 +408,14 @@ type ProcessContext struct {
 	Ancestor *ProcessCacheEntry `field:"ancestors" iterator:"ProcessAncestorsIterator"`
 }
 
+// PTraceEvent represents a ptrace event
+type PTraceEvent struct {
+	SyscallEvent
+	Request uint32 `field:"request"`
+	PID     uint32 `field:"pid"`
+	Address uint64 `field:"-"`
+}
+
 // RenameEvent represents a rename event
 type RenameEvent struct {
 	SyscallEvent
@@ -361,6 +441,13 @@ type SetXAttrEvent struct {
 	NameRaw [200]byte
 }
 
+// SignalEvent represents a signal event
+type SignalEvent struct {
+	SyscallEvent
+	PID  uint32 `field:"pid"`
+	Type uint32 `field:"type"`
+}
+
 // SyscallEvent contains common fields for all the event
 type SyscallEvent struct {
 	Retval int64 `field:"retval"`
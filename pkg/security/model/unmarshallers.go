@@ -9,20 +9,77 @@ package model
 
 import (
 	"bytes"
+	"encoding/binary"
+	"net"
+	"strings"
 	"time"
 	"unsafe"
 
 	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
 )
 
-// ErrNotEnoughData is returned when the buffer is too small to unmarshal the event
-var ErrNotEnoughData = errors.New("not enough data")
+var (
+	// ErrNotEnoughData is returned when the buffer is too small to unmarshal the event
+	ErrNotEnoughData = errors.New("not enough data")
+
+	// ErrDNSNameMalformed is returned when a DNS name can't be decoded
+	ErrDNSNameMalformed = errors.New("malformed DNS name")
+)
+
+// dnsHeaderLength is the length of the header of a DNS message
+const dnsHeaderLength = 12
 
 // BinaryUnmarshaler interface implemented by every event type
 type BinaryUnmarshaler interface {
 	UnmarshalBinary(data []byte) (int, error)
 }
 
+// UnmarshalBinary unmarshals a binary representation of itself
+func (e *AddrContext) UnmarshalBinary(data []byte) (int, error) {
+	if len(data) < 24 {
+		return 0, ErrNotEnoughData
+	}
+
+	e.Family = ByteOrder.Uint16(data[16:18])
+	// the port is in network byte order
+	e.Port = binary.BigEndian.Uint16(data[18:20])
+
+	switch e.Family {
+	case unix.AF_INET:
+		e.IP = net.IP(data[0:4]).String()
+	case unix.AF_INET6:
+		e.IP = net.IP(data[0:16]).String()
+	default:
+		e.IP = ""
+	}
+
+	return 24, nil
+}
+
+// UnmarshalBinary unmarshals a binary representation of itself
+func (e *BindEvent) UnmarshalBinary(data []byte) (int, error) {
+	return UnmarshalBinary(data, &e.SyscallEvent, &e.Addr)
+}
+
+// UnmarshalBinary unmarshals a binary representation of itself
+func (e *BPFEvent) UnmarshalBinary(data []byte) (int, error) {
+	n, err := UnmarshalBinary(data, &e.SyscallEvent)
+	if err != nil {
+		return n, err
+	}
+
+	data = data[n:]
+	if len(data) < 24 {
+		return n, ErrNotEnoughData
+	}
+
+	e.Cmd = ByteOrder.Uint32(data[0:4])
+	e.ProgType = ByteOrder.Uint32(data[4:8])
+	e.ProgName = string(bytes.Trim(data[8:24], "\x00"))
+	return n + 24, nil
+}
+
 // UnmarshalBinary unmarshals a binary representation of itself
 func (e *ChmodEvent) UnmarshalBinary(data []byte) (int, error) {
 	n, err := UnmarshalBinary(data, &e.SyscallEvent, &e.FileEvent)
@@ -56,6 +113,11 @@ func (e *ChownEvent) UnmarshalBinary(data []byte) (int, error) {
 	return n + 8, nil
 }
 
+// UnmarshalBinary unmarshals a binary representation of itself
+func (e *ConnectEvent) UnmarshalBinary(data []byte) (int, error) {
+	return UnmarshalBinary(data, &e.SyscallEvent, &e.Addr)
+}
+
 // UnmarshalBinary unmarshals a binary representation of itself
 func (e *ContainerContext) UnmarshalBinary(data []byte) (int, error) {
 	if len(data) < 64 {
@@ -72,6 +134,74 @@ func (e *ContainerContext) UnmarshalBinary(data []byte) (int, error) {
 	return 64, nil
 }
 
+// UnmarshalBinary unmarshals a binary representation of itself. Only the header and the first question of the
+// DNS query are decoded.
+func (e *DNSEvent) UnmarshalBinary(data []byte) (int, error) {
+	if len(data) < 8+DNSMaxLength {
+		return 0, ErrNotEnoughData
+	}
+
+	size := int(ByteOrder.Uint32(data[0:4]))
+	if size > DNSMaxLength {
+		size = DNSMaxLength
+	}
+	payload := data[8 : 8+size]
+
+	if len(payload) < dnsHeaderLength {
+		return 0, ErrNotEnoughData
+	}
+
+	e.ID = binary.BigEndian.Uint16(payload[0:2])
+	e.Question.Count = binary.BigEndian.Uint16(payload[4:6])
+
+	if e.Question.Count > 0 {
+		name, n, err := decodeDNSName(payload[dnsHeaderLength:])
+		if err != nil {
+			return 0, err
+		}
+		e.Question.Name = name
+
+		question := payload[dnsHeaderLength+n:]
+		if len(question) < 4 {
+			return 0, ErrNotEnoughData
+		}
+		e.Question.Type = binary.BigEndian.Uint16(question[0:2])
+		e.Question.Class = binary.BigEndian.Uint16(question[2:4])
+	}
+
+	return 8 + DNSMaxLength, nil
+}
+
+// decodeDNSName decodes a name made of labels, as found in the questions of DNS queries. It returns the name and the
+// number of bytes read.
+func decodeDNSName(data []byte) (string, int, error) {
+	var labels []string
+
+	i := 0
+	for {
+		if i >= len(data) {
+			return "", 0, ErrNotEnoughData
+		}
+
+		length := int(data[i])
+		i++
+
+		if length == 0 {
+			break
+		}
+
+		// compression pointers are not expected in questions
+		if length&0xc0 != 0 || i+length > len(data) {
+			return "", 0, ErrDNSNameMalformed
+		}
+
+		labels = append(labels, string(data[i:i+length]))
+		i += length
+	}
+
+	return strings.Join(labels, "."), i, nil
+}
+
 // UnmarshalBinary unmarshals a binary representation of itself
 func (e *Event) UnmarshalBinary(data []byte) (int, error) {
 	if len(data) < 24 {
@@ -158,6 +288,23 @@ func (e *LinkEvent) UnmarshalBinary(data []byte) (int, error) {
 	return UnmarshalBinary(data, &e.SyscallEvent, &e.Source, &e.Target)
 }
 
+// UnmarshalBinary unmarshals a binary representation of itself
+func (e *LoadModuleEvent) UnmarshalBinary(data []byte) (int, error) {
+	n, err := UnmarshalBinary(data, &e.SyscallEvent)
+	if err != nil {
+		return n, err
+	}
+
+	data = data[n:]
+	if len(data) < 64 {
+		return n, ErrNotEnoughData
+	}
+
+	e.Name = string(bytes.Trim(data[0:56], "\x00"))
+	e.LoadedFromMemory = ByteOrder.Uint32(data[56:60]) == 1
+	return n + 64, nil
+}
+
 // UnmarshalBinary unmarshals a binary representation of itself
 func (e *MkdirEvent) UnmarshalBinary(data []byte) (int, error) {
 	n, err := UnmarshalBinary(data, &e.SyscallEvent, &e.FileEvent)
@@ -232,6 +379,24 @@ func (p *ProcessContext) UnmarshalBinary(data []byte) (int, error) {
 	return 16, nil
 }
 
+// UnmarshalBinary unmarshals a binary representation of itself
+func (e *PTraceEvent) UnmarshalBinary(data []byte) (int, error) {
+	n, err := UnmarshalBinary(data, &e.SyscallEvent)
+	if err != nil {
+		return n, err
+	}
+
+	data = data[n:]
+	if len(data) < 16 {
+		return n, ErrNotEnoughData
+	}
+
+	e.Request = ByteOrder.Uint32(data[0:4])
+	e.PID = ByteOrder.Uint32(data[4:8])
+	e.Address = ByteOrder.Uint64(data[8:16])
+	return n + 16, nil
+}
+
 // UnmarshalBinary unmarshals a binary representation of itself
 func (e *RenameEvent) UnmarshalBinary(data []byte) (int, error) {
 	n, err := UnmarshalBinary(data, &e.SyscallEvent, &e.Old, &e.New)
@@ -284,6 +449,23 @@ func (e *SetXAttrEvent) UnmarshalBinary(data []byte) (int, error) {
 	return n + 200, nil
 }
 
+// UnmarshalBinary unmarshals a binary representation of itself
+func (e *SignalEvent) UnmarshalBinary(data []byte) (int, error) {
+	n, err := UnmarshalBinary(data, &e.SyscallEvent)
+	if err != nil {
+		return n, err
+	}
+
+	data = data[n:]
+	if len(data) < 8 {
+		return n, ErrNotEnoughData
+	}
+
+	e.PID = ByteOrder.Uint32(data[0:4])
+	e.Type = ByteOrder.Uint32(data[4:8])
+	return n + 8, nil
+}
+
 // UnmarshalBinary unmarshals a binary representation of itself
 func (e *SyscallEvent) UnmarshalBinary(data []byte) (int, error) {
 	if len(data) < 8 {
@@ -0,0 +1,97 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux
+
+package model
+
+import (
+	"testing"
+)
+
+func newDNSEventData(payload []byte) []byte {
+	data := make([]byte, 8+DNSMaxLength)
+	ByteOrder.PutUint32(data[0:4], uint32(len(payload)))
+	copy(data[8:], payload)
+	return data
+}
+
+func TestDNSEventUnmarshalBinary(t *testing.T) {
+	query := []byte{
+		0x12, 0x34, // id
+		0x01, 0x00, // flags
+		0x00, 0x01, // questions
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // answers, authorities, additionals
+		7, 'd', 'a', 't', 'a', 'd', 'o', 'g', 3, 'c', 'o', 'm', 0,
+		0x00, 0x1c, // AAAA
+		0x00, 0x01, // IN
+	}
+
+	var event DNSEvent
+	n, err := event.UnmarshalBinary(newDNSEventData(query))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 8+DNSMaxLength {
+		t.Errorf("expected %d bytes to be read, got %d", 8+DNSMaxLength, n)
+	}
+
+	if event.ID != 0x1234 {
+		t.Errorf("expected id 0x1234, got 0x%x", event.ID)
+	}
+
+	if event.Question.Name != "datadog.com" {
+		t.Errorf("expected name datadog.com, got %s", event.Question.Name)
+	}
+
+	if qtype := QType(event.Question.Type).String(); qtype != "AAAA" {
+		t.Errorf("expected type AAAA, got %s", qtype)
+	}
+
+	if qclass := QClass(event.Question.Class).String(); qclass != "CLASS_INET" {
+		t.Errorf("expected class CLASS_INET, got %s", qclass)
+	}
+
+	if event.Question.Count != 1 {
+		t.Errorf("expected 1 question, got %d", event.Question.Count)
+	}
+
+	// truncated name
+	if _, err := event.UnmarshalBinary(newDNSEventData(query[:16])); err == nil {
+		t.Error("expected an error")
+	}
+
+	// compression pointer
+	malformed := append([]byte{}, query...)
+	malformed[12] = 0xc0
+	if _, err := event.UnmarshalBinary(newDNSEventData(malformed)); err != ErrDNSNameMalformed {
+		t.Errorf("expected %s, got %v", ErrDNSNameMalformed, err)
+	}
+}
+
+func TestAddrContextUnmarshalBinary(t *testing.T) {
+	data := make([]byte, 24)
+	copy(data[0:4], []byte{10, 0, 0, 1})
+	ByteOrder.PutUint16(data[16:18], 2) // AF_INET
+	data[18], data[19] = 0x01, 0xbb     // 443, in network byte order
+
+	var addr AddrContext
+	if _, err := addr.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if addr.IP != "10.0.0.1" {
+		t.Errorf("expected ip 10.0.0.1, got %s", addr.IP)
+	}
+
+	if addr.Port != 443 {
+		t.Errorf("expected port 443, got %d", addr.Port)
+	}
+
+	if family := AddressFamily(addr.Family).String(); family != "AF_INET" {
+		t.Errorf("expected family AF_INET, got %s", family)
+	}
+}
@@ -0,0 +1,150 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux
+
+package module
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-go/statsd"
+
+	"github.com/DataDog/datadog-agent/pkg/security/metrics"
+	"github.com/DataDog/datadog-agent/pkg/security/rules"
+)
+
+// RuleActionsStat represents the statistics of the actions of a rule
+type RuleActionsStat struct {
+	suppressed int64
+}
+
+// RuleActions applies the actions defined by the rules when they match
+type RuleActions struct {
+	sync.Mutex
+	now          func() time.Time
+	suppressed   map[rules.RuleID]time.Time
+	stats        map[rules.RuleID]*RuleActionsStat
+	totals       map[rules.RuleID]*RuleActionsStat
+	statsdClient *statsd.Client
+}
+
+// NewRuleActions returns a new rule actions handler
+func NewRuleActions(client *statsd.Client) *RuleActions {
+	return &RuleActions{
+		now:          time.Now,
+		suppressed:   make(map[rules.RuleID]time.Time),
+		stats:        make(map[rules.RuleID]*RuleActionsStat),
+		totals:       make(map[rules.RuleID]*RuleActionsStat),
+		statsdClient: client,
+	}
+}
+
+// Apply a set of rules. The suppression windows of the previous rules are discarded.
+func (ra *RuleActions) Apply() {
+	ra.Lock()
+	defer ra.Unlock()
+
+	ra.suppressed = make(map[rules.RuleID]time.Time)
+	ra.totals = make(map[rules.RuleID]*RuleActionsStat)
+}
+
+func (ra *RuleActions) getStat(stats map[rules.RuleID]*RuleActionsStat, ruleID rules.RuleID) *RuleActionsStat {
+	stat, exists := stats[ruleID]
+	if !exists {
+		stat = &RuleActionsStat{}
+		stats[ruleID] = stat
+	}
+	return stat
+}
+
+// Match returns whether an event of the rule shall be sent, which is not the case while the rule is suppressed.
+// The `set` actions are applied by the rule set.
+func (ra *RuleActions) Match(rule *rules.Rule) bool {
+	if len(rule.Definition.Actions) == 0 {
+		return true
+	}
+
+	ra.Lock()
+	defer ra.Unlock()
+
+	if until, exists := ra.suppressed[rule.ID]; exists {
+		if ra.now().Before(until) {
+			ra.getStat(ra.stats, rule.ID).suppressed++
+			ra.getStat(ra.totals, rule.ID).suppressed++
+			return false
+		}
+		delete(ra.suppressed, rule.ID)
+	}
+
+	return true
+}
+
+// Sent starts the suppression windows defined by the `suppress` actions of a rule once one of its events was sent
+func (ra *RuleActions) Sent(rule *rules.Rule) {
+	if len(rule.Definition.Actions) == 0 {
+		return
+	}
+
+	ra.Lock()
+	defer ra.Unlock()
+
+	for _, action := range rule.Definition.Actions {
+		if action.Suppress != nil {
+			ra.suppressed[rule.ID] = ra.now().Add(action.Suppress.Duration)
+		}
+	}
+}
+
+// GetStats returns a map indexed by ruleIDs that describes the actions performed since the last call
+func (ra *RuleActions) GetStats() map[rules.RuleID]*RuleActionsStat {
+	ra.Lock()
+	defer ra.Unlock()
+
+	stats := ra.stats
+	ra.stats = make(map[rules.RuleID]*RuleActionsStat)
+	return stats
+}
+
+// GetDebugStats returns, for each rule with actions, the actions performed since the rules were applied
+func (ra *RuleActions) GetDebugStats() map[string]interface{} {
+	ra.Lock()
+	defer ra.Unlock()
+
+	ruleStats := make(map[rules.RuleID]interface{})
+	for ruleID, stat := range ra.totals {
+		ruleStats[ruleID] = map[string]interface{}{
+			"suppressed": stat.suppressed,
+		}
+	}
+
+	suppressed := make(map[string]time.Time)
+	now := ra.now()
+	for ruleID, until := range ra.suppressed {
+		if now.Before(until) {
+			suppressed[ruleID] = until
+		}
+	}
+
+	return map[string]interface{}{
+		"rules":      ruleStats,
+		"suppressed": suppressed,
+	}
+}
+
+// SendStats sends statistics about the actions performed for the set of rules
+func (ra *RuleActions) SendStats() error {
+	for ruleID, stat := range ra.GetStats() {
+		tags := []string{fmt.Sprintf("rule_id:%s", ruleID)}
+		if stat.suppressed > 0 {
+			if err := ra.statsdClient.Count(metrics.MetricActionSuppressed, stat.suppressed, tags, 1.0); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux
+
+package module
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/security/api"
+	"github.com/DataDog/datadog-agent/pkg/security/config"
+	"github.com/DataDog/datadog-agent/pkg/security/model"
+	sprobe "github.com/DataDog/datadog-agent/pkg/security/probe"
+	"github.com/DataDog/datadog-agent/pkg/security/rules"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
+)
+
+// testEvent is a probe event which can be serialized without the resolvers of the probe
+type testEvent struct {
+	*sprobe.Event
+}
+
+func (e testEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{"pid": e.Process.Pid})
+}
+
+func newTestOpenEvent(pid uint32, flags uint32) testEvent {
+	event := sprobe.NewEvent(nil)
+	event.Type = uint64(model.FileOpenEventType)
+	event.Timestamp = time.Now()
+	event.Process.Pid = pid
+	event.Open.Flags = flags
+	return testEvent{Event: event}
+}
+
+// newTestModule returns a module applying the actions of the given rules, without any probe
+func newTestModule(t *testing.T, ruleDefs ...*rules.RuleDefinition) (*Module, *rules.RuleSet) {
+	t.Helper()
+
+	enabled := map[eval.EventType]bool{"*": true}
+	rs := rules.NewRuleSet(&sprobe.Model{}, func() eval.Event { return sprobe.NewEvent(nil) }, rules.NewOptsWithParams(model.SECLConstants, nil, enabled, nil))
+	for _, ruleDef := range ruleDefs {
+		for _, action := range ruleDef.Actions {
+			if err := action.Check(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := rs.AddRules(ruleDefs); err.ErrorOrNil() != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{EventServerBurst: 10, EventServerRate: 10}
+	m := &Module{
+		config:      cfg,
+		apiServer:   NewAPIServer(cfg, nil, nil),
+		rateLimiter: NewRateLimiter(nil),
+		actions:     NewRuleActions(nil),
+		enforcer:    NewEnforcer(cfg, nil, nil),
+	}
+	rs.AddListener(m)
+	m.ruleSets[m.currentRuleSet] = rs
+
+	ruleIDs := rs.ListRuleIDs()
+	m.apiServer.Apply(ruleIDs)
+	m.rateLimiter.Apply(rs, ruleIDs)
+	m.actions.Apply()
+	m.enforcer.Apply(ruleIDs)
+
+	return m, rs
+}
+
+// sentEvents returns the messages sent by the module since the last call
+func sentEvents(m *Module) []*api.SecurityEventMessage {
+	var msgs []*api.SecurityEventMessage
+	for {
+		select {
+		case msg := <-m.apiServer.msgs:
+			msgs = append(msgs, msg)
+		default:
+			return msgs
+		}
+	}
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func TestModuleActions(t *testing.T) {
+	m, rs := newTestModule(t,
+		&rules.RuleDefinition{
+			ID:         "set_opened",
+			Expression: `open.flags == 1`,
+			Actions: []*rules.ActionDefinition{
+				{Set: &rules.SetDefinition{Name: "opened", Value: true, Scope: rules.ProcessScope}},
+			},
+		},
+		&rules.RuleDefinition{
+			ID:         "reopened",
+			Expression: `open.flags == 2 && ${process.opened}`,
+			Actions: []*rules.ActionDefinition{
+				{Report: &rules.ReportDefinition{Severity: "high"}},
+				{Suppress: &rules.SuppressDefinition{Duration: time.Minute}},
+			},
+		},
+	)
+	now := time.Now()
+	m.actions.now = func() time.Time { return now }
+
+	t.Run("set", func(t *testing.T) {
+		// the variable isn't set yet
+		if rs.Evaluate(newTestOpenEvent(42, 2)) {
+			t.Fatal("the rule reading the variable shouldn't match before it is set")
+		}
+
+		rs.Evaluate(newTestOpenEvent(42, 1))
+		msgs := sentEvents(m)
+		if len(msgs) != 1 || msgs[0].RuleID != "set_opened" {
+			t.Fatalf("expected an event of the set_opened rule, got %+v", msgs)
+		}
+
+		// the variable is attached to the process which triggered the event
+		if rs.Evaluate(newTestOpenEvent(43, 2)) {
+			t.Error("the variable of a process shouldn't be visible from another process")
+		}
+	})
+
+	t.Run("report", func(t *testing.T) {
+		if !rs.Evaluate(newTestOpenEvent(42, 2)) {
+			t.Fatal("the rule reading the variable should match once it is set")
+		}
+		msgs := sentEvents(m)
+		if len(msgs) != 1 || msgs[0].RuleID != "reopened" {
+			t.Fatalf("expected an event of the reopened rule, got %+v", msgs)
+		}
+		if !hasTag(msgs[0].Tags, "severity:high") {
+			t.Errorf("expected the severity of the report action in the tags, got %v", msgs[0].Tags)
+		}
+	})
+
+	t.Run("suppress", func(t *testing.T) {
+		rs.Evaluate(newTestOpenEvent(42, 2))
+		if msgs := sentEvents(m); len(msgs) != 0 {
+			t.Fatalf("expected the event to be suppressed, got %+v", msgs)
+		}
+		if stat := m.actions.GetStats()["reopened"]; stat == nil || stat.suppressed != 1 {
+			t.Errorf("expected 1 suppressed event, got %+v", stat)
+		}
+
+		// the rules without a suppress action are not suppressed
+		rs.Evaluate(newTestOpenEvent(42, 1))
+		if msgs := sentEvents(m); len(msgs) != 1 {
+			t.Errorf("expected the event of the set_opened rule to be sent, got %+v", msgs)
+		}
+
+		now = now.Add(time.Minute)
+		rs.Evaluate(newTestOpenEvent(42, 2))
+		if msgs := sentEvents(m); len(msgs) != 1 {
+			t.Errorf("expected the event to be sent once the suppression window is over, got %+v", msgs)
+		}
+	})
+}
+
+func TestModuleReleasesProcessVariables(t *testing.T) {
+	m, rs := newTestModule(t,
+		&rules.RuleDefinition{
+			ID:         "set_opened",
+			Expression: `open.flags == 1`,
+			Actions: []*rules.ActionDefinition{
+				{Set: &rules.SetDefinition{Name: "opened", Value: true, Scope: rules.ProcessScope}},
+			},
+		},
+		&rules.RuleDefinition{
+			ID:         "reopened",
+			Expression: `open.flags == 2 && ${process.opened}`,
+		},
+	)
+
+	rs.Evaluate(newTestOpenEvent(42, 1))
+	if !rs.Evaluate(newTestOpenEvent(42, 2)) {
+		t.Fatal("the rule reading the variable should match once it is set")
+	}
+
+	exit := sprobe.NewEvent(nil)
+	exit.Type = uint64(model.ExitEventType)
+	exit.Process.Pid = 42
+	m.HandleEvent(exit)
+
+	// a new process reusing the pid doesn't inherit the variables of the exited one
+	if rs.Evaluate(newTestOpenEvent(42, 2)) {
+		t.Error("the variables of a process should be released when it exits")
+	}
+}
+
+func TestRuleActionsApply(t *testing.T) {
+	ra := NewRuleActions(nil)
+	rule := &rules.Rule{
+		Rule: &eval.Rule{ID: "suppressed"},
+		Definition: &rules.RuleDefinition{
+			ID:      "suppressed",
+			Actions: []*rules.ActionDefinition{{Suppress: &rules.SuppressDefinition{Duration: time.Hour}}},
+		},
+	}
+
+	ra.Sent(rule)
+	if ra.Match(rule) {
+		t.Fatal("expected the rule to be suppressed")
+	}
+
+	// loading new rules discards the suppression windows
+	ra.Apply()
+	if !ra.Match(rule) {
+		t.Error("expected the suppression window to be discarded by Apply")
+	}
+}
@@ -0,0 +1,257 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux
+
+package module
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-go/statsd"
+
+	"github.com/DataDog/datadog-agent/pkg/security/activitydump"
+	"github.com/DataDog/datadog-agent/pkg/security/config"
+	"github.com/DataDog/datadog-agent/pkg/security/metrics"
+	"github.com/DataDog/datadog-agent/pkg/security/model"
+	sprobe "github.com/DataDog/datadog-agent/pkg/security/probe"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const activityDumpPersistPeriod = time.Minute
+
+// ActivityDumps learns the activity of the container images and reports the deviations from the learned profiles
+type ActivityDumps struct {
+	sync.RWMutex
+	config       *config.Config
+	store        *activitydump.Store
+	profiles     map[string]*activitydump.Profile
+	persisted    map[string]bool
+	anomalies    map[activitydump.AnomalyType]int64
+	totals       map[activitydump.AnomalyType]int64
+	statsdClient *statsd.Client
+}
+
+// NewActivityDumps returns a new activity dumps handler, loading the profiles previously stored
+func NewActivityDumps(cfg *config.Config, client *statsd.Client) (*ActivityDumps, error) {
+	store, err := activitydump.NewStore(cfg.ActivityDumpDir)
+	if err != nil {
+		return nil, err
+	}
+
+	ad := &ActivityDumps{
+		config:       cfg,
+		store:        store,
+		profiles:     make(map[string]*activitydump.Profile),
+		persisted:    make(map[string]bool),
+		anomalies:    make(map[activitydump.AnomalyType]int64),
+		totals:       make(map[activitydump.AnomalyType]int64),
+		statsdClient: client,
+	}
+
+	profiles, loadErr := store.Load()
+	if loadErr.ErrorOrNil() != nil {
+		log.Errorf("error while loading activity profiles: %s", loadErr)
+	}
+
+	now := time.Now()
+	for _, p := range profiles {
+		ad.profiles[p.Image] = p
+		ad.persisted[p.Image] = !p.IsLearning(now)
+	}
+
+	return ad, nil
+}
+
+// getProfile returns the profile of an image, starting a new learning window for the images seen for the first time
+func (ad *ActivityDumps) getProfile(image string, now time.Time) *activitydump.Profile {
+	ad.RLock()
+	p, exists := ad.profiles[image]
+	ad.RUnlock()
+
+	if exists {
+		return p
+	}
+
+	ad.Lock()
+	defer ad.Unlock()
+
+	if p, exists = ad.profiles[image]; !exists {
+		p = activitydump.NewProfile(image, now, ad.config.ActivityDumpLearningWindow)
+		ad.profiles[image] = p
+	}
+	return p
+}
+
+// IsDumped returns whether the event is recorded by the activity dumps, or checked against the profile of its
+// container image. The discarders of such an event would hide the activity of the container from the profile.
+func (ad *ActivityDumps) IsDumped(event *sprobe.Event) bool {
+	switch model.EventType(event.Type) {
+	case model.ExecEventType, model.FileOpenEventType:
+	default:
+		return false
+	}
+
+	return len(event.ResolveContainerImage(&event.Container)) > 0
+}
+
+// ProcessEvent records the event in the profile of its container image while the profile is learning, and returns
+// the deviation from the profile, if any, once the learning window is over
+func (ad *ActivityDumps) ProcessEvent(event *sprobe.Event) *activitydump.Anomaly {
+	var exec bool
+	switch model.EventType(event.Type) {
+	case model.ExecEventType:
+		exec = true
+	case model.FileOpenEventType:
+	default:
+		return nil
+	}
+
+	image := event.ResolveContainerImage(&event.Container)
+	if len(image) == 0 {
+		return nil
+	}
+
+	now := event.ResolveEventTimestamp()
+	p := ad.getProfile(image, now)
+
+	var anomaly *activitydump.Anomaly
+	if exec {
+		entry := event.ResolveProcessCacheEntry()
+
+		// the ancestor of an exec entry is the entry of the process before the exec, holding the parent binary
+		var parent string
+		if entry.Ancestor != nil {
+			parent = entry.Ancestor.PathnameStr
+		}
+
+		if p.IsLearning(now) {
+			p.AddExec(parent, entry.PathnameStr)
+		} else {
+			anomaly = p.CheckExec(parent, entry.PathnameStr)
+		}
+	} else {
+		filename := event.ResolveFileInode(&event.Open.FileEvent)
+		if p.IsLearning(now) {
+			p.AddFile(filename, ad.config.ActivityDumpMaxFiles)
+		} else {
+			anomaly = p.CheckFile(filename)
+		}
+	}
+
+	if anomaly != nil {
+		ad.Lock()
+		ad.anomalies[anomaly.Type]++
+		ad.totals[anomaly.Type]++
+		ad.Unlock()
+	}
+
+	return anomaly
+}
+
+// persist writes the profiles to the store. Unless all is set, only the profiles whose learning window just ended
+// are written.
+func (ad *ActivityDumps) persist(all bool) {
+	ad.Lock()
+	defer ad.Unlock()
+
+	now := time.Now()
+	for image, p := range ad.profiles {
+		learning := p.IsLearning(now)
+		if ad.persisted[image] || (learning && !all) {
+			continue
+		}
+
+		if err := ad.store.Save(p); err != nil {
+			log.Errorf("failed to persist the activity profile of `%s`: %s", image, err)
+			continue
+		}
+
+		if !learning {
+			log.Infof("activity profile of `%s` written to %s", image, ad.config.ActivityDumpDir)
+			ad.persisted[image] = true
+		}
+	}
+}
+
+// Start persists the profiles as their learning windows end, until the context is done
+func (ad *ActivityDumps) Start(ctx context.Context) {
+	ticker := time.NewTicker(activityDumpPersistPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ad.persist(false)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Close persists all the profiles, including the ones still learning so that their learning resumes on restart
+func (ad *ActivityDumps) Close() {
+	ad.persist(true)
+}
+
+// GetDebugStats returns the learning state of the profiles and the number of anomalies detected
+func (ad *ActivityDumps) GetDebugStats() map[string]interface{} {
+	ad.RLock()
+	defer ad.RUnlock()
+
+	now := time.Now()
+	profiles := make(map[string]interface{})
+	for image, p := range ad.profiles {
+		profiles[image] = map[string]interface{}{
+			"learning":     p.IsLearning(now),
+			"learning_end": p.LearningEnd,
+		}
+	}
+
+	anomalies := make(map[activitydump.AnomalyType]int64)
+	for anomalyType, count := range ad.totals {
+		anomalies[anomalyType] = count
+	}
+
+	return map[string]interface{}{
+		"profiles":  profiles,
+		"anomalies": anomalies,
+	}
+}
+
+// SendStats sends the number of profiles and the anomalies detected since the last call
+func (ad *ActivityDumps) SendStats() error {
+	ad.Lock()
+	defer ad.Unlock()
+
+	now := time.Now()
+	var learning, learned int64
+	for _, p := range ad.profiles {
+		if p.IsLearning(now) {
+			learning++
+		} else {
+			learned++
+		}
+	}
+
+	if err := ad.statsdClient.Gauge(metrics.MetricActivityDumpProfiles, float64(learning), []string{"learning:true"}, 1.0); err != nil {
+		return err
+	}
+	if err := ad.statsdClient.Gauge(metrics.MetricActivityDumpProfiles, float64(learned), []string{"learning:false"}, 1.0); err != nil {
+		return err
+	}
+
+	for anomalyType, count := range ad.anomalies {
+		if err := ad.statsdClient.Count(metrics.MetricActivityDumpAnomalies, count, []string{fmt.Sprintf("anomaly:%s", anomalyType)}, 1.0); err != nil {
+			return err
+		}
+	}
+	ad.anomalies = make(map[activitydump.AnomalyType]int64)
+
+	return nil
+}
@@ -0,0 +1,75 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux
+
+package module
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/security/activitydump"
+	"github.com/DataDog/datadog-agent/pkg/security/config"
+	"github.com/DataDog/datadog-agent/pkg/security/model"
+	sprobe "github.com/DataDog/datadog-agent/pkg/security/probe"
+)
+
+func newOpenEvent(image, path string, timestamp time.Time) *sprobe.Event {
+	event := sprobe.NewEvent(nil)
+	event.Type = uint64(model.FileOpenEventType)
+	event.Timestamp = timestamp
+	event.Container.Image = image
+	event.Open.PathnameStr = path
+	return event
+}
+
+func TestActivityDumpsOpen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "activity-dumps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ad, err := NewActivityDumps(&config.Config{
+		ActivityDumpDir:            dir,
+		ActivityDumpLearningWindow: time.Minute,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	if anomaly := ad.ProcessEvent(newOpenEvent("nginx:1.19", "/etc/nginx/nginx.conf", now)); anomaly != nil {
+		t.Fatalf("unexpected anomaly while learning: %+v", anomaly)
+	}
+
+	after := now.Add(2 * time.Minute)
+	if anomaly := ad.ProcessEvent(newOpenEvent("nginx:1.19", "/etc/nginx/nginx.conf", after)); anomaly != nil {
+		t.Errorf("unexpected anomaly for a learned file: %+v", anomaly)
+	}
+
+	anomaly := ad.ProcessEvent(newOpenEvent("nginx:1.19", "/etc/shadow", after))
+	if anomaly == nil || anomaly.Type != activitydump.UnknownFileAnomaly || anomaly.Value != "/etc/shadow" {
+		t.Errorf("expected an unknown file anomaly for /etc/shadow, got %+v", anomaly)
+	}
+}
+
+func TestActivityDumpsIsDumped(t *testing.T) {
+	ad := &ActivityDumps{}
+
+	if !ad.IsDumped(newOpenEvent("nginx:1.19", "/etc/nginx/nginx.conf", time.Now())) {
+		t.Error("the open events of a container should be dumped")
+	}
+
+	mkdir := sprobe.NewEvent(nil)
+	mkdir.Type = uint64(model.FileMkdirEventType)
+	mkdir.Container.Image = "nginx:1.19"
+	if ad.IsDumped(mkdir) {
+		t.Error("the mkdir events aren't dumped")
+	}
+}
@@ -0,0 +1,236 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux
+
+package module
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-go/statsd"
+	"golang.org/x/sys/unix"
+	"golang.org/x/time/rate"
+
+	"github.com/DataDog/datadog-agent/pkg/security/config"
+	"github.com/DataDog/datadog-agent/pkg/security/metrics"
+	"github.com/DataDog/datadog-agent/pkg/security/model"
+	sprobe "github.com/DataDog/datadog-agent/pkg/security/probe"
+	"github.com/DataDog/datadog-agent/pkg/security/rules"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// EnforcementPerformed is the status of an action that was enforced
+	EnforcementPerformed = "performed"
+	// EnforcementDryRun is the status of an action that would have been enforced without the dry-run mode
+	EnforcementDryRun = "dry_run"
+	// EnforcementDisabled is the status of an action that wasn't enforced because enforcement is disabled
+	EnforcementDisabled = "disabled"
+	// EnforcementNotAllowed is the status of an action whose rule isn't in the allowlist
+	EnforcementNotAllowed = "not_allowed"
+	// EnforcementRateLimited is the status of an action that wasn't enforced because the rule reached its cap
+	EnforcementRateLimited = "rate_limited"
+	// EnforcementProtected is the status of an action targeting a process that can't be killed
+	EnforcementProtected = "protected"
+	// EnforcementProcessExited is the status of an action targeting a process that exited since the event, its pid
+	// being either unused or reused by another process
+	EnforcementProcessExited = "process_exited"
+	// EnforcementFailed is the status of an action that failed
+	EnforcementFailed = "failed"
+)
+
+var killSignals = map[string]unix.Signal{
+	"SIGKILL": unix.SIGKILL,
+	"SIGTERM": unix.SIGTERM,
+}
+
+// processCache gives access to the current entries of the process cache
+type processCache interface {
+	Get(pid uint32) *model.ProcessCacheEntry
+}
+
+// Enforcer enforces the `kill` actions of the rules. Only the rules of the allowlist are enforced, up to a number of
+// processes per rule and per minute.
+type Enforcer struct {
+	sync.Mutex
+	config       *config.Config
+	allowed      map[rules.RuleID]bool
+	limiters     map[rules.RuleID]*rate.Limiter
+	stats        map[rules.RuleID]map[string]int64
+	totals       map[rules.RuleID]map[string]int64
+	processes    processCache
+	statsdClient *statsd.Client
+}
+
+// NewEnforcer returns a new enforcer. The process cache is used to make sure that the pid of an event still belongs
+// to the process that triggered it before sending the signal.
+func NewEnforcer(cfg *config.Config, client *statsd.Client, processes processCache) *Enforcer {
+	e := &Enforcer{
+		config:       cfg,
+		processes:    processes,
+		allowed:      make(map[rules.RuleID]bool),
+		limiters:     make(map[rules.RuleID]*rate.Limiter),
+		stats:        make(map[rules.RuleID]map[string]int64),
+		totals:       make(map[rules.RuleID]map[string]int64),
+		statsdClient: client,
+	}
+
+	if cfg != nil {
+		for _, id := range cfg.EnforcementRuleIDs {
+			e.allowed[id] = true
+		}
+	}
+
+	return e
+}
+
+func (e *Enforcer) getLimiter(ruleID rules.RuleID) *rate.Limiter {
+	limiter, exists := e.limiters[ruleID]
+	if !exists {
+		maxPerMinute := e.config.EnforcementMaxPerMinute
+		if maxPerMinute <= 0 {
+			maxPerMinute = 1
+		}
+		limiter = rate.NewLimiter(rate.Every(time.Minute/time.Duration(maxPerMinute)), maxPerMinute)
+		e.limiters[ruleID] = limiter
+	}
+	return limiter
+}
+
+// isEventProcess returns whether the pid of the event still belongs to the process that triggered the event. The pid
+// may have been reused since the event was generated, in which case the start time of the process found in the cache
+// differs from the one of the event.
+func (e *Enforcer) isEventProcess(event *sprobe.Event) bool {
+	if e.processes == nil {
+		return true
+	}
+
+	current := e.processes.Get(event.Process.Pid)
+	if current == nil || !current.ExitTime.IsZero() {
+		return false
+	}
+	return current.ForkTime.Equal(event.ResolveProcessCacheEntry().ForkTime)
+}
+
+func (e *Enforcer) count(ruleID rules.RuleID, status string) {
+	for _, stats := range []map[rules.RuleID]map[string]int64{e.stats, e.totals} {
+		ruleStats, exists := stats[ruleID]
+		if !exists {
+			ruleStats = make(map[string]int64)
+			stats[ruleID] = ruleStats
+		}
+		ruleStats[status]++
+	}
+}
+
+// Enforce applies the `kill` action of the rule to the process that triggered the event and returns the outcome. It
+// returns nil when the rule has no `kill` action or when the event wasn't triggered by a process.
+func (e *Enforcer) Enforce(rule *rules.Rule, event Event) *EnforcementReport {
+	kill := rule.Definition.GetKillAction()
+	if kill == nil {
+		return nil
+	}
+
+	probeEvent, ok := event.(*sprobe.Event)
+	if !ok {
+		return nil
+	}
+
+	report := &EnforcementReport{
+		Action: "kill",
+		Signal: kill.Signal,
+		Pid:    probeEvent.Process.Pid,
+	}
+
+	e.Lock()
+	defer e.Unlock()
+
+	switch {
+	case e.config == nil || !e.config.EnforcementEnabled:
+		report.Status = EnforcementDisabled
+	case !e.allowed[rule.ID]:
+		report.Status = EnforcementNotAllowed
+	case report.Pid <= 1 || int(report.Pid) == os.Getpid():
+		report.Status = EnforcementProtected
+	case !e.isEventProcess(probeEvent):
+		report.Status = EnforcementProcessExited
+	case !e.getLimiter(rule.ID).Allow():
+		report.Status = EnforcementRateLimited
+	case e.config.EnforcementDryRun:
+		report.Status = EnforcementDryRun
+	default:
+		if err := unix.Kill(int(report.Pid), killSignals[kill.Signal]); err != nil {
+			report.Status = EnforcementFailed
+			report.Error = err.Error()
+		} else {
+			report.Status = EnforcementPerformed
+		}
+	}
+
+	if report.Status == EnforcementPerformed || report.Status == EnforcementFailed {
+		log.Infof("kill action of rule `%s` on pid %d: %s %s", rule.ID, report.Pid, report.Status, report.Error)
+	}
+
+	e.count(rule.ID, report.Status)
+
+	return report
+}
+
+// Apply a set of rules. The caps of the rules that are no longer loaded are discarded.
+func (e *Enforcer) Apply(ruleIDs []rules.RuleID) {
+	e.Lock()
+	defer e.Unlock()
+
+	limiters := make(map[rules.RuleID]*rate.Limiter)
+	for _, id := range ruleIDs {
+		if limiter, exists := e.limiters[id]; exists {
+			limiters[id] = limiter
+		}
+	}
+	e.limiters = limiters
+}
+
+// GetDebugStats returns, for each rule, the number of `kill` actions per status since the start of the module
+func (e *Enforcer) GetDebugStats() map[string]interface{} {
+	e.Lock()
+	defer e.Unlock()
+
+	ruleStats := make(map[rules.RuleID]interface{})
+	for ruleID, stats := range e.totals {
+		statuses := make(map[string]int64)
+		for status, count := range stats {
+			statuses[status] = count
+		}
+		ruleStats[ruleID] = statuses
+	}
+
+	return map[string]interface{}{
+		"enabled": e.config != nil && e.config.EnforcementEnabled,
+		"dry_run": e.config != nil && e.config.EnforcementDryRun,
+		"rules":   ruleStats,
+	}
+}
+
+// SendStats sends the number of `kill` actions per rule and per status since the last call
+func (e *Enforcer) SendStats() error {
+	e.Lock()
+	stats := e.stats
+	e.stats = make(map[rules.RuleID]map[string]int64)
+	e.Unlock()
+
+	for ruleID, statuses := range stats {
+		for status, count := range statuses {
+			tags := []string{fmt.Sprintf("rule_id:%s", ruleID), fmt.Sprintf("status:%s", status)}
+			if err := e.statsdClient.Count(metrics.MetricActionKill, count, tags, 1.0); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux
+
+package module
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/security/config"
+	"github.com/DataDog/datadog-agent/pkg/security/model"
+	sprobe "github.com/DataDog/datadog-agent/pkg/security/probe"
+	"github.com/DataDog/datadog-agent/pkg/security/rules"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
+)
+
+// unusedPid is above the maximum pid of the kernel, so that no process is killed by the tests
+const unusedPid = 0x7ffffff0
+
+func newKillRule(id rules.RuleID) *rules.Rule {
+	return &rules.Rule{
+		Rule: &eval.Rule{ID: id},
+		Definition: &rules.RuleDefinition{
+			ID:      id,
+			Actions: []*rules.ActionDefinition{{Kill: &rules.KillDefinition{Signal: "SIGKILL"}}},
+		},
+	}
+}
+
+// newTestProcessResolver returns a process resolver holding an entry, forked at the given time, for each pid
+func newTestProcessResolver(t *testing.T, forkTime time.Time, pids ...uint32) *sprobe.ProcessResolver {
+	t.Helper()
+
+	resolver, err := sprobe.NewProcessResolver(nil, nil, nil, sprobe.NewProcessResolverOpts(false, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pid := range pids {
+		entry := &model.ProcessCacheEntry{}
+		entry.Pid = pid
+		entry.ForkTime = forkTime
+		resolver.AddForkEntry(pid, entry)
+	}
+	return resolver
+}
+
+func newTestKillEvent(resolver *sprobe.ProcessResolver, pid uint32) *sprobe.Event {
+	event := sprobe.NewEvent(&sprobe.Resolvers{ProcessResolver: resolver})
+	event.Process.Pid = pid
+	// resolve the process of the event when it is generated, like the probe does
+	event.ResolveProcessCacheEntry()
+	return event
+}
+
+func TestEnforcer(t *testing.T) {
+	forkTime := time.Now()
+	resolver := newTestProcessResolver(t, forkTime, unusedPid, 0, 1, uint32(os.Getpid()))
+
+	cfg := &config.Config{
+		EnforcementEnabled:      true,
+		EnforcementRuleIDs:      []string{"allowed"},
+		EnforcementMaxPerMinute: 2,
+		EnforcementDryRun:       true,
+	}
+	allowed := newKillRule("allowed")
+
+	t.Run("disabled", func(t *testing.T) {
+		e := NewEnforcer(&config.Config{EnforcementRuleIDs: []string{"allowed"}}, nil, resolver)
+		if report := e.Enforce(allowed, newTestKillEvent(resolver, unusedPid)); report.Status != EnforcementDisabled {
+			t.Errorf("expected the enforcement to be disabled, got %+v", report)
+		}
+	})
+
+	t.Run("no-kill-action", func(t *testing.T) {
+		e := NewEnforcer(cfg, nil, resolver)
+		rule := &rules.Rule{Rule: &eval.Rule{ID: "allowed"}, Definition: &rules.RuleDefinition{ID: "allowed"}}
+		if report := e.Enforce(rule, newTestKillEvent(resolver, unusedPid)); report != nil {
+			t.Errorf("expected no report for a rule without kill action, got %+v", report)
+		}
+	})
+
+	t.Run("allowlist", func(t *testing.T) {
+		e := NewEnforcer(cfg, nil, resolver)
+		if report := e.Enforce(newKillRule("other"), newTestKillEvent(resolver, unusedPid)); report.Status != EnforcementNotAllowed {
+			t.Errorf("expected a rule outside of the allowlist not to be enforced, got %+v", report)
+		}
+	})
+
+	t.Run("protected", func(t *testing.T) {
+		e := NewEnforcer(cfg, nil, resolver)
+		for _, pid := range []uint32{0, 1, uint32(os.Getpid())} {
+			if report := e.Enforce(allowed, newTestKillEvent(resolver, pid)); report.Status != EnforcementProtected {
+				t.Errorf("expected pid %d to be protected, got %+v", pid, report)
+			}
+		}
+	})
+
+	t.Run("dry-run", func(t *testing.T) {
+		e := NewEnforcer(cfg, nil, resolver)
+		report := e.Enforce(allowed, newTestKillEvent(resolver, unusedPid))
+		if report.Status != EnforcementDryRun || report.Pid != unusedPid || report.Signal != "SIGKILL" {
+			t.Errorf("expected a dry-run report, got %+v", report)
+		}
+	})
+
+	t.Run("rate-limit", func(t *testing.T) {
+		e := NewEnforcer(cfg, nil, resolver)
+		for i := 0; i < cfg.EnforcementMaxPerMinute; i++ {
+			if report := e.Enforce(allowed, newTestKillEvent(resolver, unusedPid)); report.Status != EnforcementDryRun {
+				t.Fatalf("expected action %d to be allowed by the cap, got %+v", i, report)
+			}
+		}
+		if report := e.Enforce(allowed, newTestKillEvent(resolver, unusedPid)); report.Status != EnforcementRateLimited {
+			t.Errorf("expected the action to be capped, got %+v", report)
+		}
+
+		// the cap of a rule that is loaded again is kept
+		e.Apply([]rules.RuleID{"allowed"})
+		if report := e.Enforce(allowed, newTestKillEvent(resolver, unusedPid)); report.Status != EnforcementRateLimited {
+			t.Errorf("expected the cap to be kept, got %+v", report)
+		}
+
+		stats := e.GetDebugStats()["rules"].(map[rules.RuleID]interface{})["allowed"].(map[string]int64)
+		if stats[EnforcementDryRun] != int64(cfg.EnforcementMaxPerMinute) || stats[EnforcementRateLimited] != 2 {
+			t.Errorf("unexpected stats: %+v", stats)
+		}
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		performCfg := *cfg
+		performCfg.EnforcementDryRun = false
+		e := NewEnforcer(&performCfg, nil, resolver)
+		if report := e.Enforce(allowed, newTestKillEvent(resolver, unusedPid)); report.Status != EnforcementFailed || report.Error == "" {
+			t.Errorf("expected the kill of an unused pid to fail, got %+v", report)
+		}
+	})
+}
+
+func TestEnforcerProcessExited(t *testing.T) {
+	forkTime := time.Now()
+	resolver := newTestProcessResolver(t, forkTime, unusedPid)
+
+	cfg := &config.Config{
+		EnforcementEnabled:      true,
+		EnforcementRuleIDs:      []string{"allowed"},
+		EnforcementMaxPerMinute: 10,
+		EnforcementDryRun:       true,
+	}
+	e := NewEnforcer(cfg, nil, resolver)
+	allowed := newKillRule("allowed")
+
+	event := newTestKillEvent(resolver, unusedPid)
+
+	// the pid is reused by a process forked after the event
+	reused := &model.ProcessCacheEntry{}
+	reused.Pid = unusedPid
+	reused.ForkTime = forkTime.Add(time.Second)
+	resolver.AddForkEntry(unusedPid, reused)
+
+	if report := e.Enforce(allowed, event); report.Status != EnforcementProcessExited {
+		t.Errorf("expected the process reusing the pid not to be killed, got %+v", report)
+	}
+
+	// the process of the event exited
+	reused.Exit(forkTime.Add(2 * time.Second))
+	if report := e.Enforce(allowed, newTestKillEvent(resolver, unusedPid)); report.Status != EnforcementProcessExited {
+		t.Errorf("expected an exited process not to be killed, got %+v", report)
+	}
+
+	// the pid isn't used anymore
+	e = NewEnforcer(cfg, nil, newTestProcessResolver(t, forkTime))
+	if report := e.Enforce(allowed, newTestKillEvent(resolver, unusedPid)); report.Status != EnforcementProcessExited {
+		t.Errorf("expected an unknown process not to be killed, got %+v", report)
+	}
+}
@@ -10,9 +10,21 @@ package module
 // AgentContext serializes the agent context to JSON
 // easyjson:json
 type AgentContext struct {
-	RuleID        string `json:"rule_id"`
-	PolicyName    string `json:"policy_name"`
-	PolicyVersion string `json:"policy_version"`
+	RuleID        string             `json:"rule_id"`
+	PolicyName    string             `json:"policy_name"`
+	PolicyVersion string             `json:"policy_version"`
+	Severity      string             `json:"severity,omitempty"`
+	Enforcement   *EnforcementReport `json:"enforcement,omitempty"`
+}
+
+// EnforcementReport describes the outcome of the enforcement action of a rule
+// easyjson:json
+type EnforcementReport struct {
+	Action string `json:"action"`
+	Signal string `json:"signal,omitempty"`
+	Pid    uint32 `json:"pid"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
 }
 
 // Signal - Rule event wrapper used to send an event to the backend
@@ -51,6 +51,9 @@ type Module struct {
 	grpcServer     *grpc.Server
 	listener       net.Listener
 	rateLimiter    *RateLimiter
+	actions        *RuleActions
+	enforcer       *Enforcer
+	activityDumps  *ActivityDumps
 	sigupChan      chan os.Signal
 	ctx            context.Context
 	cancelFnc      context.CancelFunc
@@ -102,6 +105,10 @@ func (m *Module) Register(httpMux *http.ServeMux) error {
 
 	go m.metricsSender()
 
+	if m.activityDumps != nil {
+		go m.activityDumps.Start(m.ctx)
+	}
+
 	signal.Notify(m.sigupChan, syscall.SIGHUP)
 
 	go func() {
@@ -177,7 +184,9 @@ func (m *Module) Reload() error {
 	ruleIDs = append(ruleIDs, sprobe.AllCustomRuleIDs()...)
 
 	m.apiServer.Apply(ruleIDs)
-	m.rateLimiter.Apply(ruleIDs)
+	m.rateLimiter.Apply(ruleSet, ruleIDs)
+	m.actions.Apply()
+	m.enforcer.Apply(ruleIDs)
 
 	atomic.StoreUint64(&m.currentRuleSet, 1-m.currentRuleSet)
 	m.ruleSets[m.currentRuleSet] = ruleSet
@@ -205,6 +214,12 @@ func (m *Module) Close() {
 	}
 
 	m.probe.Close()
+
+	m.cancelFnc()
+
+	if m.activityDumps != nil {
+		m.activityDumps.Close()
+	}
 }
 
 // EventDiscarderFound is called by the ruleset when a new discarder discovered
@@ -213,6 +228,11 @@ func (m *Module) EventDiscarderFound(rs *rules.RuleSet, event eval.Event, field
 		return
 	}
 
+	// the activity of the dumped containers is recorded regardless of the rules
+	if m.activityDumps != nil && m.activityDumps.IsDumped(event.(*probe.Event)) {
+		return
+	}
+
 	if err := m.probe.OnNewDiscarder(rs, event.(*probe.Event), field, eventType); err != nil {
 		log.Trace(err)
 	}
@@ -222,6 +242,17 @@ func (m *Module) EventDiscarderFound(rs *rules.RuleSet, event eval.Event, field
 func (m *Module) HandleEvent(event *sprobe.Event) {
 	if ruleSet := m.ruleSets[atomic.LoadUint64(&m.currentRuleSet)]; ruleSet != nil {
 		ruleSet.Evaluate(event)
+
+		// the pid of an exited process can be reused, the new process mustn't inherit its variables
+		if event.GetEventType() == model.ExitEventType {
+			ruleSet.ReleaseVariables(event, rules.ProcessScope)
+		}
+	}
+
+	if m.activityDumps != nil {
+		if anomaly := m.activityDumps.ProcessEvent(event); anomaly != nil {
+			m.SendEvent(sprobe.NewAnomalyDetectedEvent(event, anomaly))
+		}
 	}
 }
 
@@ -235,10 +266,20 @@ func (m *Module) RuleMatch(rule *rules.Rule, event eval.Event) {
 	m.SendEvent(rule, event)
 }
 
-// SendEvent sends an event to the backend after checking that the rate limiter allows it for the provided rule
+// SendEvent sends an event to the backend after applying the actions of the provided rule and checking that the
+// rate limiter allows it. The outcome of the enforcement action of the rule, if any, is carried by the event.
 func (m *Module) SendEvent(rule *rules.Rule, event Event) {
+	// the enforcement doesn't depend on the suppression or the rate limiting of the events
+	enforcement := m.enforcer.Enforce(rule, event)
+
+	if !m.actions.Match(rule) {
+		log.Tracef("Event on rule %s was suppressed", rule.ID)
+		return
+	}
+
 	if m.rateLimiter.Allow(rule.ID) {
-		m.apiServer.SendEvent(rule, event)
+		m.apiServer.SendEvent(rule, event, enforcement)
+		m.actions.Sent(rule)
 	} else {
 		log.Tracef("Event on rule %s was dropped due to rate limiting", rule.ID)
 	}
@@ -260,9 +301,20 @@ func (m *Module) metricsSender() {
 			if err := m.rateLimiter.SendStats(); err != nil {
 				log.Debug(err)
 			}
+			if err := m.actions.SendStats(); err != nil {
+				log.Debug(err)
+			}
+			if err := m.enforcer.SendStats(); err != nil {
+				log.Debug(err)
+			}
 			if err := m.apiServer.SendStats(); err != nil {
 				log.Debug(err)
 			}
+			if m.activityDumps != nil {
+				if err := m.activityDumps.SendStats(); err != nil {
+					log.Debug(err)
+				}
+			}
 		case <-heartbeatTicker.C:
 			tags := []string{fmt.Sprintf("version:%s", version.AgentVersion)}
 			if m.config.RuntimeEnabled {
@@ -287,6 +339,18 @@ func (m *Module) GetStats() map[string]interface{} {
 		debug["probe"] = "not_running"
 	}
 
+	debug["rate_limiter"] = m.rateLimiter.GetDebugStats()
+	debug["actions"] = m.actions.GetDebugStats()
+	debug["enforcement"] = m.enforcer.GetDebugStats()
+
+	if m.activityDumps != nil {
+		debug["activity_dumps"] = m.activityDumps.GetDebugStats()
+	}
+
+	if ruleSet := m.GetRuleSet(); ruleSet != nil {
+		debug["variables"] = ruleSet.GetVariables().GetStats()
+	}
+
 	return debug
 }
 
@@ -331,12 +395,20 @@ func NewModule(cfg *config.Config) (api.Module, error) {
 		apiServer:      NewAPIServer(cfg, probe, statsdClient),
 		grpcServer:     grpc.NewServer(),
 		rateLimiter:    NewRateLimiter(statsdClient),
+		actions:        NewRuleActions(statsdClient),
+		enforcer:       NewEnforcer(cfg, statsdClient, probe.GetResolvers().ProcessResolver),
 		sigupChan:      make(chan os.Signal, 1),
 		currentRuleSet: 1,
 		ctx:            ctx,
 		cancelFnc:      cancelFnc,
 	}
 
+	if cfg != nil && cfg.ActivityDumpEnabled {
+		if m.activityDumps, err = NewActivityDumps(cfg, statsdClient); err != nil {
+			return nil, err
+		}
+	}
+
 	sapi.RegisterSecurityModuleServer(m.grpcServer, m.apiServer)
 
 	return m, nil
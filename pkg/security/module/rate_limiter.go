@@ -10,6 +10,7 @@ package module
 import (
 	"fmt"
 	"sync"
+	"sync/atomic"
 
 	"github.com/DataDog/datadog-go/statsd"
 	"golang.org/x/time/rate"
@@ -33,9 +34,11 @@ type Limiter struct {
 	limiter *rate.Limiter
 
 	// https://github.com/golang/go/issues/36606
-	padding int32 //nolint:structcheck,unused
-	dropped int64
-	allowed int64
+	padding      int32 //nolint:structcheck,unused
+	dropped      int64
+	allowed      int64
+	totalDropped int64
+	totalAllowed int64
 }
 
 // NewLimiter returns a new rule limiter
@@ -60,17 +63,23 @@ func NewRateLimiter(client *statsd.Client) *RateLimiter {
 	}
 }
 
-// Apply a set of rules
-func (rl *RateLimiter) Apply(rules []rules.RuleID) {
+// Apply a set of rules. The rules defining a rate limit in the given rule set get their own token bucket, the
+// others use the default one.
+func (rl *RateLimiter) Apply(ruleSet *rules.RuleSet, ruleIDs []rules.RuleID) {
 	rl.Lock()
 	defer rl.Unlock()
 
 	newLimiters := make(map[string]*Limiter)
-	for _, id := range rules {
-		if limiter, found := rl.limiters[id]; found {
+	for _, id := range ruleIDs {
+		limit, burst := defaultLimit, defaultBurst
+		if ruleDef := ruleSet.GetRuleDefinition(id); ruleDef != nil && ruleDef.RateLimit != nil {
+			limit, burst = rate.Limit(ruleDef.RateLimit.Limit), ruleDef.RateLimit.Burst
+		}
+
+		if limiter, found := rl.limiters[id]; found && limiter.limiter.Limit() == limit && limiter.limiter.Burst() == burst {
 			newLimiters[id] = limiter
 		} else {
-			newLimiters[id] = NewLimiter(defaultLimit, defaultBurst)
+			newLimiters[id] = NewLimiter(limit, burst)
 		}
 	}
 	rl.limiters = newLimiters
@@ -86,10 +95,12 @@ func (rl *RateLimiter) Allow(ruleID string) bool {
 		return false
 	}
 	if ruleLimiter.limiter.Allow() {
-		ruleLimiter.allowed++
+		atomic.AddInt64(&ruleLimiter.allowed, 1)
+		atomic.AddInt64(&ruleLimiter.totalAllowed, 1)
 		return true
 	}
-	ruleLimiter.dropped++
+	atomic.AddInt64(&ruleLimiter.dropped, 1)
+	atomic.AddInt64(&ruleLimiter.totalDropped, 1)
 	return false
 }
 
@@ -108,11 +119,27 @@ func (rl *RateLimiter) GetStats() map[rules.RuleID]RateLimiterStat {
 	stats := make(map[rules.RuleID]RateLimiterStat)
 	for ruleID, ruleLimiter := range rl.limiters {
 		stats[ruleID] = RateLimiterStat{
-			dropped: ruleLimiter.dropped,
-			allowed: ruleLimiter.allowed,
+			dropped: atomic.SwapInt64(&ruleLimiter.dropped, 0),
+			allowed: atomic.SwapInt64(&ruleLimiter.allowed, 0),
+		}
+	}
+	return stats
+}
+
+// GetDebugStats returns, for each rule, its rate limit and the amount of events allowed and dropped since it
+// was applied
+func (rl *RateLimiter) GetDebugStats() map[rules.RuleID]map[string]interface{} {
+	rl.RLock()
+	defer rl.RUnlock()
+
+	stats := make(map[rules.RuleID]map[string]interface{})
+	for ruleID, ruleLimiter := range rl.limiters {
+		stats[ruleID] = map[string]interface{}{
+			"limit":   float64(ruleLimiter.limiter.Limit()),
+			"burst":   ruleLimiter.limiter.Burst(),
+			"allowed": atomic.LoadInt64(&ruleLimiter.totalAllowed),
+			"dropped": atomic.LoadInt64(&ruleLimiter.totalDropped),
 		}
-		ruleLimiter.dropped = 0
-		ruleLimiter.allowed = 0
 	}
 	return stats
 }
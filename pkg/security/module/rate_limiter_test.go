@@ -0,0 +1,78 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux
+
+package module
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/security/rules"
+)
+
+func TestRateLimiter(t *testing.T) {
+	_, rs := newTestModule(t,
+		&rules.RuleDefinition{ID: "limited", Expression: `open.flags == 1`, RateLimit: &rules.RateLimitDefinition{Limit: 0.001, Burst: 2}},
+		&rules.RuleDefinition{ID: "default", Expression: `open.flags == 2`},
+	)
+
+	rl := NewRateLimiter(nil)
+	rl.Apply(rs, rs.ListRuleIDs())
+
+	for i := 0; i < 2; i++ {
+		if !rl.Allow("limited") {
+			t.Fatalf("event %d should be allowed by the burst of the rule", i)
+		}
+	}
+	if rl.Allow("limited") {
+		t.Error("the event should be dropped once the burst of the rule is consumed")
+	}
+
+	// the rules without a rate limit use the default token bucket
+	for i := 0; i < defaultBurst; i++ {
+		if !rl.Allow("default") {
+			t.Fatalf("event %d should be allowed by the default burst", i)
+		}
+	}
+	if rl.Allow("default") {
+		t.Error("the event should be dropped once the default burst is consumed")
+	}
+
+	if rl.Allow("unknown") {
+		t.Error("the events of unknown rules should be dropped")
+	}
+
+	stats := rl.GetStats()
+	if stat := stats["limited"]; stat.allowed != 2 || stat.dropped != 1 {
+		t.Errorf("unexpected stats for the limited rule: %+v", stat)
+	}
+	if stat := stats["default"]; stat.allowed != int64(defaultBurst) || stat.dropped != 1 {
+		t.Errorf("unexpected stats for the default rule: %+v", stat)
+	}
+	if stat := rl.GetStats()["limited"]; stat.allowed != 0 || stat.dropped != 0 {
+		t.Errorf("the stats should be reset once retrieved: %+v", stat)
+	}
+
+	// applying the same limits again keeps the token buckets
+	rl.Apply(rs, rs.ListRuleIDs())
+	if rl.Allow("limited") {
+		t.Error("the token bucket of an unchanged rule shouldn't be reset")
+	}
+
+	// a rule whose limit changed gets a new token bucket
+	_, updated := newTestModule(t,
+		&rules.RuleDefinition{ID: "limited", Expression: `open.flags == 1`, RateLimit: &rules.RateLimitDefinition{Limit: 0.001, Burst: 3}},
+	)
+	rl.Apply(updated, updated.ListRuleIDs())
+	for i := 0; i < 3; i++ {
+		if !rl.Allow("limited") {
+			t.Fatalf("event %d should be allowed by the new burst of the rule", i)
+		}
+	}
+	if rl.Allow("default") {
+		t.Error("the rules which are no longer loaded should be dropped")
+	}
+}
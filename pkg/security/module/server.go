@@ -95,10 +95,13 @@ func (a *APIServer) DumpProcessCache(ctx context.Context, params *api.DumpProces
 	}, nil
 }
 
-// SendEvent forwards events sent by the runtime security module to Datadog
-func (a *APIServer) SendEvent(rule *rules.Rule, event Event) {
+// SendEvent forwards events sent by the runtime security module to Datadog, along with the outcome of the
+// enforcement action of the rule, if any
+func (a *APIServer) SendEvent(rule *rules.Rule, event Event, enforcement *EnforcementReport) {
 	agentContext := &AgentContext{
-		RuleID: rule.Definition.ID,
+		RuleID:      rule.Definition.ID,
+		Severity:    rule.Definition.GetSeverity(),
+		Enforcement: enforcement,
 	}
 
 	ruleEvent := &Signal{
@@ -128,10 +131,18 @@ func (a *APIServer) SendEvent(rule *rules.Rule, event Event) {
 	data = append(data, ruleEventJSON[1:]...)
 	log.Tracef("Sending event message for rule `%s` to security-agent `%s`", rule.ID, string(data))
 
+	tags := append(rule.Tags, append(event.GetTags(), "rule_id:"+rule.Definition.ID)...)
+	if agentContext.Severity != "" {
+		tags = append(tags, "severity:"+agentContext.Severity)
+	}
+	if enforcement != nil {
+		tags = append(tags, "enforcement:"+enforcement.Status)
+	}
+
 	msg := &api.SecurityEventMessage{
 		RuleID: rule.Definition.ID,
 		Data:   data,
-		Tags:   append(rule.Tags, append(event.GetTags(), "rule_id:"+rule.Definition.ID)...),
+		Tags:   tags,
 	}
 
 	select {
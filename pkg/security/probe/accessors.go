@@ -31,18 +31,30 @@ func (m *Model) GetIterator(field eval.Field) (eval.Iterator, error) {
 func (m *Model) GetEventTypes() []eval.EventType {
 	return []eval.EventType{
 
+		eval.EventType("bind"),
+
+		eval.EventType("bpf"),
+
 		eval.EventType("chmod"),
 
 		eval.EventType("chown"),
 
+		eval.EventType("connect"),
+
+		eval.EventType("dns"),
+
 		eval.EventType("exec"),
 
 		eval.EventType("link"),
 
+		eval.EventType("load_module"),
+
 		eval.EventType("mkdir"),
 
 		eval.EventType("open"),
 
+		eval.EventType("ptrace"),
+
 		eval.EventType("removexattr"),
 
 		eval.EventType("rename"),
@@ -51,6 +63,8 @@ func (m *Model) GetEventTypes() []eval.EventType {
 
 		eval.EventType("setxattr"),
 
+		eval.EventType("signal"),
+
 		eval.EventType("unlink"),
 
 		eval.EventType("utimes"),
@@ -60,6 +74,102 @@ func (m *Model) GetEventTypes() []eval.EventType {
 func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Evaluator, error) {
 	switch field {
 
+	case "bind.addr.family":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Bind.Addr.Family)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "bind.addr.ip":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+
+				return (*Event)(ctx.Object).Bind.Addr.IP
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "bind.addr.port":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Bind.Addr.Port)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "bind.retval":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Bind.SyscallEvent.Retval)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "bpf.cmd":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).BPF.Cmd)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "bpf.prog_name":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+
+				return (*Event)(ctx.Object).BPF.ProgName
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "bpf.prog_type":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).BPF.ProgType)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "bpf.retval":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).BPF.SyscallEvent.Retval)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
 	case "chmod.basename":
 		return &eval.StringEvaluator{
 			EvalFnc: func(ctx *eval.Context) string {
@@ -240,6 +350,54 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Weight: eval.FunctionWeight,
 		}, nil
 
+	case "connect.addr.family":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Connect.Addr.Family)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "connect.addr.ip":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+
+				return (*Event)(ctx.Object).Connect.Addr.IP
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "connect.addr.port":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Connect.Addr.Port)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "connect.retval":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Connect.SyscallEvent.Retval)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
 	case "container.id":
 		return &eval.StringEvaluator{
 			EvalFnc: func(ctx *eval.Context) string {
@@ -252,6 +410,78 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Weight: eval.HandlerWeight,
 		}, nil
 
+	case "container.image":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+
+				return (*Event)(ctx.Object).ResolveContainerImage(&(*Event)(ctx.Object).Container)
+
+			},
+			Field: field,
+
+			Weight: eval.HandlerWeight,
+		}, nil
+
+	case "dns.id":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).DNS.ID)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "dns.question.class":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).DNS.Question.Class)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "dns.question.count":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).DNS.Question.Count)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "dns.question.name":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+
+				return (*Event)(ctx.Object).DNS.Question.Name
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "dns.question.type":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).DNS.Question.Type)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
 	case "exec.container_path":
 		return &eval.StringEvaluator{
 			EvalFnc: func(ctx *eval.Context) string {
@@ -276,6 +506,18 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Weight: eval.HandlerWeight,
 		}, nil
 
+	case "exec.created_at":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).ResolveExecCreatedAt(&(*Event)(ctx.Object).Exec))
+
+			},
+			Field: field,
+
+			Weight: eval.HandlerWeight,
+		}, nil
+
 	case "exec.filename":
 		return &eval.StringEvaluator{
 			EvalFnc: func(ctx *eval.Context) string {
@@ -528,6 +770,42 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Weight: eval.FunctionWeight,
 		}, nil
 
+	case "load_module.loaded_from_memory":
+		return &eval.BoolEvaluator{
+			EvalFnc: func(ctx *eval.Context) bool {
+
+				return (*Event)(ctx.Object).LoadModule.LoadedFromMemory
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "load_module.name":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+
+				return (*Event)(ctx.Object).LoadModule.Name
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "load_module.retval":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).LoadModule.SyscallEvent.Retval)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
 	case "mkdir.basename":
 		return &eval.StringEvaluator{
 			EvalFnc: func(ctx *eval.Context) string {
@@ -752,6 +1030,28 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Weight: eval.IteratorWeight,
 		}, nil
 
+	case "process.ancestors.created_at":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				var result int
+
+				reg := ctx.Registers[regID]
+				if reg.Value != nil {
+					element := (*model.ProcessCacheEntry)(reg.Value)
+
+					result = int((*Event)(ctx.Object).ResolveExecCreatedAt(&element.ExecEvent))
+
+				}
+
+				return result
+
+			},
+			Field: field,
+
+			Weight: eval.IteratorWeight,
+		}, nil
+
 	case "process.ancestors.filename":
 		return &eval.StringEvaluator{
 			EvalFnc: func(ctx *eval.Context) string {
@@ -840,6 +1140,28 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Weight: eval.IteratorWeight,
 		}, nil
 
+	case "process.ancestors.image":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+
+				var result string
+
+				reg := ctx.Registers[regID]
+				if reg.Value != nil {
+					element := (*model.ProcessCacheEntry)(reg.Value)
+
+					result = (*Event)(ctx.Object).ResolveContainerImage(&element.ContainerContext)
+
+				}
+
+				return result
+
+			},
+			Field: field,
+
+			Weight: eval.IteratorWeight,
+		}, nil
+
 	case "process.ancestors.inode":
 		return &eval.IntEvaluator{
 			EvalFnc: func(ctx *eval.Context) int {
@@ -1062,6 +1384,18 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Weight: eval.HandlerWeight,
 		}, nil
 
+	case "process.created_at":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).ResolveExecCreatedAt(&(*Event)(ctx.Object).Process.ExecEvent))
+
+			},
+			Field: field,
+
+			Weight: eval.HandlerWeight,
+		}, nil
+
 	case "process.filename":
 		return &eval.StringEvaluator{
 			EvalFnc: func(ctx *eval.Context) string {
@@ -1198,12 +1532,48 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 		return &eval.StringEvaluator{
 			EvalFnc: func(ctx *eval.Context) string {
 
-				return (*Event)(ctx.Object).ResolveExecUser(&(*Event)(ctx.Object).Process.ExecEvent)
+				return (*Event)(ctx.Object).ResolveExecUser(&(*Event)(ctx.Object).Process.ExecEvent)
+
+			},
+			Field: field,
+
+			Weight: eval.HandlerWeight,
+		}, nil
+
+	case "ptrace.pid":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).PTrace.PID)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "ptrace.request":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).PTrace.Request)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "ptrace.retval":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).PTrace.SyscallEvent.Retval)
 
 			},
 			Field: field,
 
-			Weight: eval.HandlerWeight,
+			Weight: eval.FunctionWeight,
 		}, nil
 
 	case "removexattr.basename":
@@ -1602,6 +1972,42 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Weight: eval.FunctionWeight,
 		}, nil
 
+	case "signal.pid":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Signal.PID)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "signal.retval":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Signal.SyscallEvent.Retval)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
+	case "signal.type":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+
+				return int((*Event)(ctx.Object).Signal.Type)
+
+			},
+			Field: field,
+
+			Weight: eval.FunctionWeight,
+		}, nil
+
 	case "unlink.basename":
 		return &eval.StringEvaluator{
 			EvalFnc: func(ctx *eval.Context) string {
@@ -1766,6 +2172,22 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 func (e *Event) GetFields() []eval.Field {
 	return []eval.Field{
 
+		"bind.addr.family",
+
+		"bind.addr.ip",
+
+		"bind.addr.port",
+
+		"bind.retval",
+
+		"bpf.cmd",
+
+		"bpf.prog_name",
+
+		"bpf.prog_type",
+
+		"bpf.retval",
+
 		"chmod.basename",
 
 		"chmod.container_path",
@@ -1796,12 +2218,34 @@ func (e *Event) GetFields() []eval.Field {
 
 		"chown.uid",
 
+		"connect.addr.family",
+
+		"connect.addr.ip",
+
+		"connect.addr.port",
+
+		"connect.retval",
+
 		"container.id",
 
+		"container.image",
+
+		"dns.id",
+
+		"dns.question.class",
+
+		"dns.question.count",
+
+		"dns.question.name",
+
+		"dns.question.type",
+
 		"exec.container_path",
 
 		"exec.cookie",
 
+		"exec.created_at",
+
 		"exec.filename",
 
 		"exec.gid",
@@ -1844,6 +2288,12 @@ func (e *Event) GetFields() []eval.Field {
 
 		"link.target.overlay_numlower",
 
+		"load_module.loaded_from_memory",
+
+		"load_module.name",
+
+		"load_module.retval",
+
 		"mkdir.basename",
 
 		"mkdir.container_path",
@@ -1878,6 +2328,8 @@ func (e *Event) GetFields() []eval.Field {
 
 		"process.ancestors.cookie",
 
+		"process.ancestors.created_at",
+
 		"process.ancestors.filename",
 
 		"process.ancestors.gid",
@@ -1886,6 +2338,8 @@ func (e *Event) GetFields() []eval.Field {
 
 		"process.ancestors.id",
 
+		"process.ancestors.image",
+
 		"process.ancestors.inode",
 
 		"process.ancestors.name",
@@ -1908,6 +2362,8 @@ func (e *Event) GetFields() []eval.Field {
 
 		"process.cookie",
 
+		"process.created_at",
+
 		"process.filename",
 
 		"process.gid",
@@ -1932,6 +2388,12 @@ func (e *Event) GetFields() []eval.Field {
 
 		"process.user",
 
+		"ptrace.pid",
+
+		"ptrace.request",
+
+		"ptrace.retval",
+
 		"removexattr.basename",
 
 		"removexattr.container_path",
@@ -1998,6 +2460,12 @@ func (e *Event) GetFields() []eval.Field {
 
 		"setxattr.retval",
 
+		"signal.pid",
+
+		"signal.retval",
+
+		"signal.type",
+
 		"unlink.basename",
 
 		"unlink.container_path",
@@ -2029,6 +2497,38 @@ func (e *Event) GetFields() []eval.Field {
 func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 	switch field {
 
+	case "bind.addr.family":
+
+		return int(e.Bind.Addr.Family), nil
+
+	case "bind.addr.ip":
+
+		return e.Bind.Addr.IP, nil
+
+	case "bind.addr.port":
+
+		return int(e.Bind.Addr.Port), nil
+
+	case "bind.retval":
+
+		return int(e.Bind.SyscallEvent.Retval), nil
+
+	case "bpf.cmd":
+
+		return int(e.BPF.Cmd), nil
+
+	case "bpf.prog_name":
+
+		return e.BPF.ProgName, nil
+
+	case "bpf.prog_type":
+
+		return int(e.BPF.ProgType), nil
+
+	case "bpf.retval":
+
+		return int(e.BPF.SyscallEvent.Retval), nil
+
 	case "chmod.basename":
 
 		return e.ResolveFileBasename(&e.Chmod.FileEvent), nil
@@ -2089,10 +2589,50 @@ func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 
 		return int(e.Chown.UID), nil
 
+	case "connect.addr.family":
+
+		return int(e.Connect.Addr.Family), nil
+
+	case "connect.addr.ip":
+
+		return e.Connect.Addr.IP, nil
+
+	case "connect.addr.port":
+
+		return int(e.Connect.Addr.Port), nil
+
+	case "connect.retval":
+
+		return int(e.Connect.SyscallEvent.Retval), nil
+
 	case "container.id":
 
 		return e.ResolveContainerID(&e.Container), nil
 
+	case "container.image":
+
+		return e.ResolveContainerImage(&e.Container), nil
+
+	case "dns.id":
+
+		return int(e.DNS.ID), nil
+
+	case "dns.question.class":
+
+		return int(e.DNS.Question.Class), nil
+
+	case "dns.question.count":
+
+		return int(e.DNS.Question.Count), nil
+
+	case "dns.question.name":
+
+		return e.DNS.Question.Name, nil
+
+	case "dns.question.type":
+
+		return int(e.DNS.Question.Type), nil
+
 	case "exec.container_path":
 
 		return e.ResolveExecContainerPath(&e.Exec), nil
@@ -2101,6 +2641,10 @@ func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 
 		return int(e.ResolveExecCookie(&e.Exec)), nil
 
+	case "exec.created_at":
+
+		return int(e.ResolveExecCreatedAt(&e.Exec)), nil
+
 	case "exec.filename":
 
 		return e.ResolveExecInode(&e.Exec), nil
@@ -2185,6 +2729,18 @@ func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 
 		return int(e.Link.Target.FileFields.OverlayNumLower), nil
 
+	case "load_module.loaded_from_memory":
+
+		return e.LoadModule.LoadedFromMemory, nil
+
+	case "load_module.name":
+
+		return e.LoadModule.Name, nil
+
+	case "load_module.retval":
+
+		return int(e.LoadModule.SyscallEvent.Retval), nil
+
 	case "mkdir.basename":
 
 		return e.ResolveFileBasename(&e.Mkdir.FileEvent), nil
@@ -2289,6 +2845,28 @@ func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 
 		return values, nil
 
+	case "process.ancestors.created_at":
+
+		var values []int
+
+		ctx := &eval.Context{}
+		ctx.SetObject(unsafe.Pointer(e))
+
+		iterator := &model.ProcessAncestorsIterator{}
+		ptr := iterator.Front(ctx)
+
+		for ptr != nil {
+			element := (*model.ProcessCacheEntry)(ptr)
+
+			result := int((*Event)(ctx.Object).ResolveExecCreatedAt(&element.ExecEvent))
+
+			values = append(values, result)
+
+			ptr = iterator.Next()
+		}
+
+		return values, nil
+
 	case "process.ancestors.filename":
 
 		var values []string
@@ -2377,6 +2955,28 @@ func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 
 		return values, nil
 
+	case "process.ancestors.image":
+
+		var values []string
+
+		ctx := &eval.Context{}
+		ctx.SetObject(unsafe.Pointer(e))
+
+		iterator := &model.ProcessAncestorsIterator{}
+		ptr := iterator.Front(ctx)
+
+		for ptr != nil {
+			element := (*model.ProcessCacheEntry)(ptr)
+
+			result := (*Event)(ctx.Object).ResolveContainerImage(&element.ContainerContext)
+
+			values = append(values, result)
+
+			ptr = iterator.Next()
+		}
+
+		return values, nil
+
 	case "process.ancestors.inode":
 
 		var values []int
@@ -2583,6 +3183,10 @@ func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 
 		return int(e.ResolveExecCookie(&e.Process.ExecEvent)), nil
 
+	case "process.created_at":
+
+		return int(e.ResolveExecCreatedAt(&e.Process.ExecEvent)), nil
+
 	case "process.filename":
 
 		return e.ResolveExecInode(&e.Process.ExecEvent), nil
@@ -2631,6 +3235,18 @@ func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 
 		return e.ResolveExecUser(&e.Process.ExecEvent), nil
 
+	case "ptrace.pid":
+
+		return int(e.PTrace.PID), nil
+
+	case "ptrace.request":
+
+		return int(e.PTrace.Request), nil
+
+	case "ptrace.retval":
+
+		return int(e.PTrace.SyscallEvent.Retval), nil
+
 	case "removexattr.basename":
 
 		return e.ResolveFileBasename(&e.RemoveXAttr.FileEvent), nil
@@ -2763,6 +3379,18 @@ func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 
 		return int(e.SetXAttr.SyscallEvent.Retval), nil
 
+	case "signal.pid":
+
+		return int(e.Signal.PID), nil
+
+	case "signal.retval":
+
+		return int(e.Signal.SyscallEvent.Retval), nil
+
+	case "signal.type":
+
+		return int(e.Signal.Type), nil
+
 	case "unlink.basename":
 
 		return e.ResolveFileBasename(&e.Unlink.FileEvent), nil
@@ -2823,6 +3451,30 @@ func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 	switch field {
 
+	case "bind.addr.family":
+		return "bind", nil
+
+	case "bind.addr.ip":
+		return "bind", nil
+
+	case "bind.addr.port":
+		return "bind", nil
+
+	case "bind.retval":
+		return "bind", nil
+
+	case "bpf.cmd":
+		return "bpf", nil
+
+	case "bpf.prog_name":
+		return "bpf", nil
+
+	case "bpf.prog_type":
+		return "bpf", nil
+
+	case "bpf.retval":
+		return "bpf", nil
+
 	case "chmod.basename":
 		return "chmod", nil
 
@@ -2868,15 +3520,48 @@ func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 	case "chown.uid":
 		return "chown", nil
 
+	case "connect.addr.family":
+		return "connect", nil
+
+	case "connect.addr.ip":
+		return "connect", nil
+
+	case "connect.addr.port":
+		return "connect", nil
+
+	case "connect.retval":
+		return "connect", nil
+
 	case "container.id":
 		return "*", nil
 
+	case "container.image":
+		return "*", nil
+
+	case "dns.id":
+		return "dns", nil
+
+	case "dns.question.class":
+		return "dns", nil
+
+	case "dns.question.count":
+		return "dns", nil
+
+	case "dns.question.name":
+		return "dns", nil
+
+	case "dns.question.type":
+		return "dns", nil
+
 	case "exec.container_path":
 		return "exec", nil
 
 	case "exec.cookie":
 		return "exec", nil
 
+	case "exec.created_at":
+		return "exec", nil
+
 	case "exec.filename":
 		return "exec", nil
 
@@ -2940,6 +3625,15 @@ func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 	case "link.target.overlay_numlower":
 		return "link", nil
 
+	case "load_module.loaded_from_memory":
+		return "load_module", nil
+
+	case "load_module.name":
+		return "load_module", nil
+
+	case "load_module.retval":
+		return "load_module", nil
+
 	case "mkdir.basename":
 		return "mkdir", nil
 
@@ -2991,6 +3685,9 @@ func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 	case "process.ancestors.cookie":
 		return "*", nil
 
+	case "process.ancestors.created_at":
+		return "*", nil
+
 	case "process.ancestors.filename":
 		return "*", nil
 
@@ -3003,6 +3700,9 @@ func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 	case "process.ancestors.id":
 		return "*", nil
 
+	case "process.ancestors.image":
+		return "*", nil
+
 	case "process.ancestors.inode":
 		return "*", nil
 
@@ -3036,6 +3736,9 @@ func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 	case "process.cookie":
 		return "*", nil
 
+	case "process.created_at":
+		return "*", nil
+
 	case "process.filename":
 		return "*", nil
 
@@ -3072,6 +3775,15 @@ func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 	case "process.user":
 		return "*", nil
 
+	case "ptrace.pid":
+		return "ptrace", nil
+
+	case "ptrace.request":
+		return "ptrace", nil
+
+	case "ptrace.retval":
+		return "ptrace", nil
+
 	case "removexattr.basename":
 		return "removexattr", nil
 
@@ -3171,6 +3883,15 @@ func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 	case "setxattr.retval":
 		return "setxattr", nil
 
+	case "signal.pid":
+		return "signal", nil
+
+	case "signal.retval":
+		return "signal", nil
+
+	case "signal.type":
+		return "signal", nil
+
 	case "unlink.basename":
 		return "unlink", nil
 
@@ -3218,6 +3939,38 @@ func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 	switch field {
 
+	case "bind.addr.family":
+
+		return reflect.Int, nil
+
+	case "bind.addr.ip":
+
+		return reflect.String, nil
+
+	case "bind.addr.port":
+
+		return reflect.Int, nil
+
+	case "bind.retval":
+
+		return reflect.Int, nil
+
+	case "bpf.cmd":
+
+		return reflect.Int, nil
+
+	case "bpf.prog_name":
+
+		return reflect.String, nil
+
+	case "bpf.prog_type":
+
+		return reflect.Int, nil
+
+	case "bpf.retval":
+
+		return reflect.Int, nil
+
 	case "chmod.basename":
 
 		return reflect.String, nil
@@ -3264,24 +4017,64 @@ func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 
 	case "chown.inode":
 
-		return reflect.Int, nil
+		return reflect.Int, nil
+
+	case "chown.overlay_numlower":
+
+		return reflect.Int, nil
+
+	case "chown.retval":
+
+		return reflect.Int, nil
+
+	case "chown.uid":
+
+		return reflect.Int, nil
+
+	case "connect.addr.family":
+
+		return reflect.Int, nil
+
+	case "connect.addr.ip":
+
+		return reflect.String, nil
+
+	case "connect.addr.port":
+
+		return reflect.Int, nil
+
+	case "connect.retval":
+
+		return reflect.Int, nil
+
+	case "container.id":
+
+		return reflect.String, nil
+
+	case "container.image":
+
+		return reflect.String, nil
 
-	case "chown.overlay_numlower":
+	case "dns.id":
 
 		return reflect.Int, nil
 
-	case "chown.retval":
+	case "dns.question.class":
 
 		return reflect.Int, nil
 
-	case "chown.uid":
+	case "dns.question.count":
 
 		return reflect.Int, nil
 
-	case "container.id":
+	case "dns.question.name":
 
 		return reflect.String, nil
 
+	case "dns.question.type":
+
+		return reflect.Int, nil
+
 	case "exec.container_path":
 
 		return reflect.String, nil
@@ -3290,6 +4083,10 @@ func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 
 		return reflect.Int, nil
 
+	case "exec.created_at":
+
+		return reflect.Int, nil
+
 	case "exec.filename":
 
 		return reflect.String, nil
@@ -3374,6 +4171,18 @@ func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 
 		return reflect.Int, nil
 
+	case "load_module.loaded_from_memory":
+
+		return reflect.Bool, nil
+
+	case "load_module.name":
+
+		return reflect.String, nil
+
+	case "load_module.retval":
+
+		return reflect.Int, nil
+
 	case "mkdir.basename":
 
 		return reflect.String, nil
@@ -3442,6 +4251,10 @@ func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 
 		return reflect.Int, nil
 
+	case "process.ancestors.created_at":
+
+		return reflect.Int, nil
+
 	case "process.ancestors.filename":
 
 		return reflect.String, nil
@@ -3458,6 +4271,10 @@ func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 
 		return reflect.String, nil
 
+	case "process.ancestors.image":
+
+		return reflect.String, nil
+
 	case "process.ancestors.inode":
 
 		return reflect.Int, nil
@@ -3502,6 +4319,10 @@ func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 
 		return reflect.Int, nil
 
+	case "process.created_at":
+
+		return reflect.Int, nil
+
 	case "process.filename":
 
 		return reflect.String, nil
@@ -3550,6 +4371,18 @@ func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 
 		return reflect.String, nil
 
+	case "ptrace.pid":
+
+		return reflect.Int, nil
+
+	case "ptrace.request":
+
+		return reflect.Int, nil
+
+	case "ptrace.retval":
+
+		return reflect.Int, nil
+
 	case "removexattr.basename":
 
 		return reflect.String, nil
@@ -3682,6 +4515,18 @@ func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 
 		return reflect.Int, nil
 
+	case "signal.pid":
+
+		return reflect.Int, nil
+
+	case "signal.retval":
+
+		return reflect.Int, nil
+
+	case "signal.type":
+
+		return reflect.Int, nil
+
 	case "unlink.basename":
 
 		return reflect.String, nil
@@ -3742,6 +4587,82 @@ func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 	switch field {
 
+	case "bind.addr.family":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Bind.Addr.Family"}
+		}
+		e.Bind.Addr.Family = uint16(v)
+		return nil
+
+	case "bind.addr.ip":
+
+		var ok bool
+		if e.Bind.Addr.IP, ok = value.(string); !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Bind.Addr.IP"}
+		}
+		return nil
+
+	case "bind.addr.port":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Bind.Addr.Port"}
+		}
+		e.Bind.Addr.Port = uint16(v)
+		return nil
+
+	case "bind.retval":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Bind.SyscallEvent.Retval"}
+		}
+		e.Bind.SyscallEvent.Retval = int64(v)
+		return nil
+
+	case "bpf.cmd":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "BPF.Cmd"}
+		}
+		e.BPF.Cmd = uint32(v)
+		return nil
+
+	case "bpf.prog_name":
+
+		var ok bool
+		if e.BPF.ProgName, ok = value.(string); !ok {
+			return &eval.ErrValueTypeMismatch{Field: "BPF.ProgName"}
+		}
+		return nil
+
+	case "bpf.prog_type":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "BPF.ProgType"}
+		}
+		e.BPF.ProgType = uint32(v)
+		return nil
+
+	case "bpf.retval":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "BPF.SyscallEvent.Retval"}
+		}
+		e.BPF.SyscallEvent.Retval = int64(v)
+		return nil
+
 	case "chmod.basename":
 
 		var ok bool
@@ -3880,6 +4801,44 @@ func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 		e.Chown.UID = int32(v)
 		return nil
 
+	case "connect.addr.family":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Connect.Addr.Family"}
+		}
+		e.Connect.Addr.Family = uint16(v)
+		return nil
+
+	case "connect.addr.ip":
+
+		var ok bool
+		if e.Connect.Addr.IP, ok = value.(string); !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Connect.Addr.IP"}
+		}
+		return nil
+
+	case "connect.addr.port":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Connect.Addr.Port"}
+		}
+		e.Connect.Addr.Port = uint16(v)
+		return nil
+
+	case "connect.retval":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Connect.SyscallEvent.Retval"}
+		}
+		e.Connect.SyscallEvent.Retval = int64(v)
+		return nil
+
 	case "container.id":
 
 		var ok bool
@@ -3888,6 +4847,62 @@ func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 		}
 		return nil
 
+	case "container.image":
+
+		var ok bool
+		if e.Container.Image, ok = value.(string); !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Container.Image"}
+		}
+		return nil
+
+	case "dns.id":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "DNS.ID"}
+		}
+		e.DNS.ID = uint16(v)
+		return nil
+
+	case "dns.question.class":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "DNS.Question.Class"}
+		}
+		e.DNS.Question.Class = uint16(v)
+		return nil
+
+	case "dns.question.count":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "DNS.Question.Count"}
+		}
+		e.DNS.Question.Count = uint16(v)
+		return nil
+
+	case "dns.question.name":
+
+		var ok bool
+		if e.DNS.Question.Name, ok = value.(string); !ok {
+			return &eval.ErrValueTypeMismatch{Field: "DNS.Question.Name"}
+		}
+		return nil
+
+	case "dns.question.type":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "DNS.Question.Type"}
+		}
+		e.DNS.Question.Type = uint16(v)
+		return nil
+
 	case "exec.container_path":
 
 		var ok bool
@@ -3906,6 +4921,16 @@ func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 		e.Exec.Cookie = uint32(v)
 		return nil
 
+	case "exec.created_at":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Exec.CreatedAt"}
+		}
+		e.Exec.CreatedAt = uint64(v)
+		return nil
+
 	case "exec.filename":
 
 		var ok bool
@@ -4094,6 +5119,32 @@ func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 		e.Link.Target.FileFields.OverlayNumLower = int32(v)
 		return nil
 
+	case "load_module.loaded_from_memory":
+
+		var ok bool
+		if e.LoadModule.LoadedFromMemory, ok = value.(bool); !ok {
+			return &eval.ErrValueTypeMismatch{Field: "LoadModule.LoadedFromMemory"}
+		}
+		return nil
+
+	case "load_module.name":
+
+		var ok bool
+		if e.LoadModule.Name, ok = value.(string); !ok {
+			return &eval.ErrValueTypeMismatch{Field: "LoadModule.Name"}
+		}
+		return nil
+
+	case "load_module.retval":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "LoadModule.SyscallEvent.Retval"}
+		}
+		e.LoadModule.SyscallEvent.Retval = int64(v)
+		return nil
+
 	case "mkdir.basename":
 
 		var ok bool
@@ -4258,6 +5309,20 @@ func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 		e.Process.Ancestor.ProcessContext.ExecEvent.Cookie = uint32(v)
 		return nil
 
+	case "process.ancestors.created_at":
+
+		if e.Process.Ancestor == nil {
+			e.Process.Ancestor = &model.ProcessCacheEntry{}
+		}
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Process.Ancestor.ProcessContext.ExecEvent.CreatedAt"}
+		}
+		e.Process.Ancestor.ProcessContext.ExecEvent.CreatedAt = uint64(v)
+		return nil
+
 	case "process.ancestors.filename":
 
 		if e.Process.Ancestor == nil {
@@ -4308,6 +5373,18 @@ func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 		}
 		return nil
 
+	case "process.ancestors.image":
+
+		if e.Process.Ancestor == nil {
+			e.Process.Ancestor = &model.ProcessCacheEntry{}
+		}
+
+		var ok bool
+		if e.Process.Ancestor.ContainerContext.Image, ok = value.(string); !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Process.Ancestor.ContainerContext.Image"}
+		}
+		return nil
+
 	case "process.ancestors.inode":
 
 		if e.Process.Ancestor == nil {
@@ -4446,6 +5523,16 @@ func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 		e.Process.ExecEvent.Cookie = uint32(v)
 		return nil
 
+	case "process.created_at":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Process.ExecEvent.CreatedAt"}
+		}
+		e.Process.ExecEvent.CreatedAt = uint64(v)
+		return nil
+
 	case "process.filename":
 
 		var ok bool
@@ -4556,6 +5643,36 @@ func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 		}
 		return nil
 
+	case "ptrace.pid":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "PTrace.PID"}
+		}
+		e.PTrace.PID = uint32(v)
+		return nil
+
+	case "ptrace.request":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "PTrace.Request"}
+		}
+		e.PTrace.Request = uint32(v)
+		return nil
+
+	case "ptrace.retval":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "PTrace.SyscallEvent.Retval"}
+		}
+		e.PTrace.SyscallEvent.Retval = int64(v)
+		return nil
+
 	case "removexattr.basename":
 
 		var ok bool
@@ -4848,6 +5965,36 @@ func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 		e.SetXAttr.SyscallEvent.Retval = int64(v)
 		return nil
 
+	case "signal.pid":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Signal.PID"}
+		}
+		e.Signal.PID = uint32(v)
+		return nil
+
+	case "signal.retval":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Signal.SyscallEvent.Retval"}
+		}
+		e.Signal.SyscallEvent.Retval = int64(v)
+		return nil
+
+	case "signal.type":
+
+		var ok bool
+		v, ok := value.(int)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Signal.Type"}
+		}
+		e.Signal.Type = uint32(v)
+		return nil
+
 	case "unlink.basename":
 
 		var ok bool
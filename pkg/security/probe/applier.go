@@ -11,12 +11,20 @@ import (
 	"math"
 
 	"github.com/DataDog/datadog-agent/pkg/security/config"
+	"github.com/DataDog/datadog-agent/pkg/security/model"
 	"github.com/DataDog/datadog-agent/pkg/security/rules"
 	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/pkg/errors"
 )
 
+// ActivityDumpEventTypes lists the event types recorded by the activity dumps. They are enabled regardless of the
+// rules when the activity dumps are enabled.
+var ActivityDumpEventTypes = []eval.EventType{
+	model.ExecEventType.String(),
+	model.FileOpenEventType.String(),
+}
+
 // RuleSetApplier defines a rule set applier. It applies rules using an Applier
 type RuleSetApplier struct {
 	config   *config.Config
@@ -58,8 +66,8 @@ func (rsa *RuleSetApplier) setupFilters(rs *rules.RuleSet, eventType eval.EventT
 		return nil
 	}
 
-	// if approvers disabled
-	if !rsa.config.EnableApprovers {
+	// if approvers disabled, or if the activity dumps need all the events of the type
+	if !rsa.config.EnableApprovers || rsa.isDumped(eventType) {
 		return rsa.applyFilterPolicy(eventType, PolicyModeAccept, math.MaxUint8)
 	}
 
@@ -91,20 +99,51 @@ func (rsa *RuleSetApplier) setupFilters(rs *rules.RuleSet, eventType eval.EventT
 	return nil
 }
 
+// isDumped returns whether the event type is recorded by the activity dumps
+func (rsa *RuleSetApplier) isDumped(eventType eval.EventType) bool {
+	if !rsa.config.ActivityDumpEnabled {
+		return false
+	}
+
+	for _, dumped := range ActivityDumpEventTypes {
+		if dumped == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// getEventTypes returns the event types of the rules, and the ones recorded by the activity dumps
+func (rsa *RuleSetApplier) getEventTypes(rs *rules.RuleSet) []eval.EventType {
+	eventTypes := rs.GetEventTypes()
+	if !rsa.config.ActivityDumpEnabled {
+		return eventTypes
+	}
+
+	for _, dumped := range ActivityDumpEventTypes {
+		if !rs.HasRulesForEventType(dumped) {
+			eventTypes = append(eventTypes, dumped)
+		}
+	}
+	return eventTypes
+}
+
 // Apply setup the filters for the provided set of rules and returns the policy report.
 func (rsa *RuleSetApplier) Apply(rs *rules.RuleSet) (*Report, error) {
+	eventTypes := rsa.getEventTypes(rs)
+
 	if rsa.probe != nil {
 		if err := rsa.probe.FlushDiscarders(); err != nil {
 			return nil, errors.Wrap(err, "failed to flush discarders")
 		}
 
 		// based on the ruleset and the requested rules, select the probes that need to be activated
-		if err := rsa.probe.SelectProbes(rs); err != nil {
+		if err := rsa.probe.SelectProbes(eventTypes); err != nil {
 			return nil, errors.Wrap(err, "failed to select probes")
 		}
 	}
 
-	for _, eventType := range rs.GetEventTypes() {
+	for _, eventType := range eventTypes {
 		if err := rsa.setupFilters(rs, eventType); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,56 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux
+
+package probe
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/security/config"
+	"github.com/DataDog/datadog-agent/pkg/security/log"
+	"github.com/DataDog/datadog-agent/pkg/security/model"
+	"github.com/DataDog/datadog-agent/pkg/security/rules"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
+)
+
+func TestRuleSetApplierActivityDump(t *testing.T) {
+	enabled := map[eval.EventType]bool{"*": true}
+
+	for _, dump := range []bool{false, true} {
+		rs := rules.NewRuleSet(&Model{}, func() eval.Event { return &Event{} }, rules.NewOptsWithParams(model.SECLConstants, nil, enabled, nil, log.DatadogAgentLogger{}))
+		addRuleExpr(t, rs, `open.filename == "/etc/passwd"`)
+
+		cfg := &config.Config{EnableKernelFilters: true, EnableApprovers: true, ActivityDumpEnabled: dump}
+		report, err := NewRuleSetApplier(cfg, nil).Apply(rs)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		open := report.Policies["open"]
+		if open == nil {
+			t.Fatal("expected a filter policy for open")
+		}
+
+		if !dump {
+			if open.Mode != PolicyModeDeny || len(open.Approvers) == 0 {
+				t.Errorf("expected the approvers of the rule to be applied, got %+v", open)
+			}
+			if _, exists := report.Policies["exec"]; exists {
+				t.Error("exec shouldn't be enabled without any rule")
+			}
+			continue
+		}
+
+		// the activity dumps need all the open and exec events
+		if open.Mode != PolicyModeAccept {
+			t.Errorf("expected the approvers to be bypassed, got %+v", open)
+		}
+		if exec := report.Policies["exec"]; exec == nil || exec.Mode != PolicyModeAccept {
+			t.Errorf("expected exec to be enabled, got %+v", exec)
+		}
+	}
+}
@@ -8,11 +8,20 @@
 package probe
 
 import (
+	"sync"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+
 	"github.com/DataDog/datadog-agent/pkg/security/utils"
 )
 
+const containerImageCacheSize = 256
+
 // ContainerResolver is used to resolve the container context of the events
-type ContainerResolver struct{}
+type ContainerResolver struct {
+	sync.Mutex
+	imageCache *simplelru.LRU
+}
 
 // GetContainerID returns the container id of the given pid
 func (cr *ContainerResolver) GetContainerID(pid uint32) (utils.ContainerID, error) {
@@ -25,3 +34,31 @@ func (cr *ContainerResolver) ResolveLabels(containerID string) ([]string, error)
 	// Do not use the tagger for now
 	return []string{}, nil
 }
+
+// ResolveImage resolves the image name of a container from its container ID
+func (cr *ContainerResolver) ResolveImage(containerID string) (string, error) {
+	cr.Lock()
+	defer cr.Unlock()
+
+	if cachedEntry, found := cr.imageCache.Get(containerID); found {
+		return cachedEntry.(string), nil
+	}
+
+	// failed resolutions are cached as well so that the container runtime isn't queried for every event
+	image, err := resolveContainerImage(containerID)
+	cr.imageCache.Add(containerID, image)
+
+	return image, err
+}
+
+// NewContainerResolver instantiates a new container resolver
+func NewContainerResolver() (*ContainerResolver, error) {
+	imageCache, err := simplelru.NewLRU(containerImageCacheSize, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ContainerResolver{
+		imageCache: imageCache,
+	}, nil
+}
@@ -0,0 +1,27 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux,docker
+
+package probe
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+)
+
+// resolveContainerImage queries the docker daemon for the image of a container
+func resolveContainerImage(containerID string) (string, error) {
+	du, err := docker.GetDockerUtil()
+	if err != nil {
+		return "", err
+	}
+
+	container, err := du.Inspect(containerID, false)
+	if err != nil {
+		return "", err
+	}
+
+	return du.ResolveImageNameFromContainer(container)
+}
@@ -0,0 +1,17 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux,!docker
+
+package probe
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+)
+
+// resolveContainerImage is not supported without docker
+func resolveContainerImage(containerID string) (string, error) {
+	return "", docker.ErrDockerNotCompiled
+}
@@ -13,6 +13,7 @@ import (
 	"encoding/json"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/security/activitydump"
 	"github.com/DataDog/datadog-agent/pkg/security/model"
 	"github.com/DataDog/datadog-agent/pkg/security/rules"
 	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
@@ -28,6 +29,8 @@ const (
 	NoisyProcessRuleID = "noisy_process"
 	// AbnormalPathRuleID is the rule ID for the abnormal_path events
 	AbnormalPathRuleID = "abnormal_path"
+	// AnomalyDetectedRuleID is the rule ID for the anomaly_detected events
+	AnomalyDetectedRuleID = "anomaly_detected"
 )
 
 // AllCustomRuleIDs returns the list of custom rule IDs
@@ -37,6 +40,7 @@ func AllCustomRuleIDs() []string {
 		RulesetLoadedRuleID,
 		NoisyProcessRuleID,
 		AbnormalPathRuleID,
+		AnomalyDetectedRuleID,
 	}
 }
 
@@ -321,3 +325,28 @@ func NewAbnormalPathEvent(event *Event, pathResolutionError error) (*rules.Rule,
 			PathResolutionError: pathResolutionError.Error(),
 		}.MarshalJSON)
 }
+
+// AnomalyDetectedEvent is used to report that an event deviates from the activity profile of its container image
+// easyjson:json
+type AnomalyDetectedEvent struct {
+	Timestamp time.Time        `json:"date"`
+	Image     string           `json:"image"`
+	Anomaly   string           `json:"anomaly"`
+	Value     string           `json:"value"`
+	Parent    string           `json:"parent,omitempty"`
+	Event     *EventSerializer `json:"triggering_event"`
+}
+
+// NewAnomalyDetectedEvent returns the rule and a populated custom event for a anomaly_detected event
+func NewAnomalyDetectedEvent(event *Event, anomaly *activitydump.Anomaly) (*rules.Rule, *CustomEvent) {
+	return newRule(&rules.RuleDefinition{
+			ID: AnomalyDetectedRuleID,
+		}), newCustomEvent(model.CustomAnomalyDetectedEventType, AnomalyDetectedEvent{
+			Timestamp: event.ResolveEventTimestamp(),
+			Image:     anomaly.Image,
+			Anomaly:   string(anomaly.Type),
+			Value:     anomaly.Value,
+			Parent:    anomaly.Parent,
+			Event:     newEventSerializer(event),
+		}.MarshalJSON)
+}
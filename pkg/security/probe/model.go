@@ -123,6 +123,16 @@ func (ev *Event) ResolveContainerID(e *model.ContainerContext) string {
 	return e.ID
 }
 
+// ResolveContainerImage resolves the image of the container of the event
+func (ev *Event) ResolveContainerImage(e *model.ContainerContext) string {
+	if len(e.Image) == 0 {
+		if id := ev.ResolveContainerID(e); len(id) > 0 {
+			e.Image, _ = ev.resolvers.ContainerResolver.ResolveImage(id)
+		}
+	}
+	return e.Image
+}
+
 // UnmarshalExecEvent unmarshal an ExecEvent
 func (ev *Event) UnmarshalExecEvent(data []byte) (int, error) {
 	if len(data) < 136 {
@@ -271,6 +281,20 @@ func (ev *Event) ResolveExecExecTimestamp(e *model.ExecEvent) time.Time {
 	return e.ExecTime
 }
 
+// ResolveExecCreatedAt resolves the creation time of the process, in nanoseconds since the epoch
+func (ev *Event) ResolveExecCreatedAt(e *model.ExecEvent) int {
+	if e.CreatedAt == 0 && ev != nil {
+		createdAt := ev.ResolveExecForkTimestamp(e)
+		if createdAt.IsZero() {
+			createdAt = ev.ResolveExecExecTimestamp(e)
+		}
+		if !createdAt.IsZero() {
+			e.CreatedAt = uint64(createdAt.UnixNano())
+		}
+	}
+	return int(e.CreatedAt)
+}
+
 // ResolveExecExitTimestamp returns the exit timestamp of the process
 func (ev *Event) ResolveExecExitTimestamp(e *model.ExecEvent) time.Time {
 	if e.ExitTime.IsZero() && ev != nil {
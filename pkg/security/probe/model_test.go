@@ -39,6 +39,22 @@ func TestAbsolutePath(t *testing.T) {
 	}
 }
 
+func TestIPAddress(t *testing.T) {
+	model := &Model{}
+	if err := model.ValidateField("connect.addr.ip", eval.FieldValue{Value: "10.0.0.1", Type: eval.ScalarValueType}); err != nil {
+		t.Fatalf("shouldn't return an error: %s", err)
+	}
+	if err := model.ValidateField("connect.addr.ip", eval.FieldValue{Value: "fd00::/8", Type: eval.IPNetValueType}); err != nil {
+		t.Fatalf("shouldn't return an error: %s", err)
+	}
+	if err := model.ValidateField("bind.addr.ip", eval.FieldValue{Value: "10.0.*", Type: eval.PatternValueType}); err != nil {
+		t.Fatalf("shouldn't return an error: %s", err)
+	}
+	if err := model.ValidateField("connect.addr.ip", eval.FieldValue{Value: "10.0.0.256", Type: eval.ScalarValueType}); err == nil {
+		t.Fatal("should return an error")
+	}
+}
+
 func TestSetFieldValue(t *testing.T) {
 	event := &Event{}
 
@@ -57,6 +73,10 @@ func TestSetFieldValue(t *testing.T) {
 			if err = event.SetFieldValue(field, 123); err != nil {
 				t.Fatal(err)
 			}
+		case reflect.Bool:
+			if err = event.SetFieldValue(field, true); err != nil {
+				t.Fatal(err)
+			}
 		default:
 			t.Fatalf("type unknown: %v", kind)
 		}
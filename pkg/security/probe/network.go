@@ -0,0 +1,117 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux
+
+package probe
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/security/ebpf"
+	"github.com/DataDog/datadog-agent/pkg/security/model"
+	"github.com/DataDog/datadog-agent/pkg/security/rules"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
+	"github.com/DataDog/datadog-agent/pkg/util/kernel"
+)
+
+var (
+	// KERNEL_VERSION(a,b,c) = (a << 16) + (b << 8) + (c)
+	kernel6_4 = kernel.VersionCode(6, 4, 0)
+)
+
+// getIovIterIovOffset returns the offset of the union holding the iovec pointer, or the user buffer of an ITER_UBUF,
+// in struct iov_iter
+func getIovIterIovOffset(probe *Probe) uint64 {
+	if probe.kernelVersion != 0 && probe.kernelVersion >= kernel6_4 {
+		return 16
+	}
+	return 24
+}
+
+// getIovIterCountOffset returns the offset of the count field in struct iov_iter
+func getIovIterCountOffset(probe *Probe) uint64 {
+	if probe.kernelVersion != 0 && probe.kernelVersion >= kernel6_4 {
+		return 24
+	}
+	return 16
+}
+
+func addrCapabilities(event string) Capabilities {
+	return Capabilities{
+		event + ".addr.port": {
+			PolicyFlags:     PolicyFlagPort,
+			FieldValueTypes: eval.ScalarValueType,
+		},
+		event + ".addr.family": {
+			PolicyFlags:     PolicyFlagFamily,
+			FieldValueTypes: eval.ScalarValueType,
+		},
+	}
+}
+
+func approvePort(eventType model.EventType, port int) (activeApprover, error) {
+	if port < 0 || port > 0xffff {
+		return nil, fmt.Errorf("invalid port %d", port)
+	}
+
+	return &mapEventMask{
+		tableName: "port_approvers",
+		key:       port,
+		tableKey:  ebpf.NetworkUint16MapItem(port),
+		eventMask: uint64(1 << (eventType - 1)),
+	}, nil
+}
+
+func approveFamily(eventType model.EventType, family int) (activeApprover, error) {
+	if family < 0 || family > 0xffff {
+		return nil, fmt.Errorf("invalid address family %d", family)
+	}
+
+	return &mapEventMask{
+		tableName: "family_approvers",
+		key:       family,
+		tableKey:  ebpf.Uint16MapItem(family),
+		eventMask: uint64(1 << (eventType - 1)),
+	}, nil
+}
+
+func onNewAddrApproversWrapper(eventType model.EventType) onApproverHandler {
+	return func(probe *Probe, approvers rules.Approvers) (activeApprovers, error) {
+		prefix := eventType.String()
+
+		var addrApprovers []activeApprover
+		for field, values := range approvers {
+			var approve func(eventType model.EventType, value int) (activeApprover, error)
+
+			switch field {
+			case prefix + ".addr.port":
+				approve = approvePort
+			case prefix + ".addr.family":
+				approve = approveFamily
+			default:
+				return nil, fmt.Errorf("unknown field '%s'", field)
+			}
+
+			for _, value := range values {
+				activeApprover, err := approve(eventType, value.Value.(int))
+				if err != nil {
+					return nil, err
+				}
+				addrApprovers = append(addrApprovers, activeApprover)
+			}
+		}
+
+		return newActiveKFilters(addrApprovers...), nil
+	}
+}
+
+func init() {
+	allCapabilities["bind"] = addrCapabilities("bind")
+	allCapabilities["connect"] = addrCapabilities("connect")
+
+	allApproversHandlers["bind"] = onNewAddrApproversWrapper(model.BindEventType)
+	allApproversHandlers["connect"] = onNewAddrApproversWrapper(model.ConnectEventType)
+}
@@ -30,6 +30,8 @@ const (
 	PolicyFlagBasename PolicyFlag = 1
 	PolicyFlagFlags    PolicyFlag = 2
 	PolicyFlagMode     PolicyFlag = 4
+	PolicyFlagPort     PolicyFlag = 16
+	PolicyFlagFamily   PolicyFlag = 32
 
 	// need to be aligned with the kernel size
 	BasenameFilterSize = 32
@@ -69,5 +71,11 @@ func (f PolicyFlag) MarshalJSON() ([]byte, error) {
 	if f&PolicyFlagMode != 0 {
 		flags = append(flags, `"mode"`)
 	}
+	if f&PolicyFlagPort != 0 {
+		flags = append(flags, `"port"`)
+	}
+	if f&PolicyFlagFamily != 0 {
+		flags = append(flags, `"family"`)
+	}
 	return []byte("[" + strings.Join(flags, ",") + "]"), nil
 }
@@ -421,6 +421,42 @@ func (p *Probe) handleEvent(CPU uint64, data []byte) {
 		event.updateProcessCachePointer(p.resolvers.ProcessResolver.AddExecEntry(event.Process.Pid, event.processCacheEntry))
 	case model.ExitEventType:
 		defer p.resolvers.ProcessResolver.DeleteEntry(event.Process.Pid, event.ResolveEventTimestamp())
+	case model.SignalEventType:
+		if _, err := event.Signal.UnmarshalBinary(data[offset:]); err != nil {
+			log.Errorf("failed to decode signal event: %s (offset %d, len %d)", err, offset, dataLen)
+			return
+		}
+	case model.PTraceEventType:
+		if _, err := event.PTrace.UnmarshalBinary(data[offset:]); err != nil {
+			log.Errorf("failed to decode ptrace event: %s (offset %d, len %d)", err, offset, dataLen)
+			return
+		}
+	case model.BPFEventType:
+		if _, err := event.BPF.UnmarshalBinary(data[offset:]); err != nil {
+			log.Errorf("failed to decode bpf event: %s (offset %d, len %d)", err, offset, dataLen)
+			return
+		}
+	case model.LoadModuleEventType:
+		if _, err := event.LoadModule.UnmarshalBinary(data[offset:]); err != nil {
+			log.Errorf("failed to decode load_module event: %s (offset %d, len %d)", err, offset, dataLen)
+			return
+		}
+	case model.ConnectEventType:
+		if _, err := event.Connect.UnmarshalBinary(data[offset:]); err != nil {
+			log.Errorf("failed to decode connect event: %s (offset %d, len %d)", err, offset, dataLen)
+			return
+		}
+	case model.BindEventType:
+		if _, err := event.Bind.UnmarshalBinary(data[offset:]); err != nil {
+			log.Errorf("failed to decode bind event: %s (offset %d, len %d)", err, offset, dataLen)
+			return
+		}
+	case model.DNSEventType:
+		if _, err := event.DNS.UnmarshalBinary(data[offset:]); err != nil {
+			// any payload sent to port 53 is captured, it may not be a DNS query
+			log.Debugf("failed to decode dns event: %s (offset %d, len %d)", err, offset, dataLen)
+			return
+		}
 	default:
 		log.Errorf("unsupported event type %d", eventType)
 		return
@@ -508,13 +544,17 @@ func (p *Probe) SetApprovers(eventType eval.EventType, approvers rules.Approvers
 	return nil
 }
 
-// SelectProbes applies the loaded set of rules and returns a report
-// of the applied approvers for it.
-func (p *Probe) SelectProbes(rs *rules.RuleSet) error {
+// SelectProbes activates the probes and enables the events of the given event types
+func (p *Probe) SelectProbes(eventTypes []eval.EventType) error {
 	var activatedProbes []manager.ProbesSelector
 
+	enabled := make(map[eval.EventType]bool)
+	for _, eventType := range eventTypes {
+		enabled[eventType] = true
+	}
+
 	for eventType, selectors := range probes.SelectorsPerEventType {
-		if eventType == "*" || rs.HasRulesForEventType(eventType) {
+		if eventType == "*" || enabled[eventType] {
 			activatedProbes = append(activatedProbes, selectors...)
 		}
 	}
@@ -547,7 +587,7 @@ func (p *Probe) SelectProbes(rs *rules.RuleSet) error {
 	}
 
 	enabledEvents := uint64(0)
-	for _, eventName := range rs.GetEventTypes() {
+	for _, eventName := range eventTypes {
 		if eventName != "*" {
 			eventType := model.ParseEvalEventType(eventName)
 			if eventType == model.UnknownEventType {
@@ -740,6 +780,14 @@ func NewProbe(config *config.Config, client *statsd.Client) (*Probe, error) {
 			Name:  "sb_magic_offset",
 			Value: getSuperBlockMagicOffset(p),
 		},
+		manager.ConstantEditor{
+			Name:  "iov_iter_iov_offset",
+			Value: getIovIterIovOffset(p),
+		},
+		manager.ConstantEditor{
+			Name:  "iov_iter_count_offset",
+			Value: getIovIterCountOffset(p),
+		},
 	)
 	p.managerOptions.ConstantEditors = append(p.managerOptions.ConstantEditors, erpc.GetConstants()...)
 	p.managerOptions.ConstantEditors = append(p.managerOptions.ConstantEditors, DiscarderConstants...)
@@ -51,12 +51,17 @@ func NewResolvers(probe *Probe, client *statsd.Client) (*Resolvers, error) {
 		return nil, err
 	}
 
+	containerResolver, err := NewContainerResolver()
+	if err != nil {
+		return nil, err
+	}
+
 	resolvers := &Resolvers{
 		probe:             probe,
 		DentryResolver:    dentryResolver,
 		MountResolver:     NewMountResolver(probe),
 		TimeResolver:      timeResolver,
-		ContainerResolver: &ContainerResolver{},
+		ContainerResolver: containerResolver,
 		UserGroupResolver: userGroupResolver,
 	}
 
@@ -10,9 +10,12 @@
 package probe
 
 import (
+	"fmt"
 	"syscall"
 	"time"
 
+	"golang.org/x/sys/unix"
+
 	"github.com/DataDog/datadog-agent/pkg/security/model"
 	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
 )
@@ -21,6 +24,8 @@ import (
 const (
 	FIMCategory     = "File Activity"
 	ProcessActivity = "Process Activity"
+	KernelActivity  = "Kernel Activity"
+	NetworkActivity = "Network Activity"
 )
 
 // FileSerializer serializes a file to JSON
@@ -76,7 +81,8 @@ type ProcessCacheEntrySerializer struct {
 // ContainerContextSerializer serializes a container context to JSON
 // easyjson:json
 type ContainerContextSerializer struct {
-	ID string `json:"id,omitempty"`
+	ID    string `json:"id,omitempty"`
+	Image string `json:"image,omitempty"`
 }
 
 // FileEventSerializer serializes a file event to JSON
@@ -108,6 +114,66 @@ type ProcessContextSerializer struct {
 	Ancestors []*ProcessCacheEntrySerializer `json:"ancestors,omitempty"`
 }
 
+// SignalEventSerializer serializes a signal event to JSON
+// easyjson:json
+type SignalEventSerializer struct {
+	Type string `json:"type"`
+	PID  uint32 `json:"pid"`
+}
+
+// PTraceEventSerializer serializes a ptrace event to JSON
+// easyjson:json
+type PTraceEventSerializer struct {
+	Request string `json:"request"`
+	PID     uint32 `json:"pid"`
+	Address string `json:"address,omitempty"`
+}
+
+// BPFEventSerializer serializes a bpf event to JSON
+// easyjson:json
+type BPFEventSerializer struct {
+	Cmd      string `json:"cmd"`
+	ProgType string `json:"prog_type,omitempty"`
+	ProgName string `json:"prog_name,omitempty"`
+}
+
+// LoadModuleEventSerializer serializes a kernel module load event to JSON
+// easyjson:json
+type LoadModuleEventSerializer struct {
+	Name             string `json:"name,omitempty"`
+	LoadedFromMemory bool   `json:"loaded_from_memory"`
+}
+
+// AddrSerializer serializes a socket address to JSON
+// easyjson:json
+type AddrSerializer struct {
+	Family string `json:"family"`
+	IP     string `json:"ip,omitempty"`
+	Port   uint16 `json:"port,omitempty"`
+}
+
+// NetworkEventSerializer serializes a connect or a bind event to JSON
+// easyjson:json
+type NetworkEventSerializer struct {
+	Addr AddrSerializer `json:"addr"`
+}
+
+// DNSQuestionSerializer serializes a DNS question to JSON
+// easyjson:json
+type DNSQuestionSerializer struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Class string `json:"class"`
+	Count uint16 `json:"count"`
+}
+
+// DNSEventSerializer serializes a DNS event to JSON
+// easyjson:json
+type DNSEventSerializer struct {
+	ID       uint16                `json:"id"`
+	Question DNSQuestionSerializer `json:"question"`
+}
+
 // EventSerializer serializes an event to JSON
 // easyjson:json
 type EventSerializer struct {
@@ -116,6 +182,13 @@ type EventSerializer struct {
 	UserContextSerializer      UserContextSerializer       `json:"usr,omitempty"`
 	ProcessContextSerializer   *ProcessContextSerializer   `json:"process,omitempty"`
 	ContainerContextSerializer *ContainerContextSerializer `json:"container,omitempty"`
+	SignalEventSerializer      *SignalEventSerializer      `json:"signal,omitempty"`
+	PTraceEventSerializer      *PTraceEventSerializer      `json:"ptrace,omitempty"`
+	BPFEventSerializer         *BPFEventSerializer         `json:"bpf,omitempty"`
+	LoadModuleEventSerializer  *LoadModuleEventSerializer  `json:"module,omitempty"`
+	ConnectEventSerializer     *NetworkEventSerializer     `json:"connect,omitempty"`
+	BindEventSerializer        *NetworkEventSerializer     `json:"bind,omitempty"`
+	DNSEventSerializer         *DNSEventSerializer         `json:"dns,omitempty"`
 	Date                       time.Time                   `json:"date,omitempty"`
 }
 
@@ -215,7 +288,8 @@ func newProcessCacheEntrySerializer(pce *model.ProcessCacheEntry, e *Event, r *R
 
 func newContainerContextSerializer(cc *model.ContainerContext, e *Event) *ContainerContextSerializer {
 	return &ContainerContextSerializer{
-		ID: e.ResolveContainerID(cc),
+		ID:    e.ResolveContainerID(cc),
+		Image: e.ResolveContainerImage(cc),
 	}
 }
 
@@ -266,6 +340,16 @@ func serializeSyscallRetval(retval int64) string {
 	}
 }
 
+func newNetworkEventSerializer(addr *model.AddrContext) *NetworkEventSerializer {
+	return &NetworkEventSerializer{
+		Addr: AddrSerializer{
+			Family: model.AddressFamily(addr.Family).String(),
+			IP:     addr.IP,
+			Port:   addr.Port,
+		},
+	}
+}
+
 func newEventSerializer(event *Event) *EventSerializer {
 	s := &EventSerializer{
 		EventContextSerializer: &EventContextSerializer{
@@ -391,6 +475,65 @@ func newEventSerializer(event *Event) *EventSerializer {
 		}
 		s.EventContextSerializer.Outcome = serializeSyscallRetval(0)
 		s.Category = ProcessActivity
+	case model.SignalEventType:
+		s.SignalEventSerializer = &SignalEventSerializer{
+			Type: model.Signal(event.Signal.Type).String(),
+			PID:  event.Signal.PID,
+		}
+		s.EventContextSerializer.Outcome = serializeSyscallRetval(event.Signal.Retval)
+		s.Category = ProcessActivity
+	case model.PTraceEventType:
+		s.PTraceEventSerializer = &PTraceEventSerializer{
+			Request: model.PTraceRequest(event.PTrace.Request).String(),
+			PID:     event.PTrace.PID,
+		}
+		if event.PTrace.Address != 0 {
+			s.PTraceEventSerializer.Address = fmt.Sprintf("0x%x", event.PTrace.Address)
+		}
+		s.EventContextSerializer.Outcome = serializeSyscallRetval(event.PTrace.Retval)
+		s.Category = ProcessActivity
+	case model.BPFEventType:
+		s.BPFEventSerializer = &BPFEventSerializer{
+			Cmd: model.BPFCmd(event.BPF.Cmd).String(),
+		}
+		if event.BPF.Cmd == unix.BPF_PROG_LOAD {
+			s.BPFEventSerializer.ProgType = model.BPFProgramType(event.BPF.ProgType).String()
+			s.BPFEventSerializer.ProgName = event.BPF.ProgName
+		}
+		s.EventContextSerializer.Outcome = serializeSyscallRetval(event.BPF.Retval)
+		s.Category = KernelActivity
+	case model.LoadModuleEventType:
+		s.LoadModuleEventSerializer = &LoadModuleEventSerializer{
+			Name:             event.LoadModule.Name,
+			LoadedFromMemory: event.LoadModule.LoadedFromMemory,
+		}
+		s.EventContextSerializer.Outcome = serializeSyscallRetval(event.LoadModule.Retval)
+		s.Category = KernelActivity
+	case model.ConnectEventType:
+		s.ConnectEventSerializer = newNetworkEventSerializer(&event.Connect.Addr)
+		// non blocking connections are still in progress when the syscall returns
+		if event.Connect.Retval == -int64(syscall.EINPROGRESS) {
+			s.EventContextSerializer.Outcome = serializeSyscallRetval(0)
+		} else {
+			s.EventContextSerializer.Outcome = serializeSyscallRetval(event.Connect.Retval)
+		}
+		s.Category = NetworkActivity
+	case model.BindEventType:
+		s.BindEventSerializer = newNetworkEventSerializer(&event.Bind.Addr)
+		s.EventContextSerializer.Outcome = serializeSyscallRetval(event.Bind.Retval)
+		s.Category = NetworkActivity
+	case model.DNSEventType:
+		s.DNSEventSerializer = &DNSEventSerializer{
+			ID: event.DNS.ID,
+			Question: DNSQuestionSerializer{
+				Name:  event.DNS.Question.Name,
+				Type:  model.QType(event.DNS.Question.Type).String(),
+				Class: model.QClass(event.DNS.Question.Class).String(),
+				Count: event.DNS.Question.Count,
+			},
+		}
+		s.EventContextSerializer.Outcome = serializeSyscallRetval(0)
+		s.Category = NetworkActivity
 	}
 
 	return s
@@ -0,0 +1,191 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
+)
+
+// VariableScope represents the object a variable set by a rule action is attached to
+type VariableScope = eval.VariableScope
+
+const (
+	// GlobalScope doesn't attach the variable to any object
+	GlobalScope VariableScope = eval.GlobalScope
+	// ProcessScope attaches the variable to the process that triggered the event
+	ProcessScope VariableScope = "process"
+	// ContainerScope attaches the variable to the container of the process that triggered the event
+	ContainerScope VariableScope = "container"
+)
+
+// DefaultVariableScopes maps the variable scopes to the field identifying the objects of the scope
+var DefaultVariableScopes = map[VariableScope]eval.Field{
+	ProcessScope:   "process.pid",
+	ContainerScope: "container.id",
+}
+
+// DefaultMaxVariableValues is the default maximum number of variable values held by a rule set
+const DefaultMaxVariableValues = 16384
+
+// Severities lists the severities that can be reported by a rule
+var Severities = []string{"info", "low", "medium", "high", "critical"}
+
+// KillSignals lists the signals that can be sent by the `kill` action
+var KillSignals = []string{"SIGKILL", "SIGTERM"}
+
+// ActionDefinition describes an action performed when a rule matches. Exactly one of its members has to be set.
+type ActionDefinition struct {
+	Set      *SetDefinition      `yaml:"set"`
+	Report   *ReportDefinition   `yaml:"report"`
+	Suppress *SuppressDefinition `yaml:"suppress"`
+	Kill     *KillDefinition     `yaml:"kill"`
+}
+
+// SetDefinition describes the `set` action, which sets a variable, globally or on the process or the container
+// of the event. Rule expressions can read the variable with the `${scope.name}` syntax until it expires.
+type SetDefinition struct {
+	Name  string        `yaml:"name"`
+	Value interface{}   `yaml:"value"`
+	Scope VariableScope `yaml:"scope"`
+	TTL   time.Duration `yaml:"ttl"`
+}
+
+// ReportDefinition describes the `report` action, which reports the event with a custom severity
+type ReportDefinition struct {
+	Severity string `yaml:"severity"`
+}
+
+// SuppressDefinition describes the `suppress` action, which suppresses the events of the rule for a
+// duration once an event was sent
+type SuppressDefinition struct {
+	Duration time.Duration `yaml:"duration"`
+}
+
+// KillDefinition describes the `kill` action, which sends a signal to the process that triggered the event. The
+// action is only enforced for the rules allowed by the configuration of the agent.
+type KillDefinition struct {
+	Signal string `yaml:"signal"`
+}
+
+// RateLimitDefinition describes the token bucket used to limit the rate of events sent for a rule
+type RateLimitDefinition struct {
+	// Limit is the number of events per second
+	Limit float64 `yaml:"limit"`
+	// Burst is the maximum number of events that can be sent at once
+	Burst int `yaml:"burst"`
+}
+
+// Check returns an error if the action is not valid. It sets the default values of the action.
+func (a *ActionDefinition) Check() error {
+	count := 0
+	if a.Set != nil {
+		count++
+	}
+	if a.Report != nil {
+		count++
+	}
+	if a.Suppress != nil {
+		count++
+	}
+	if a.Kill != nil {
+		count++
+	}
+	if count != 1 {
+		return errors.New("an action must have exactly one of `set`, `report`, `suppress` or `kill`")
+	}
+
+	switch {
+	case a.Set != nil:
+		if a.Set.Name == "" {
+			return errors.New("no variable name defined for `set` action")
+		}
+		if !checkRuleID(a.Set.Name) {
+			return fmt.Errorf("variable name `%s` does not match pattern `%s`", a.Set.Name, ruleIDPattern)
+		}
+		switch a.Set.Scope {
+		case "":
+			a.Set.Scope = ProcessScope
+		case GlobalScope, ProcessScope, ContainerScope:
+		default:
+			return fmt.Errorf("invalid scope `%s` for variable `%s`", a.Set.Scope, a.Set.Name)
+		}
+		switch a.Set.Value.(type) {
+		case nil:
+			a.Set.Value = true
+		case string, int, bool:
+		default:
+			return fmt.Errorf("invalid value for variable `%s`: only strings, integers and booleans are supported", a.Set.Name)
+		}
+		if a.Set.TTL < 0 {
+			return fmt.Errorf("invalid ttl for variable `%s`", a.Set.Name)
+		}
+	case a.Report != nil:
+		for _, severity := range Severities {
+			if a.Report.Severity == severity {
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid severity `%s` for `report` action", a.Report.Severity)
+	case a.Suppress != nil:
+		if a.Suppress.Duration <= 0 {
+			return errors.New("no duration defined for `suppress` action")
+		}
+	case a.Kill != nil:
+		if a.Kill.Signal == "" {
+			a.Kill.Signal = "SIGKILL"
+		}
+		for _, signal := range KillSignals {
+			if a.Kill.Signal == signal {
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid signal `%s` for `kill` action", a.Kill.Signal)
+	}
+
+	return nil
+}
+
+// Check returns an error if the rate limit is not valid. It sets the default values of the rate limit.
+func (rl *RateLimitDefinition) Check() error {
+	if rl.Limit <= 0 {
+		return errors.New("rate limit must be positive")
+	}
+	if rl.Burst < 0 {
+		return errors.New("rate limit burst can't be negative")
+	}
+	if rl.Burst == 0 {
+		rl.Burst = int(rl.Limit)
+		if rl.Burst < 1 {
+			rl.Burst = 1
+		}
+	}
+	return nil
+}
+
+// GetSeverity returns the severity reported by the rule, if any
+func (rd *RuleDefinition) GetSeverity() string {
+	for _, action := range rd.Actions {
+		if action.Report != nil {
+			return action.Report.Severity
+		}
+	}
+	return ""
+}
+
+// GetKillAction returns the `kill` action of the rule, if any
+func (rd *RuleDefinition) GetKillAction() *KillDefinition {
+	for _, action := range rd.Actions {
+		if action.Kill != nil {
+			return action.Kill
+		}
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
+)
+
+// EventFixture describes an event, using the values of its fields, and the rules expected to match it
+type EventFixture struct {
+	Name   string                     `json:"name"`
+	Type   eval.EventType             `json:"type"`
+	Fields map[eval.Field]interface{} `json:"fields"`
+	Match  []RuleID                   `json:"match"`
+}
+
+// EventFixtureResult describes the result of the evaluation of an event fixture
+type EventFixtureResult struct {
+	Name       string   `json:"name"`
+	Matched    []RuleID `json:"matched"`
+	Missing    []RuleID `json:"missing,omitempty"`
+	Unexpected []RuleID `json:"unexpected,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// Passed returns whether the event matched exactly the expected rules
+func (r *EventFixtureResult) Passed() bool {
+	return r.Error == "" && len(r.Missing) == 0 && len(r.Unexpected) == 0
+}
+
+// LoadEventFixtures loads a JSON list of event fixtures
+func LoadEventFixtures(r io.Reader) ([]*EventFixture, error) {
+	var fixtures []*EventFixture
+
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&fixtures); err != nil {
+		return nil, errors.Wrap(err, "failed to load event fixtures")
+	}
+
+	for i, fixture := range fixtures {
+		if fixture.Name == "" {
+			fixture.Name = fmt.Sprintf("fixture #%d", i+1)
+		}
+	}
+
+	return fixtures, nil
+}
+
+// NewEvent returns the event described by the fixture. newEvent returns an empty event of the given type.
+func (f *EventFixture) NewEvent(newEvent func(eventType eval.EventType) eval.Event) (eval.Event, error) {
+	fields := make([]eval.Field, 0, len(f.Fields))
+	for field := range f.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	eventType := f.Type
+	if eventType == "" {
+		// the type of the event is the one of its first event specific field
+		event := newEvent("")
+		for _, field := range fields {
+			fieldEventType, err := event.GetFieldEventType(field)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid field `%s`", field)
+			}
+
+			if fieldEventType != "*" {
+				eventType = fieldEventType
+				break
+			}
+		}
+
+		if eventType == "" {
+			return nil, errors.New("unable to find the type of the event")
+		}
+	}
+
+	event := newEvent(eventType)
+	for _, field := range fields {
+		kind, err := event.GetFieldType(field)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid field `%s`", field)
+		}
+
+		value, err := fixtureValue(kind, f.Fields[field])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value for field `%s`", field)
+		}
+
+		if err := event.SetFieldValue(field, value); err != nil {
+			return nil, errors.Wrapf(err, "invalid value for field `%s`", field)
+		}
+	}
+
+	return event, nil
+}
+
+// fixtureValue converts a value decoded from JSON to the kind of the field
+func fixtureValue(kind reflect.Kind, value interface{}) (interface{}, error) {
+	switch kind {
+	case reflect.Int:
+		if number, ok := value.(float64); ok {
+			if number != math.Trunc(number) {
+				return nil, fmt.Errorf("%v is not an integer", number)
+			}
+			return int(number), nil
+		}
+	case reflect.String:
+		if str, ok := value.(string); ok {
+			return str, nil
+		}
+	case reflect.Bool:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported field type %s", kind)
+	}
+
+	return nil, fmt.Errorf("%v is not a %s", value, kind)
+}
+
+type fixtureListener struct {
+	matched []RuleID
+}
+
+// RuleMatch is called when a rule matches an event
+func (l *fixtureListener) RuleMatch(rule *Rule, event eval.Event) {
+	l.matched = append(l.matched, rule.ID)
+}
+
+// EventDiscarderFound is called when a discarder is found for an event
+func (l *fixtureListener) EventDiscarderFound(rs *RuleSet, event eval.Event, field eval.Field, eventType eval.EventType) {
+}
+
+// TestEventFixtures evaluates the event fixtures, in order, against the ruleset. The variables set by the rules are
+// kept from one fixture to the next so that the correlation of events can be tested.
+func (rs *RuleSet) TestEventFixtures(fixtures []*EventFixture, newEvent func(eventType eval.EventType) eval.Event) []*EventFixtureResult {
+	listener := &fixtureListener{}
+
+	listeners := rs.listeners
+	rs.listeners = []RuleSetListener{listener}
+	defer func() {
+		rs.listeners = listeners
+	}()
+
+	var results []*EventFixtureResult
+	for _, fixture := range fixtures {
+		result := &EventFixtureResult{Name: fixture.Name}
+		results = append(results, result)
+
+		event, err := fixture.NewEvent(newEvent)
+		if err != nil {
+			result.Error = err.Error()
+			continue
+		}
+
+		listener.matched = nil
+		rs.Evaluate(event)
+		result.Matched = listener.matched
+
+		matched := make(map[RuleID]bool)
+		for _, id := range result.Matched {
+			matched[id] = true
+		}
+
+		expected := make(map[RuleID]bool)
+		for _, id := range fixture.Match {
+			expected[id] = true
+			if !matched[id] {
+				result.Missing = append(result.Missing, id)
+			}
+		}
+
+		for _, id := range result.Matched {
+			if !expected[id] {
+				result.Unexpected = append(result.Unexpected, id)
+			}
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
+)
+
+func TestEventFixtures(t *testing.T) {
+	model := &testModel{}
+
+	enabled := map[eval.EventType]bool{"*": true}
+	opts := NewOptsWithParams(testConstants, testSupportedDiscarders, enabled, nil)
+	opts.Variables = eval.NewVariableStore(model, map[VariableScope]eval.Field{ProcessScope: "process.name"}, 10)
+
+	rs := NewRuleSet(model, func() eval.Event { return &testEvent{} }, opts)
+
+	ruleDefs := []*RuleDefinition{
+		{
+			ID:         "mkdir_after_cron",
+			Expression: `mkdir.filename == "/tmp/payload" && ${process.wrote_cron} == true`,
+		},
+		{
+			ID:         "cron",
+			Expression: `open.filename == "/etc/crontab" && process.uid != 0`,
+			Actions: []*ActionDefinition{
+				{Set: &SetDefinition{Name: "wrote_cron", Value: true, Scope: ProcessScope}},
+			},
+		},
+	}
+
+	if err := rs.AddRules(ruleDefs); err.ErrorOrNil() != nil {
+		t.Fatal(err)
+	}
+
+	fixtures, err := LoadEventFixtures(strings.NewReader(`[
+  {
+    "name": "payload before cron",
+    "fields": {"process.name": "sh", "mkdir.filename": "/tmp/payload"}
+  },
+  {
+    "name": "cron",
+    "type": "open",
+    "fields": {"process.name": "sh", "process.uid": 1000, "open.filename": "/etc/crontab"},
+    "match": ["cron"]
+  },
+  {
+    "name": "payload after cron",
+    "fields": {"process.name": "sh", "mkdir.filename": "/tmp/payload"},
+    "match": ["mkdir_after_cron"]
+  },
+  {
+    "fields": {"process.name": "bash", "mkdir.filename": "/tmp/payload"},
+    "match": ["mkdir_after_cron"]
+  },
+  {
+    "name": "invalid value",
+    "fields": {"process.uid": "root", "open.filename": "/etc/crontab"}
+  }
+]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := rs.TestEventFixtures(fixtures, func(eventType eval.EventType) eval.Event {
+		return &testEvent{kind: eventType}
+	})
+
+	if len(results) != 5 {
+		t.Fatalf("expected 5 results, got %d", len(results))
+	}
+
+	for _, result := range results[:3] {
+		if !result.Passed() {
+			t.Errorf("fixture `%s` should pass: %+v", result.Name, result)
+		}
+	}
+
+	if result := results[3]; result.Passed() || result.Name != "fixture #4" || !reflect.DeepEqual(result.Missing, []RuleID{"mkdir_after_cron"}) {
+		t.Errorf("fixture #4 should miss mkdir_after_cron: %+v", result)
+	}
+
+	if result := results[4]; result.Passed() || result.Error == "" {
+		t.Errorf("fixture with an invalid value should fail: %+v", result)
+	}
+}
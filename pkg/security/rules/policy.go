@@ -74,12 +74,36 @@ func (p *Policy) GetValidMacroAndRules() ([]*MacroDefinition, []*RuleDefinition,
 			continue
 		}
 
+		if err := checkRuleActions(ruleDef); err != nil {
+			result = multierror.Append(result, &ErrRuleLoad{Definition: ruleDef, Err: err})
+			continue
+		}
+
 		rules = append(rules, ruleDef)
 	}
 
 	return macros, rules, result
 }
 
+func checkRuleActions(ruleDef *RuleDefinition) error {
+	for i, action := range ruleDef.Actions {
+		if action == nil {
+			return fmt.Errorf("empty action #%d", i+1)
+		}
+		if err := action.Check(); err != nil {
+			return err
+		}
+	}
+
+	if ruleDef.RateLimit != nil {
+		if err := ruleDef.RateLimit.Check(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // LoadPolicy loads a YAML file and returns a new policy
 func LoadPolicy(r io.Reader, name string) (*Policy, error) {
 	policy := &Policy{Name: name}
@@ -92,16 +116,16 @@ func LoadPolicy(r io.Reader, name string) (*Policy, error) {
 	return policy, nil
 }
 
-// LoadPolicies loads the policies listed in the configuration and apply them to the given ruleset
-func LoadPolicies(policiesDir string, ruleSet *RuleSet) *multierror.Error {
+// LoadPolicyFiles loads the policy files of a directory, sorted by name
+func LoadPolicyFiles(policiesDir string, logger Logger) ([]*Policy, *multierror.Error) {
 	var (
 		result   *multierror.Error
-		allRules []*RuleDefinition
+		policies []*Policy
 	)
 
 	policyFiles, err := ioutil.ReadDir(policiesDir)
 	if err != nil {
-		return multierror.Append(result, ErrPoliciesLoad{Name: policiesDir, Err: err})
+		return nil, multierror.Append(result, ErrPoliciesLoad{Name: policiesDir, Err: err})
 	}
 	sort.Slice(policyFiles, func(i, j int) bool { return policyFiles[i].Name() < policyFiles[j].Name() })
 
@@ -111,7 +135,7 @@ func LoadPolicies(policiesDir string, ruleSet *RuleSet) *multierror.Error {
 
 		// policy path extension check
 		if filepath.Ext(filename) != ".policy" {
-			ruleSet.logger.Debugf("ignoring file `%s` wrong extension `%s`", policyPath.Name(), filepath.Ext(filename))
+			logger.Debugf("ignoring file `%s` wrong extension `%s`", policyPath.Name(), filepath.Ext(filename))
 			continue
 		}
 
@@ -130,29 +154,59 @@ func LoadPolicies(policiesDir string, ruleSet *RuleSet) *multierror.Error {
 			continue
 		}
 
+		policies = append(policies, policy)
+	}
+
+	return policies, result
+}
+
+// LoadPolicies loads the policies listed in the configuration and apply them to the given ruleset
+func LoadPolicies(policiesDir string, ruleSet *RuleSet) *multierror.Error {
+	policies, result := LoadPolicyFiles(policiesDir, ruleSet.logger)
+	if err := ApplyPolicies(policies, ruleSet); err.ErrorOrNil() != nil {
+		result = multierror.Append(result, err)
+	}
+	return result
+}
+
+// ApplyPolicies adds the macros and rules of the policies to the given ruleset
+func ApplyPolicies(policies []*Policy, ruleSet *RuleSet) *multierror.Error {
+	var (
+		result    *multierror.Error
+		allMacros []*MacroDefinition
+		allRules  []*RuleDefinition
+	)
+
+	for _, policy := range policies {
 		// Add policy version for logging purposes
-		ruleSet.AddPolicyVersion(filename, policy.Version)
+		ruleSet.AddPolicyVersion(policy.Name, policy.Version)
 
 		macros, rules, mErr := policy.GetValidMacroAndRules()
 		if mErr.ErrorOrNil() != nil {
 			result = multierror.Append(result, mErr)
 		}
 
-		if len(macros) > 0 {
-			// Add the macros to the ruleset and generate macros evaluators
-			if err := ruleSet.AddMacros(macros); err != nil {
-				result = multierror.Append(result, err)
-			}
-		}
+		// aggregates them as we may need to have all the variables and macros before compiling
+		allMacros = append(allMacros, macros...)
+		allRules = append(allRules, rules...)
+	}
 
-		// aggregates them as we may need to have all the macro before compiling
-		if len(rules) > 0 {
-			allRules = append(allRules, rules...)
+	// Declare the variables set by the rules, so that the macros and rules can read them. The rules declaring a
+	// variable with conflicting types are not loaded.
+	if err := ruleSet.DeclareVariables(allRules); err.ErrorOrNil() != nil {
+		result = multierror.Append(result, err)
+		allRules = withoutRuleErrors(allRules, err)
+	}
+
+	if len(allMacros) > 0 {
+		// Add the macros to the ruleset and generate macros evaluators
+		if err := ruleSet.AddMacros(allMacros); err != nil {
+			result = multierror.Append(result, err)
 		}
 	}
 
 	// Add rules to the ruleset and generate rules evaluators
-	if err := ruleSet.AddRules(allRules); err.ErrorOrNil() != nil {
+	if err := ruleSet.addRules(allRules); err.ErrorOrNil() != nil {
 		result = multierror.Append(result, err)
 	}
 
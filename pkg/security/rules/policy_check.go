@@ -0,0 +1,212 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/ast"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
+)
+
+// PolicyReport describes the result of the static analysis of a set of policies
+type PolicyReport struct {
+	Policies []string `json:"policies"`
+	// Errors lists the errors raised while loading the policies
+	Errors []string `json:"errors,omitempty"`
+	// Conflicts lists the macros and rules defined multiple times, and the variables set with different types
+	Conflicts []string `json:"conflicts,omitempty"`
+	// UnusedMacros lists the macros referenced by neither a rule nor a used macro
+	UnusedMacros []MacroID `json:"unused_macros,omitempty"`
+	// UndiscardableFields lists, for each event type, the fields supporting discarders for which no discarder can
+	// be found, along with the rules preventing it because they don't constrain the field
+	UndiscardableFields map[eval.EventType]map[eval.Field][]RuleID `json:"undiscardable_fields,omitempty"`
+}
+
+// IsValid returns whether the policies were loaded without error nor conflict
+func (pr *PolicyReport) IsValid() bool {
+	return len(pr.Errors) == 0 && len(pr.Conflicts) == 0
+}
+
+// CheckPolicies applies the policies to the given ruleset and reports the errors, the conflicts, the unused macros
+// and the fields that can't be discarded
+func CheckPolicies(policies []*Policy, ruleSet *RuleSet) *PolicyReport {
+	report := &PolicyReport{
+		UndiscardableFields: make(map[eval.EventType]map[eval.Field][]RuleID),
+	}
+
+	for _, policy := range policies {
+		report.Policies = append(report.Policies, policy.Name)
+	}
+
+	if err := ApplyPolicies(policies, ruleSet); err.ErrorOrNil() != nil {
+		for _, err := range err.Errors {
+			report.Errors = append(report.Errors, err.Error())
+		}
+	}
+
+	report.Conflicts = checkConflicts(policies)
+	report.UnusedMacros = checkUnusedMacros(policies, ruleSet)
+
+	for eventType, bucket := range ruleSet.eventRuleBuckets {
+		for _, field := range bucket.fields {
+			if ruleSet.opts.SupportedDiscarders != nil {
+				if _, exists := ruleSet.opts.SupportedDiscarders[field]; !exists {
+					continue
+				}
+			}
+
+			var ruleIDs []RuleID
+			for _, rule := range bucket.rules {
+				if !ruleSet.getRuleFields(rule)[field] {
+					ruleIDs = append(ruleIDs, rule.ID)
+				}
+			}
+
+			if len(ruleIDs) > 0 {
+				fields, exists := report.UndiscardableFields[eventType]
+				if !exists {
+					fields = make(map[eval.Field][]RuleID)
+					report.UndiscardableFields[eventType] = fields
+				}
+				sort.Strings(ruleIDs)
+				fields[field] = ruleIDs
+			}
+		}
+	}
+
+	return report
+}
+
+// checkConflicts returns the macros and rules defined multiple times, and the variables set with different types
+func checkConflicts(policies []*Policy) []string {
+	var conflicts []string
+
+	macroPolicies := make(map[MacroID][]string)
+	rulePolicies := make(map[RuleID][]string)
+	variableTypes := make(map[string]map[string][]RuleID)
+
+	for _, policy := range policies {
+		for _, macroDef := range policy.Macros {
+			if macroDef.ID != "" {
+				macroPolicies[macroDef.ID] = append(macroPolicies[macroDef.ID], policy.Name)
+			}
+		}
+
+		for _, ruleDef := range policy.Rules {
+			if ruleDef.ID == "" {
+				continue
+			}
+			rulePolicies[ruleDef.ID] = append(rulePolicies[ruleDef.ID], policy.Name)
+
+			for _, action := range ruleDef.Actions {
+				if action == nil || action.Set == nil || action.Set.Value == nil {
+					continue
+				}
+
+				variable := action.Set.Scope + "." + action.Set.Name
+				types, exists := variableTypes[variable]
+				if !exists {
+					types = make(map[string][]RuleID)
+					variableTypes[variable] = types
+				}
+				valueType := fmt.Sprintf("%T", action.Set.Value)
+				types[valueType] = append(types[valueType], ruleDef.ID)
+			}
+		}
+	}
+
+	for id, names := range macroPolicies {
+		if len(names) > 1 {
+			conflicts = append(conflicts, fmt.Sprintf("macro `%s` defined multiple times in %s", id, strings.Join(names, ", ")))
+		}
+	}
+
+	for id, names := range rulePolicies {
+		if len(names) > 1 {
+			conflicts = append(conflicts, fmt.Sprintf("rule `%s` defined multiple times in %s", id, strings.Join(names, ", ")))
+		}
+	}
+
+	for variable, types := range variableTypes {
+		if len(types) > 1 {
+			var setters []string
+			for valueType, ruleIDs := range types {
+				setters = append(setters, fmt.Sprintf("%s by %s", valueType, strings.Join(ruleIDs, ", ")))
+			}
+			sort.Strings(setters)
+			conflicts = append(conflicts, fmt.Sprintf("variable `%s` set as %s", variable, strings.Join(setters, " and ")))
+		}
+	}
+
+	sort.Strings(conflicts)
+
+	return conflicts
+}
+
+// checkUnusedMacros returns the macros referenced by neither a rule nor a used macro. The expressions of the rules
+// which couldn't be compiled are taken into account.
+func checkUnusedMacros(policies []*Policy, ruleSet *RuleSet) []MacroID {
+	var idents []string
+	for _, policy := range policies {
+		for _, ruleDef := range policy.Rules {
+			if rule, err := ast.ParseRule(ruleDef.Expression); err == nil {
+				idents = append(idents, rule.Idents()...)
+			}
+		}
+	}
+
+	used := make(map[MacroID]bool)
+	for len(idents) > 0 {
+		ident := idents[0]
+		idents = idents[1:]
+
+		if macro, exists := ruleSet.opts.Macros[ident]; exists && !used[ident] {
+			used[ident] = true
+			idents = append(idents, macro.GetAst().Idents()...)
+		}
+	}
+
+	var unused []MacroID
+	for id := range ruleSet.opts.Macros {
+		if !used[id] {
+			unused = append(unused, id)
+		}
+	}
+	sort.Strings(unused)
+
+	return unused
+}
+
+// getRuleFields returns the fields constrained by a rule, including the ones of the macros it uses
+func (rs *RuleSet) getRuleFields(rule *Rule) map[eval.Field]bool {
+	fields := make(map[eval.Field]bool)
+	for _, field := range rule.GetEvaluator().GetFields() {
+		fields[field] = true
+	}
+
+	visited := make(map[MacroID]bool)
+	idents := rule.GetAst().Idents()
+	for len(idents) > 0 {
+		ident := idents[0]
+		idents = idents[1:]
+
+		macro, exists := rs.opts.Macros[ident]
+		if !exists || visited[ident] {
+			continue
+		}
+		visited[ident] = true
+
+		for _, field := range macro.GetEvaluator().GetFields() {
+			fields[field] = true
+		}
+		idents = append(idents, macro.GetAst().Idents()...)
+	}
+
+	return fields
+}
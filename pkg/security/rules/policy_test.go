@@ -0,0 +1,253 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
+)
+
+func TestPolicyActions(t *testing.T) {
+	policy, err := LoadPolicy(strings.NewReader(`
+version: 1.2.3
+rules:
+  - id: shell_in_container
+    expression: exec.filename == "/bin/sh"
+    actions:
+      - set:
+          name: shell_spawned
+          scope: container
+      - report:
+          severity: high
+      - suppress:
+          duration: 5m
+    rate_limit:
+      limit: 0.5
+  - id: bad_scope
+    expression: exec.filename == "/bin/sh"
+    actions:
+      - set:
+          name: shell_spawned
+          scope: host
+  - id: bad_severity
+    expression: exec.filename == "/bin/sh"
+    actions:
+      - report:
+          severity: urgent
+  - id: multiple_actions
+    expression: exec.filename == "/bin/sh"
+    actions:
+      - report:
+          severity: low
+        suppress:
+          duration: 1m
+  - id: bad_rate_limit
+    expression: exec.filename == "/bin/sh"
+    rate_limit:
+      limit: -1
+`), "test.policy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, rules, mErr := policy.GetValidMacroAndRules()
+	if mErr == nil || len(mErr.Errors) != 4 {
+		t.Fatalf("expected 4 errors, got: %v", mErr)
+	}
+
+	if len(rules) != 1 {
+		t.Fatalf("expected 1 valid rule, got %d", len(rules))
+	}
+
+	rule := rules[0]
+	if len(rule.Actions) != 3 {
+		t.Fatalf("expected 3 actions, got %d", len(rule.Actions))
+	}
+
+	set := rule.Actions[0].Set
+	if set == nil || set.Name != "shell_spawned" || set.Scope != ContainerScope || set.Value != true {
+		t.Errorf("unexpected set action: %+v", set)
+	}
+
+	if severity := rule.GetSeverity(); severity != "high" {
+		t.Errorf("expected severity `high`, got `%s`", severity)
+	}
+
+	if suppress := rule.Actions[2].Suppress; suppress == nil || suppress.Duration != 5*time.Minute {
+		t.Errorf("unexpected suppress action: %+v", suppress)
+	}
+
+	if rl := rule.RateLimit; rl == nil || rl.Limit != 0.5 || rl.Burst != 1 {
+		t.Errorf("unexpected rate limit: %+v", rl)
+	}
+}
+
+func TestPolicyKillAction(t *testing.T) {
+	policy, err := LoadPolicy(strings.NewReader(`
+version: 1.2.3
+rules:
+  - id: kill_default
+    expression: exec.filename == "/usr/bin/nc"
+    actions:
+      - kill: {}
+  - id: kill_term
+    expression: exec.filename == "/usr/bin/nc"
+    actions:
+      - kill:
+          signal: SIGTERM
+  - id: kill_bad_signal
+    expression: exec.filename == "/usr/bin/nc"
+    actions:
+      - kill:
+          signal: SIGHUP
+  - id: no_kill
+    expression: exec.filename == "/usr/bin/nc"
+`), "test.policy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, rules, mErr := policy.GetValidMacroAndRules()
+	if mErr == nil || len(mErr.Errors) != 1 {
+		t.Fatalf("expected 1 error, got: %v", mErr)
+	}
+
+	expected := map[RuleID]string{
+		"kill_default": "SIGKILL",
+		"kill_term":    "SIGTERM",
+		"no_kill":      "",
+	}
+
+	if len(rules) != len(expected) {
+		t.Fatalf("expected %d valid rules, got %d", len(expected), len(rules))
+	}
+
+	for _, rule := range rules {
+		var signal string
+		if kill := rule.GetKillAction(); kill != nil {
+			signal = kill.Signal
+		}
+		if signal != expected[rule.ID] {
+			t.Errorf("expected signal `%s` for rule `%s`, got `%s`", expected[rule.ID], rule.ID, signal)
+		}
+	}
+}
+
+func TestCheckPolicies(t *testing.T) {
+	load := func(name, content string) *Policy {
+		policy, err := LoadPolicy(strings.NewReader(content), name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return policy
+	}
+
+	policies := []*Policy{
+		load("a.policy", `
+macros:
+  - id: sbin
+    expression: '["/sbin/*", "/usr/sbin/*"]'
+  - id: not_root
+    expression: process.uid != 0
+  - id: unused
+    expression: process.uid == 0
+rules:
+  - id: sbin_open
+    expression: open.filename in sbin && not_root
+  - id: root_open
+    expression: open.flags & O_CREAT > 0 && process.uid == 0
+  - id: mkdir
+    expression: mkdir.filename == "/tmp/payload"
+    actions:
+      - set:
+          name: payload
+          value: true
+`),
+		load("b.policy", `
+rules:
+  - id: mkdir
+    expression: mkdir.filename == "/tmp/other"
+  - id: mkdir_other
+    expression: mkdir.filename == "/tmp/other"
+    actions:
+      - set:
+          name: payload
+          value: "other"
+`),
+	}
+
+	enabled := map[eval.EventType]bool{"*": true}
+	rs := NewRuleSet(&testModel{}, func() eval.Event { return &testEvent{} }, NewOptsWithParams(testConstants, testSupportedDiscarders, enabled, nil))
+
+	report := CheckPolicies(policies, rs)
+	if report.IsValid() {
+		t.Fatal("policies shouldn't be valid")
+	}
+
+	if len(report.Errors) == 0 {
+		t.Error("the duplicated rule should raise an error")
+	}
+
+	expectedConflicts := []string{
+		"rule `mkdir` defined multiple times in a.policy, b.policy",
+		"variable `process.payload` set as bool by mkdir and string by mkdir_other",
+	}
+	if !reflect.DeepEqual(report.Conflicts, expectedConflicts) {
+		t.Errorf("expected conflicts %v, got %v", expectedConflicts, report.Conflicts)
+	}
+
+	if !reflect.DeepEqual(report.UnusedMacros, []MacroID{"unused"}) {
+		t.Errorf("expected unused macros [unused], got %v", report.UnusedMacros)
+	}
+
+	expectedFields := map[eval.EventType]map[eval.Field][]RuleID{
+		"open": {"open.filename": {"root_open"}},
+	}
+	if !reflect.DeepEqual(report.UndiscardableFields, expectedFields) {
+		t.Errorf("expected undiscardable fields %v, got %v", expectedFields, report.UndiscardableFields)
+	}
+}
+
+func TestPolicyVariableConflict(t *testing.T) {
+	policy, err := LoadPolicy(strings.NewReader(`
+rules:
+  - id: set_bool
+    expression: open.filename == "/etc/crontab"
+    actions:
+      - set:
+          name: opened
+          scope: process
+  - id: set_string
+    expression: open.filename == "/etc/passwd"
+    actions:
+      - set:
+          name: opened
+          value: passwd
+          scope: process
+`), "test.policy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enabled := map[eval.EventType]bool{"*": true}
+	rs := NewRuleSet(&testModel{}, func() eval.Event { return &testEvent{} }, NewOptsWithParams(testConstants, testSupportedDiscarders, enabled, nil))
+
+	mErr := ApplyPolicies([]*Policy{policy}, rs)
+	if mErr.ErrorOrNil() == nil || len(mErr.Errors) != 1 {
+		t.Fatalf("expected the conflicting declaration to be reported, got: %v", mErr)
+	}
+	if rErr, ok := mErr.Errors[0].(*ErrRuleLoad); !ok || rErr.Definition.ID != "set_string" {
+		t.Errorf("expected a load error of the set_string rule, got: %v", mErr.Errors[0])
+	}
+
+	if ids := rs.ListRuleIDs(); len(ids) != 1 || ids[0] != "set_bool" {
+		t.Errorf("expected only the set_bool rule to be loaded, got %v", ids)
+	}
+}
@@ -29,10 +29,12 @@ type RuleID = string
 
 // RuleDefinition holds the definition of a rule
 type RuleDefinition struct {
-	ID          RuleID            `yaml:"id"`
-	Expression  string            `yaml:"expression"`
-	Description string            `yaml:"description"`
-	Tags        map[string]string `yaml:"tags"`
+	ID          RuleID               `yaml:"id"`
+	Expression  string               `yaml:"expression"`
+	Description string               `yaml:"description"`
+	Tags        map[string]string    `yaml:"tags"`
+	Actions     []*ActionDefinition  `yaml:"actions"`
+	RateLimit   *RateLimitDefinition `yaml:"rate_limit"`
 	Policy      *Policy
 }
 
@@ -93,6 +95,7 @@ type RuleSet struct {
 	loadedPolicies   map[string]string
 	eventRuleBuckets map[eval.EventType]*RuleBucket
 	rules            map[eval.RuleID]*eval.Rule
+	definitions      map[eval.RuleID]*RuleDefinition
 	model            eval.Model
 	eventCtor        func() eval.Event
 	listeners        []RuleSetListener
@@ -115,6 +118,16 @@ func (rs *RuleSet) GetRules() map[eval.RuleID]*eval.Rule {
 	return rs.rules
 }
 
+// GetVariables returns the store of the variables set by the rules actions
+func (rs *RuleSet) GetVariables() *eval.VariableStore {
+	return rs.opts.Variables
+}
+
+// GetRuleDefinition returns the definition of the given rule
+func (rs *RuleSet) GetRuleDefinition(id RuleID) *RuleDefinition {
+	return rs.definitions[id]
+}
+
 // ListMacroIDs returns the list of MacroIDs from the ruleset
 func (rs *RuleSet) ListMacroIDs() []MacroID {
 	var ids []string
@@ -167,8 +180,61 @@ func (rs *RuleSet) AddMacro(macroDef *MacroDefinition) (*eval.Macro, error) {
 	return macro, nil
 }
 
-// AddRules adds rules to the ruleset and generate their partials
+// DeclareVariables declares the variables set by the actions of the rules, so that rules and macros can read them
+func (rs *RuleSet) DeclareVariables(rules []*RuleDefinition) *multierror.Error {
+	var result *multierror.Error
+
+	for _, ruleDef := range rules {
+		for _, action := range ruleDef.Actions {
+			if action.Set == nil {
+				continue
+			}
+
+			if err := rs.opts.Variables.Declare(action.Set.Scope, action.Set.Name, action.Set.Value); err != nil {
+				result = multierror.Append(result, &ErrRuleLoad{Definition: ruleDef, Err: err})
+			}
+		}
+	}
+
+	return result
+}
+
+// withoutRuleErrors returns the rules for which no load error was reported
+func withoutRuleErrors(rules []*RuleDefinition, errs *multierror.Error) []*RuleDefinition {
+	if errs.ErrorOrNil() == nil {
+		return rules
+	}
+
+	failed := make(map[string]bool)
+	for _, err := range errs.Errors {
+		if rErr, ok := err.(*ErrRuleLoad); ok {
+			failed[rErr.Definition.ID] = true
+		}
+	}
+
+	var valid []*RuleDefinition
+	for _, ruleDef := range rules {
+		if !failed[ruleDef.ID] {
+			valid = append(valid, ruleDef)
+		}
+	}
+	return valid
+}
+
+// AddRules declares the variables set by the rules, then adds the rules to the ruleset and generate their partials
 func (rs *RuleSet) AddRules(rules []*RuleDefinition) *multierror.Error {
+	// rules can read variables set by the rules defined after them
+	result := rs.DeclareVariables(rules)
+
+	if err := rs.addRules(withoutRuleErrors(rules, result)); err.ErrorOrNil() != nil {
+		result = multierror.Append(result, err.Errors...)
+	}
+
+	return result
+}
+
+// addRules adds rules, whose variables were already declared, to the ruleset and generate their partials
+func (rs *RuleSet) addRules(rules []*RuleDefinition) *multierror.Error {
 	var result *multierror.Error
 
 	for _, ruleDef := range rules {
@@ -184,7 +250,8 @@ func (rs *RuleSet) AddRules(rules []*RuleDefinition) *multierror.Error {
 	return result
 }
 
-// AddRule creates the rule evaluator and adds it to the bucket of its events
+// AddRule creates the rule evaluator and adds it to the bucket of its events. The variables set by the rule have to
+// be declared beforehand, see DeclareVariables.
 func (rs *RuleSet) AddRule(ruleDef *RuleDefinition) (*eval.Rule, error) {
 	for _, id := range rs.opts.ReservedRuleIDs {
 		if id == ruleDef.ID {
@@ -253,10 +320,33 @@ func (rs *RuleSet) AddRule(ruleDef *RuleDefinition) (*eval.Rule, error) {
 	rs.AddFields(rule.GetEvaluator().GetFields())
 
 	rs.rules[ruleDef.ID] = rule.Rule
+	rs.definitions[ruleDef.ID] = ruleDef
 
 	return rule.Rule, nil
 }
 
+// applyActions applies the actions of a rule which alter the state of the ruleset, like setting variables
+func (rs *RuleSet) applyActions(rule *Rule, ctx *eval.Context) {
+	for _, action := range rule.Definition.Actions {
+		if action.Set == nil {
+			continue
+		}
+
+		if err := rs.opts.Variables.Set(ctx, action.Set.Scope, action.Set.Name, action.Set.Value, action.Set.TTL); err != nil {
+			rs.logger.Debugf("failed to set variable `%s` for rule `%s`: %s", action.Set.Name, rule.ID, err)
+		}
+	}
+}
+
+// ReleaseVariables removes the values of the variables attached to the object of the scope the event belongs to,
+// like the variables of a process that exited
+func (rs *RuleSet) ReleaseVariables(event eval.Event, scope VariableScope) {
+	ctx := &eval.Context{}
+	ctx.SetObject(event.GetPointer())
+
+	rs.opts.Variables.Release(ctx, scope)
+}
+
 // NotifyRuleMatch notifies all the ruleset listeners that an event matched a rule
 func (rs *RuleSet) NotifyRuleMatch(rule *Rule, event eval.Event) {
 	for _, listener := range rs.listeners {
@@ -345,6 +435,9 @@ func (rs *RuleSet) IsDiscarder(event eval.Event, field eval.Field) (bool, error)
 func (rs *RuleSet) Evaluate(event eval.Event) bool {
 	ctx := &eval.Context{}
 	ctx.SetObject(event.GetPointer())
+	if event, ok := event.(eval.TimestampedEvent); ok {
+		ctx.SetTimeFnc(event.ResolveEventTimestamp)
+	}
 
 	eventType := event.GetType()
 
@@ -359,6 +452,7 @@ func (rs *RuleSet) Evaluate(event eval.Event) bool {
 		if rule.GetEvaluator().Eval(ctx) {
 			rs.logger.Tracef("Rule `%s` matches with event `%s`\n", rule.ID, event)
 
+			rs.applyActions(rule, ctx)
 			rs.NotifyRuleMatch(rule, event)
 			result = true
 		}
@@ -435,12 +529,17 @@ func (rs *RuleSet) AddPolicyVersion(filename string, version string) {
 
 // NewRuleSet returns a new ruleset for the specified data model
 func NewRuleSet(model eval.Model, eventCtor func() eval.Event, opts *Opts) *RuleSet {
+	if opts.Variables == nil {
+		opts.Variables = eval.NewVariableStore(model, DefaultVariableScopes, DefaultMaxVariableValues)
+	}
+
 	return &RuleSet{
 		model:            model,
 		eventCtor:        eventCtor,
 		opts:             opts,
 		eventRuleBuckets: make(map[eval.EventType]*RuleBucket),
 		rules:            make(map[eval.RuleID]*eval.Rule),
+		definitions:      make(map[eval.RuleID]*RuleDefinition),
 		loadedPolicies:   make(map[string]string),
 		logger:           opts.Logger,
 	}
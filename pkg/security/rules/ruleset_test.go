@@ -380,3 +380,69 @@ func TestRuleSetFilters6(t *testing.T) {
 		t.Fatal("shouldn't get any approver")
 	}
 }
+
+type testMatchHandler struct {
+	matches map[eval.RuleID]int
+}
+
+func (h *testMatchHandler) RuleMatch(rule *Rule, event eval.Event) {
+	h.matches[rule.ID]++
+}
+
+func (h *testMatchHandler) EventDiscarderFound(rs *RuleSet, event eval.Event, field string, eventType eval.EventType) {
+}
+
+func TestRuleSetVariables(t *testing.T) {
+	model := &testModel{}
+
+	enabled := map[eval.EventType]bool{"*": true}
+	opts := NewOptsWithParams(testConstants, testSupportedDiscarders, enabled, nil)
+	opts.Variables = eval.NewVariableStore(model, map[VariableScope]eval.Field{ProcessScope: "process.name"}, 10)
+
+	rs := NewRuleSet(model, func() eval.Event { return &testEvent{} }, opts)
+
+	handler := &testMatchHandler{matches: make(map[eval.RuleID]int)}
+	rs.AddListener(handler)
+
+	ruleDefs := []*RuleDefinition{
+		{
+			ID:         "mkdir_after_cron",
+			Expression: `mkdir.filename == "/tmp/payload" && ${process.wrote_cron} == true`,
+		},
+		{
+			ID:         "cron",
+			Expression: `open.filename == "/etc/crontab"`,
+			Actions: []*ActionDefinition{
+				{Set: &SetDefinition{Name: "wrote_cron", Value: true, Scope: ProcessScope}},
+			},
+		},
+	}
+
+	if err := rs.AddRules(ruleDefs); err.ErrorOrNil() != nil {
+		t.Fatal(err)
+	}
+
+	mkdir := &testEvent{kind: "mkdir", process: testProcess{name: "sh"}, mkdir: testMkdir{filename: "/tmp/payload"}}
+	open := &testEvent{kind: "open", process: testProcess{name: "sh"}, open: testOpen{filename: "/etc/crontab"}}
+	otherMkdir := &testEvent{kind: "mkdir", process: testProcess{name: "bash"}, mkdir: testMkdir{filename: "/tmp/payload"}}
+
+	rs.Evaluate(mkdir)
+	if handler.matches["mkdir_after_cron"] != 0 {
+		t.Error("mkdir shouldn't match before the crontab was opened")
+	}
+
+	rs.Evaluate(open)
+	if handler.matches["cron"] != 1 {
+		t.Error("crontab open should match")
+	}
+
+	rs.Evaluate(mkdir)
+	if handler.matches["mkdir_after_cron"] != 1 {
+		t.Error("mkdir should match after the crontab was opened by the same process")
+	}
+
+	rs.Evaluate(otherMkdir)
+	if handler.matches["mkdir_after_cron"] != 1 {
+		t.Error("mkdir shouldn't match for another process")
+	}
+}
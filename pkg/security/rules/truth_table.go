@@ -122,7 +122,7 @@ func genFilterValues(rule *eval.Rule, event eval.Event) ([]FilterValues, error)
 		var values FilterValues
 		for _, fValue := range fValues {
 			switch fValue.Type {
-			case eval.ScalarValueType, eval.PatternValueType:
+			case eval.ScalarValueType, eval.PatternValueType, eval.IPNetValueType:
 				values = append(values, FilterValue{
 					Field: field,
 					Value: fValue.Value,
@@ -7,7 +7,11 @@ package ast
 
 import (
 	"bytes"
+	"math"
+	"reflect"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/alecthomas/participle"
 	"github.com/alecthomas/participle/lexer"
@@ -17,14 +21,21 @@ import (
 var (
 	seclLexer = lexer.Must(ebnf.New(`
 Comment = ("#" | "//") { "\u0000"…"\uffff"-"\n" } .
+CIDR = ( ipv4 | ipv6 ) [ "/" digit { digit } ] .
 Ident = (alpha | "_") { "_" | alpha | digit | "." | "[" | "]" } .
 String = "\"" { "\u0000"…"\uffff"-"\""-"\\" | "\\" any } "\"" .
+CaseInsensitivePattern = "~i\"" { "\u0000"…"\uffff"-"\""-"\\" | "\\" any } "\"" .
 Pattern = "~\"" { "\u0000"…"\uffff"-"\""-"\\" | "\\" any } "\"" .
+Variable = "${" (alpha | "_") { "_" | alpha | digit | "." } "}" .
+Duration = digit { digit } ( "m" [ "s" ] | "s" | "h" | "d" ) .
 Int = [ "-" | "+" ] digit { digit } .
 Punct = "!"…"/" | ":"…"@" | "["…` + "\"`\"" + ` | "{"…"~" .
 Whitespace = ( " " | "\t" | "\n" ) { " " | "\t" | "\n" } .
 alpha = "a"…"z" | "A"…"Z" .
 digit = "0"…"9" .
+hex = digit | "a"…"f" | "A"…"F" .
+ipv4 = digit { digit } "." digit { digit } "." digit { digit } "." digit { digit } .
+ipv6 = { hex } ":" { hex | ":" } .
 any = "\u0000"…"\uffff" .
 `))
 )
@@ -39,12 +50,58 @@ func unquotePattern(t lexer.Token) (lexer.Token, error) {
 	return t, nil
 }
 
+func unquoteCaseInsensitivePattern(t lexer.Token) (lexer.Token, error) {
+	unquoted, err := strconv.Unquote(t.Value[2:])
+	if err != nil {
+		return t, participle.Errorf(t.Pos, "invalid pattern string %q: %s", t.Value, err)
+	}
+	t.Value = unquoted
+
+	return t, nil
+}
+
+// durationUnits lists the units of the duration literals. Days are not supported by time.ParseDuration.
+var durationUnits = map[string]time.Duration{
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+}
+
+// parseDuration converts a duration literal to a number of nanoseconds
+func parseDuration(t lexer.Token) (lexer.Token, error) {
+	i := strings.IndexFunc(t.Value, func(r rune) bool { return r < '0' || r > '9' })
+
+	value, err := strconv.ParseInt(t.Value[:i], 10, 64)
+	if err != nil {
+		return t, participle.Errorf(t.Pos, "invalid duration %q: %s", t.Value, err)
+	}
+
+	unit := durationUnits[t.Value[i:]]
+	if value > math.MaxInt64/int64(unit) {
+		return t, participle.Errorf(t.Pos, "invalid duration %q: out of range", t.Value)
+	}
+	t.Value = strconv.FormatInt(value*int64(unit), 10)
+
+	return t, nil
+}
+
+func unquoteVariable(t lexer.Token) (lexer.Token, error) {
+	t.Value = t.Value[2 : len(t.Value)-1]
+
+	return t, nil
+}
+
 func buildParser(obj interface{}) (*participle.Parser, error) {
 	return participle.Build(obj,
 		participle.Lexer(seclLexer),
 		participle.Elide("Whitespace", "Comment"),
 		participle.Unquote("String"),
 		participle.Map(unquotePattern, "Pattern"),
+		participle.Map(unquoteCaseInsensitivePattern, "CaseInsensitivePattern"),
+		participle.Map(parseDuration, "Duration"),
+		participle.Map(unquoteVariable, "Variable"),
 	)
 }
 
@@ -129,7 +186,7 @@ type Comparison struct {
 type ScalarComparison struct {
 	Pos lexer.Position
 
-	Op   *string     `parser:"@( \">\" | \">\" \"=\" | \"<\" | \"<\" \"=\" | \"!\" \"=\" | \"=\" \"=\" | \"=\" \"~\" | \"!\" \"~\" )"`
+	Op   *string     `parser:"@( \">\" \"=\" | \">\" | \"<\" \"=\" | \"<\" | \"!\" \"=\" | \"=\" \"=\" | \"=\" \"~\" | \"!\" \"~\" )"`
 	Next *Comparison `parser:"@@"`
 }
 
@@ -145,9 +202,41 @@ type ArrayComparison struct {
 type BitOperation struct {
 	Pos lexer.Position
 
-	Unary *Unary        `parser:"@@"`
-	Op    *string       `parser:"[ @( \"&\" | \"|\" | \"^\" )"`
-	Next  *BitOperation `parser:"@@ ]"`
+	Arithmetic *Arithmetic   `parser:"@@"`
+	Op         *string       `parser:"[ @( \"&\" | \"|\" | \"^\" )"`
+	Next       *BitOperation `parser:"@@ ]"`
+}
+
+// Arithmetic describes a sequence of additions and subtractions, evaluated from left to right
+type Arithmetic struct {
+	Pos lexer.Position
+
+	Term       *Term                  `parser:"@@"`
+	Operations []*ArithmeticOperation `parser:"{ @@ }"`
+}
+
+// ArithmeticOperation describes an addition or a subtraction with its right operand
+type ArithmeticOperation struct {
+	Pos lexer.Position
+
+	Op   *string `parser:"@( \"+\" | \"-\" )"`
+	Term *Term   `parser:"@@"`
+}
+
+// Term describes a sequence of multiplications, evaluated from left to right
+type Term struct {
+	Pos lexer.Position
+
+	Unary      *Unary           `parser:"@@"`
+	Operations []*TermOperation `parser:"{ @@ }"`
+}
+
+// TermOperation describes a multiplication with its right operand
+type TermOperation struct {
+	Pos lexer.Position
+
+	Op    *string `parser:"@\"*\""`
+	Unary *Unary  `parser:"@@"`
 }
 
 // Unary describes an unary operation like logical not, binary not, minus
@@ -160,23 +249,29 @@ type Unary struct {
 }
 
 // Primary describes a single operand. It can be a simple identifier, a number,
-// a string or a full expression in parenthesis
+// a duration, an IP address or a CIDR, a string, a pattern, a variable or a full
+// expression in parenthesis
 type Primary struct {
 	Pos lexer.Position
 
-	Ident         *string     `parser:"@Ident"`
-	Number        *int        `parser:"| @Int"`
-	String        *string     `parser:"| @String"`
-	Pattern       *string     `parser:"| @Pattern"`
-	SubExpression *Expression `parser:"| \"(\" @@ \")\""`
+	Ident                  *string     `parser:"@Ident"`
+	CIDR                   *string     `parser:"| @CIDR"`
+	Duration               *int        `parser:"| @Duration"`
+	Number                 *int        `parser:"| @Int"`
+	String                 *string     `parser:"| @String"`
+	Pattern                *string     `parser:"| @Pattern"`
+	CaseInsensitivePattern *string     `parser:"| @CaseInsensitivePattern"`
+	Variable               *string     `parser:"| @Variable"`
+	SubExpression          *Expression `parser:"| \"(\" @@ \")\""`
 }
 
 // StringMember describes a String based array member
 type StringMember struct {
 	Pos lexer.Position
 
-	String  *string `parser:"@String"`
-	Pattern *string `parser:"| @Pattern"`
+	String                 *string `parser:"@String"`
+	Pattern                *string `parser:"| @Pattern"`
+	CaseInsensitivePattern *string `parser:"| @CaseInsensitivePattern"`
 }
 
 // Array describes an array of values
@@ -185,5 +280,43 @@ type Array struct {
 
 	StringMembers []StringMember `parser:"\"[\" @@ { \",\" @@ } \"]\""`
 	Numbers       []int          `parser:"| \"[\" @Int { \",\" @Int } \"]\""`
+	CIDRs         []string       `parser:"| \"[\" @CIDR { \",\" @CIDR } \"]\""`
+	CIDR          *string        `parser:"| @CIDR"`
 	Ident         *string        `parser:"| @Ident"`
 }
+
+// Idents returns the identifiers referenced by the rule, like fields, constants or macros
+func (r *Rule) Idents() []string {
+	return collectIdents(reflect.ValueOf(r), nil)
+}
+
+// Idents returns the identifiers referenced by the macro, like fields, constants or macros
+func (m *Macro) Idents() []string {
+	return collectIdents(reflect.ValueOf(m), nil)
+}
+
+// collectIdents walks the nodes of the AST looking for the `Ident` members
+func collectIdents(v reflect.Value, idents []string) []string {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			idents = collectIdents(v.Elem(), idents)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			idents = collectIdents(v.Index(i), idents)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			if v.Type().Field(i).Name == "Ident" {
+				if ident, ok := field.Interface().(*string); ok && ident != nil {
+					idents = append(idents, *ident)
+				}
+				continue
+			}
+			idents = collectIdents(field, idents)
+		}
+	}
+	return idents
+}
@@ -7,7 +7,9 @@ package ast
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func print(t *testing.T, i interface{}) {
@@ -186,3 +188,99 @@ func TestArrayPattern(t *testing.T) {
 
 	print(t, rule)
 }
+
+func TestVariable(t *testing.T) {
+	rule, err := ParseRule(`${process.wrote_cron} == true && process.name == "sh"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	print(t, rule)
+
+	primary := rule.BooleanExpression.Expression.Comparison.BitOperation.Arithmetic.Term.Unary.Primary
+	if primary.Variable == nil || *primary.Variable != "process.wrote_cron" {
+		t.Errorf("expected variable `process.wrote_cron`, got %+v", primary)
+	}
+}
+
+func TestDuration(t *testing.T) {
+	rule, err := ParseRule(`process.created_at > 5m && process.created_at < 1d`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	print(t, rule)
+
+	primary := rule.BooleanExpression.Expression.Comparison.ScalarComparison.Next.BitOperation.Arithmetic.Term.Unary.Primary
+	if primary.Duration == nil || *primary.Duration != int(5*time.Minute) {
+		t.Errorf("expected a duration of 5 minutes, got %+v", primary)
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	rule, err := ParseRule(`process.uid + 2 * 3 - 1 == process.gid`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	print(t, rule)
+
+	arithmetic := rule.BooleanExpression.Expression.Comparison.BitOperation.Arithmetic
+	if len(arithmetic.Operations) != 2 || *arithmetic.Operations[0].Op != "+" || *arithmetic.Operations[1].Op != "-" {
+		t.Fatalf("expected an addition and a subtraction, got %+v", arithmetic.Operations)
+	}
+
+	if len(arithmetic.Operations[0].Term.Operations) != 1 {
+		t.Errorf("expected a multiplication, got %+v", arithmetic.Operations[0].Term)
+	}
+}
+
+func TestCIDR(t *testing.T) {
+	for _, expr := range []string{
+		`network.destination.ip in 10.0.0.0/8`,
+		`network.destination.ip in [10.0.0.0/8, 192.168.0.0/16, fd00::/8]`,
+		`network.destination.ip == 127.0.0.1 || network.destination.ip == ::1`,
+	} {
+		rule, err := ParseRule(expr)
+		if err != nil {
+			t.Fatalf("%s: %s", expr, err)
+		}
+
+		print(t, rule)
+	}
+}
+
+func TestCaseInsensitivePattern(t *testing.T) {
+	rule, err := ParseRule(`process.name == ~i"Bash" || process.name in [~i"*.EXE", "sh"]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	print(t, rule)
+
+	primary := rule.BooleanExpression.Expression.Comparison.ScalarComparison.Next.BitOperation.Arithmetic.Term.Unary.Primary
+	if primary.CaseInsensitivePattern == nil || *primary.CaseInsensitivePattern != "Bash" {
+		t.Errorf("expected case insensitive pattern `Bash`, got %+v", primary)
+	}
+}
+
+func TestIdents(t *testing.T) {
+	rule, err := ParseRule(`(open.filename in sensitive_files || open.filename == "/etc/shadow") && open.flags & O_CREAT > 0 && !is_root`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"open.filename", "sensitive_files", "open.filename", "open.flags", "O_CREAT", "is_root"}
+	if idents := rule.Idents(); !reflect.DeepEqual(idents, expected) {
+		t.Errorf("expected idents %v, got %v", expected, idents)
+	}
+
+	macro, err := ParseMacro(`[ "/etc/shadow", "/etc/gshadow" ]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if idents := macro.Idents(); len(idents) != 0 {
+		t.Errorf("expected no ident, got %v", idents)
+	}
+}
@@ -6,6 +6,7 @@
 package eval
 
 import (
+	"time"
 	"unsafe"
 )
 
@@ -14,9 +15,33 @@ type Context struct {
 	Object unsafe.Pointer
 
 	Registers Registers
+
+	now     time.Time
+	timeFnc func() time.Time
 }
 
 // SetObject set the given object to the context
 func (c *Context) SetObject(obj unsafe.Pointer) {
 	c.Object = obj
+	c.now = time.Time{}
+}
+
+// SetTimeFnc sets the function returning the time of the object, used as reference to evaluate the durations
+func (c *Context) SetTimeFnc(timeFnc func() time.Time) {
+	c.timeFnc = timeFnc
+	c.now = time.Time{}
+}
+
+// Now returns the time of the object being evaluated, or the current time if the time of the object is unknown.
+// It is resolved once per object.
+func (c *Context) Now() time.Time {
+	if c.now.IsZero() {
+		if c.timeFnc != nil {
+			c.now = c.timeFnc()
+		}
+		if c.now.IsZero() {
+			c.now = time.Now()
+		}
+	}
+	return c.now
 }
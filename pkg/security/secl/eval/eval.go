@@ -9,6 +9,7 @@ package eval
 
 import (
 	"fmt"
+	"net"
 	"reflect"
 	"regexp"
 	"sort"
@@ -31,6 +32,7 @@ const (
 	ScalarValueType  FieldValueType = 1
 	PatternValueType FieldValueType = 2
 	BitmaskValueType FieldValueType = 4
+	IPNetValueType   FieldValueType = 8
 )
 
 // defines factor applied by specific operator
@@ -49,12 +51,14 @@ type FieldValue struct {
 	Type  FieldValueType
 
 	Regex *regexp.Regexp
+	IPNet *net.IPNet
 }
 
 // Opts are the options to be passed to the evaluator
 type Opts struct {
 	Constants map[string]interface{}
 	Macros    map[MacroID]*Macro
+	Variables *VariableStore
 }
 
 // NewOptsWithParams initializes a new Opts instance with Constants parameters
@@ -101,7 +105,8 @@ type IntEvaluator struct {
 	Value   int
 	Weight  int
 
-	isPartial bool
+	isPartial  bool
+	isDuration bool
 }
 
 // Eval returns the result of the evaluation
@@ -111,11 +116,12 @@ func (i *IntEvaluator) Eval(ctx *Context) interface{} {
 
 // StringEvaluator returns a string as result of the evaluation
 type StringEvaluator struct {
-	EvalFnc   func(ctx *Context) string
-	Field     Field
-	Value     string
-	Weight    int
-	IsPattern bool
+	EvalFnc         func(ctx *Context) string
+	Field           Field
+	Value           string
+	Weight          int
+	IsPattern       bool
+	CaseInsensitive bool
 
 	isPartial bool
 }
@@ -141,6 +147,12 @@ type PatternArray struct {
 	Regexps []*regexp.Regexp
 }
 
+// CIDRArray represents an array of IP addresses and networks
+type CIDRArray struct {
+	Values []string
+	IPNets []*net.IPNet
+}
+
 func extractField(field string) (Field, Field, RegisterID, error) {
 	var regID RegisterID
 
@@ -166,7 +178,7 @@ func extractField(field string) (Field, Field, RegisterID, error) {
 	return field, itField, regID, nil
 }
 
-func patternToRegexp(pattern string) (*regexp.Regexp, error) {
+func patternToRegexp(pattern string, caseInsensitive bool) (*regexp.Regexp, error) {
 	// do not accept full wildcard value
 	if matched, err := regexp.Match(`[a-zA-Z0-9\.]+`, []byte(pattern)); err != nil || !matched {
 		return nil, &ErrInvalidPattern{Pattern: pattern}
@@ -181,9 +193,41 @@ func patternToRegexp(pattern string) (*regexp.Regexp, error) {
 		return ".*"
 	})
 
+	if caseInsensitive {
+		return regexp.Compile("(?i)^" + quoted + "$")
+	}
 	return regexp.Compile("^" + quoted + "$")
 }
 
+// parseIPNet parses an IP address, returned as a network of a single address, or a CIDR
+func parseIPNet(s string) (*net.IPNet, error) {
+	if ip := net.ParseIP(s); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			return &net.IPNet{IP: ip4, Mask: net.CIDRMask(8*net.IPv4len, 8*net.IPv4len)}, nil
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(8*net.IPv6len, 8*net.IPv6len)}, nil
+	}
+
+	_, ipnet, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, err
+	}
+	return ipnet, nil
+}
+
+func newCIDRArray(values []string) (*CIDRArray, error) {
+	array := &CIDRArray{}
+	for _, value := range values {
+		ipnet, err := parseIPNet(value)
+		if err != nil {
+			return nil, err
+		}
+		array.Values = append(array.Values, ipnet.String())
+		array.IPNets = append(array.IPNets, ipnet)
+	}
+	return array, nil
+}
+
 func nodeToEvaluator(obj interface{}, opts *Opts, state *state) (interface{}, interface{}, lexer.Position, error) {
 	switch obj := obj.(type) {
 	case *ast.BooleanExpression:
@@ -228,7 +272,7 @@ func nodeToEvaluator(obj interface{}, opts *Opts, state *state) (interface{}, in
 		}
 		return cmp, nil, obj.Pos, nil
 	case *ast.BitOperation:
-		unary, _, pos, err := nodeToEvaluator(obj.Unary, opts, state)
+		unary, _, pos, err := nodeToEvaluator(obj.Arithmetic, opts, state)
 		if err != nil {
 			return nil, nil, pos, err
 		}
@@ -273,6 +317,70 @@ func nodeToEvaluator(obj interface{}, opts *Opts, state *state) (interface{}, in
 		}
 		return unary, nil, obj.Pos, nil
 
+	case *ast.Arithmetic:
+		term, _, pos, err := nodeToEvaluator(obj.Term, opts, state)
+		if err != nil {
+			return nil, nil, pos, err
+		}
+
+		for _, operation := range obj.Operations {
+			termInt, ok := term.(*IntEvaluator)
+			if !ok {
+				return nil, nil, obj.Pos, NewTypeError(obj.Pos, reflect.Int)
+			}
+
+			next, _, pos, err := nodeToEvaluator(operation.Term, opts, state)
+			if err != nil {
+				return nil, nil, pos, err
+			}
+
+			nextInt, ok := next.(*IntEvaluator)
+			if !ok {
+				return nil, nil, pos, NewTypeError(pos, reflect.Int)
+			}
+
+			switch *operation.Op {
+			case "+":
+				term = IntPlus(termInt, nextInt, opts, state)
+			case "-":
+				term = IntMinus(termInt, nextInt, opts, state)
+			default:
+				return nil, nil, pos, NewOpUnknownError(operation.Pos, *operation.Op)
+			}
+		}
+		return term, nil, obj.Pos, nil
+
+	case *ast.Term:
+		unary, _, pos, err := nodeToEvaluator(obj.Unary, opts, state)
+		if err != nil {
+			return nil, nil, pos, err
+		}
+
+		for _, operation := range obj.Operations {
+			unaryInt, ok := unary.(*IntEvaluator)
+			if !ok {
+				return nil, nil, obj.Pos, NewTypeError(obj.Pos, reflect.Int)
+			}
+
+			next, _, pos, err := nodeToEvaluator(operation.Unary, opts, state)
+			if err != nil {
+				return nil, nil, pos, err
+			}
+
+			nextInt, ok := next.(*IntEvaluator)
+			if !ok {
+				return nil, nil, pos, NewTypeError(pos, reflect.Int)
+			}
+
+			switch *operation.Op {
+			case "*":
+				unary = IntMultiply(unaryInt, nextInt, opts, state)
+			default:
+				return nil, nil, pos, NewOpUnknownError(operation.Pos, *operation.Op)
+			}
+		}
+		return unary, nil, obj.Pos, nil
+
 	case *ast.Comparison:
 		unary, _, pos, err := nodeToEvaluator(obj.BitOperation, opts, state)
 		if err != nil {
@@ -300,6 +408,12 @@ func nodeToEvaluator(obj interface{}, opts *Opts, state *state) (interface{}, in
 						return nil, nil, pos, err
 					}
 					return boolEvaluator, nil, obj.Pos, nil
+				case *CIDRArray:
+					boolEvaluator, err := CIDRArrayContains(unary, next.(*CIDRArray), *obj.ArrayComparison.Op == "notin", opts, state)
+					if err != nil {
+						return nil, nil, pos, err
+					}
+					return boolEvaluator, nil, obj.Pos, nil
 				default:
 					return nil, nil, pos, NewTypeError(pos, reflect.Array)
 				}
@@ -346,6 +460,18 @@ func nodeToEvaluator(obj interface{}, opts *Opts, state *state) (interface{}, in
 				}
 				return nil, nil, pos, NewOpUnknownError(obj.Pos, *obj.ScalarComparison.Op)
 			case *StringEvaluator:
+				if nextCIDR, ok := next.(*CIDRArray); ok {
+					switch *obj.ScalarComparison.Op {
+					case "==", "!=":
+						boolEvaluator, err := CIDRArrayContains(unary, nextCIDR, *obj.ScalarComparison.Op == "!=", opts, state)
+						if err != nil {
+							return nil, nil, pos, err
+						}
+						return boolEvaluator, nil, obj.Pos, nil
+					}
+					return nil, nil, pos, NewOpUnknownError(obj.Pos, *obj.ScalarComparison.Op)
+				}
+
 				nextString, ok := next.(*StringEvaluator)
 				if !ok {
 					return nil, nil, pos, NewTypeError(pos, reflect.String)
@@ -394,6 +520,11 @@ func nodeToEvaluator(obj interface{}, opts *Opts, state *state) (interface{}, in
 					return nil, nil, pos, NewTypeError(pos, reflect.Int)
 				}
 
+				// a timestamp compared to a duration is compared using the time elapsed until the event
+				if nextInt.isDuration && !unary.isDuration {
+					unary = ElapsedSince(unary, opts, state)
+				}
+
 				switch *obj.ScalarComparison.Op {
 				case "<":
 					boolEvaluator, err := LesserThan(unary, nextInt, opts, state)
@@ -555,6 +686,17 @@ func nodeToEvaluator(obj interface{}, opts *Opts, state *state) (interface{}, in
 			return &IntEvaluator{
 				Value: *obj.Number,
 			}, nil, obj.Pos, nil
+		case obj.Duration != nil:
+			return &IntEvaluator{
+				Value:      *obj.Duration,
+				isDuration: true,
+			}, nil, obj.Pos, nil
+		case obj.CIDR != nil:
+			array, err := newCIDRArray([]string{*obj.CIDR})
+			if err != nil {
+				return nil, nil, obj.Pos, NewError(obj.Pos, fmt.Sprintf("invalid IP address or CIDR '%s': %s", *obj.CIDR, err))
+			}
+			return array, nil, obj.Pos, nil
 		case obj.String != nil:
 			return &StringEvaluator{
 				Value: *obj.String,
@@ -564,6 +706,36 @@ func nodeToEvaluator(obj interface{}, opts *Opts, state *state) (interface{}, in
 				Value:     *obj.Pattern,
 				IsPattern: true,
 			}, nil, obj.Pos, nil
+		case obj.CaseInsensitivePattern != nil:
+			return &StringEvaluator{
+				Value:           *obj.CaseInsensitivePattern,
+				IsPattern:       true,
+				CaseInsensitive: true,
+			}, nil, obj.Pos, nil
+		case obj.Variable != nil:
+			if opts.Variables == nil {
+				return nil, nil, obj.Pos, NewError(obj.Pos, fmt.Sprintf("variables not supported, can't use '%s'", *obj.Variable))
+			}
+
+			scope, name := ParseVariable(*obj.Variable)
+			evaluator, err := opts.Variables.GetEvaluator(scope, name)
+			if err != nil {
+				return nil, nil, obj.Pos, NewError(obj.Pos, err.Error())
+			}
+
+			// variables change over time, they can't be used to compute discarders
+			if state.field != "" {
+				switch evaluator := evaluator.(type) {
+				case *BoolEvaluator:
+					evaluator.isPartial = true
+				case *IntEvaluator:
+					evaluator.isPartial = true
+				case *StringEvaluator:
+					evaluator.isPartial = true
+				}
+			}
+
+			return evaluator, nil, obj.Pos, nil
 		case obj.SubExpression != nil:
 			return nodeToEvaluator(obj.SubExpression, opts, state)
 		default:
@@ -579,11 +751,15 @@ func nodeToEvaluator(obj interface{}, opts *Opts, state *state) (interface{}, in
 			var hasPatterns bool
 
 			for _, member := range obj.StringMembers {
-				if member.String != nil {
+				switch {
+				case member.String != nil:
 					strs = append(strs, *member.String)
-				} else {
+				case member.Pattern != nil:
 					strs = append(strs, *member.Pattern)
 					hasPatterns = true
+				default:
+					strs = append(strs, *member.CaseInsensitivePattern)
+					hasPatterns = true
 				}
 			}
 
@@ -593,17 +769,22 @@ func nodeToEvaluator(obj interface{}, opts *Opts, state *state) (interface{}, in
 				var err error
 
 				for _, member := range obj.StringMembers {
-					if member.String != nil {
+					switch {
+					case member.String != nil:
 						// escape wildcard
 						str := strings.ReplaceAll(*member.String, "*", "\\*")
 
-						if reg, err = patternToRegexp(str); err != nil {
+						if reg, err = patternToRegexp(str, false); err != nil {
 							return nil, nil, obj.Pos, NewError(obj.Pos, fmt.Sprintf("invalid pattern '%s': %s", *member.String, err))
 						}
-					} else {
-						if reg, err = patternToRegexp(*member.Pattern); err != nil {
+					case member.Pattern != nil:
+						if reg, err = patternToRegexp(*member.Pattern, false); err != nil {
 							return nil, nil, obj.Pos, NewError(obj.Pos, fmt.Sprintf("invalid pattern '%s': %s", *member.Pattern, err))
 						}
+					default:
+						if reg, err = patternToRegexp(*member.CaseInsensitivePattern, true); err != nil {
+							return nil, nil, obj.Pos, NewError(obj.Pos, fmt.Sprintf("invalid pattern '%s': %s", *member.CaseInsensitivePattern, err))
+						}
 					}
 					regs = append(regs, reg)
 				}
@@ -612,6 +793,17 @@ func nodeToEvaluator(obj interface{}, opts *Opts, state *state) (interface{}, in
 
 			sort.Strings(strs)
 			return &StringArray{Values: strs}, nil, obj.Pos, nil
+		} else if len(obj.CIDRs) != 0 || obj.CIDR != nil {
+			cidrs := obj.CIDRs
+			if obj.CIDR != nil {
+				cidrs = []string{*obj.CIDR}
+			}
+
+			array, err := newCIDRArray(cidrs)
+			if err != nil {
+				return nil, nil, obj.Pos, NewError(obj.Pos, fmt.Sprintf("invalid IP address or CIDR: %s", err))
+			}
+			return array, nil, obj.Pos, nil
 		} else if obj.Ident != nil {
 			if state.macros != nil {
 				if macro, ok := state.macros[*obj.Ident]; ok {
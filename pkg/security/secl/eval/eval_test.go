@@ -11,6 +11,7 @@ import (
 	"strings"
 	"syscall"
 	"testing"
+	"time"
 	"unsafe"
 
 	"github.com/DataDog/datadog-agent/pkg/security/secl/ast"
@@ -426,6 +427,14 @@ func TestPartial(t *testing.T) {
 		{Expr: `open.filename == "test1" && process.uid == 123`, Field: "process.uid", IsDiscarder: false},
 		{Expr: `open.filename == "test1" && !process.is_root`, Field: "process.is_root", IsDiscarder: true},
 		{Expr: `open.filename == "test1" && process.is_root`, Field: "process.is_root", IsDiscarder: false},
+		{Expr: `open.filename == "test1" && process.uid + 1 == 125`, Field: "process.uid", IsDiscarder: true},
+		{Expr: `open.filename == "test1" && process.uid * 2 == 246`, Field: "process.uid", IsDiscarder: false},
+		{Expr: `open.filename == "test1" && process.uid + 1 == 125`, Field: "open.filename", IsDiscarder: true},
+		{Expr: `open.filename == "xyz" && process.uid + 1 == 125`, Field: "open.filename", IsDiscarder: false},
+		{Expr: `open.filename == "test1" && process.name == ~i"ABC"`, Field: "process.name", IsDiscarder: false},
+		{Expr: `open.filename == "test1" && process.name == ~i"XYZ"`, Field: "process.name", IsDiscarder: true},
+		{Expr: `open.filename == "xyz" && process.created_at > 5m`, Field: "open.filename", IsDiscarder: false},
+		{Expr: `open.filename == "xyz" && process.created_at > 5m`, Field: "process.created_at", IsDiscarder: false},
 	}
 
 	ctx := &Context{}
@@ -454,6 +463,182 @@ func TestPartial(t *testing.T) {
 	}
 }
 
+func TestArithmeticOperations(t *testing.T) {
+	event := &testEvent{
+		process: testProcess{
+			uid: 3,
+			gid: 5,
+		},
+	}
+
+	tests := []struct {
+		Expr     string
+		Expected bool
+	}{
+		{Expr: `1 + 2 == 3`, Expected: true},
+		{Expr: `10 - 2 - 3 == 5`, Expected: true},
+		{Expr: `2 + 3 * 4 == 14`, Expected: true},
+		{Expr: `(2 + 3) * 4 == 20`, Expected: true},
+		{Expr: `process.uid + 2 == process.gid`, Expected: true},
+		{Expr: `process.gid - process.uid == 2`, Expected: true},
+		{Expr: `process.uid * process.gid > 10`, Expected: true},
+		{Expr: `process.uid * 2 in [ 5, 6 ]`, Expected: true},
+		{Expr: `process.uid - 4 == - 1`, Expected: true},
+		{Expr: `process.uid + 1 & 4 == 4`, Expected: true},
+	}
+
+	for _, test := range tests {
+		result, _, err := eval(t, event, test.Expr)
+		if err != nil {
+			t.Fatalf("error while evaluating `%s`: %s", test.Expr, err)
+		}
+
+		if result != test.Expected {
+			t.Errorf("expected result `%t` not found, got `%t`\n%s", test.Expected, result, test.Expr)
+		}
+	}
+
+	if _, _, err := eval(t, event, `process.name + 1 == 2`); err == nil {
+		t.Error("arithmetic operations on strings should fail")
+	}
+}
+
+func TestArithmeticApprovers(t *testing.T) {
+	rule, err := parseRule(`process.uid + 1 == 5 && process.gid == 2`, &testModel{}, &Opts{Constants: testConstants})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if values := rule.GetFieldValues("process.uid"); len(values) != 0 {
+		t.Errorf("operands of arithmetic operations shouldn't be field values, got %+v", values)
+	}
+
+	if values := rule.GetFieldValues("process.gid"); len(values) != 1 || values[0].Value != 2 {
+		t.Errorf("unexpected field values %+v", values)
+	}
+}
+
+func TestDuration(t *testing.T) {
+	now := time.Now()
+
+	event := &testEvent{
+		process: testProcess{
+			createdAt: now.Add(-10 * time.Minute).UnixNano(),
+		},
+	}
+
+	tests := []struct {
+		Expr     string
+		Expected bool
+	}{
+		{Expr: `process.created_at > 5m`, Expected: true},
+		{Expr: `process.created_at > 1h`, Expected: false},
+		{Expr: `process.created_at < 15m`, Expected: true},
+		{Expr: `process.created_at >= 600s && process.created_at < 601000ms`, Expected: true},
+		{Expr: `process.created_at < 1d`, Expected: true},
+		{Expr: `5m < 10m`, Expected: true},
+	}
+
+	for _, test := range tests {
+		rule, err := parseRule(test.Expr, &testModel{}, &Opts{Constants: testConstants})
+		if err != nil {
+			t.Fatalf("error while evaluating `%s`: %s", test.Expr, err)
+		}
+
+		ctx := &Context{}
+		ctx.SetObject(unsafe.Pointer(event))
+		ctx.SetTimeFnc(func() time.Time { return now })
+
+		if result := rule.Eval(ctx); result != test.Expected {
+			t.Errorf("expected result `%t` not found, got `%t`\n%s", test.Expected, result, test.Expr)
+		}
+	}
+}
+
+func TestCIDR(t *testing.T) {
+	tests := []struct {
+		IP       string
+		Expr     string
+		Expected bool
+	}{
+		{IP: "10.1.2.3", Expr: `network.destination.ip in 10.0.0.0/8`, Expected: true},
+		{IP: "11.1.2.3", Expr: `network.destination.ip in 10.0.0.0/8`, Expected: false},
+		{IP: "11.1.2.3", Expr: `network.destination.ip not in 10.0.0.0/8`, Expected: true},
+		{IP: "192.168.1.1", Expr: `network.destination.ip in [ 10.0.0.0/8, 192.168.0.0/16 ]`, Expected: true},
+		{IP: "127.0.0.1", Expr: `network.destination.ip == 127.0.0.1`, Expected: true},
+		{IP: "127.0.0.2", Expr: `network.destination.ip != 127.0.0.1`, Expected: true},
+		{IP: "fd00::1", Expr: `network.destination.ip in [ 10.0.0.0/8, fd00::/8 ]`, Expected: true},
+		{IP: "fe80::1", Expr: `network.destination.ip in fd00::/8`, Expected: false},
+		{IP: "::1", Expr: `network.destination.ip == ::1`, Expected: true},
+		{IP: "10.1.0.0/16", Expr: `network.destination.ip in 10.0.0.0/8`, Expected: true},
+		{IP: "10.0.0.0/7", Expr: `network.destination.ip in 10.0.0.0/8`, Expected: false},
+		{IP: "", Expr: `network.destination.ip in 10.0.0.0/8`, Expected: false},
+	}
+
+	for _, test := range tests {
+		event := &testEvent{
+			network: testNetwork{
+				destinationIP: test.IP,
+			},
+		}
+
+		result, _, err := eval(t, event, test.Expr)
+		if err != nil {
+			t.Fatalf("error while evaluating `%s`: %s", test.Expr, err)
+		}
+
+		if result != test.Expected {
+			t.Errorf("expected result `%t` not found for `%s`, got `%t`\n%s", test.Expected, test.IP, result, test.Expr)
+		}
+	}
+
+	rule, err := parseRule(`network.destination.ip in [ 10.0.0.0/8, 192.168.1.1 ]`, &testModel{}, &Opts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := rule.GetFieldValues("network.destination.ip")
+	if len(values) != 2 || values[0].Type != IPNetValueType || values[0].Value != "10.0.0.0/8" || values[1].Value != "192.168.1.1/32" {
+		t.Errorf("unexpected field values %+v", values)
+	}
+
+	if _, err := parseRule(`network.destination.ip in 10.0.0.0/33`, &testModel{}, &Opts{}); err == nil {
+		t.Error("invalid CIDR should fail")
+	}
+}
+
+func TestCaseInsensitive(t *testing.T) {
+	event := &testEvent{
+		process: testProcess{
+			name: "/usr/bin/PowerShell.EXE",
+		},
+	}
+
+	tests := []struct {
+		Expr     string
+		Expected bool
+	}{
+		{Expr: `process.name == ~i"/usr/bin/powershell.exe"`, Expected: true},
+		{Expr: `process.name == ~"/usr/bin/powershell.exe"`, Expected: false},
+		{Expr: `process.name != ~i"/USR/BIN/POWERSHELL.EXE"`, Expected: false},
+		{Expr: `process.name =~ ~i"*.exe"`, Expected: true},
+		{Expr: `process.name !~ ~i"*.exe"`, Expected: false},
+		{Expr: `process.name in [ ~i"*/pwsh", ~i"*/powershell*" ]`, Expected: true},
+		{Expr: `process.name in [ "/usr/bin/powershell.exe", ~"*.exe" ]`, Expected: false},
+	}
+
+	for _, test := range tests {
+		result, _, err := eval(t, event, test.Expr)
+		if err != nil {
+			t.Fatalf("error while evaluating `%s`: %s", test.Expr, err)
+		}
+
+		if result != test.Expected {
+			t.Errorf("expected result `%t` not found, got `%t`\n%s", test.Expected, result, test.Expr)
+		}
+	}
+}
+
 func TestMacroList(t *testing.T) {
 	macro := &Macro{
 		ID:         "list",
@@ -7,6 +7,7 @@ package eval
 
 import (
 	"reflect"
+	"time"
 	"unsafe"
 )
 
@@ -31,6 +32,12 @@ type Event interface {
 	GetTags() []string
 }
 
+// TimestampedEvent is implemented by the events providing their time, used as reference to evaluate the durations
+type TimestampedEvent interface {
+	// ResolveEventTimestamp returns the time of the Event
+	ResolveEventTimestamp() time.Time
+}
+
 func eventTypesFromFields(model Model, state *state) ([]EventType, error) {
 	events := make(map[EventType]bool)
 	for field := range state.fieldValues {
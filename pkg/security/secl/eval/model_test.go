@@ -21,12 +21,13 @@ type testItem struct {
 }
 
 type testProcess struct {
-	name   string
-	uid    int
-	gid    int
-	isRoot bool
-	list   *list.List
-	array  []*testItem
+	name      string
+	uid       int
+	gid       int
+	isRoot    bool
+	createdAt int64
+	list      *list.List
+	array     []*testItem
 }
 
 type testItemListIterator struct {
@@ -88,6 +89,10 @@ type testMkdir struct {
 	mode     int
 }
 
+type testNetwork struct {
+	destinationIP string
+}
+
 type testEvent struct {
 	id   string
 	kind string
@@ -95,6 +100,7 @@ type testEvent struct {
 	process testProcess
 	open    testOpen
 	mkdir   testMkdir
+	network testNetwork
 
 	listEvaluated bool
 	uidEvaluated  bool
@@ -191,6 +197,13 @@ func (m *testModel) GetEvaluator(field Field, regID RegisterID) (Evaluator, erro
 			Field:   field,
 		}, nil
 
+	case "process.created_at":
+
+		return &IntEvaluator{
+			EvalFnc: func(ctx *Context) int { return int((*testEvent)(ctx.Object).process.createdAt) },
+			Field:   field,
+		}, nil
+
 	case "process.list.key":
 
 		return &IntEvaluator{
@@ -291,6 +304,13 @@ func (m *testModel) GetEvaluator(field Field, regID RegisterID) (Evaluator, erro
 			EvalFnc: func(ctx *Context) int { return (*testEvent)(ctx.Object).mkdir.mode },
 			Field:   field,
 		}, nil
+
+	case "network.destination.ip":
+
+		return &StringEvaluator{
+			EvalFnc: func(ctx *Context) string { return (*testEvent)(ctx.Object).network.destinationIP },
+			Field:   field,
+		}, nil
 	}
 
 	return nil, &ErrFieldNotFound{Field: field}
@@ -315,6 +335,10 @@ func (e *testEvent) GetFieldValue(field Field) (interface{}, error) {
 
 		return e.process.isRoot, nil
 
+	case "process.created_at":
+
+		return int(e.process.createdAt), nil
+
 	case "open.filename":
 
 		return e.open.filename, nil
@@ -335,6 +359,10 @@ func (e *testEvent) GetFieldValue(field Field) (interface{}, error) {
 
 		return e.mkdir.mode, nil
 
+	case "network.destination.ip":
+
+		return e.network.destinationIP, nil
+
 	}
 
 	return nil, &ErrFieldNotFound{Field: field}
@@ -359,6 +387,10 @@ func (e *testEvent) GetFieldEventType(field Field) (string, error) {
 
 		return "*", nil
 
+	case "process.created_at":
+
+		return "*", nil
+
 	case "process.list.key":
 
 		return "*", nil
@@ -395,6 +427,10 @@ func (e *testEvent) GetFieldEventType(field Field) (string, error) {
 
 		return "mkdir", nil
 
+	case "network.destination.ip":
+
+		return "network", nil
+
 	}
 
 	return "", &ErrFieldNotFound{Field: field}
@@ -423,6 +459,11 @@ func (e *testEvent) SetFieldValue(field Field, value interface{}) error {
 		e.process.isRoot = value.(bool)
 		return nil
 
+	case "process.created_at":
+
+		e.process.createdAt = int64(value.(int))
+		return nil
+
 	case "open.filename":
 
 		e.open.filename = value.(string)
@@ -448,6 +489,11 @@ func (e *testEvent) SetFieldValue(field Field, value interface{}) error {
 		e.mkdir.mode = value.(int)
 		return nil
 
+	case "network.destination.ip":
+
+		e.network.destinationIP = value.(string)
+		return nil
+
 	}
 
 	return &ErrFieldNotFound{Field: field}
@@ -472,6 +518,10 @@ func (e *testEvent) GetFieldType(field Field) (reflect.Kind, error) {
 
 		return reflect.Bool, nil
 
+	case "process.created_at":
+
+		return reflect.Int, nil
+
 	case "process.list.key":
 		return reflect.Int, nil
 
@@ -504,6 +554,10 @@ func (e *testEvent) GetFieldType(field Field) (reflect.Kind, error) {
 
 		return reflect.Int, nil
 
+	case "network.destination.ip":
+
+		return reflect.String, nil
+
 	}
 
 	return reflect.Invalid, &ErrFieldNotFound{Field: field}
@@ -6,7 +6,9 @@
 package eval
 
 import (
+	"net"
 	"sort"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -41,7 +43,7 @@ func IntNot(a *IntEvaluator, opts *Opts, state *state) *IntEvaluator {
 
 // StringMatches - String pattern matching operator
 func StringMatches(a *StringEvaluator, b *StringEvaluator, not bool, opts *Opts, state *state) (*BoolEvaluator, error) {
-	re, err := patternToRegexp(b.Value)
+	re, err := patternToRegexp(b.Value, b.CaseInsensitive)
 	if err != nil {
 		return nil, err
 	}
@@ -327,3 +329,161 @@ func IntArrayContains(a *IntEvaluator, b *IntArray, not bool, opts *Opts, state
 		isPartial: isPartialLeaf,
 	}, nil
 }
+
+// intArithmetic returns the evaluator of an arithmetic operation. Contrary to the comparisons, the operands of an
+// arithmetic operation are not approver values of their fields.
+func intArithmetic(a *IntEvaluator, b *IntEvaluator, op func(a, b int) int, state *state) *IntEvaluator {
+	partialA, partialB := a.isPartial, b.isPartial
+
+	if a.EvalFnc == nil || (a.Field != "" && a.Field != state.field) {
+		partialA = true
+	}
+	if b.EvalFnc == nil || (b.Field != "" && b.Field != state.field) {
+		partialB = true
+	}
+	isPartialLeaf := partialA && partialB
+
+	if a.Field != "" && b.Field != "" {
+		isPartialLeaf = true
+	}
+
+	if a.EvalFnc == nil && b.EvalFnc == nil {
+		return &IntEvaluator{
+			Value:     op(a.Value, b.Value),
+			isPartial: isPartialLeaf,
+		}
+	}
+
+	ea, eb := a.EvalFnc, b.EvalFnc
+	if ea == nil {
+		value := a.Value
+		ea = func(ctx *Context) int {
+			return value
+		}
+	}
+	if eb == nil {
+		value := b.Value
+		eb = func(ctx *Context) int {
+			return value
+		}
+	}
+
+	evalFnc := func(ctx *Context) int {
+		return op(ea(ctx), eb(ctx))
+	}
+
+	return &IntEvaluator{
+		EvalFnc:   evalFnc,
+		Weight:    a.Weight + b.Weight,
+		isPartial: isPartialLeaf,
+	}
+}
+
+// IntPlus - int + int operator
+func IntPlus(a *IntEvaluator, b *IntEvaluator, opts *Opts, state *state) *IntEvaluator {
+	return intArithmetic(a, b, func(a, b int) int { return a + b }, state)
+}
+
+// IntMinus - int - int operator
+func IntMinus(a *IntEvaluator, b *IntEvaluator, opts *Opts, state *state) *IntEvaluator {
+	return intArithmetic(a, b, func(a, b int) int { return a - b }, state)
+}
+
+// IntMultiply - int * int operator
+func IntMultiply(a *IntEvaluator, b *IntEvaluator, opts *Opts, state *state) *IntEvaluator {
+	return intArithmetic(a, b, func(a, b int) int { return a * b }, state)
+}
+
+// ElapsedSince returns the time elapsed between a timestamp, in nanoseconds since the epoch, and the time of the
+// event, so that it can be compared to a duration. As the result depends on the time of the event, it can't be used
+// to compute discarders.
+func ElapsedSince(a *IntEvaluator, opts *Opts, state *state) *IntEvaluator {
+	ea := a.EvalFnc
+	if ea == nil {
+		value := a.Value
+		ea = func(ctx *Context) int {
+			return value
+		}
+	}
+
+	evalFnc := func(ctx *Context) int {
+		return int(ctx.Now().Sub(time.Unix(0, int64(ea(ctx)))))
+	}
+
+	return &IntEvaluator{
+		EvalFnc:    evalFnc,
+		Weight:     a.Weight + FunctionWeight,
+		isPartial:  state.field != "",
+		isDuration: true,
+	}
+}
+
+// ipNetContains returns whether the network b is included in the network a
+func ipNetContains(a *net.IPNet, b *net.IPNet) bool {
+	onesA, bitsA := a.Mask.Size()
+	onesB, bitsB := b.Mask.Size()
+	return bitsA == bitsB && onesB >= onesA && a.Contains(b.IP)
+}
+
+// CIDRArrayContains - 10.0.0.1 in [10.0.0.0/8, ::1] operator. The string evaluated is an IP address or a CIDR, which
+// has to be fully included in one of the networks of the array.
+func CIDRArrayContains(a *StringEvaluator, b *CIDRArray, not bool, opts *Opts, state *state) (*BoolEvaluator, error) {
+	isPartialLeaf := a.isPartial
+	if a.Field != "" && state.field != "" && a.Field != state.field {
+		isPartialLeaf = true
+	}
+
+	if a.Field != "" {
+		for i, value := range b.Values {
+			if err := state.UpdateFieldValues(a.Field, FieldValue{Value: value, Type: IPNetValueType, IPNet: b.IPNets[i]}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	contains := func(s string) bool {
+		ipnet, err := parseIPNet(s)
+		if err != nil {
+			return false
+		}
+
+		for _, n := range b.IPNets {
+			if ipNetContains(n, ipnet) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if a.EvalFnc != nil {
+		ea := a.EvalFnc
+
+		evalFnc := func(ctx *Context) bool {
+			result := contains(ea(ctx))
+			if not {
+				return !result
+			}
+			return result
+		}
+
+		return &BoolEvaluator{
+			EvalFnc:   evalFnc,
+			Weight:    a.Weight + InArrayWeight*len(b.Values),
+			isPartial: isPartialLeaf,
+		}, nil
+	}
+
+	ea := true
+	if !isPartialLeaf {
+		ea = contains(a.Value)
+		if not {
+			ea = !ea
+		}
+	}
+
+	return &BoolEvaluator{
+		Value:     ea,
+		Weight:    a.Weight + InArrayWeight*len(b.Values),
+		isPartial: isPartialLeaf,
+	}, nil
+}
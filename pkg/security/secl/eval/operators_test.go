@@ -10,7 +10,7 @@ import (
 )
 
 func TestPatternValue(t *testing.T) {
-	re, err := patternToRegexp("^$[]{}+?/etc/?+*.conf")
+	re, err := patternToRegexp("^$[]{}+?/etc/?+*.conf", false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -19,7 +19,7 @@ func TestPatternValue(t *testing.T) {
 		t.Fatalf("expected regexp not found: %s", re.String())
 	}
 
-	if _, err = patternToRegexp("*"); err == nil {
+	if _, err = patternToRegexp("*", false); err == nil {
 		t.Fatal("wildcard only pattern is not supported")
 	}
 }
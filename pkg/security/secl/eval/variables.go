@@ -0,0 +1,327 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package eval
+
+import (
+	"container/list"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// VariableScope represents the object a variable is attached to
+type VariableScope = string
+
+// GlobalScope is the scope of the variables which aren't attached to any object
+const GlobalScope VariableScope = "global"
+
+// variableKey identifies the value of a variable for an object of its scope
+type variableKey struct {
+	scope  VariableScope
+	object string
+	name   string
+}
+
+// variableValue holds the value of a variable
+type variableValue struct {
+	key    variableKey
+	value  interface{}
+	expire time.Time
+}
+
+// VariableStore holds the variables read by the rule expressions, using the `${scope.name}` syntax, and set by
+// the rule actions. Apart from the global ones, variables are attached to an object, like a process or a
+// container, identified by the value of a field of the event. The store holds at most a fixed number of values,
+// the least recently set ones being evicted first, and values can expire.
+type VariableStore struct {
+	sync.Mutex
+	model     Model
+	scopes    map[VariableScope]Field
+	scopeKeys map[VariableScope]Evaluator
+	declared  map[string]reflect.Kind
+	values    map[variableKey]*list.Element
+	lru       *list.List
+	maxValues int
+	now       func() time.Time
+
+	sets     int64
+	evicted  int64
+	expired  int64
+	released int64
+}
+
+// NewVariableStore returns a new variable store. scopes maps each scope, other than the global one, to the field
+// identifying the objects of the scope.
+func NewVariableStore(model Model, scopes map[VariableScope]Field, maxValues int) *VariableStore {
+	return &VariableStore{
+		model:     model,
+		scopes:    scopes,
+		scopeKeys: make(map[VariableScope]Evaluator),
+		declared:  make(map[string]reflect.Kind),
+		values:    make(map[variableKey]*list.Element),
+		lru:       list.New(),
+		maxValues: maxValues,
+		now:       time.Now,
+	}
+}
+
+// ParseVariable splits a variable reference, like `process.wrote_cron`, into its scope and name. Variables
+// without scope are global.
+func ParseVariable(variable string) (VariableScope, string) {
+	if i := strings.IndexByte(variable, '.'); i >= 0 {
+		return variable[:i], variable[i+1:]
+	}
+	return GlobalScope, variable
+}
+
+func valueKind(value interface{}) reflect.Kind {
+	switch value.(type) {
+	case bool:
+		return reflect.Bool
+	case int:
+		return reflect.Int
+	case string:
+		return reflect.String
+	}
+	return reflect.Invalid
+}
+
+// Declare declares a variable with the type of the given value. A variable has to be declared before being used
+// by an expression.
+func (vs *VariableStore) Declare(scope VariableScope, name string, value interface{}) error {
+	vs.Lock()
+	defer vs.Unlock()
+
+	if scope != GlobalScope {
+		if _, exists := vs.scopes[scope]; !exists {
+			return fmt.Errorf("unknown variable scope `%s`", scope)
+		}
+	}
+
+	kind := valueKind(value)
+	if kind == reflect.Invalid {
+		return fmt.Errorf("invalid value type for variable `%s.%s`", scope, name)
+	}
+
+	id := scope + "." + name
+	if declared, exists := vs.declared[id]; exists && declared != kind {
+		return fmt.Errorf("variable `%s` declared as %s and %s", id, declared, kind)
+	}
+	vs.declared[id] = kind
+
+	return nil
+}
+
+// getScopeKey returns the key of the object of the scope the event belongs to
+func (vs *VariableStore) getScopeKey(ctx *Context, scope VariableScope) (string, bool) {
+	if scope == GlobalScope {
+		return "", true
+	}
+
+	evaluator, exists := vs.scopeKeys[scope]
+	if !exists {
+		return "", false
+	}
+
+	switch key := evaluator.Eval(ctx).(type) {
+	case string:
+		return key, key != ""
+	case int:
+		return fmt.Sprintf("%d", key), key != 0
+	}
+	return "", false
+}
+
+// resolveScopeKey resolves the evaluator of the field identifying the objects of the scope
+func (vs *VariableStore) resolveScopeKey(scope VariableScope) error {
+	if scope == GlobalScope {
+		return nil
+	}
+
+	if _, exists := vs.scopeKeys[scope]; exists {
+		return nil
+	}
+
+	field, exists := vs.scopes[scope]
+	if !exists {
+		return fmt.Errorf("unknown variable scope `%s`", scope)
+	}
+
+	evaluator, err := vs.model.GetEvaluator(field, "")
+	if err != nil {
+		return errors.Wrapf(err, "couldn't resolve scope `%s`", scope)
+	}
+	vs.scopeKeys[scope] = evaluator
+
+	return nil
+}
+
+// Get returns the value of the variable for the event of the context
+func (vs *VariableStore) Get(ctx *Context, scope VariableScope, name string) (interface{}, bool) {
+	vs.Lock()
+	defer vs.Unlock()
+
+	object, ok := vs.getScopeKey(ctx, scope)
+	if !ok {
+		return nil, false
+	}
+
+	key := variableKey{scope: scope, object: object, name: name}
+	element, exists := vs.values[key]
+	if !exists {
+		return nil, false
+	}
+
+	value := element.Value.(*variableValue)
+	if !value.expire.IsZero() && vs.now().After(value.expire) {
+		vs.remove(element)
+		vs.expired++
+		return nil, false
+	}
+
+	return value.value, true
+}
+
+// Set sets the value of the variable for the event of the context. The value expires after ttl, unless ttl is zero.
+func (vs *VariableStore) Set(ctx *Context, scope VariableScope, name string, value interface{}, ttl time.Duration) error {
+	vs.Lock()
+	defer vs.Unlock()
+
+	if err := vs.resolveScopeKey(scope); err != nil {
+		return err
+	}
+
+	object, ok := vs.getScopeKey(ctx, scope)
+	if !ok {
+		return nil
+	}
+
+	var expire time.Time
+	if ttl > 0 {
+		expire = vs.now().Add(ttl)
+	}
+
+	vs.sets++
+
+	key := variableKey{scope: scope, object: object, name: name}
+	if element, exists := vs.values[key]; exists {
+		element.Value.(*variableValue).value = value
+		element.Value.(*variableValue).expire = expire
+		vs.lru.MoveToFront(element)
+		return nil
+	}
+
+	for vs.lru.Len() >= vs.maxValues && vs.lru.Len() > 0 {
+		vs.remove(vs.lru.Back())
+		vs.evicted++
+	}
+	vs.values[key] = vs.lru.PushFront(&variableValue{key: key, value: value, expire: expire})
+
+	return nil
+}
+
+// Release removes the values of all the variables attached to the object of the scope the event belongs to. It is
+// called once the object is gone, like a process that exited, so that an object reusing its key, like a new process
+// reusing its pid, doesn't inherit the values.
+func (vs *VariableStore) Release(ctx *Context, scope VariableScope) {
+	vs.Lock()
+	defer vs.Unlock()
+
+	if scope == GlobalScope || vs.resolveScopeKey(scope) != nil {
+		return
+	}
+
+	object, ok := vs.getScopeKey(ctx, scope)
+	if !ok {
+		return
+	}
+
+	prefix := scope + "."
+	for id := range vs.declared {
+		if !strings.HasPrefix(id, prefix) {
+			continue
+		}
+
+		key := variableKey{scope: scope, object: object, name: strings.TrimPrefix(id, prefix)}
+		if element, exists := vs.values[key]; exists {
+			vs.remove(element)
+			vs.released++
+		}
+	}
+}
+
+func (vs *VariableStore) remove(element *list.Element) {
+	vs.lru.Remove(element)
+	delete(vs.values, element.Value.(*variableValue).key)
+}
+
+// GetEvaluator returns an evaluator reading the value of the given variable. Unset variables evaluate to the zero
+// value of their type.
+func (vs *VariableStore) GetEvaluator(scope VariableScope, name string) (interface{}, error) {
+	vs.Lock()
+	kind, exists := vs.declared[scope+"."+name]
+	if !exists {
+		vs.Unlock()
+		return nil, fmt.Errorf("variable `%s.%s` is not set by any rule", scope, name)
+	}
+	err := vs.resolveScopeKey(scope)
+	vs.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case reflect.Bool:
+		return &BoolEvaluator{
+			EvalFnc: func(ctx *Context) bool {
+				value, _ := vs.Get(ctx, scope, name)
+				b, _ := value.(bool)
+				return b
+			},
+		}, nil
+	case reflect.Int:
+		return &IntEvaluator{
+			EvalFnc: func(ctx *Context) int {
+				value, _ := vs.Get(ctx, scope, name)
+				i, _ := value.(int)
+				return i
+			},
+		}, nil
+	default:
+		return &StringEvaluator{
+			EvalFnc: func(ctx *Context) string {
+				value, _ := vs.Get(ctx, scope, name)
+				s, _ := value.(string)
+				return s
+			},
+		}, nil
+	}
+}
+
+// GetStats returns statistics about the variable store
+func (vs *VariableStore) GetStats() map[string]interface{} {
+	vs.Lock()
+	defer vs.Unlock()
+
+	values := make(map[VariableScope]int)
+	for key := range vs.values {
+		values[key.scope]++
+	}
+
+	return map[string]interface{}{
+		"values":     values,
+		"max_values": vs.maxValues,
+		"sets":       vs.sets,
+		"evicted":    vs.evicted,
+		"expired":    vs.expired,
+		"released":   vs.released,
+	}
+}
@@ -0,0 +1,215 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package eval
+
+import (
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func newTestVariableStore(maxValues int) *VariableStore {
+	return NewVariableStore(&testModel{}, map[VariableScope]Field{"process": "process.name"}, maxValues)
+}
+
+func newTestContext(event *testEvent) *Context {
+	ctx := &Context{}
+	ctx.SetObject(unsafe.Pointer(event))
+	return ctx
+}
+
+func TestVariables(t *testing.T) {
+	store := newTestVariableStore(10)
+	if err := store.Declare("process", "wrote_cron", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Declare(GlobalScope, "counter", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := &Opts{Constants: testConstants, Variables: store}
+
+	rule, err := parseRule(`${process.wrote_cron} == true && open.filename == "/etc/crontab"`, &testModel{}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counterRule, err := parseRule(`${counter} > 1`, &testModel{}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sh := &testEvent{process: testProcess{name: "sh"}, open: testOpen{filename: "/etc/crontab"}}
+	bash := &testEvent{process: testProcess{name: "bash"}, open: testOpen{filename: "/etc/crontab"}}
+
+	if rule.Eval(newTestContext(sh)) {
+		t.Error("rule shouldn't match before the variable is set")
+	}
+
+	if err := store.Set(newTestContext(sh), "process", "wrote_cron", true, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if !rule.Eval(newTestContext(sh)) {
+		t.Error("rule should match once the variable is set")
+	}
+
+	if rule.Eval(newTestContext(bash)) {
+		t.Error("rule shouldn't match for another process")
+	}
+
+	if counterRule.Eval(newTestContext(bash)) {
+		t.Error("unset variable should evaluate to zero")
+	}
+
+	if err := store.Set(newTestContext(sh), GlobalScope, "counter", 2, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if !counterRule.Eval(newTestContext(bash)) {
+		t.Error("global variable should be shared by all the processes")
+	}
+}
+
+func TestVariablesErrors(t *testing.T) {
+	store := newTestVariableStore(10)
+	if err := store.Declare("process", "wrote_cron", true); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Declare("process", "wrote_cron", "yes"); err == nil {
+		t.Error("variable redeclared with another type should fail")
+	}
+
+	if err := store.Declare("container", "wrote_cron", true); err == nil {
+		t.Error("variable declared with an unknown scope should fail")
+	}
+
+	opts := &Opts{Constants: testConstants, Variables: store}
+
+	if _, err := parseRule(`${process.unknown} == true`, &testModel{}, opts); err == nil {
+		t.Error("undeclared variable should fail")
+	}
+
+	if _, err := parseRule(`${process.wrote_cron} == "yes"`, &testModel{}, opts); err == nil {
+		t.Error("variable compared to a value of another type should fail")
+	}
+
+	if _, err := parseRule(`${process.wrote_cron} == true`, &testModel{}, &Opts{Constants: testConstants}); err == nil {
+		t.Error("variable used without store should fail")
+	}
+}
+
+func TestVariablesTTL(t *testing.T) {
+	now := time.Now()
+
+	store := newTestVariableStore(10)
+	store.now = func() time.Time { return now }
+
+	ctx := newTestContext(&testEvent{process: testProcess{name: "sh"}})
+
+	if err := store.Set(ctx, "process", "wrote_cron", true, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, exists := store.Get(ctx, "process", "wrote_cron"); !exists {
+		t.Error("variable should be set")
+	}
+
+	now = now.Add(2 * time.Minute)
+
+	if _, exists := store.Get(ctx, "process", "wrote_cron"); exists {
+		t.Error("variable should have expired")
+	}
+
+	if stats := store.GetStats(); stats["expired"] != int64(1) {
+		t.Errorf("expected 1 expired value, got %v", stats["expired"])
+	}
+}
+
+func TestVariablesEviction(t *testing.T) {
+	store := newTestVariableStore(2)
+
+	for _, name := range []string{"a", "b", "c"} {
+		ctx := newTestContext(&testEvent{process: testProcess{name: name}})
+		if err := store.Set(ctx, "process", "wrote_cron", true, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, exists := store.Get(newTestContext(&testEvent{process: testProcess{name: "a"}}), "process", "wrote_cron"); exists {
+		t.Error("least recently set value should have been evicted")
+	}
+
+	for _, name := range []string{"b", "c"} {
+		if _, exists := store.Get(newTestContext(&testEvent{process: testProcess{name: name}}), "process", "wrote_cron"); !exists {
+			t.Errorf("value of `%s` should be set", name)
+		}
+	}
+
+	if stats := store.GetStats(); stats["evicted"] != int64(1) {
+		t.Errorf("expected 1 evicted value, got %v", stats["evicted"])
+	}
+}
+
+func TestVariablesPartial(t *testing.T) {
+	store := newTestVariableStore(10)
+	if err := store.Declare("process", "wrote_cron", true); err != nil {
+		t.Fatal(err)
+	}
+
+	rule, err := parseRule(`${process.wrote_cron} == true && open.filename == "/etc/crontab"`, &testModel{}, &Opts{Constants: testConstants, Variables: store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rule.GenPartials(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the variable isn't set yet, but may be later on, so that the filename can't be discarded
+	ctx := newTestContext(&testEvent{process: testProcess{name: "sh"}, open: testOpen{filename: "/etc/crontab"}})
+	result, err := rule.PartialEval(ctx, "open.filename")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result {
+		t.Error("variable shouldn't be used to compute discarders")
+	}
+}
+
+func TestVariablesRelease(t *testing.T) {
+	store := newTestVariableStore(10)
+	for _, name := range []string{"wrote_cron", "opened"} {
+		if err := store.Declare("process", name, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sh := newTestContext(&testEvent{process: testProcess{name: "sh"}})
+	bash := newTestContext(&testEvent{process: testProcess{name: "bash"}})
+	for _, ctx := range []*Context{sh, bash} {
+		for _, name := range []string{"wrote_cron", "opened"} {
+			if err := store.Set(ctx, "process", name, true, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	store.Release(sh, "process")
+
+	for _, name := range []string{"wrote_cron", "opened"} {
+		if _, exists := store.Get(sh, "process", name); exists {
+			t.Errorf("variable `%s` of the released process shouldn't be set", name)
+		}
+		if _, exists := store.Get(bash, "process", name); !exists {
+			t.Errorf("variable `%s` of another process should be kept", name)
+		}
+	}
+
+	if stats := store.GetStats(); stats["released"] != int64(2) {
+		t.Errorf("expected 2 released values, got %v", stats["released"])
+	}
+}
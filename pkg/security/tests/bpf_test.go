@@ -0,0 +1,74 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build functionaltests
+
+package tests
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"syscall"
+	"testing"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/DataDog/datadog-agent/pkg/security/rules"
+)
+
+// bpfMapCreateAttr is the part of the bpf_attr union used by BPF_MAP_CREATE
+type bpfMapCreateAttr struct {
+	mapType    uint32
+	keySize    uint32
+	valueSize  uint32
+	maxEntries uint32
+	mapFlags   uint32
+}
+
+func TestBPF(t *testing.T) {
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ruleDef := &rules.RuleDefinition{
+		ID:         "test_rule",
+		Expression: fmt.Sprintf(`bpf.cmd == BPF_MAP_CREATE && process.name == "%s"`, path.Base(executable)),
+	}
+
+	test, err := newTestModule(nil, []*rules.RuleDefinition{ruleDef}, testOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer test.Close()
+
+	attr := bpfMapCreateAttr{
+		mapType:    unix.BPF_MAP_TYPE_ARRAY,
+		keySize:    4,
+		valueSize:  4,
+		maxEntries: 1,
+	}
+
+	fd, _, errno := syscall.Syscall(unix.SYS_BPF, unix.BPF_MAP_CREATE, uintptr(unsafe.Pointer(&attr)), unsafe.Sizeof(attr))
+	if errno != 0 {
+		t.Fatal(errno)
+	}
+	defer syscall.Close(int(fd))
+
+	event, _, err := test.GetEvent()
+	if err != nil {
+		t.Error(err)
+	} else {
+		if event.GetType() != "bpf" {
+			t.Errorf("expected bpf event, got %s", event.GetType())
+		}
+
+		if retval := event.BPF.Retval; retval != int64(fd) {
+			t.Errorf("expected retval of %d, got %d", fd, retval)
+		}
+	}
+}
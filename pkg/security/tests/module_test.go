@@ -0,0 +1,75 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build functionaltests
+
+package tests
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/DataDog/datadog-agent/pkg/security/rules"
+)
+
+const testModuleName = "dummy"
+
+func TestLoadModule(t *testing.T) {
+	var uname unix.Utsname
+	if err := unix.Uname(&uname); err != nil {
+		t.Fatal(err)
+	}
+
+	modulePath := path.Join("/lib/modules", unix.ByteSliceToString(uname.Release[:]), "kernel/drivers/net", testModuleName+".ko")
+	if _, err := os.Stat(modulePath); err != nil {
+		t.Skipf("module %s not available: %s", modulePath, err)
+	}
+
+	if _, err := os.Stat(path.Join("/sys/module", testModuleName)); err == nil {
+		t.Skipf("module %s already loaded", testModuleName)
+	}
+
+	ruleDef := &rules.RuleDefinition{
+		ID:         "test_rule",
+		Expression: `load_module.name == "` + testModuleName + `"`,
+	}
+
+	test, err := newTestModule(nil, []*rules.RuleDefinition{ruleDef}, testOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer test.Close()
+
+	f, err := os.Open(modulePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if err := unix.FinitModule(int(f.Fd()), "", 0); err != nil {
+		t.Fatal(err)
+	}
+	defer unix.DeleteModule(testModuleName, 0)
+
+	event, _, err := test.GetEvent()
+	if err != nil {
+		t.Error(err)
+	} else {
+		if event.GetType() != "load_module" {
+			t.Errorf("expected load_module event, got %s", event.GetType())
+		}
+
+		if event.LoadModule.LoadedFromMemory {
+			t.Error("expected the module to be loaded from a file")
+		}
+
+		if retval := event.LoadModule.Retval; retval != 0 {
+			t.Errorf("expected retval of 0, got %d", retval)
+		}
+	}
+}
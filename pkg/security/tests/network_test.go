@@ -0,0 +1,137 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build functionaltests
+
+package tests
+
+import (
+	"net"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/security/model"
+	"github.com/DataDog/datadog-agent/pkg/security/rules"
+)
+
+func TestConnect(t *testing.T) {
+	ruleDef := &rules.RuleDefinition{
+		ID:         "test_rule",
+		Expression: `connect.addr.family == AF_INET && connect.addr.port == 4242 && connect.addr.ip in [127.0.0.0/8]`,
+	}
+
+	test, err := newTestModule(nil, []*rules.RuleDefinition{ruleDef}, testOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer test.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:4242")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	conn, err := net.Dial("tcp", "127.0.0.1:4242")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	event, _, err := test.GetEvent()
+	if err != nil {
+		t.Error(err)
+	} else {
+		if event.GetType() != "connect" {
+			t.Errorf("expected connect event, got %s", event.GetType())
+		}
+
+		if ip := event.Connect.Addr.IP; ip != "127.0.0.1" {
+			t.Errorf("expected ip 127.0.0.1, got %s", ip)
+		}
+	}
+}
+
+func TestBind(t *testing.T) {
+	ruleDef := &rules.RuleDefinition{
+		ID:         "test_rule",
+		Expression: `bind.addr.family == AF_INET && bind.addr.port == 4243`,
+	}
+
+	test, err := newTestModule(nil, []*rules.RuleDefinition{ruleDef}, testOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer test.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:4243")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	event, _, err := test.GetEvent()
+	if err != nil {
+		t.Error(err)
+	} else {
+		if event.GetType() != "bind" {
+			t.Errorf("expected bind event, got %s", event.GetType())
+		}
+
+		if ip := event.Bind.Addr.IP; ip != "127.0.0.1" {
+			t.Errorf("expected ip 127.0.0.1, got %s", ip)
+		}
+
+		if retval := event.Bind.Retval; retval != 0 {
+			t.Errorf("expected retval of 0, got %d", retval)
+		}
+	}
+}
+
+func TestDNS(t *testing.T) {
+	ruleDef := &rules.RuleDefinition{
+		ID:         "test_rule",
+		Expression: `dns.question.name == "datadog-agent.test" && dns.question.type == A`,
+	}
+
+	test, err := newTestModule(nil, []*rules.RuleDefinition{ruleDef}, testOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer test.Close()
+
+	conn, err := net.Dial("udp", "127.0.0.1:53")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	query := []byte{
+		0x12, 0x34, 0x01, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		13, 'd', 'a', 't', 'a', 'd', 'o', 'g', '-', 'a', 'g', 'e', 'n', 't', 4, 't', 'e', 's', 't', 0,
+		0x00, 0x01, 0x00, 0x01,
+	}
+
+	// nothing has to listen on the port, the query is captured when sent
+	if _, err := conn.Write(query); err != nil {
+		t.Fatal(err)
+	}
+
+	event, _, err := test.GetEvent()
+	if err != nil {
+		t.Error(err)
+	} else {
+		if event.GetType() != "dns" {
+			t.Errorf("expected dns event, got %s", event.GetType())
+		}
+
+		if event.DNS.ID != 0x1234 {
+			t.Errorf("expected id 0x1234, got 0x%x", event.DNS.ID)
+		}
+
+		if class := model.QClass(event.DNS.Question.Class).String(); class != "CLASS_INET" {
+			t.Errorf("expected class CLASS_INET, got %s", class)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build functionaltests
+
+package tests
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"runtime"
+	"syscall"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/security/rules"
+)
+
+func TestPTrace(t *testing.T) {
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ruleDef := &rules.RuleDefinition{
+		ID:         "test_rule",
+		Expression: fmt.Sprintf(`ptrace.request == PTRACE_ATTACH && process.name == "%s"`, path.Base(executable)),
+	}
+
+	test, err := newTestModule(nil, []*rules.RuleDefinition{ruleDef}, testOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer test.Close()
+
+	cmd := exec.Command("sleep", "10")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer cmd.Wait()
+	defer cmd.Process.Kill()
+
+	// the tracer is the thread which attached to the tracee
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := syscall.PtraceAttach(cmd.Process.Pid); err != nil {
+		t.Fatal(err)
+	}
+
+	var status syscall.WaitStatus
+	if _, err := syscall.Wait4(cmd.Process.Pid, &status, syscall.WALL, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := syscall.PtraceDetach(cmd.Process.Pid); err != nil {
+		t.Fatal(err)
+	}
+
+	event, _, err := test.GetEvent()
+	if err != nil {
+		t.Error(err)
+	} else {
+		if event.GetType() != "ptrace" {
+			t.Errorf("expected ptrace event, got %s", event.GetType())
+		}
+
+		if pid := event.PTrace.PID; pid != uint32(cmd.Process.Pid) {
+			t.Errorf("expected ptrace pid %d, got %d", cmd.Process.Pid, pid)
+		}
+
+		if retval := event.PTrace.Retval; retval != 0 {
+			t.Errorf("expected retval of 0, got %d", retval)
+		}
+	}
+}
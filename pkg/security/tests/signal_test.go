@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build functionaltests
+
+package tests
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"syscall"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/security/rules"
+)
+
+func TestSignal(t *testing.T) {
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ruleDef := &rules.RuleDefinition{
+		ID:         "test_rule",
+		Expression: fmt.Sprintf(`signal.type == SIGUSR1 && process.name == "%s"`, path.Base(executable)),
+	}
+
+	test, err := newTestModule(nil, []*rules.RuleDefinition{ruleDef}, testOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer test.Close()
+
+	cmd := exec.Command("sleep", "10")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer cmd.Wait()
+
+	if err := syscall.Kill(cmd.Process.Pid, syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+
+	event, _, err := test.GetEvent()
+	if err != nil {
+		t.Error(err)
+	} else {
+		if event.GetType() != "signal" {
+			t.Errorf("expected signal event, got %s", event.GetType())
+		}
+
+		if pid := event.Signal.PID; pid != uint32(cmd.Process.Pid) {
+			t.Errorf("expected signal pid %d, got %d", cmd.Process.Pid, pid)
+		}
+
+		if retval := event.Signal.Retval; retval != 0 {
+			t.Errorf("expected retval of 0, got %d", retval)
+		}
+	}
+}
@@ -121,6 +121,17 @@ func FormatSecurityAgentStatus(data []byte) (string, error) {
 	return b.String(), nil
 }
 
+// FormatComplianceStatus takes a json bytestring and prints out the formatted status of the compliance checks
+func FormatComplianceStatus(data []byte) (string, error) {
+	var b = new(bytes.Buffer)
+
+	stats := make(map[string]interface{})
+	json.Unmarshal(data, &stats) //nolint:errcheck
+	renderComplianceChecksStats(b, stats["runnerStats"], stats["complianceChecks"])
+
+	return b.String(), nil
+}
+
 // FormatMetadataMapCLI builds the rendering in the metadataMapper template.
 func FormatMetadataMapCLI(data []byte) (string, error) {
 	var b = new(bytes.Buffer)
@@ -9,6 +9,7 @@ import (
 	"context"
 	"runtime"
 	"strconv"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/trace/event"
 	"github.com/DataDog/datadog-agent/pkg/trace/filters"
 	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/inspect"
 	"github.com/DataDog/datadog-agent/pkg/trace/metrics/timing"
 	"github.com/DataDog/datadog-agent/pkg/trace/obfuscate"
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
@@ -36,17 +38,22 @@ const (
 
 // Agent struct holds all the sub-routines structs and make the data flow between them
 type Agent struct {
-	Receiver          *api.HTTPReceiver
-	Concentrator      *stats.Concentrator
-	Blacklister       *filters.Blacklister
-	Replacer          *filters.Replacer
-	PrioritySampler   *sampler.PrioritySampler
-	ErrorsSampler     *sampler.ErrorsSampler
-	ExceptionSampler  *sampler.ExceptionSampler
-	NoPrioritySampler *sampler.NoPrioritySampler
-	EventProcessor    *event.Processor
-	TraceWriter       *writer.TraceWriter
-	StatsWriter       *writer.StatsWriter
+	Receiver              *api.HTTPReceiver
+	Concentrator          *stats.Concentrator
+	ClientStatsAggregator *stats.ClientStatsAggregator
+	Blacklister           *filters.Blacklister
+	Replacer              *filters.Replacer
+	PrioritySampler       *sampler.PrioritySampler
+	ErrorsSampler         *sampler.ErrorsSampler
+	ExceptionSampler      *sampler.ExceptionSampler
+	NoPrioritySampler     *sampler.NoPrioritySampler
+	EventProcessor        *event.Processor
+	TraceWriter           *writer.TraceWriter
+	StatsWriter           *writer.StatsWriter
+
+	// Inspector records the recently processed traces for local inspection. It is nil
+	// when trace inspection is disabled.
+	Inspector *inspect.Recorder
 
 	// obfuscator is used to obfuscate sensitive data from various span
 	// tags based on their type.
@@ -55,6 +62,15 @@ type Agent struct {
 	// In takes incoming payloads to be processed by the agent.
 	In chan *api.Payload
 
+	// clientStats receives the client stats payloads aggregated by ClientStatsAggregator.
+	clientStats chan pb.ClientStatsPayload
+	// clientStatsWG waits for the payloads in clientStats to be sent on exit.
+	clientStatsWG sync.WaitGroup
+	// clientDims caps the additional aggregation dimensions of the client stats, the same
+	// way as the concentrator does for the spans. It is guarded by clientDimsMu.
+	clientDims   *stats.Dimensions
+	clientDimsMu sync.Mutex
+
 	// config
 	conf *config.AgentConfig
 
@@ -68,24 +84,40 @@ func NewAgent(ctx context.Context, conf *config.AgentConfig) *Agent {
 	dynConf := sampler.NewDynamicConfig(conf.DefaultEnv)
 	in := make(chan *api.Payload, 1000)
 	statsChan := make(chan []stats.Bucket, 100)
+	clientStatsChan := make(chan pb.ClientStatsPayload, 100)
+	newDims := func() *stats.Dimensions {
+		return stats.NewDimensions(
+			conf.StatsAggregation.PeerService,
+			conf.StatsAggregation.HTTPMethod,
+			conf.StatsAggregation.Tags,
+			conf.StatsAggregation.MaxCardinality,
+		)
+	}
 
 	agnt := &Agent{
-		Concentrator:      stats.NewConcentrator(conf.BucketInterval.Nanoseconds(), statsChan, time.Now()),
-		Blacklister:       filters.NewBlacklister(conf.Ignore["resource"]),
-		Replacer:          filters.NewReplacer(conf.ReplaceTags),
-		PrioritySampler:   sampler.NewPrioritySampler(conf, dynConf),
-		ErrorsSampler:     sampler.NewErrorsSampler(conf),
-		ExceptionSampler:  sampler.NewExceptionSampler(),
-		NoPrioritySampler: sampler.NewNoPrioritySampler(conf),
-		EventProcessor:    newEventProcessor(conf),
-		TraceWriter:       writer.NewTraceWriter(conf),
-		StatsWriter:       writer.NewStatsWriter(conf, statsChan),
-		obfuscator:        obfuscate.NewObfuscator(conf.Obfuscation),
-		In:                in,
-		conf:              conf,
-		ctx:               ctx,
+		Concentrator:          stats.NewConcentrator(conf.BucketInterval.Nanoseconds(), statsChan, time.Now(), newDims()),
+		ClientStatsAggregator: stats.NewClientStatsAggregator(conf.BucketInterval.Nanoseconds(), clientStatsChan, time.Now()),
+		Blacklister:           filters.NewBlacklister(conf.Ignore["resource"]),
+		Replacer:              filters.NewReplacer(conf.ReplaceTags),
+		PrioritySampler:       sampler.NewPrioritySampler(conf, dynConf),
+		ErrorsSampler:         sampler.NewErrorsSampler(conf),
+		ExceptionSampler:      sampler.NewExceptionSampler(),
+		NoPrioritySampler:     sampler.NewNoPrioritySampler(conf),
+		EventProcessor:        newEventProcessor(conf),
+		TraceWriter:           writer.NewTraceWriter(conf),
+		StatsWriter:           writer.NewStatsWriter(conf, statsChan),
+		obfuscator:            obfuscate.NewObfuscator(conf.Obfuscation),
+		In:                    in,
+		clientStats:           clientStatsChan,
+		clientDims:            newDims(),
+		conf:                  conf,
+		ctx:                   ctx,
 	}
 	agnt.Receiver = api.NewHTTPReceiver(conf, dynConf, in, agnt)
+	if conf.InspectorMaxTraces > 0 {
+		agnt.Inspector = inspect.NewRecorder(conf.InspectorMaxTraces)
+		agnt.Receiver.Inspector = agnt.Inspector
+	}
 	return agnt
 }
 
@@ -94,6 +126,7 @@ func (a *Agent) Run() {
 	for _, starter := range []interface{ Start() }{
 		a.Receiver,
 		a.Concentrator,
+		a.ClientStatsAggregator,
 		a.PrioritySampler,
 		a.ErrorsSampler,
 		a.NoPrioritySampler,
@@ -104,6 +137,13 @@ func (a *Agent) Run() {
 
 	go a.TraceWriter.Run()
 	go a.StatsWriter.Run()
+	a.clientStatsWG.Add(1)
+	go func() {
+		defer a.clientStatsWG.Done()
+		for p := range a.clientStats {
+			a.sendClientStats(p)
+		}
+	}()
 
 	for i := 0; i < runtime.NumCPU(); i++ {
 		go a.work()
@@ -152,6 +192,11 @@ func (a *Agent) loop() {
 				log.Error(err)
 			}
 			a.Concentrator.Stop()
+			a.ClientStatsAggregator.Stop()
+			// the aggregator flushed its buckets into clientStats on Stop; send them
+			// before stopping the stats writer.
+			close(a.clientStats)
+			a.clientStatsWG.Wait()
 			a.TraceWriter.Stop()
 			a.StatsWriter.Stop()
 			a.PrioritySampler.Stop()
@@ -198,6 +243,7 @@ func (a *Agent) Process(p *api.Payload) {
 			log.Debugf("Trace rejected by blacklister. root: %v", root)
 			atomic.AddInt64(&ts.TracesFiltered, 1)
 			atomic.AddInt64(&ts.SpansFiltered, tracen)
+			a.inspectFiltered(t, root, inspect.ReasonFilteredResource)
 			continue
 		}
 
@@ -205,16 +251,27 @@ func (a *Agent) Process(p *api.Payload) {
 			log.Debugf("Trace rejected as it fails to meet tag requirements. root: %v", root)
 			atomic.AddInt64(&ts.TracesFiltered, 1)
 			atomic.AddInt64(&ts.SpansFiltered, tracen)
+			a.inspectFiltered(t, root, inspect.ReasonFilteredTags)
 			continue
 		}
 
 		// Extra sanitization steps of the trace.
+		var obfuscated, truncated []inspect.Change
 		for _, span := range t {
 			for k, v := range a.conf.GlobalTags {
 				traceutil.SetMeta(span, k, v)
 			}
-			a.obfuscator.Obfuscate(span)
-			Truncate(span)
+			if a.Inspector == nil {
+				a.obfuscator.Obfuscate(span)
+				Truncate(span)
+			} else {
+				snap := inspect.TakeSnapshot(span)
+				a.obfuscator.Obfuscate(span)
+				obfuscated = append(obfuscated, snap.Diff(span)...)
+				snap = inspect.TakeSnapshot(span)
+				Truncate(span)
+				truncated = append(truncated, snap.Diff(span)...)
+			}
 			if p.ClientComputedTopLevel {
 				traceutil.UpdateTracerTopLevel(span)
 			}
@@ -252,7 +309,22 @@ func (a *Agent) Process(p *api.Payload) {
 			Env:           env,
 		}
 
-		events, keep := a.sample(ts, pt)
+		events, keep, reason := a.sample(ts, pt)
+		if a.Inspector != nil {
+			it := inspect.NewTrace(t, root, env)
+			it.Sampling = inspect.Sampling{
+				Kept:     keep,
+				Reason:   reason,
+				Priority: samplingPriority(root),
+				Events:   len(events),
+			}
+			it.Obfuscation = obfuscated
+			it.Truncation = truncated
+			if !a.conf.InspectorRawValues {
+				it.RedactObfuscation()
+			}
+			a.Inspector.Record(it)
+		}
 
 		if !p.ClientComputedStats {
 			if sinputs == nil {
@@ -289,21 +361,46 @@ func (a *Agent) Process(p *api.Payload) {
 var _ api.StatsProcessor = (*Agent)(nil)
 
 // ProcessStats processes incoming client stats in from the given language lang.
+// They are aggregated with the stats sent by other tracers before being sent.
 func (a *Agent) ProcessStats(in pb.ClientStatsPayload, lang string) {
+	in = a.processStats(in, lang)
+	if a.conf.SynchronousFlushing {
+		// stats are expected to be sent on the next call to FlushSync.
+		a.sendClientStats(in)
+		return
+	}
+	a.ClientStatsAggregator.In <- in
+}
+
+// processStats normalizes and obfuscates the client stats in, and applies the replace
+// rules to them.
+func (a *Agent) processStats(in pb.ClientStatsPayload, lang string) pb.ClientStatsPayload {
 	if in.Env == "" {
 		in.Env = a.conf.DefaultEnv
 	}
 	in.Env = traceutil.NormalizeTag(in.Env)
+	a.clientDimsMu.Lock()
+	defer a.clientDimsMu.Unlock()
+	for i := range in.Stats {
+		for j := range in.Stats[i].Stats {
+			b := &in.Stats[i].Stats[j]
+			normalizeStatsGroup(b, lang)
+			a.obfuscator.ObfuscateStatsGroup(b)
+			a.Replacer.ReplaceStatsGroup(b)
+			a.clientDims.ApplyClient(b)
+		}
+	}
+	return in
+}
+
+// sendClientStats converts the processed client stats in and sends them to the stats writer.
+func (a *Agent) sendClientStats(in pb.ClientStatsPayload) {
 	out := stats.Payload{
 		HostName: in.Hostname,
 		Env:      in.Env,
 	}
 	for _, group := range in.Stats {
 		for _, b := range group.Stats {
-			normalizeStatsGroup(&b, lang)
-			a.obfuscator.ObfuscateStatsGroup(&b)
-			a.Replacer.ReplaceStatsGroup(&b)
-
 			statusCode := ""
 			if b.HTTPStatusCode != 0 {
 				statusCode = strconv.Itoa(int(b.HTTPStatusCode))
@@ -315,7 +412,7 @@ func (a *Agent) ProcessStats(in pb.ClientStatsPayload, lang string) {
 				Distributions:    make(map[string]stats.Distribution),
 				ErrDistributions: make(map[string]stats.Distribution),
 			}
-			aggr := stats.NewAggregation(out.Env, b.Resource, b.Service, "", statusCode, in.Version, b.Synthetics)
+			aggr := stats.NewAggregation(out.Env, b.Resource, b.Service, "", statusCode, in.Version, b.Synthetics, b.PeerService, b.HTTPMethod, b.Tags)
 			tagset := aggr.ToTagSet()
 			key := stats.GrainKey(b.Name, stats.HITS, aggr)
 			newb.Counts[key] = stats.Count{
@@ -363,12 +460,18 @@ func (a *Agent) ProcessStats(in pb.ClientStatsPayload, lang string) {
 		}
 	}
 
+	// the cardinality cap of the client stats applies per flushed payload
+	a.clientDimsMu.Lock()
+	if a.clientDims != nil {
+		a.clientDims.Reset()
+	}
+	a.clientDimsMu.Unlock()
 	a.StatsWriter.SendPayload(&out)
 }
 
 // sample decides whether the trace will be kept and extracts any APM events
-// from it.
-func (a *Agent) sample(ts *info.TagStats, pt ProcessedTrace) (events []*pb.Span, keep bool) {
+// from it. It also returns the reason for the sampling decision.
+func (a *Agent) sample(ts *info.TagStats, pt ProcessedTrace) (events []*pb.Span, keep bool, reason string) {
 	priority, hasPriority := sampler.GetSamplingPriority(pt.Root)
 
 	// Depending on the sampling priority, count that trace differently.
@@ -387,22 +490,22 @@ func (a *Agent) sample(ts *info.TagStats, pt ProcessedTrace) (events []*pb.Span,
 	atomic.AddInt64(stat, 1)
 
 	if priority < 0 {
-		return nil, false
+		return nil, false, inspect.ReasonUserDrop
 	}
 
-	sampled := a.runSamplers(pt, hasPriority)
+	sampled, reason := a.runSamplers(pt, hasPriority)
 
 	events, numExtracted := a.EventProcessor.Process(pt.Root, pt.Trace)
 
 	atomic.AddInt64(&ts.EventsExtracted, int64(numExtracted))
 	atomic.AddInt64(&ts.EventsSampled, int64(len(events)))
 
-	return events, sampled
+	return events, sampled, reason
 }
 
 // runSamplers runs all the agent's samplers on pt and returns the sampling decision
-// along with the sampling rate.
-func (a *Agent) runSamplers(pt ProcessedTrace, hasPriority bool) bool {
+// along with the reason for it.
+func (a *Agent) runSamplers(pt ProcessedTrace, hasPriority bool) (bool, string) {
 	if hasPriority {
 		return a.samplePriorityTrace(pt)
 	}
@@ -412,23 +515,55 @@ func (a *Agent) runSamplers(pt ProcessedTrace, hasPriority bool) bool {
 // samplePriorityTrace samples traces with priority set on them. PrioritySampler and
 // ErrorSampler are run in parallel. The ExceptionSampler catches traces with rare top-level
 // or measured spans that are not caught by PrioritySampler and ErrorSampler.
-func (a *Agent) samplePriorityTrace(pt ProcessedTrace) bool {
+func (a *Agent) samplePriorityTrace(pt ProcessedTrace) (bool, string) {
 	if a.PrioritySampler.Sample(pt.Trace, pt.Root, pt.Env) {
-		return true
+		return true, inspect.ReasonPriority
 	}
 	if traceContainsError(pt.Trace) {
-		return a.ErrorsSampler.Sample(pt.Trace, pt.Root, pt.Env)
+		return a.ErrorsSampler.Sample(pt.Trace, pt.Root, pt.Env), inspect.ReasonErrors
 	}
-	return a.ExceptionSampler.Sample(pt.Trace, pt.Root, pt.Env)
+	return a.ExceptionSampler.Sample(pt.Trace, pt.Root, pt.Env), inspect.ReasonRare
 }
 
 // sampleNoPriorityTrace samples traces with no priority set on them. The traces
 // get sampled by either the score sampler or the error sampler if they have an error.
-func (a *Agent) sampleNoPriorityTrace(pt ProcessedTrace) bool {
+func (a *Agent) sampleNoPriorityTrace(pt ProcessedTrace) (bool, string) {
 	if traceContainsError(pt.Trace) {
-		return a.ErrorsSampler.Sample(pt.Trace, pt.Root, pt.Env)
+		return a.ErrorsSampler.Sample(pt.Trace, pt.Root, pt.Env), inspect.ReasonErrors
+	}
+	return a.NoPrioritySampler.Sample(pt.Trace, pt.Root, pt.Env), inspect.ReasonNoPriority
+}
+
+// inspectFiltered records the filtered trace t for inspection, when enabled.
+func (a *Agent) inspectFiltered(t pb.Trace, root *pb.Span, reason string) {
+	if a.Inspector == nil {
+		return
+	}
+	env := a.conf.DefaultEnv
+	if v := traceutil.GetEnv(t); v != "" {
+		env = v
+	}
+	it := inspect.NewTrace(t, root, env)
+	it.Sampling = inspect.Sampling{Reason: reason, Priority: samplingPriority(root)}
+	if !a.conf.InspectorRawValues {
+		// the trace was filtered before being obfuscated, only its copy is
+		for _, span := range it.Spans {
+			a.obfuscator.Obfuscate(span)
+			Truncate(span)
+		}
+	}
+	a.Inspector.Record(it)
+}
+
+// samplingPriority returns the sampling priority of the trace having the given root,
+// or nil if it has none.
+func samplingPriority(root *pb.Span) *int {
+	p, ok := sampler.GetSamplingPriority(root)
+	if !ok {
+		return nil
 	}
-	return a.NoPrioritySampler.Sample(pt.Trace, pt.Root, pt.Env)
+	v := int(p)
+	return &v
 }
 
 func traceContainsError(trace pb.Trace) bool {
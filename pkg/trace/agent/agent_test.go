@@ -16,6 +16,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -23,16 +24,21 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/trace/config"
 	"github.com/DataDog/datadog-agent/pkg/trace/event"
 	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/inspect"
 	"github.com/DataDog/datadog-agent/pkg/trace/obfuscate"
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
 	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+	"github.com/DataDog/datadog-agent/pkg/trace/stats"
 	"github.com/DataDog/datadog-agent/pkg/trace/test/testutil"
 	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
 	"github.com/DataDog/datadog-agent/pkg/trace/writer"
 	ddlog "github.com/DataDog/datadog-agent/pkg/util/log"
 
+	"github.com/DataDog/sketches-go/ddsketch"
 	"github.com/cihub/seelog"
+	"github.com/golang/protobuf/proto"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Test to make sure that the joined effort of the quantizer and truncator, in that order, produce the
@@ -364,6 +370,85 @@ func TestProcess(t *testing.T) {
 		// without missing a trace
 		assert.Equal(t, gotCount, len(traces))
 	})
+
+	inspectorTest := func(t *testing.T, raw bool) {
+		cfg := config.New()
+		cfg.Endpoints[0].APIKey = "test"
+		cfg.Ignore["resource"] = []string{"^INSERT.*"}
+		cfg.InspectorMaxTraces = 10
+		cfg.InspectorRawValues = raw
+		ctx, cancel := context.WithCancel(context.Background())
+		agnt := NewAgent(ctx, cfg)
+		defer cancel()
+
+		now := time.Now()
+		span := &pb.Span{
+			TraceID:  1,
+			SpanID:   2,
+			Service:  "web",
+			Resource: "SELECT name FROM people WHERE age = 42",
+			Type:     "sql",
+			Meta:     map[string]string{"long": strings.Repeat("x", traceutil.MaxMetaValLen+1)},
+			Metrics:  map[string]float64{sampler.KeySamplingPriority: -1},
+			Start:    now.Add(-time.Second).UnixNano(),
+			Duration: (500 * time.Millisecond).Nanoseconds(),
+		}
+		filtered := &pb.Span{
+			TraceID:  3,
+			SpanID:   4,
+			Service:  "web",
+			Resource: "INSERT INTO db VALUES (1, 2, 3)",
+			Type:     "sql",
+			Start:    now.Add(-time.Second).UnixNano(),
+			Duration: (500 * time.Millisecond).Nanoseconds(),
+		}
+		agnt.Process(&api.Payload{
+			Traces: pb.Traces{{span}, {filtered}},
+			Source: info.NewReceiverStats().GetTagStats(info.Tags{}),
+		})
+
+		assert := assert.New(t)
+		traces, seq := agnt.Inspector.Query(inspect.Query{})
+		assert.EqualValues(2, seq)
+		if !assert.Len(traces, 2) {
+			return
+		}
+		priority := -1
+		before := inspect.RedactedValue
+		if raw {
+			before = "SELECT name FROM people WHERE age = 42"
+		}
+		assert.Equal(uint64(1), traces[0].TraceID)
+		assert.Equal(inspect.Sampling{Reason: inspect.ReasonUserDrop, Priority: &priority}, traces[0].Sampling)
+		assert.Contains(traces[0].Obfuscation, inspect.Change{
+			SpanID: 2,
+			Field:  "resource",
+			Before: before,
+			After:  "SELECT name FROM people WHERE age = ?",
+		})
+		assert.Contains(traces[0].Obfuscation, inspect.Change{
+			SpanID: 2,
+			Field:  "meta[sql.query]",
+			After:  "SELECT name FROM people WHERE age = ?",
+		})
+		if assert.Len(traces[0].Truncation, 1) {
+			assert.Equal("meta[long]", traces[0].Truncation[0].Field)
+		}
+		assert.Equal(uint64(3), traces[1].TraceID)
+		assert.Equal(inspect.ReasonFilteredResource, traces[1].Sampling.Reason)
+		assert.False(traces[1].Sampling.Kept)
+		// the filtered trace isn't obfuscated by the pipeline
+		if raw {
+			assert.Equal("INSERT INTO db VALUES (1, 2, 3)", traces[1].Spans[0].Resource)
+		} else {
+			assert.NotContains(traces[1].Spans[0].Resource, "1, 2, 3")
+		}
+		// the recorded trace is a copy
+		assert.Equal("INSERT INTO db VALUES (1, 2, 3)", filtered.Resource)
+	}
+
+	t.Run("Inspector", func(t *testing.T) { inspectorTest(t, false) })
+	t.Run("InspectorRawValues", func(t *testing.T) { inspectorTest(t, true) })
 }
 
 func TestFilteredByTags(t *testing.T) {
@@ -550,6 +635,93 @@ func TestClientComputedStats(t *testing.T) {
 	})
 }
 
+func TestProcessStats(t *testing.T) {
+	cfg := config.New()
+	cfg.Endpoints[0].APIKey = "test"
+	ctx, cancel := context.WithCancel(context.Background())
+	agnt := NewAgent(ctx, cfg)
+	defer cancel()
+
+	agnt.ProcessStats(pb.ClientStatsPayload{
+		Hostname: "host",
+		Stats: []pb.ClientStatsBucket{{
+			Start:    uint64(time.Now().UnixNano()),
+			Duration: uint64(10 * time.Second),
+			Stats: []pb.ClientGroupedStats{{
+				Service:  "Web Service",
+				Name:     "sql.query",
+				Resource: "SELECT name FROM people WHERE age = 42",
+				Type:     "sql",
+				Hits:     1,
+			}},
+		}},
+	}, "go")
+
+	// client stats are aggregated before being sent
+	if !assert.Len(t, agnt.ClientStatsAggregator.In, 1) {
+		return
+	}
+	p := <-agnt.ClientStatsAggregator.In
+	assert.Equal(t, "none", p.Env)
+	b := p.Stats[0].Stats[0]
+	assert.Equal(t, "web_service", b.Service)
+	assert.Equal(t, "SELECT name FROM people WHERE age = ?", b.Resource)
+}
+
+func TestStopFlushesClientStats(t *testing.T) {
+	var received int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/api/v0.2/stats" {
+			atomic.AddInt32(&received, 1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	cfg := config.New()
+	cfg.Endpoints[0].APIKey = "test"
+	cfg.Endpoints[0].Host = srv.URL
+	cfg.ReceiverPort = 0 // any free port
+	ctx, cancel := context.WithCancel(context.Background())
+	agnt := NewAgent(ctx, cfg)
+	done := make(chan struct{})
+	go func() {
+		agnt.Run()
+		close(done)
+	}()
+
+	s, err := ddsketch.LogCollapsingLowestDenseDDSketch(0.01, 2048)
+	require.NoError(t, err)
+	require.NoError(t, s.Add(1))
+	sketch, err := proto.Marshal(s.ToProto())
+	require.NoError(t, err)
+
+	// the bucket is still open, so the payload stays in the aggregator until it is stopped
+	agnt.ProcessStats(pb.ClientStatsPayload{
+		Hostname: "host",
+		Stats: []pb.ClientStatsBucket{{
+			Start:    uint64(time.Now().UnixNano()),
+			Duration: uint64(10 * time.Second),
+			Stats: []pb.ClientGroupedStats{{
+				Service:      "web",
+				Name:         "http.request",
+				Resource:     "GET /",
+				Hits:         1,
+				OkSummary:    sketch,
+				ErrorSummary: sketch,
+			}},
+		}},
+	}, "go")
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("agent did not stop")
+	}
+	assert.EqualValues(t, 1, atomic.LoadInt32(&received))
+}
+
 func TestSampling(t *testing.T) {
 	for name, tt := range map[string]struct {
 		// hasErrors will be true if the input trace should have errors
@@ -653,7 +825,7 @@ func TestSampling(t *testing.T) {
 				}
 			}
 
-			sampled := a.runSamplers(pt, tt.hasPriority)
+			sampled, _ := a.runSamplers(pt, tt.hasPriority)
 			assert.EqualValues(t, tt.wantSampled, sampled)
 		})
 	}
@@ -1006,3 +1178,27 @@ func tracesFromFile(file string) (raw []byte, count int, err error) {
 	}
 	return data, count, nil
 }
+
+func TestProcessStatsDimensions(t *testing.T) {
+	assert := assert.New(t)
+	cfg := config.New()
+	cfg.Endpoints[0].APIKey = "test"
+	cfg.StatsAggregation.Tags = []string{"user"}
+	cfg.StatsAggregation.MaxCardinality = 1
+	agnt := NewAgent(context.Background(), cfg)
+
+	in := agnt.processStats(pb.ClientStatsPayload{
+		Stats: []pb.ClientStatsBucket{{
+			Stats: []pb.ClientGroupedStats{
+				{Name: "op", Service: "svc", PeerService: "db", Tags: []string{"user:u0", "request_id:1"}},
+				{Name: "op", Service: "svc", Tags: []string{"user:u1", "request_id:2"}},
+			},
+		}},
+	}, "go")
+
+	// the dimensions which aren't configured are dropped, and the cardinality is capped
+	groups := in.Stats[0].Stats
+	assert.Equal("", groups[0].PeerService)
+	assert.Equal([]string{"user:u0"}, groups[0].Tags)
+	assert.Equal([]string{"user:" + stats.OverflowValue}, groups[1].Tags)
+}
@@ -0,0 +1,57 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/writer"
+)
+
+// runReplay implements the "replay" command, which sends the payloads recorded using
+// apm_config.record_payloads_file to an intake.
+func runReplay(ctx context.Context, w io.Writer, args []string) error {
+	var (
+		opts writer.ReplayOptions
+		path string
+	)
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	fs.StringVar(&path, "file", "", "Recording to replay (required)")
+	fs.StringVar(&opts.URL, "url", "", "Base URL of the intake to send the payloads to, e.g. http://localhost:8080 (required)")
+	fs.StringVar(&opts.APIKey, "api-key", "", "API key to send along with the payloads")
+	fs.Float64Var(&opts.Speed, "speed", 1, "Replay speed factor; 1 keeps the recorded pace, 0 sends payloads as fast as possible")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: trace-agent replay -file <recording> -url <intake URL> [options]\n\n")
+		fmt.Fprintf(fs.Output(), "Sends the payloads recorded by a trace-agent with apm_config.record_payloads_file set.\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if path == "" || opts.URL == "" {
+		fs.Usage()
+		return errors.New("replay: both -file and -url are required")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	start := time.Now()
+	stats, err := writer.Replay(ctx, f, opts)
+	fmt.Fprintf(w, "Replayed %d payloads (%d bytes) in %s, %d failed.\n", stats.Sent, stats.Bytes, time.Since(start).Round(time.Millisecond), stats.Failed)
+	return err
+}
@@ -7,6 +7,7 @@ package agent
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"math/rand"
 	"os"
@@ -42,6 +43,14 @@ func Run(ctx context.Context) {
 		return
 	}
 
+	if args := flag.Args(); len(args) > 0 && args[0] == "replay" {
+		// replaying does not require a valid configuration
+		if err := runReplay(ctx, os.Stdout, args[1:]); err != nil {
+			osutil.Exitf("%v", err)
+		}
+		return
+	}
+
 	cfg, err := config.Load(flags.ConfigPath)
 	if err != nil {
 		if err == config.ErrMissingAPIKey {
@@ -71,6 +80,13 @@ func Run(ctx context.Context) {
 		return
 	}
 
+	if args := flag.Args(); len(args) > 0 && args[0] == "tail" {
+		if err := runTail(ctx, os.Stdout, cfg, args[1:]); err != nil {
+			osutil.Exitf("%v", err)
+		}
+		return
+	}
+
 	if err := coreconfig.SetupLogger(
 		coreconfig.LoggerName("TRACE"),
 		cfg.LogLevel,
@@ -0,0 +1,42 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package agent
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/inspect"
+)
+
+// runTail implements the "tail" command, which continuously prints the traces processed
+// by a running trace-agent, as recorded by its trace inspector.
+func runTail(ctx context.Context, w io.Writer, cfg *config.AgentConfig, args []string) error {
+	var opts inspect.TailOptions
+	fs := flag.NewFlagSet("tail", flag.ContinueOnError)
+	fs.StringVar(&opts.Query.Service, "service", "", "Only show traces containing spans of this service")
+	fs.Uint64Var(&opts.Query.TraceID, "trace-id", 0, "Only show the trace with this ID")
+	fs.IntVar(&opts.Query.Limit, "n", 10, "Number of already recorded traces to show when starting")
+	fs.DurationVar(&opts.Interval, "interval", time.Second, "Interval at which the trace-agent is polled")
+	fs.BoolVar(&opts.JSON, "json", false, "Print each trace as a JSON object")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: trace-agent [options] tail [tail options]\n\n")
+		fmt.Fprintf(fs.Output(), "Prints the traces processed by the running trace-agent. Requires apm_config.trace_inspector.enabled.\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	addr := fmt.Sprintf("%s:%d", cfg.ReceiverHost, cfg.ReceiverPort)
+	return inspect.Tail(ctx, w, addr, opts)
+}
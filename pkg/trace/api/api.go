@@ -35,6 +35,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 	"github.com/DataDog/datadog-agent/pkg/trace/config"
 	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/inspect"
 	"github.com/DataDog/datadog-agent/pkg/trace/logutil"
 	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
 	"github.com/DataDog/datadog-agent/pkg/trace/metrics/timing"
@@ -67,6 +68,9 @@ type HTTPReceiver struct {
 	Stats       *info.ReceiverStats
 	RateLimiter *rateLimiter
 
+	// Inspector, when set, serves the recently processed traces on the inspect.Path endpoint.
+	Inspector *inspect.Recorder
+
 	out            chan *Payload
 	conf           *config.AgentConfig
 	dynConf        *sampler.DynamicConfig
@@ -111,6 +115,9 @@ func (r *HTTPReceiver) buildMux() *http.ServeMux {
 		mux.Handle(e.Pattern, replyWithVersion(hash, e.Handler(r)))
 	}
 	mux.HandleFunc("/info", infoHandler)
+	if r.Inspector != nil {
+		mux.Handle(inspect.Path, r.Inspector)
+	}
 
 	return mux
 }
@@ -117,6 +117,26 @@ type WriterConfig struct {
 	FlushPeriodSeconds float64 `mapstructure:"flush_period_seconds"`
 }
 
+// DefaultStatsMaxCardinality is the default value of StatsAggregationConfig.MaxCardinality.
+const DefaultStatsMaxCardinality = 100
+
+// StatsAggregationConfig specifies the dimensions which stats are aggregated on, in
+// addition to the default ones (env, service, resource, status code, version, etc.).
+type StatsAggregationConfig struct {
+	// PeerService enables aggregating stats on the "peer.service" span tag.
+	PeerService bool `mapstructure:"peer_service"`
+
+	// HTTPMethod enables aggregating stats on the "http.method" span tag.
+	HTTPMethod bool `mapstructure:"http_method"`
+
+	// Tags specifies a small set of additional span tags to aggregate stats on.
+	Tags []string `mapstructure:"tags"`
+
+	// MaxCardinality specifies the maximum number of distinct values allowed for each of the
+	// dimensions above in a stats bucket. Any value above it is collapsed into a single one.
+	MaxCardinality int `mapstructure:"max_cardinality"`
+}
+
 func (c *AgentConfig) applyDatadogConfig() error {
 	if len(c.Endpoints) == 0 {
 		c.Endpoints = []*Endpoint{{}}
@@ -265,6 +285,12 @@ func (c *AgentConfig) applyDatadogConfig() error {
 		}
 	}
 
+	if k := "apm_config.stats_aggregation"; config.Datadog.IsSet(k) {
+		if err := config.Datadog.UnmarshalKey(k, c.StatsAggregation); err != nil {
+			log.Errorf("Error reading %q: %v", k, err)
+		}
+	}
+
 	if config.Datadog.IsSet("apm_config.filter_tags.require") {
 		tags := config.Datadog.GetStringSlice("apm_config.filter_tags.require")
 		for _, tag := range tags {
@@ -286,6 +312,14 @@ func (c *AgentConfig) applyDatadogConfig() error {
 		c.MaxMemory = config.Datadog.GetFloat64("apm_config.max_memory")
 	}
 
+	if config.Datadog.GetBool("apm_config.trace_inspector.enabled") {
+		c.InspectorMaxTraces = 100
+		if k := "apm_config.trace_inspector.max_traces"; config.Datadog.IsSet(k) {
+			c.InspectorMaxTraces = config.Datadog.GetInt(k)
+		}
+		c.InspectorRawValues = config.Datadog.GetBool("apm_config.trace_inspector.show_raw_values")
+	}
+
 	// undocumented writers
 	for key, cfg := range map[string]*WriterConfig{
 		"apm_config.trace_writer": c.TraceWriter,
@@ -298,6 +332,9 @@ func (c *AgentConfig) applyDatadogConfig() error {
 	if config.Datadog.IsSet("apm_config.connection_reset_interval") {
 		c.ConnectionResetInterval = getDuration(config.Datadog.GetInt("apm_config.connection_reset_interval"))
 	}
+	if config.Datadog.IsSet("apm_config.record_payloads_file") {
+		c.RecordPayloadsFile = config.Datadog.GetString("apm_config.record_payloads_file")
+	}
 	if config.Datadog.IsSet("apm_config.sync_flushing") {
 		c.SynchronousFlushing = config.Datadog.GetBool("apm_config.sync_flushing")
 	}
@@ -63,6 +63,7 @@ type AgentConfig struct {
 	// Concentrator
 	BucketInterval   time.Duration // the size of our pre-aggregation per bucket
 	ExtraAggregators []string
+	StatsAggregation *StatsAggregationConfig // additional dimensions to aggregate stats on
 
 	// Sampler configuration
 	ExtraSampleRate float64
@@ -82,6 +83,7 @@ type AgentConfig struct {
 	StatsWriter             *WriterConfig
 	TraceWriter             *WriterConfig
 	ConnectionResetInterval time.Duration // frequency at which outgoing connections are reset. 0 means no reset is performed
+	RecordPayloadsFile      string        // if not empty, outgoing payloads are appended to this file, to be replayed later
 
 	// internal telemetry
 	StatsdHost string
@@ -126,6 +128,13 @@ type AgentConfig struct {
 
 	// RejectTags specifies a list of tags which must be absent on the root span in order for a trace to be accepted.
 	RejectTags []*Tag
+
+	// InspectorMaxTraces specifies the number of recent traces kept for local inspection on
+	// the receiver's /debug/traces endpoint. Zero disables trace inspection.
+	InspectorMaxTraces int
+	// InspectorRawValues makes the trace inspector serve the values of the spans before
+	// obfuscation. They are redacted otherwise.
+	InspectorRawValues bool
 }
 
 // Tag represents a key/value pair.
@@ -140,7 +149,8 @@ func New() *AgentConfig {
 		DefaultEnv: "none",
 		Endpoints:  []*Endpoint{{Host: "https://trace.agent.datadoghq.com"}},
 
-		BucketInterval: time.Duration(10) * time.Second,
+		BucketInterval:   time.Duration(10) * time.Second,
+		StatsAggregation: &StatsAggregationConfig{MaxCardinality: DefaultStatsMaxCardinality},
 
 		ExtraSampleRate: 1.0,
 		TargetTPS:       10,
@@ -163,6 +163,8 @@ func TestFullYamlConfig(t *testing.T) {
 	assert.EqualValues(123.4, c.MaxMemory)
 	assert.Equal("0.0.0.0", c.ReceiverHost)
 	assert.True(c.LogThrottling)
+	assert.Equal(20, c.InspectorMaxTraces)
+	assert.True(c.InspectorRawValues)
 
 	noProxy := true
 	if _, ok := os.LookupEnv("NO_PROXY"); ok {
@@ -241,6 +243,7 @@ func TestUndocumentedYamlConfig(t *testing.T) {
 	assert.Equal(1000.0, c.MaxEPS)
 	assert.Equal(25, c.ReceiverPort)
 	assert.Equal(120*time.Second, c.ConnectionResetInterval)
+	assert.Equal("/tmp/payloads.rec", c.RecordPayloadsFile)
 	// watchdog
 	assert.Equal(0.07, c.MaxCPU)
 	assert.Equal(30e6, c.MaxMemory)
@@ -0,0 +1,237 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package inspect keeps track of the most recent traces processed by the agent, along
+// with the decisions which were taken about them, so that users can check locally what
+// the agent received from their instrumented services.
+package inspect
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+// Path is the path of the HTTP endpoint serving the recorded traces.
+const Path = "/debug/traces"
+
+// Sampling reasons, explaining the decision taken about a trace.
+const (
+	// ReasonFilteredResource is used when the trace was rejected by the ignore_resources setting.
+	ReasonFilteredResource = "filtered_resource"
+	// ReasonFilteredTags is used when the trace did not pass the filter_tags settings.
+	ReasonFilteredTags = "filtered_tags"
+	// ReasonUserDrop is used when the trace had a negative sampling priority.
+	ReasonUserDrop = "user_drop"
+	// ReasonPriority is used when the trace was sampled by the priority sampler.
+	ReasonPriority = "priority_sampler"
+	// ReasonErrors is used when the trace was sampled by the errors sampler.
+	ReasonErrors = "errors_sampler"
+	// ReasonRare is used when the trace was sampled by the exception sampler.
+	ReasonRare = "rare_sampler"
+	// ReasonNoPriority is used when the trace had no priority and was sampled by the score sampler.
+	ReasonNoPriority = "no_priority_sampler"
+)
+
+// Sampling describes the sampling decision taken about a trace.
+type Sampling struct {
+	// Kept reports whether the trace was kept and sent to Datadog.
+	Kept bool `json:"kept"`
+	// Reason explains which component took the decision.
+	Reason string `json:"reason"`
+	// Priority holds the sampling priority of the trace, if it had any.
+	Priority *int `json:"priority,omitempty"`
+	// Events holds the number of APM events extracted from the trace.
+	Events int `json:"events"`
+}
+
+// Change describes the modification of a span field.
+type Change struct {
+	SpanID uint64 `json:"span_id"`
+	// Field is the modified field, such as "resource" or "meta[sql.query]".
+	Field string `json:"field"`
+	// Before holds the value of the field before the change. It is empty when the field was added.
+	Before string `json:"before,omitempty"`
+	// After holds the value of the field after the change. It is empty when the field was removed.
+	After string `json:"after,omitempty"`
+}
+
+// Trace is a trace recorded by the Recorder.
+type Trace struct {
+	// Seq is a sequence number which increases with each recorded trace.
+	Seq      uint64    `json:"seq"`
+	Time     time.Time `json:"time"`
+	TraceID  uint64    `json:"trace_id"`
+	Env      string    `json:"env"`
+	Service  string    `json:"service"`
+	Name     string    `json:"name"`
+	Resource string    `json:"resource"`
+	Sampling Sampling  `json:"sampling"`
+	// Obfuscation lists the changes made to the spans by the obfuscator.
+	Obfuscation []Change `json:"obfuscation,omitempty"`
+	// Truncation lists the changes made to the spans when truncating them.
+	Truncation []Change `json:"truncation,omitempty"`
+	// Spans holds a copy of the spans, as they were after processing.
+	Spans []*pb.Span `json:"spans"`
+}
+
+// NewTrace returns a new Trace holding a copy of t. The root span, found in t, is used
+// to fill the trace summary.
+func NewTrace(t pb.Trace, root *pb.Span, env string) *Trace {
+	spans := make([]*pb.Span, len(t))
+	for i, s := range t {
+		spans[i] = copySpan(s)
+	}
+	return &Trace{
+		TraceID:  root.TraceID,
+		Env:      env,
+		Service:  root.Service,
+		Name:     root.Name,
+		Resource: root.Resource,
+		Spans:    spans,
+	}
+}
+
+// RedactedValue replaces the values of the fields before their obfuscation, unless the
+// raw values are served.
+const RedactedValue = "?redacted?"
+
+// RedactObfuscation replaces the values of the obfuscated fields before their obfuscation
+// with RedactedValue, so that they are not served.
+func (t *Trace) RedactObfuscation() {
+	for i := range t.Obfuscation {
+		if t.Obfuscation[i].Before != "" {
+			t.Obfuscation[i].Before = RedactedValue
+		}
+	}
+}
+
+// Query specifies which recorded traces to return.
+type Query struct {
+	// Service, when set, only matches traces having at least one span of this service.
+	Service string
+	// TraceID, when non-zero, only matches the trace with this ID.
+	TraceID uint64
+	// Since only matches traces recorded after the given sequence number.
+	Since uint64
+	// Limit, when positive, caps the number of returned traces to the most recent ones.
+	Limit int
+}
+
+func (q *Query) match(t *Trace) bool {
+	if t.Seq <= q.Since {
+		return false
+	}
+	if q.TraceID != 0 && t.TraceID != q.TraceID {
+		return false
+	}
+	if q.Service == "" {
+		return true
+	}
+	for _, s := range t.Spans {
+		if s.Service == q.Service {
+			return true
+		}
+	}
+	return false
+}
+
+// Response is the body of the responses sent by the HTTP endpoint.
+type Response struct {
+	// Seq is the sequence number of the last recorded trace. It can be used as the
+	// "since" parameter of the next request in order to only get new traces.
+	Seq uint64 `json:"seq"`
+	// Traces holds the matching traces, oldest first.
+	Traces []*Trace `json:"traces"`
+}
+
+// Recorder keeps the last recorded traces in a ring buffer. It is safe for concurrent use.
+type Recorder struct {
+	mu     sync.RWMutex
+	traces []*Trace // ring buffer
+	next   int      // position of the next recorded trace in traces
+	seq    uint64   // sequence number of the last recorded trace
+}
+
+// NewRecorder returns a new Recorder keeping the last size traces.
+func NewRecorder(size int) *Recorder {
+	if size <= 0 {
+		size = 1
+	}
+	return &Recorder{traces: make([]*Trace, size)}
+}
+
+// Record adds t to the recorder, replacing the oldest trace if the buffer is full.
+func (r *Recorder) Record(t *Trace) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	t.Seq = r.seq
+	if t.Time.IsZero() {
+		t.Time = time.Now()
+	}
+	r.traces[r.next] = t
+	r.next = (r.next + 1) % len(r.traces)
+}
+
+// Query returns the recorded traces matching q, oldest first, along with the sequence
+// number of the last recorded trace.
+func (r *Recorder) Query(q Query) ([]*Trace, uint64) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]*Trace, 0)
+	for i := 0; i < len(r.traces); i++ {
+		t := r.traces[(r.next+i)%len(r.traces)]
+		if t == nil || !q.match(t) {
+			continue
+		}
+		out = append(out, t)
+	}
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[len(out)-q.Limit:]
+	}
+	return out, r.seq
+}
+
+// ServeHTTP implements http.Handler. The recorded traces are returned as a JSON encoded
+// Response, filtered using the optional "service", "trace_id", "since" and "limit" query
+// string parameters.
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var (
+		q   Query
+		err error
+		v   = req.URL.Query()
+	)
+	q.Service = v.Get("service")
+	if s := v.Get("trace_id"); s != "" {
+		if q.TraceID, err = strconv.ParseUint(s, 10, 64); err != nil {
+			http.Error(w, "trace_id must be an unsigned integer", http.StatusBadRequest)
+			return
+		}
+	}
+	if s := v.Get("since"); s != "" {
+		if q.Since, err = strconv.ParseUint(s, 10, 64); err != nil {
+			http.Error(w, "since must be an unsigned integer", http.StatusBadRequest)
+			return
+		}
+	}
+	if s := v.Get("limit"); s != "" {
+		if q.Limit, err = strconv.Atoi(s); err != nil {
+			http.Error(w, "limit must be an integer", http.StatusBadRequest)
+			return
+		}
+	}
+	traces, seq := r.Query(q)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Response{Seq: seq, Traces: traces})
+}
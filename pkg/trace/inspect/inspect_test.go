@@ -0,0 +1,204 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package inspect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testTrace(traceID uint64, services ...string) *Trace {
+	t := make(pb.Trace, len(services))
+	for i, s := range services {
+		t[i] = &pb.Span{TraceID: traceID, SpanID: uint64(i + 1), Service: s, Name: "op", Resource: "res"}
+	}
+	return NewTrace(t, t[0], "none")
+}
+
+func traceIDs(traces []*Trace) []uint64 {
+	ids := make([]uint64, len(traces))
+	for i, t := range traces {
+		ids[i] = t.TraceID
+	}
+	return ids
+}
+
+func TestRecorder(t *testing.T) {
+	assert := assert.New(t)
+
+	r := NewRecorder(3)
+	traces, seq := r.Query(Query{})
+	assert.Empty(traces)
+	assert.EqualValues(0, seq)
+
+	r.Record(testTrace(1, "web"))
+	r.Record(testTrace(2, "web", "db"))
+	traces, seq = r.Query(Query{})
+	assert.Equal([]uint64{1, 2}, traceIDs(traces))
+	assert.EqualValues(2, seq)
+
+	// oldest traces are evicted
+	r.Record(testTrace(3, "worker"))
+	r.Record(testTrace(4, "worker", "db"))
+	r.Record(testTrace(5, "web"))
+	traces, seq = r.Query(Query{})
+	assert.Equal([]uint64{3, 4, 5}, traceIDs(traces))
+	assert.EqualValues(5, seq)
+
+	for name, tt := range map[string]struct {
+		q   Query
+		out []uint64
+	}{
+		"service":       {Query{Service: "db"}, []uint64{4}},
+		"trace-id":      {Query{TraceID: 3}, []uint64{3}},
+		"since":         {Query{Since: 3}, []uint64{4, 5}},
+		"limit":         {Query{Limit: 2}, []uint64{4, 5}},
+		"service-limit": {Query{Service: "worker", Limit: 1}, []uint64{4}},
+		"none":          {Query{Service: "unknown"}, []uint64{}},
+	} {
+		traces, _ := r.Query(tt.q)
+		assert.Equal(tt.out, traceIDs(traces), name)
+	}
+}
+
+func TestNewTraceCopies(t *testing.T) {
+	span := &pb.Span{TraceID: 1, Service: "web", Meta: map[string]string{"a": "b"}, Metrics: map[string]float64{"c": 1}}
+	tr := NewTrace(pb.Trace{span}, span, "prod")
+	span.Meta["a"] = "modified"
+	span.Metrics["c"] = 2
+	assert.Equal(t, "b", tr.Spans[0].Meta["a"])
+	assert.Equal(t, 1., tr.Spans[0].Metrics["c"])
+	assert.Equal(t, "web", tr.Service)
+	assert.Equal(t, "prod", tr.Env)
+}
+
+func TestSnapshotDiff(t *testing.T) {
+	span := &pb.Span{
+		SpanID:   7,
+		Resource: "SELECT 1",
+		Meta:     map[string]string{"kept": "v", "changed": "old", "removed": "v"},
+		Metrics:  map[string]float64{"m": 1, "gone": 2},
+	}
+	snap := TakeSnapshot(span)
+	assert.Empty(t, snap.Diff(span))
+
+	span.Resource = "SELECT ?"
+	span.Meta["changed"] = "new"
+	span.Meta["added"] = "v"
+	delete(span.Meta, "removed")
+	span.Metrics["m"] = 1.5
+	delete(span.Metrics, "gone")
+	assert.Equal(t, []Change{
+		{SpanID: 7, Field: "meta[added]", After: "v"},
+		{SpanID: 7, Field: "meta[changed]", Before: "old", After: "new"},
+		{SpanID: 7, Field: "meta[removed]", Before: "v"},
+		{SpanID: 7, Field: "metrics[gone]", Before: "2"},
+		{SpanID: 7, Field: "metrics[m]", Before: "1", After: "1.5"},
+		{SpanID: 7, Field: "resource", Before: "SELECT 1", After: "SELECT ?"},
+	}, snap.Diff(span))
+}
+
+func TestServeHTTP(t *testing.T) {
+	r := NewRecorder(10)
+	r.Record(testTrace(1, "web"))
+	r.Record(testTrace(2, "db"))
+
+	for name, tt := range map[string]struct {
+		query  string
+		status int
+		out    []uint64
+	}{
+		"all":      {"", http.StatusOK, []uint64{1, 2}},
+		"service":  {"?service=db", http.StatusOK, []uint64{2}},
+		"trace-id": {"?trace_id=1", http.StatusOK, []uint64{1}},
+		"since":    {"?since=1&limit=5", http.StatusOK, []uint64{2}},
+		"invalid":  {"?trace_id=abc", http.StatusBadRequest, nil},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest("GET", Path+tt.query, nil))
+			assert.Equal(t, tt.status, rec.Code)
+			if tt.status != http.StatusOK {
+				return
+			}
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			var resp Response
+			assert.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			assert.EqualValues(t, 2, resp.Seq)
+			assert.Equal(t, tt.out, traceIDs(resp.Traces))
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("POST", Path, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestTail(t *testing.T) {
+	r := NewRecorder(10)
+	tr := testTrace(1, "web")
+	tr.Sampling = Sampling{Kept: true, Reason: ReasonPriority}
+	tr.Obfuscation = []Change{{SpanID: 1, Field: "resource", Before: "SELECT 1", After: "SELECT ?"}}
+	r.Record(tr)
+	r.Record(testTrace(2, "db"))
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+	addr := strings.TrimPrefix(srv.URL, "http://")
+
+	tail := func(opts TailOptions) (string, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		opts.Interval = 10 * time.Millisecond
+		var buf bytes.Buffer
+		err := Tail(ctx, &buf, addr, opts)
+		return buf.String(), err
+	}
+
+	out, err := tail(TailOptions{Query: Query{Service: "web"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(out, "trace_id=1"), "traces should only be printed once")
+	assert.Contains(t, out, `trace_id=1 env="none" service="web"`)
+	assert.Contains(t, out, "kept reason=priority_sampler priority=none")
+	assert.Contains(t, out, `obfuscated span 1 resource: "SELECT 1" -> "SELECT ?"`)
+	assert.NotContains(t, out, "trace_id=2")
+
+	out, err = tail(TailOptions{JSON: true, Query: Query{TraceID: 2}})
+	assert.NoError(t, err)
+	var got Trace
+	assert.NoError(t, json.Unmarshal([]byte(out), &got))
+	assert.EqualValues(t, 2, got.TraceID)
+	assert.EqualValues(t, 2, got.Seq)
+}
+
+func TestTailNotEnabled(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	err := Tail(context.Background(), &bytes.Buffer{}, strings.TrimPrefix(srv.URL, "http://"), TailOptions{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not enabled")
+}
+
+func TestRedactObfuscation(t *testing.T) {
+	tr := &Trace{Obfuscation: []Change{
+		{SpanID: 1, Field: "resource", Before: "SELECT 1", After: "SELECT ?"},
+		{SpanID: 1, Field: "meta[sql.query]", After: "SELECT ?"},
+	}}
+	tr.RedactObfuscation()
+	assert.Equal(t, []Change{
+		{SpanID: 1, Field: "resource", Before: RedactedValue, After: "SELECT ?"},
+		{SpanID: 1, Field: "meta[sql.query]", After: "SELECT ?"},
+	}, tr.Obfuscation)
+}
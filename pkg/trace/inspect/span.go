@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package inspect
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+// Snapshot holds the state of the span fields which may be modified by the agent
+// when obfuscating or truncating spans.
+type Snapshot struct {
+	spanID   uint64
+	resource string
+	meta     map[string]string
+	metrics  map[string]float64
+}
+
+// TakeSnapshot returns a snapshot of the current state of s.
+func TakeSnapshot(s *pb.Span) Snapshot {
+	ss := Snapshot{
+		spanID:   s.SpanID,
+		resource: s.Resource,
+		meta:     make(map[string]string, len(s.Meta)),
+		metrics:  make(map[string]float64, len(s.Metrics)),
+	}
+	for k, v := range s.Meta {
+		ss.meta[k] = v
+	}
+	for k, v := range s.Metrics {
+		ss.metrics[k] = v
+	}
+	return ss
+}
+
+// Diff returns the changes made to s since the snapshot was taken, sorted by field.
+func (ss Snapshot) Diff(s *pb.Span) []Change {
+	var changes []Change
+	add := func(field, before, after string) {
+		changes = append(changes, Change{SpanID: ss.spanID, Field: field, Before: before, After: after})
+	}
+	if s.Resource != ss.resource {
+		add("resource", ss.resource, s.Resource)
+	}
+	for k, before := range ss.meta {
+		if after, ok := s.Meta[k]; !ok || after != before {
+			add("meta["+k+"]", before, after)
+		}
+	}
+	for k, after := range s.Meta {
+		if _, ok := ss.meta[k]; !ok {
+			add("meta["+k+"]", "", after)
+		}
+	}
+	for k, before := range ss.metrics {
+		if after, ok := s.Metrics[k]; !ok {
+			add("metrics["+k+"]", formatFloat(before), "")
+		} else if after != before {
+			add("metrics["+k+"]", formatFloat(before), formatFloat(after))
+		}
+	}
+	for k, after := range s.Metrics {
+		if _, ok := ss.metrics[k]; !ok {
+			add("metrics["+k+"]", "", formatFloat(after))
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// copySpan returns a deep copy of s.
+func copySpan(s *pb.Span) *pb.Span {
+	cp := *s
+	if s.Meta != nil {
+		cp.Meta = make(map[string]string, len(s.Meta))
+		for k, v := range s.Meta {
+			cp.Meta[k] = v
+		}
+	}
+	if s.Metrics != nil {
+		cp.Metrics = make(map[string]float64, len(s.Metrics))
+		for k, v := range s.Metrics {
+			cp.Metrics[k] = v
+		}
+	}
+	return &cp
+}
@@ -0,0 +1,134 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package inspect
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// TailOptions specifies how traces are tailed.
+type TailOptions struct {
+	// Query filters the traces to display. Query.Limit is only applied to the first
+	// request, in order to display the last traces recorded before tailing started.
+	Query Query
+	// Interval is the time to wait between two requests to the agent.
+	Interval time.Duration
+	// JSON prints each trace as a JSON object on a single line.
+	JSON bool
+}
+
+// Tail polls the inspection endpoint of the agent at addr (host:port) and writes the new
+// traces matching opts.Query to w, until ctx is done or an error occurs.
+func Tail(ctx context.Context, w io.Writer, addr string, opts TailOptions) error {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	client := http.Client{Timeout: 3 * time.Second}
+	q := opts.Query
+	for {
+		resp, err := fetch(ctx, &client, addr, q)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if resp.Seq < q.Since {
+			// the agent was restarted, start over
+			q.Since = 0
+			continue
+		}
+		for _, t := range resp.Traces {
+			if err := writeTrace(w, t, opts.JSON); err != nil {
+				return err
+			}
+		}
+		q.Since = resp.Seq
+		q.Limit = 0
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(opts.Interval):
+		}
+	}
+}
+
+func fetch(ctx context.Context, client *http.Client, addr string, q Query) (*Response, error) {
+	v := url.Values{}
+	if q.Service != "" {
+		v.Set("service", q.Service)
+	}
+	if q.TraceID != 0 {
+		v.Set("trace_id", strconv.FormatUint(q.TraceID, 10))
+	}
+	if q.Since != 0 {
+		v.Set("since", strconv.FormatUint(q.Since, 10))
+	}
+	if q.Limit > 0 {
+		v.Set("limit", strconv.Itoa(q.Limit))
+	}
+	u := fmt.Sprintf("http://%s%s?%s", addr, Path, v.Encode())
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("error querying trace-agent at %s: %v", u, err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("trace inspection is not enabled on the trace-agent (apm_config.trace_inspector.enabled)")
+	default:
+		return nil, fmt.Errorf("unexpected response from trace-agent at %s: %s", u, resp.Status)
+	}
+	var r Response
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, fmt.Errorf("error decoding trace-agent response: %v", err)
+	}
+	return &r, nil
+}
+
+// writeTrace writes a description of t to w.
+func writeTrace(w io.Writer, t *Trace, asJSON bool) error {
+	if asJSON {
+		return json.NewEncoder(w).Encode(t)
+	}
+	decision := "dropped"
+	if t.Sampling.Kept {
+		decision = "kept"
+	}
+	priority := "none"
+	if t.Sampling.Priority != nil {
+		priority = strconv.Itoa(*t.Sampling.Priority)
+	}
+	_, err := fmt.Fprintf(w, "%s trace_id=%d env=%q service=%q name=%q resource=%q spans=%d %s reason=%s priority=%s events=%d\n",
+		t.Time.Format(time.RFC3339), t.TraceID, t.Env, t.Service, t.Name, t.Resource, len(t.Spans),
+		decision, t.Sampling.Reason, priority, t.Sampling.Events)
+	if err != nil {
+		return err
+	}
+	for _, c := range t.Obfuscation {
+		if _, err := fmt.Fprintf(w, "  obfuscated span %d %s: %q -> %q\n", c.SpanID, c.Field, c.Before, c.After); err != nil {
+			return err
+		}
+	}
+	for _, c := range t.Truncation {
+		if _, err := fmt.Fprintf(w, "  truncated span %d %s: %q -> %q\n", c.SpanID, c.Field, c.Before, c.After); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -22,6 +22,10 @@ import (
 const sqlQueryTag = "sql.query"
 const nonParsableResource = "Non-parsable SQL query"
 
+// dbTypeTag is set by tracers to the type of the database engine, e.g. "postgresql".
+// It is used to choose the SQL dialect when tokenizing queries.
+const dbTypeTag = "db.type"
+
 var questionMark = []byte("?")
 
 // tokenFilter is a generic interface that a sqlObfuscator expects. It defines
@@ -203,25 +207,43 @@ func (f *groupingFilter) Reset() {
 // some elements such as comments and aliases and obfuscation attempts to hide sensitive information
 // in strings and numbers by redacting them.
 func (o *Obfuscator) ObfuscateSQLString(in string) (*ObfuscatedQuery, error) {
-	if v, ok := o.queryCache.Get(in); ok {
+	return o.ObfuscateSQLStringForDialect(in, DialectGeneric)
+}
+
+// ObfuscateSQLStringForDialect behaves like ObfuscateSQLString, but tokenizes the query using the
+// syntax of the given SQL dialect. When the dialect is known, the names of the tables referenced by
+// the query are also extracted into the result.
+func (o *Obfuscator) ObfuscateSQLStringForDialect(in string, dialect SQLDialect) (*ObfuscatedQuery, error) {
+	key := queryCacheKey(in, dialect)
+	if v, ok := o.queryCache.Get(key); ok {
 		return v.(*ObfuscatedQuery), nil
 	}
-	oq, err := o.obfuscateSQLString(in)
+	oq, err := o.obfuscateSQLString(in, dialect)
 	if err != nil {
 		return oq, err
 	}
-	o.queryCache.Set(in, oq, oq.Cost())
+	o.queryCache.Set(key, oq, oq.Cost())
 	return oq, nil
 }
 
-func (o *Obfuscator) obfuscateSQLString(in string) (*ObfuscatedQuery, error) {
+// queryCacheKey returns the key under which the obfuscation result of the query in, tokenized
+// using the given dialect, is cached.
+func queryCacheKey(in string, dialect SQLDialect) string {
+	if dialect == DialectGeneric {
+		return in
+	}
+	// queries don't start with NUL bytes, so keys can't collide across dialects
+	return "\x00" + dialect.String() + "\x00" + in
+}
+
+func (o *Obfuscator) obfuscateSQLString(in string, dialect SQLDialect) (*ObfuscatedQuery, error) {
 	lesc := o.SQLLiteralEscapes()
-	tok := NewSQLTokenizer(in, lesc)
+	tok := NewSQLTokenizerWithDialect(in, lesc, dialect)
 	out, err := attemptObfuscation(tok)
 	if err != nil && tok.SeenEscape() {
 		// If the tokenizer failed, but saw an escape character in the process,
 		// try again treating escapes differently
-		tok = NewSQLTokenizer(in, !lesc)
+		tok = NewSQLTokenizerWithDialect(in, !lesc, dialect)
 		if out, err2 := attemptObfuscation(tok); err2 == nil {
 			// If the second attempt succeeded, change the default behavior so that
 			// on the next run we get it right in the first run.
@@ -309,7 +331,8 @@ func (oq *ObfuscatedQuery) Cost() int64 {
 func attemptObfuscation(tokenizer *SQLTokenizer) (*ObfuscatedQuery, error) {
 
 	var (
-		storeTableNames    = config.HasFeature("table_names")
+		dialect            = tokenizer.Dialect()
+		storeTableNames    = config.HasFeature("table_names") || dialect != DialectGeneric
 		quantizeTableNames = config.HasFeature("quantize_sql_tables")
 		out                = bytes.NewBuffer(make([]byte, 0, len(tokenizer.buf)))
 		err                error
@@ -349,6 +372,12 @@ func attemptObfuscation(tokenizer *SQLTokenizer) (*ObfuscatedQuery, error) {
 			if out.Len() != 0 {
 				switch token {
 				case ',':
+				case ColonCast:
+					if dialect == DialectPostgres {
+						// write Postgres casts compactly, as in "?::text"
+						break
+					}
+					out.WriteRune(' ')
 				case '=':
 					if lastToken == ':' {
 						// do not add a space before an equals if a colon was
@@ -357,6 +386,9 @@ func attemptObfuscation(tokenizer *SQLTokenizer) (*ObfuscatedQuery, error) {
 					}
 					fallthrough
 				default:
+					if lastToken == ColonCast && dialect == DialectPostgres {
+						break
+					}
 					out.WriteRune(' ')
 				}
 			}
@@ -377,7 +409,7 @@ func (o *Obfuscator) obfuscateSQL(span *pb.Span) {
 	if span.Resource == "" {
 		return
 	}
-	oq, err := o.ObfuscateSQLString(span.Resource)
+	oq, err := o.ObfuscateSQLStringForDialect(span.Resource, DialectFromDBType(span.Meta[dbTypeTag]))
 	if err != nil {
 		// we have an error, discard the SQL to avoid polluting user resources.
 		log.Debugf("Error parsing SQL query: %v. Resource: %q", err, span.Resource)
@@ -7,9 +7,11 @@ package obfuscate
 
 import (
 	"bytes"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"sync/atomic"
 	"testing"
@@ -313,6 +315,102 @@ func TestSQLTableFinderAndQuantizeTableNames(t *testing.T) {
 	})
 }
 
+// sqlDialectTestFiles contains the test corpus for each of the supported SQL dialects.
+var sqlDialectTestFiles = []string{
+	"./testdata/sql_postgres.xml",
+	"./testdata/sql_mysql.xml",
+	"./testdata/sql_mssql.xml",
+}
+
+type xmlSQLDialectTests struct {
+	XMLName xml.Name             `xml:"SQLDialectTests,-"`
+	DBType  string               // value of the "db.type" span tag
+	Tests   []*xmlSQLDialectTest `xml:"TestSuite>Test"`
+}
+
+type xmlSQLDialectTest struct {
+	Tag    string
+	In     string
+	Out    string
+	Tables string
+}
+
+// loadSQLDialectTests loads the XML tests from the given file.
+func loadSQLDialectTests(file string) (*xmlSQLDialectTests, error) {
+	path, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var suite xmlSQLDialectTests
+	if err := xml.NewDecoder(f).Decode(&suite); err != nil {
+		return nil, err
+	}
+	return &suite, nil
+}
+
+func TestSQLDialects(t *testing.T) {
+	for _, file := range sqlDialectTestFiles {
+		suite, err := loadSQLDialectTests(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dialect := DialectFromDBType(suite.DBType)
+		assert.NotEqual(t, DialectGeneric, dialect, suite.DBType)
+		for _, tt := range suite.Tests {
+			t.Run(tt.Tag, func(t *testing.T) {
+				span := &pb.Span{
+					Resource: tt.In,
+					Type:     "sql",
+					Meta:     map[string]string{"db.type": suite.DBType},
+				}
+				NewObfuscator(nil).Obfuscate(span)
+				assert.Equal(t, tt.Out, span.Resource)
+				assert.Equal(t, tt.Tables, span.Meta["sql.tables"])
+			})
+		}
+	}
+}
+
+func TestSQLDialectFromDBType(t *testing.T) {
+	for in, want := range map[string]SQLDialect{
+		"postgresql": DialectPostgres,
+		"Postgres":   DialectPostgres,
+		"mysql":      DialectMySQL,
+		"mariadb":    DialectMySQL,
+		"mssql":      DialectMSSQL,
+		"sqlserver":  DialectMSSQL,
+		"sqlite":     DialectGeneric,
+		"":           DialectGeneric,
+	} {
+		assert.Equal(t, want, DialectFromDBType(in), in)
+	}
+}
+
+func TestSQLDialectCache(t *testing.T) {
+	assert := assert.New(t)
+	o := NewObfuscator(nil)
+	defer o.Stop()
+
+	// the same query is tokenized differently depending on the dialect
+	query := `SELECT "secret" FROM users`
+	oq, err := o.ObfuscateSQLStringForDialect(query, DialectMySQL)
+	assert.NoError(err)
+	assert.Equal("SELECT ? FROM users", oq.Query)
+	oq, err = o.ObfuscateSQLStringForDialect(query, DialectPostgres)
+	assert.NoError(err)
+	assert.Equal("SELECT secret FROM users", oq.Query)
+	assert.Equal("users", oq.TablesCSV)
+	oq, err = o.ObfuscateSQLString(query)
+	assert.NoError(err)
+	assert.Equal("SELECT secret FROM users", oq.Query)
+	assert.Empty(oq.TablesCSV)
+}
+
 func TestSQLQuantizer(t *testing.T) {
 	cases := []sqlTestCase{
 		{
@@ -979,6 +1077,40 @@ in the middle'`,
 	}
 }
 
+func TestSQLTokenizerDialects(t *testing.T) {
+	for _, tt := range []struct {
+		dialect      SQLDialect
+		str          string
+		expected     string
+		expectedKind TokenKind
+	}{
+		{DialectPostgres, `$$dollar 'quoted'$$`, "dollar 'quoted'", String},
+		{DialectPostgres, `$tag$with $$ inside$tag$`, "with $$ inside", String},
+		{DialectPostgres, `$1`, "$1", PreparedStatement},
+		{DialectPostgres, `$tag$unterminated`, "unterminated", LexError},
+		{DialectPostgres, `E'escaped \' quote'`, "escaped ' quote", String},
+		{DialectPostgres, `"quoted ""identifier"""`, `quoted "identifier"`, ID},
+		{DialectPostgres, `"public"."users"`, "public.users", ID},
+		{DialectPostgres, `public."users".*`, "public.users.*", ID},
+		{DialectPostgres, `""`, `""`, ID},
+		{DialectMySQL, "`my``table`", "my`table", ID},
+		{DialectMySQL, "`db`.`my table`", "db.my table", ID},
+		{DialectMySQL, `"string"`, "string", String},
+		{DialectMSSQL, `[my table]`, "my table", ID},
+		{DialectMSSQL, `[a]]b]`, "a]b", ID},
+		{DialectMSSQL, `[dbo].[users]`, "dbo.users", ID},
+		{DialectMSSQL, `[unterminated`, "unterminated", LexError},
+		{DialectMSSQL, `N'unicode'`, "unicode", String},
+	} {
+		t.Run(fmt.Sprintf("%s_%s", tt.dialect, tt.str), func(t *testing.T) {
+			tokenizer := NewSQLTokenizerWithDialect(tt.str, false, tt.dialect)
+			kind, buffer := tokenizer.Scan()
+			assert.Equal(t, tt.expectedKind, kind)
+			assert.Equal(t, tt.expected, string(buffer))
+		})
+	}
+}
+
 func TestMultipleProcess(t *testing.T) {
 	assert := assert.New(t)
 
@@ -1328,7 +1460,10 @@ func BenchmarkQueryCacheTippingPoint(b *testing.B) {
 		"xlong":       "select top ? percent IdTrebEmpresa, CodCli, NOMEMP, Baixa, CASE WHEN IdCentreTreball IS ? THEN ? ELSE CONVERT ( VARCHAR ( ? ) IdCentreTreball ) END, CASE WHEN NOMESTAB IS ? THEN ? ELSE NOMESTAB END, TIPUS, CASE WHEN IdLloc IS ? THEN ? ELSE CONVERT ( VARCHAR ( ? ) IdLloc ) END, CASE WHEN NomLlocComplert IS ? THEN ? ELSE NomLlocComplert END, CASE WHEN DesLloc IS ? THEN ? ELSE DesLloc END, IdLlocTreballUnic From ( SELECT ?, dbo.Treb_Empresa.IdTrebEmpresa, dbo.Treb_Empresa.IdTreballador, dbo.Treb_Empresa.CodCli, dbo.Clients.NOMEMP, dbo.Treb_Empresa.Baixa, dbo.Treb_Empresa.IdCentreTreball, dbo.Cli_Establiments.NOMESTAB, ?, ?, dbo.Treb_Empresa.DataInici, dbo.Treb_Empresa.DataFi, CASE WHEN dbo.Treb_Empresa.DesLloc IS ? THEN ? ELSE dbo.Treb_Empresa.DesLloc END DesLloc, dbo.Treb_Empresa.IdLlocTreballUnic FROM dbo.Clients WITH ( NOLOCK ) INNER JOIN dbo.Treb_Empresa WITH ( NOLOCK ) ON dbo.Clients.CODCLI = dbo.Treb_Empresa.CodCli LEFT OUTER JOIN dbo.Cli_Establiments WITH ( NOLOCK ) ON dbo.Cli_Establiments.Id_ESTAB_CLI = dbo.Treb_Empresa.IdCentreTreball AND dbo.Cli_Establiments.CODCLI = dbo.Treb_Empresa.CodCli WHERE dbo.Treb_Empresa.IdTreballador = ? AND Treb_Empresa.IdTecEIRLLlocTreball IS ? AND IdMedEIRLLlocTreball IS ? AND IdLlocTreballTemporal IS ? UNION ALL SELECT ?, dbo.Treb_Empresa.IdTrebEmpresa, dbo.Treb_Empresa.IdTreballador, dbo.Treb_Empresa.CodCli, dbo.Clients.NOMEMP, dbo.Treb_Empresa.Baixa, dbo.Treb_Empresa.IdCentreTreball, dbo.Cli_Establiments.NOMESTAB, dbo.Treb_Empresa.IdTecEIRLLlocTreball, dbo.fn_NomLlocComposat ( dbo.Treb_Empresa.IdTecEIRLLlocTreball ), dbo.Treb_Empresa.DataInici, dbo.Treb_Empresa.DataFi, CASE WHEN dbo.Treb_Empresa.DesLloc IS ? THEN ? ELSE dbo.Treb_Empresa.DesLloc END DesLloc, dbo.Treb_Empresa.IdLlocTreballUnic FROM dbo.Clients WITH ( NOLOCK ) INNER JOIN dbo.Treb_Empresa WITH ( NOLOCK ) ON dbo.Clients.CODCLI = dbo.Treb_Empresa.CodCli LEFT OUTER JOIN dbo.Cli_Establiments WITH ( NOLOCK ) ON dbo.Cli_Establiments.Id_ESTAB_CLI = dbo.Treb_Empresa.IdCentreTreball AND dbo.Cli_Establiments.CODCLI = dbo.Treb_Empresa.CodCli WHERE ( dbo.Treb_Empresa.IdTreballador = ? ) AND ( NOT ( dbo.Treb_Empresa.IdTecEIRLLlocTreball IS ? ) ) UNION ALL SELECT ?, dbo.Treb_Empresa.IdTrebEmpresa, dbo.Treb_Empresa.IdTreballador, dbo.Treb_Empresa.CodCli, dbo.Clients.NOMEMP, dbo.Treb_Empresa.Baixa, dbo.Treb_Empresa.IdCentreTreball, dbo.Cli_Establiments.NOMESTAB, dbo.Treb_Empresa.IdMedEIRLLlocTreball, dbo.fn_NomMedEIRLLlocComposat ( dbo.Treb_Empresa.IdMedEIRLLlocTreball ), dbo.Treb_Empresa.DataInici, dbo.Treb_Empresa.DataFi, CASE WHEN dbo.Treb_Empresa.DesLloc IS ? THEN ? ELSE dbo.Treb_Empresa.DesLloc END DesLloc, dbo.Treb_Empresa.IdLlocTreballUnic FROM dbo.Clients WITH ( NOLOCK ) INNER JOIN dbo.Treb_Empresa WITH ( NOLOCK ) ON dbo.Clients.CODCLI = dbo.Treb_Empresa.CodCli LEFT OUTER JOIN dbo.Cli_Establiments WITH ( NOLOCK ) ON dbo.Cli_Establiments.Id_ESTAB_CLI = dbo.Treb_Empresa.IdCentreTreball AND dbo.Cli_Establiments.CODCLI = dbo.Treb_Empresa.CodCli WHERE ( dbo.Treb_Empresa.IdTreballador = ? ) AND ( Treb_Empresa.IdTecEIRLLlocTreball IS ? ) AND ( NOT ( dbo.Treb_Empresa.IdMedEIRLLlocTreball IS ? ) ) UNION ALL SELECT ?, dbo.Treb_Empresa.IdTrebEmpresa, dbo.Treb_Empresa.IdTreballador, dbo.Treb_Empresa.CodCli, dbo.Clients.NOMEMP, dbo.Treb_Empresa.Baixa, dbo.Treb_Empresa.IdCentreTreball, dbo.Cli_Establiments.NOMESTAB, dbo.Treb_Empresa.IdLlocTreballTemporal, dbo.Lloc_Treball_Temporal.NomLlocTreball, dbo.Treb_Empresa.DataInici, dbo.Treb_Empresa.DataFi, CASE WHEN dbo.Treb_Empresa.DesLloc IS ? THEN ? ELSE dbo.Treb_Empresa.DesLloc END DesLloc, dbo.Treb_Empresa.IdLlocTreballUnic FROM dbo.Clients WITH ( NOLOCK ) INNER JOIN dbo.Treb_Empresa WITH ( NOLOCK ) ON dbo.Clients.CODCLI = dbo.Treb_Empresa.CodCli INNER JOIN dbo.Lloc_Treball_Temporal WITH ( NOLOCK ) ON dbo.Treb_Empresa.IdLlocTreballTemporal = dbo.Lloc_Treball_Temporal.IdLlocTreballTemporal LEFT OUTER JOIN dbo.Cli_Establiments WITH ( NOLOCK ) ON dbo.Cli_Establiments.Id_ESTAB_CLI = dbo.Treb_Empresa.IdCentreTreball AND dbo.Cli_Establiments.CODCLI = dbo.Treb_Empresa.CodCli WHERE dbo.Treb_Empresa.IdTreballador = ? AND Treb_Empresa.IdTecEIRLLlocTreball IS ? AND IdMedEIRLLlocTreball IS ? ) Where ? = %d",
 	} {
 		b.Run(fmt.Sprintf("%s-%d", name, len(queryfmt)), func(b *testing.B) {
-			b.Run("off", bench1KQueries((*Obfuscator).obfuscateSQLString, 1, queryfmt))
+			noCache := func(o *Obfuscator, in string) (*ObfuscatedQuery, error) {
+				return o.obfuscateSQLString(in, DialectGeneric)
+			}
+			b.Run("off", bench1KQueries(noCache, 1, queryfmt))
 			b.Run("0%", bench1KQueries((*Obfuscator).ObfuscateSQLString, 0, queryfmt))
 			b.Run("1%", bench1KQueries((*Obfuscator).ObfuscateSQLString, 0.01, queryfmt))
 			b.Run("5%", bench1KQueries((*Obfuscator).ObfuscateSQLString, 0.05, queryfmt))
@@ -8,6 +8,7 @@ package obfuscate
 import (
 	"bytes"
 	"fmt"
+	"strings"
 	"unicode"
 	"unicode/utf8"
 )
@@ -126,6 +127,70 @@ func (k TokenKind) String() string {
 
 const escapeCharacter = '\\'
 
+// SQLDialect specifies the SQL flavor that a query is written in. It allows the tokenizer to
+// correctly handle syntax which is specific to a given database engine, such as quoting rules.
+type SQLDialect uint8
+
+const (
+	// DialectGeneric is used when the database engine is not known. It tokenizes a
+	// common subset of the SQL syntax of most engines.
+	DialectGeneric SQLDialect = iota
+	// DialectPostgres is the PostgreSQL dialect. It adds support for dollar-quoted
+	// strings, escape string constants (E'...') and double-quoted identifiers.
+	DialectPostgres
+	// DialectMySQL is the MySQL (and MariaDB) dialect. It adds support for backtick
+	// quoted identifiers containing any character and treats double-quoted text as
+	// string literals.
+	DialectMySQL
+	// DialectMSSQL is the Microsoft SQL Server dialect. It adds support for bracketed
+	// identifiers and unicode string constants (N'...').
+	DialectMSSQL
+)
+
+var sqlDialectStrings = map[SQLDialect]string{
+	DialectGeneric:  "generic",
+	DialectPostgres: "postgres",
+	DialectMySQL:    "mysql",
+	DialectMSSQL:    "mssql",
+}
+
+func (d SQLDialect) String() string {
+	str, ok := sqlDialectStrings[d]
+	if !ok {
+		return "<unknown>"
+	}
+	return str
+}
+
+// DialectFromDBType returns the SQL dialect corresponding to the given value of a span's
+// "db.type" tag, as set by tracers. DialectGeneric is returned for unknown values.
+func DialectFromDBType(dbType string) SQLDialect {
+	switch strings.ToLower(dbType) {
+	case "postgres", "postgresql", "pg":
+		return DialectPostgres
+	case "mysql", "mariadb":
+		return DialectMySQL
+	case "mssql", "sqlserver", "sql server":
+		return DialectMSSQL
+	default:
+		return DialectGeneric
+	}
+}
+
+// quotesIdentifier reports whether ch opens a quoted identifier in dialect d.
+func (d SQLDialect) quotesIdentifier(ch rune) bool {
+	switch d {
+	case DialectPostgres:
+		return ch == '"'
+	case DialectMySQL:
+		return ch == '`'
+	case DialectMSSQL:
+		return ch == '[' || ch == '"'
+	default:
+		return false
+	}
+}
+
 // SQLTokenizer is the struct used to generate SQL
 // tokens for the parser.
 type SQLTokenizer struct {
@@ -137,19 +202,30 @@ type SQLTokenizer struct {
 
 	curlys uint32 // number of active open curly braces in top-level SQL escape sequences.
 
-	literalEscapes bool // indicates we should not treat backslashes as escape characters
-	seenEscape     bool // indicates whether this tokenizer has seen an escape character within a string
+	literalEscapes bool       // indicates we should not treat backslashes as escape characters
+	seenEscape     bool       // indicates whether this tokenizer has seen an escape character within a string
+	dialect        SQLDialect // the SQL dialect used to tokenize the query
 }
 
 // NewSQLTokenizer creates a new SQLTokenizer for the given SQL string. The literalEscapes argument specifies
 // whether escape characters should be treated literally or as such.
 func NewSQLTokenizer(sql string, literalEscapes bool) *SQLTokenizer {
+	return NewSQLTokenizerWithDialect(sql, literalEscapes, DialectGeneric)
+}
+
+// NewSQLTokenizerWithDialect creates a new SQLTokenizer for the given SQL string, handling the syntax
+// specific to the given dialect.
+func NewSQLTokenizerWithDialect(sql string, literalEscapes bool, dialect SQLDialect) *SQLTokenizer {
 	return &SQLTokenizer{
 		buf:            []byte(sql),
 		literalEscapes: literalEscapes,
+		dialect:        dialect,
 	}
 }
 
+// Dialect returns the SQL dialect used by this tokenizer.
+func (tkn *SQLTokenizer) Dialect() SQLDialect { return tkn.dialect }
+
 // Reset the underlying buffer and positions
 func (tkn *SQLTokenizer) Reset(in string) {
 	tkn.pos = 0
@@ -221,7 +297,12 @@ func (tkn *SQLTokenizer) Scan() (TokenKind, []byte) {
 				return tkn.scanBindVar()
 			}
 			fallthrough
-		case '=', ',', ';', '(', ')', '+', '*', '&', '|', '^', '~', '[', ']', '?':
+		case '[':
+			if tkn.dialect == DialectMSSQL {
+				return tkn.scanQuotedIdentifier(ch, 0)
+			}
+			return TokenKind(ch), tkn.bytes()
+		case '=', ',', ';', '(', ')', '+', '*', '&', '|', '^', '~', ']', '?':
 			return TokenKind(ch), tkn.bytes()
 		case '.':
 			if isDigit(tkn.lastChar) {
@@ -281,8 +362,17 @@ func (tkn *SQLTokenizer) Scan() (TokenKind, []byte) {
 		case '\'':
 			return tkn.scanString(ch, String)
 		case '"':
+			switch tkn.dialect {
+			case DialectPostgres, DialectMSSQL:
+				return tkn.scanQuotedIdentifier(ch, 0)
+			case DialectMySQL:
+				return tkn.scanString(ch, String)
+			}
 			return tkn.scanString(ch, DoubleQuotedString)
 		case '`':
+			if tkn.dialect == DialectMySQL {
+				return tkn.scanQuotedIdentifier(ch, 0)
+			}
 			return tkn.scanLiteralIdentifier('`')
 		case '%':
 			if tkn.lastChar == '(' {
@@ -295,6 +385,9 @@ func (tkn *SQLTokenizer) Scan() (TokenKind, []byte) {
 			// modulo operator (e.g. 'id % 8')
 			return TokenKind(ch), tkn.bytes()
 		case '$':
+			if tkn.dialect == DialectPostgres && !isDigit(tkn.lastChar) {
+				return tkn.scanDollarQuotedString()
+			}
 			return tkn.scanPreparedStatement('$')
 		case '{':
 			if tkn.pos == 1 || tkn.curlys > 0 {
@@ -373,6 +466,18 @@ func (tkn *SQLTokenizer) scanIdentifier() (TokenKind, []byte) {
 	for isLetter(tkn.lastChar) || isDigit(tkn.lastChar) || tkn.lastChar == '.' || tkn.lastChar == '*' {
 		tkn.advance()
 	}
+	if tkn.lastChar == '\'' || tkn.dialect.quotesIdentifier(tkn.lastChar) {
+		// the delimiters are single-byte, so the identifier is everything but the last byte
+		n := tkn.off - 1
+		if tkn.lastChar == '\'' && n == 1 && tkn.isStringPrefix(tkn.buf[0]) {
+			// string constant with a prefix, such as E'...' (Postgres) or N'...' (MSSQL)
+			return tkn.scanPrefixedString(tkn.buf[0])
+		}
+		if tkn.lastChar != '\'' && tkn.buf[n-1] == '.' {
+			// qualified name with a quoted part, such as public."users"
+			return tkn.scanQuotedIdentifier(tkn.lastChar, n)
+		}
+	}
 
 	t := tkn.bytes()
 	// Space allows us to upper-case identifiers 256 bytes long or less without allocating heap
@@ -405,6 +510,131 @@ func (tkn *SQLTokenizer) scanLiteralIdentifier(quote rune) (TokenKind, []byte) {
 	return ID, t
 }
 
+// scanQuotedIdentifier scans an identifier quoted using the dialect-specific delimiters, such as
+// "users" (Postgres), `users` (MySQL) or [users] (MSSQL). Doubling the closing delimiter escapes it.
+// If the identifier is followed by dots, the remaining parts of the qualified name are scanned too,
+// so that "public"."users" or [dbo].[users] are returned as a single identifier. The delimiters are
+// stripped from the result and the first n bytes of the buffer are kept as a prefix.
+// It expects the opening delimiter to have already been consumed.
+func (tkn *SQLTokenizer) scanQuotedIdentifier(open rune, n int) (TokenKind, []byte) {
+	// the output is never larger than the consumed input, so we can reuse the buffer
+	buf := bytes.NewBuffer(tkn.buf[:n])
+	if n > 0 {
+		// skip the opening delimiter, which is still the current character
+		tkn.advance()
+	}
+	for {
+		closing := closingQuote(open)
+		for {
+			ch := tkn.lastChar
+			if ch == EndChar {
+				tkn.setErr(`unexpected EOF in quoted identifier, expected "%c"`, closing)
+				return LexError, buf.Bytes()
+			}
+			tkn.advance()
+			if ch == closing {
+				if tkn.lastChar != closing {
+					break
+				}
+				// doubled delimiter
+				tkn.advance()
+			}
+			buf.WriteRune(ch)
+		}
+		// scan the remaining parts of a qualified name
+		quoted := false
+		for tkn.lastChar == '.' {
+			tkn.advance()
+			buf.WriteByte('.')
+			if tkn.dialect.quotesIdentifier(tkn.lastChar) {
+				open = tkn.lastChar
+				tkn.advance()
+				quoted = true
+				break
+			}
+			for isLetter(tkn.lastChar) || isDigit(tkn.lastChar) || tkn.lastChar == '*' {
+				buf.WriteRune(tkn.lastChar)
+				tkn.advance()
+			}
+		}
+		if !quoted {
+			break
+		}
+	}
+	if buf.Len() == 0 {
+		// keep the delimiters of empty identifiers to avoid creating invalid queries
+		return ID, append(runeBytes(open), runeBytes(closingQuote(open))...)
+	}
+	return ID, buf.Bytes()
+}
+
+// closingQuote returns the delimiter which closes a quoted identifier opened with the given rune.
+func closingQuote(open rune) rune {
+	if open == '[' {
+		return ']'
+	}
+	return open
+}
+
+// isStringPrefix reports whether the given byte may prefix a string constant in the tokenizer's dialect.
+func (tkn *SQLTokenizer) isStringPrefix(b byte) bool {
+	switch tkn.dialect {
+	case DialectPostgres:
+		return b == 'E' || b == 'e'
+	case DialectMSSQL:
+		return b == 'N' || b == 'n'
+	default:
+		return false
+	}
+}
+
+// scanPrefixedString scans a string constant prefixed with the given byte, such as E'...' or N'...'.
+// The current character is expected to be the opening quote.
+func (tkn *SQLTokenizer) scanPrefixedString(prefix byte) (TokenKind, []byte) {
+	tkn.advance()
+	if prefix == 'E' || prefix == 'e' {
+		// Postgres escape string constants always treat backslashes as escape characters
+		lesc := tkn.literalEscapes
+		tkn.literalEscapes = false
+		defer func() { tkn.literalEscapes = lesc }()
+	}
+	return tkn.scanString('\'', String)
+}
+
+// scanDollarQuotedString scans a Postgres dollar-quoted string constant, such as $$text$$ or
+// $tag$text$tag$. It expects the opening dollar sign to have already been consumed.
+// See: https://www.postgresql.org/docs/current/sql-syntax-lexical.html#SQL-SYNTAX-DOLLAR-QUOTING
+func (tkn *SQLTokenizer) scanDollarQuotedString() (TokenKind, []byte) {
+	tag := []byte{'$'}
+	for tkn.lastChar != '$' {
+		if !isLetter(tkn.lastChar) && !isDigit(tkn.lastChar) {
+			tkn.setErr(`unexpected character "%c" (%d) in dollar-quoted string tag`, tkn.lastChar, tkn.lastChar)
+			return LexError, tkn.bytes()
+		}
+		tag = append(tag, runeBytes(tkn.lastChar)...)
+		tkn.advance()
+	}
+	tag = append(tag, '$')
+	tkn.advance()
+	buf := bytes.NewBuffer(tkn.buf[:0])
+	for {
+		ch := tkn.lastChar
+		if ch == EndChar {
+			tkn.setErr("unexpected EOF in dollar-quoted string")
+			return LexError, buf.Bytes()
+		}
+		if ch == '$' && bytes.HasPrefix(tkn.buf[tkn.off-1:], tag) {
+			for i := utf8.RuneCount(tag); i > 0; i-- {
+				tkn.advance()
+			}
+			break
+		}
+		buf.WriteRune(ch)
+		tkn.advance()
+	}
+	return String, buf.Bytes()
+}
+
 func (tkn *SQLTokenizer) scanVariableIdentifier(prefix rune) (TokenKind, []byte) {
 	for tkn.advance(); tkn.lastChar != ')' && tkn.lastChar != EndChar; tkn.advance() {
 	}
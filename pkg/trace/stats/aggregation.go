@@ -25,13 +25,21 @@ type Aggregation struct {
 	StatusCode string
 	Version    string
 	Synthetics bool
+
+	// Additional dimensions, only set when enabled (see Dimensions).
+	PeerService string
+	HTTPMethod  string
+	// CustomTags holds the user-configured tags in their canonical form:
+	// comma-separated "name:value" pairs, sorted by name.
+	CustomTags string
 }
 
-// NewAggregationFromSpan creates a new aggregation from the provided span and env
-func NewAggregationFromSpan(s *pb.Span, env string) Aggregation {
+// NewAggregationFromSpan creates a new aggregation from the provided span and env.
+// The additional dimensions in dims, if not nil, are also read from the span.
+func NewAggregationFromSpan(s *pb.Span, env string, dims *Dimensions) Aggregation {
 	synthetics := strings.HasPrefix(s.Meta[tagOrigin], "synthetics")
 
-	return Aggregation{
+	aggr := Aggregation{
 		Env:        env,
 		Resource:   s.Resource,
 		Service:    s.Service,
@@ -40,39 +48,58 @@ func NewAggregationFromSpan(s *pb.Span, env string) Aggregation {
 		Version:    s.Meta[tagVersion],
 		Synthetics: synthetics,
 	}
+	if dims != nil {
+		dims.apply(&aggr, s)
+	}
+	return aggr
 }
 
-// NewAggregation creates a new aggregation from the provided fields
-func NewAggregation(env string, resource string, service string, hostname string, statusCode string, version string, synthetics bool) Aggregation {
+// NewAggregation creates a new aggregation from the provided fields. customTags
+// holds additional "name:value" tags, in any order.
+func NewAggregation(env string, resource string, service string, hostname string, statusCode string, version string, synthetics bool, peerService string, httpMethod string, customTags []string) Aggregation {
 	return Aggregation{
-		Env:        env,
-		Resource:   resource,
-		Service:    service,
-		Hostname:   hostname,
-		StatusCode: statusCode,
-		Version:    version,
-		Synthetics: synthetics,
+		Env:         env,
+		Resource:    resource,
+		Service:     service,
+		Hostname:    hostname,
+		StatusCode:  statusCode,
+		Version:     version,
+		Synthetics:  synthetics,
+		PeerService: peerService,
+		HTTPMethod:  httpMethod,
+		CustomTags:  customTagsKey(customTags),
 	}
 }
 
 // ToTagSet creates a TagSet with the fields of the aggregation
 func (aggr *Aggregation) ToTagSet() TagSet {
-	tagSet := make(TagSet, 3, 7)
+	tagSet := make(TagSet, 3, 9)
 	tagSet[0] = Tag{"env", aggr.Env}
 	tagSet[1] = Tag{"resource", aggr.Resource}
 	tagSet[2] = Tag{"service", aggr.Service}
 	if len(aggr.Hostname) > 0 {
 		tagSet = append(tagSet, Tag{tagHostname, aggr.Hostname})
 	}
+	if len(aggr.HTTPMethod) > 0 {
+		tagSet = append(tagSet, Tag{tagHTTPMethod, aggr.HTTPMethod})
+	}
 	if len(aggr.StatusCode) > 0 {
 		tagSet = append(tagSet, Tag{tagStatusCode, aggr.StatusCode})
 	}
+	if len(aggr.PeerService) > 0 {
+		tagSet = append(tagSet, Tag{tagPeerService, aggr.PeerService})
+	}
 	if len(aggr.Version) > 0 {
 		tagSet = append(tagSet, Tag{tagVersion, aggr.Version})
 	}
 	if aggr.Synthetics {
 		tagSet = append(tagSet, Tag{tagSynthetics, "true"})
 	}
+	if len(aggr.CustomTags) > 0 {
+		for _, t := range strings.Split(aggr.CustomTags, ",") {
+			tagSet = append(tagSet, NewTagFromString(t))
+		}
+	}
 	return tagSet
 }
 
@@ -83,10 +110,18 @@ func (aggr *Aggregation) KeyLen() int {
 		// +2 for "," and ":" separator
 		length += 1 + len(tagHostname) + 1 + len(aggr.Hostname)
 	}
+	if len(aggr.HTTPMethod) > 0 {
+		// +2 for "," and ":" separator
+		length += 1 + len(tagHTTPMethod) + 1 + len(aggr.HTTPMethod)
+	}
 	if len(aggr.StatusCode) > 0 {
 		// +2 for "," and ":" separator
 		length += 1 + len(tagStatusCode) + 1 + len(aggr.StatusCode)
 	}
+	if len(aggr.PeerService) > 0 {
+		// +2 for "," and ":" separator
+		length += 1 + len(tagPeerService) + 1 + len(aggr.PeerService)
+	}
 	if len(aggr.Version) > 0 {
 		// +2 for "," and ":" separator
 		length += 1 + len(tagVersion) + 1 + len(aggr.Version)
@@ -95,6 +130,10 @@ func (aggr *Aggregation) KeyLen() int {
 		// +2 for "," and ":" separator
 		length += 1 + len(tagSynthetics) + 1 + len("true")
 	}
+	if len(aggr.CustomTags) > 0 {
+		// +1 for "," separator
+		length += 1 + len(aggr.CustomTags)
+	}
 	return length
 }
 
@@ -112,10 +151,18 @@ func (aggr *Aggregation) WriteKey(b *strings.Builder) {
 		b.WriteString("," + tagHostname + ":")
 		b.WriteString(aggr.Hostname)
 	}
+	if len(aggr.HTTPMethod) > 0 {
+		b.WriteString("," + tagHTTPMethod + ":")
+		b.WriteString(aggr.HTTPMethod)
+	}
 	if len(aggr.StatusCode) > 0 {
 		b.WriteString("," + tagStatusCode + ":")
 		b.WriteString(aggr.StatusCode)
 	}
+	if len(aggr.PeerService) > 0 {
+		b.WriteString("," + tagPeerService + ":")
+		b.WriteString(aggr.PeerService)
+	}
 	if len(aggr.Version) > 0 {
 		b.WriteString("," + tagVersion + ":")
 		b.WriteString(aggr.Version)
@@ -124,4 +171,9 @@ func (aggr *Aggregation) WriteKey(b *strings.Builder) {
 		b.WriteString("," + tagSynthetics + ":")
 		b.WriteString("true")
 	}
+	// custom tags are sorted amongst themselves and always come last
+	if len(aggr.CustomTags) > 0 {
+		b.WriteByte(',')
+		b.WriteString(aggr.CustomTags)
+	}
 }
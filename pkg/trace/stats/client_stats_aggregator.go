@@ -0,0 +1,334 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package stats
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/watchdog"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/sketches-go/ddsketch"
+	"github.com/DataDog/sketches-go/ddsketch/pb/sketchpb"
+	"github.com/golang/protobuf/proto"
+)
+
+// ClientStatsAggregator aggregates the stats payloads computed by tracers into time
+// buckets of bsize. Tracers running in different processes on the same host send their
+// own payloads for the same time bucket; the aggregator merges them, so that a single
+// payload is forwarded per bucket (and per hostname, env and version).
+type ClientStatsAggregator struct {
+	In  chan pb.ClientStatsPayload
+	out chan pb.ClientStatsPayload
+
+	// bucket duration in nanoseconds
+	bsize int64
+	// Timestamp of the oldest time bucket which was not flushed yet. Payloads for older
+	// buckets are forwarded as they are.
+	oldestTs int64
+	// bufferLen is the number of buckets kept in memory before flushing them, allowing
+	// payloads to arrive late.
+	bufferLen int
+
+	buckets map[int64]*clientStatsBucket
+	stats   clientStatsAggregatorStats
+
+	exit   chan struct{}
+	exitWG *sync.WaitGroup
+}
+
+// clientStatsAggregatorStats holds the statistics reported by the aggregator on each flush.
+type clientStatsAggregatorStats struct {
+	payloadsIn     int64 // payloads received
+	payloadsOut    int64 // payloads forwarded
+	groupsIn       int64 // grouped stats received
+	groupsMerged   int64 // grouped stats merged into an already existing one
+	passthrough    int64 // payloads forwarded as they are, because their bucket was already flushed
+	sketchErrors   int64 // sketches which could not be decoded or merged
+	bucketsFlushed int64 // buckets flushed
+}
+
+// NewClientStatsAggregator initializes a new aggregator ready to be started. Aggregated
+// payloads are sent to out.
+func NewClientStatsAggregator(bsize int64, out chan pb.ClientStatsPayload, now time.Time) *ClientStatsAggregator {
+	return &ClientStatsAggregator{
+		In:  make(chan pb.ClientStatsPayload, 10),
+		out: out,
+
+		bsize:     bsize,
+		oldestTs:  alignTs(now.UnixNano(), bsize),
+		bufferLen: defaultBufferLen,
+		buckets:   make(map[int64]*clientStatsBucket),
+
+		exit:   make(chan struct{}),
+		exitWG: &sync.WaitGroup{},
+	}
+}
+
+// Start starts the aggregator.
+func (a *ClientStatsAggregator) Start() {
+	a.exitWG.Add(1)
+	go func() {
+		defer watchdog.LogOnPanic()
+		defer a.exitWG.Done()
+		a.Run()
+	}()
+}
+
+// Run runs the main loop of the aggregator, until Stop is called.
+func (a *ClientStatsAggregator) Run() {
+	flushTicker := time.NewTicker(time.Duration(a.bsize) * time.Nanosecond)
+	defer flushTicker.Stop()
+
+	log.Debug("Starting client stats aggregator")
+	for {
+		select {
+		case p := <-a.In:
+			a.add(p)
+		case t := <-flushTicker.C:
+			a.flush(a.flushNow(t.UnixNano(), false))
+		case <-a.exit:
+			log.Info("Exiting client stats aggregator, flushing remaining stats")
+			for len(a.In) > 0 {
+				a.add(<-a.In)
+			}
+			a.flush(a.flushNow(time.Now().UnixNano(), true))
+			return
+		}
+	}
+}
+
+// Stop stops the main Run loop, flushing all the buckets.
+func (a *ClientStatsAggregator) Stop() {
+	close(a.exit)
+	a.exitWG.Wait()
+}
+
+// add aggregates p into the buckets. Payloads containing buckets which were already
+// flushed are forwarded as they are.
+func (a *ClientStatsAggregator) add(p pb.ClientStatsPayload) {
+	a.stats.payloadsIn++
+	pk := clientPayloadKey{hostname: p.Hostname, env: p.Env, version: p.Version}
+	var late []pb.ClientStatsBucket
+	for _, sb := range p.Stats {
+		ts := alignTs(int64(sb.Start), a.bsize)
+		if ts < a.oldestTs {
+			late = append(late, sb)
+			continue
+		}
+		b, ok := a.buckets[ts]
+		if !ok {
+			b = newClientStatsBucket()
+			a.buckets[ts] = b
+		}
+		for _, gs := range sb.Stats {
+			a.stats.groupsIn++
+			b.add(pk, gs, &a.stats)
+		}
+	}
+	if len(late) > 0 {
+		a.stats.passthrough++
+		a.stats.payloadsOut++
+		p.Stats = late
+		a.flush([]pb.ClientStatsPayload{p})
+	}
+}
+
+func (a *ClientStatsAggregator) flush(payloads []pb.ClientStatsPayload) {
+	for _, p := range payloads {
+		a.out <- p
+	}
+}
+
+// flushNow returns the payloads of the buckets which are complete at the given time,
+// or of all the buckets if force is true, and reports the aggregation stats.
+func (a *ClientStatsAggregator) flushNow(now int64, force bool) []pb.ClientStatsPayload {
+	var payloads []pb.ClientStatsPayload
+	for ts, b := range a.buckets {
+		if !force && ts > now-int64(a.bufferLen)*a.bsize {
+			continue
+		}
+		log.Debugf("flushing client stats bucket %d", ts)
+		payloads = append(payloads, b.export(ts, a.bsize, &a.stats)...)
+		a.stats.bucketsFlushed++
+		delete(a.buckets, ts)
+	}
+	newOldestTs := alignTs(now, a.bsize) - int64(a.bufferLen-1)*a.bsize
+	if force {
+		newOldestTs = alignTs(now, a.bsize) + a.bsize
+	}
+	if newOldestTs > a.oldestTs {
+		a.oldestTs = newOldestTs
+	}
+	a.stats.payloadsOut += int64(len(payloads))
+	a.report()
+	return payloads
+}
+
+// report sends the aggregation stats collected since the last call, and resets them.
+func (a *ClientStatsAggregator) report() {
+	s := a.stats
+	metrics.Count("datadog.trace_agent.stats_aggregator.payloads_in", s.payloadsIn, nil, 1)
+	metrics.Count("datadog.trace_agent.stats_aggregator.payloads_out", s.payloadsOut, nil, 1)
+	metrics.Count("datadog.trace_agent.stats_aggregator.groups_in", s.groupsIn, nil, 1)
+	metrics.Count("datadog.trace_agent.stats_aggregator.groups_merged", s.groupsMerged, nil, 1)
+	metrics.Count("datadog.trace_agent.stats_aggregator.passthrough", s.passthrough, nil, 1)
+	metrics.Count("datadog.trace_agent.stats_aggregator.sketch_errors", s.sketchErrors, nil, 1)
+	metrics.Count("datadog.trace_agent.stats_aggregator.buckets_flushed", s.bucketsFlushed, nil, 1)
+	a.stats = clientStatsAggregatorStats{}
+}
+
+// clientPayloadKey identifies the payloads which can be merged together.
+type clientPayloadKey struct {
+	hostname, env, version string
+}
+
+// clientGroupKey identifies the grouped stats which can be merged together.
+type clientGroupKey struct {
+	service, name, resource string
+	statusCode              uint32
+	typ, dbType             string
+	synthetics              bool
+	peerService, httpMethod string
+	tags                    string
+}
+
+func newClientGroupKey(gs *pb.ClientGroupedStats) clientGroupKey {
+	tags := ""
+	if len(gs.Tags) > 0 {
+		sorted := append([]string(nil), gs.Tags...)
+		sort.Strings(sorted)
+		tags = strings.Join(sorted, ",")
+	}
+	return clientGroupKey{
+		service:     gs.Service,
+		name:        gs.Name,
+		resource:    gs.Resource,
+		statusCode:  gs.HTTPStatusCode,
+		typ:         gs.Type,
+		dbType:      gs.DBType,
+		synthetics:  gs.Synthetics,
+		peerService: gs.PeerService,
+		httpMethod:  gs.HTTPMethod,
+		tags:        tags,
+	}
+}
+
+// clientStatsBucket holds the grouped stats received for a time bucket.
+type clientStatsBucket struct {
+	payloads map[clientPayloadKey]map[clientGroupKey]*clientGroupedStats
+}
+
+func newClientStatsBucket() *clientStatsBucket {
+	return &clientStatsBucket{payloads: make(map[clientPayloadKey]map[clientGroupKey]*clientGroupedStats)}
+}
+
+// clientGroupedStats holds merged grouped stats. The summaries are decoded in order to
+// be merged and are only encoded again when exporting the bucket.
+type clientGroupedStats struct {
+	pb.ClientGroupedStats
+	ok, errors *ddsketch.DDSketch
+}
+
+func (b *clientStatsBucket) add(pk clientPayloadKey, gs pb.ClientGroupedStats, stats *clientStatsAggregatorStats) {
+	groups, ok := b.payloads[pk]
+	if !ok {
+		groups = make(map[clientGroupKey]*clientGroupedStats)
+		b.payloads[pk] = groups
+	}
+	gk := newClientGroupKey(&gs)
+	g, ok := groups[gk]
+	if !ok {
+		g = &clientGroupedStats{ClientGroupedStats: gs}
+		g.OkSummary, g.ErrorSummary = nil, nil
+		groups[gk] = g
+	} else {
+		stats.groupsMerged++
+		g.Hits += gs.Hits
+		g.Errors += gs.Errors
+		g.Duration += gs.Duration
+	}
+	var err error
+	if g.ok, err = mergeSketch(g.ok, gs.OkSummary); err != nil {
+		log.Debugf("Error merging client stats summary: %v", err)
+		stats.sketchErrors++
+	}
+	if g.errors, err = mergeSketch(g.errors, gs.ErrorSummary); err != nil {
+		log.Debugf("Error merging client stats error summary: %v", err)
+		stats.sketchErrors++
+	}
+}
+
+// export returns the payloads holding the stats of the bucket starting at ts.
+func (b *clientStatsBucket) export(ts, bsize int64, stats *clientStatsAggregatorStats) []pb.ClientStatsPayload {
+	payloads := make([]pb.ClientStatsPayload, 0, len(b.payloads))
+	for pk, groups := range b.payloads {
+		sb := pb.ClientStatsBucket{
+			Start:    uint64(ts),
+			Duration: uint64(bsize),
+			Stats:    make([]pb.ClientGroupedStats, 0, len(groups)),
+		}
+		for _, g := range groups {
+			gs := g.ClientGroupedStats
+			var err error
+			if gs.OkSummary, err = encodeSketch(g.ok); err != nil {
+				log.Debugf("Error encoding client stats summary: %v", err)
+				stats.sketchErrors++
+			}
+			if gs.ErrorSummary, err = encodeSketch(g.errors); err != nil {
+				log.Debugf("Error encoding client stats error summary: %v", err)
+				stats.sketchErrors++
+			}
+			sb.Stats = append(sb.Stats, gs)
+		}
+		payloads = append(payloads, pb.ClientStatsPayload{
+			Hostname: pk.hostname,
+			Env:      pk.env,
+			Version:  pk.version,
+			Stats:    []pb.ClientStatsBucket{sb},
+		})
+	}
+	return payloads
+}
+
+// mergeSketch merges the protobuf encoded sketch data into s and returns the result.
+// A nil sketch is returned when both are empty.
+func mergeSketch(s *ddsketch.DDSketch, data []byte) (*ddsketch.DDSketch, error) {
+	if len(data) == 0 {
+		return s, nil
+	}
+	var msg sketchpb.DDSketch
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		return s, err
+	}
+	if msg.PositiveValues == nil {
+		msg.PositiveValues = &sketchpb.Store{}
+	}
+	if msg.NegativeValues == nil {
+		msg.NegativeValues = &sketchpb.Store{}
+	}
+	other, err := (&ddsketch.DDSketch{}).FromProto(&msg)
+	if err != nil {
+		return s, err
+	}
+	if s == nil {
+		return other, nil
+	}
+	return s, s.MergeWith(other)
+}
+
+// encodeSketch returns the protobuf encoding of s, or nil if s is nil.
+func encodeSketch(s *ddsketch.DDSketch) ([]byte, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return proto.Marshal(s.ToProto())
+}
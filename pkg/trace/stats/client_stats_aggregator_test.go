@@ -0,0 +1,188 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package stats
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+
+	"github.com/DataDog/sketches-go/ddsketch"
+	"github.com/DataDog/sketches-go/ddsketch/pb/sketchpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testClientBucketSize = int64(10 * time.Second)
+
+// testSketch returns a protobuf encoded sketch holding the given values.
+func testSketch(t *testing.T, values ...float64) []byte {
+	s, err := ddsketch.LogCollapsingLowestDenseDDSketch(0.01, 2048)
+	require.NoError(t, err)
+	for _, v := range values {
+		require.NoError(t, s.Add(v))
+	}
+	data, err := proto.Marshal(s.ToProto())
+	require.NoError(t, err)
+	return data
+}
+
+// sketchCount returns the number of values held by the protobuf encoded sketch.
+func sketchCount(t *testing.T, data []byte) float64 {
+	var msg sketchpb.DDSketch
+	require.NoError(t, proto.Unmarshal(data, &msg))
+	s, err := (&ddsketch.DDSketch{}).FromProto(&msg)
+	require.NoError(t, err)
+	return s.GetCount()
+}
+
+func testClientPayload(t *testing.T, hostname string, start int64, groups ...pb.ClientGroupedStats) pb.ClientStatsPayload {
+	return pb.ClientStatsPayload{
+		Hostname: hostname,
+		Env:      "prod",
+		Version:  "1.0",
+		Stats: []pb.ClientStatsBucket{{
+			Start:    uint64(start),
+			Duration: uint64(testClientBucketSize),
+			Stats:    groups,
+		}},
+	}
+}
+
+func testGroup(t *testing.T, resource string, hits, errors uint64, okValues, errValues []float64) pb.ClientGroupedStats {
+	return pb.ClientGroupedStats{
+		Service:      "web",
+		Name:         "http.request",
+		Resource:     resource,
+		Hits:         hits,
+		Errors:       errors,
+		Duration:     hits * 100,
+		OkSummary:    testSketch(t, okValues...),
+		ErrorSummary: testSketch(t, errValues...),
+	}
+}
+
+func TestClientStatsAggregatorMerge(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Now()
+	a := NewClientStatsAggregator(testClientBucketSize, nil, now)
+	start := alignTs(now.UnixNano(), testClientBucketSize)
+
+	a.add(testClientPayload(t, "host", start,
+		testGroup(t, "GET /a", 2, 1, []float64{1}, []float64{2}),
+		testGroup(t, "GET /b", 1, 0, []float64{3}, nil),
+	))
+	// sent by another tracer, in the middle of the bucket
+	a.add(testClientPayload(t, "host", start+int64(time.Second),
+		testGroup(t, "GET /a", 3, 0, []float64{4, 5, 6}, nil),
+	))
+	// sent from another host
+	a.add(testClientPayload(t, "other", start,
+		testGroup(t, "GET /a", 1, 0, []float64{7}, nil),
+	))
+
+	// the bucket is not complete yet
+	assert.Empty(a.flushNow(start+testClientBucketSize, false))
+	assert.Len(a.buckets, 1)
+
+	payloads := a.flushNow(start+int64(defaultBufferLen)*testClientBucketSize, false)
+	assert.Empty(a.buckets)
+	require.Len(t, payloads, 2)
+	sort.Slice(payloads, func(i, j int) bool { return payloads[i].Hostname < payloads[j].Hostname })
+
+	p := payloads[0]
+	assert.Equal("host", p.Hostname)
+	assert.Equal("prod", p.Env)
+	assert.Equal("1.0", p.Version)
+	require.Len(t, p.Stats, 1)
+	assert.EqualValues(start, p.Stats[0].Start)
+	assert.EqualValues(testClientBucketSize, p.Stats[0].Duration)
+	groups := p.Stats[0].Stats
+	require.Len(t, groups, 2)
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Resource < groups[j].Resource })
+
+	assert.Equal("GET /a", groups[0].Resource)
+	assert.EqualValues(5, groups[0].Hits)
+	assert.EqualValues(1, groups[0].Errors)
+	assert.EqualValues(500, groups[0].Duration)
+	assert.Equal(4., sketchCount(t, groups[0].OkSummary))
+	assert.Equal(1., sketchCount(t, groups[0].ErrorSummary))
+
+	assert.Equal("GET /b", groups[1].Resource)
+	assert.EqualValues(1, groups[1].Hits)
+	assert.Equal(1., sketchCount(t, groups[1].OkSummary))
+	assert.Equal(0., sketchCount(t, groups[1].ErrorSummary))
+
+	p = payloads[1]
+	assert.Equal("other", p.Hostname)
+	require.Len(t, p.Stats[0].Stats, 1)
+	assert.EqualValues(1, p.Stats[0].Stats[0].Hits)
+
+	assert.Equal(clientStatsAggregatorStats{}, a.stats, "stats should be reset after being reported")
+}
+
+func TestClientStatsAggregatorStats(t *testing.T) {
+	now := time.Now()
+	a := NewClientStatsAggregator(testClientBucketSize, nil, now)
+	start := alignTs(now.UnixNano(), testClientBucketSize)
+	group := testGroup(t, "GET /a", 1, 0, []float64{1}, nil)
+
+	a.add(testClientPayload(t, "host", start, group))
+	a.add(testClientPayload(t, "host", start, group))
+	bad := group
+	bad.OkSummary = []byte("invalid")
+	a.add(testClientPayload(t, "host", start, bad))
+
+	assert.Equal(t, clientStatsAggregatorStats{
+		payloadsIn:   3,
+		groupsIn:     3,
+		groupsMerged: 2,
+		sketchErrors: 1,
+	}, a.stats)
+}
+
+func TestClientStatsAggregatorGroupKey(t *testing.T) {
+	g1 := pb.ClientGroupedStats{Service: "web", Tags: []string{"b:2", "a:1"}}
+	g2 := pb.ClientGroupedStats{Service: "web", Tags: []string{"a:1", "b:2"}}
+	g3 := pb.ClientGroupedStats{Service: "web", Tags: []string{"a:1"}}
+	g4 := pb.ClientGroupedStats{Service: "web", Tags: []string{"a:1", "b:2"}, PeerService: "db"}
+	assert.Equal(t, newClientGroupKey(&g1), newClientGroupKey(&g2))
+	assert.NotEqual(t, newClientGroupKey(&g1), newClientGroupKey(&g3))
+	assert.NotEqual(t, newClientGroupKey(&g2), newClientGroupKey(&g4))
+}
+
+func TestClientStatsAggregatorLate(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Now()
+	out := make(chan pb.ClientStatsPayload, 10)
+	a := NewClientStatsAggregator(testClientBucketSize, out, now)
+	start := alignTs(now.UnixNano(), testClientBucketSize)
+
+	late := testClientPayload(t, "host", start-testClientBucketSize, testGroup(t, "GET /a", 1, 0, []float64{1}, nil))
+	a.add(late)
+	assert.Empty(a.buckets)
+	require.Len(t, out, 1)
+	assert.Equal(late, <-out)
+	assert.EqualValues(1, a.stats.passthrough)
+	assert.EqualValues(1, a.stats.payloadsOut)
+}
+
+func TestClientStatsAggregatorStop(t *testing.T) {
+	now := time.Now()
+	out := make(chan pb.ClientStatsPayload, 10)
+	a := NewClientStatsAggregator(testClientBucketSize, out, now)
+	a.Start()
+	a.In <- testClientPayload(t, "host", now.UnixNano(), testGroup(t, "GET /a", 1, 0, []float64{1}, nil))
+	a.Stop()
+
+	// all buckets are flushed when stopping, including the current one
+	require.Len(t, out, 1)
+	p := <-out
+	assert.EqualValues(t, 1, p.Stats[0].Stats[0].Hits)
+}
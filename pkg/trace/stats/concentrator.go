@@ -40,14 +40,17 @@ type Concentrator struct {
 	exitWG *sync.WaitGroup
 
 	buckets map[int64]*RawBucket // buckets used to aggregate stats per timestamp
+	dims    *Dimensions          // additional aggregation dimensions, nil if there are none
 	mu      sync.Mutex
 }
 
-// NewConcentrator initializes a new concentrator ready to be started
-func NewConcentrator(bsize int64, out chan []Bucket, now time.Time) *Concentrator {
+// NewConcentrator initializes a new concentrator ready to be started. Stats are
+// additionally aggregated on dims, if not nil.
+func NewConcentrator(bsize int64, out chan []Bucket, now time.Time, dims *Dimensions) *Concentrator {
 	c := Concentrator{
 		bsize:   bsize,
 		buckets: make(map[int64]*RawBucket),
+		dims:    dims,
 		// At start, only allow stats for the current time bucket. Ensure we don't
 		// override buckets which could have been sent before an Agent restart.
 		oldestTs: alignTs(now.UnixNano(), bsize),
@@ -141,7 +144,7 @@ func (c *Concentrator) addNow(i *Input) {
 
 		b, ok := c.buckets[btime]
 		if !ok {
-			b = NewRawBucket(btime, c.bsize)
+			b = newRawBucket(btime, c.bsize, c.dims)
 			c.buckets[btime] = b
 		}
 		b.HandleSpan(s, i.Env)
@@ -169,6 +172,11 @@ func (c *Concentrator) flushNow(now int64) []Bucket {
 		delete(c.buckets, ts)
 	}
 
+	// The cardinality cap of the additional dimensions applies per flush interval.
+	if c.dims != nil {
+		c.dims.Reset()
+	}
+
 	// After flushing, update the oldest timestamp allowed to prevent having stats for
 	// an already-flushed bucket.
 	newOldestTs := alignTs(now, c.bsize) - int64(c.bufferLen-1)*c.bsize
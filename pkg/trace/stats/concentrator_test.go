@@ -155,7 +155,7 @@ func TestConcentratorOldestTs(t *testing.T) {
 	t.Run("cold", func(t *testing.T) {
 		// Running cold, all spans in the past should end up in the current time bucket.
 		flushTime := now.UnixNano()
-		c := NewConcentrator(testBucketInterval, statsChan, now)
+		c := NewConcentrator(testBucketInterval, statsChan, now, nil)
 		c.addNow(testTrace)
 
 		for i := 0; i < c.bufferLen; i++ {
@@ -184,7 +184,7 @@ func TestConcentratorOldestTs(t *testing.T) {
 
 	t.Run("hot", func(t *testing.T) {
 		flushTime := now.UnixNano()
-		c := NewConcentrator(testBucketInterval, statsChan, now)
+		c := NewConcentrator(testBucketInterval, statsChan, now, nil)
 		c.oldestTs = alignTs(flushTime, c.bsize) - int64(c.bufferLen-1)*c.bsize
 		c.addNow(testTrace)
 
@@ -233,7 +233,7 @@ func TestConcentratorStatsTotals(t *testing.T) {
 	statsChan := make(chan []Bucket)
 
 	now := time.Now()
-	c := NewConcentrator(testBucketInterval, statsChan, now)
+	c := NewConcentrator(testBucketInterval, statsChan, now, nil)
 	alignedNow := alignTs(now.UnixNano(), c.bsize)
 
 	// update oldestTs as it running for quite some time, to avoid the fact that at startup
@@ -292,13 +292,55 @@ func TestConcentratorStatsTotals(t *testing.T) {
 	})
 }
 
+// TestConcentratorDimensions tests that stats are aggregated on the additional dimensions.
+func TestConcentratorDimensions(t *testing.T) {
+	assert := assert.New(t)
+	statsChan := make(chan []Bucket)
+
+	now := time.Now()
+	dims := NewDimensions(true, true, []string{"region"}, 1)
+	c := NewConcentrator(testBucketInterval, statsChan, now, dims)
+
+	spanWithTags := func(spanID uint64, peer, method, region string) *pb.Span {
+		s := testSpan(spanID, 0, 10, 0, "A1", "resource1", 0)
+		s.Meta = map[string]string{"peer.service": peer, "http.method": method, "region": region}
+		return s
+	}
+	trace := pb.Trace{
+		spanWithTags(1, "db", "GET", "us"),
+		spanWithTags(2, "db", "GET", "us"),
+		spanWithTags(3, "cache", "POST", "eu"),
+	}
+	for _, s := range trace {
+		// all spans are top-level
+		traceutil.SetTopLevel(s, true)
+	}
+	c.addNow(&Input{Env: "none", Trace: NewWeightedTrace(trace, trace[0])})
+
+	stats := c.flushNow(now.UnixNano() + int64(c.bufferLen)*c.bsize)
+	if !assert.Len(stats, 1) {
+		return
+	}
+	expected := map[string]float64{
+		"query|hits|env:none,resource:resource1,service:A1,http.method:GET,peer.service:db,region:us":                                                    2,
+		"query|hits|env:none,resource:resource1,service:A1,http.method:" + OverflowValue + ",peer.service:" + OverflowValue + ",region:" + OverflowValue: 1,
+	}
+	for key, value := range expected {
+		count, ok := stats[0].Counts[key]
+		if assert.True(ok, "missing count %s", key) {
+			assert.Equal(value, count.Value)
+		}
+	}
+	assert.Empty(dims.seen, "cardinality state should be reset on flush")
+}
+
 // TestConcentratorStatsCounts tests exhaustively each stats bucket, over multiple time buckets.
 func TestConcentratorStatsCounts(t *testing.T) {
 	assert := assert.New(t)
 	statsChan := make(chan []Bucket)
 
 	now := time.Now()
-	c := NewConcentrator(testBucketInterval, statsChan, now)
+	c := NewConcentrator(testBucketInterval, statsChan, now, nil)
 	alignedNow := alignTs(now.UnixNano(), c.bsize)
 
 	// update oldestTs as it running for quite some time, to avoid the fact that at startup
@@ -467,7 +509,7 @@ func TestConcentratorAdd(t *testing.T) {
 				Env:   "none",
 				Trace: wt,
 			}
-			c := NewConcentrator(testBucketInterval, statsChan, now)
+			c := NewConcentrator(testBucketInterval, statsChan, now, nil)
 			c.addNow(testTrace)
 			stats := c.flushNow(now.UnixNano() + (int64(c.bufferLen) * testBucketInterval))
 			countValsEq(t, test.out, stats[0].Counts)
@@ -0,0 +1,208 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package stats
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+const (
+	tagPeerService = "peer.service"
+	tagHTTPMethod  = "http.method"
+)
+
+// DefaultMaxCardinality is the default maximum number of distinct values allowed for each
+// additional aggregation dimension within a flush interval.
+const DefaultMaxCardinality = config.DefaultStatsMaxCardinality
+
+// OverflowValue replaces the values of an additional aggregation dimension once its
+// cardinality cap is reached.
+const OverflowValue = "_dd.overflow"
+
+// Dimensions specifies the additional span tags on which stats are aggregated, on top of
+// the fixed ones found in Aggregation. To protect against high-cardinality tags, the number
+// of distinct values of each dimension is capped: once the cap is reached, any new value is
+// collapsed into OverflowValue until Reset is called.
+//
+// Dimensions is not safe for concurrent use.
+type Dimensions struct {
+	peerService    bool
+	httpMethod     bool
+	tags           []string // custom span tags, sorted
+	maxCardinality int
+
+	seen     map[string]map[string]struct{} // distinct values seen per dimension
+	overflow map[string]int64               // number of collapsed values per dimension
+}
+
+// NewDimensions returns a new set of additional aggregation dimensions. The peerService
+// and httpMethod arguments enable aggregating on the "peer.service" and "http.method" tags,
+// and tags lists additional custom span tags to aggregate on. A maxCardinality of zero or
+// less disables the cardinality cap. It returns nil when no dimension is enabled.
+func NewDimensions(peerService, httpMethod bool, tags []string, maxCardinality int) *Dimensions {
+	if !peerService && !httpMethod && len(tags) == 0 {
+		return nil
+	}
+	custom := make([]string, 0, len(tags))
+	for _, t := range tags {
+		if t == "" || t == tagPeerService || t == tagHTTPMethod {
+			continue
+		}
+		custom = append(custom, t)
+	}
+	sort.Strings(custom)
+	return &Dimensions{
+		peerService:    peerService || contains(tags, tagPeerService),
+		httpMethod:     httpMethod || contains(tags, tagHTTPMethod),
+		tags:           custom,
+		maxCardinality: maxCardinality,
+		seen:           make(map[string]map[string]struct{}),
+		overflow:       make(map[string]int64),
+	}
+}
+
+// value returns the value v of dimension name, or OverflowValue if v is a new value and
+// the dimension has reached its cardinality cap.
+func (d *Dimensions) value(name, v string) string {
+	if v == "" || d.maxCardinality <= 0 {
+		return v
+	}
+	values, ok := d.seen[name]
+	if !ok {
+		values = make(map[string]struct{})
+		d.seen[name] = values
+	}
+	if _, ok := values[v]; ok {
+		return v
+	}
+	if len(values) >= d.maxCardinality {
+		d.overflow[name]++
+		return OverflowValue
+	}
+	values[v] = struct{}{}
+	return v
+}
+
+// apply sets the additional dimensions of aggr using the tags of span s.
+func (d *Dimensions) apply(aggr *Aggregation, s *pb.Span) {
+	if d.peerService {
+		aggr.PeerService = d.value(tagPeerService, s.Meta[tagPeerService])
+	}
+	if d.httpMethod {
+		aggr.HTTPMethod = d.value(tagHTTPMethod, s.Meta[tagHTTPMethod])
+	}
+	if len(d.tags) == 0 {
+		return
+	}
+	var b strings.Builder
+	for _, name := range d.tags {
+		v, ok := s.Meta[name]
+		if !ok || v == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(sanitizeTagValue(d.value(name, v)))
+	}
+	aggr.CustomTags = b.String()
+}
+
+// ApplyClient keeps the additional dimensions of the client stats b which are enabled in d,
+// and caps their values the same way as the ones read from the spans. The custom tags of b
+// are "name:value" pairs, the ones which aren't configured are dropped. All the additional
+// dimensions are dropped if d is nil.
+func (d *Dimensions) ApplyClient(b *pb.ClientGroupedStats) {
+	if d == nil {
+		b.PeerService, b.HTTPMethod, b.Tags = "", "", nil
+		return
+	}
+	if d.peerService {
+		b.PeerService = d.value(tagPeerService, b.PeerService)
+	} else {
+		b.PeerService = ""
+	}
+	if d.httpMethod {
+		b.HTTPMethod = d.value(tagHTTPMethod, b.HTTPMethod)
+	} else {
+		b.HTTPMethod = ""
+	}
+	if len(b.Tags) == 0 {
+		return
+	}
+	tags := b.Tags[:0]
+	for _, t := range b.Tags {
+		name, v := SplitTag(t)
+		if v == "" || !d.hasTag(name) {
+			continue
+		}
+		tags = append(tags, name+":"+d.value(name, v))
+	}
+	if len(tags) == 0 {
+		tags = nil
+	}
+	b.Tags = tags
+}
+
+// hasTag reports whether name is one of the custom span tags of d.
+func (d *Dimensions) hasTag(name string) bool {
+	i := sort.SearchStrings(d.tags, name)
+	return i < len(d.tags) && d.tags[i] == name
+}
+
+// Reset forgets the values seen so far and reports the number of values which were
+// collapsed because of the cardinality cap since the last call.
+func (d *Dimensions) Reset() {
+	for name, n := range d.overflow {
+		metrics.Count("datadog.trace_agent.stats.dimension_overflow", n, []string{"dimension:" + name}, 1)
+		delete(d.overflow, name)
+	}
+	for name := range d.seen {
+		delete(d.seen, name)
+	}
+}
+
+// customTagsKey returns the canonical form of the given "name:value" tags, as stored
+// in Aggregation.CustomTags.
+func customTagsKey(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	sorted := make([]string, 0, len(tags))
+	for _, t := range tags {
+		name, value := SplitTag(t)
+		if name == "" || value == "" {
+			continue
+		}
+		sorted = append(sorted, name+":"+sanitizeTagValue(value))
+	}
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// sanitizeTagValue replaces commas in v, which are used to separate tags in aggregation keys.
+func sanitizeTagValue(v string) string {
+	if strings.IndexByte(v, ',') == -1 {
+		return v
+	}
+	return strings.Replace(v, ",", "_", -1)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package stats
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDimensions(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(NewDimensions(false, false, nil, 10))
+
+	dims := NewDimensions(false, false, []string{"region", "peer.service", "", "customer.tier"}, 10)
+	assert.True(dims.peerService)
+	assert.False(dims.httpMethod)
+	assert.Equal([]string{"customer.tier", "region"}, dims.tags)
+}
+
+func TestAggregationDimensions(t *testing.T) {
+	assert := assert.New(t)
+
+	span := &pb.Span{
+		Service:  "web",
+		Resource: "GET /users",
+		Meta: map[string]string{
+			"peer.service":  "users-db",
+			"http.method":   "GET",
+			"region":        "us-east,1",
+			"customer.tier": "gold",
+			"unused":        "value",
+		},
+	}
+
+	aggr := NewAggregationFromSpan(span, "prod", nil)
+	assert.Empty(aggr.PeerService)
+	assert.Empty(aggr.HTTPMethod)
+	assert.Empty(aggr.CustomTags)
+
+	dims := NewDimensions(true, true, []string{"region", "customer.tier", "missing"}, 10)
+	aggr = NewAggregationFromSpan(span, "prod", dims)
+	assert.Equal("users-db", aggr.PeerService)
+	assert.Equal("GET", aggr.HTTPMethod)
+	assert.Equal("customer.tier:gold,region:us-east_1", aggr.CustomTags)
+
+	key := "env:prod,resource:GET /users,service:web,http.method:GET,peer.service:users-db,customer.tier:gold,region:us-east_1"
+	var b strings.Builder
+	aggr.WriteKey(&b)
+	assert.Equal(key, b.String())
+	assert.Equal(len(key), aggr.KeyLen())
+	assert.Equal(NewTagSetFromString(key), aggr.ToTagSet())
+}
+
+func TestNewAggregationCustomTags(t *testing.T) {
+	aggr := NewAggregation("prod", "res", "svc", "", "200", "", false, "db", "POST", []string{"z:1", "invalid", "a:2"})
+	assert.Equal(t, "a:2,z:1", aggr.CustomTags)
+	assert.Equal(t, "db", aggr.PeerService)
+	assert.Equal(t, "POST", aggr.HTTPMethod)
+}
+
+func TestDimensionsCardinality(t *testing.T) {
+	assert := assert.New(t)
+
+	dims := NewDimensions(true, false, []string{"user"}, 2)
+	aggrFor := func(peer, user string) Aggregation {
+		return NewAggregationFromSpan(&pb.Span{
+			Meta: map[string]string{"peer.service": peer, "user": user},
+		}, "none", dims)
+	}
+
+	for i := 0; i < 2; i++ {
+		aggr := aggrFor(fmt.Sprintf("peer%d", i), fmt.Sprintf("user%d", i))
+		assert.Equal(fmt.Sprintf("peer%d", i), aggr.PeerService)
+		assert.Equal(fmt.Sprintf("user:user%d", i), aggr.CustomTags)
+	}
+
+	// known values are still accepted once the cap is reached
+	aggr := aggrFor("peer0", "user1")
+	assert.Equal("peer0", aggr.PeerService)
+	assert.Equal("user:user1", aggr.CustomTags)
+
+	// new values overflow, independently for each dimension
+	aggr = aggrFor("peer2", "user0")
+	assert.Equal(OverflowValue, aggr.PeerService)
+	assert.Equal("user:user0", aggr.CustomTags)
+	aggr = aggrFor("peer3", "user3")
+	assert.Equal(OverflowValue, aggr.PeerService)
+	assert.Equal("user:"+OverflowValue, aggr.CustomTags)
+	assert.EqualValues(2, dims.overflow[tagPeerService])
+	assert.EqualValues(1, dims.overflow["user"])
+
+	dims.Reset()
+	assert.Empty(dims.overflow)
+	aggr = aggrFor("peer3", "user3")
+	assert.Equal("peer3", aggr.PeerService)
+	assert.Equal("user:user3", aggr.CustomTags)
+}
+
+func TestDimensionsNoCardinalityCap(t *testing.T) {
+	dims := NewDimensions(true, false, nil, 0)
+	for i := 0; i < 1000; i++ {
+		peer := fmt.Sprintf("peer%d", i)
+		aggr := NewAggregationFromSpan(&pb.Span{Meta: map[string]string{"peer.service": peer}}, "none", dims)
+		assert.Equal(t, peer, aggr.PeerService)
+	}
+}
+
+func TestDimensionsApplyClient(t *testing.T) {
+	assert := assert.New(t)
+
+	dims := NewDimensions(true, false, []string{"user"}, 1)
+	b := &pb.ClientGroupedStats{PeerService: "db", HTTPMethod: "GET", Tags: []string{"user:u0", "secret:s", "invalid"}}
+	dims.ApplyClient(b)
+	assert.Equal("db", b.PeerService)
+	assert.Equal("", b.HTTPMethod)
+	assert.Equal([]string{"user:u0"}, b.Tags)
+
+	// the client stats share the cardinality cap of the dimensions
+	b = &pb.ClientGroupedStats{PeerService: "cache", Tags: []string{"user:u1"}}
+	dims.ApplyClient(b)
+	assert.Equal(OverflowValue, b.PeerService)
+	assert.Equal([]string{"user:" + OverflowValue}, b.Tags)
+
+	b = &pb.ClientGroupedStats{PeerService: "db", HTTPMethod: "GET", Tags: []string{"user:u0"}}
+	(*Dimensions)(nil).ApplyClient(b)
+	assert.Equal("", b.PeerService)
+	assert.Equal("", b.HTTPMethod)
+	assert.Nil(b.Tags)
+}
@@ -48,17 +48,27 @@ type RawBucket struct {
 	// this should really remain private as it's subject to refactoring
 	data map[statsKey]*groupedStats
 
+	// dims holds the additional aggregation dimensions, nil if there are none
+	dims *Dimensions
+
 	// internal buffer for aggregate strings - not threadsafe
 	keyBuf strings.Builder
 }
 
 // NewRawBucket opens a new calculation bucket for time ts and initializes it properly
 func NewRawBucket(ts, d int64) *RawBucket {
+	return newRawBucket(ts, d, nil)
+}
+
+// newRawBucket opens a new calculation bucket for time ts, aggregating stats on the
+// additional dimensions dims.
+func newRawBucket(ts, d int64, dims *Dimensions) *RawBucket {
 	// The only non-initialized value is the Duration which should be set by whoever closes that bucket
 	return &RawBucket{
 		start:    ts,
 		duration: d,
 		data:     make(map[statsKey]*groupedStats),
+		dims:     dims,
 	}
 }
 
@@ -121,7 +131,7 @@ func (sb *RawBucket) HandleSpan(s *WeightedSpan, env string) {
 	if env == "" {
 		panic("env should never be empty")
 	}
-	aggr := NewAggregationFromSpan(s.Span, env)
+	aggr := NewAggregationFromSpan(s.Span, env, sb.dims)
 	sb.add(s, aggr)
 }
 
@@ -19,7 +19,7 @@ func TestGrain(t *testing.T) {
 	assert := assert.New(t)
 
 	s := pb.Span{Service: "thing", Name: "other", Resource: "yo"}
-	aggr := NewAggregationFromSpan(&s, "default")
+	aggr := NewAggregationFromSpan(&s, "default", nil)
 
 	b := strings.Builder{}
 	aggr.WriteKey(&b)
@@ -31,7 +31,7 @@ func TestGrainWithExtraTags(t *testing.T) {
 	assert := assert.New(t)
 
 	s := pb.Span{Service: "thing", Name: "other", Resource: "yo", Meta: map[string]string{tagHostname: "host-id", tagVersion: "v0", tagStatusCode: "418", tagOrigin: "synthetics-browser"}}
-	aggr := NewAggregationFromSpan(&s, "default")
+	aggr := NewAggregationFromSpan(&s, "default", nil)
 
 	b := strings.Builder{}
 	aggr.WriteKey(&b)
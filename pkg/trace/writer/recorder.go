@@ -0,0 +1,145 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package writer
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Record is an outgoing payload, as written in a recording. A recording is a file
+// containing one JSON encoded Record per line, in the order in which the payloads
+// were sent.
+type Record struct {
+	// Time is the time at which the payload was sent.
+	Time time.Time `json:"time"`
+	// Path is the path of the intake endpoint which the payload was sent to.
+	Path string `json:"path"`
+	// Headers holds the HTTP headers of the payload, without the API key.
+	Headers map[string]string `json:"headers"`
+	// Body holds the body of the payload.
+	Body []byte `json:"body"`
+}
+
+// payloadRecorder appends the payloads sent by the writers to a recording file.
+// It is shared by all the writers recording to the same file.
+type payloadRecorder struct {
+	path string
+	refs int // number of writers using the recorder, guarded by recorders.mu
+
+	mu  sync.Mutex // guards below
+	f   *os.File
+	enc *json.Encoder
+}
+
+// recorders holds the recorders in use, by path.
+var recorders = struct {
+	mu sync.Mutex
+	m  map[string]*payloadRecorder
+}{m: make(map[string]*payloadRecorder)}
+
+// acquireRecorder returns the recorder appending to the file at path, opening it if
+// it is not in use by another writer yet. It returns nil if path is empty or if the
+// file can not be opened. Recorders must be released after use.
+func acquireRecorder(path string) *payloadRecorder {
+	if path == "" {
+		return nil
+	}
+	recorders.mu.Lock()
+	defer recorders.mu.Unlock()
+	if r, ok := recorders.m[path]; ok {
+		r.refs++
+		return r
+	}
+	// recordings contain customer data, keep them private
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		log.Errorf("Can not record outgoing payloads: %v", err)
+		return nil
+	}
+	log.Infof("Recording outgoing payloads to %s", path)
+	r := &payloadRecorder{path: path, refs: 1, f: f, enc: json.NewEncoder(f)}
+	recorders.m[path] = r
+	return r
+}
+
+// release releases the recorder, closing its file if no other writer uses it.
+func (r *payloadRecorder) release() {
+	if r == nil {
+		return
+	}
+	recorders.mu.Lock()
+	defer recorders.mu.Unlock()
+	if r.refs--; r.refs > 0 {
+		return
+	}
+	delete(recorders.m, r.path)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.f.Close(); err != nil {
+		log.Errorf("Error closing payload recording: %v", err)
+	}
+}
+
+// record appends p, sent to the given intake path, to the recording. It must be called
+// before p is handed to the senders, which release it once sent.
+func (r *payloadRecorder) record(path string, p *payload) {
+	if r == nil {
+		return
+	}
+	rec := Record{
+		Time:    time.Now(),
+		Path:    path,
+		Headers: p.headers,
+		Body:    p.body.Bytes(),
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.enc.Encode(rec); err != nil {
+		log.Errorf("Error recording outgoing payload: %v", err)
+	}
+}
+
+// RecordReader reads the records of a recording.
+type RecordReader struct {
+	s *bufio.Scanner
+}
+
+// maxRecordSize is the maximum size of an encoded record. Payloads are capped at a few
+// megabytes by the writers; base64 encoding adds a third to that.
+const maxRecordSize = 64 * 1024 * 1024
+
+// NewRecordReader returns a new RecordReader reading the recording from r.
+func NewRecordReader(r io.Reader) *RecordReader {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), maxRecordSize)
+	return &RecordReader{s: s}
+}
+
+// Next returns the next record of the recording, or io.EOF once all of them were read.
+func (rr *RecordReader) Next() (*Record, error) {
+	for rr.s.Scan() {
+		line := rr.s.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return nil, err
+		}
+		return &rec, nil
+	}
+	if err := rr.s.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
@@ -0,0 +1,176 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package writer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/stats"
+	"github.com/DataDog/datadog-agent/pkg/trace/test/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "recording")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "payloads.rec")
+
+	srv := newTestServer()
+	cfg := &config.AgentConfig{
+		Hostname:           testHostname,
+		DefaultEnv:         testEnv,
+		Endpoints:          []*config.Endpoint{{APIKey: "123", Host: srv.URL}},
+		TraceWriter:        &config.WriterConfig{ConnectionLimit: 200, QueueSize: 40},
+		StatsWriter:        &config.WriterConfig{ConnectionLimit: 20, QueueSize: 20},
+		RecordPayloadsFile: path,
+	}
+
+	// both writers record to the same file
+	tw := NewTraceWriter(cfg)
+	sw := NewStatsWriter(cfg, make(chan []stats.Bucket))
+	assert.True(tw.recorder == sw.recorder)
+	go tw.Run()
+	go sw.Run()
+	tw.In <- randomSampledSpans(10, 2)
+	sw.SendPayload(&stats.Payload{
+		HostName: testHostname,
+		Env:      testEnv,
+		Stats:    []stats.Bucket{testutil.RandomBucket(3)},
+	})
+	tw.Stop()
+	sw.Stop()
+	assert.Empty(recorders.m, "the recording should be closed once all writers are stopped")
+	require.Equal(t, 2, srv.Accepted())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var records []*Record
+	rr := NewRecordReader(f)
+	for {
+		rec, err := rr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		records = append(records, rec)
+	}
+	require.Len(t, records, 2)
+	paths := []string{records[0].Path, records[1].Path}
+	assert.ElementsMatch([]string{pathTraces, pathStats}, paths)
+	for _, rec := range records {
+		assert.Equal("gzip", rec.Headers["Content-Encoding"])
+		assert.NotContains(rec.Headers, headerAPIKey)
+		assert.False(rec.Time.IsZero())
+	}
+
+	// replay the recording against another intake
+	replaySrv := newTestServer()
+	_, err = f.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+	rs, err := Replay(context.Background(), f, ReplayOptions{URL: replaySrv.URL + "/", APIKey: "456", Speed: 0})
+	require.NoError(t, err)
+	assert.Equal(ReplayStats{Sent: 2, Bytes: int64(len(records[0].Body) + len(records[1].Body))}, rs)
+	require.Len(t, replaySrv.Payloads(), 2)
+	for _, p := range replaySrv.Payloads() {
+		assert.Equal("456", p.headers[http.CanonicalHeaderKey(headerAPIKey)])
+		assert.Equal("gzip", p.headers["Content-Encoding"])
+	}
+	// the replayed payloads are identical to the original ones
+	var sent, replayed []string
+	for i := range srv.Payloads() {
+		sent = append(sent, srv.Payloads()[i].body.String())
+		replayed = append(replayed, replaySrv.Payloads()[i].body.String())
+	}
+	assert.ElementsMatch(sent, replayed)
+}
+
+// testRecording returns a recording of payloads sent at the given offsets.
+func testRecording(t *testing.T, offsets ...time.Duration) io.Reader {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	start := time.Now()
+	for i, off := range offsets {
+		require.NoError(t, enc.Encode(Record{
+			Time: start.Add(off),
+			Path: "/api/v0.2/traces",
+			Body: []byte{byte(i)},
+		}))
+		// blank lines are ignored
+		buf.WriteString("\n")
+	}
+	return &buf
+}
+
+func TestReplaySpeed(t *testing.T) {
+	offsets := []time.Duration{0, 100 * time.Millisecond, 200 * time.Millisecond}
+
+	for name, tt := range map[string]struct {
+		speed    float64
+		min, max time.Duration
+	}{
+		"original":    {1, 200 * time.Millisecond, time.Second},
+		"accelerated": {4, 50 * time.Millisecond, 150 * time.Millisecond},
+		"max":         {0, 0, 50 * time.Millisecond},
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv := newTestServer()
+			start := time.Now()
+			rs, err := Replay(context.Background(), testRecording(t, offsets...), ReplayOptions{URL: srv.URL, Speed: tt.speed})
+			elapsed := time.Since(start)
+			assert.NoError(t, err)
+			assert.Equal(t, 3, rs.Sent)
+			assert.Equal(t, 3, srv.Accepted())
+			assert.True(t, elapsed >= tt.min && elapsed < tt.max, "replay took %s", elapsed)
+		})
+	}
+}
+
+func TestReplayCancel(t *testing.T) {
+	srv := newTestServer()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	rs, err := Replay(ctx, testRecording(t, 0, time.Hour), ReplayOptions{URL: srv.URL, Speed: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, ReplayStats{Sent: 1, Bytes: 1}, rs)
+}
+
+func TestReplayErrors(t *testing.T) {
+	srv := newTestServer()
+	rec := testRecording(t, 0, 0)
+	var buf bytes.Buffer
+	io.Copy(&buf, rec)
+	// the second payload is rejected by the server
+	buf.WriteString(strings.Replace(buf.String(), `"body":"AA=="`, `"body":"`+encodeBody("1|404")+`"`, 1))
+
+	rs, err := Replay(context.Background(), &buf, ReplayOptions{URL: srv.URL})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, rs.Sent)
+	assert.Equal(t, 1, rs.Failed)
+
+	_, err = Replay(context.Background(), strings.NewReader("not json\n"), ReplayOptions{URL: srv.URL})
+	assert.Error(t, err)
+}
+
+func encodeBody(s string) string {
+	b, _ := json.Marshal([]byte(s))
+	return strings.Trim(string(b), `"`)
+}
@@ -0,0 +1,114 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package writer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// ReplayOptions specifies how a recording is replayed.
+type ReplayOptions struct {
+	// URL is the base URL of the intake to send the payloads to, such as "http://localhost:8080".
+	// The path of each recorded payload is appended to it.
+	URL string
+	// APIKey is sent along with every payload, if not empty.
+	APIKey string
+	// Speed is the factor by which the recorded time between two payloads is divided:
+	// 1 replays the payloads at their original pace, 2 twice as fast, etc. With a speed of
+	// zero or less, payloads are sent as fast as possible.
+	Speed float64
+	// Client is the HTTP client used to send the payloads. It defaults to a client with a
+	// timeout of 10 seconds.
+	Client *http.Client
+}
+
+// ReplayStats holds statistics about a replayed recording.
+type ReplayStats struct {
+	// Sent is the number of payloads accepted by the intake.
+	Sent int
+	// Failed is the number of payloads which could not be sent or were rejected.
+	Failed int
+	// Bytes is the total size of the payloads sent.
+	Bytes int64
+}
+
+// Replay sends the payloads of the recording read from r to the intake specified in opts,
+// in the order and at the pace in which they were recorded, until the recording ends or
+// ctx is done. Payloads which fail to be sent are not retried.
+func Replay(ctx context.Context, r io.Reader, opts ReplayOptions) (ReplayStats, error) {
+	var stats ReplayStats
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	base := strings.TrimSuffix(opts.URL, "/")
+	rr := NewRecordReader(r)
+	var first time.Time // time of the first record
+	var start time.Time // time at which the first record was replayed
+	for {
+		rec, err := rr.Next()
+		if err == io.EOF {
+			return stats, nil
+		}
+		if err != nil {
+			return stats, fmt.Errorf("error reading recording: %v", err)
+		}
+		if first.IsZero() {
+			first, start = rec.Time, time.Now()
+		} else if opts.Speed > 0 {
+			at := start.Add(time.Duration(float64(rec.Time.Sub(first)) / opts.Speed))
+			select {
+			case <-ctx.Done():
+				return stats, nil
+			case <-time.After(time.Until(at)):
+			}
+		}
+		if ctx.Err() != nil {
+			return stats, nil
+		}
+		if err := replayRecord(ctx, client, base, opts.APIKey, rec); err != nil {
+			log.Warnf("Error replaying payload recorded at %s: %v", rec.Time, err)
+			stats.Failed++
+			continue
+		}
+		stats.Sent++
+		stats.Bytes += int64(len(rec.Body))
+	}
+}
+
+// replayRecord sends the payload of rec to the intake at the base URL.
+func replayRecord(ctx context.Context, client *http.Client, base, apiKey string, rec *Record) error {
+	req, err := http.NewRequest(http.MethodPost, base+rec.Path, bytes.NewReader(rec.Body))
+	if err != nil {
+		return err
+	}
+	for k, v := range rec.Headers {
+		req.Header.Set(k, v)
+	}
+	if apiKey != "" {
+		req.Header.Set(headerAPIKey, apiKey)
+	}
+	req.Header.Set(headerUserAgent, userAgent)
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("server responded with %q", resp.Status)
+	}
+	return nil
+}
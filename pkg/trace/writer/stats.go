@@ -41,6 +41,7 @@ type StatsWriter struct {
 	hostname string
 	env      string
 	senders  []*sender
+	recorder *payloadRecorder // records outgoing payloads, nil if disabled
 	stop     chan struct{}
 	stats    *info.StatsWriterInfo
 
@@ -62,6 +63,7 @@ func NewStatsWriter(cfg *config.AgentConfig, in <-chan []stats.Bucket) *StatsWri
 		stop:      make(chan struct{}),
 		flushChan: make(chan chan struct{}),
 		syncMode:  cfg.SynchronousFlushing,
+		recorder:  acquireRecorder(cfg.RecordPayloadsFile),
 		easylog:   logutil.NewThrottled(5, 10*time.Second), // no more than 5 messages every 10 seconds
 	}
 	climit := cfg.StatsWriter.ConnectionLimit
@@ -127,6 +129,7 @@ func (w *StatsWriter) Stop() {
 	w.stop <- struct{}{}
 	<-w.stop
 	stopSenders(w.senders)
+	w.recorder.release()
 }
 
 func (w *StatsWriter) addStats(s []stats.Bucket) {
@@ -158,6 +161,7 @@ func (w *StatsWriter) SendPayload(p *stats.Payload) {
 	}
 	atomic.AddInt64(&w.stats.Bytes, int64(req.body.Len()))
 
+	w.recorder.record(pathStats, req)
 	sendPayloads(w.senders, req, w.syncMode)
 }
 
@@ -53,6 +53,7 @@ type TraceWriter struct {
 	hostname string
 	env      string
 	senders  []*sender
+	recorder *payloadRecorder // records outgoing payloads, nil if disabled
 	stop     chan struct{}
 	stats    *info.TraceWriterInfo
 	wg       sync.WaitGroup // waits for gzippers
@@ -81,6 +82,7 @@ func NewTraceWriter(cfg *config.AgentConfig) *TraceWriter {
 		flushChan: make(chan chan struct{}),
 		syncMode:  cfg.SynchronousFlushing,
 		tick:      5 * time.Second,
+		recorder:  acquireRecorder(cfg.RecordPayloadsFile),
 		easylog:   logutil.NewThrottled(5, 10*time.Second), // no more than 5 messages every 10 seconds
 	}
 	climit := cfg.TraceWriter.ConnectionLimit
@@ -114,6 +116,7 @@ func (w *TraceWriter) Stop() {
 	w.stop <- struct{}{}
 	<-w.stop
 	stopSenders(w.senders)
+	w.recorder.release()
 }
 
 // Run starts the TraceWriter.
@@ -266,6 +269,7 @@ func (w *TraceWriter) flush() {
 			log.Errorf("Error closing gzip stream when writing trace payload: %v", err)
 		}
 
+		w.recorder.record(pathTraces, p)
 		sendPayloads(w.senders, p, w.syncMode)
 	}()
 }
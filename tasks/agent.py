@@ -41,6 +41,7 @@ AGENT_CORECHECKS = [
     "cri",
     "snmp",
     "docker",
+    "dns_query_log",
     "file_handle",
     "go_expvar",
     "io",
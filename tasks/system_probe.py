@@ -277,27 +277,16 @@ def kitchen_test(ctx):
 @task
 def nettop(ctx, incremental_build=False, go_mod="mod"):
     """
-    Build and run the `nettop` utility for testing
+    Build system-probe and run its `nettop` command against the running system-probe
     """
-    build_object_files(ctx, bundle_ebpf=False)
-
-    cmd = 'go build -mod={go_mod} {build_type} -tags linux_bpf,ebpf_bindata -o {bin_path} {path}'
-    bin_path = os.path.join(BIN_DIR, "nettop")
-    # Build
-    ctx.run(
-        cmd.format(
-            path=os.path.join(REPO_PATH, "pkg", "ebpf", "nettop"),
-            bin_path=bin_path,
-            go_mod=go_mod,
-            build_type="" if incremental_build else "-a",
-        )
-    )
+    build(ctx, incremental_build=incremental_build, go_mod=go_mod)
 
     # Run
+    cmd = "{bin_path} nettop".format(bin_path=BIN_PATH)
     if should_use_sudo(ctx):
-        ctx.sudo(bin_path)
+        ctx.sudo(cmd)
     else:
-        ctx.run(bin_path)
+        ctx.run(cmd)
 
 
 @task